@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+	"github.com/urfave/cli/v2"
+)
+
+// batchTimeout is how long a batch may take by default; its commands do
+// not stream, so the orchestrator cannot keep them alive
+const batchTimeout = 10 * time.Minute
+
+// runBatch sends a JSON array of operations as one batch message and
+// prints each operation's result in order
+func runBatch(c *cli.Context) error {
+	agentDir := os.Getenv("SWARM_AGENT_DIR")
+	if agentDir == "" {
+		return fmt.Errorf("SWARM_AGENT_DIR environment variable not set")
+	}
+
+	path := c.Args().First()
+	if path == "" {
+		return fmt.Errorf("operations file is required (- reads standard input)")
+	}
+
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read operations: %w", err)
+	}
+
+	var operations []workflow.Message
+	if err := json.Unmarshal(data, &operations); err != nil {
+		return fmt.Errorf("failed to parse operations: %w", err)
+	}
+	for i := range operations {
+		if operations[i].Type == workflow.MessageTypeWriteFile {
+			operations[i].Checksum = workflow.Checksum(operations[i].Content)
+		}
+	}
+
+	resp, err := sendMessage(agentDir, workflow.Message{
+		Type:       workflow.MessageTypeBatch,
+		Operations: operations,
+		KeepGoing:  c.Bool("keep-going"),
+	}, c.Duration("timeout"))
+	if err != nil {
+		return err
+	}
+
+	setResult(batchResults(operations, resp.Results))
+	for i, result := range resp.Results {
+		say("=== %d/%d %s: %s\n", i+1, len(operations), describeOperation(operations[i]), result.Status)
+		if result.Data != "" {
+			say("%s", result.Data)
+			if !strings.HasSuffix(result.Data, "\n") {
+				say("\n")
+			}
+		}
+		if result.Status == "error" {
+			say("error [%s]: %s\n", result.Code, result.Error)
+		}
+	}
+
+	if resp.Status == "error" {
+		return errorFromResponse(resp)
+	}
+	return nil
+}
+
+// batchResults lists a batch's results for its JSON result, each with the
+// operation it answers
+func batchResults(operations []workflow.Message, responses []workflow.Response) []map[string]interface{} {
+	results := []map[string]interface{}{}
+	for i, resp := range responses {
+		results = append(results, map[string]interface{}{
+			"operation": describeOperation(operations[i]),
+			"status":    resp.Status,
+			"data":      resp.Data,
+			"code":      resp.Code,
+			"error":     resp.Error,
+		})
+	}
+	return results
+}
+
+// describeOperation names an operation and its target for the results
+func describeOperation(op workflow.Message) string {
+	switch {
+	case op.Command != "":
+		return fmt.Sprintf("%s %s", op.Type, op.Command)
+	case op.Path != "":
+		return fmt.Sprintf("%s %s", op.Type, op.Path)
+	}
+	return string(op.Type)
+}
+
+// verifyBatch checks the checksums of the files a batch read or wrote
+func verifyBatch(msg *workflow.Message, resp *workflow.Response) error {
+	for i := range resp.Results {
+		if i >= len(msg.Operations) {
+			break
+		}
+		if err := verifyResponse(&msg.Operations[i], &resp.Results[i]); err != nil {
+			return fmt.Errorf("operation %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+	"github.com/urfave/cli/v2"
+)
+
+// saveCheckpoint sends the orchestrator the agent's results so far, which
+// survive the agent crashing: the next attempt at the task gets them in
+// its context
+func saveCheckpoint(c *cli.Context) error {
+	agentDir := os.Getenv("SWARM_AGENT_DIR")
+	if agentDir == "" {
+		return fmt.Errorf("SWARM_AGENT_DIR environment variable not set")
+	}
+
+	output := c.String("output")
+	resp, err := sendMessage(agentDir, workflow.Message{
+		Type:    workflow.MessageTypeCheckpoint,
+		Content: output,
+	}, 30*time.Second)
+	if err != nil {
+		return err
+	}
+
+	if resp.Status == "error" {
+		return errorFromResponse(resp)
+	}
+
+	setResult(map[string]interface{}{"bytes": len(output)})
+	say("%s\n", resp.Data)
+	return nil
+}
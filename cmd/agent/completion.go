@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+	"github.com/urfave/cli/v2"
+)
+
+// writeCompletion writes the metadata given to complete with
+// --files-changed, --tests-passed and --notes, or removes the metadata of
+// an earlier completion if none was given
+func writeCompletion(c *cli.Context, agentDir string) error {
+	path := filepath.Join(agentDir, workflow.CompletionFile)
+
+	completion := workflow.Completion{Notes: strings.TrimSpace(c.String("notes"))}
+	for _, file := range c.StringSlice("files-changed") {
+		if file = strings.TrimSpace(file); file != "" {
+			completion.FilesChanged = append(completion.FilesChanged, file)
+		}
+	}
+	if c.IsSet("tests-passed") {
+		passed := c.Bool("tests-passed")
+		completion.TestsPassed = &passed
+	}
+
+	if completion.Empty() {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to clear completion metadata: %w", err)
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(completion, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal completion metadata: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write completion metadata: %w", err)
+	}
+	return nil
+}
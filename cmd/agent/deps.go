@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/state"
+	"github.com/aristath/claude-swarm/internal/workflow"
+	"github.com/urfave/cli/v2"
+)
+
+// listDeps lists the tasks upstream of the agent's, whose outputs deps get
+// fetches
+func listDeps(c *cli.Context) error {
+	agentDir := os.Getenv("SWARM_AGENT_DIR")
+	if agentDir == "" {
+		return fmt.Errorf("SWARM_AGENT_DIR environment variable not set")
+	}
+
+	resp, err := sendMessage(agentDir, workflow.Message{
+		Type: workflow.MessageTypeDeps,
+	}, 30*time.Second)
+	if err != nil {
+		return err
+	}
+
+	if resp.Status == "error" {
+		return errorFromResponse(resp)
+	}
+
+	var deps []state.Dependency
+	if err := json.Unmarshal([]byte(resp.Data), &deps); err != nil {
+		return fmt.Errorf("failed to parse dependencies: %w", err)
+	}
+
+	if jsonOutput {
+		if deps == nil {
+			deps = []state.Dependency{}
+		}
+		setResult(map[string]interface{}{"dependencies": deps})
+		return nil
+	}
+	if len(deps) == 0 {
+		fmt.Println("No upstream tasks")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TASK\tSTATUS\tDEPENDENCY\tOUTPUT\tREPORTED")
+	for _, dep := range deps {
+		via := "indirect"
+		if dep.Direct {
+			via = "direct"
+		}
+		output := "-"
+		if dep.Status == workflow.TaskStatusCompleted {
+			output = fmt.Sprintf("%d bytes", dep.OutputBytes)
+		}
+		reported := dep.Completion.Summary()
+		if reported == "" {
+			reported = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", dep.TaskID, dep.Status, via, output, reported)
+	}
+	return w.Flush()
+}
+
+// getDep prints the current output of an upstream task, which may be newer
+// than the one in the agent's context if the task ran again
+func getDep(c *cli.Context) error {
+	agentDir := os.Getenv("SWARM_AGENT_DIR")
+	if agentDir == "" {
+		return fmt.Errorf("SWARM_AGENT_DIR environment variable not set")
+	}
+
+	taskID := c.Args().First()
+	if taskID == "" {
+		return fmt.Errorf("task ID is required")
+	}
+
+	resp, err := sendMessage(agentDir, workflow.Message{
+		Type: workflow.MessageTypeDepOutput,
+		Task: taskID,
+	}, 30*time.Second)
+	if err != nil {
+		return err
+	}
+
+	if resp.Status == "error" {
+		return errorFromResponse(resp)
+	}
+
+	setResult(map[string]interface{}{"task": taskID, "output": resp.Data})
+	if !strings.HasSuffix(resp.Data, "\n") {
+		resp.Data += "\n"
+	}
+	say("%s", resp.Data)
+	return nil
+}
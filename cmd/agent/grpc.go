@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/agentpb"
+	"github.com/aristath/claude-swarm/internal/version"
+	"github.com/aristath/claude-swarm/internal/workflow"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// grpcConn is a connection to the API server's gRPC service
+type grpcConn struct {
+	*grpc.ClientConn
+	// unreachable is set when connecting to the server failed
+	unreachable atomic.Bool
+}
+
+// dialGRPC connects to the gRPC service at SWARM_GRPC_ADDR with
+// --transport grpc
+func dialGRPC() (*grpcConn, error) {
+	addr := os.Getenv("SWARM_GRPC_ADDR")
+	if transport != transportGRPC || addr == "" {
+		return nil, errNoServer
+	}
+
+	conn := &grpcConn{}
+	client, err := grpc.NewClient("passthrough:///"+addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			c, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+			if err != nil {
+				conn.unreachable.Store(true)
+			}
+			return c, err
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SWARM_GRPC_ADDR: %w", err)
+	}
+	conn.ClientConn = client
+	return conn, nil
+}
+
+// callContext carries the API token and protocol version of a call
+func callContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx := metadata.AppendToOutgoingContext(context.Background(), version.Header, strconv.Itoa(version.ProtocolVersion))
+	if token := os.Getenv("SWARM_API_TOKEN"); token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+	}
+	if timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// callError describes a failed call. A server that is not running leaves
+// the message to the socket or the file bus.
+func (c *grpcConn) callError(err error, timeout time.Duration) error {
+	switch status.Code(err) {
+	case codes.Unavailable:
+		if c.unreachable.Load() {
+			return errNoServer
+		}
+	case codes.DeadlineExceeded:
+		return fmt.Errorf("timeout waiting for response (%s)", timeout)
+	case codes.Unauthenticated:
+		return &responseError{Code: workflow.ErrorUnauthorized, Message: status.Convert(err).Message()}
+	case codes.FailedPrecondition:
+		return &responseError{Code: workflow.ErrorUnsupportedProtocol, Message: status.Convert(err).Message()}
+	}
+	return fmt.Errorf("failed to send message: %w", err)
+}
+
+// sendOverGRPC sends a message to the API server's gRPC service with
+// --transport grpc. A server that is not running leaves the message to
+// the socket or the file bus.
+func sendOverGRPC(agentDir string, msg workflow.Message, timeout time.Duration) (*workflow.Response, error) {
+	request := grpcMessage(agentID(agentDir), msg)
+	if request == nil {
+		return nil, errNoServer
+	}
+	conn, err := dialGRPC()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	ctx, cancel := callContext(timeout)
+	defer cancel()
+
+	// Long commands keep the agent alive while they run
+	stop := keepAlive(agentDir)
+	defer stop()
+
+	var header metadata.MD
+	reply, err := agentpb.NewAgentClient(conn).Send(ctx, request, grpc.Header(&header))
+	if err != nil {
+		return nil, conn.callError(err, timeout)
+	}
+
+	resp := &workflow.Response{
+		MessageID:  msg.ID,
+		Status:     "success",
+		Data:       reply.Data,
+		Checksum:   reply.Checksum,
+		NextCursor: reply.NextCursor,
+		Timestamp:  time.Now(),
+	}
+	if values := header.Get(version.Header); len(values) > 0 {
+		resp.ProtocolVersion, _ = strconv.Atoi(values[0])
+	}
+	if !reply.Success {
+		resp.Status = "error"
+		resp.Code = workflow.ErrorCode(reply.Code)
+		resp.Error = reply.Error
+	}
+	return resp, nil
+}
+
+// grpcMessage converts a message for the gRPC service, or returns nil for
+// messages it does not take with Send
+func grpcMessage(agentID string, msg workflow.Message) *agentpb.Message {
+	switch msg.Type {
+	case workflow.MessageTypeBash, workflow.MessageTypeBatch, workflow.MessageTypeProgress,
+		workflow.MessageTypeDeps, workflow.MessageTypeDepOutput:
+		return nil
+	case workflow.MessageTypeUsage:
+		if msg.Usage == nil {
+			return nil
+		}
+	}
+
+	request := &agentpb.Message{
+		Id:               msg.ID,
+		AgentId:          agentID,
+		Type:             string(msg.Type),
+		Path:             msg.Path,
+		Content:          msg.Content,
+		Checksum:         msg.Checksum,
+		ExpectedChecksum: msg.ExpectedChecksum,
+		WorkingDir:       msg.WorkingDir,
+		TtlSeconds:       int32(msg.TTLSeconds),
+		MaxResults:       int32(msg.MaxResults),
+		Cursor:           msg.Cursor,
+		Symbol:           msg.Symbol,
+		Kind:             msg.Kind,
+		References:       msg.References,
+	}
+	for _, edit := range msg.Edits {
+		request.Edits = append(request.Edits, &agentpb.Edit{OldString: edit.OldString, NewString: edit.NewString})
+	}
+	if msg.Usage != nil {
+		request.Usage = &agentpb.Usage{
+			InputTokens:  msg.Usage.InputTokens,
+			OutputTokens: msg.Usage.OutputTokens,
+			CostUsd:      msg.Usage.CostUSD,
+		}
+	}
+	return request
+}
+
+// streamBash runs a command over the gRPC service with --transport grpc,
+// writing its output to stdout as it arrives and, with stdin, feeding it
+// this process's standard input. Interrupting swarm-agent kills the command.
+func streamBash(agentDir, command, dir string, stdin bool, stdout io.Writer) error {
+	conn, err := dialGRPC()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := callContext(0)
+	defer cancel()
+	ctx, stopSignals := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	// Long commands keep the agent alive while they run
+	stop := keepAlive(agentDir)
+	defer stop()
+
+	stream, err := agentpb.NewAgentClient(conn).Bash(ctx)
+	if err != nil {
+		return conn.callError(err, 0)
+	}
+	// A failed send shows up as the stream's error on receive
+	if err := stream.Send(&agentpb.BashInput{
+		AgentId:    agentID(agentDir),
+		Command:    command,
+		WorkingDir: dir,
+	}); err == nil {
+		if stdin {
+			go forwardStdin(stream)
+		} else {
+			stream.CloseSend()
+		}
+	}
+
+	for {
+		out, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("command output ended before the command finished")
+		}
+		if err != nil {
+			return conn.callError(err, 0)
+		}
+
+		stdout.Write(out.Output)
+		if out.Done {
+			if out.Code == "" {
+				return nil
+			}
+			return &responseError{Code: workflow.ErrorCode(out.Code), Message: out.Error}
+		}
+	}
+}
+
+// forwardStdin sends standard input to a streamed command until it ends
+func forwardStdin(stream agentpb.Agent_BashClient) {
+	defer stream.CloseSend()
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if n > 0 {
+			if stream.Send(&agentpb.BashInput{Stdin: append([]byte(nil), buf[:n]...)}) != nil {
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
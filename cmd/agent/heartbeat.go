@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+	"github.com/urfave/cli/v2"
+)
+
+// writeHeartbeat tells the orchestrator the agent is alive by touching its
+// heartbeat file
+func writeHeartbeat(agentDir string) error {
+	heartbeatFile := filepath.Join(agentDir, workflow.HeartbeatFile)
+	tmpFile := heartbeatFile + ".tmp"
+	if err := os.WriteFile(tmpFile, []byte(time.Now().Format(time.RFC3339)), 0644); err != nil {
+		return fmt.Errorf("failed to write heartbeat: %w", err)
+	}
+	if err := os.Rename(tmpFile, heartbeatFile); err != nil {
+		os.Remove(tmpFile)
+		return fmt.Errorf("failed to write heartbeat: %w", err)
+	}
+	return nil
+}
+
+// keepAlive writes heartbeats while a long request is in flight, until
+// the returned function is called
+func keepAlive(agentDir string) func() {
+	ticker := time.NewTicker(workflow.HeartbeatInterval)
+	done := make(chan struct{})
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				writeHeartbeat(agentDir)
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// sendHeartbeat writes a heartbeat, or with --every keeps writing them
+// until the task completes or fails, for agents busy with long work that
+// talks to nobody
+func sendHeartbeat(c *cli.Context) error {
+	agentDir := os.Getenv("SWARM_AGENT_DIR")
+	if agentDir == "" {
+		return fmt.Errorf("SWARM_AGENT_DIR environment variable not set")
+	}
+
+	if err := writeHeartbeat(agentDir); err != nil {
+		return err
+	}
+	every := c.Duration("every")
+	if every <= 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for _, marker := range []string{"COMPLETE", "FAILED"} {
+			if _, err := os.Stat(filepath.Join(agentDir, marker)); err == nil {
+				return nil
+			}
+		}
+		if err := writeHeartbeat(agentDir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
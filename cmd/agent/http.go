@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/version"
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// errNoServer means no API server takes the agent's messages, so they go
+// over the socket or the file bus
+var errNoServer = errors.New("no API server")
+
+// httpReply is the API server's response, a success or a problem
+type httpReply struct {
+	Success    bool               `json:"success"`
+	Data       string             `json:"data"`
+	Code       workflow.ErrorCode `json:"code"`
+	Error      string             `json:"error"`
+	Detail     string             `json:"detail"`
+	Checksum   string             `json:"checksum"`
+	NextCursor string             `json:"next_cursor"`
+}
+
+// sendOverHTTP sends a message to the API server at SWARM_API_URL with
+// --transport http. A server that is not running leaves the message to
+// the socket or the file bus.
+func sendOverHTTP(agentDir string, msg workflow.Message, timeout time.Duration) (*workflow.Response, error) {
+	apiURL := os.Getenv("SWARM_API_URL")
+	if transport != transportHTTP || apiURL == "" {
+		return nil, errNoServer
+	}
+
+	endpoint, body := httpRequest(agentID(agentDir), msg)
+	if endpoint == "" {
+		return nil, errNoServer
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(apiURL, "/")+endpoint, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SWARM_API_URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(version.Header, strconv.Itoa(version.ProtocolVersion))
+	if token := os.Getenv("SWARM_API_TOKEN"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	// Long commands keep the agent alive while they run
+	stop := keepAlive(agentDir)
+	defer stop()
+
+	client := &http.Client{Timeout: timeout}
+	res, err := client.Do(req)
+	if err != nil {
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			return nil, errNoServer
+		}
+		if os.IsTimeout(err) {
+			return nil, fmt.Errorf("timeout waiting for response (%s)", timeout)
+		}
+		return nil, fmt.Errorf("failed to send message: %w", err)
+	}
+	defer res.Body.Close()
+
+	var reply httpReply
+	if err := json.NewDecoder(res.Body).Decode(&reply); err != nil {
+		return nil, fmt.Errorf("failed to read response (HTTP %d): %w", res.StatusCode, err)
+	}
+
+	resp := &workflow.Response{
+		MessageID:  msg.ID,
+		Status:     "success",
+		Data:       reply.Data,
+		Checksum:   reply.Checksum,
+		NextCursor: reply.NextCursor,
+		Timestamp:  time.Now(),
+	}
+	resp.ProtocolVersion, _ = strconv.Atoi(res.Header.Get(version.Header))
+	if !reply.Success {
+		resp.Status = "error"
+		resp.Code = reply.Code
+		resp.Error = reply.Error
+		if resp.Error == "" {
+			resp.Error = reply.Detail
+		}
+	}
+	return resp, nil
+}
+
+// agentID returns the ID the API server knows an agent by, its task's
+func agentID(agentDir string) string {
+	return strings.TrimPrefix(filepath.Base(agentDir), "agent-")
+}
+
+// httpRequest returns the endpoint and request body of a message, or no
+// endpoint for messages the API server does not take
+func httpRequest(agentID string, msg workflow.Message) (string, map[string]interface{}) {
+	switch msg.Type {
+	case workflow.MessageTypeReadFile:
+		return "/api/file/read", map[string]interface{}{
+			"agent_id": agentID,
+			"path":     msg.Path,
+		}
+	case workflow.MessageTypeWriteFile:
+		return "/api/file/write", map[string]interface{}{
+			"agent_id":          agentID,
+			"path":              msg.Path,
+			"content":           msg.Content,
+			"checksum":          msg.Checksum,
+			"expected_checksum": msg.ExpectedChecksum,
+		}
+	case workflow.MessageTypeEditFile:
+		return "/api/file/edit", map[string]interface{}{
+			"agent_id":          agentID,
+			"path":              msg.Path,
+			"edits":             msg.Edits,
+			"expected_checksum": msg.ExpectedChecksum,
+		}
+	case workflow.MessageTypeBash:
+		return "/api/bash", map[string]interface{}{
+			"agent_id":    agentID,
+			"command":     msg.Command,
+			"working_dir": msg.WorkingDir,
+		}
+	case workflow.MessageTypeGlob:
+		return "/api/glob", map[string]interface{}{
+			"pattern":     msg.Path,
+			"max_results": msg.MaxResults,
+			"cursor":      msg.Cursor,
+		}
+	case workflow.MessageTypeGrep:
+		return "/api/grep", map[string]interface{}{
+			"pattern":     msg.Content,
+			"path":        msg.Path,
+			"recursive":   true,
+			"max_results": msg.MaxResults,
+			"cursor":      msg.Cursor,
+		}
+	case workflow.MessageTypeCodeSearch:
+		return "/api/symbols", map[string]interface{}{
+			"name":        msg.Symbol,
+			"path":        msg.Path,
+			"kind":        msg.Kind,
+			"references":  msg.References,
+			"max_results": msg.MaxResults,
+			"cursor":      msg.Cursor,
+		}
+	case workflow.MessageTypeLock, workflow.MessageTypeUnlock:
+		endpoint := "/api/lock"
+		if msg.Type == workflow.MessageTypeUnlock {
+			endpoint = "/api/unlock"
+		}
+		return endpoint, map[string]interface{}{
+			"agent_id":    agentID,
+			"path":        msg.Path,
+			"ttl_seconds": msg.TTLSeconds,
+		}
+	case workflow.MessageTypeLocks:
+		return "/api/locks", map[string]interface{}{}
+	case workflow.MessageTypeAddTasks:
+		return "/api/tasks/add", map[string]interface{}{
+			"agent_id":    agentID,
+			"tasks":       msg.Content,
+			"working_dir": msg.WorkingDir,
+		}
+	case workflow.MessageTypeUsage:
+		if msg.Usage == nil {
+			return "", nil
+		}
+		return "/api/usage", map[string]interface{}{
+			"agent_id":      agentID,
+			"input_tokens":  msg.Usage.InputTokens,
+			"output_tokens": msg.Usage.OutputTokens,
+			"cost_usd":      msg.Usage.CostUSD,
+		}
+	case workflow.MessageTypeCheckpoint:
+		return "/api/checkpoint", map[string]interface{}{
+			"agent_id": agentID,
+			"output":   msg.Content,
+		}
+	case workflow.MessageTypeDeps:
+		return "/api/deps", map[string]interface{}{
+			"agent_id": agentID,
+		}
+	case workflow.MessageTypeDepOutput:
+		return "/api/deps/output", map[string]interface{}{
+			"agent_id": agentID,
+			"task":     msg.Task,
+		}
+	case workflow.MessageTypeProgress:
+		return "/api/progress", map[string]interface{}{
+			"agent_id": agentID,
+			"text":     msg.Content,
+			"percent":  msg.Percent,
+		}
+	}
+	return "", nil
+}
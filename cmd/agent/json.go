@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// jsonOutput is set by --json: commands record their data instead of
+// printing it, and swarm-agent prints their result as one JSON object
+var jsonOutput bool
+
+// commandName is the command being run, for its JSON result
+var commandName string
+
+// resultData is the data the command recorded for its JSON result
+var resultData interface{}
+
+// result is what a command prints with --json, on success and on failure
+type result struct {
+	Status     string             `json:"status"` // "success" or "error"
+	Command    string             `json:"command"`
+	Data       interface{}        `json:"data,omitempty"`
+	Error      string             `json:"error,omitempty"`
+	Code       workflow.ErrorCode `json:"code,omitempty"`
+	DurationMS int64              `json:"duration_ms"`
+}
+
+// say prints text meant for people, which --json leaves out
+func say(format string, args ...interface{}) {
+	if !jsonOutput {
+		fmt.Printf(format, args...)
+	}
+}
+
+// setResult records a command's data for its JSON result
+func setResult(data interface{}) {
+	resultData = data
+}
+
+// printResult prints a command's JSON result: its data, and its error if
+// it failed
+func printResult(err error, elapsed time.Duration) {
+	out := result{
+		Status:     "success",
+		Command:    commandName,
+		Data:       resultData,
+		DurationMS: elapsed.Milliseconds(),
+	}
+	if err != nil {
+		out.Status = "error"
+		out.Error = err.Error()
+		out.Code = workflow.CodeOf(err)
+	}
+
+	data, marshalErr := json.Marshal(out)
+	if marshalErr != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to marshal result: %v\n", marshalErr)
+		return
+	}
+	fmt.Printf("%s\n", data)
+}
+
+// lines splits newline-separated results into a list
+func lines(data string) []string {
+	data = strings.TrimSuffix(data, "\n")
+	if data == "" {
+		return []string{}
+	}
+	return strings.Split(data, "\n")
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/state"
+	"github.com/aristath/claude-swarm/internal/workflow"
+	"github.com/urfave/cli/v2"
+)
+
+func lockFile(c *cli.Context) error {
+	agentDir := os.Getenv("SWARM_AGENT_DIR")
+	if agentDir == "" {
+		return fmt.Errorf("SWARM_AGENT_DIR environment variable not set")
+	}
+
+	path, err := lockPath(c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	resp, err := sendMessage(agentDir, workflow.Message{
+		Type:       workflow.MessageTypeLock,
+		Path:       path,
+		TTLSeconds: int(c.Duration("ttl").Seconds()),
+	}, 30*time.Second)
+	if err != nil {
+		return err
+	}
+
+	if resp.Status == "error" {
+		return errorFromResponse(resp)
+	}
+
+	locks, err := parseLocks(resp.Data)
+	if err != nil {
+		return err
+	}
+	setResult(map[string]interface{}{"locks": locks})
+	for _, lock := range locks {
+		say("Locked %s until %s\n", lock.Path, lock.ExpiresAt.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func unlockFile(c *cli.Context) error {
+	agentDir := os.Getenv("SWARM_AGENT_DIR")
+	if agentDir == "" {
+		return fmt.Errorf("SWARM_AGENT_DIR environment variable not set")
+	}
+
+	path, err := lockPath(c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	resp, err := sendMessage(agentDir, workflow.Message{
+		Type: workflow.MessageTypeUnlock,
+		Path: path,
+	}, 30*time.Second)
+	if err != nil {
+		return err
+	}
+
+	if resp.Status == "error" {
+		return errorFromResponse(resp)
+	}
+
+	setResult(map[string]interface{}{"path": path, "message": resp.Data})
+	say("%s\n", resp.Data)
+	return nil
+}
+
+func listLocks(c *cli.Context) error {
+	agentDir := os.Getenv("SWARM_AGENT_DIR")
+	if agentDir == "" {
+		return fmt.Errorf("SWARM_AGENT_DIR environment variable not set")
+	}
+
+	resp, err := sendMessage(agentDir, workflow.Message{
+		Type: workflow.MessageTypeLocks,
+	}, 30*time.Second)
+	if err != nil {
+		return err
+	}
+
+	if resp.Status == "error" {
+		return errorFromResponse(resp)
+	}
+
+	locks, err := parseLocks(resp.Data)
+	if err != nil {
+		return err
+	}
+
+	// Optionally show only the locks on the given paths
+	if c.Args().Present() {
+		var filtered []state.FileLock
+		for _, lock := range locks {
+			for _, arg := range c.Args().Slice() {
+				if path, err := lockPath(arg); err == nil && path == lock.Path {
+					filtered = append(filtered, lock)
+				}
+			}
+		}
+		locks = filtered
+	}
+
+	if jsonOutput {
+		if locks == nil {
+			locks = []state.FileLock{}
+		}
+		setResult(map[string]interface{}{"locks": locks})
+		return nil
+	}
+	if len(locks) == 0 {
+		fmt.Println("No locks held")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PATH\tOWNER\tEXPIRES")
+	for _, lock := range locks {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", lock.Path, lock.Owner, lock.ExpiresAt.Format(time.RFC3339))
+	}
+	return w.Flush()
+}
+
+// lockPath makes a path absolute, so that agents in different working
+// directories lock the same file under the same name
+func lockPath(path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("file path is required")
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	return abs, nil
+}
+
+// parseLocks decodes the locks in a response
+func parseLocks(data string) ([]state.FileLock, error) {
+	var locks []state.FileLock
+	if err := json.Unmarshal([]byte(data), &locks); err != nil {
+		return nil, fmt.Errorf("failed to parse locks: %w", err)
+	}
+	return locks, nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+	"github.com/urfave/cli/v2"
+)
+
+// logLimit caps what one operation adds to the transcript, such as a
+// long command or error
+const logLimit = 200
+
+// writeLog appends a note to the agent's transcript, which the TUI shows
+// alongside its operations
+func writeLog(c *cli.Context) error {
+	agentDir := os.Getenv("SWARM_AGENT_DIR")
+	if agentDir == "" {
+		return fmt.Errorf("SWARM_AGENT_DIR environment variable not set")
+	}
+
+	message := strings.Join(c.Args().Slice(), " ")
+	if strings.TrimSpace(message) == "" {
+		return fmt.Errorf("message is required")
+	}
+	if err := appendLog(agentDir, message); err != nil {
+		return err
+	}
+
+	setResult(map[string]interface{}{"logged": message})
+	return nil
+}
+
+// appendLog appends a line to the agent's transcript
+func appendLog(agentDir, text string) error {
+	file, err := os.OpenFile(filepath.Join(agentDir, workflow.AgentLogFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open agent log: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(workflow.AgentLogLine(time.Now(), text)); err != nil {
+		return fmt.Errorf("failed to write agent log: %w", err)
+	}
+	return nil
+}
+
+// logOperation appends an operation and its outcome to the transcript.
+// The transcript is for people debugging agents, so failing to write it
+// never fails the operation.
+func logOperation(agentDir, operation string, resp *workflow.Response, err error, elapsed time.Duration) {
+	outcome := "success"
+	switch {
+	case err != nil:
+		outcome = "failed: " + err.Error()
+	case resp.Status == "error" && resp.Code != "":
+		outcome = fmt.Sprintf("error [%s]: %s", resp.Code, resp.Error)
+	case resp.Status == "error":
+		outcome = "error: " + resp.Error
+	}
+	appendLog(agentDir, fmt.Sprintf("%s: %s (%s)", operation, truncateLog(outcome), elapsed.Round(time.Millisecond)))
+}
+
+// truncateLog shortens text to logLimit bytes for the transcript
+func truncateLog(text string) string {
+	if len(text) > logLimit {
+		return strings.ToValidUTF8(text[:logLimit], "") + "..."
+	}
+	return text
+}
@@ -2,23 +2,61 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/aristath/claude-swarm/internal/state"
+	"github.com/aristath/claude-swarm/internal/version"
 	"github.com/aristath/claude-swarm/internal/workflow"
 	"github.com/urfave/cli/v2"
 )
 
 func main() {
 	app := &cli.App{
-		Name:  "swarm-agent",
-		Usage: "Claude Swarm agent helper CLI",
+		Name:    "swarm-agent",
+		Usage:   "Claude Swarm agent helper CLI",
+		Version: version.Current().String(),
+		Before:  handshake,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "transport",
+				Usage:   "How messages reach the orchestrator: socket, http (the API server), grpc (its gRPC service) or files; unreachable ones fall back to the file bus",
+				EnvVars: []string{"SWARM_TRANSPORT"},
+				Value:   transportSocket,
+			},
+			&cli.BoolFlag{
+				Name:    "json",
+				Usage:   "Print each command's result as one JSON object: status, data, error, code and duration_ms",
+				EnvVars: []string{"SWARM_JSON"},
+			},
+		},
 		Commands: []*cli.Command{
 			{
-				Name:   "ask",
-				Usage:  "Ask the orchestrator a question",
+				Name:  "version",
+				Usage: "Print the swarm-agent version and protocol version",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "Print as JSON",
+					},
+				},
+				Action: printVersion,
+			},
+			{
+				Name:      "ask",
+				Usage:     "Ask the orchestrator a question",
+				ArgsUsage: "<question>",
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "thread",
+						Usage: "Reply in the thread of an earlier question, by its number",
+					},
+				},
 				Action: askQuestion,
 			},
 			{
@@ -30,9 +68,45 @@ func main() {
 						Usage:    "Task output/results",
 						Required: true,
 					},
+					&cli.StringSliceFlag{
+						Name:  "files-changed",
+						Usage: "A file the task changed; repeat it or separate files with commas",
+					},
+					&cli.BoolFlag{
+						Name:  "tests-passed",
+						Usage: "Whether the tests passed; --tests-passed=false reports that they failed",
+					},
+					&cli.StringFlag{
+						Name:  "notes",
+						Usage: "Notes for the operator and the tasks that depend on this one",
+					},
 				},
 				Action: completeTask,
 			},
+			{
+				Name:  "checkpoint",
+				Usage: "Save partial results, which the next attempt at the task starts from if this one does not complete",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "output",
+						Usage:    "Results so far",
+						Required: true,
+					},
+				},
+				Action: saveCheckpoint,
+			},
+			{
+				Name:  "fail",
+				Usage: "Give up on the task, reporting why",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "error",
+						Usage:    "Why the task could not be completed",
+						Required: true,
+					},
+				},
+				Action: failTask,
+			},
 			{
 				Name:   "check-followup",
 				Usage:  "Check for orchestrator follow-up questions",
@@ -42,13 +116,33 @@ func main() {
 				Name:      "file-read",
 				Usage:     "Read a file via orchestrator",
 				ArgsUsage: "<path>",
-				Action:    fileRead,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "checksum",
+						Usage: "Print the file's SHA-256 checksum to stderr, for --expect",
+					},
+				},
+				Action: fileRead,
 			},
 			{
 				Name:      "file-write",
 				Usage:     "Write a file via orchestrator",
-				ArgsUsage: "<path> <content>",
-				Action:    fileWrite,
+				ArgsUsage: "<path> [content]",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "stdin",
+						Usage: "Write the content read from standard input, such as a heredoc",
+					},
+					&cli.StringFlag{
+						Name:  "from-file",
+						Usage: "Write the content of this local file, such as one staged in a temporary directory",
+					},
+					&cli.StringFlag{
+						Name:  "expect",
+						Usage: "Only apply if the file still has this SHA-256 checksum (from file-read --checksum)",
+					},
+				},
+				Action: fileWrite,
 			},
 			{
 				Name:      "file-edit",
@@ -65,6 +159,10 @@ func main() {
 						Usage:    "New string to insert",
 						Required: true,
 					},
+					&cli.StringFlag{
+						Name:  "expect",
+						Usage: "Only apply if the file still has this SHA-256 checksum (from file-read --checksum)",
+					},
 				},
 				Action: fileEdit,
 			},
@@ -77,22 +175,273 @@ func main() {
 						Name:  "dir",
 						Usage: "Working directory",
 					},
+					&cli.BoolFlag{
+						Name:  "stdin",
+						Usage: "Feed standard input to the command (with --transport grpc)",
+					},
 				},
 				Action: bashCommand,
 			},
+			{
+				Name:      "batch",
+				Usage:     "Run a JSON array of operations in order as one message, printing each result",
+				ArgsUsage: "<operations.json|->",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "keep-going",
+						Usage: "Run the remaining operations after one fails",
+					},
+					&cli.DurationFlag{
+						Name:  "timeout",
+						Value: batchTimeout,
+						Usage: "How long to wait for the whole batch",
+					},
+				},
+				Action: runBatch,
+			},
 			{
 				Name:      "glob",
 				Usage:     "Search files with glob pattern via orchestrator",
 				ArgsUsage: "<pattern>",
-				Action:    globPattern,
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "max-results",
+						Usage: "Return at most this many results (default 1000)",
+					},
+					&cli.StringFlag{
+						Name:  "cursor",
+						Usage: "Continue from the cursor printed by the previous page",
+					},
+				},
+				Action: globPattern,
+			},
+			{
+				Name:      "grep",
+				Usage:     "Search file contents recursively via orchestrator",
+				ArgsUsage: "<pattern> [path]",
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "max-results",
+						Usage: "Return at most this many results (default 1000)",
+					},
+					&cli.StringFlag{
+						Name:  "cursor",
+						Usage: "Continue from the cursor printed by the previous page",
+					},
+				},
+				Action: grepPattern,
+			},
+			{
+				Name:      "symbols",
+				Usage:     "Find where a symbol is defined, or referenced, via orchestrator",
+				ArgsUsage: "<name>",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "refs",
+						Usage: "List references instead of definitions",
+					},
+					&cli.StringFlag{
+						Name:  "kind",
+						Usage: "Only definitions of this kind (func, method, type, class, field, const, var)",
+					},
+					&cli.StringFlag{
+						Name:  "path",
+						Usage: "Directory tree to search (default: current directory)",
+					},
+					&cli.IntFlag{
+						Name:  "max-results",
+						Usage: "Return at most this many results (default 1000)",
+					},
+					&cli.StringFlag{
+						Name:  "cursor",
+						Usage: "Continue from the cursor printed by the previous page",
+					},
+				},
+				Action: findSymbols,
+			},
+			{
+				Name:      "lock",
+				Usage:     "Take an advisory lock on a file shared with other agents",
+				ArgsUsage: "<path>",
+				Flags: []cli.Flag{
+					&cli.DurationFlag{
+						Name:  "ttl",
+						Usage: "How long the lock lasts unless renewed by locking again",
+						Value: state.DefaultLockTTL,
+					},
+				},
+				Action: lockFile,
+			},
+			{
+				Name:      "unlock",
+				Usage:     "Release an advisory lock",
+				ArgsUsage: "<path>",
+				Action:    unlockFile,
+			},
+			{
+				Name:      "locks",
+				Usage:     "List the advisory locks held by agents",
+				ArgsUsage: "[path...]",
+				Action:    listLocks,
+			},
+			{
+				Name:  "heartbeat",
+				Usage: "Tell the orchestrator the agent is alive",
+				Flags: []cli.Flag{
+					&cli.DurationFlag{
+						Name:  "every",
+						Usage: "Keep sending heartbeats at this interval until the task completes or fails",
+					},
+				},
+				Action: sendHeartbeat,
+			},
+			{
+				Name:      "add-tasks",
+				Usage:     "Add tasks to the running workflow, from a YAML file with a tasks list",
+				ArgsUsage: "<tasks.yaml|->",
+				Action:    addTasks,
+			},
+			{
+				Name:  "report-usage",
+				Usage: "Report the tokens the agent spent, counted against the workflow's budget",
+				Flags: []cli.Flag{
+					&cli.Int64Flag{
+						Name:  "input",
+						Usage: "Input tokens spent since the last report",
+					},
+					&cli.Int64Flag{
+						Name:  "output",
+						Usage: "Output tokens spent since the last report",
+					},
+					&cli.Float64Flag{
+						Name:  "cost",
+						Usage: "Cost in USD; priced by the workflow's budget when omitted",
+					},
+					&cli.StringFlag{
+						Name:  "claude-json",
+						Usage: "Read the usage from claude --output-format json (or stream-json) output, - for standard input",
+					},
+				},
+				Action: reportUsage,
+			},
+			{
+				Name:  "deps",
+				Usage: "Fetch the outputs of upstream tasks on demand",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "list",
+						Usage:  "List the tasks this one depends on, directly or through other tasks",
+						Action: listDeps,
+					},
+					{
+						Name:      "get",
+						Usage:     "Print the current output of an upstream task",
+						ArgsUsage: "<task-id>",
+						Action:    getDep,
+					},
+				},
+			},
+			{
+				Name:      "log",
+				Usage:     "Append a note to the agent's transcript, shown in the TUI",
+				ArgsUsage: "<message>",
+				Action:    writeLog,
+			},
+			{
+				Name:      "progress",
+				Usage:     "Report what the agent is doing and how far along it is, shown on its card in the TUI",
+				ArgsUsage: "<status>",
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "percent",
+						Usage: "How far along the task is, from 0 to 100",
+						Value: -1,
+					},
+				},
+				Action: reportProgress,
 			},
 		},
 	}
 
-	if err := app.Run(os.Args); err != nil {
+	started := time.Now()
+	err := app.Run(os.Args)
+	if jsonOutput {
+		printResult(err, time.Since(started))
+	} else if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
 	}
+	if err != nil {
+		os.Exit(exitCode(err))
+	}
+}
+
+// handshake checks that the orchestrator of the current session speaks the
+// same protocol before running any command, so a stale swarm-agent binary
+// fails with a clear message instead of writing messages nobody reads
+func handshake(c *cli.Context) error {
+	// With --json, usage and help go to stderr so stdout holds only the
+	// result
+	jsonOutput = c.Bool("json")
+	commandName = c.Args().First()
+	if jsonOutput {
+		c.App.Writer = os.Stderr
+	}
+
+	switch transport = c.String("transport"); transport {
+	case transportSocket, transportHTTP, transportGRPC, transportFiles:
+	default:
+		return fmt.Errorf("unknown transport %q (want socket, http, grpc or files)", transport)
+	}
+
+	switch c.Args().First() {
+	case "", "version", "help", "h":
+		return nil
+	}
+
+	// Every command is a sign of life
+	if agentDir := os.Getenv("SWARM_AGENT_DIR"); agentDir != "" {
+		writeHeartbeat(agentDir)
+	}
+
+	swarmDir := os.Getenv("SWARM_DIR")
+	if swarmDir == "" {
+		agentDir := os.Getenv("SWARM_AGENT_DIR")
+		if agentDir == "" {
+			return nil
+		}
+		swarmDir = filepath.Dir(filepath.Dir(agentDir)) // agents/agent-X -> swarm dir
+	}
+
+	orchestratorVersion, err := version.Read(swarmDir)
+	if err != nil {
+		if os.IsNotExist(errors.Unwrap(err)) {
+			fmt.Fprintf(os.Stderr, "Warning: orchestrator did not publish a protocol version; it may be older than swarm-agent %s\n", version.Current())
+			return nil
+		}
+		return err
+	}
+
+	return version.Current().CheckCompatible(orchestratorVersion)
+}
+
+func printVersion(c *cli.Context) error {
+	info := version.Current()
+
+	if jsonOutput {
+		setResult(info)
+		return nil
+	}
+	if c.Bool("json") {
+		data, err := json.Marshal(info)
+		if err != nil {
+			return fmt.Errorf("failed to marshal version: %w", err)
+		}
+		fmt.Printf("%s\n", data)
+		return nil
+	}
+
+	fmt.Printf("swarm-agent %s\n", info)
+	return nil
 }
 
 func askQuestion(c *cli.Context) error {
@@ -114,16 +463,34 @@ func askQuestion(c *cli.Context) error {
 	}
 	qNum := len(files) + 1
 
-	// Write question file
-	qFile := filepath.Join(questionsDir, fmt.Sprintf("q-%d.txt", qNum))
-	if err := os.WriteFile(qFile, []byte(question), 0644); err != nil {
+	// Replies name the thread they continue
+	name := fmt.Sprintf("%d", qNum)
+	thread := qNum
+	if c.Int("thread") > 0 {
+		thread = c.Int("thread")
+		if thread >= qNum {
+			return fmt.Errorf("question %d not found", thread)
+		}
+		name = fmt.Sprintf("%d-re-%d", qNum, thread)
+	}
+
+	// Write question file, renaming it into place so the orchestrator
+	// never reads it half-written
+	qFile := filepath.Join(questionsDir, fmt.Sprintf("q-%s.txt", name))
+	tmpFile := filepath.Join(questionsDir, fmt.Sprintf(".q-%s.txt.tmp", name))
+	if err := os.WriteFile(tmpFile, []byte(question), 0644); err != nil {
+		return fmt.Errorf("failed to write question: %w", err)
+	}
+	if err := os.Rename(tmpFile, qFile); err != nil {
+		os.Remove(tmpFile)
 		return fmt.Errorf("failed to write question: %w", err)
 	}
 
-	fmt.Printf("Question sent to orchestrator. Waiting for answer...\n")
+	say("Question %d sent to orchestrator. Waiting for answer...\n", qNum)
 
 	// Wait for answer (with timeout)
-	aFile := filepath.Join(questionsDir, fmt.Sprintf("a-%d.txt", qNum))
+	aFile := filepath.Join(questionsDir, fmt.Sprintf("a-%s.txt", name))
+	dFile := filepath.Join(questionsDir, fmt.Sprintf("d-%s.txt", name))
 	timeout := time.After(5 * time.Minute)
 	ticker := time.NewTicker(2 * time.Second)
 	defer ticker.Stop()
@@ -131,6 +498,13 @@ func askQuestion(c *cli.Context) error {
 	for {
 		select {
 		case <-timeout:
+			// A drafted answer awaits an operator's review, however long
+			// that takes
+			if _, err := os.Stat(dFile); err == nil {
+				timeout = time.After(5 * time.Minute)
+				say("The answer awaits review by an operator. Still waiting...\n")
+				continue
+			}
 			return fmt.Errorf("timeout waiting for answer (5 minutes)")
 
 		case <-ticker.C:
@@ -141,9 +515,15 @@ func askQuestion(c *cli.Context) error {
 					return fmt.Errorf("failed to read answer: %w", err)
 				}
 
-				fmt.Printf("\n=== Orchestrator's Answer ===\n")
-				fmt.Printf("%s\n", string(answer))
-				fmt.Printf("============================\n\n")
+				setResult(map[string]interface{}{
+					"question": qNum,
+					"thread":   thread,
+					"answer":   string(answer),
+				})
+				say("\n=== Orchestrator's Answer ===\n")
+				say("%s\n", string(answer))
+				say("============================\n\n")
+				say("To follow up: swarm-agent ask --thread %d \"...\"\n", thread)
 
 				return nil
 			}
@@ -159,12 +539,34 @@ func completeTask(c *cli.Context) error {
 
 	output := c.String("output")
 
+	// Check the output's format and structure before the orchestrator sees
+	// it, so it can be fixed and completed again
+	var task workflow.Task
+	if data, err := os.ReadFile(filepath.Join(agentDir, workflow.OutputFormatFile)); err == nil {
+		task.OutputFormat = string(data)
+	}
+	if data, err := os.ReadFile(filepath.Join(agentDir, workflow.OutputSchemaFile)); err == nil {
+		if err := json.Unmarshal(data, &task.OutputSchema); err != nil {
+			return fmt.Errorf("failed to parse output schema: %w", err)
+		}
+	}
+	output, err := task.CheckOutput(output)
+	if err != nil {
+		return err
+	}
+
 	// Write output file
 	outputFile := filepath.Join(agentDir, "output.txt")
 	if err := os.WriteFile(outputFile, []byte(output), 0644); err != nil {
 		return fmt.Errorf("failed to write output: %w", err)
 	}
 
+	// Write the completion metadata, before the marker the orchestrator
+	// reads it on
+	if err := writeCompletion(c, agentDir); err != nil {
+		return err
+	}
+
 	// Write status file
 	statusFile := filepath.Join(agentDir, "status.txt")
 	if err := os.WriteFile(statusFile, []byte("completed"), 0644); err != nil {
@@ -177,8 +579,43 @@ func completeTask(c *cli.Context) error {
 		return fmt.Errorf("failed to create COMPLETE marker: %w", err)
 	}
 
-	fmt.Printf("Task marked as complete. Output saved.\n")
-	fmt.Printf("Orchestrator will detect completion and spawn dependent tasks.\n")
+	say("Task marked as complete. Output saved.\n")
+	say("Orchestrator will detect completion and spawn dependent tasks.\n")
+
+	// Echo the acceptance criteria, for the agent to check its work once more
+	if criteria, err := os.ReadFile(filepath.Join(agentDir, workflow.AcceptanceCriteriaFile)); err == nil {
+		setResult(map[string]interface{}{"acceptance_criteria": lines(string(criteria))})
+		say("\nAcceptance criteria for this task:\n%s", criteria)
+	}
+
+	return nil
+}
+
+func failTask(c *cli.Context) error {
+	agentDir := os.Getenv("SWARM_AGENT_DIR")
+	if agentDir == "" {
+		return fmt.Errorf("SWARM_AGENT_DIR environment variable not set")
+	}
+
+	// Write error file
+	errorFile := filepath.Join(agentDir, "error.txt")
+	if err := os.WriteFile(errorFile, []byte(c.String("error")), 0644); err != nil {
+		return fmt.Errorf("failed to write error: %w", err)
+	}
+
+	// Write status file
+	statusFile := filepath.Join(agentDir, "status.txt")
+	if err := os.WriteFile(statusFile, []byte("failed"), 0644); err != nil {
+		return fmt.Errorf("failed to write status: %w", err)
+	}
+
+	// Create FAILED marker
+	failedFile := filepath.Join(agentDir, "FAILED")
+	if err := os.WriteFile(failedFile, []byte(""), 0644); err != nil {
+		return fmt.Errorf("failed to create FAILED marker: %w", err)
+	}
+
+	say("Task marked as failed.\n")
 
 	return nil
 }
@@ -197,6 +634,7 @@ func checkFollowup(c *cli.Context) error {
 		return fmt.Errorf("failed to list follow-up questions: %w", err)
 	}
 
+	answered := []map[string]string{}
 	for _, qFile := range files {
 		aFile := filepath.Base(qFile)
 		aFile = filepath.Join(followupDir, "a-"+aFile[2:]) // q-N.txt -> a-N.txt
@@ -212,10 +650,10 @@ func checkFollowup(c *cli.Context) error {
 			return fmt.Errorf("failed to read follow-up question: %w", err)
 		}
 
-		fmt.Printf("\n=== Orchestrator Follow-Up Question ===\n")
-		fmt.Printf("%s\n", string(question))
-		fmt.Printf("=====================================\n\n")
-		fmt.Printf("Please provide your answer:\n")
+		say("\n=== Orchestrator Follow-Up Question ===\n")
+		say("%s\n", string(question))
+		say("=====================================\n\n")
+		say("Please provide your answer:\n")
 
 		// Read answer from stdin
 		var answer string
@@ -226,11 +664,13 @@ func checkFollowup(c *cli.Context) error {
 			return fmt.Errorf("failed to write answer: %w", err)
 		}
 
-		fmt.Printf("Answer sent to orchestrator.\n")
+		answered = append(answered, map[string]string{"question": string(question), "answer": answer})
+		say("Answer sent to orchestrator.\n")
 	}
+	setResult(map[string]interface{}{"answered": answered})
 
 	if len(files) == 0 {
-		fmt.Printf("No pending follow-up questions.\n")
+		say("No pending follow-up questions.\n")
 	}
 
 	return nil
@@ -247,65 +687,33 @@ func fileRead(c *cli.Context) error {
 		return fmt.Errorf("file path is required")
 	}
 
-	// Generate message ID
-	msgID := fmt.Sprintf("msg-%d", time.Now().UnixNano())
-
-	// Create message
-	msg := workflow.Message{
-		ID:        msgID,
-		Type:      workflow.MessageTypeReadFile,
-		Path:      path,
-		Timestamp: time.Now(),
-	}
-
-	// Write message
-	messagesDir := filepath.Join(agentDir, "messages")
-	msgFile := filepath.Join(messagesDir, fmt.Sprintf("%s.json", msgID))
-
-	msgData, err := json.MarshalIndent(msg, "", "  ")
+	// Large files print as they arrive
+	var streamed strings.Builder
+	resp, err := streamMessage(agentDir, workflow.Message{
+		Type: workflow.MessageTypeReadFile,
+		Path: path,
+	}, 30*time.Second, chunkPrinter(&streamed))
 	if err != nil {
-		return fmt.Errorf("failed to marshal message: %w", err)
+		return err
 	}
 
-	if err := os.WriteFile(msgFile, msgData, 0644); err != nil {
-		return fmt.Errorf("failed to write message: %w", err)
+	if resp.Status == "error" {
+		return errorFromResponse(resp)
 	}
 
-	// Wait for response
-	responsesDir := filepath.Join(agentDir, "responses")
-	responseFile := filepath.Join(responsesDir, fmt.Sprintf("%s-result.json", msgID))
-
-	timeout := time.After(30 * time.Second)
-	ticker := time.NewTicker(500 * time.Millisecond)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-timeout:
-			return fmt.Errorf("timeout waiting for response (30 seconds)")
-
-		case <-ticker.C:
-			if _, err := os.Stat(responseFile); err == nil {
-				// Response exists, read it
-				respData, err := os.ReadFile(responseFile)
-				if err != nil {
-					return fmt.Errorf("failed to read response: %w", err)
-				}
-
-				var resp workflow.Response
-				if err := json.Unmarshal(respData, &resp); err != nil {
-					return fmt.Errorf("failed to parse response: %w", err)
-				}
-
-				if resp.Status == "error" {
-					return fmt.Errorf("orchestrator error: %s", resp.Error)
-				}
-
-				fmt.Printf("%s", resp.Data)
-				return nil
-			}
-		}
+	if jsonOutput {
+		setResult(map[string]interface{}{
+			"path":     path,
+			"content":  streamed.String() + resp.Data,
+			"checksum": resp.Checksum,
+		})
+		return nil
+	}
+	fmt.Printf("%s", resp.Data)
+	if c.Bool("checksum") {
+		fmt.Fprintf(os.Stderr, "checksum: %s\n", resp.Checksum)
 	}
+	return nil
 }
 
 func fileWrite(c *cli.Context) error {
@@ -314,73 +722,74 @@ func fileWrite(c *cli.Context) error {
 		return fmt.Errorf("SWARM_AGENT_DIR environment variable not set")
 	}
 
-	if c.Args().Len() < 2 {
-		return fmt.Errorf("path and content are required")
-	}
-
-	path := c.Args().Get(0)
-	content := c.Args().Get(1)
-
-	// Generate message ID
-	msgID := fmt.Sprintf("msg-%d", time.Now().UnixNano())
-
-	// Create message
-	msg := workflow.Message{
-		ID:        msgID,
-		Type:      workflow.MessageTypeWriteFile,
-		Path:      path,
-		Content:   content,
-		Timestamp: time.Now(),
+	content, err := writeContent(c)
+	if err != nil {
+		return err
 	}
 
-	// Write message
-	messagesDir := filepath.Join(agentDir, "messages")
-	msgFile := filepath.Join(messagesDir, fmt.Sprintf("%s.json", msgID))
-
-	msgData, err := json.MarshalIndent(msg, "", "  ")
+	resp, err := sendMessage(agentDir, workflow.Message{
+		Type:             workflow.MessageTypeWriteFile,
+		Path:             c.Args().Get(0),
+		Content:          content,
+		ExpectedChecksum: c.String("expect"),
+	}, 30*time.Second)
 	if err != nil {
-		return fmt.Errorf("failed to marshal message: %w", err)
+		return err
 	}
 
-	if err := os.WriteFile(msgFile, msgData, 0644); err != nil {
-		return fmt.Errorf("failed to write message: %w", err)
+	if resp.Status == "error" {
+		printStale(resp)
+		return errorFromResponse(resp)
 	}
 
-	// Wait for response
-	responsesDir := filepath.Join(agentDir, "responses")
-	responseFile := filepath.Join(responsesDir, fmt.Sprintf("%s-result.json", msgID))
-
-	timeout := time.After(30 * time.Second)
-	ticker := time.NewTicker(500 * time.Millisecond)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-timeout:
-			return fmt.Errorf("timeout waiting for response (30 seconds)")
-
-		case <-ticker.C:
-			if _, err := os.Stat(responseFile); err == nil {
-				// Response exists, read it
-				respData, err := os.ReadFile(responseFile)
-				if err != nil {
-					return fmt.Errorf("failed to read response: %w", err)
-				}
+	setResult(map[string]interface{}{
+		"path":     c.Args().First(),
+		"message":  resp.Data,
+		"checksum": resp.Checksum,
+	})
+	say("%s\n", resp.Data)
+	return nil
+}
 
-				var resp workflow.Response
-				if err := json.Unmarshal(respData, &resp); err != nil {
-					return fmt.Errorf("failed to parse response: %w", err)
-				}
+// writeContent returns the content file-write writes, verbatim: the
+// argument, standard input with --stdin, or a local file with --from-file
+func writeContent(c *cli.Context) (string, error) {
+	if c.Args().First() == "" {
+		return "", fmt.Errorf("file path is required")
+	}
 
-				if resp.Status == "error" {
-					return fmt.Errorf("orchestrator error: %s", resp.Error)
-				}
+	sources := 0
+	if c.Args().Len() > 1 {
+		sources++
+	}
+	if c.Bool("stdin") {
+		sources++
+	}
+	if c.String("from-file") != "" {
+		sources++
+	}
+	switch {
+	case sources == 0:
+		return "", fmt.Errorf("content is required, as an argument, with --stdin or with --from-file")
+	case sources > 1:
+		return "", fmt.Errorf("give the content once: as an argument, with --stdin or with --from-file")
+	}
 
-				fmt.Printf("%s\n", resp.Data)
-				return nil
-			}
+	switch {
+	case c.Bool("stdin"):
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read content: %w", err)
 		}
+		return string(data), nil
+	case c.String("from-file") != "":
+		data, err := os.ReadFile(c.String("from-file"))
+		if err != nil {
+			return "", fmt.Errorf("failed to read content: %w", err)
+		}
+		return string(data), nil
 	}
+	return c.Args().Get(1), nil
 }
 
 func fileEdit(c *cli.Context) error {
@@ -394,74 +803,33 @@ func fileEdit(c *cli.Context) error {
 		return fmt.Errorf("file path is required")
 	}
 
-	oldString := c.String("old")
-	newString := c.String("new")
-
-	// Generate message ID
-	msgID := fmt.Sprintf("msg-%d", time.Now().UnixNano())
-
-	// Create message
-	msg := workflow.Message{
-		ID:   msgID,
+	resp, err := sendMessage(agentDir, workflow.Message{
 		Type: workflow.MessageTypeEditFile,
 		Path: path,
 		Edits: []workflow.Edit{
 			{
-				OldString: oldString,
-				NewString: newString,
+				OldString: c.String("old"),
+				NewString: c.String("new"),
 			},
 		},
-		Timestamp: time.Now(),
-	}
-
-	// Write message
-	messagesDir := filepath.Join(agentDir, "messages")
-	msgFile := filepath.Join(messagesDir, fmt.Sprintf("%s.json", msgID))
-
-	msgData, err := json.MarshalIndent(msg, "", "  ")
+		ExpectedChecksum: c.String("expect"),
+	}, 30*time.Second)
 	if err != nil {
-		return fmt.Errorf("failed to marshal message: %w", err)
+		return err
 	}
 
-	if err := os.WriteFile(msgFile, msgData, 0644); err != nil {
-		return fmt.Errorf("failed to write message: %w", err)
+	if resp.Status == "error" {
+		printStale(resp)
+		return errorFromResponse(resp)
 	}
 
-	// Wait for response
-	responsesDir := filepath.Join(agentDir, "responses")
-	responseFile := filepath.Join(responsesDir, fmt.Sprintf("%s-result.json", msgID))
-
-	timeout := time.After(30 * time.Second)
-	ticker := time.NewTicker(500 * time.Millisecond)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-timeout:
-			return fmt.Errorf("timeout waiting for response (30 seconds)")
-
-		case <-ticker.C:
-			if _, err := os.Stat(responseFile); err == nil {
-				// Response exists, read it
-				respData, err := os.ReadFile(responseFile)
-				if err != nil {
-					return fmt.Errorf("failed to read response: %w", err)
-				}
-
-				var resp workflow.Response
-				if err := json.Unmarshal(respData, &resp); err != nil {
-					return fmt.Errorf("failed to parse response: %w", err)
-				}
-
-				if resp.Status == "error" {
-					return fmt.Errorf("orchestrator error: %s", resp.Error)
-				}
-
-				fmt.Printf("%s\n", resp.Data)
-				return nil
-			}
-		}
-	}
+	setResult(map[string]interface{}{
+		"path":     c.Args().First(),
+		"message":  resp.Data,
+		"checksum": resp.Checksum,
+	})
+	say("%s\n", resp.Data)
+	return nil
 }
 
 func bashCommand(c *cli.Context) error {
@@ -475,70 +843,37 @@ func bashCommand(c *cli.Context) error {
 		return fmt.Errorf("command is required")
 	}
 
-	workingDir := c.String("dir")
-
-	// Generate message ID
-	msgID := fmt.Sprintf("msg-%d", time.Now().UnixNano())
+	// Over gRPC the output streams while the command runs
+	var output strings.Builder
+	var stdout io.Writer = os.Stdout
+	if jsonOutput {
+		stdout = &output
+	}
+	if err := streamBash(agentDir, command, c.String("dir"), c.Bool("stdin"), stdout); !errors.Is(err, errNoServer) {
+		setResult(map[string]interface{}{"output": output.String()})
+		return err
+	}
 
-	// Create message
-	msg := workflow.Message{
-		ID:         msgID,
+	// Output prints while the command runs; the orchestrator keeps
+	// silent commands alive, so the timeout only trips when it is gone
+	resp, err := streamMessage(agentDir, workflow.Message{
 		Type:       workflow.MessageTypeBash,
 		Command:    command,
-		WorkingDir: workingDir,
-		Timestamp:  time.Now(),
-	}
-
-	// Write message
-	messagesDir := filepath.Join(agentDir, "messages")
-	msgFile := filepath.Join(messagesDir, fmt.Sprintf("%s.json", msgID))
-
-	msgData, err := json.MarshalIndent(msg, "", "  ")
+		WorkingDir: c.String("dir"),
+	}, 60*time.Second, chunkPrinter(&output))
 	if err != nil {
-		return fmt.Errorf("failed to marshal message: %w", err)
+		return err
 	}
 
-	if err := os.WriteFile(msgFile, msgData, 0644); err != nil {
-		return fmt.Errorf("failed to write message: %w", err)
+	// For bash, include output even on error
+	setResult(map[string]interface{}{"output": output.String() + resp.Data})
+	if !jsonOutput {
+		fmt.Printf("%s", resp.Data)
 	}
-
-	// Wait for response
-	responsesDir := filepath.Join(agentDir, "responses")
-	responseFile := filepath.Join(responsesDir, fmt.Sprintf("%s-result.json", msgID))
-
-	timeout := time.After(60 * time.Second) // Longer timeout for bash commands
-	ticker := time.NewTicker(500 * time.Millisecond)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-timeout:
-			return fmt.Errorf("timeout waiting for response (60 seconds)")
-
-		case <-ticker.C:
-			if _, err := os.Stat(responseFile); err == nil {
-				// Response exists, read it
-				respData, err := os.ReadFile(responseFile)
-				if err != nil {
-					return fmt.Errorf("failed to read response: %w", err)
-				}
-
-				var resp workflow.Response
-				if err := json.Unmarshal(respData, &resp); err != nil {
-					return fmt.Errorf("failed to parse response: %w", err)
-				}
-
-				if resp.Status == "error" {
-					// For bash, include output even on error
-					fmt.Printf("%s", resp.Data)
-					return fmt.Errorf("command failed: %s", resp.Error)
-				}
-
-				fmt.Printf("%s", resp.Data)
-				return nil
-			}
-		}
+	if resp.Status == "error" {
+		return errorFromResponse(resp)
 	}
+	return nil
 }
 
 func globPattern(c *cli.Context) error {
@@ -552,63 +887,114 @@ func globPattern(c *cli.Context) error {
 		return fmt.Errorf("glob pattern is required")
 	}
 
-	// Generate message ID
-	msgID := fmt.Sprintf("msg-%d", time.Now().UnixNano())
+	resp, err := sendMessage(agentDir, workflow.Message{
+		Type:       workflow.MessageTypeGlob,
+		Path:       pattern,
+		MaxResults: c.Int("max-results"),
+		Cursor:     c.String("cursor"),
+	}, 30*time.Second)
+	if err != nil {
+		return err
+	}
+
+	if resp.Status == "error" {
+		return errorFromResponse(resp)
+	}
+
+	printPage(resp)
+	return nil
+}
 
-	// Create message
-	msg := workflow.Message{
-		ID:        msgID,
-		Type:      workflow.MessageTypeGlob,
-		Path:      pattern,
-		Timestamp: time.Now(),
+func grepPattern(c *cli.Context) error {
+	agentDir := os.Getenv("SWARM_AGENT_DIR")
+	if agentDir == "" {
+		return fmt.Errorf("SWARM_AGENT_DIR environment variable not set")
 	}
 
-	// Write message
-	messagesDir := filepath.Join(agentDir, "messages")
-	msgFile := filepath.Join(messagesDir, fmt.Sprintf("%s.json", msgID))
+	pattern := c.Args().First()
+	if pattern == "" {
+		return fmt.Errorf("grep pattern is required")
+	}
 
-	msgData, err := json.MarshalIndent(msg, "", "  ")
+	resp, err := sendMessage(agentDir, workflow.Message{
+		Type:       workflow.MessageTypeGrep,
+		Content:    pattern,
+		Path:       c.Args().Get(1),
+		MaxResults: c.Int("max-results"),
+		Cursor:     c.String("cursor"),
+	}, 60*time.Second)
 	if err != nil {
-		return fmt.Errorf("failed to marshal message: %w", err)
+		return err
 	}
 
-	if err := os.WriteFile(msgFile, msgData, 0644); err != nil {
-		return fmt.Errorf("failed to write message: %w", err)
+	if resp.Status == "error" {
+		return errorFromResponse(resp)
 	}
 
-	// Wait for response
-	responsesDir := filepath.Join(agentDir, "responses")
-	responseFile := filepath.Join(responsesDir, fmt.Sprintf("%s-result.json", msgID))
+	printPage(resp)
+	return nil
+}
 
-	timeout := time.After(30 * time.Second)
-	ticker := time.NewTicker(500 * time.Millisecond)
-	defer ticker.Stop()
+func findSymbols(c *cli.Context) error {
+	agentDir := os.Getenv("SWARM_AGENT_DIR")
+	if agentDir == "" {
+		return fmt.Errorf("SWARM_AGENT_DIR environment variable not set")
+	}
 
-	for {
-		select {
-		case <-timeout:
-			return fmt.Errorf("timeout waiting for response (30 seconds)")
+	name := c.Args().First()
+	if name == "" {
+		return fmt.Errorf("symbol name is required")
+	}
 
-		case <-ticker.C:
-			if _, err := os.Stat(responseFile); err == nil {
-				// Response exists, read it
-				respData, err := os.ReadFile(responseFile)
-				if err != nil {
-					return fmt.Errorf("failed to read response: %w", err)
-				}
+	// The orchestrator runs elsewhere, so send it an absolute tree
+	root := c.String("path")
+	if root == "" {
+		root = "."
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return fmt.Errorf("failed to resolve search path: %w", err)
+	}
+
+	resp, err := sendMessage(agentDir, workflow.Message{
+		Type:       workflow.MessageTypeCodeSearch,
+		Symbol:     name,
+		Kind:       c.String("kind"),
+		References: c.Bool("refs"),
+		Path:       root,
+		MaxResults: c.Int("max-results"),
+		Cursor:     c.String("cursor"),
+	}, 120*time.Second) // Indexing a large tree the first time takes a while
+	if err != nil {
+		return err
+	}
 
-				var resp workflow.Response
-				if err := json.Unmarshal(respData, &resp); err != nil {
-					return fmt.Errorf("failed to parse response: %w", err)
-				}
+	if resp.Status == "error" {
+		return errorFromResponse(resp)
+	}
 
-				if resp.Status == "error" {
-					return fmt.Errorf("orchestrator error: %s", resp.Error)
-				}
+	if resp.Data == "" && !jsonOutput {
+		fmt.Printf("No matches for %s\n", name)
+		return nil
+	}
+	printPage(resp)
+	return nil
+}
 
-				fmt.Printf("%s\n", resp.Data)
-				return nil
-			}
-		}
+// printPage prints a page of search results and, if more follow, how to
+// get them
+func printPage(resp *workflow.Response) {
+	if jsonOutput {
+		setResult(map[string]interface{}{
+			"results":     lines(resp.Data),
+			"next_cursor": resp.NextCursor,
+		})
+		return
+	}
+	if resp.Data != "" {
+		fmt.Printf("%s\n", resp.Data)
+	}
+	if resp.NextCursor != "" {
+		fmt.Fprintf(os.Stderr, "More results: rerun with --cursor %s\n", resp.NextCursor)
 	}
 }
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/version"
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// Transports messages can take to the orchestrator, chosen with
+// --transport or SWARM_TRANSPORT
+const (
+	transportSocket = "socket" // the session's Unix socket, else the file bus
+	transportHTTP   = "http"   // the API server, else the socket or file bus
+	transportGRPC   = "grpc"   // the API server's gRPC service, else the socket or file bus
+	transportFiles  = "files"  // the file bus only
+)
+
+// transport is the transport chosen for this command
+var transport = transportSocket
+
+// sendMessage sends a message to the orchestrator, over its API server's
+// gRPC service or HTTP API, its socket or else through the agent's
+// messages directory, and waits for the response
+func sendMessage(agentDir string, msg workflow.Message, timeout time.Duration) (*workflow.Response, error) {
+	return streamMessage(agentDir, msg, timeout, nil)
+}
+
+// streamMessage sends a message like sendMessage, but asks for the
+// response in chunks and passes their data to onChunk as they arrive; the
+// final response holds the rest. Each chunk restarts the timeout, so a
+// stream lasts as long as the orchestrator keeps sending. The API server
+// answers in one piece. Every operation goes into the agent's transcript.
+func streamMessage(agentDir string, msg workflow.Message, timeout time.Duration, onChunk func(data string)) (resp *workflow.Response, err error) {
+	started := time.Now()
+	operation := truncateLog(describeOperation(msg))
+	defer func() {
+		logOperation(agentDir, operation, resp, err, time.Since(started))
+	}()
+
+	// Generate message ID
+	msg.ID = workflow.NewMessageID(agentID(agentDir))
+	msg.ProtocolVersion = version.ProtocolVersion
+	msg.AcceptEncoding = workflow.EncodingGzip
+	msg.Timestamp = time.Now()
+	if msg.Type == workflow.MessageTypeWriteFile {
+		msg.Checksum = workflow.Checksum(msg.Content)
+	}
+
+	// Streamed data is checksummed as it passes
+	streamed := sha256.New()
+	if onChunk != nil {
+		msg.Stream = true
+		print := onChunk
+		onChunk = func(data string) {
+			streamed.Write([]byte(data))
+			print(data)
+		}
+	}
+
+	resp, err = sendOverGRPC(agentDir, msg, timeout)
+	if errors.Is(err, errNoServer) {
+		resp, err = sendOverHTTP(agentDir, msg, timeout)
+	}
+	if errors.Is(err, errNoServer) {
+		if err := msg.CompressContent(); err != nil {
+			return nil, err
+		}
+		resp, err = sendOverSocket(agentDir, msg, timeout, onChunk)
+	}
+	if errors.Is(err, errNoSocket) {
+		resp, err = sendOverFiles(agentDir, msg, timeout, onChunk)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := version.CheckProtocol(resp.ProtocolVersion); err != nil {
+		return nil, fmt.Errorf("orchestrator response: %w", err)
+	}
+
+	if err := resp.DecodeData(); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if resp.Sequence > 0 {
+		err = verifyStreamed(&msg, resp, streamed)
+	} else {
+		err = verifyResponse(&msg, resp)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// chunkPrinter returns a callback that prints streamed data as it
+// arrives, or with --json collects it in buf for the result
+func chunkPrinter(buf *strings.Builder) func(data string) {
+	return func(data string) {
+		if jsonOutput {
+			buf.WriteString(data)
+			return
+		}
+		fmt.Print(data)
+	}
+}
+
+// sendOverFiles writes a message to the agent's messages directory and
+// polls for the response file, and for the numbered chunk files that come
+// before it when streaming
+func sendOverFiles(agentDir string, msg workflow.Message, timeout time.Duration, onChunk func(string)) (*workflow.Response, error) {
+	msgData, err := json.MarshalIndent(msg, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	// Write message via rename so the orchestrator never reads it half-written
+	messagesDir := filepath.Join(agentDir, "messages")
+	msgFile := filepath.Join(messagesDir, fmt.Sprintf("%s.json", msg.ID))
+	tmpFile := filepath.Join(messagesDir, fmt.Sprintf(".%s.json.tmp", msg.ID))
+
+	if err := os.WriteFile(tmpFile, msgData, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := os.Rename(tmpFile, msgFile); err != nil {
+		return nil, fmt.Errorf("failed to write message: %w", err)
+	}
+
+	// Wait for response
+	responseDir := filepath.Join(agentDir, "responses")
+	responseFile := filepath.Join(responseDir, fmt.Sprintf("%s-result.json", msg.ID))
+
+	// readChunks passes on the chunks written so far, in order
+	next := 1
+	readChunks := func() (bool, error) {
+		read := false
+		for {
+			chunkFile := filepath.Join(responseDir, workflow.ChunkFile(msg.ID, next))
+			data, err := os.ReadFile(chunkFile)
+			if err != nil {
+				return read, nil
+			}
+			var chunk workflow.Response
+			if err := json.Unmarshal(data, &chunk); err != nil {
+				return read, fmt.Errorf("failed to parse response chunk: %w", err)
+			}
+			os.Remove(chunkFile)
+			if onChunk != nil {
+				onChunk(chunk.Data)
+			}
+			next++
+			read = true
+		}
+	}
+
+	deadline := time.After(timeout)
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+
+	// Long commands keep the agent alive while they run
+	heartbeat := time.NewTicker(workflow.HeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-deadline:
+			return nil, fmt.Errorf("timeout waiting for response (%s)", timeout)
+
+		case <-heartbeat.C:
+			writeHeartbeat(agentDir)
+
+		case <-ticker.C:
+			// Each chunk restarts the wait
+			read, err := readChunks()
+			if err != nil {
+				return nil, err
+			}
+			if read {
+				deadline = time.After(timeout)
+			}
+
+			if _, err := os.Stat(responseFile); err != nil {
+				continue
+			}
+
+			// Response exists, read it
+			respData, err := os.ReadFile(responseFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read response: %w", err)
+			}
+
+			var resp workflow.Response
+			if err := json.Unmarshal(respData, &resp); err != nil {
+				return nil, fmt.Errorf("failed to parse response: %w", err)
+			}
+
+			// The last chunks may have landed just before the response
+			if _, err := readChunks(); err != nil {
+				return nil, err
+			}
+			if next <= resp.Sequence {
+				return nil, fmt.Errorf("response chunks %d to %d are missing", next, resp.Sequence)
+			}
+
+			return &resp, nil
+		}
+	}
+}
+
+// verifyResponse checks the checksum of a file read or written, so that
+// truncated transfers and concurrently modified files are reported instead
+// of silently used
+func verifyResponse(msg *workflow.Message, resp *workflow.Response) error {
+	if msg.Type == workflow.MessageTypeBatch {
+		return verifyBatch(msg, resp)
+	}
+	if resp.Status != "success" || resp.Checksum == "" {
+		return nil
+	}
+
+	switch msg.Type {
+	case workflow.MessageTypeReadFile:
+		return workflow.VerifyChecksum(resp.Data, resp.Checksum)
+	case workflow.MessageTypeWriteFile:
+		if resp.Checksum != msg.Checksum {
+			return workflow.Errorf(workflow.ErrorChecksumMismatch, "checksum mismatch: %s holds %s, not the written content (%s)", msg.Path, resp.Checksum, msg.Checksum)
+		}
+	}
+	return nil
+}
+
+// verifyStreamed checks the checksum of a file read over the data streamed
+// before the response and the rest in it
+func verifyStreamed(msg *workflow.Message, resp *workflow.Response, streamed hash.Hash) error {
+	if resp.Status != "success" || resp.Checksum == "" || msg.Type != workflow.MessageTypeReadFile {
+		return nil
+	}
+
+	streamed.Write([]byte(resp.Data))
+	if got := hex.EncodeToString(streamed.Sum(nil)); got != resp.Checksum {
+		return workflow.Errorf(workflow.ErrorChecksumMismatch, "checksum mismatch: expected %s, got %s; content was truncated or modified in transit", resp.Checksum, got)
+	}
+	return nil
+}
+
+// printStale prints the current content of a file that changed since the
+// agent read it, so the agent can redo its change without another read
+func printStale(resp *workflow.Response) {
+	if resp.Code != workflow.ErrorConflict || resp.Checksum == "" {
+		return
+	}
+	if jsonOutput {
+		setResult(map[string]interface{}{"content": resp.Data, "checksum": resp.Checksum})
+		return
+	}
+	fmt.Printf("%s", resp.Data)
+	fmt.Fprintf(os.Stderr, "checksum: %s\n", resp.Checksum)
+}
+
+// responseError is an error response from the orchestrator
+type responseError struct {
+	Code    workflow.ErrorCode
+	Message string
+}
+
+// ErrorCode returns the orchestrator's code for the error
+func (e *responseError) ErrorCode() workflow.ErrorCode {
+	return e.Code
+}
+
+func (e *responseError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("orchestrator error: %s", e.Message)
+	}
+	return fmt.Sprintf("orchestrator error [%s]: %s", e.Code, e.Message)
+}
+
+// errorFromResponse turns an error response into a responseError
+func errorFromResponse(resp *workflow.Response) error {
+	return &responseError{Code: resp.Code, Message: resp.Error}
+}
+
+// exitCodes gives each error code its own exit status so that scripts can
+// branch on the failure without parsing messages
+var exitCodes = map[workflow.ErrorCode]int{
+	workflow.ErrorNotFound:            3,
+	workflow.ErrorPermissionDenied:    4,
+	workflow.ErrorTimeout:             5,
+	workflow.ErrorSandboxViolation:    6,
+	workflow.ErrorQuotaExceeded:       7,
+	workflow.ErrorConflict:            8,
+	workflow.ErrorChecksumMismatch:    13,
+	workflow.ErrorInvalidRequest:      9,
+	workflow.ErrorUnauthorized:        10,
+	workflow.ErrorUnsupportedProtocol: 11,
+	workflow.ErrorCommandFailed:       12,
+	workflow.ErrorVerificationFailed:  14,
+}
+
+// exitCode returns the exit status for an error
+func exitCode(err error) int {
+	if code, ok := exitCodes[workflow.CodeOf(err)]; ok {
+		return code
+	}
+	return 1
+}
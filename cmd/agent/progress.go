@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+	"github.com/urfave/cli/v2"
+)
+
+// reportProgress tells the orchestrator what the agent is doing and, with
+// --percent, how far along it is, so long tasks show more than "running"
+func reportProgress(c *cli.Context) error {
+	agentDir := os.Getenv("SWARM_AGENT_DIR")
+	if agentDir == "" {
+		return fmt.Errorf("SWARM_AGENT_DIR environment variable not set")
+	}
+
+	msg := workflow.Message{
+		Type:    workflow.MessageTypeProgress,
+		Content: strings.Join(c.Args().Slice(), " "),
+	}
+	if percent := c.Int("percent"); percent >= 0 {
+		msg.Percent = &percent
+	}
+	if msg.Content == "" && msg.Percent == nil {
+		return fmt.Errorf("a status or --percent is required")
+	}
+
+	resp, err := sendMessage(agentDir, msg, 30*time.Second)
+	if err != nil {
+		return err
+	}
+
+	if resp.Status == "error" {
+		return errorFromResponse(resp)
+	}
+
+	result := map[string]interface{}{"status": msg.Content}
+	if msg.Percent != nil {
+		result["percent"] = *msg.Percent
+	}
+	setResult(result)
+	say("Progress: %s\n", resp.Data)
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// errNoSocket means the orchestrator takes no messages on a socket, so
+// they go through the file bus
+var errNoSocket = errors.New("no message socket")
+
+// sendOverSocket sends a message over the orchestrator's Unix socket and
+// reads the response from the same connection, after the chunks of a
+// streamed one. --transport files skips the socket.
+func sendOverSocket(agentDir string, msg workflow.Message, timeout time.Duration, onChunk func(string)) (*workflow.Response, error) {
+	swarmDir := os.Getenv("SWARM_DIR")
+	if swarmDir == "" || transport == transportFiles {
+		return nil, errNoSocket
+	}
+
+	conn, err := net.DialTimeout("unix", filepath.Join(swarmDir, workflow.SocketFile), time.Second)
+	if err != nil {
+		return nil, errNoSocket
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	// Long commands keep the agent alive while they run
+	stop := keepAlive(agentDir)
+	defer stop()
+
+	req := workflow.SocketRequest{
+		AgentID: strings.TrimPrefix(filepath.Base(agentDir), "agent-"),
+		Message: msg,
+	}
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, fmt.Errorf("failed to send message: %w", err)
+	}
+
+	decoder := json.NewDecoder(conn)
+	for {
+		var resp workflow.Response
+		if err := decoder.Decode(&resp); err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				return nil, fmt.Errorf("timeout waiting for response (%s)", timeout)
+			}
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		if !resp.More {
+			return &resp, nil
+		}
+
+		// Each chunk restarts the wait
+		if onChunk != nil {
+			onChunk(resp.Data)
+		}
+		conn.SetDeadline(time.Now().Add(timeout))
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+	"github.com/urfave/cli/v2"
+)
+
+func addTasks(c *cli.Context) error {
+	agentDir := os.Getenv("SWARM_AGENT_DIR")
+	if agentDir == "" {
+		return fmt.Errorf("SWARM_AGENT_DIR environment variable not set")
+	}
+
+	path := c.Args().First()
+	if path == "" {
+		return fmt.Errorf("tasks file is required (- reads standard input)")
+	}
+
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read tasks: %w", err)
+	}
+
+	resp, err := sendMessage(agentDir, workflow.Message{
+		Type:    workflow.MessageTypeAddTasks,
+		Content: string(data),
+	}, 30*time.Second)
+	if err != nil {
+		return err
+	}
+
+	if resp.Status == "error" {
+		return errorFromResponse(resp)
+	}
+
+	added := strings.Split(resp.Data, "\n")
+	setResult(map[string]interface{}{"added": added})
+	say("Added %d tasks: %s\n", len(added), strings.Join(added, ", "))
+	return nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+	"github.com/urfave/cli/v2"
+)
+
+// claudeResult is the part of the claude CLI's result message, printed
+// with --output-format json or stream-json, that carries its usage
+type claudeResult struct {
+	Type         string  `json:"type"`
+	TotalCostUSD float64 `json:"total_cost_usd"`
+	Usage        struct {
+		InputTokens              int64 `json:"input_tokens"`
+		OutputTokens             int64 `json:"output_tokens"`
+		CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+		CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+	} `json:"usage"`
+}
+
+// reportUsage tells the orchestrator how many tokens the agent spent,
+// from flags or from the claude CLI's JSON output
+func reportUsage(c *cli.Context) error {
+	agentDir := os.Getenv("SWARM_AGENT_DIR")
+	if agentDir == "" {
+		return fmt.Errorf("SWARM_AGENT_DIR environment variable not set")
+	}
+
+	usage := workflow.TokenUsage{
+		InputTokens:  c.Int64("input"),
+		OutputTokens: c.Int64("output"),
+		CostUSD:      c.Float64("cost"),
+	}
+	if path := c.String("claude-json"); path != "" {
+		parsed, err := readClaudeUsage(path)
+		if err != nil {
+			return err
+		}
+		usage = parsed
+	}
+	if usage.Tokens() == 0 && usage.CostUSD == 0 {
+		return fmt.Errorf("no usage to report (use --input and --output, or --claude-json)")
+	}
+
+	resp, err := sendMessage(agentDir, workflow.Message{
+		Type:  workflow.MessageTypeUsage,
+		Usage: &usage,
+	}, 30*time.Second)
+	if err != nil {
+		return err
+	}
+
+	if resp.Status == "error" {
+		return errorFromResponse(resp)
+	}
+
+	setResult(map[string]interface{}{"usage": resp.Data})
+	say("Usage so far: %s\n", resp.Data)
+	return nil
+}
+
+// readClaudeUsage reads the usage from the claude CLI's output: a JSON
+// result, or stream-json lines whose last result counts. - reads standard
+// input.
+func readClaudeUsage(path string) (workflow.TokenUsage, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return workflow.TokenUsage{}, fmt.Errorf("failed to read claude output: %w", err)
+	}
+
+	var result *claudeResult
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var line claudeResult
+		if json.Unmarshal(scanner.Bytes(), &line) == nil && line.Type == "result" {
+			result = &line
+		}
+	}
+	if result == nil {
+		var whole claudeResult
+		if json.Unmarshal(data, &whole) == nil && whole.Type == "result" {
+			result = &whole
+		}
+	}
+	if result == nil {
+		return workflow.TokenUsage{}, fmt.Errorf("no result with usage in claude output (run claude with --output-format json)")
+	}
+
+	return workflow.TokenUsage{
+		InputTokens:  result.Usage.InputTokens + result.Usage.CacheCreationInputTokens + result.Usage.CacheReadInputTokens,
+		OutputTokens: result.Usage.OutputTokens,
+		CostUSD:      result.TotalCostUSD,
+	}, nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aristath/claude-swarm/internal/agenttype"
+	"github.com/urfave/cli/v2"
+)
+
+// listAgentTypes prints the agent types runs use, where each is defined
+// and the definitions it overrides
+func listAgentTypes(c *cli.Context) error {
+	library, err := agenttype.Open(agenttype.Dir())
+	if err != nil {
+		return err
+	}
+
+	entries := library.Entries()
+	if len(entries) == 0 {
+		fmt.Printf("No agent types defined; import a pack or define them in %s\n",
+			filepath.Join(agenttype.Dir(), agenttype.LocalFile))
+		return nil
+	}
+
+	for _, entry := range entries {
+		model := entry.Model
+		if model == "" {
+			model = "default model"
+		}
+		fmt.Printf("%-20s %-16s %s\n", entry.Name, model, entry.Source)
+		if entry.Description != "" {
+			fmt.Printf("    %s\n", entry.Description)
+		}
+		if len(entry.Shadowed) > 0 {
+			fmt.Printf("    overrides %s\n", strings.Join(entry.Shadowed, ", "))
+		}
+	}
+	return nil
+}
+
+// exportAgentTypes bundles agent types, as runs see them, into a pack
+func exportAgentTypes(c *cli.Context) error {
+	library, err := agenttype.Open(agenttype.Dir())
+	if err != nil {
+		return err
+	}
+
+	pack, err := library.Export(c.String("name"), c.String("version"), c.String("description"), c.Args().Slice())
+	if err != nil {
+		return err
+	}
+	data, err := pack.Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to encode pack: %w", err)
+	}
+
+	output := c.String("output")
+	if output == "" {
+		fmt.Print(string(data))
+		return nil
+	}
+	if err := os.WriteFile(output, data, 0644); err != nil {
+		return fmt.Errorf("failed to write pack: %w", err)
+	}
+	fmt.Printf("Exported %d agent types to %s (%s@%s)\n", len(pack.Types), output, pack.Name, pack.Version)
+	return nil
+}
+
+// importAgentTypes installs or upgrades a pack, reporting the types other
+// sources also define
+func importAgentTypes(c *cli.Context) error {
+	if c.Args().Len() != 1 {
+		return fmt.Errorf("usage: swarm agents import [--force] <pack-file>")
+	}
+	pack, err := agenttype.ReadPack(c.Args().First())
+	if err != nil {
+		return err
+	}
+	library, err := agenttype.Open(agenttype.Dir())
+	if err != nil {
+		return err
+	}
+
+	result, err := library.Import(pack, c.Bool("force"))
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case result.Unchanged:
+		fmt.Printf("%s@%s is already installed\n", pack.Name, pack.Version)
+		return nil
+	case result.Previous != "":
+		fmt.Printf("Upgraded %s from %s to %s (%d agent types)\n", pack.Name, result.Previous, pack.Version, len(pack.Types))
+	default:
+		fmt.Printf("Installed %s@%s (%d agent types)\n", pack.Name, pack.Version, len(pack.Types))
+	}
+	for _, conflict := range result.Conflicts {
+		fmt.Printf("  %s is also defined by %s\n", conflict.Type, conflict.Source)
+	}
+	for _, name := range result.Overridden {
+		fmt.Printf("  %s is overridden by its local definition\n", name)
+	}
+	return nil
+}
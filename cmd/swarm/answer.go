@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/aristath/claude-swarm/internal/orchestrator"
+	"github.com/aristath/claude-swarm/internal/workflow"
+	"github.com/urfave/cli/v2"
+)
+
+// answerQuestion approves or edits an answer the orchestrator drafted for
+// review, or lists the drafts awaiting review without arguments
+func answerQuestion(c *cli.Context) error {
+	session := c.String("session")
+	if session == "" {
+		latest, err := latestSession()
+		if err != nil {
+			return err
+		}
+		session = latest
+	}
+	swarmDir, err := resolveSessionDir(session)
+	if err != nil {
+		return err
+	}
+
+	if c.Args().Len() == 0 {
+		return listDrafts(c, swarmDir)
+	}
+	if c.Args().Len() < 2 {
+		return fmt.Errorf("task ID and question ID are required")
+	}
+	taskID := c.Args().Get(0)
+	qID, err := strconv.Atoi(strings.TrimPrefix(c.Args().Get(1), "q-"))
+	if err != nil {
+		return fmt.Errorf("invalid question ID %q", c.Args().Get(1))
+	}
+
+	draft, err := orchestrator.FindDraft(swarmDir, taskID, qID)
+	if err != nil {
+		return err
+	}
+
+	answer := c.String("text")
+	if c.Bool("edit") {
+		if answer, err = editDraft(draft); err != nil {
+			return err
+		}
+		if answer == strings.TrimSpace(draft.Answer) {
+			answer = ""
+		}
+	}
+
+	err = orchestrator.SendControlRequest(swarmDir, workflow.ControlRequest{
+		Action:   workflow.ControlAnswer,
+		TaskID:   taskID,
+		Question: qID,
+		Answer:   answer,
+	})
+	if err != nil {
+		return err
+	}
+
+	if answer == "" {
+		fmt.Printf("Draft approved for question %d of task %s\n", qID, taskID)
+	} else {
+		fmt.Printf("Answer sent for question %d of task %s\n", qID, taskID)
+	}
+	return nil
+}
+
+// listDrafts prints the drafted answers awaiting review
+func listDrafts(c *cli.Context, swarmDir string) error {
+	drafts, err := orchestrator.PendingDrafts(swarmDir)
+	if err != nil {
+		return err
+	}
+
+	if c.Bool("json") {
+		if drafts == nil {
+			drafts = []orchestrator.Draft{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(drafts)
+	}
+	if len(drafts) == 0 {
+		fmt.Printf("No answers await review in %s\n", swarmDir)
+		return nil
+	}
+	for _, draft := range drafts {
+		fmt.Printf("%s %d: %s\n", draft.TaskID, draft.QuestionID, draft.Question)
+		fmt.Printf("  Draft: %s\n\n", strings.ReplaceAll(strings.TrimSpace(draft.Answer), "\n", "\n         "))
+	}
+	fmt.Printf("Approve a draft with: swarm answer [--edit | --text ...] <task-id> <question-id>\n")
+	return nil
+}
+
+// editDraft opens the draft in $EDITOR, after the question, and returns
+// the edited answer
+func editDraft(draft *orchestrator.Draft) (string, error) {
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+
+	file, err := os.CreateTemp("", "swarm-answer-*.md")
+	if err != nil {
+		return "", fmt.Errorf("failed to create answer file: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	var header strings.Builder
+	for _, line := range strings.Split(draft.Question, "\n") {
+		header.WriteString("# " + line + "\n")
+	}
+	header.WriteString("# Lines starting with # are ignored. An empty answer aborts.\n")
+	if _, err := file.WriteString(header.String() + draft.Answer); err != nil {
+		file.Close()
+		return "", fmt.Errorf("failed to write answer file: %w", err)
+	}
+	file.Close()
+
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, editor, file.Name())
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor failed: %w", err)
+	}
+
+	data, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", fmt.Errorf("failed to read answer file: %w", err)
+	}
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	answer := strings.TrimSpace(strings.Join(lines, "\n"))
+	if answer == "" {
+		return "", fmt.Errorf("empty answer, nothing sent")
+	}
+	return answer, nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/aristath/claude-swarm/internal/orchestrator"
+	"github.com/urfave/cli/v2"
+)
+
+// printAudit lists the operator actions of a session, oldest first
+func printAudit(c *cli.Context) error {
+	session := c.Args().Get(0)
+	if session == "" {
+		latest, err := latestSession()
+		if err != nil {
+			return err
+		}
+		session = latest
+	}
+	swarmDir, err := resolveSessionDir(session)
+	if err != nil {
+		return err
+	}
+
+	entries, err := orchestrator.LoadAudit(swarmDir)
+	if err != nil {
+		return err
+	}
+
+	if c.Bool("json") {
+		if entries == nil {
+			entries = []orchestrator.AuditEntry{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(entries)
+	}
+	if len(entries) == 0 {
+		fmt.Printf("No operator actions in %s\n", swarmDir)
+		return nil
+	}
+	for _, entry := range entries {
+		line := fmt.Sprintf("%s  %-12s %s", entry.Time.Format("2006-01-02 15:04:05"), entry.Operator, entry.Action)
+		if entry.Task != "" {
+			line += " " + entry.Task
+		}
+		if entry.Detail != "" {
+			line += ": " + entry.Detail
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
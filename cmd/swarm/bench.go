@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/bench"
+	"github.com/urfave/cli/v2"
+)
+
+// runBench measures orchestration overhead with fake agents
+func runBench(c *cli.Context) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// The orchestrator logs every event; keep them out of the report
+	stdout := os.Stdout
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		return err
+	}
+	defer devNull.Close()
+	if !c.Bool("verbose") {
+		os.Stdout = devNull
+	}
+
+	fmt.Fprintf(os.Stderr, "Benchmarking orchestration overhead...\n")
+	result, err := bench.Run(ctx, bench.Options{
+		Tasks:    c.Int("tasks"),
+		Messages: c.Int("messages"),
+		Saves:    c.Int("saves"),
+	})
+	os.Stdout = stdout
+	if err != nil {
+		return fmt.Errorf("benchmark failed: %w", err)
+	}
+
+	if c.Bool("json") {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "Measurement\tCount\tMean\tP50\tP95\tMax\t\n")
+	for _, sample := range result.Samples {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\t\n",
+			sample.Name, sample.Count,
+			formatLatency(sample.Mean), formatLatency(sample.P50),
+			formatLatency(sample.P95), formatLatency(sample.Max))
+	}
+	w.Flush()
+
+	for _, note := range result.Notes {
+		fmt.Printf("\n%s\n", note)
+	}
+	return nil
+}
+
+// formatLatency formats a duration in microsecond precision
+func formatLatency(d time.Duration) string {
+	return d.Round(time.Microsecond).String()
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/aristath/claude-swarm/internal/changelog"
+	"github.com/aristath/claude-swarm/internal/state"
+	"github.com/urfave/cli/v2"
+)
+
+// writeChangelog writes a session's changelog from its state and the diffs
+// its orchestrator wrote as tasks finished. It can run while the session
+// does, covering the tasks finished so far.
+func writeChangelog(c *cli.Context) error {
+	session := c.String("session")
+	if session == "" {
+		latest, err := latestSession()
+		if err != nil {
+			return err
+		}
+		session = latest
+	}
+	swarmDir, err := resolveSessionDir(session)
+	if err != nil {
+		return err
+	}
+
+	swarmState, err := state.NewPersistence(swarmDir).Load()
+	if err != nil {
+		return fmt.Errorf("failed to load session state: %w", err)
+	}
+	root, _ := os.Getwd()
+
+	// For a pull request description, such as with gh pr create --body-file -
+	if c.String("output") == "-" {
+		fmt.Print(changelog.Markdown(swarmDir, swarmState, root, ""))
+		return nil
+	}
+
+	path, err := changelog.Write(swarmDir, swarmState, root, c.String("output"))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Changelog written to %s\n", path)
+	return nil
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/orchestrator"
+	"github.com/aristath/claude-swarm/internal/state"
+	"github.com/aristath/claude-swarm/internal/workflow"
+	"github.com/urfave/cli/v2"
+)
+
+// checkpointWait is how long to wait for a running orchestrator to write a
+// requested checkpoint
+const checkpointWait = 10 * time.Second
+
+// checkpointSession snapshots a session's state with an interim report
+// under the name given as argument, or lists its checkpoints without one.
+// A running orchestrator writes the checkpoint itself, so it is recorded
+// in the session's events.
+func checkpointSession(c *cli.Context) error {
+	session := c.String("session")
+	if session == "" {
+		latest, err := latestSession()
+		if err != nil {
+			return err
+		}
+		session = latest
+	}
+	swarmDir, err := resolveSessionDir(session)
+	if err != nil {
+		return err
+	}
+
+	name := strings.TrimSpace(strings.Join(c.Args().Slice(), " "))
+	if name == "" {
+		return listCheckpoints(swarmDir)
+	}
+
+	// The socket exists while an orchestrator runs the session
+	if _, err := os.Stat(filepath.Join(swarmDir, workflow.SocketFile)); err != nil {
+		return writeCheckpoint(swarmDir, name)
+	}
+
+	before, err := orchestrator.ListCheckpoints(swarmDir)
+	if err != nil {
+		return err
+	}
+	err = orchestrator.SendControlRequest(swarmDir, workflow.ControlRequest{
+		Action: workflow.ControlCheckpoint,
+		Name:   name,
+	})
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(checkpointWait)
+	for time.Now().Before(deadline) {
+		time.Sleep(200 * time.Millisecond)
+		checkpoints, err := orchestrator.ListCheckpoints(swarmDir)
+		if err != nil {
+			return err
+		}
+		if len(checkpoints) > len(before) {
+			printCheckpoint(checkpoints[len(checkpoints)-1])
+			return nil
+		}
+	}
+
+	fmt.Printf("Checkpoint %q requested; the orchestrator has not written it yet\n", name)
+	return nil
+}
+
+// writeCheckpoint checkpoints a session no orchestrator is running
+func writeCheckpoint(swarmDir, name string) error {
+	swarmState, err := state.NewPersistence(swarmDir).Load()
+	if err != nil {
+		return fmt.Errorf("failed to load session state: %w", err)
+	}
+
+	operator := workflow.CurrentOperator()
+	checkpoint, err := orchestrator.WriteCheckpoint(swarmDir, swarmState, name, operator)
+	if err != nil {
+		return err
+	}
+
+	err = orchestrator.AppendAudit(swarmDir, orchestrator.AuditEntry{
+		Time:     checkpoint.Time,
+		Operator: operator,
+		Action:   string(workflow.ControlCheckpoint),
+		Detail:   name,
+	})
+	if err != nil {
+		fmt.Printf("Failed to audit checkpoint: %v\n", err)
+	}
+
+	printCheckpoint(*checkpoint)
+	return nil
+}
+
+// listCheckpoints prints a session's checkpoints, oldest first
+func listCheckpoints(swarmDir string) error {
+	checkpoints, err := orchestrator.ListCheckpoints(swarmDir)
+	if err != nil {
+		return err
+	}
+	if len(checkpoints) == 0 {
+		fmt.Printf("No checkpoints in %s\n", swarmDir)
+		return nil
+	}
+
+	for _, checkpoint := range checkpoints {
+		fmt.Printf("%s  %-30s %d/%d completed, %d failed  %s\n",
+			checkpoint.Time.Format("2006-01-02 15:04"), checkpoint.Name,
+			checkpoint.Completed, checkpoint.Tasks, checkpoint.Failed, checkpoint.Dir)
+	}
+	return nil
+}
+
+// printCheckpoint reports a checkpoint just written
+func printCheckpoint(checkpoint orchestrator.Checkpoint) {
+	fmt.Printf("Checkpoint %q: %d of %d tasks completed, %d failed\n",
+		checkpoint.Name, checkpoint.Completed, checkpoint.Tasks, checkpoint.Failed)
+	fmt.Printf("Report: %s\n", filepath.Join(checkpoint.Dir, "report.md"))
+}
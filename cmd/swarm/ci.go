@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/orchestrator"
+	"github.com/aristath/claude-swarm/internal/report"
+	"github.com/aristath/claude-swarm/internal/state"
+	"github.com/urfave/cli/v2"
+)
+
+// Exit codes of swarm run
+const (
+	exitTasksFailed = 1
+	exitTimeout     = 2
+)
+
+// ciEvent is a line of CI mode's JSON output
+type ciEvent struct {
+	Type     string    `json:"type"`
+	Event    string    `json:"event"`
+	Task     string    `json:"task,omitempty"`
+	Path     string    `json:"path,omitempty"`
+	Operator string    `json:"operator,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// ciResult is the last line of CI mode's JSON output
+type ciResult struct {
+	Type string `json:"type"`
+	*report.Result
+}
+
+// streamEvents writes the session's events as JSON lines until the
+// returned function is called
+func streamEvents(swarmState *state.SwarmState, w io.Writer) func() {
+	events, cancel := swarmState.Subscribe(256)
+	finished := make(chan struct{})
+	encoder := json.NewEncoder(w)
+
+	go func() {
+		defer close(finished)
+		for event := range events {
+			encoder.Encode(ciEvent{
+				Type:     "event",
+				Event:    string(event.Type),
+				Task:     event.AgentID,
+				Path:     event.FilePath,
+				Operator: event.Operator,
+				Time:     event.Time,
+			})
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-finished
+	}
+}
+
+// runStatus classifies how orchestrator.Run ended
+func runStatus(err error) string {
+	switch {
+	case err == nil:
+		return report.StatusPassed
+	case errors.Is(err, context.DeadlineExceeded):
+		return report.StatusTimeout
+	case errors.Is(err, context.Canceled):
+		return report.StatusInterrupted
+	case errors.Is(err, orchestrator.ErrTasksFailed):
+		return report.StatusFailed
+	case errors.Is(err, orchestrator.ErrCancelled):
+		return report.StatusCancelled
+	}
+	return ""
+}
+
+// writeReports writes the JUnit results and Markdown summary requested on
+// the command line. In CI mode the summary defaults to the GitHub Actions
+// job summary, and the result is printed as the last JSON line.
+func writeReports(c *cli.Context, result *report.Result, jsonOut io.Writer) error {
+	if path := c.String("junit"); path != "" {
+		if err := result.WriteJUnit(path); err != nil {
+			return err
+		}
+	}
+
+	summary := c.String("summary")
+	if summary == "" && c.Bool("ci") {
+		summary = os.Getenv("GITHUB_STEP_SUMMARY")
+	}
+	if summary != "" {
+		if err := result.WriteMarkdown(summary); err != nil {
+			return err
+		}
+	}
+
+	if jsonOut != nil {
+		if err := json.NewEncoder(jsonOut).Encode(ciResult{Type: "result", Result: result}); err != nil {
+			return fmt.Errorf("failed to write result: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// exitError turns the outcome of a run into the command's exit status
+func exitError(status string, err error) error {
+	switch status {
+	case report.StatusPassed:
+		return nil
+	case report.StatusFailed:
+		return cli.Exit(fmt.Sprintf("Error: %v", err), exitTasksFailed)
+	case report.StatusTimeout:
+		return cli.Exit("Error: workflow timed out", exitTimeout)
+	case report.StatusInterrupted:
+		return cli.Exit("Error: workflow interrupted", exitTimeout)
+	case report.StatusCancelled:
+		return cli.Exit("Error: workflow cancelled", exitTimeout)
+	}
+	return fmt.Errorf("orchestration failed: %w", err)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aristath/claude-swarm/internal/diff"
+	"github.com/aristath/claude-swarm/internal/orchestrator"
+	"github.com/aristath/claude-swarm/internal/state"
+	"github.com/aristath/claude-swarm/internal/workflow"
+	"github.com/urfave/cli/v2"
+)
+
+// resolveFileConflict shows a file conflict given as argument, or resolves
+// it with --keep or --merged; without arguments it lists the open
+// conflicts. Resolutions are sent to the orchestrator running the session.
+func resolveFileConflict(c *cli.Context) error {
+	session := c.String("session")
+	if session == "" {
+		latest, err := latestSession()
+		if err != nil {
+			return err
+		}
+		session = latest
+	}
+	swarmDir, err := resolveSessionDir(session)
+	if err != nil {
+		return err
+	}
+
+	swarmState, err := state.NewPersistence(swarmDir).Load()
+	if err != nil {
+		return fmt.Errorf("failed to load session state: %w", err)
+	}
+
+	if c.Args().Len() == 0 {
+		return listConflicts(swarmState)
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(c.Args().Get(0), "#"))
+	if err != nil {
+		return fmt.Errorf("invalid conflict ID %q", c.Args().Get(0))
+	}
+	conflict, ok := swarmState.GetConflict(id)
+	if !ok {
+		return fmt.Errorf("conflict %d not found", id)
+	}
+
+	req := workflow.ControlRequest{Action: workflow.ControlResolve, Conflict: id}
+	switch keep := c.String("keep"); {
+	case keep != "" && c.String("merged") != "":
+		return fmt.Errorf("--keep and --merged are exclusive")
+	case keep == "first" || keep == conflict.First:
+		req.Resolution = state.ResolutionKeepFirst
+	case keep == "second" || keep == conflict.Second:
+		req.Resolution = state.ResolutionKeepSecond
+	case keep != "":
+		return fmt.Errorf("invalid --keep %q: use first, second or a task ID of the conflict", keep)
+	case c.String("merged") != "":
+		content, err := os.ReadFile(c.String("merged"))
+		if err != nil {
+			return fmt.Errorf("failed to read merged file: %w", err)
+		}
+		if strings.Contains(string(content), "<<<<<<< ") {
+			return fmt.Errorf("%s still has conflict markers", c.String("merged"))
+		}
+		req.Resolution = state.ResolutionMerged
+		req.Content = string(content)
+	default:
+		showConflict(conflict)
+		return nil
+	}
+
+	if !conflict.IsOpen() {
+		return fmt.Errorf("conflict %d was already resolved (%s)", id, conflict.Resolution)
+	}
+	if err := orchestrator.SendControlRequest(swarmDir, req); err != nil {
+		return err
+	}
+	fmt.Printf("Resolution %s sent for conflict %d on %s\n", req.Resolution, id, conflict.Path)
+	return nil
+}
+
+// listConflicts prints a session's open file conflicts, oldest first
+func listConflicts(swarmState *state.SwarmState) error {
+	open := swarmState.OpenConflicts()
+	if len(open) == 0 {
+		fmt.Println("No open file conflicts")
+		return nil
+	}
+
+	for _, conflict := range open {
+		fmt.Printf("#%d  %s  %s (first) and %s (second)  %s\n",
+			conflict.ID, conflict.DetectedAt.Format("15:04:05"),
+			conflict.First, conflict.Second, conflict.Path)
+	}
+	return nil
+}
+
+// showConflict prints a conflict as a three-way view: each task's change
+// to the base, and their merge with conflict markers where they collide
+func showConflict(conflict state.Conflict) {
+	fmt.Printf("# Conflict %d on %s\n", conflict.ID, conflict.Path)
+	if !conflict.IsOpen() {
+		fmt.Printf("Resolved by %s: %s\n", conflict.ResolvedBy, conflict.Resolution)
+	}
+
+	fmt.Printf("\n## First: %s\n%s", conflict.First,
+		diff.Unified("base", conflict.First, conflict.Base, conflict.FirstText))
+	fmt.Printf("\n## Second: %s\n%s", conflict.Second,
+		diff.Unified("base", conflict.Second, conflict.Base, conflict.SecondText))
+
+	merged, conflicted := diff.Merge3(conflict.Base, conflict.FirstText, conflict.SecondText, conflict.First, conflict.Second)
+	status := "merges cleanly"
+	if conflicted {
+		status = "needs a manual merge"
+	}
+	fmt.Printf("\n## Merge (%s)\n%s", status, merged)
+	if !strings.HasSuffix(merged, "\n") {
+		fmt.Println()
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/aristath/claude-swarm/internal/orchestrator"
+	"github.com/aristath/claude-swarm/internal/state"
+	"github.com/urfave/cli/v2"
+)
+
+// printContext prints the spawn prompt and context a task's agent was
+// given, to see what it was working from
+func printContext(c *cli.Context) error {
+	if c.Args().Len() < 1 {
+		return fmt.Errorf("task ID is required")
+	}
+	taskID := c.Args().Get(0)
+
+	session := c.String("session")
+	if session == "" {
+		latest, err := latestSession()
+		if err != nil {
+			return err
+		}
+		session = latest
+	}
+	swarmDir, err := resolveSessionDir(session)
+	if err != nil {
+		return err
+	}
+
+	swarmState, err := state.NewPersistence(swarmDir).Load()
+	if err != nil {
+		return fmt.Errorf("failed to load session: %w", err)
+	}
+
+	bundle, err := orchestrator.LoadContextBundle(swarmDir, swarmState, taskID, c.Bool("regenerate"))
+	if err != nil {
+		return err
+	}
+
+	if c.Bool("json") {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(bundle)
+	}
+
+	switch {
+	case bundle.Regenerated:
+		fmt.Fprintf(os.Stderr, "Context of %s regenerated from the session state\n", taskID)
+	case bundle.Stale:
+		fmt.Fprintf(os.Stderr, "Note: the session state now generates a different context for %s; showing the one its agent received (see --regenerate)\n", taskID)
+	}
+
+	if c.Bool("prompt") {
+		fmt.Print(bundle.Prompt)
+		fmt.Println()
+		return nil
+	}
+	fmt.Printf("===== Spawn prompt =====\n%s\n", bundle.SpawnPrompt)
+	fmt.Printf("===== context.txt =====\n%s", bundle.Context)
+	return nil
+}
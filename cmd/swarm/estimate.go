@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/aristath/claude-swarm/internal/estimate"
+	"github.com/aristath/claude-swarm/internal/workflow"
+	"github.com/urfave/cli/v2"
+)
+
+// estimateWorkflow prints a rough cost and time estimate for a workflow,
+// going by past sessions
+func estimateWorkflow(c *cli.Context) error {
+	wf, err := workflow.NewParser().ParseFile(c.String("workflow"))
+	if err != nil {
+		return fmt.Errorf("failed to parse workflow: %w", err)
+	}
+	params, err := workflow.ParseParams(c.StringSlice("param"))
+	if err != nil {
+		return err
+	}
+	if err := wf.SetParams(params); err != nil {
+		return err
+	}
+
+	history, err := estimate.LoadHistory(filepath.Join(os.Getenv("HOME"), ".claude-swarm"))
+	if err != nil {
+		return fmt.Errorf("failed to load history: %w", err)
+	}
+	estimator := estimate.NewEstimator(history)
+	estimator.USDPerMillionTokens = c.Float64("usd-per-mtok")
+	result := estimator.Estimate(wf)
+
+	if c.Bool("json") {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+	fmt.Print(result)
+	return nil
+}
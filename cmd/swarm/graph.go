@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+	"github.com/urfave/cli/v2"
+)
+
+// graphWorkflow prints a workflow's dependency graph, as text or DOT.
+// With --outputs, edges show which placeholders carry the dependency's
+// output, and edges whose output is never referenced are flagged.
+func graphWorkflow(c *cli.Context) error {
+	wf, err := workflow.NewParser().ParseFile(c.String("workflow"))
+	if err != nil {
+		return fmt.Errorf("failed to parse workflow: %w", err)
+	}
+	params, err := workflow.ParseParams(c.StringSlice("param"))
+	if err != nil {
+		return err
+	}
+	if err := wf.SetParams(params); err != nil {
+		return err
+	}
+
+	edges := wf.Edges()
+	switch format := c.String("format"); format {
+	case "text":
+		fmt.Print(textGraph(wf, edges, c.Bool("outputs")))
+	case "dot":
+		fmt.Print(dotGraph(wf, edges, c.Bool("outputs")))
+	default:
+		return fmt.Errorf("unknown format %q (want text or dot)", format)
+	}
+	return nil
+}
+
+// textGraph lists each task with the tasks it depends on
+func textGraph(wf *workflow.Workflow, edges []workflow.Edge, outputs bool) string {
+	deps := make(map[string][]string)
+	var unreferenced []workflow.Edge
+	for _, edge := range edges {
+		dep := edge.From
+		if outputs {
+			dep += " " + edgeLabel(edge)
+			if edge.Unreferenced() {
+				unreferenced = append(unreferenced, edge)
+			}
+		}
+		deps[edge.To] = append(deps[edge.To], dep)
+	}
+
+	width := 0
+	for _, task := range wf.Tasks {
+		width = max(width, len(task.ID))
+	}
+
+	var b strings.Builder
+	for _, task := range wf.Tasks {
+		if len(deps[task.ID]) == 0 {
+			fmt.Fprintf(&b, "%s\n", task.ID)
+			continue
+		}
+		fmt.Fprintf(&b, "%-*s  ← %s\n", width, task.ID, strings.Join(deps[task.ID], ", "))
+	}
+
+	if len(unreferenced) > 0 {
+		noun := "dependency's output is"
+		if len(unreferenced) > 1 {
+			noun = "dependencies' outputs are"
+		}
+		fmt.Fprintf(&b, "\n%d %s never referenced; drop the dependency unless the task needs the ordering:\n", len(unreferenced), noun)
+		for _, edge := range unreferenced {
+			fmt.Fprintf(&b, "  %s → %s\n", edge.From, edge.To)
+		}
+	}
+	return b.String()
+}
+
+// dotGraph renders the graph for Graphviz; unreferenced edges are dashed
+func dotGraph(wf *workflow.Workflow, edges []workflow.Edge, outputs bool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %q {\n", wf.Name)
+	b.WriteString("  rankdir=LR;\n")
+	for _, task := range wf.Tasks {
+		fmt.Fprintf(&b, "  %q;\n", task.ID)
+	}
+	for _, edge := range edges {
+		switch {
+		case !outputs:
+			fmt.Fprintf(&b, "  %q -> %q;\n", edge.From, edge.To)
+		case edge.Unreferenced():
+			fmt.Fprintf(&b, "  %q -> %q [label=%q, style=dashed, color=gray];\n", edge.From, edge.To, edgeLabel(edge))
+		default:
+			fmt.Fprintf(&b, "  %q -> %q [label=%q];\n", edge.From, edge.To, edgeLabel(edge))
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// edgeLabel describes how a dependent task uses its dependency's output
+func edgeLabel(edge workflow.Edge) string {
+	switch {
+	case edge.Joined:
+		return "(joined)"
+	case edge.Unreferenced():
+		return "(output unused)"
+	}
+	return strings.Join(edge.Placeholders, " ")
+}
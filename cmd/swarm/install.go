@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/aristath/claude-swarm/internal/orchestrator"
+	"github.com/aristath/claude-swarm/internal/version"
+	"github.com/urfave/cli/v2"
+)
+
+func installAgent(c *cli.Context) error {
+	target := c.String("target")
+	if target == "" {
+		target = runtime.GOOS + "/" + runtime.GOARCH
+	}
+	goos, goarch, ok := strings.Cut(target, "/")
+	if !ok || goos == "" || goarch == "" {
+		return fmt.Errorf("invalid target %q, expected os/arch such as linux/amd64", target)
+	}
+	native := goos == runtime.GOOS && goarch == runtime.GOARCH
+
+	destDir := c.String("dir")
+	if destDir == "" {
+		destDir = orchestrator.DefaultAgentBinDir()
+	}
+	destDir, err := filepath.Abs(destDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve install directory: %w", err)
+	}
+
+	binaryName := "swarm-agent"
+	if goos == "windows" {
+		binaryName += ".exe"
+	}
+	dest := filepath.Join(destDir, binaryName)
+
+	source, err := findAgentBinary(goos, goarch, native, dest)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return fmt.Errorf("failed to create install directory: %w", err)
+	}
+	if err := copyExecutable(source, dest); err != nil {
+		return err
+	}
+
+	fmt.Printf("Installed %s (%s) to %s\n", source, target, dest)
+
+	// Binaries for other platforms cannot be run here to verify them
+	if !native {
+		fmt.Printf("Not verified: %s binaries cannot run on this host. Check with 'swarm-agent version' on the target.\n", target)
+		return nil
+	}
+
+	installed, err := agentVersion(dest)
+	if err != nil {
+		return err
+	}
+
+	current := version.Current()
+	if err := installed.CheckCompatible(current); err != nil {
+		return fmt.Errorf("installed swarm-agent is %s but swarm is %s: protocol mismatch", installed, current)
+	}
+	if installed.Version != current.Version {
+		fmt.Printf("Warning: swarm-agent %s and swarm %s are different builds of the same protocol\n", installed.Version, current.Version)
+	}
+
+	fmt.Printf("Verified swarm-agent %s\n", installed)
+
+	if !onPath(destDir) {
+		fmt.Printf("\n%s is not on your PATH. Agents get it through env.sh; to use it yourself:\n", destDir)
+		fmt.Printf("  export PATH=%s:$PATH\n", destDir)
+	}
+
+	return nil
+}
+
+// findAgentBinary locates a swarm-agent binary for a platform. Release
+// archives ship swarm-agent-<os>-<arch> binaries next to swarm; for the host
+// platform a plain swarm-agent next to swarm or on PATH is used too.
+func findAgentBinary(goos, goarch string, native bool, dest string) (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate swarm executable: %w", err)
+	}
+	exeDir := filepath.Dir(exe)
+
+	suffix := ""
+	if goos == "windows" {
+		suffix = ".exe"
+	}
+
+	candidates := []string{
+		filepath.Join(exeDir, fmt.Sprintf("swarm-agent-%s-%s%s", goos, goarch, suffix)),
+	}
+	if native {
+		candidates = append(candidates, filepath.Join(exeDir, "swarm-agent"+suffix))
+		if path, err := exec.LookPath("swarm-agent"); err == nil {
+			candidates = append(candidates, path)
+		}
+	}
+
+	for _, candidate := range candidates {
+		abs, err := filepath.Abs(candidate)
+		if err != nil || abs == dest {
+			continue
+		}
+		if info, err := os.Stat(abs); err == nil && !info.IsDir() {
+			return abs, nil
+		}
+	}
+
+	return "", fmt.Errorf("no swarm-agent binary for %s/%s found next to %s; build one with: GOOS=%s GOARCH=%s go build -o %s ./cmd/agent",
+		goos, goarch, exe, goos, goarch, filepath.Join(exeDir, fmt.Sprintf("swarm-agent-%s-%s%s", goos, goarch, suffix)))
+}
+
+// copyExecutable copies a binary into place via a temporary file
+func copyExecutable(source, dest string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", source, err)
+	}
+	defer in.Close()
+
+	tmpFile := filepath.Join(filepath.Dir(dest), "."+filepath.Base(dest)+".tmp")
+	out, err := os.OpenFile(tmpFile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", tmpFile, err)
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(tmpFile)
+		return fmt.Errorf("failed to copy swarm-agent: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmpFile)
+		return fmt.Errorf("failed to copy swarm-agent: %w", err)
+	}
+
+	if err := os.Rename(tmpFile, dest); err != nil {
+		os.Remove(tmpFile)
+		return fmt.Errorf("failed to install swarm-agent: %w", err)
+	}
+
+	return nil
+}
+
+// agentVersion runs a swarm-agent binary to ask for its version
+func agentVersion(path string) (version.Info, error) {
+	var info version.Info
+
+	output, err := exec.Command(path, "version", "--json").Output()
+	if err != nil {
+		return info, fmt.Errorf("failed to run %s version: %w (is it older than the version handshake?)", path, err)
+	}
+
+	if err := json.Unmarshal(output, &info); err != nil {
+		return info, fmt.Errorf("failed to parse swarm-agent version: %w", err)
+	}
+
+	return info, nil
+}
+
+// onPath reports whether dir is in the PATH environment variable
+func onPath(dir string) bool {
+	for _, entry := range filepath.SplitList(os.Getenv("PATH")) {
+		if filepath.Clean(entry) == dir {
+			return true
+		}
+	}
+	return false
+}
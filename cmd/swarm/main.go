@@ -1,26 +1,87 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
 	"time"
 
+	"github.com/aristath/claude-swarm/internal/agenttype"
+	"github.com/aristath/claude-swarm/internal/bench"
+	"github.com/aristath/claude-swarm/internal/estimate"
+	"github.com/aristath/claude-swarm/internal/memory"
 	"github.com/aristath/claude-swarm/internal/orchestrator"
+	"github.com/aristath/claude-swarm/internal/report"
 	"github.com/aristath/claude-swarm/internal/state"
 	"github.com/aristath/claude-swarm/internal/tui"
+	"github.com/aristath/claude-swarm/internal/version"
 	"github.com/aristath/claude-swarm/internal/workflow"
 	"github.com/urfave/cli/v2"
 )
 
 func main() {
 	app := &cli.App{
-		Name:  "swarm",
-		Usage: "Claude Swarm orchestrator",
+		Name:    "swarm",
+		Usage:   "Claude Swarm orchestrator",
+		Version: version.Current().String(),
+		// Parameter values may contain commas
+		DisableSliceFlagSeparator: true,
 		Commands: []*cli.Command{
 			{
-				Name:   "init",
-				Usage:  "Initialize a new swarm session",
+				Name:  "init",
+				Usage: "Initialize a new swarm session",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "pprof",
+						Usage: "Serve net/http/pprof under /debug/pprof/ on the API server",
+					},
+					&cli.StringSliceFlag{
+						Name:  "cors-origin",
+						Usage: "Let web pages from this origin call the API server, such as https://portal.example.com, or * for any (repeatable)",
+					},
+					&cli.BoolFlag{
+						Name:  "embed",
+						Usage: "Serve the status endpoints without the API token and let the CORS origins frame the status page",
+					},
+					&cli.DurationFlag{
+						Name:  "summarize-every",
+						Usage: "Periodically condense the planning discussion into plan.md instead of saving messages verbatim (e.g. 2m)",
+					},
+					&cli.DurationFlag{
+						Name:  "timebox",
+						Usage: "End the planning discussion after this long (e.g. 30m)",
+					},
+					&cli.BoolFlag{
+						Name:  "spawn",
+						Usage: "Spawn agents as processes with --agent-command instead of printing spawn prompts",
+					},
+					&cli.StringFlag{
+						Name:  "agent-command",
+						Value: orchestrator.DefaultAgentCommand,
+						Usage: "Command spawning an agent; {prompt}, {task}, {agent_type}, {dir} and {settings} are replaced by shell-quoted values",
+					},
+					&cli.StringFlag{
+						Name:  "summary-command",
+						Usage: "Command summarizing long dependency outputs, read on stdin, such as \"" + orchestrator.DefaultSummaryCommand + "\"; {prompt} is replaced by the instructions (without one they are excerpted)",
+					},
+					&cli.BoolFlag{
+						Name:  "no-memory",
+						Usage: "Neither read nor update the project memory under ~/.claude-swarm/projects/",
+					},
+					&cli.BoolFlag{
+						Name:  "review-answers",
+						Usage: "Queue the answers the orchestrator drafts for review in the orchestration view before agents get them",
+					},
+					&cli.BoolFlag{
+						Name:    "a11y",
+						Usage:   "Render the TUI for screen readers: plain text instead of borders, progress bars and symbols, and new events announced line by line",
+						EnvVars: []string{"SWARM_A11Y"},
+					},
+				},
 				Action: initSession,
 			},
 			{
@@ -36,9 +97,530 @@ func main() {
 						Name:  "plan",
 						Usage: "Path to plan.md file",
 					},
+					&cli.BoolFlag{
+						Name:  "spawn",
+						Usage: "Spawn agents as processes with --agent-command instead of printing spawn prompts",
+					},
+					&cli.StringFlag{
+						Name:  "agent-command",
+						Value: orchestrator.DefaultAgentCommand,
+						Usage: "Command spawning an agent; {prompt}, {task}, {agent_type}, {dir} and {settings} are replaced by shell-quoted values",
+					},
+					&cli.StringFlag{
+						Name:  "summary-command",
+						Usage: "Command summarizing long dependency outputs, read on stdin, such as \"" + orchestrator.DefaultSummaryCommand + "\"; {prompt} is replaced by the instructions (without one they are excerpted)",
+					},
+					&cli.BoolFlag{
+						Name:  "no-memory",
+						Usage: "Neither read nor update the project memory under ~/.claude-swarm/projects/",
+					},
+					&cli.BoolFlag{
+						Name:  "fake-agents",
+						Usage: "Spawn simulated agents that complete immediately with canned outputs",
+					},
+					&cli.StringFlag{
+						Name:  "fake-script",
+						Usage: "Path to a YAML script with outputs, questions, delays and failures for fake agents",
+					},
+					&cli.StringFlag{
+						Name:  "record",
+						Usage: "Record answers and operation responses to this file",
+					},
+					&cli.StringFlag{
+						Name:  "replay",
+						Usage: "Replay answers and operation responses from a recording",
+					},
+					&cli.BoolFlag{
+						Name:  "read-only",
+						Usage: "Record writes and edits as proposals instead of applying them, and reject bash commands with side effects",
+					},
+					&cli.BoolFlag{
+						Name:  "review-answers",
+						Usage: "Queue the answers the orchestrator drafts for review with swarm answer before agents get them",
+					},
+					&cli.StringFlag{
+						Name:  "failure-policy",
+						Usage: "What happens when a task fails: halt, continue_independent or ignore (overrides the workflow's failure_policy)",
+					},
+					&cli.StringSliceFlag{
+						Name:  "param",
+						Usage: "Override a workflow parameter as key=value (repeatable)",
+					},
+					&cli.StringFlag{
+						Name:  "answers",
+						Usage: "File of canned answers to questions",
+						Value: workflow.UserAnswersFile(),
+					},
+					&cli.BoolFlag{
+						Name:  "ci",
+						Usage: "Run as a CI job: print events and the result as JSON lines on stdout, logs on stderr, and exit non-zero if a task fails",
+					},
+					&cli.DurationFlag{
+						Name:  "timeout",
+						Usage: "Stop the run after this long, e.g. 30m (0 means no limit)",
+					},
+					&cli.StringFlag{
+						Name:  "junit",
+						Usage: "Write JUnit XML results to this file",
+					},
+					&cli.StringFlag{
+						Name:  "summary",
+						Usage: "Append a Markdown summary to this file (with --ci, default $GITHUB_STEP_SUMMARY)",
+					},
+					&cli.DurationFlag{
+						Name:  "tick",
+						Usage: "How often to check for work when no event arrives (events schedule tasks immediately)",
+						Value: orchestrator.DefaultTickInterval,
+					},
+					&cli.DurationFlag{
+						Name:  "poll",
+						Usage: "How often to scan for files the file watcher missed, as on NFS or Docker volumes (0 turns polling off)",
+						Value: orchestrator.DefaultPollInterval,
+					},
+					&cli.StringFlag{
+						Name:  "cpuprofile",
+						Usage: "Write a CPU profile of the run to this file",
+					},
+					&cli.StringFlag{
+						Name:  "memprofile",
+						Usage: "Write a heap profile to this file when the run ends",
+					},
 				},
 				Action: runWorkflow,
 			},
+			{
+				Name:  "estimate",
+				Usage: "Estimate a workflow's cost and duration from prompt sizes and past sessions",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "workflow",
+						Usage:    "Path to workflow.yaml file",
+						Required: true,
+					},
+					&cli.StringSliceFlag{
+						Name:  "param",
+						Usage: "Override a workflow parameter as key=value (repeatable)",
+					},
+					&cli.Float64Flag{
+						Name:  "usd-per-mtok",
+						Value: estimate.DefaultUSDPerMillionTokens,
+						Usage: "Price per million tokens, input and output blended",
+					},
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "Print the estimate as JSON",
+					},
+				},
+				Action: estimateWorkflow,
+			},
+			{
+				Name:  "graph",
+				Usage: "Print a workflow's dependency graph",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "workflow",
+						Usage:    "Path to workflow.yaml file",
+						Required: true,
+					},
+					&cli.StringSliceFlag{
+						Name:  "param",
+						Usage: "Override a workflow parameter as key=value (repeatable)",
+					},
+					&cli.BoolFlag{
+						Name:  "outputs",
+						Usage: "Show which output placeholders each task uses, and flag dependencies whose output is never referenced",
+					},
+					&cli.StringFlag{
+						Name:  "format",
+						Value: "text",
+						Usage: "Output format: text or dot (Graphviz)",
+					},
+				},
+				Action: graphWorkflow,
+			},
+			{
+				Name:      "resume",
+				Usage:     "Resume an interrupted session, running only the tasks that have not finished",
+				ArgsUsage: "<session>",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "spawn",
+						Usage: "Spawn agents as processes with --agent-command instead of printing spawn prompts",
+					},
+					&cli.StringFlag{
+						Name:  "agent-command",
+						Value: orchestrator.DefaultAgentCommand,
+						Usage: "Command spawning an agent; {prompt}, {task}, {agent_type}, {dir} and {settings} are replaced by shell-quoted values",
+					},
+					&cli.StringFlag{
+						Name:  "summary-command",
+						Usage: "Command summarizing long dependency outputs, read on stdin, such as \"" + orchestrator.DefaultSummaryCommand + "\"; {prompt} is replaced by the instructions (without one they are excerpted)",
+					},
+					&cli.BoolFlag{
+						Name:  "no-memory",
+						Usage: "Neither read nor update the project memory under ~/.claude-swarm/projects/",
+					},
+					&cli.BoolFlag{
+						Name:  "fake-agents",
+						Usage: "Spawn simulated agents that complete immediately with canned outputs",
+					},
+					&cli.StringFlag{
+						Name:  "fake-script",
+						Usage: "Path to a YAML script with outputs, questions, delays and failures for fake agents",
+					},
+					&cli.DurationFlag{
+						Name:  "timeout",
+						Usage: "Stop the run after this long, e.g. 30m (0 means no limit)",
+					},
+					&cli.DurationFlag{
+						Name:  "tick",
+						Usage: "How often to check for work when no event arrives (events schedule tasks immediately)",
+						Value: orchestrator.DefaultTickInterval,
+					},
+					&cli.DurationFlag{
+						Name:  "poll",
+						Usage: "How often to scan for files the file watcher missed, as on NFS or Docker volumes (0 turns polling off)",
+						Value: orchestrator.DefaultPollInterval,
+					},
+					&cli.StringSliceFlag{
+						Name:  "rerun",
+						Usage: "Run this failed task again from scratch (repeatable)",
+					},
+					&cli.StringSliceFlag{
+						Name:  "skip",
+						Usage: "Skip this pending or failed task, so its dependents run with an empty output (repeatable)",
+					},
+				},
+				Action: resumeSession,
+			},
+			{
+				Name:      "search",
+				Usage:     "Search a session's task outputs, questions and answers, and agent transcripts",
+				ArgsUsage: "<query>",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "session",
+						Usage: "Session ID or directory to search (default: the session in the current directory, or the most recent one)",
+					},
+					&cli.StringFlag{
+						Name:  "task",
+						Usage: "Only search this task",
+					},
+					&cli.BoolFlag{
+						Name:  "regexp",
+						Usage: "Treat the query as a regular expression instead of a case-insensitive substring",
+					},
+					&cli.IntFlag{
+						Name:  "limit",
+						Value: 100,
+						Usage: "Maximum number of matches to list (0 means no limit)",
+					},
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "Print the matches as JSON",
+					},
+				},
+				Action: searchSession,
+			},
+			{
+				Name:      "context",
+				Usage:     "Print the spawn prompt and context a task's agent received, for debugging",
+				ArgsUsage: "<task-id>",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "session",
+						Usage: "Session ID or directory (default: the session in the current directory, or the most recent one)",
+					},
+					&cli.BoolFlag{
+						Name:  "regenerate",
+						Usage: "Generate the context from the session state even if the agent's context.txt exists",
+					},
+					&cli.BoolFlag{
+						Name:  "prompt",
+						Usage: "Print only the task prompt, after interpolation",
+					},
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "Print the prompts and context as JSON",
+					},
+				},
+				Action: printContext,
+			},
+			{
+				Name:      "cancel",
+				Usage:     "Cancel a running session: stop spawning agents and mark the remaining tasks cancelled",
+				ArgsUsage: "<session>",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "signal",
+						Usage: "Also interrupt the agents spawned as processes",
+					},
+				},
+				Action: cancelSession,
+			},
+			{
+				Name:      "approve",
+				Usage:     "Approve further operations for an agent paused by its quotas",
+				ArgsUsage: "<session> <task-id>",
+				Action:    approveQuota,
+			},
+			{
+				Name:  "task",
+				Usage: "Rerun or skip tasks of a running session",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "rerun",
+						Usage:     "Run a failed task again with a fresh agent directory (stopped sessions: swarm resume --rerun)",
+						ArgsUsage: "<task-id>",
+						Flags:     []cli.Flag{sessionFlag()},
+						Action:    rerunTask,
+					},
+					{
+						Name:      "skip",
+						Usage:     "Skip a pending or failed task, so its dependents run with an empty output (stopped sessions: swarm resume --skip)",
+						ArgsUsage: "<task-id>",
+						Flags:     []cli.Flag{sessionFlag()},
+						Action:    skipTask,
+					},
+				},
+			},
+			{
+				Name:      "answer",
+				Usage:     "Approve or edit an answer the orchestrator drafted for review; without arguments, list the drafts awaiting review",
+				ArgsUsage: "[task-id question-id]",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "session",
+						Usage: "Session ID or directory (default: the session in the current directory, or the most recent one)",
+					},
+					&cli.StringFlag{
+						Name:  "text",
+						Usage: "Answer with this text instead of the draft",
+					},
+					&cli.BoolFlag{
+						Name:  "edit",
+						Usage: "Edit the draft in $EDITOR before answering",
+					},
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "List the drafts as JSON",
+					},
+				},
+				Action: answerQuestion,
+			},
+			{
+				Name:      "checkpoint",
+				Usage:     "Snapshot a session's state and write an interim report under a name; without one, list the checkpoints",
+				ArgsUsage: "[name]",
+				Flags:     []cli.Flag{sessionFlag()},
+				Action:    checkpointSession,
+			},
+			{
+				Name:  "changelog",
+				Usage: "Write a session's changes as a changelog grouped by task, with links to each task's diff",
+				Flags: []cli.Flag{
+					sessionFlag(),
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "Where to write it, or - for stdout (default: CHANGELOG.md in the session directory)",
+					},
+				},
+				Action: writeChangelog,
+			},
+			{
+				Name:      "conflict",
+				Usage:     "Show or resolve a conflict between tasks that changed the same lines of a file; without arguments, list the open conflicts",
+				ArgsUsage: "[conflict-id]",
+				Flags: []cli.Flag{
+					sessionFlag(),
+					&cli.StringFlag{
+						Name:  "keep",
+						Usage: "Resolve by keeping the first or the second task's version",
+					},
+					&cli.StringFlag{
+						Name:  "merged",
+						Usage: "Resolve with the merged file at this path",
+					},
+				},
+				Action: resolveFileConflict,
+			},
+			{
+				Name:  "agents",
+				Usage: "Share agent type definitions as versioned packs",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "list",
+						Usage:  "List the agent types runs use and where each is defined",
+						Action: listAgentTypes,
+					},
+					{
+						Name:      "export",
+						Usage:     "Bundle agent types into a pack; without arguments, all of them",
+						ArgsUsage: "[type...]",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "name",
+								Usage:    "Name of the pack",
+								Required: true,
+							},
+							&cli.StringFlag{
+								Name:  "version",
+								Usage: "Version of the pack, as MAJOR.MINOR.PATCH",
+								Value: "1.0.0",
+							},
+							&cli.StringFlag{
+								Name:  "description",
+								Usage: "What the pack is for",
+							},
+							&cli.StringFlag{
+								Name:    "output",
+								Aliases: []string{"o"},
+								Usage:   "Write the pack to this file instead of stdout",
+							},
+						},
+						Action: exportAgentTypes,
+					},
+					{
+						Name:      "import",
+						Usage:     "Install or upgrade a pack of agent types",
+						ArgsUsage: "<pack-file>",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "force",
+								Usage: "Install even a downgrade, a changed version or types other packs define",
+							},
+						},
+						Action: importAgentTypes,
+					},
+				},
+			},
+			{
+				Name:      "audit",
+				Usage:     "List who answered, approved, paused and cancelled what in a session",
+				ArgsUsage: "[session]",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "Print the audit log as JSON",
+					},
+				},
+				Action: printAudit,
+			},
+			{
+				Name:  "bench",
+				Usage: "Measure orchestration overhead with a synthetic workflow and fake agents",
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "tasks",
+						Value: bench.DefaultTasks,
+						Usage: "Length of the task chain whose spawns are timed",
+					},
+					&cli.IntFlag{
+						Name:  "messages",
+						Value: bench.DefaultMessages,
+						Usage: "Operations sent over each transport",
+					},
+					&cli.IntFlag{
+						Name:  "saves",
+						Value: bench.DefaultSaves,
+						Usage: "State saves timed",
+					},
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "Print the results as JSON",
+					},
+					&cli.BoolFlag{
+						Name:  "verbose",
+						Usage: "Show the orchestrator's log",
+					},
+				},
+				Action: runBench,
+			},
+			{
+				Name:      "prune",
+				Usage:     "Prune the artifacts of a session's finished agents",
+				ArgsUsage: "<session>",
+				Flags: []cli.Flag{
+					&cli.Int64Flag{
+						Name:  "max-size-mb",
+						Usage: "Prune the oldest artifacts until the session is under this size (default: the workflow's retention)",
+					},
+					&cli.IntFlag{
+						Name:  "max-age-hours",
+						Usage: "Prune artifacts older than this (default: the workflow's retention)",
+					},
+				},
+				Action: pruneSession,
+			},
+			{
+				Name:  "install-agent",
+				Usage: "Install the swarm-agent binary matching this swarm build",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "dir",
+						Usage: "Install directory (default ~/.claude-swarm/bin)",
+					},
+					&cli.StringFlag{
+						Name:  "target",
+						Usage: "Target platform as os/arch (default: this host)",
+					},
+				},
+				Action: installAgent,
+			},
+			{
+				Name:   "schema",
+				Usage:  "Print the JSON Schema of workflow files, for editors",
+				Action: printSchema,
+			},
+			{
+				Name:  "proposals",
+				Usage: "Review changes proposed by agents in read-only runs",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "list",
+						Usage:     "List proposals grouped by task",
+						ArgsUsage: "<session>",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "all",
+								Usage: "Include applied and rejected proposals",
+							},
+						},
+						Action: listProposals,
+					},
+					{
+						Name:      "show",
+						Usage:     "Show the diffs of proposals",
+						ArgsUsage: "<session> <proposal-or-task-id>...",
+						Action:    showProposals,
+					},
+					{
+						Name:      "apply",
+						Usage:     "Apply proposals; a task ID applies its whole patch set",
+						ArgsUsage: "<session> [proposal-or-task-id...]",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "all",
+								Usage: "Apply every pending proposal",
+							},
+						},
+						Action: applyProposals,
+					},
+					{
+						Name:      "reject",
+						Usage:     "Reject proposals; a task ID rejects its whole patch set",
+						ArgsUsage: "<session> [proposal-or-task-id...]",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "all",
+								Usage: "Reject every pending proposal",
+							},
+						},
+						Action: rejectProposals,
+					},
+				},
+			},
 		},
 	}
 
@@ -69,8 +651,34 @@ func initSession(c *cli.Context) error {
 	fmt.Printf("Directory: %s\n\n", swarmDir)
 	fmt.Printf("Launching interactive planning mode...\n\n")
 
+	projectMemory, err := openProjectMemory(c)
+	if err != nil {
+		return err
+	}
+	agentTypes, err := agenttype.Open(agenttype.Dir())
+	if err != nil {
+		return err
+	}
+
 	// Launch TUI
-	if err := tui.Run(sessionID, swarmDir); err != nil {
+	opts := tui.Options{
+		Profiling:   c.Bool("pprof"),
+		CORSOrigins: c.StringSlice("cors-origin"),
+		Embed:       c.Bool("embed"),
+		Planning: tui.PlanningOptions{
+			SummarizeEvery: c.Duration("summarize-every"),
+			Timebox:        c.Duration("timebox"),
+		},
+		SummaryCommand: c.String("summary-command"),
+		Memory:         projectMemory,
+		AgentTypes:     agentTypes,
+		ReviewAnswers:  c.Bool("review-answers"),
+		A11y:           c.Bool("a11y"),
+	}
+	if c.Bool("spawn") {
+		opts.AgentCommand = c.String("agent-command")
+	}
+	if err := tui.Run(sessionID, swarmDir, opts); err != nil {
 		return fmt.Errorf("TUI error: %w", err)
 	}
 
@@ -87,6 +695,30 @@ func runWorkflow(c *cli.Context) error {
 	if err != nil {
 		return fmt.Errorf("failed to parse workflow: %w", err)
 	}
+	if c.Bool("read-only") {
+		wf.ReadOnly = true
+	}
+	if c.Bool("review-answers") {
+		wf.ReviewAnswers = true
+	}
+	if name := c.String("failure-policy"); name != "" {
+		policy, err := workflow.ParseFailurePolicy(name)
+		if err != nil {
+			return err
+		}
+		wf.FailurePolicy = policy
+	}
+	if err := wf.AddAnswers(c.String("answers")); err != nil {
+		return err
+	}
+
+	params, err := workflow.ParseParams(c.StringSlice("param"))
+	if err != nil {
+		return err
+	}
+	if err := wf.SetParams(params); err != nil {
+		return err
+	}
 
 	// Read plan
 	var plan string
@@ -99,7 +731,10 @@ func runWorkflow(c *cli.Context) error {
 	}
 
 	// Determine swarm directory from workflow path
-	swarmDir := filepath.Dir(workflowPath)
+	swarmDir, err := filepath.Abs(filepath.Dir(workflowPath))
+	if err != nil {
+		return fmt.Errorf("failed to resolve swarm directory: %w", err)
+	}
 
 	// Generate session ID
 	sessionID := filepath.Base(swarmDir)
@@ -107,24 +742,208 @@ func runWorkflow(c *cli.Context) error {
 	// Create state
 	swarmState := state.NewSwarmState(sessionID, plan, wf)
 
+	return orchestrate(c, swarmDir, swarmState)
+}
+
+// orchestrate runs a session's orchestrator until its workflow completes,
+// fails or is interrupted, with the spawner and reports the flags select
+func orchestrate(c *cli.Context, swarmDir string, swarmState *state.SwarmState) error {
+	wf := swarmState.Workflow
+	var err error
+
+	// In CI mode stdout carries JSON only; logs go to stderr
+	var jsonOut io.Writer
+	stopEvents := func() {}
+	if c.Bool("ci") {
+		jsonOut = os.Stdout
+		os.Stdout = os.Stderr
+		stopEvents = streamEvents(swarmState, jsonOut)
+	}
+	defer stopEvents()
+
+	// Select how agents are spawned
+	opts := []orchestrator.Option{
+		orchestrator.WithTickInterval(c.Duration("tick")),
+		orchestrator.WithPollInterval(c.Duration("poll")),
+	}
+	if c.Bool("fake-agents") || c.String("fake-script") != "" {
+		var script *orchestrator.FakeScript
+		if scriptPath := c.String("fake-script"); scriptPath != "" {
+			script, err = orchestrator.LoadFakeScript(scriptPath)
+			if err != nil {
+				return err
+			}
+		}
+		opts = append(opts, orchestrator.WithSpawner(orchestrator.NewFakeSpawner(script)))
+	} else {
+		if c.Bool("spawn") {
+			opts = append(opts, orchestrator.WithSpawner(orchestrator.NewProcessSpawner(c.String("agent-command"))))
+		}
+		// Dry runs excerpt long outputs rather than calling a model
+		opts = append(opts, orchestrator.WithSummaryCommand(c.String("summary-command")))
+	}
+
+	// Simulated runs are not worth remembering
+	var projectMemory *memory.Memory
+	if !c.Bool("fake-agents") && c.String("fake-script") == "" {
+		projectMemory, err = openProjectMemory(c)
+		if err != nil {
+			return err
+		}
+		if projectMemory != nil {
+			opts = append(opts, orchestrator.WithProjectMemory(projectMemory))
+		}
+	}
+
+	agentTypes, err := agenttype.Open(agenttype.Dir())
+	if err != nil {
+		return err
+	}
+	opts = append(opts, orchestrator.WithAgentTypes(agentTypes))
+
+	if recordPath := c.String("record"); recordPath != "" {
+		recorder, err := orchestrator.NewRecorder(recordPath)
+		if err != nil {
+			return err
+		}
+		defer recorder.Close()
+		opts = append(opts, orchestrator.WithRecorder(recorder))
+	}
+	if replayPath := c.String("replay"); replayPath != "" {
+		replayer, err := orchestrator.LoadReplayer(replayPath)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, orchestrator.WithReplayer(replayer))
+	}
+
 	// Create orchestrator
-	orch, err := orchestrator.NewOrchestrator(swarmDir, swarmState)
+	orch, err := orchestrator.NewOrchestrator(swarmDir, swarmState, opts...)
 	if err != nil {
 		return fmt.Errorf("failed to create orchestrator: %w", err)
 	}
 
 	fmt.Printf("Starting orchestration...\n")
-	fmt.Printf("Session: %s\n", sessionID)
+	fmt.Printf("Session: %s\n", swarmState.SessionID)
 	fmt.Printf("Workflow: %s\n", wf.Name)
-	fmt.Printf("Tasks: %d\n\n", len(wf.Tasks))
+	fmt.Printf("Tasks: %d\n", len(wf.Tasks))
+	if wf.ReadOnly {
+		fmt.Printf("Mode: read-only (proposals in %s/proposals/)\n", swarmDir)
+	}
+	if wf.FailurePolicy != "" {
+		fmt.Printf("Failure policy: %s\n", wf.FailurePolicy)
+	}
+	if wf.ReviewAnswers {
+		fmt.Printf("Answers: drafted for review (swarm answer --session %s)\n", swarmDir)
+	}
+	if projectMemory != nil {
+		fmt.Printf("Project memory: %s\n", projectMemory.Path())
+	}
+	fmt.Printf("\n")
+
+	// Run orchestrator until it completes or the user interrupts it
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if timeout := c.Duration("timeout"); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	stopProfiling, err := startProfiling(c.String("cpuprofile"), c.String("memprofile"))
+	if err != nil {
+		return err
+	}
 
-	// Run orchestrator
-	if err := orch.Run(); err != nil {
-		return fmt.Errorf("orchestration failed: %w", err)
+	err = orch.Run(ctx)
+	stopProfiling()
+	stopEvents()
+	status := runStatus(err)
+	if status != "" && (c.Bool("ci") || c.String("junit") != "" || c.String("summary") != "") {
+		if err := writeReports(c, report.FromState(swarmState, status), jsonOut); err != nil {
+			return err
+		}
+	}
+
+	if err != nil {
+		if status == report.StatusInterrupted && !c.Bool("ci") {
+			fmt.Printf("\nOrchestration interrupted. State saved to %s/state.json\n", swarmDir)
+			return nil
+		}
+		if status == report.StatusCancelled && !c.Bool("ci") {
+			fmt.Printf("\nOrchestration cancelled. State saved to %s/state.json\n", swarmDir)
+			return nil
+		}
+		return exitError(status, err)
 	}
 
 	fmt.Printf("\nWorkflow completed successfully!\n")
 	fmt.Printf("Check agent outputs in: %s/agents/\n", swarmDir)
+	if wf.ReadOnly {
+		fmt.Printf("Review proposed changes with: swarm proposals list %s\n", swarmDir)
+	}
+
+	return nil
+}
+
+func approveQuota(c *cli.Context) error {
+	if c.Args().Len() < 2 {
+		return fmt.Errorf("session and task ID are required")
+	}
+
+	swarmDir, err := resolveSessionDir(c.Args().Get(0))
+	if err != nil {
+		return err
+	}
+	taskID := c.Args().Get(1)
+
+	err = orchestrator.SendControlRequest(swarmDir, workflow.ControlRequest{
+		Action: workflow.ControlApproveQuota,
+		TaskID: taskID,
+	})
+	if err != nil {
+		return err
+	}
 
+	fmt.Printf("Quota approval sent for task %s\n", taskID)
 	return nil
 }
+
+// cancelSession asks a running orchestrator to cancel its run
+func cancelSession(c *cli.Context) error {
+	if c.Args().Len() < 1 {
+		return fmt.Errorf("session is required")
+	}
+
+	swarmDir, err := resolveSessionDir(c.Args().Get(0))
+	if err != nil {
+		return err
+	}
+
+	err = orchestrator.SendControlRequest(swarmDir, workflow.ControlRequest{
+		Action: workflow.ControlCancel,
+		Signal: c.Bool("signal"),
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Cancellation sent to session %s\n", filepath.Base(swarmDir))
+	return nil
+}
+
+// resolveSessionDir accepts a session directory or a session ID under
+// ~/.claude-swarm and returns the absolute session directory
+func resolveSessionDir(session string) (string, error) {
+	if info, err := os.Stat(session); err == nil && info.IsDir() {
+		return filepath.Abs(session)
+	}
+
+	swarmDir := filepath.Join(os.Getenv("HOME"), ".claude-swarm", session)
+	if info, err := os.Stat(swarmDir); err == nil && info.IsDir() {
+		return swarmDir, nil
+	}
+
+	return "", fmt.Errorf("session not found: %s", session)
+}
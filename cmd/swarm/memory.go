@@ -0,0 +1,15 @@
+package main
+
+import (
+	"github.com/aristath/claude-swarm/internal/memory"
+	"github.com/urfave/cli/v2"
+)
+
+// openProjectMemory returns the memory of the repository swarm runs in,
+// or nil with --no-memory or outside a repository
+func openProjectMemory(c *cli.Context) (*memory.Memory, error) {
+	if c.Bool("no-memory") {
+		return nil, nil
+	}
+	return memory.ForDir(".")
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aristath/claude-swarm/internal/proposals"
+	"github.com/urfave/cli/v2"
+)
+
+func listProposals(c *cli.Context) error {
+	if c.Args().Len() < 1 {
+		return fmt.Errorf("session is required")
+	}
+
+	store, err := openProposals(c.Args().Get(0))
+	if err != nil {
+		return err
+	}
+
+	var list []*proposals.Proposal
+	if c.Bool("all") {
+		list, err = store.List()
+	} else {
+		list, err = store.Pending()
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(list) == 0 {
+		fmt.Printf("No proposals\n")
+		return nil
+	}
+
+	sets := proposals.ByTask(list)
+	taskIDs := make([]string, 0, len(sets))
+	for taskID := range sets {
+		taskIDs = append(taskIDs, taskID)
+	}
+	sort.Strings(taskIDs)
+
+	for _, taskID := range taskIDs {
+		set := sets[taskID]
+		fmt.Printf("Task %s (%d files)\n", taskID, len(set))
+		for _, proposal := range set {
+			added, removed := proposal.LineStats()
+			fmt.Printf("  %-40s %s  +%d -%d  [%s]\n", proposal.ID, proposal.Path, added, removed, proposal.Status)
+		}
+	}
+
+	return nil
+}
+
+func showProposals(c *cli.Context) error {
+	if c.Args().Len() < 2 {
+		return fmt.Errorf("session and at least one proposal or task ID are required")
+	}
+
+	store, err := openProposals(c.Args().Get(0))
+	if err != nil {
+		return err
+	}
+
+	selected, err := selectProposals(store, c.Args().Slice()[1:], false)
+	if err != nil {
+		return err
+	}
+
+	for _, proposal := range selected {
+		fmt.Printf("# %s (task %s, %s)\n", proposal.ID, proposal.TaskID, proposal.Status)
+		if proposal.Diff == "" {
+			fmt.Printf("(no changes)\n\n")
+			continue
+		}
+		fmt.Printf("%s\n", proposal.Diff)
+	}
+
+	return nil
+}
+
+func applyProposals(c *cli.Context) error {
+	return reviewProposals(c, "Applied", func(store *proposals.Store, id string) (*proposals.Proposal, error) {
+		return store.Apply(id)
+	})
+}
+
+func rejectProposals(c *cli.Context) error {
+	return reviewProposals(c, "Rejected", func(store *proposals.Store, id string) (*proposals.Proposal, error) {
+		return store.Reject(id)
+	})
+}
+
+// reviewProposals applies a review decision to the selected proposals,
+// continuing past failures and reporting them at the end
+func reviewProposals(c *cli.Context, verb string, decide func(*proposals.Store, string) (*proposals.Proposal, error)) error {
+	if c.Args().Len() < 1 {
+		return fmt.Errorf("session is required")
+	}
+	if c.Args().Len() < 2 && !c.Bool("all") {
+		return fmt.Errorf("proposal or task IDs are required (or --all)")
+	}
+
+	store, err := openProposals(c.Args().Get(0))
+	if err != nil {
+		return err
+	}
+
+	selected, err := selectProposals(store, c.Args().Slice()[1:], c.Bool("all"))
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, proposal := range selected {
+		if _, err := decide(store, proposal.ID); err != nil {
+			fmt.Printf("✗ %s: %v\n", proposal.ID, err)
+			failed++
+			continue
+		}
+		fmt.Printf("✓ %s %s\n", verb, proposal.Path)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d proposals failed", failed, len(selected))
+	}
+
+	return nil
+}
+
+// selectProposals resolves proposal IDs and task IDs (selecting the task's
+// whole patch set) to pending proposals. With all set, every pending
+// proposal is selected.
+func selectProposals(store *proposals.Store, ids []string, all bool) ([]*proposals.Proposal, error) {
+	list, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	selected := []*proposals.Proposal{}
+	seen := make(map[string]bool)
+	add := func(proposal *proposals.Proposal) {
+		if !seen[proposal.ID] {
+			seen[proposal.ID] = true
+			selected = append(selected, proposal)
+		}
+	}
+
+	if all {
+		for _, proposal := range list {
+			if proposal.Status == proposals.StatusPending {
+				add(proposal)
+			}
+		}
+		return selected, nil
+	}
+
+	for _, id := range ids {
+		found := false
+		for _, proposal := range list {
+			if proposal.ID == id {
+				add(proposal)
+				found = true
+			} else if proposal.TaskID == id && proposal.Status == proposals.StatusPending {
+				add(proposal)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no proposal or pending patch set found for %s", id)
+		}
+	}
+
+	return selected, nil
+}
+
+// openProposals returns the proposal store of a session
+func openProposals(session string) (*proposals.Store, error) {
+	swarmDir, err := resolveSessionDir(session)
+	if err != nil {
+		return nil, err
+	}
+
+	return proposals.NewStore(swarmDir), nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/retention"
+	"github.com/aristath/claude-swarm/internal/workflow"
+	"github.com/urfave/cli/v2"
+)
+
+// pruneSession prunes a session's agent artifacts by its workflow's
+// retention policy, or the limits given on the command line
+func pruneSession(c *cli.Context) error {
+	if c.Args().Len() < 1 {
+		return fmt.Errorf("session is required")
+	}
+
+	swarmDir, err := resolveSessionDir(c.Args().Get(0))
+	if err != nil {
+		return err
+	}
+
+	var policy retention.Policy
+	if wf, err := workflow.NewParser().ParseFile(filepath.Join(swarmDir, "workflow.yaml")); err == nil && wf.Retention != nil {
+		policy = retention.Policy{MaxBytes: wf.Retention.MaxBytes(), MaxAge: wf.Retention.MaxAge()}
+	}
+	if c.IsSet("max-size-mb") {
+		policy.MaxBytes = c.Int64("max-size-mb") << 20
+	}
+	if c.IsSet("max-age-hours") {
+		policy.MaxAge = time.Duration(c.Int("max-age-hours")) * time.Hour
+	}
+	if policy.MaxBytes == 0 && policy.MaxAge == 0 {
+		return fmt.Errorf("no retention policy: set retention in the workflow or pass --max-size-mb or --max-age-hours")
+	}
+
+	result, err := retention.Prune(swarmDir, policy, time.Now())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Pruned %d files (%s), %s left\n", result.Files, retention.FormatBytes(result.Bytes), retention.FormatBytes(result.Remaining))
+	return nil
+}
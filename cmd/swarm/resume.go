@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/orchestrator"
+	"github.com/aristath/claude-swarm/internal/workflow"
+	"github.com/urfave/cli/v2"
+)
+
+// resumeSession continues an interrupted session from its saved state,
+// running only the tasks that have not finished
+func resumeSession(c *cli.Context) error {
+	if c.Args().Len() < 1 {
+		return fmt.Errorf("session is required")
+	}
+
+	swarmDir, err := resolveSessionDir(c.Args().Get(0))
+	if err != nil {
+		return err
+	}
+
+	swarmState, reconciled, err := orchestrator.LoadSession(swarmDir)
+	if err != nil {
+		return err
+	}
+	// Tasks an operator reruns or skips before resuming
+	operator := workflow.CurrentOperator()
+	for _, taskID := range c.StringSlice("rerun") {
+		if err := orchestrator.RerunTask(swarmDir, swarmState, taskID, operator); err != nil {
+			return err
+		}
+		auditResume(swarmDir, operator, workflow.ControlRerun, taskID)
+		reconciled.Restarted = append(reconciled.Restarted, taskID)
+	}
+	for _, taskID := range c.StringSlice("skip") {
+		if err := swarmState.SkipTask(taskID, operator); err != nil {
+			return err
+		}
+		auditResume(swarmDir, operator, workflow.ControlSkip, taskID)
+		fmt.Printf("Skipped: %s\n", taskID)
+	}
+
+	if swarmState.IsComplete() {
+		fmt.Printf("Session %s has already completed\n", swarmState.SessionID)
+		return nil
+	}
+
+	fmt.Printf("Resuming session %s\n", swarmState.SessionID)
+	for _, line := range []struct {
+		label string
+		tasks []string
+	}{
+		{"Completed while stopped", reconciled.Completed},
+		{"Failed while stopped", reconciled.Failed},
+		{"Restarting", reconciled.Restarted},
+	} {
+		if len(line.tasks) > 0 {
+			fmt.Printf("%s: %s\n", line.label, strings.Join(line.tasks, ", "))
+		}
+	}
+	fmt.Printf("\n")
+
+	return orchestrate(c, swarmDir, swarmState)
+}
+
+// auditResume logs a rerun or skip given when resuming
+func auditResume(swarmDir, operator string, action workflow.ControlAction, taskID string) {
+	err := orchestrator.AppendAudit(swarmDir, orchestrator.AuditEntry{
+		Time:     time.Now(),
+		Operator: operator,
+		Action:   string(action),
+		Task:     taskID,
+	})
+	if err != nil {
+		fmt.Printf("Failed to audit %s: %v\n", action, err)
+	}
+}
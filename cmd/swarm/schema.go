@@ -0,0 +1,14 @@
+package main
+
+import (
+	"os"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+	"github.com/urfave/cli/v2"
+)
+
+// printSchema prints the workflow JSON Schema
+func printSchema(c *cli.Context) error {
+	_, err := os.Stdout.Write(workflow.WorkflowSchema)
+	return err
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aristath/claude-swarm/internal/search"
+	"github.com/aristath/claude-swarm/internal/state"
+	"github.com/urfave/cli/v2"
+)
+
+// searchSession greps a session's outputs, questions and answers and agent
+// transcripts, listing the matches by task
+func searchSession(c *cli.Context) error {
+	query := strings.Join(c.Args().Slice(), " ")
+	if query == "" {
+		return fmt.Errorf("query is required")
+	}
+
+	session := c.String("session")
+	if session == "" {
+		latest, err := latestSession()
+		if err != nil {
+			return err
+		}
+		session = latest
+	}
+	swarmDir, err := resolveSessionDir(session)
+	if err != nil {
+		return err
+	}
+
+	swarmState, err := state.NewPersistence(swarmDir).Load()
+	if err != nil {
+		return fmt.Errorf("failed to load session: %w", err)
+	}
+
+	matches, err := search.Session(swarmDir, swarmState, query, search.Options{
+		Regexp: c.Bool("regexp"),
+		Task:   c.String("task"),
+		Limit:  c.Int("limit"),
+	})
+	if err != nil {
+		return err
+	}
+
+	if c.Bool("json") {
+		if matches == nil {
+			matches = []search.Match{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(matches)
+	}
+	if len(matches) == 0 {
+		fmt.Printf("No matches in session %s\n", swarmState.SessionID)
+		return nil
+	}
+	fmt.Print(search.Format(matches))
+	fmt.Printf("\n%d matches in %d tasks of session %s\n", len(matches), len(search.Tasks(matches)), swarmState.SessionID)
+	return nil
+}
+
+// latestSession returns the current directory when a run saved its
+// session there, or else the most recently saved session under
+// ~/.claude-swarm
+func latestSession() (string, error) {
+	if _, err := os.Stat("state.json"); err == nil {
+		return filepath.Abs(".")
+	}
+
+	paths, err := filepath.Glob(filepath.Join(os.Getenv("HOME"), ".claude-swarm", "*", "state.json"))
+	if err != nil {
+		return "", err
+	}
+
+	latest := ""
+	var latestInfo os.FileInfo
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if latestInfo == nil || info.ModTime().After(latestInfo.ModTime()) {
+			latest, latestInfo = filepath.Dir(path), info
+		}
+	}
+	if latest == "" {
+		return "", fmt.Errorf("no sessions found; pass --session")
+	}
+	return latest, nil
+}
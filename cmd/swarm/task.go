@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/aristath/claude-swarm/internal/orchestrator"
+	"github.com/aristath/claude-swarm/internal/workflow"
+	"github.com/urfave/cli/v2"
+)
+
+// sessionFlag selects the session of commands acting on a running one
+func sessionFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "session",
+		Usage: "Session ID or directory (default: the session in the current directory, or the most recent one)",
+	}
+}
+
+// rerunTask asks a running orchestrator to run a failed task again
+func rerunTask(c *cli.Context) error {
+	return sendTaskControl(c, workflow.ControlRerun, "Rerun")
+}
+
+// skipTask asks a running orchestrator to skip a task
+func skipTask(c *cli.Context) error {
+	return sendTaskControl(c, workflow.ControlSkip, "Skip")
+}
+
+// sendTaskControl sends a control request for the task given as argument
+// to the session given by --session
+func sendTaskControl(c *cli.Context, action workflow.ControlAction, label string) error {
+	if c.Args().Len() < 1 {
+		return fmt.Errorf("task ID is required")
+	}
+	taskID := c.Args().Get(0)
+
+	session := c.String("session")
+	if session == "" {
+		latest, err := latestSession()
+		if err != nil {
+			return err
+		}
+		session = latest
+	}
+	swarmDir, err := resolveSessionDir(session)
+	if err != nil {
+		return err
+	}
+
+	err = orchestrator.SendControlRequest(swarmDir, workflow.ControlRequest{
+		Action: action,
+		TaskID: taskID,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s sent for task %s\n", label, taskID)
+	return nil
+}
@@ -0,0 +1,262 @@
+// Protocol between agents and the orchestrator's API server.
+//
+// swarm-agent speaks it with --transport grpc; agents written in other
+// languages can generate a client from this file instead of calling the
+// HTTP API. Operations behave exactly as their HTTP endpoints do.
+//
+// Regenerate the Go code with: go generate ./internal/agentpb
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: agent/v1/agent.proto
+
+package agentpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Agent_Send_FullMethodName     = "/swarm.agent.v1.Agent/Send"
+	Agent_Ask_FullMethodName      = "/swarm.agent.v1.Agent/Ask"
+	Agent_Complete_FullMethodName = "/swarm.agent.v1.Agent/Complete"
+	Agent_Bash_FullMethodName     = "/swarm.agent.v1.Agent/Bash"
+)
+
+// AgentClient is the client API for Agent service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Agent is served next to the HTTP API. Calls carry the API token as
+// "authorization: Bearer <token>" metadata and may declare their protocol
+// version as "x-swarm-protocol-version".
+type AgentClient interface {
+	// Send performs a file, search, lock, task or usage operation
+	Send(ctx context.Context, in *Message, opts ...grpc.CallOption) (*Response, error)
+	// Ask asks the orchestrator a question and returns its answer, if one
+	// is ready
+	Ask(ctx context.Context, in *Question, opts ...grpc.CallOption) (*Response, error)
+	// Complete completes or fails the agent's task
+	Complete(ctx context.Context, in *Completion, opts ...grpc.CallOption) (*Response, error)
+	// Bash runs a command and streams its output while it runs. The first
+	// input names the command; later inputs feed its standard input, which
+	// is closed when the agent closes its side of the stream. Ending the
+	// call kills the command.
+	Bash(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[BashInput, BashOutput], error)
+}
+
+type agentClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAgentClient(cc grpc.ClientConnInterface) AgentClient {
+	return &agentClient{cc}
+}
+
+func (c *agentClient) Send(ctx context.Context, in *Message, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, Agent_Send_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentClient) Ask(ctx context.Context, in *Question, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, Agent_Ask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentClient) Complete(ctx context.Context, in *Completion, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, Agent_Complete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentClient) Bash(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[BashInput, BashOutput], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Agent_ServiceDesc.Streams[0], Agent_Bash_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[BashInput, BashOutput]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Agent_BashClient = grpc.BidiStreamingClient[BashInput, BashOutput]
+
+// AgentServer is the server API for Agent service.
+// All implementations must embed UnimplementedAgentServer
+// for forward compatibility.
+//
+// Agent is served next to the HTTP API. Calls carry the API token as
+// "authorization: Bearer <token>" metadata and may declare their protocol
+// version as "x-swarm-protocol-version".
+type AgentServer interface {
+	// Send performs a file, search, lock, task or usage operation
+	Send(context.Context, *Message) (*Response, error)
+	// Ask asks the orchestrator a question and returns its answer, if one
+	// is ready
+	Ask(context.Context, *Question) (*Response, error)
+	// Complete completes or fails the agent's task
+	Complete(context.Context, *Completion) (*Response, error)
+	// Bash runs a command and streams its output while it runs. The first
+	// input names the command; later inputs feed its standard input, which
+	// is closed when the agent closes its side of the stream. Ending the
+	// call kills the command.
+	Bash(grpc.BidiStreamingServer[BashInput, BashOutput]) error
+	mustEmbedUnimplementedAgentServer()
+}
+
+// UnimplementedAgentServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAgentServer struct{}
+
+func (UnimplementedAgentServer) Send(context.Context, *Message) (*Response, error) {
+	return nil, status.Error(codes.Unimplemented, "method Send not implemented")
+}
+func (UnimplementedAgentServer) Ask(context.Context, *Question) (*Response, error) {
+	return nil, status.Error(codes.Unimplemented, "method Ask not implemented")
+}
+func (UnimplementedAgentServer) Complete(context.Context, *Completion) (*Response, error) {
+	return nil, status.Error(codes.Unimplemented, "method Complete not implemented")
+}
+func (UnimplementedAgentServer) Bash(grpc.BidiStreamingServer[BashInput, BashOutput]) error {
+	return status.Error(codes.Unimplemented, "method Bash not implemented")
+}
+func (UnimplementedAgentServer) mustEmbedUnimplementedAgentServer() {}
+func (UnimplementedAgentServer) testEmbeddedByValue()               {}
+
+// UnsafeAgentServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AgentServer will
+// result in compilation errors.
+type UnsafeAgentServer interface {
+	mustEmbedUnimplementedAgentServer()
+}
+
+func RegisterAgentServer(s grpc.ServiceRegistrar, srv AgentServer) {
+	// If the following call panics, it indicates UnimplementedAgentServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Agent_ServiceDesc, srv)
+}
+
+func _Agent_Send_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Message)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServer).Send(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Agent_Send_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServer).Send(ctx, req.(*Message))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Agent_Ask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Question)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServer).Ask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Agent_Ask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServer).Ask(ctx, req.(*Question))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Agent_Complete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Completion)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServer).Complete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Agent_Complete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServer).Complete(ctx, req.(*Completion))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Agent_Bash_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AgentServer).Bash(&grpc.GenericServerStream[BashInput, BashOutput]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Agent_BashServer = grpc.BidiStreamingServer[BashInput, BashOutput]
+
+// Agent_ServiceDesc is the grpc.ServiceDesc for Agent service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Agent_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "swarm.agent.v1.Agent",
+	HandlerType: (*AgentServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Send",
+			Handler:    _Agent_Send_Handler,
+		},
+		{
+			MethodName: "Ask",
+			Handler:    _Agent_Ask_Handler,
+		},
+		{
+			MethodName: "Complete",
+			Handler:    _Agent_Complete_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Bash",
+			Handler:       _Agent_Bash_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "agent/v1/agent.proto",
+}
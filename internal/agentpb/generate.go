@@ -0,0 +1,5 @@
+// Package agentpb is the Go code generated from proto/agent/v1/agent.proto,
+// the gRPC protocol between agents and the API server
+package agentpb
+
+//go:generate protoc --proto_path=../../proto --go_out=../.. --go_opt=module=github.com/aristath/claude-swarm --go-grpc_out=../.. --go-grpc_opt=module=github.com/aristath/claude-swarm agent/v1/agent.proto
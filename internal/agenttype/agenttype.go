@@ -0,0 +1,357 @@
+// Package agenttype defines agent types: the system prompt, tool
+// permissions and model that agents of a type run with. Definitions are
+// shared across teams as versioned packs, installed under
+// ~/.claude-swarm/agent-types/packs/, and overridden by the local
+// definitions in ~/.claude-swarm/agent-types/local.yaml.
+package agenttype
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LocalFile holds the local definitions, which override the packs'
+const LocalFile = "local.yaml"
+
+// packsDir is the library directory holding installed packs, one file each
+const packsDir = "packs"
+
+// LocalSource is the source of local definitions
+const LocalSource = "local"
+
+// Type is an agent type definition
+type Type struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+	// Model is the model agents of the type run with, such as sonnet
+	Model string `yaml:"model,omitempty"`
+	// Tools are permissions granted without prompts, such as
+	// "Bash(go test:*)"
+	Tools []string `yaml:"tools,omitempty"`
+	// Prompt is the system prompt, given to agents before their task
+	Prompt string `yaml:"prompt,omitempty"`
+}
+
+// Pack is a versioned set of agent types shared as one file
+type Pack struct {
+	Name        string `yaml:"name"`
+	Version     string `yaml:"version"`
+	Description string `yaml:"description,omitempty"`
+	Types       []Type `yaml:"types"`
+}
+
+// Validate checks that a pack can be installed: a file-name safe name, a
+// MAJOR.MINOR.PATCH version and uniquely named types
+func (p *Pack) Validate() error {
+	if p.Name == "" || strings.ContainsAny(p.Name, `/\`) || strings.HasPrefix(p.Name, ".") {
+		return fmt.Errorf("pack name %q must be non-empty and contain no slashes", p.Name)
+	}
+	if _, err := parseVersion(p.Version); err != nil {
+		return err
+	}
+	return validateTypes(p.Types)
+}
+
+// validateTypes checks that types are named, each once
+func validateTypes(types []Type) error {
+	seen := make(map[string]bool)
+	for i, t := range types {
+		if t.Name == "" {
+			return fmt.Errorf("type %d has no name", i+1)
+		}
+		if seen[t.Name] {
+			return fmt.Errorf("type %s is defined twice", t.Name)
+		}
+		seen[t.Name] = true
+	}
+	return nil
+}
+
+// ReadPack reads and validates a pack file
+func ReadPack(path string) (*Pack, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pack: %w", err)
+	}
+
+	var pack Pack
+	if err := yaml.Unmarshal(data, &pack); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if err := pack.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid pack %s: %w", path, err)
+	}
+	return &pack, nil
+}
+
+// Marshal encodes a pack as YAML
+func (p *Pack) Marshal() ([]byte, error) {
+	return yaml.Marshal(p)
+}
+
+// Library is the agent types available to runs: the installed packs and
+// the local definitions
+type Library struct {
+	dir   string
+	Packs []*Pack // By name
+	Local []Type
+}
+
+// Dir returns the default library directory, ~/.claude-swarm/agent-types
+func Dir() string {
+	return filepath.Join(os.Getenv("HOME"), ".claude-swarm", "agent-types")
+}
+
+// Open reads the library in dir. A missing directory is an empty library.
+func Open(dir string) (*Library, error) {
+	library := &Library{dir: dir}
+
+	paths, err := filepath.Glob(filepath.Join(dir, packsDir, "*.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list packs: %w", err)
+	}
+	for _, path := range paths {
+		pack, err := ReadPack(path)
+		if err != nil {
+			return nil, err
+		}
+		library.Packs = append(library.Packs, pack)
+	}
+	sort.Slice(library.Packs, func(i, j int) bool {
+		return library.Packs[i].Name < library.Packs[j].Name
+	})
+
+	data, err := os.ReadFile(filepath.Join(dir, LocalFile))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read local agent types: %w", err)
+	}
+	var local struct {
+		Types []Type `yaml:"types"`
+	}
+	if err := yaml.Unmarshal(data, &local); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", LocalFile, err)
+	}
+	if err := validateTypes(local.Types); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", LocalFile, err)
+	}
+	library.Local = local.Types
+
+	return library, nil
+}
+
+// Entry is an agent type as runs see it, with where it comes from
+type Entry struct {
+	Type
+	// Source is LocalSource or the pack as name@version
+	Source string
+	// Shadowed are the other sources defining the type, which it
+	// overrides
+	Shadowed []string
+}
+
+// Entries returns every agent type by name. Local definitions override
+// the packs'; a type several packs define comes from the pack whose name
+// sorts first.
+func (l *Library) Entries() []Entry {
+	byName := make(map[string]*Entry)
+	var names []string
+	add := func(t Type, source string) {
+		if entry, ok := byName[t.Name]; ok {
+			entry.Shadowed = append(entry.Shadowed, source)
+			return
+		}
+		byName[t.Name] = &Entry{Type: t, Source: source}
+		names = append(names, t.Name)
+	}
+
+	for _, t := range l.Local {
+		add(t, LocalSource)
+	}
+	for _, pack := range l.Packs {
+		for _, t := range pack.Types {
+			add(t, pack.Name+"@"+pack.Version)
+		}
+	}
+
+	sort.Strings(names)
+	entries := make([]Entry, 0, len(names))
+	for _, name := range names {
+		entries = append(entries, *byName[name])
+	}
+	return entries
+}
+
+// Get returns the definition of an agent type, if there is one
+func (l *Library) Get(name string) (Type, bool) {
+	if l == nil {
+		return Type{}, false
+	}
+	for _, entry := range l.Entries() {
+		if entry.Name == name {
+			return entry.Type, true
+		}
+	}
+	return Type{}, false
+}
+
+// Export bundles agent types, as runs see them, into a pack. Without
+// names it bundles them all.
+func (l *Library) Export(name, version, description string, names []string) (*Pack, error) {
+	pack := &Pack{Name: name, Version: version, Description: description}
+	if len(names) == 0 {
+		for _, entry := range l.Entries() {
+			pack.Types = append(pack.Types, entry.Type)
+		}
+	}
+	for _, typeName := range names {
+		t, ok := l.Get(typeName)
+		if !ok {
+			return nil, fmt.Errorf("agent type %s is not defined", typeName)
+		}
+		pack.Types = append(pack.Types, t)
+	}
+
+	if len(pack.Types) == 0 {
+		return nil, fmt.Errorf("no agent types to export")
+	}
+	if err := pack.Validate(); err != nil {
+		return nil, err
+	}
+	return pack, nil
+}
+
+// Conflict is an agent type an imported pack shares with another source
+type Conflict struct {
+	Type   string
+	Source string // LocalSource or the other pack as name@version
+}
+
+// ImportResult describes what importing a pack did
+type ImportResult struct {
+	// Previous is the version of the pack it replaced, or ""
+	Previous string
+	// Unchanged is set when the same version was already installed
+	Unchanged bool
+	// Conflicts are types other packs define too
+	Conflicts []Conflict
+	// Overridden are types local definitions override
+	Overridden []string
+}
+
+// Import installs a pack, replacing an older version of it. It refuses to
+// downgrade the pack, to change an installed version's contents and to
+// install types other packs define, unless forced; local definitions
+// keep overriding the pack's.
+func (l *Library) Import(pack *Pack, force bool) (*ImportResult, error) {
+	if err := pack.Validate(); err != nil {
+		return nil, err
+	}
+	result := &ImportResult{}
+
+	for _, installed := range l.Packs {
+		if installed.Name != pack.Name {
+			continue
+		}
+		result.Previous = installed.Version
+		switch order := compareVersions(pack.Version, installed.Version); {
+		case order == 0 && reflect.DeepEqual(installed, pack):
+			result.Unchanged = true
+			return result, nil
+		case order == 0 && !force:
+			return nil, fmt.Errorf("%s@%s is installed with different contents; bump the pack's version or import with --force", pack.Name, pack.Version)
+		case order < 0 && !force:
+			return nil, fmt.Errorf("%s@%s is newer than %s; import with --force to downgrade", pack.Name, installed.Version, pack.Version)
+		}
+	}
+
+	local := make(map[string]bool)
+	for _, t := range l.Local {
+		local[t.Name] = true
+	}
+	for _, t := range pack.Types {
+		if local[t.Name] {
+			result.Overridden = append(result.Overridden, t.Name)
+		}
+		for _, other := range l.Packs {
+			if other.Name == pack.Name {
+				continue
+			}
+			for _, theirs := range other.Types {
+				if theirs.Name == t.Name {
+					result.Conflicts = append(result.Conflicts, Conflict{Type: t.Name, Source: other.Name + "@" + other.Version})
+				}
+			}
+		}
+	}
+	if len(result.Conflicts) > 0 && !force {
+		var names []string
+		for _, conflict := range result.Conflicts {
+			names = append(names, conflict.Type+" ("+conflict.Source+")")
+		}
+		return result, fmt.Errorf("types already defined by other packs: %s; import with --force to install anyway", strings.Join(names, ", "))
+	}
+
+	data, err := pack.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode pack: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Join(l.dir, packsDir), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create packs directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(l.dir, packsDir, pack.Name+".yaml"), data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to install pack: %w", err)
+	}
+
+	// Keep the library as it is on disk
+	packs := []*Pack{pack}
+	for _, installed := range l.Packs {
+		if installed.Name != pack.Name {
+			packs = append(packs, installed)
+		}
+	}
+	sort.Slice(packs, func(i, j int) bool { return packs[i].Name < packs[j].Name })
+	l.Packs = packs
+
+	return result, nil
+}
+
+// parseVersion parses a MAJOR.MINOR.PATCH version, optionally prefixed
+// with v
+func parseVersion(version string) ([3]int, error) {
+	var parsed [3]int
+	parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	if len(parts) != 3 {
+		return parsed, fmt.Errorf("version %q is not MAJOR.MINOR.PATCH, such as 1.0.0", version)
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return parsed, fmt.Errorf("version %q is not MAJOR.MINOR.PATCH, such as 1.0.0", version)
+		}
+		parsed[i] = n
+	}
+	return parsed, nil
+}
+
+// compareVersions returns -1, 0 or 1 as version a is older than, the same
+// as or newer than b. Both must be valid.
+func compareVersions(a, b string) int {
+	va, _ := parseVersion(a)
+	vb, _ := parseVersion(b)
+	for i := range va {
+		switch {
+		case va[i] < vb[i]:
+			return -1
+		case va[i] > vb[i]:
+			return 1
+		}
+	}
+	return 0
+}
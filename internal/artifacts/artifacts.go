@@ -0,0 +1,109 @@
+// Package artifacts collects the files tasks declare as their products
+// into the session directory, so they outlive the working tree and
+// downstream tasks can find them.
+package artifacts
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Dir returns the directory holding a task's artifacts
+func Dir(swarmDir, taskID string) string {
+	return filepath.Join(swarmDir, "artifacts", taskID)
+}
+
+// Collect copies the files matching patterns into the task's artifact
+// directory and returns their copies' paths. Relative patterns are
+// resolved against base, and matched files keep their path relative to it;
+// files outside base keep only their name. Matched directories are copied
+// whole. Patterns use filepath.Match syntax.
+func Collect(swarmDir, taskID, base string, patterns []string) ([]string, error) {
+	dest := Dir(swarmDir, taskID)
+	var collected []string
+	seen := make(map[string]bool)
+
+	for _, pattern := range patterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(base, pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return collected, fmt.Errorf("invalid artifact pattern %s: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			fmt.Printf("Task %s: no artifacts match %s\n", taskID, pattern)
+			continue
+		}
+
+		for _, match := range matches {
+			err := filepath.WalkDir(match, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if !d.Type().IsRegular() {
+					return nil
+				}
+
+				target := filepath.Join(dest, relativeName(base, match, path))
+				if seen[target] {
+					return nil
+				}
+				seen[target] = true
+				if err := copyFile(path, target); err != nil {
+					return err
+				}
+				collected = append(collected, target)
+				return nil
+			})
+			if err != nil {
+				return collected, fmt.Errorf("failed to collect %s: %w", match, err)
+			}
+		}
+	}
+
+	return collected, nil
+}
+
+// relativeName names a file below the artifact directory: its path
+// relative to base, or relative to the match's parent outside base
+func relativeName(base, match, path string) string {
+	if rel, err := filepath.Rel(base, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	rel, err := filepath.Rel(filepath.Dir(match), path)
+	if err != nil {
+		return filepath.Base(path)
+	}
+	return rel
+}
+
+func copyFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,475 @@
+// Package bench measures the orchestrator's own overhead with a synthetic
+// workflow and simulated agents: how long spawning and event delivery take,
+// the round-trip time of operations over each transport, and the cost of
+// saving state. No model is involved, so the numbers reflect only what
+// swarm adds.
+package bench
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/orchestrator"
+	"github.com/aristath/claude-swarm/internal/server"
+	"github.com/aristath/claude-swarm/internal/state"
+	"github.com/aristath/claude-swarm/internal/version"
+	"github.com/aristath/claude-swarm/internal/workflow"
+	"github.com/fsnotify/fsnotify"
+)
+
+// Defaults for Options
+const (
+	DefaultTasks    = 20
+	DefaultMessages = 100
+	DefaultSaves    = 50
+)
+
+// waitTimeout bounds every wait on the orchestrator
+const waitTimeout = 30 * time.Second
+
+// Options sizes a benchmark run
+type Options struct {
+	Tasks    int // Length of the task chain whose spawns are timed
+	Messages int // Operations sent over each transport
+	Saves    int // State saves timed
+}
+
+// Sample summarizes the timings of one measurement
+type Sample struct {
+	Name  string        `json:"name"`
+	Count int           `json:"count"`
+	Mean  time.Duration `json:"mean_ns"`
+	P50   time.Duration `json:"p50_ns"`
+	P95   time.Duration `json:"p95_ns"`
+	Max   time.Duration `json:"max_ns"`
+}
+
+// Result is the outcome of a benchmark run
+type Result struct {
+	Samples []Sample `json:"samples"`
+	Notes   []string `json:"notes,omitempty"`
+}
+
+// spawnSpawner records when the orchestrator spawns each task, without
+// launching anything
+type spawnSpawner struct {
+	spawned chan string
+}
+
+func (s *spawnSpawner) Spawn(ctx context.Context, task workflow.Task, agentDir, prompt string) error {
+	s.spawned <- task.ID
+	return nil
+}
+
+// Run benchmarks an orchestrator in a temporary directory
+func Run(ctx context.Context, opts Options) (*Result, error) {
+	if opts.Tasks <= 0 {
+		opts.Tasks = DefaultTasks
+	}
+	if opts.Messages <= 0 {
+		opts.Messages = DefaultMessages
+	}
+	if opts.Saves <= 0 {
+		opts.Saves = DefaultSaves
+	}
+
+	dir, err := os.MkdirTemp("", "swarm-bench-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create benchmark directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.MkdirAll(filepath.Join(dir, "agents"), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create agents directory: %w", err)
+	}
+
+	// A chain, so that every completion spawns exactly one task
+	wf := &workflow.Workflow{
+		Name:    "bench",
+		RepoMap: &workflow.RepoMap{Disabled: true},
+	}
+	for i := 1; i <= opts.Tasks; i++ {
+		task := workflow.Task{
+			ID:        taskID(i),
+			AgentType: "bench",
+			Prompt:    fmt.Sprintf("Synthetic task %d", i),
+		}
+		if i > 1 {
+			task.DependsOn = []string{taskID(i - 1)}
+		}
+		wf.Tasks = append(wf.Tasks, task)
+	}
+
+	port, err := freePort()
+	if err != nil {
+		return nil, err
+	}
+	apiURL := fmt.Sprintf("http://localhost:%d", port)
+	token := "bench"
+
+	swarmState := state.NewSwarmState(filepath.Base(dir), "", wf)
+	spawner := &spawnSpawner{spawned: make(chan string, opts.Tasks)}
+	orch, err := orchestrator.NewOrchestrator(dir, swarmState,
+		orchestrator.WithSpawner(spawner),
+		orchestrator.WithAPI(apiURL, token))
+	if err != nil {
+		return nil, err
+	}
+
+	events, cancelEvents := swarmState.Subscribe(opts.Tasks * 4)
+	defer cancelEvents()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	apiServer := server.NewServer(swarmState, dir, port)
+	apiServer.SetToken(token)
+	go apiServer.Start(ctx)
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- orch.Run(ctx)
+	}()
+
+	if err := waitForSpawn(ctx, spawner.spawned, taskID(1)); err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+
+	// Round trips, while the first agent is running
+	target := filepath.Join(dir, "bench.txt")
+	if err := os.WriteFile(target, []byte("benchmark\n"), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write benchmark file: %w", err)
+	}
+
+	fileTimes, err := fileRoundTrips(ctx, filepath.Join(dir, "agents", "agent-"+taskID(1)), target, opts.Messages)
+	if err != nil {
+		return nil, err
+	}
+	result.add("File bus round trip", fileTimes)
+
+	if err := waitForHealth(ctx, apiURL); err != nil {
+		return nil, err
+	}
+	httpTimes, err := httpRoundTrips(ctx, apiURL, token, target, opts.Messages)
+	if err != nil {
+		return nil, err
+	}
+	result.add("HTTP round trip", httpTimes)
+
+	socketTimes, err := socketRoundTrips(ctx, filepath.Join(dir, workflow.SocketFile), target, opts.Messages)
+	if err != nil {
+		return nil, err
+	}
+	if socketTimes == nil {
+		result.Notes = append(result.Notes, "Unix socket transport: unavailable, agents use the file bus")
+	} else {
+		result.add("Socket round trip", socketTimes)
+	}
+
+	// Completions: how long until the orchestrator sees each one, and
+	// until it has spawned the next task in the chain
+	var eventTimes, spawnTimes []time.Duration
+	for i := 1; i <= opts.Tasks; i++ {
+		start := time.Now()
+		if err := complete(filepath.Join(dir, "agents", "agent-"+taskID(i))); err != nil {
+			return nil, err
+		}
+		if err := waitForEvent(ctx, events, workflow.EventTaskCompleted, taskID(i)); err != nil {
+			return nil, err
+		}
+		eventTimes = append(eventTimes, time.Since(start))
+
+		if i < opts.Tasks {
+			if err := waitForSpawn(ctx, spawner.spawned, taskID(i+1)); err != nil {
+				return nil, err
+			}
+			spawnTimes = append(spawnTimes, time.Since(start))
+		}
+	}
+	result.add("Event delivery", eventTimes)
+	result.add("Spawn after dependency", spawnTimes)
+
+	select {
+	case err := <-runErr:
+		if err != nil {
+			return nil, fmt.Errorf("orchestrator failed: %w", err)
+		}
+	case <-time.After(waitTimeout):
+		return nil, fmt.Errorf("timeout waiting for the workflow to finish")
+	}
+
+	// State saves, with the state the run left behind
+	persistence := state.NewPersistence(dir)
+	var saveTimes []time.Duration
+	for i := 0; i < opts.Saves; i++ {
+		start := time.Now()
+		if err := persistence.Save(swarmState); err != nil {
+			return nil, fmt.Errorf("failed to save state: %w", err)
+		}
+		saveTimes = append(saveTimes, time.Since(start))
+	}
+	result.add("State save", saveTimes)
+
+	return result, nil
+}
+
+// fileRoundTrips sends read operations over the file bus and times each
+// until its response is written
+func fileRoundTrips(ctx context.Context, agentDir, target string, count int) ([]time.Duration, error) {
+	responsesDir := filepath.Join(agentDir, "responses")
+	if err := os.MkdirAll(responsesDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create responses directory: %w", err)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create watcher: %w", err)
+	}
+	defer watcher.Close()
+	if err := watcher.Add(responsesDir); err != nil {
+		return nil, fmt.Errorf("failed to watch responses: %w", err)
+	}
+
+	var times []time.Duration
+	for i := 1; i <= count; i++ {
+		msg := workflow.Message{
+			ID:              fmt.Sprintf("msg-bench-%d", i),
+			ProtocolVersion: version.ProtocolVersion,
+			Type:            workflow.MessageTypeReadFile,
+			Path:            target,
+			Timestamp:       time.Now(),
+		}
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return nil, err
+		}
+
+		start := time.Now()
+		if err := writeAtomic(filepath.Join(agentDir, "messages", msg.ID+".json"), data); err != nil {
+			return nil, err
+		}
+
+		responseName := msg.ID + "-result.json"
+		timeout := time.After(waitTimeout)
+	wait:
+		for {
+			select {
+			case event := <-watcher.Events:
+				if filepath.Base(event.Name) == responseName && event.Op&fsnotify.Create != 0 {
+					break wait
+				}
+			case err := <-watcher.Errors:
+				return nil, fmt.Errorf("watcher failed: %w", err)
+			case <-timeout:
+				return nil, fmt.Errorf("timeout waiting for response to %s", msg.ID)
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		times = append(times, time.Since(start))
+	}
+	return times, nil
+}
+
+// httpRoundTrips sends read operations to the API server and times each
+func httpRoundTrips(ctx context.Context, apiURL, token, target string, count int) ([]time.Duration, error) {
+	body, err := json.Marshal(server.FileReadRequest{AgentID: taskID(1), Path: target})
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: waitTimeout}
+
+	var times []time.Duration
+	for i := 0; i < count; i++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL+"/api/file/read", bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		start := time.Now()
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("HTTP request failed: %w", err)
+		}
+		var buf bytes.Buffer
+		buf.ReadFrom(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("HTTP request failed: %s: %s", resp.Status, strings.TrimSpace(buf.String()))
+		}
+		times = append(times, time.Since(start))
+	}
+	return times, nil
+}
+
+// socketRoundTrips sends read operations over the orchestrator's Unix
+// socket and times each until its response is read. It returns no times
+// when the orchestrator takes no messages on a socket.
+func socketRoundTrips(ctx context.Context, socketPath, target string, count int) ([]time.Duration, error) {
+	if _, err := os.Stat(socketPath); err != nil {
+		return nil, nil
+	}
+	dialer := &net.Dialer{Timeout: waitTimeout}
+
+	var times []time.Duration
+	for i := 1; i <= count; i++ {
+		req := workflow.SocketRequest{
+			AgentID: taskID(1),
+			Message: workflow.Message{
+				ID:              fmt.Sprintf("msg-bench-socket-%d", i),
+				ProtocolVersion: version.ProtocolVersion,
+				Type:            workflow.MessageTypeReadFile,
+				Path:            target,
+				Timestamp:       time.Now(),
+			},
+		}
+
+		start := time.Now()
+		conn, err := dialer.DialContext(ctx, "unix", socketPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to socket: %w", err)
+		}
+		conn.SetDeadline(time.Now().Add(waitTimeout))
+		var resp workflow.Response
+		err = json.NewEncoder(conn).Encode(req)
+		if err == nil {
+			err = json.NewDecoder(conn).Decode(&resp)
+		}
+		conn.Close()
+		if err != nil {
+			return nil, fmt.Errorf("socket round trip failed: %w", err)
+		}
+		if resp.Status != "success" {
+			return nil, fmt.Errorf("socket round trip failed: %s", resp.Error)
+		}
+		times = append(times, time.Since(start))
+	}
+	return times, nil
+}
+
+// complete reports an agent's completion through the file protocol
+func complete(agentDir string) error {
+	if err := os.MkdirAll(agentDir, 0755); err != nil {
+		return err
+	}
+	if err := writeAtomic(filepath.Join(agentDir, "output.txt"), []byte("done")); err != nil {
+		return err
+	}
+	if err := writeAtomic(filepath.Join(agentDir, "status.txt"), []byte("completed")); err != nil {
+		return err
+	}
+	return writeAtomic(filepath.Join(agentDir, "COMPLETE"), nil)
+}
+
+func waitForSpawn(ctx context.Context, spawned <-chan string, id string) error {
+	timeout := time.After(waitTimeout)
+	for {
+		select {
+		case spawnedID := <-spawned:
+			if spawnedID == id {
+				return nil
+			}
+		case <-timeout:
+			return fmt.Errorf("timeout waiting for %s to be spawned", id)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func waitForEvent(ctx context.Context, events <-chan workflow.FileEvent, eventType workflow.EventType, id string) error {
+	timeout := time.After(waitTimeout)
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return fmt.Errorf("event stream closed")
+			}
+			if event.Type == eventType && event.AgentID == id {
+				return nil
+			}
+		case <-timeout:
+			return fmt.Errorf("timeout waiting for %s of %s", eventType, id)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func waitForHealth(ctx context.Context, apiURL string) error {
+	deadline := time.Now().Add(waitTimeout)
+	for time.Now().Before(deadline) {
+		resp, err := http.Get(apiURL + "/health")
+		if err == nil {
+			resp.Body.Close()
+			return nil
+		}
+		select {
+		case <-time.After(10 * time.Millisecond):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return fmt.Errorf("API server did not start")
+}
+
+// freePort finds a TCP port to run the API server on
+func freePort() (int, error) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return 0, fmt.Errorf("failed to find a free port: %w", err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+// writeAtomic writes a file under a temporary name and renames it, so the
+// orchestrator never reads it half-written
+func writeAtomic(path string, data []byte) error {
+	tmpFile := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmpFile, path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+func taskID(i int) string {
+	return fmt.Sprintf("bench-%d", i)
+}
+
+// add summarizes a measurement
+func (r *Result) add(name string, times []time.Duration) {
+	sample := Sample{Name: name, Count: len(times)}
+	if len(times) == 0 {
+		r.Samples = append(r.Samples, sample)
+		return
+	}
+
+	sorted := append([]time.Duration(nil), times...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, t := range sorted {
+		total += t
+	}
+	sample.Mean = total / time.Duration(len(sorted))
+	sample.P50 = sorted[len(sorted)/2]
+	sample.P95 = sorted[(len(sorted)*95)/100]
+	sample.Max = sorted[len(sorted)-1]
+	r.Samples = append(r.Samples, sample)
+}
@@ -0,0 +1,239 @@
+// Package changelog writes what a session changed as a CHANGELOG.md, one
+// section per task with the files it changed, its completion notes and a
+// link to its diff, for a release or pull request description.
+package changelog
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aristath/claude-swarm/internal/state"
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+const (
+	// File is the changelog's name in the session directory
+	File = "CHANGELOG.md"
+
+	// DiffsDir is the session directory holding each task's diff, as
+	// <task>.diff
+	DiffsDir = "diffs"
+)
+
+// fileStat is a file a task changed, with the lines its diff added and
+// removed
+type fileStat struct {
+	path     string
+	added    int
+	removed  int
+	diffed   bool // In the task's diff, rather than only reported
+	large    bool // Changed, but too large to diff
+	reported bool // In the agent's --files-changed
+}
+
+// WriteDiffs writes the diffs of tasks' changes to files through the
+// orchestrator, all tasks' without taskIDs. A task with no changes tracked
+// by this process keeps the diff an earlier one wrote.
+func WriteDiffs(swarmDir string, s *state.SwarmState, taskIDs ...string) error {
+	if len(taskIDs) == 0 {
+		for _, task := range s.GetTasks() {
+			taskIDs = append(taskIDs, task.ID)
+		}
+	}
+
+	dir := filepath.Join(swarmDir, DiffsDir)
+	for _, taskID := range taskIDs {
+		unified, paths := s.TaskDiff(taskID)
+		if len(paths) == 0 {
+			continue
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create diffs directory: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, taskID+".diff"), []byte(unified), 0644); err != nil {
+			return fmt.Errorf("failed to write diff of %s: %w", taskID, err)
+		}
+	}
+	return nil
+}
+
+// Markdown formats the changelog of a session from its state and the diffs
+// in its directory. Paths under root are shown relative to it, and diffs
+// are linked relative to linkDir, the directory the changelog goes in; ""
+// links them by absolute path.
+func Markdown(swarmDir string, s *state.SwarmState, root, linkDir string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Changelog: %s\n\n", s.Workflow.Name)
+
+	var sections strings.Builder
+	tasks, total := 0, 0
+	for _, task := range s.GetTasks() {
+		agent := s.GetAgent(task.ID)
+		if agent == nil {
+			continue
+		}
+		diffPath := filepath.Join(swarmDir, DiffsDir, task.ID+".diff")
+		files := taskFiles(diffPath, agent.Completion)
+		if len(files) == 0 && agent.Completion.Empty() {
+			continue
+		}
+		tasks++
+		total += len(files)
+
+		fmt.Fprintf(&sections, "## %s\n\n", task.ID)
+		if description := firstLine(task.Description); description != "" {
+			fmt.Fprintf(&sections, "%s\n\n", description)
+		}
+
+		status := string(agent.Status)
+		if summary := agent.Completion.Summary(); summary != "" {
+			status += ", " + summary
+		}
+		fmt.Fprintf(&sections, "Status: %s\n\n", status)
+
+		for _, file := range files {
+			fmt.Fprintf(&sections, "- `%s`", displayPath(root, file.path))
+			switch {
+			case file.diffed:
+				fmt.Fprintf(&sections, " (+%d -%d)", file.added, file.removed)
+			case file.large:
+				sections.WriteString(" (too large to diff)")
+			case file.reported:
+				sections.WriteString(" (reported by the agent)")
+			}
+			sections.WriteString("\n")
+		}
+		if len(files) > 0 {
+			sections.WriteString("\n")
+		}
+
+		if agent.Completion != nil && agent.Completion.Notes != "" {
+			fmt.Fprintf(&sections, "%s\n\n", agent.Completion.Notes)
+		}
+		if _, err := os.Stat(diffPath); err == nil {
+			fmt.Fprintf(&sections, "[Diff](%s)\n\n", link(linkDir, diffPath))
+		}
+	}
+
+	fmt.Fprintf(&b, "Session %s, %s.", s.SessionID, s.StartedAt.Format("2006-01-02"))
+	if tasks == 0 {
+		b.WriteString(" No changes were recorded.\n")
+		return b.String()
+	}
+	fmt.Fprintf(&b, " %d %s changed %d %s.\n\n", tasks, plural(tasks, "task"), total, plural(total, "file"))
+	b.WriteString(strings.TrimSuffix(sections.String(), "\n"))
+	return b.String()
+}
+
+// Write writes the diffs this process tracked and the changelog, to path
+// or to File in the session directory for "", and returns where it went
+func Write(swarmDir string, s *state.SwarmState, root, path string) (string, error) {
+	if err := WriteDiffs(swarmDir, s); err != nil {
+		return "", err
+	}
+	if path == "" {
+		path = filepath.Join(swarmDir, File)
+	}
+	if err := os.WriteFile(path, []byte(Markdown(swarmDir, s, root, filepath.Dir(path))), 0644); err != nil {
+		return "", fmt.Errorf("failed to write changelog: %w", err)
+	}
+	return path, nil
+}
+
+// taskFiles returns the files in a task's diff, with their line counts,
+// and those its agent reported changing
+func taskFiles(diffPath string, completion *workflow.Completion) []*fileStat {
+	var files []*fileStat
+	byPath := make(map[string]*fileStat)
+	add := func(path string) *fileStat {
+		if file, ok := byPath[path]; ok {
+			return file
+		}
+		file := &fileStat{path: path}
+		byPath[path] = file
+		files = append(files, file)
+		return file
+	}
+
+	if data, err := os.Open(diffPath); err == nil {
+		defer data.Close()
+
+		var current *fileStat
+		scanner := bufio.NewScanner(data)
+		scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "+++ b"):
+				current = add(strings.TrimPrefix(line, "+++ b"))
+				current.diffed = true
+			case strings.HasPrefix(line, "--- a"):
+			case strings.HasPrefix(line, "# ") && strings.HasSuffix(line, ": too large to diff"):
+				add(strings.TrimSuffix(strings.TrimPrefix(line, "# "), ": too large to diff")).large = true
+				current = nil
+			case current == nil:
+			case strings.HasPrefix(line, "+"):
+				current.added++
+			case strings.HasPrefix(line, "-"):
+				current.removed++
+			}
+		}
+	}
+
+	if completion != nil {
+		for _, path := range completion.FilesChanged {
+			if abs, err := filepath.Abs(path); err == nil && byPath[abs] != nil {
+				continue
+			}
+			add(path).reported = true
+		}
+	}
+	return files
+}
+
+// displayPath shows a path relative to root when it is under it
+func displayPath(root, path string) string {
+	if root == "" || !filepath.IsAbs(path) {
+		return path
+	}
+	if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+// link returns the path of a file relative to the directory linking to it
+func link(linkDir, path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil || linkDir == "" {
+		return path
+	}
+	dir, err := filepath.Abs(linkDir)
+	if err != nil {
+		return abs
+	}
+	if rel, err := filepath.Rel(dir, abs); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return abs
+}
+
+// firstLine returns the first non-empty line of text
+func firstLine(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
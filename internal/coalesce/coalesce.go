@@ -0,0 +1,91 @@
+// Package coalesce merges identical operations that run at the same time
+// into one execution whose result is handed to every caller. When a swarm
+// starts, every agent tends to read the same plan and main files and run
+// the same searches; coalescing turns those bursts into a single read or
+// search each.
+package coalesce
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// Group coalesces calls with the same key
+type Group struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+type call struct {
+	done chan struct{}
+	val  any
+	err  error
+}
+
+// Shared is the group shared by the message handler and the HTTP API, so
+// an agent on the file bus and one on HTTP share work too
+var Shared = &Group{}
+
+// Do runs fn, unless a call with the same key is already running, in which
+// case it waits for that call and returns its result. shared reports
+// whether the result came from another caller's execution. Callers share
+// the result, so they must not modify it.
+func (g *Group) Do(key string, fn func() (any, error)) (val any, shared bool, err error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call)
+	}
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		<-c.done
+		return c.val, true, c.err
+	}
+
+	c := &call{done: make(chan struct{})}
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		if g.calls[key] == c {
+			delete(g.calls, key)
+		}
+		g.mu.Unlock()
+		close(c.done)
+	}()
+
+	c.val, c.err = fn()
+	return c.val, false, c.err
+}
+
+// Forget makes later calls with key run again instead of waiting for the
+// call already running, whose result may be out of date
+func (g *Group) Forget(key string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	delete(g.calls, key)
+}
+
+// do is Do for a typed result. The running call uses the context of
+// whichever caller started it; if that caller went away and cancelled it,
+// the others run the operation themselves instead of failing with it.
+func do[T any](ctx context.Context, key string, fn func() (T, error)) (T, bool, error) {
+	val, shared, err := Shared.Do(key, func() (any, error) {
+		return fn()
+	})
+	if shared && errors.Is(err, context.Canceled) && ctx.Err() == nil {
+		result, err := fn()
+		return result, false, err
+	}
+
+	result, _ := val.(T)
+	return result, shared, err
+}
+
+// key joins the parts of an operation into a key
+func key(parts ...string) string {
+	return strings.Join(parts, "\x00")
+}
@@ -0,0 +1,60 @@
+package coalesce
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/aristath/claude-swarm/internal/filecache"
+	"github.com/aristath/claude-swarm/internal/symbols"
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// File is the result of a coalesced file read
+type File struct {
+	Content  string
+	CacheHit bool // Served from the read cache
+}
+
+// ReadFile reads a file through the shared read cache
+func ReadFile(path string) (File, bool, error) {
+	return do(context.Background(), key("read", path), func() (File, error) {
+		content, hit, err := filecache.Shared.Read(path)
+		return File{Content: content, CacheHit: hit}, err
+	})
+}
+
+// Invalidate drops a file's cached content and forgets reads of it that
+// are running, so reads after a write see the new content. Writers call it
+// before they return.
+func Invalidate(path string) {
+	filecache.Shared.Invalidate(path)
+	Shared.Forget(key("read", path))
+}
+
+// Glob returns the files matching a pattern
+func Glob(pattern string) ([]string, bool, error) {
+	return do(context.Background(), key("glob", pattern), func() ([]string, error) {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("glob failed: %w", err)
+		}
+		return matches, nil
+	})
+}
+
+// Grep runs grep with args, returning up to limit lines
+func Grep(ctx context.Context, args []string, limit int) ([]string, bool, error) {
+	return do(ctx, key("grep", strconv.Itoa(limit), strings.Join(args, "\x00")), func() ([]string, error) {
+		return workflow.Grep(ctx, args, limit)
+	})
+}
+
+// SearchSymbols looks up a symbol under root
+func SearchSymbols(ctx context.Context, root, name, kind string, refs bool) ([]string, bool, error) {
+	return do(ctx, key("code_search", root, name, kind, strconv.FormatBool(refs)), func() ([]string, error) {
+		return symbols.Search(ctx, root, name, kind, refs)
+	})
+}
@@ -0,0 +1,244 @@
+// Package diff computes line-based differences between texts and renders
+// them as unified diffs
+package diff
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// OpKind identifies a line operation in an edit script
+type OpKind int
+
+const (
+	OpEqual OpKind = iota
+	OpDelete
+	OpInsert
+)
+
+// Op is a single line of an edit script
+type Op struct {
+	Kind OpKind
+	Line string
+	// OldLine and NewLine are 0-based line numbers in the old and new text;
+	// -1 when the line does not exist on that side
+	OldLine int
+	NewLine int
+}
+
+// Lines splits text into lines, keeping a trailing line without newline
+func Lines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Compute returns the edit script turning a into b using Myers' algorithm
+func Compute(a, b []string) []Op {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return nil
+	}
+
+	offset := max + 1
+	v := make([]int, 2*max+2)
+	trace := [][]int{}
+
+	// Forward pass, keeping a copy of V for every D to backtrack through
+	var d int
+search:
+	for d = 0; d <= max; d++ {
+		snapshot := make([]int, len(v))
+		copy(snapshot, v)
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// Backtrack from (n, m) to (0, 0)
+	ops := []Op{}
+	x, y := n, m
+	for ; d > 0; d-- {
+		vPrev := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && vPrev[offset+k-1] < vPrev[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := vPrev[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, Op{Kind: OpEqual, Line: a[x], OldLine: x, NewLine: y})
+		}
+
+		if x == prevX {
+			y--
+			ops = append(ops, Op{Kind: OpInsert, Line: b[y], OldLine: -1, NewLine: y})
+		} else {
+			x--
+			ops = append(ops, Op{Kind: OpDelete, Line: a[x], OldLine: x, NewLine: -1})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		ops = append(ops, Op{Kind: OpEqual, Line: a[x], OldLine: x, NewLine: y})
+	}
+
+	// Reverse into forward order
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+
+	return ops
+}
+
+// Unified renders the difference between oldText and newText as a unified
+// diff with three lines of context. It returns "" when the texts are equal.
+func Unified(oldName, newName, oldText, newText string) string {
+	ops := Compute(Lines(oldText), Lines(newText))
+
+	changed := false
+	for _, op := range ops {
+		if op.Kind != OpEqual {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return ""
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+
+	const context = 3
+	for i := 0; i < len(ops); {
+		// Find the next change
+		for i < len(ops) && ops[i].Kind == OpEqual {
+			i++
+		}
+		if i >= len(ops) {
+			break
+		}
+
+		// Extend the hunk while changes are within 2*context lines of each other
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for end < len(ops) {
+			if ops[end].Kind != OpEqual {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].Kind == OpEqual {
+				run++
+			}
+			if run >= len(ops) || run-end > 2*context {
+				end += context
+				if end > len(ops) {
+					end = len(ops)
+				}
+				break
+			}
+			end = run
+		}
+
+		writeHunk(&out, ops[start:end])
+		i = end
+	}
+
+	return out.String()
+}
+
+// UnifiedFile renders the change to a file as a unified diff, naming it
+// a/<path> and b/<path> like git does
+func UnifiedFile(path, oldText, newText string) string {
+	name := strings.TrimPrefix(filepath.ToSlash(path), "/")
+	return Unified("a/"+name, "b/"+name, oldText, newText)
+}
+
+// writeHunk writes a single hunk with its header
+func writeHunk(out *strings.Builder, ops []Op) {
+	oldStart, newStart := -1, -1
+	oldCount, newCount := 0, 0
+	for _, op := range ops {
+		if op.Kind != OpInsert {
+			if oldStart < 0 {
+				oldStart = op.OldLine
+			}
+			oldCount++
+		}
+		if op.Kind != OpDelete {
+			if newStart < 0 {
+				newStart = op.NewLine
+			}
+			newCount++
+		}
+	}
+
+	fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+
+	for _, op := range ops {
+		prefix := " "
+		switch op.Kind {
+		case OpDelete:
+			prefix = "-"
+		case OpInsert:
+			prefix = "+"
+		}
+
+		line := op.Line
+		out.WriteString(prefix)
+		out.WriteString(line)
+		if !strings.HasSuffix(line, "\n") {
+			out.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats a hunk range; start is 0-based or -1 for empty ranges
+func hunkRange(start, count int) string {
+	if count == 0 {
+		if start < 0 {
+			start = 0
+		}
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
@@ -0,0 +1,175 @@
+package diff
+
+import (
+	"strings"
+)
+
+// Hunk is a run of changed lines: lines [OldStart, OldEnd) of the old text
+// replaced by Lines, which are lines [NewStart, NewEnd) of the new text.
+// Pure insertions have OldStart == OldEnd.
+type Hunk struct {
+	OldStart, OldEnd int
+	NewStart, NewEnd int
+	Lines            []string
+}
+
+// Hunks returns the changes turning oldText into newText, in order
+func Hunks(oldText, newText string) []Hunk {
+	var hunks []Hunk
+	var current *Hunk
+	oldLine, newLine := 0, 0
+	for _, op := range Compute(Lines(oldText), Lines(newText)) {
+		if op.Kind == OpEqual {
+			if current != nil {
+				hunks = append(hunks, *current)
+				current = nil
+			}
+			oldLine++
+			newLine++
+			continue
+		}
+
+		if current == nil {
+			current = &Hunk{OldStart: oldLine, OldEnd: oldLine, NewStart: newLine, NewEnd: newLine}
+		}
+		if op.Kind == OpDelete {
+			oldLine++
+			current.OldEnd = oldLine
+		} else {
+			current.Lines = append(current.Lines, op.Line)
+			newLine++
+			current.NewEnd = newLine
+		}
+	}
+	if current != nil {
+		hunks = append(hunks, *current)
+	}
+	return hunks
+}
+
+// touches reports whether two line ranges overlap or are adjacent, so an
+// insertion touches the lines around it
+func touches(start1, end1, start2, end2 int) bool {
+	return start1 <= end2 && start2 <= end1
+}
+
+// Overlaps reports whether two successive changes of a text, from base to
+// middle and then from middle to result, changed the same or adjacent
+// lines of the middle text
+func Overlaps(base, middle, result string) bool {
+	first := Hunks(base, middle)
+	for _, second := range Hunks(middle, result) {
+		for _, hunk := range first {
+			if touches(hunk.NewStart, hunk.NewEnd, second.OldStart, second.OldEnd) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Merge3 merges the changes two texts made to a common base. Changes to
+// separate lines are combined; where both changed the same or adjacent
+// lines differently, the result holds conflict markers with both versions
+// and the base, labelled with the names. It reports whether there were
+// conflicts.
+func Merge3(base, ours, theirs, oursName, theirsName string) (string, bool) {
+	baseLines := Lines(base)
+	type sided struct {
+		Hunk
+		ours bool
+	}
+	var all []sided
+	for _, hunk := range Hunks(base, ours) {
+		all = append(all, sided{hunk, true})
+	}
+	theirHunks := Hunks(base, theirs)
+	// Merge the two ordered lists by base position
+	merged := make([]sided, 0, len(all)+len(theirHunks))
+	i := 0
+	for _, hunk := range theirHunks {
+		for i < len(all) && all[i].OldStart <= hunk.OldStart {
+			merged = append(merged, all[i])
+			i++
+		}
+		merged = append(merged, sided{hunk, false})
+	}
+	merged = append(merged, all[i:]...)
+
+	var out strings.Builder
+	conflicted := false
+	position := 0
+	for i := 0; i < len(merged); {
+		// Gather the hunks touching each other into a region of the base
+		start, end := merged[i].OldStart, merged[i].OldEnd
+		j := i + 1
+		for j < len(merged) && touches(start, end, merged[j].OldStart, merged[j].OldEnd) {
+			end = max(end, merged[j].OldEnd)
+			j++
+		}
+		region := merged[i:j]
+		i = j
+
+		writeLines(&out, baseLines[position:start])
+		position = end
+
+		var oursHunks, theirsHunks []Hunk
+		for _, hunk := range region {
+			if hunk.ours {
+				oursHunks = append(oursHunks, hunk.Hunk)
+			} else {
+				theirsHunks = append(theirsHunks, hunk.Hunk)
+			}
+		}
+		oursText := apply(baseLines, start, end, oursHunks)
+		theirsText := apply(baseLines, start, end, theirsHunks)
+
+		switch {
+		case len(theirsHunks) == 0 || oursText == theirsText:
+			out.WriteString(oursText)
+		case len(oursHunks) == 0:
+			out.WriteString(theirsText)
+		default:
+			conflicted = true
+			out.WriteString("<<<<<<< " + oursName + "\n")
+			out.WriteString(withNewline(oursText))
+			out.WriteString("||||||| base\n")
+			out.WriteString(withNewline(strings.Join(baseLines[start:end], "")))
+			out.WriteString("=======\n")
+			out.WriteString(withNewline(theirsText))
+			out.WriteString(">>>>>>> " + theirsName + "\n")
+		}
+	}
+	writeLines(&out, baseLines[position:])
+
+	return out.String(), conflicted
+}
+
+// apply returns lines [start, end) of the base with one side's hunks
+// within them applied
+func apply(base []string, start, end int, hunks []Hunk) string {
+	var out strings.Builder
+	position := start
+	for _, hunk := range hunks {
+		writeLines(&out, base[position:hunk.OldStart])
+		writeLines(&out, hunk.Lines)
+		position = hunk.OldEnd
+	}
+	writeLines(&out, base[position:end])
+	return out.String()
+}
+
+// writeLines writes lines, which keep their newlines
+func writeLines(out *strings.Builder, lines []string) {
+	for _, line := range lines {
+		out.WriteString(line)
+	}
+}
+
+// withNewline ends text with a newline, so conflict markers start lines
+func withNewline(text string) string {
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		return text + "\n"
+	}
+	return text
+}
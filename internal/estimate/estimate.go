@@ -0,0 +1,263 @@
+// Package estimate roughly predicts what a workflow will cost and how long
+// it will take before it runs, from its prompt sizes, its shape and how
+// tasks of each agent type went in past sessions.
+package estimate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// DefaultUSDPerMillionTokens is the blended input and output price used to
+// turn tokens into cost
+const DefaultUSDPerMillionTokens = workflow.DefaultUSDPerMillionTokens
+
+// contextTokens is the size of the instructions and session context every
+// agent is spawned with besides its prompt
+const contextTokens = 2500
+
+// maxDepth bounds how deeply sub-workflows are estimated
+const maxDepth = 5
+
+// defaultProfile is assumed for agent types without history
+var defaultProfile = Profile{OutputTokens: 1500, Duration: 5 * time.Minute}
+
+// workTokens is what agents of a type spend reading and reasoning beyond
+// their prompt and output, as a multiple of the prompt and context
+var workTokens = map[string]float64{
+	"Explore":         8,
+	"Plan":            4,
+	"general-purpose": 12,
+}
+
+// defaultWorkTokens is the multiple for agent types not in workTokens
+const defaultWorkTokens = 10
+
+// Task is the estimate for one task
+type Task struct {
+	ID        string        `json:"id"`
+	AgentType string        `json:"agent_type"`
+	Tokens    int           `json:"tokens"`
+	Duration  time.Duration `json:"duration_ns"`
+	// Historical is set when the agent type has past tasks to go by
+	Historical bool `json:"historical"`
+}
+
+// Estimate is a workflow's predicted cost and duration
+type Estimate struct {
+	Workflow string  `json:"workflow"`
+	Tasks    []Task  `json:"tasks"`
+	Tokens   int     `json:"tokens"`
+	Cost     float64 `json:"cost_usd"`
+	// Duration is the wall-clock time, with tasks running in parallel as
+	// their dependencies and max_parallel allow
+	Duration time.Duration `json:"duration_ns"`
+	// AgentTime is the time of all tasks added up
+	AgentTime time.Duration `json:"agent_time_ns"`
+	// HistoryTasks is how many past tasks the estimate is based on
+	HistoryTasks int `json:"history_tasks"`
+}
+
+// Estimator estimates workflows
+type Estimator struct {
+	History             History
+	USDPerMillionTokens float64
+}
+
+// NewEstimator creates an estimator going by a history, at the default price
+func NewEstimator(history History) *Estimator {
+	return &Estimator{History: history, USDPerMillionTokens: DefaultUSDPerMillionTokens}
+}
+
+// Tokens approximates the number of tokens in a text
+func Tokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// Estimate estimates a parsed workflow. Repeated tasks are counted once,
+// and sub-workflows are estimated from their files.
+func (e *Estimator) Estimate(wf *workflow.Workflow) *Estimate {
+	return e.estimate(wf, 0)
+}
+
+func (e *Estimator) estimate(wf *workflow.Workflow, depth int) *Estimate {
+	est := &Estimate{Workflow: wf.Name, HistoryTasks: e.History.Tasks()}
+
+	durations := make(map[string]time.Duration, len(wf.Tasks))
+	for _, task := range wf.Tasks {
+		t := e.estimateTask(wf, task, depth)
+		est.Tasks = append(est.Tasks, t)
+		est.Tokens += t.Tokens
+		est.AgentTime += t.Duration
+		durations[task.ID] = t.Duration
+	}
+
+	est.Cost = float64(est.Tokens) / 1e6 * e.USDPerMillionTokens
+	est.Duration = schedule(wf, durations)
+	return est
+}
+
+// estimateTask estimates one task
+func (e *Estimator) estimateTask(wf *workflow.Workflow, task workflow.Task, depth int) Task {
+	t := Task{ID: task.ID, AgentType: agentType(task)}
+
+	// Aggregate tasks are done by the orchestrator, at no cost
+	if task.IsAggregate() {
+		t.AgentType = workflow.TaskTypeAggregate
+		return t
+	}
+
+	if task.IsSubWorkflow() {
+		t.AgentType = workflow.TaskTypeWorkflow
+		if depth >= maxDepth {
+			return t
+		}
+		child, err := workflow.NewParser().ParseFile(task.Workflow)
+		if err != nil {
+			return t
+		}
+		sub := e.estimate(child, depth+1)
+		t.Tokens, t.Duration = sub.Tokens, sub.Duration
+		return t
+	}
+
+	profile, ok := e.History[t.AgentType]
+	t.Historical = ok
+	if !ok {
+		profile = defaultProfile
+	}
+
+	t.Duration = profile.Duration
+
+	// Usage agents reported beats approximating from prompts and outputs
+	if profile.Tokens > 0 {
+		t.Tokens = profile.Tokens
+		return t
+	}
+
+	multiple, ok := workTokens[t.AgentType]
+	if !ok {
+		multiple = defaultWorkTokens
+	}
+	input := Tokens(wf.InterpolateParams(task.Prompt)) + contextTokens
+	t.Tokens = input + int(float64(input)*multiple) + profile.OutputTokens
+	return t
+}
+
+// schedule returns how long a workflow takes when each task starts as soon
+// as its dependencies are done and a slot under max_parallel is free
+func schedule(wf *workflow.Workflow, durations map[string]time.Duration) time.Duration {
+	finished := make(map[string]time.Duration, len(wf.Tasks))
+	var running []time.Duration // Finish times of running tasks
+	var now time.Duration
+
+	pending := append([]workflow.Task(nil), wf.Tasks...)
+	for len(pending) > 0 {
+		// Start every ready task there is room for
+		for i := 0; i < len(pending); i++ {
+			if wf.MaxParallel > 0 && len(running) >= wf.MaxParallel {
+				break
+			}
+			task := pending[i]
+			if !ready(task, finished, now) {
+				continue
+			}
+			end := now + durations[task.ID]
+			finished[task.ID] = end
+			running = append(running, end)
+			pending = append(pending[:i], pending[i+1:]...)
+			i--
+		}
+		if len(running) == 0 {
+			break // Unsatisfiable dependencies; validation reports them
+		}
+
+		// Move on to the next task finishing
+		sort.Slice(running, func(i, j int) bool { return running[i] < running[j] })
+		now = running[0]
+		running = running[1:]
+	}
+
+	var end time.Duration
+	for _, t := range finished {
+		end = max(end, t)
+	}
+	return end
+}
+
+// ready reports whether a task's dependencies are all done by now
+func ready(task workflow.Task, finished map[string]time.Duration, now time.Duration) bool {
+	for _, dep := range task.DependsOn {
+		end, ok := finished[dep]
+		if !ok || end > now {
+			return false
+		}
+	}
+	return true
+}
+
+// agentType returns the agent type a task is spawned as
+func agentType(task workflow.Task) string {
+	if task.AgentType == "" {
+		return "general-purpose"
+	}
+	return task.AgentType
+}
+
+// Summary is a one-line estimate, e.g. "6 tasks, ~210k tokens (~$1.26),
+// ~25m wall clock, 1h10m of agent time"
+func (e *Estimate) Summary() string {
+	return fmt.Sprintf("%d tasks, ~%s tokens (~$%.2f), ~%s wall clock, %s of agent time",
+		len(e.Tasks), formatTokens(e.Tokens), e.Cost, formatDuration(e.Duration), formatDuration(e.AgentTime))
+}
+
+// String is the summary followed by the estimate of each task
+func (e *Estimate) String() string {
+	var b strings.Builder
+	b.WriteString(e.Summary())
+	b.WriteString("\n")
+	if e.HistoryTasks > 0 {
+		fmt.Fprintf(&b, "Based on %d past tasks and prompt sizes\n", e.HistoryTasks)
+	} else {
+		b.WriteString("No past sessions found; based on prompt sizes and defaults\n")
+	}
+	b.WriteString("\n")
+
+	idWidth := len("TASK")
+	for _, t := range e.Tasks {
+		idWidth = max(idWidth, len(t.ID))
+	}
+	fmt.Fprintf(&b, "%-*s  %-16s  %8s  %8s\n", idWidth, "TASK", "AGENT", "TOKENS", "TIME")
+	defaults := false
+	for _, t := range e.Tasks {
+		marker := ""
+		if e.HistoryTasks > 0 && !t.Historical && t.AgentType != workflow.TaskTypeWorkflow && t.AgentType != workflow.TaskTypeAggregate {
+			marker = " *"
+			defaults = true
+		}
+		fmt.Fprintf(&b, "%-*s  %-16s  %8s  %8s%s\n", idWidth, t.ID, t.AgentType, formatTokens(t.Tokens), formatDuration(t.Duration), marker)
+	}
+	if defaults {
+		b.WriteString("\n* no past tasks of this agent type; defaults used\n")
+	}
+	return b.String()
+}
+
+// formatTokens formats a token count as 850, 12k or 1.2M
+func formatTokens(n int) string {
+	return workflow.FormatTokens(int64(n))
+}
+
+// formatDuration formats a duration to the minute, or second when short
+func formatDuration(d time.Duration) string {
+	if d < time.Minute {
+		return d.Round(time.Second).String()
+	}
+	d = d.Round(time.Minute)
+	s := d.String()
+	return strings.TrimSuffix(s, "0s")
+}
@@ -0,0 +1,104 @@
+package estimate
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// Profile is how tasks of one agent type went in past sessions
+type Profile struct {
+	Tasks        int           // Completed tasks seen
+	OutputTokens int           // Average output size, in tokens
+	Duration     time.Duration // Average time from spawn to completion
+
+	// Tokens is the average the agents reported spending in all, for
+	// tasks whose agents reported usage; 0 if none did
+	Tokens int
+}
+
+// History profiles past tasks by agent type
+type History map[string]Profile
+
+// session is the part of a saved session state the history reads
+type session struct {
+	Workflow *workflow.Workflow
+	Agents   map[string]*workflow.AgentState
+}
+
+// LoadHistory profiles the completed tasks of the sessions under root,
+// usually ~/.claude-swarm. Sessions whose state can't be read are skipped.
+func LoadHistory(root string) (History, error) {
+	paths, err := filepath.Glob(filepath.Join(root, "*", "state.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	type totals struct {
+		tasks    int
+		tokens   int
+		duration time.Duration
+		reported int // tasks with reported usage, not counted in tokens
+		used     int64
+	}
+	byType := map[string]*totals{}
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var s session
+		if err := json.Unmarshal(data, &s); err != nil || s.Workflow == nil {
+			continue
+		}
+
+		for _, task := range s.Workflow.Tasks {
+			agent := s.Agents[task.ID]
+			if agent == nil || agent.Status != workflow.TaskStatusCompleted || agent.FinishedAt.Before(agent.StartedAt) {
+				continue
+			}
+			t := byType[agentType(task)]
+			if t == nil {
+				t = &totals{}
+				byType[agentType(task)] = t
+			}
+			t.tasks++
+			if used := agent.Tokens.Tokens(); used > 0 {
+				t.reported++
+				t.used += used
+			} else {
+				t.tokens += Tokens(agent.Output)
+			}
+			t.duration += agent.FinishedAt.Sub(agent.StartedAt)
+		}
+	}
+
+	history := make(History, len(byType))
+	for name, t := range byType {
+		profile := Profile{
+			Tasks:    t.tasks,
+			Duration: t.duration / time.Duration(t.tasks),
+		}
+		if estimated := t.tasks - t.reported; estimated > 0 {
+			profile.OutputTokens = t.tokens / estimated
+		}
+		if t.reported > 0 {
+			profile.Tokens = int(t.used / int64(t.reported))
+		}
+		history[name] = profile
+	}
+	return history, nil
+}
+
+// Tasks returns how many completed tasks the history is built from
+func (h History) Tasks() int {
+	total := 0
+	for _, profile := range h {
+		total += profile.Tasks
+	}
+	return total
+}
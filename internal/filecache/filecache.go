@@ -0,0 +1,123 @@
+// Package filecache caches file contents for reads through the
+// orchestrator, so that many agents reading the same large file cost one
+// disk read. Entries are invalidated when a file's size or modification
+// time changes, and the least recently used ones are evicted to stay
+// within a memory budget.
+package filecache
+
+import (
+	"container/list"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultMaxBytes is the memory budget of the shared cache
+const DefaultMaxBytes = 64 << 20
+
+// maxEntryBytes keeps single huge files from flushing the whole cache
+const maxEntryBytes = 8 << 20
+
+type entry struct {
+	path    string
+	modTime time.Time
+	size    int64
+	content string
+}
+
+// Cache is an LRU cache of file contents
+type Cache struct {
+	mu       sync.Mutex
+	maxBytes int64
+	used     int64
+	entries  map[string]*list.Element
+	lru      *list.List // Front is most recently used
+}
+
+// Shared is the cache shared by the message handler and the HTTP API
+var Shared = New(DefaultMaxBytes)
+
+// New creates a cache holding at most maxBytes of content
+func New(maxBytes int64) *Cache {
+	return &Cache{
+		maxBytes: maxBytes,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// Read returns a file's content, from the cache if the file is unchanged.
+// hit reports whether the disk read was saved.
+func (c *Cache) Read(path string) (content string, hit bool, err error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		c.Invalidate(path)
+		return "", false, err
+	}
+	if info.IsDir() {
+		return "", false, fmt.Errorf("read %s: is a directory", path)
+	}
+
+	c.mu.Lock()
+	if elem, ok := c.entries[path]; ok {
+		e := elem.Value.(*entry)
+		if e.modTime.Equal(info.ModTime()) && e.size == info.Size() {
+			c.lru.MoveToFront(elem)
+			c.mu.Unlock()
+			return e.content, true, nil
+		}
+		c.remove(elem)
+	}
+	c.mu.Unlock()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, err
+	}
+	content = string(data)
+
+	// Only cache what was read if the file did not change while reading
+	if int64(len(data)) == info.Size() && info.Size() <= maxEntryBytes {
+		c.add(&entry{path: path, modTime: info.ModTime(), size: info.Size(), content: content})
+	}
+
+	return content, false, nil
+}
+
+// Invalidate drops a file from the cache, after writing it
+func (c *Cache) Invalidate(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[path]; ok {
+		c.remove(elem)
+	}
+}
+
+func (c *Cache) add(e *entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[e.path]; ok {
+		c.remove(elem)
+	}
+	if e.size > c.maxBytes {
+		return
+	}
+
+	c.entries[e.path] = c.lru.PushFront(e)
+	c.used += e.size
+
+	for c.used > c.maxBytes {
+		c.remove(c.lru.Back())
+	}
+}
+
+// remove drops an entry. Callers must hold c.mu.
+func (c *Cache) remove(elem *list.Element) {
+	e := elem.Value.(*entry)
+	c.lru.Remove(elem)
+	delete(c.entries, e.path)
+	c.used -= e.size
+}
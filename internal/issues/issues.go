@@ -0,0 +1,124 @@
+// Package issues mirrors tasks into an issue tracker, so teams can follow
+// agent work next to human work. Each task gets an issue, created when its
+// agent starts unless the workflow names an existing one; the issue is
+// moved through the tracker's workflow as the task runs, and the task's
+// output or error is posted to it when the task finishes.
+package issues
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// maxCommentBytes keeps reports within the trackers' comment size limits
+const maxCommentBytes = 30000
+
+// Tracker is an issue tracker
+type Tracker interface {
+	// Create creates an issue for a task and returns its key
+	Create(ctx context.Context, task workflow.Task) (string, error)
+	// Transition moves an issue to the named status
+	Transition(ctx context.Context, key, status string) error
+	// Comment adds a comment to an issue
+	Comment(ctx context.Context, key, body string) error
+}
+
+// New returns the tracker configured by a workflow, with credentials from
+// the environment
+func New(cfg *workflow.Issues) (Tracker, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	switch cfg.Provider {
+	case workflow.IssueProviderJira:
+		token := os.Getenv("JIRA_API_TOKEN")
+		if token == "" {
+			return nil, fmt.Errorf("JIRA_API_TOKEN is not set")
+		}
+		return &Jira{
+			client:    client,
+			url:       cfg.URL,
+			project:   cfg.Project,
+			issueType: cfg.IssueType,
+			email:     os.Getenv("JIRA_EMAIL"),
+			token:     token,
+		}, nil
+
+	case workflow.IssueProviderLinear:
+		key := os.Getenv("LINEAR_API_KEY")
+		if key == "" {
+			return nil, fmt.Errorf("LINEAR_API_KEY is not set")
+		}
+		url := cfg.URL
+		if url == "" {
+			url = DefaultLinearURL
+		}
+		return &Linear{
+			client: client,
+			url:    url,
+			team:   cfg.Team,
+			apiKey: key,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown issue provider %q", cfg.Provider)
+}
+
+// Report formats a task's output or error as an issue comment
+func Report(agent *workflow.AgentState) string {
+	var body string
+	if agent.Status.IsFailure() {
+		body = fmt.Sprintf("Task %s failed: %s", agent.TaskID, agent.Error)
+	} else {
+		body = fmt.Sprintf("Task %s completed.\n\n%s", agent.TaskID, agent.Output)
+	}
+
+	if len(body) > maxCommentBytes {
+		body = body[:maxCommentBytes] + "\n\n[truncated]"
+	}
+	return body
+}
+
+// doJSON sends a JSON request and decodes the JSON response into out,
+// which may be nil
+func doJSON(ctx context.Context, client *http.Client, req *http.Request, body, out any) error {
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(data))
+		req.ContentLength = int64(len(data))
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s: %s: %s", req.Method, req.URL.Path, resp.Status, bytes.TrimSpace(data))
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package issues
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// Jira creates and updates issues through the Jira REST API. With an email
+// it authenticates like Jira Cloud, with the email and an API token;
+// without one the token is sent as a personal access token, as Jira
+// Server and Data Center expect.
+type Jira struct {
+	client    *http.Client
+	url       string
+	project   string
+	issueType string
+	email     string
+	token     string
+}
+
+// Create creates an issue for a task in the configured project
+func (j *Jira) Create(ctx context.Context, task workflow.Task) (string, error) {
+	issueType := j.issueType
+	if issueType == "" {
+		issueType = "Task"
+	}
+
+	body := map[string]any{
+		"fields": map[string]any{
+			"project":     map[string]string{"key": j.project},
+			"issuetype":   map[string]string{"name": issueType},
+			"summary":     summary(task),
+			"description": task.Prompt,
+		},
+	}
+
+	var created struct {
+		Key string `json:"key"`
+	}
+	if err := j.do(ctx, http.MethodPost, "/rest/api/2/issue", body, &created); err != nil {
+		return "", fmt.Errorf("failed to create Jira issue: %w", err)
+	}
+	return created.Key, nil
+}
+
+// Transition moves an issue through the transition that is named after
+// the status or leads to it
+func (j *Jira) Transition(ctx context.Context, key, status string) error {
+	path := fmt.Sprintf("/rest/api/2/issue/%s/transitions", url.PathEscape(key))
+
+	var available struct {
+		Transitions []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+			To   struct {
+				Name string `json:"name"`
+			} `json:"to"`
+		} `json:"transitions"`
+	}
+	if err := j.do(ctx, http.MethodGet, path, nil, &available); err != nil {
+		return fmt.Errorf("failed to list transitions of %s: %w", key, err)
+	}
+
+	for _, transition := range available.Transitions {
+		if strings.EqualFold(transition.Name, status) || strings.EqualFold(transition.To.Name, status) {
+			body := map[string]any{"transition": map[string]string{"id": transition.ID}}
+			if err := j.do(ctx, http.MethodPost, path, body, nil); err != nil {
+				return fmt.Errorf("failed to transition %s to %s: %w", key, status, err)
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no transition of %s leads to %q", key, status)
+}
+
+// Comment adds a comment to an issue
+func (j *Jira) Comment(ctx context.Context, key, body string) error {
+	path := fmt.Sprintf("/rest/api/2/issue/%s/comment", url.PathEscape(key))
+	if err := j.do(ctx, http.MethodPost, path, map[string]string{"body": body}, nil); err != nil {
+		return fmt.Errorf("failed to comment on %s: %w", key, err)
+	}
+	return nil
+}
+
+func (j *Jira) do(ctx context.Context, method, path string, body, out any) error {
+	req, err := http.NewRequest(method, strings.TrimSuffix(j.url, "/")+path, nil)
+	if err != nil {
+		return err
+	}
+	if j.email != "" {
+		req.SetBasicAuth(j.email, j.token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+j.token)
+	}
+	return doJSON(ctx, j.client, req, body, out)
+}
+
+// summary returns the one-line title of a task's issue
+func summary(task workflow.Task) string {
+	title := task.Description
+	if title == "" {
+		title = task.Prompt
+	}
+	if line, _, found := strings.Cut(title, "\n"); found {
+		title = line
+	}
+	if len(title) > 200 {
+		title = title[:200] + "..."
+	}
+	return fmt.Sprintf("[swarm] %s: %s", task.ID, strings.TrimSpace(title))
+}
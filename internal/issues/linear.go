@@ -0,0 +1,163 @@
+package issues
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// DefaultLinearURL is the Linear GraphQL API
+const DefaultLinearURL = "https://api.linear.app/graphql"
+
+// Linear creates and updates issues through the Linear GraphQL API
+type Linear struct {
+	client *http.Client
+	url    string
+	team   string // Team key, such as "ENG"
+	apiKey string
+
+	mu     sync.Mutex
+	teamID string
+	states map[string]string // Workflow state IDs by lowercase name
+}
+
+// Create creates an issue for a task in the configured team
+func (l *Linear) Create(ctx context.Context, task workflow.Task) (string, error) {
+	if err := l.loadTeam(ctx); err != nil {
+		return "", err
+	}
+
+	var data struct {
+		IssueCreate struct {
+			Success bool `json:"success"`
+			Issue   struct {
+				Identifier string `json:"identifier"`
+			} `json:"issue"`
+		} `json:"issueCreate"`
+	}
+	err := l.query(ctx, `mutation($input: IssueCreateInput!) {
+		issueCreate(input: $input) { success issue { identifier } }
+	}`, map[string]any{
+		"input": map[string]string{
+			"teamId":      l.teamID,
+			"title":       summary(task),
+			"description": task.Prompt,
+		},
+	}, &data)
+	if err != nil {
+		return "", fmt.Errorf("failed to create Linear issue: %w", err)
+	}
+	if !data.IssueCreate.Success {
+		return "", fmt.Errorf("failed to create Linear issue")
+	}
+	return data.IssueCreate.Issue.Identifier, nil
+}
+
+// Transition moves an issue to the team's workflow state with the name
+func (l *Linear) Transition(ctx context.Context, key, status string) error {
+	if err := l.loadTeam(ctx); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	stateID, ok := l.states[strings.ToLower(status)]
+	l.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("team %s has no workflow state %q", l.team, status)
+	}
+
+	err := l.query(ctx, `mutation($id: String!, $input: IssueUpdateInput!) {
+		issueUpdate(id: $id, input: $input) { success }
+	}`, map[string]any{
+		"id":    key,
+		"input": map[string]string{"stateId": stateID},
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to move %s to %s: %w", key, status, err)
+	}
+	return nil
+}
+
+// Comment adds a comment to an issue
+func (l *Linear) Comment(ctx context.Context, key, body string) error {
+	err := l.query(ctx, `mutation($input: CommentCreateInput!) {
+		commentCreate(input: $input) { success }
+	}`, map[string]any{
+		"input": map[string]string{"issueId": key, "body": body},
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to comment on %s: %w", key, err)
+	}
+	return nil
+}
+
+// loadTeam looks up the team's ID and workflow states once
+func (l *Linear) loadTeam(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.teamID != "" {
+		return nil
+	}
+
+	var data struct {
+		Teams struct {
+			Nodes []struct {
+				ID     string `json:"id"`
+				States struct {
+					Nodes []struct {
+						ID   string `json:"id"`
+						Name string `json:"name"`
+					} `json:"nodes"`
+				} `json:"states"`
+			} `json:"nodes"`
+		} `json:"teams"`
+	}
+	err := l.query(ctx, `query($key: String!) {
+		teams(filter: { key: { eq: $key } }) { nodes { id states { nodes { id name } } } }
+	}`, map[string]any{"key": l.team}, &data)
+	if err != nil {
+		return fmt.Errorf("failed to look up Linear team %s: %w", l.team, err)
+	}
+	if len(data.Teams.Nodes) == 0 {
+		return fmt.Errorf("Linear team %s not found", l.team)
+	}
+
+	team := data.Teams.Nodes[0]
+	l.states = make(map[string]string, len(team.States.Nodes))
+	for _, state := range team.States.Nodes {
+		l.states[strings.ToLower(state.Name)] = state.ID
+	}
+	l.teamID = team.ID
+	return nil
+}
+
+// query runs a GraphQL query, decoding its data into out
+func (l *Linear) query(ctx context.Context, query string, variables map[string]any, out any) error {
+	req, err := http.NewRequest(http.MethodPost, l.url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", l.apiKey)
+
+	var resp struct {
+		Data   any `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	resp.Data = out
+
+	body := map[string]any{"query": query, "variables": variables}
+	if err := doJSON(ctx, l.client, req, body, &resp); err != nil {
+		return err
+	}
+	if len(resp.Errors) > 0 {
+		return fmt.Errorf("%s", resp.Errors[0].Message)
+	}
+	return nil
+}
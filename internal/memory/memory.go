@@ -0,0 +1,182 @@
+// Package memory keeps a project's memory across sessions: a markdown file
+// under ~/.claude-swarm/projects/<repo>-<hash>/ that runs append their key
+// decisions and outcomes to, and that later runs in the same repository
+// start from.
+package memory
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aristath/claude-swarm/internal/symbols"
+)
+
+// File is the name of the memory file, in the project directory and in
+// the session directories of runs that read it
+const File = "memory.md"
+
+// DefaultMaxBytes is how much of the memory, its most recent entries, is
+// injected into plans and agent contexts
+const DefaultMaxBytes = 8000
+
+// maxFileBytes bounds the memory file; the oldest entries are dropped
+// beyond it
+const maxFileBytes = 64 * 1024
+
+// entryPrefix starts every entry of the memory file
+const entryPrefix = "## "
+
+// Memory is the memory file of a project
+type Memory struct {
+	root string
+	path string
+}
+
+// ForDir returns the memory of the repository dir is in, or nil when dir
+// is not in a project. The repository is the nearest directory up with a
+// .git, or dir itself when it looks like a project.
+func ForDir(dir string) (*Memory, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve directory: %w", err)
+	}
+
+	root := repoRoot(dir)
+	if root == "" {
+		return nil, nil
+	}
+
+	return &Memory{
+		root: root,
+		path: filepath.Join(os.Getenv("HOME"), ".claude-swarm", "projects", projectKey(root), File),
+	}, nil
+}
+
+// projectKey names a repository's project directory: its base name, with a
+// hash of its path so repositories of the same name don't share memory
+func projectKey(root string) string {
+	sum := sha256.Sum256([]byte(root))
+	return fmt.Sprintf("%s-%s", filepath.Base(root), hex.EncodeToString(sum[:4]))
+}
+
+// repoRoot returns the repository dir is in, or ""
+func repoRoot(dir string) string {
+	for current := dir; ; current = filepath.Dir(current) {
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			return current
+		}
+		if filepath.Dir(current) == current {
+			break
+		}
+	}
+	if symbols.IsProject(dir) {
+		return dir
+	}
+	return ""
+}
+
+// Root returns the repository the memory is for
+func (m *Memory) Root() string {
+	return m.root
+}
+
+// Path returns the memory file
+func (m *Memory) Path() string {
+	return m.path
+}
+
+// Recent returns the most recent entries of the memory that fit in
+// maxBytes, oldest first, or "" when nothing was remembered yet. Zero
+// means DefaultMaxBytes.
+func (m *Memory) Recent(maxBytes int) (string, error) {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+
+	data, err := os.ReadFile(m.path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read project memory: %w", err)
+	}
+
+	_, entries := split(string(data))
+	recent := strings.Join(newest(entries, maxBytes), "")
+	if recent == "" && len(entries) > 0 {
+		// The latest entry alone is too long; keep its beginning
+		recent = entries[len(entries)-1][:maxBytes]
+	}
+	return strings.TrimSpace(recent), nil
+}
+
+// Append adds an entry to the memory, dropping the oldest entries when the
+// file grows too long. The entry starts with a "## " heading.
+func (m *Memory) Append(entry string) error {
+	if err := os.MkdirAll(filepath.Dir(m.path), 0755); err != nil {
+		return fmt.Errorf("failed to create project directory: %w", err)
+	}
+
+	data, err := os.ReadFile(m.path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read project memory: %w", err)
+	}
+	header, entries := split(string(data))
+	if header == "" {
+		header = fmt.Sprintf("# Project memory: %s\n\nKey decisions and outcomes of swarm runs in %s, oldest first. Edit freely; every entry starts with a \"## \" heading.\n\n", filepath.Base(m.root), m.root)
+	}
+
+	entry = strings.TrimSpace(entry) + "\n\n"
+	entries = newest(append(entries, entry), maxFileBytes-len(header))
+
+	tmp := m.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(header+strings.Join(entries, "")), 0644); err != nil {
+		return fmt.Errorf("failed to write project memory: %w", err)
+	}
+	if err := os.Rename(tmp, m.path); err != nil {
+		return fmt.Errorf("failed to write project memory: %w", err)
+	}
+	return nil
+}
+
+// split splits a memory file into the text before its first entry and its
+// entries
+func split(text string) (string, []string) {
+	var starts []int
+	offset := 0
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if strings.HasPrefix(line, entryPrefix) {
+			starts = append(starts, offset)
+		}
+		offset += len(line)
+	}
+	if len(starts) == 0 {
+		return text, nil
+	}
+
+	entries := make([]string, len(starts))
+	for i, start := range starts {
+		end := len(text)
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		entries[i] = text[start:end]
+	}
+	return text[:starts[0]], entries
+}
+
+// newest returns the last entries that fit in maxBytes together
+func newest(entries []string, maxBytes int) []string {
+	size := 0
+	for i := len(entries) - 1; i >= 0; i-- {
+		size += len(entries[i])
+		if size > maxBytes {
+			return entries[i+1:]
+		}
+	}
+	return entries
+}
@@ -0,0 +1,122 @@
+// Package notify delivers notifications about a swarm run to people
+// outside the terminal.
+package notify
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+)
+
+// Mailer sends plain-text email through an SMTP server. It authenticates
+// with SMTP_USERNAME and SMTP_PASSWORD when they are set.
+type Mailer struct {
+	addr     string
+	from     string
+	to       []string
+	username string
+	password string
+}
+
+// NewMailer creates a mailer sending from one address to others
+func NewMailer(addr, from string, to []string) *Mailer {
+	return &Mailer{
+		addr:     addr,
+		from:     from,
+		to:       to,
+		username: os.Getenv("SMTP_USERNAME"),
+		password: os.Getenv("SMTP_PASSWORD"),
+	}
+}
+
+// smtpTimeout bounds connecting to the SMTP server and the whole exchange
+// with it, so an unresponsive server cannot hold up notifications
+const smtpTimeout = 30 * time.Second
+
+// Send sends an email
+func (m *Mailer) Send(subject, body string) error {
+	host, port, err := net.SplitHostPort(m.addr)
+	if err != nil {
+		return fmt.Errorf("invalid SMTP address %s: %w", m.addr, err)
+	}
+
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, host)
+	}
+
+	dialer := net.Dialer{Timeout: smtpTimeout}
+	conn, err := dialer.Dial("tcp", m.addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", m.addr, err)
+	}
+	if err := conn.SetDeadline(time.Now().Add(smtpTimeout)); err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to connect to %s: %w", m.addr, err)
+	}
+
+	// Port 465 speaks TLS from the start; others upgrade with STARTTLS
+	// when the server offers it
+	if port == "465" {
+		conn = tls.Client(conn, &tls.Config{ServerName: host})
+	}
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to connect to %s: %w", m.addr, err)
+	}
+	defer client.Close()
+
+	if port != "465" {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+				return fmt.Errorf("failed to start TLS with %s: %w", m.addr, err)
+			}
+		}
+	}
+	if auth != nil {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return fmt.Errorf("SMTP authentication failed: %s does not support AUTH", m.addr)
+		}
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+	if err := client.Mail(m.from); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	for _, to := range m.to {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("failed to send email to %s: %w", to, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	if _, err := w.Write(m.message(subject, body)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return client.Quit()
+}
+
+// message formats the headers and body of an email
+func (m *Mailer) message(subject, body string) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", m.from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(m.to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", strings.NewReplacer("\r", " ", "\n", " ").Replace(subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n"))
+	return []byte(b.String())
+}
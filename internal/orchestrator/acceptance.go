@@ -0,0 +1,61 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// briefSection renders the task's description and acceptance criteria,
+// which the agent's context shows apart from its prompt
+func (o *Orchestrator) briefSection(task workflow.Task) string {
+	var b strings.Builder
+	if description := strings.TrimSpace(o.state.Workflow.InterpolateParams(task.Description)); description != "" {
+		fmt.Fprintf(&b, "\n## Description\n%s\n", description)
+	}
+	if criteria := o.state.Workflow.AcceptanceCriteria(task); len(criteria) > 0 {
+		fmt.Fprintf(&b, `
+## Acceptance Criteria
+Your work is done when all of these hold. Check each one before you
+complete, and say in your completion output how each is met; the
+criteria are echoed back to you when you complete.
+
+%s`, workflow.Checklist(criteria))
+	}
+	return b.String()
+}
+
+// dependencyCriteria renders a dependency's acceptance criteria, for the
+// tasks reviewing its output to check it against
+func (o *Orchestrator) dependencyCriteria(depID string) string {
+	dep := o.state.GetTask(depID)
+	if dep == nil || len(dep.AcceptanceCriteria) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("## Acceptance criteria of task: %s\n%s\n", depID, workflow.Checklist(o.state.Workflow.AcceptanceCriteria(*dep)))
+}
+
+// writeAcceptanceCriteria publishes the task's acceptance criteria for
+// swarm-agent complete to echo back
+func (o *Orchestrator) writeAcceptanceCriteria(task workflow.Task, agentDir string) error {
+	criteria := o.state.Workflow.AcceptanceCriteria(task)
+	if len(criteria) == 0 {
+		return nil
+	}
+	if err := os.WriteFile(filepath.Join(agentDir, workflow.AcceptanceCriteriaFile), []byte(workflow.Checklist(criteria)), 0644); err != nil {
+		return fmt.Errorf("failed to write acceptance criteria: %w", err)
+	}
+	return nil
+}
+
+// completionCriteria returns a completed task's acceptance criteria, for
+// post_complete hooks to validate its output against
+func (o *Orchestrator) completionCriteria(taskID string) []string {
+	if task := o.state.GetTask(taskID); task != nil {
+		return o.state.Workflow.AcceptanceCriteria(*task)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+const (
+	// agentLogInterval is how often agents' transcripts are read
+	agentLogInterval = time.Second
+
+	// agentLogBacklog caps how much of a transcript is read at once; a
+	// larger backlog, such as a resumed run's, is skipped to its end
+	agentLogBacklog = 64 * 1024
+)
+
+// startAgentLogTail tails the transcripts of running agents, the agent.log
+// files swarm-agent and spawned processes append to, into the TUI's log.
+// It returns a function that stops it.
+func (o *Orchestrator) startAgentLogTail(ctx context.Context) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+
+		ticker := time.NewTicker(agentLogInterval)
+		defer ticker.Stop()
+
+		offsets := make(map[string]int64)
+		for {
+			select {
+			case <-ctx.Done():
+				o.tailAgentLogs(offsets)
+				return
+			case <-ticker.C:
+			}
+			o.tailAgentLogs(offsets)
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-finished
+	}
+}
+
+// tailAgentLogs reads the lines added to transcripts since the last read.
+// Agents that finished are read too, for their last lines.
+func (o *Orchestrator) tailAgentLogs(offsets map[string]int64) {
+	tasks := make(map[string]bool, len(offsets))
+	for taskID := range offsets {
+		tasks[taskID] = true
+	}
+	for _, agent := range o.state.GetActiveAgents() {
+		tasks[agent.TaskID] = true
+	}
+
+	for taskID := range tasks {
+		path := filepath.Join(o.swarmDir, "agents", "agent-"+taskID, workflow.AgentLogFile)
+		lines, offset := readAgentLog(path, offsets[taskID])
+		offsets[taskID] = offset
+		if len(lines) > 0 {
+			o.state.AppendAgentLog(taskID, lines)
+		}
+	}
+}
+
+// readAgentLog returns the complete lines of a transcript from offset on,
+// and the offset to read from next
+func readAgentLog(path string, offset int64) ([]string, int64) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, offset
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, offset
+	}
+	size := info.Size()
+	if size < offset {
+		// Truncated, read it again from the start
+		offset = 0
+	}
+	skipped := size-offset > agentLogBacklog
+	if skipped {
+		offset = size - agentLogBacklog
+	}
+	if size == offset {
+		return nil, offset
+	}
+
+	data := make([]byte, size-offset)
+	n, err := file.ReadAt(data, offset)
+	if err != nil && err != io.EOF {
+		return nil, offset
+	}
+	data = data[:n]
+
+	// A line still being written is read once it is complete
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		return nil, offset
+	}
+	data = data[:end]
+	next := offset + int64(end) + 1
+	if skipped {
+		// The first line was cut where the backlog was skipped
+		cut := bytes.IndexByte(data, '\n')
+		if cut < 0 {
+			return nil, next
+		}
+		data = data[cut+1:]
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimRight(line, "\r"); strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, next
+}
@@ -0,0 +1,17 @@
+package orchestrator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// roleSection gives an agent the system prompt of its task's agent type
+func (o *Orchestrator) roleSection(task workflow.Task) string {
+	agentType, ok := o.agentTypes.Get(task.AgentType)
+	if !ok || strings.TrimSpace(agentType.Prompt) == "" {
+		return ""
+	}
+	return fmt.Sprintf("\n## Your Role: %s\n%s\n", agentType.Name, strings.TrimSpace(agentType.Prompt))
+}
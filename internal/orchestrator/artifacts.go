@@ -0,0 +1,37 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/artifacts"
+)
+
+// collectArtifacts copies the artifacts of completed tasks into the
+// session, before their dependents are spawned. Globs are resolved against
+// the orchestrator's working directory, where agents work.
+func (o *Orchestrator) collectArtifacts() {
+	pending := o.state.PendingArtifacts()
+	if len(pending) == 0 {
+		return
+	}
+
+	base, err := os.Getwd()
+	if err != nil {
+		fmt.Printf("Failed to resolve working directory for artifacts: %v\n", err)
+		return
+	}
+
+	for _, task := range pending {
+		paths, err := artifacts.Collect(o.swarmDir, task.ID, base, task.Artifacts)
+		if err != nil {
+			fmt.Printf("Failed to collect artifacts of task %s: %v\n", task.ID, err)
+		}
+		// Record what was collected even on errors, so a broken pattern is
+		// not retried on every tick
+		o.state.RecordArtifacts(task.ID, paths)
+		fmt.Printf("[%s] Collected %d artifacts from %s into %s\n",
+			time.Now().Format("15:04:05"), len(paths), task.ID, artifacts.Dir(o.swarmDir, task.ID))
+	}
+}
@@ -0,0 +1,98 @@
+package orchestrator
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// AuditFile is the log of operator actions in the session directory, one
+// JSON object per line
+const AuditFile = "audit.jsonl"
+
+// AuditEntry is an operator action
+type AuditEntry struct {
+	Time     time.Time `json:"time"`
+	Operator string    `json:"operator"`
+	Action   string    `json:"action"`
+	Task     string    `json:"task,omitempty"`
+	// Detail is what the action did, such as the tasks it cancelled
+	Detail string `json:"detail,omitempty"`
+}
+
+// audit appends an operator action to the session's audit log. Actions
+// the orchestrator takes on its own, and those forwarded to
+// sub-workflows, are not audited.
+func (o *Orchestrator) audit(operator string, action workflow.ControlAction, taskID, detail string) {
+	if operator == "" || len(o.ancestors) > 0 {
+		return
+	}
+
+	err := AppendAudit(o.swarmDir, AuditEntry{
+		Time:     time.Now(),
+		Operator: operator,
+		Action:   string(action),
+		Task:     taskID,
+		Detail:   o.state.Redact(detail),
+	})
+	if err != nil {
+		fmt.Printf("Failed to audit %s: %v\n", action, err)
+	}
+}
+
+// AppendAudit appends an operator action to a session's audit log, for
+// actions taken while no orchestrator runs it
+func AppendAudit(swarmDir string, entry AuditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+
+	file, err := os.OpenFile(filepath.Join(swarmDir, AuditFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// LoadAudit reads a session's audit log, oldest first. A session without
+// operator actions has none.
+func LoadAudit(swarmDir string) ([]AuditEntry, error) {
+	file, err := os.Open(filepath.Join(swarmDir, AuditFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	defer file.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse audit log: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// operatorName names an operator in logs
+func operatorName(operator string) string {
+	if operator == "" {
+		return "the orchestrator"
+	}
+	return operator
+}
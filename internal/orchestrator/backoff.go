@@ -0,0 +1,44 @@
+package orchestrator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// wakeUpAt schedules the tasks again at a time, such as when a task's
+// backoff is over, unless an earlier wake-up is already due
+func (o *Orchestrator) wakeUpAt(at time.Time) {
+	o.wakeMu.Lock()
+	defer o.wakeMu.Unlock()
+
+	if o.wakeTimer != nil && o.wakeDeadline.After(time.Now()) && !o.wakeDeadline.After(at) {
+		return
+	}
+	if o.wakeTimer != nil {
+		o.wakeTimer.Stop()
+	}
+	o.wakeDeadline = at
+	o.wakeTimer = time.AfterFunc(time.Until(at), func() {
+		select {
+		case o.wake <- struct{}{}:
+		default:
+		}
+	})
+}
+
+// logFailure logs a failed task, telling operators how to release it when
+// it was quarantined
+func (o *Orchestrator) logFailure(taskID string) {
+	agent := o.state.GetAgent(taskID)
+	if agent == nil {
+		return
+	}
+	if agent.Status == workflow.TaskStatusQuarantined {
+		fmt.Printf("[%s] Task quarantined: %s failed too often too quickly: %s (rerun it to release it: swarm task rerun %s)\n",
+			time.Now().Format("15:04:05"), taskID, agent.Error, taskID)
+		return
+	}
+	fmt.Printf("[%s] Task failed: %s: %s\n", time.Now().Format("15:04:05"), taskID, agent.Error)
+}
@@ -0,0 +1,77 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// executeBatch runs a batch's operations in order, each as if it were its
+// own message, and combines their responses. The batch stops at the first
+// failed operation unless it keeps going; it fails with the code of its
+// first failed operation.
+func (h *MessageHandler) executeBatch(ctx context.Context, agentID string, msg *workflow.Message) workflow.Response {
+	response := workflow.Response{
+		MessageID: msg.ID,
+		Timestamp: time.Now(),
+	}
+	if err := checkBatch(msg.Operations); err != nil {
+		response.SetError(err)
+		return response
+	}
+
+	// failed is the index of the first failed operation, or -1
+	failed := -1
+	for i := range msg.Operations {
+		op := msg.Operations[i]
+		if op.ID == "" {
+			op.ID = fmt.Sprintf("%s.%d", msg.ID, i+1)
+		}
+
+		var result workflow.Response
+		if err := decodeMessage(&op); err != nil {
+			result = workflow.Response{MessageID: op.ID, Timestamp: time.Now()}
+			result.SetError(err)
+		} else {
+			result = h.executeOperation(ctx, agentID, &op, nil)
+		}
+		response.Results = append(response.Results, result)
+
+		if result.Status == "error" && failed < 0 {
+			failed = i
+		}
+		if result.Status == "error" && !msg.KeepGoing {
+			break
+		}
+	}
+
+	ran := len(response.Results)
+	if failed < 0 {
+		response.Status = "success"
+		response.Data = fmt.Sprintf("Ran %d operations", ran)
+		return response
+	}
+	response.Status = "error"
+	response.Code = response.Results[failed].Code
+	response.Error = fmt.Sprintf("operation %d (%s) failed: %s", failed+1, msg.Operations[failed].Type, response.Results[failed].Error)
+	response.Data = fmt.Sprintf("Ran %d of %d operations", ran, len(msg.Operations))
+	return response
+}
+
+// checkBatch rejects empty, oversized and nested batches
+func checkBatch(operations []workflow.Message) error {
+	switch {
+	case len(operations) == 0:
+		return workflow.Errorf(workflow.ErrorInvalidRequest, "batch has no operations")
+	case len(operations) > workflow.MaxBatchOperations:
+		return workflow.Errorf(workflow.ErrorInvalidRequest, "batch has %d operations, more than %d", len(operations), workflow.MaxBatchOperations)
+	}
+	for i, op := range operations {
+		if op.Type == workflow.MessageTypeBatch {
+			return workflow.Errorf(workflow.ErrorInvalidRequest, "operation %d: batches cannot be nested", i+1)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package orchestrator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// enforceBudget pauses or cancels the run once its agents have reported
+// more usage than the workflow's budget allows. Pausing lets running
+// agents finish; cancelling interrupts them.
+func (o *Orchestrator) enforceBudget() {
+	reason := o.state.BudgetExceeded()
+	if reason == "" {
+		return
+	}
+
+	if o.state.Workflow.Budget.OnExceeded == workflow.BudgetCancel {
+		fmt.Printf("[%s] Budget exceeded, cancelling: %s\n", time.Now().Format("15:04:05"), reason)
+		o.Cancel("", true)
+		return
+	}
+	fmt.Printf("[%s] Budget exceeded, pausing: %s\n", time.Now().Format("15:04:05"), reason)
+	o.Pause("")
+}
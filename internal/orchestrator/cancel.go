@@ -0,0 +1,128 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// ErrCancelled is returned by Run when the run was cancelled
+var ErrCancelled = errors.New("run cancelled")
+
+// Interrupter is implemented by spawners that can interrupt the agents
+// they started
+type Interrupter interface {
+	Interrupt()
+}
+
+// Pause stops spawning agents on behalf of an operator; running agents
+// carry on and their results are still recorded. Sub-workflows pause too.
+func (o *Orchestrator) Pause(operator string) {
+	if o.state.SetPaused(true, operator) {
+		fmt.Printf("[%s] Paused by %s: no agents will be spawned until resumed\n", time.Now().Format("15:04:05"), operatorName(operator))
+		o.audit(operator, workflow.ControlPause, "", "")
+	}
+	for _, child := range o.subWorkflows() {
+		child.Pause(operator)
+	}
+}
+
+// Resume spawns agents again after Pause
+func (o *Orchestrator) Resume(operator string) {
+	if o.state.SetPaused(false, operator) {
+		fmt.Printf("[%s] Resumed by %s\n", time.Now().Format("15:04:05"), operatorName(operator))
+		o.audit(operator, workflow.ControlResume, "", "")
+	}
+	for _, child := range o.subWorkflows() {
+		child.Resume(operator)
+	}
+}
+
+// Cancel ends the run: no more agents are spawned, the tasks that have not
+// finished are marked cancelled, and Run returns ErrCancelled. With signal,
+// agents the spawner started as processes are interrupted; others are left
+// to notice that their completions are rejected. The operator who
+// cancelled is recorded with the cancellations.
+func (o *Orchestrator) Cancel(operator string, signal bool) {
+	for _, child := range o.subWorkflows() {
+		child.Cancel(operator, signal)
+	}
+
+	cancelled := o.state.Cancel(operator)
+	if len(cancelled) > 0 {
+		fmt.Printf("[%s] Cancelled %d tasks (by %s)\n", time.Now().Format("15:04:05"), len(cancelled), operatorName(operator))
+		o.audit(operator, workflow.ControlCancel, "", strings.Join(cancelled, ", "))
+	}
+
+	if signal {
+		if interrupter, ok := o.spawner.(Interrupter); ok {
+			interrupter.Interrupt()
+		}
+	}
+}
+
+// halt stops the run after failures under the halt failure policy: no more
+// agents are spawned, running ones are cancelled and interrupted, and Run
+// returns ErrTasksFailed
+func (o *Orchestrator) halt(failed []string) {
+	for _, child := range o.subWorkflows() {
+		child.Cancel("", true)
+	}
+
+	cancelled := o.state.Halt()
+	fmt.Printf("[%s] Halted after %s failed: cancelled %d tasks\n",
+		time.Now().Format("15:04:05"), strings.Join(failed, ", "), len(cancelled))
+
+	if interrupter, ok := o.spawner.(Interrupter); ok {
+		interrupter.Interrupt()
+	}
+}
+
+// Interrupt interrupts every running agent process
+func (s *ProcessSpawner) Interrupt() {
+	for taskID, pid := range s.PIDs() {
+		process, err := os.FindProcess(pid)
+		if err != nil {
+			continue
+		}
+		if err := process.Signal(os.Interrupt); err != nil {
+			fmt.Printf("Failed to interrupt agent %s: %v\n", taskID, err)
+		}
+	}
+}
+
+// addSubWorkflow registers a running sub-workflow, to pause and cancel it
+// with its parent
+func (o *Orchestrator) addSubWorkflow(taskID string, child *Orchestrator) {
+	o.childrenMu.Lock()
+	defer o.childrenMu.Unlock()
+
+	if o.children == nil {
+		o.children = make(map[string]*Orchestrator)
+	}
+	o.children[taskID] = child
+}
+
+// removeSubWorkflow forgets a sub-workflow that has ended
+func (o *Orchestrator) removeSubWorkflow(taskID string) {
+	o.childrenMu.Lock()
+	defer o.childrenMu.Unlock()
+
+	delete(o.children, taskID)
+}
+
+// subWorkflows returns the running sub-workflows
+func (o *Orchestrator) subWorkflows() []*Orchestrator {
+	o.childrenMu.Lock()
+	defer o.childrenMu.Unlock()
+
+	children := make([]*Orchestrator, 0, len(o.children))
+	for _, child := range o.children {
+		children = append(children, child)
+	}
+	return children
+}
@@ -0,0 +1,29 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/changelog"
+)
+
+// writeTaskDiff writes the diff of a finished task's changes, for swarm
+// changelog to include while the run goes on
+func (o *Orchestrator) writeTaskDiff(taskID string) {
+	if err := changelog.WriteDiffs(o.swarmDir, o.state, taskID); err != nil {
+		fmt.Printf("Failed to write diff of task %s: %v\n", taskID, err)
+	}
+}
+
+// writeChangelog writes the session's changelog once the run is over,
+// with paths relative to the directory the run started in
+func (o *Orchestrator) writeChangelog() {
+	root, _ := os.Getwd()
+	path, err := changelog.Write(o.swarmDir, o.state, root, "")
+	if err != nil {
+		fmt.Printf("Failed to write changelog: %v\n", err)
+		return
+	}
+	fmt.Printf("[%s] Changelog: %s\n", time.Now().Format("15:04:05"), path)
+}
@@ -0,0 +1,179 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/report"
+	"github.com/aristath/claude-swarm/internal/state"
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// CheckpointsDir is the session directory holding checkpoints
+const CheckpointsDir = "checkpoints"
+
+// checkpointFile describes a checkpoint in its directory
+const checkpointFile = "checkpoint.json"
+
+// maxCheckpointSlug caps the part of a checkpoint's directory name taken
+// from its name
+const maxCheckpointSlug = 48
+
+// Checkpoint is a named moment of a run: a snapshot of its state and an
+// interim report, kept in a directory of its own for sharing progress
+type Checkpoint struct {
+	Name      string    `json:"name"`
+	Dir       string    `json:"dir"`
+	Time      time.Time `json:"time"`
+	Operator  string    `json:"operator,omitempty"`
+	Completed int       `json:"completed"`
+	Failed    int       `json:"failed"`
+	Tasks     int       `json:"tasks"`
+}
+
+// Checkpoint snapshots the running session's state and writes an interim
+// report, on behalf of an operator
+func (o *Orchestrator) Checkpoint(operator, name string) error {
+	checkpoint, err := WriteCheckpoint(o.swarmDir, o.state, name, operator)
+	if err != nil {
+		return err
+	}
+
+	o.state.RecordCheckpoint(checkpoint.Dir, operator)
+	fmt.Printf("[%s] Checkpoint %q by %s: %d of %d tasks completed, report in %s\n",
+		time.Now().Format("15:04:05"), name, operatorName(operator),
+		checkpoint.Completed, checkpoint.Tasks, filepath.Join(checkpoint.Dir, "report.md"))
+	o.audit(operator, workflow.ControlCheckpoint, "", name)
+	return nil
+}
+
+// WriteCheckpoint writes a checkpoint of a session into a new directory
+// under its checkpoints directory: the state as state.json and an interim
+// report as report.md and report.json. It also checkpoints sessions no
+// orchestrator is running.
+func WriteCheckpoint(swarmDir string, swarmState *state.SwarmState, name, operator string) (*Checkpoint, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, workflow.Errorf(workflow.ErrorInvalidRequest, "checkpoint name is required")
+	}
+
+	existing, err := ListCheckpoints(swarmDir)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(swarmDir, CheckpointsDir, fmt.Sprintf("%03d-%s", len(existing)+1, checkpointSlug(name)))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+
+	if err := state.NewPersistence(dir).Save(swarmState); err != nil {
+		return nil, fmt.Errorf("failed to snapshot state: %w", err)
+	}
+
+	result := report.FromState(swarmState, checkpointStatus(swarmState))
+	checkpoint := &Checkpoint{
+		Name:      name,
+		Dir:       dir,
+		Time:      time.Now(),
+		Operator:  operator,
+		Completed: result.Count(workflow.TaskStatusCompleted),
+		Failed:    result.Count(workflow.TaskStatusFailed) + result.Count(workflow.TaskStatusQuarantined),
+		Tasks:     len(result.Tasks),
+	}
+
+	header := fmt.Sprintf("# Checkpoint: %s\n\nTaken %s by %s, %s into the run.\n\n",
+		name, checkpoint.Time.Format("2006-01-02 15:04"), operatorName(operator),
+		checkpoint.Time.Sub(swarmState.StartedAt).Round(time.Second))
+	if err := os.WriteFile(filepath.Join(dir, "report.md"), []byte(header+result.Markdown()), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write checkpoint report: %w", err)
+	}
+	if err := writeJSON(filepath.Join(dir, "report.json"), result); err != nil {
+		return nil, fmt.Errorf("failed to write checkpoint report: %w", err)
+	}
+
+	// Written last, so only complete checkpoints are listed
+	if err := writeJSON(filepath.Join(dir, checkpointFile), checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return checkpoint, nil
+}
+
+// ListCheckpoints returns a session's checkpoints, oldest first
+func ListCheckpoints(swarmDir string) ([]Checkpoint, error) {
+	paths, err := filepath.Glob(filepath.Join(swarmDir, CheckpointsDir, "*", checkpointFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+
+	var checkpoints []Checkpoint
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+		}
+		var checkpoint Checkpoint
+		if err := json.Unmarshal(data, &checkpoint); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		checkpoint.Dir = filepath.Dir(path)
+		checkpoints = append(checkpoints, checkpoint)
+	}
+
+	sort.Slice(checkpoints, func(i, j int) bool {
+		return checkpoints[i].Time.Before(checkpoints[j].Time)
+	})
+	return checkpoints, nil
+}
+
+// checkpointStatus returns the run's status at a checkpoint: running,
+// unless it already finished
+func checkpointStatus(swarmState *state.SwarmState) string {
+	switch {
+	case swarmState.IsCancelled():
+		return report.StatusCancelled
+	case swarmState.IsComplete() && len(swarmState.GetFailedTasks()) == 0:
+		return report.StatusPassed
+	case swarmState.IsComplete():
+		return report.StatusFailed
+	}
+	return report.StatusRunning
+}
+
+// checkpointSlug turns a checkpoint name into a file-name safe part of
+// its directory name
+func checkpointSlug(name string) string {
+	slug := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		}
+		return '-'
+	}, name)
+	for strings.Contains(slug, "--") {
+		slug = strings.ReplaceAll(slug, "--", "-")
+	}
+	if len(slug) > maxCheckpointSlug {
+		slug = slug[:maxCheckpointSlug]
+	}
+	slug = strings.Trim(slug, "-")
+	if slug == "" {
+		return "checkpoint"
+	}
+	return slug
+}
+
+// writeJSON writes a value as indented JSON
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
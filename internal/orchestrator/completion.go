@@ -0,0 +1,16 @@
+package orchestrator
+
+import (
+	"fmt"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// dependencyCompletion renders the files changed, test outcome and notes a
+// dependency's agent reported when it completed, if it did
+func dependencyCompletion(depID string, agent *workflow.AgentState) string {
+	if agent == nil || agent.Completion.Empty() {
+		return ""
+	}
+	return fmt.Sprintf("## Completion report of task: %s\n%s\n", depID, agent.Completion.Describe())
+}
@@ -0,0 +1,89 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/coalesce"
+	"github.com/aristath/claude-swarm/internal/state"
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// conflictMarker starts the first version in a file with unresolved
+// conflict markers
+const conflictMarker = "<<<<<<< "
+
+// trackChange records an agent's change to a file, and announces the
+// conflict it causes with another task's change
+func (o *Orchestrator) trackChange(agentID, path, before, after string) {
+	conflict := o.state.RecordChange(agentID, path, before, after)
+	if conflict == nil {
+		return
+	}
+	fmt.Printf("[%s] Conflict %d: %s and %s changed the same lines of %s; tasks depending on them wait for its resolution\n",
+		time.Now().Format("15:04:05"), conflict.ID, conflict.First, conflict.Second, conflict.Path)
+}
+
+// ResolveConflict settles a file conflict on behalf of an operator: keeping
+// the first task's version writes it back, keeping the second's leaves the
+// file as it is, and a manual merge writes the merged content
+func (o *Orchestrator) ResolveConflict(operator string, id int, resolution, content string) error {
+	conflict, ok := o.state.GetConflict(id)
+	if !ok {
+		return workflow.Errorf(workflow.ErrorNotFound, "conflict %d not found", id)
+	}
+	if !conflict.IsOpen() {
+		return workflow.Errorf(workflow.ErrorConflict, "conflict %d was already resolved (%s)", id, conflict.Resolution)
+	}
+
+	switch resolution {
+	case state.ResolutionKeepFirst:
+		content = conflict.FirstText
+	case state.ResolutionKeepSecond:
+		content = ""
+	case state.ResolutionMerged:
+		if strings.Contains(content, conflictMarker) {
+			return workflow.Errorf(workflow.ErrorInvalidRequest, "merged content still has conflict markers")
+		}
+	default:
+		return workflow.Errorf(workflow.ErrorInvalidRequest, "unknown resolution %q", resolution)
+	}
+
+	if resolution != state.ResolutionKeepSecond {
+		if err := o.writeResolution(conflict, content); err != nil {
+			return err
+		}
+	}
+
+	if err := o.state.ResolveConflict(id, resolution, operator); err != nil {
+		return err
+	}
+	fmt.Printf("[%s] Conflict %d on %s resolved by %s: %s\n",
+		time.Now().Format("15:04:05"), id, conflict.Path, operatorName(operator), resolution)
+	o.audit(operator, workflow.ControlResolve, "", conflict.Path+": "+resolution)
+	return nil
+}
+
+// writeResolution writes a conflict's resolved content, tracked as a change
+// by no task so it conflicts with nothing. The file must still hold the
+// second task's version, or the resolution would undo later changes.
+func (o *Orchestrator) writeResolution(conflict state.Conflict, content string) error {
+	o.state.FileLock().Lock()
+	defer o.state.FileLock().Unlock()
+
+	before, _, err := readCurrent(conflict.Path)
+	if err != nil {
+		return err
+	}
+	if before != conflict.SecondText {
+		return workflow.Errorf(workflow.ErrorConflict, "conflict %d: %s changed since the conflict was found", conflict.ID, conflict.Path)
+	}
+	defer coalesce.Invalidate(conflict.Path)
+	if err := os.WriteFile(conflict.Path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write resolution: %w", err)
+	}
+	o.state.RecordChange("", conflict.Path, before, content)
+	return nil
+}
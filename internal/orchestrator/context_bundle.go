@@ -0,0 +1,111 @@
+package orchestrator
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aristath/claude-swarm/internal/memory"
+	"github.com/aristath/claude-swarm/internal/state"
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// ContextBundle is what an agent was given when it was spawned, for
+// debugging agents that went off the rails
+type ContextBundle struct {
+	TaskID string `json:"task_id"`
+	// Prompt is the task's prompt after parameters, dependency outputs and
+	// artifacts were interpolated
+	Prompt string `json:"prompt"`
+	// SpawnPrompt is what the agent was spawned with
+	SpawnPrompt string `json:"spawn_prompt"`
+	// Context is the context.txt the agent read: the one written at spawn,
+	// or a regenerated one when the agent hasn't been spawned or its file
+	// is gone
+	Context string `json:"context"`
+	// Regenerated is set when Context was regenerated from the session
+	Regenerated bool `json:"regenerated"`
+	// Stale is set when the context written at spawn differs from what the
+	// session state generates now, e.g. after a dependency was rerun
+	Stale bool `json:"stale"`
+}
+
+// LoadContextBundle rebuilds the context of a task in a saved session the
+// way the orchestrator generated it, with the API address the agent was
+// given and the session's repository map. With regenerate, the context is
+// always generated from the session state instead of read from the
+// agent's context.txt.
+func LoadContextBundle(swarmDir string, swarmState *state.SwarmState, taskID string, regenerate bool) (*ContextBundle, error) {
+	task := swarmState.GetTask(taskID)
+	if task == nil {
+		return nil, fmt.Errorf("task not found: %s", taskID)
+	}
+
+	agentDir := filepath.Join(swarmDir, "agents", fmt.Sprintf("agent-%s", taskID))
+	o := &Orchestrator{
+		swarmDir: swarmDir,
+		state:    swarmState,
+		parser:   workflow.NewParser(),
+		apiURL:   DefaultAPIURL,
+	}
+	if url := readEnvValue(filepath.Join(agentDir, "env.sh"), "SWARM_API_URL"); url != "" {
+		o.apiURL = url
+	}
+	if data, err := os.ReadFile(filepath.Join(swarmDir, RepoMapFile)); err == nil {
+		o.repoMap = string(data)
+	}
+	if data, err := os.ReadFile(filepath.Join(swarmDir, memory.File)); err == nil {
+		o.recalled = string(data)
+	}
+
+	generated := o.generateAgentContext(*task)
+	bundle := &ContextBundle{
+		TaskID:      taskID,
+		Prompt:      o.interpolateTaskPrompt(*task),
+		SpawnPrompt: o.generateSpawnPrompt(*task, agentDir),
+		Context:     generated,
+		Regenerated: true,
+	}
+
+	if saved, err := os.ReadFile(filepath.Join(agentDir, "context.txt")); err == nil && !regenerate {
+		bundle.Context = string(saved)
+		bundle.Regenerated = false
+		bundle.Stale = string(saved) != generated
+	}
+	return bundle, nil
+}
+
+// interpolateTaskPrompt fills a task's prompt with the workflow's
+// parameters and its dependencies' outputs and artifacts
+func (o *Orchestrator) interpolateTaskPrompt(task workflow.Task) string {
+	prompt := o.parser.InterpolatePrompt(o.state.Workflow.InterpolateParams(task.Prompt), o.state.GetOutputs())
+	return o.parser.InterpolateArtifacts(prompt, o.state.GetArtifacts())
+}
+
+// readEnvValue returns the value an env.sh exports for a variable, or ""
+func readEnvValue(envFile, name string) string {
+	file, err := os.Open(envFile)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	prefix := "export " + name + "="
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), prefix); ok {
+			return shellUnquote(value)
+		}
+	}
+	return ""
+}
+
+// shellUnquote reverses shellQuote
+func shellUnquote(value string) string {
+	if len(value) < 2 || !strings.HasPrefix(value, "'") || !strings.HasSuffix(value, "'") {
+		return value
+	}
+	return strings.ReplaceAll(value[1:len(value)-1], `'\''`, "'")
+}
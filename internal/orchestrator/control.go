@@ -0,0 +1,106 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// SendControlRequest delivers an operator command to the orchestrator
+// running in swarmDir, from the current operator unless it names one
+func SendControlRequest(swarmDir string, req workflow.ControlRequest) error {
+	if req.ID == "" {
+		req.ID = "ctl-" + workflow.NewULID()
+	}
+	if req.Timestamp.IsZero() {
+		req.Timestamp = time.Now()
+	}
+	if req.Operator == "" {
+		req.Operator = workflow.CurrentOperator()
+	}
+
+	controlDir := filepath.Join(swarmDir, "control")
+	if err := os.MkdirAll(controlDir, 0755); err != nil {
+		return fmt.Errorf("failed to create control directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(req, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal control request: %w", err)
+	}
+
+	if err := writeFileAtomic(filepath.Join(controlDir, req.ID+".json"), data); err != nil {
+		return fmt.Errorf("failed to write control request: %w", err)
+	}
+
+	return nil
+}
+
+// handleControlRequest executes an operator command
+func (o *Orchestrator) handleControlRequest(ctx context.Context, event workflow.FileEvent) error {
+	data, err := os.ReadFile(event.FilePath)
+	if err != nil {
+		return fmt.Errorf("failed to read control request: %w", err)
+	}
+
+	var req workflow.ControlRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return fmt.Errorf("failed to parse control request: %w", err)
+	}
+
+	// Processed requests are removed so the directory only holds pending ones
+	defer os.Remove(event.FilePath)
+
+	switch req.Action {
+	case workflow.ControlApproveQuota:
+		if err := o.state.ApproveQuota(req.TaskID, req.Operator); err != nil {
+			return fmt.Errorf("failed to approve quota: %w", err)
+		}
+		fmt.Printf("[%s] Quota approved for agent %s by %s\n", time.Now().Format("15:04:05"), req.TaskID, operatorName(req.Operator))
+		o.audit(req.Operator, req.Action, req.TaskID, "")
+
+	case workflow.ControlPause:
+		o.Pause(req.Operator)
+
+	case workflow.ControlResume:
+		o.Resume(req.Operator)
+
+	case workflow.ControlCancel:
+		o.Cancel(req.Operator, req.Signal)
+
+	case workflow.ControlRerun:
+		if err := o.Rerun(req.Operator, req.TaskID); err != nil {
+			return fmt.Errorf("failed to rerun task: %w", err)
+		}
+
+	case workflow.ControlSkip:
+		if err := o.Skip(req.Operator, req.TaskID); err != nil {
+			return fmt.Errorf("failed to skip task: %w", err)
+		}
+
+	case workflow.ControlCheckpoint:
+		if err := o.Checkpoint(req.Operator, req.Name); err != nil {
+			return fmt.Errorf("failed to checkpoint: %w", err)
+		}
+
+	case workflow.ControlResolve:
+		if err := o.ResolveConflict(req.Operator, req.Conflict, req.Resolution, req.Content); err != nil {
+			return fmt.Errorf("failed to resolve conflict: %w", err)
+		}
+
+	case workflow.ControlAnswer:
+		if err := o.Answer(req.Operator, req.TaskID, req.Question, req.Answer); err != nil {
+			return fmt.Errorf("failed to answer question: %w", err)
+		}
+
+	default:
+		return fmt.Errorf("unknown control action: %s", req.Action)
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// crashedStatus is the status.txt the process spawner leaves for an agent
+// that exited without completing or failing its task
+const crashedStatus = "crashed"
+
+// crashed reports whether an agent's process crashed
+func crashed(agentDir string) bool {
+	status, err := os.ReadFile(filepath.Join(agentDir, "status.txt"))
+	return err == nil && strings.TrimSpace(string(status)) == crashedStatus
+}
+
+// restartCrashed runs the task of a crashed agent again, once its backoff
+// is over, if it has restarts left; otherwise the task fails
+func (o *Orchestrator) restartCrashed(taskID, reason string) {
+	restarted, crashes := o.state.RestartCrashed(taskID, reason)
+	if !restarted {
+		o.logFailure(taskID)
+		return
+	}
+
+	limit := 0
+	if task := o.state.GetTask(taskID); task != nil {
+		limit = o.state.Workflow.RestartLimit(*task)
+	}
+	wait := time.Until(o.state.RetryAt(taskID)).Round(time.Second)
+	fmt.Printf("[%s] Agent crashed: %s: %s (restart %d of %d in %s)\n", time.Now().Format("15:04:05"), taskID, reason, crashes, limit, wait)
+}
+
+// crashNote tells a restarted agent that the agents before it crashed, so
+// it checks what they left behind instead of starting blindly
+func (o *Orchestrator) crashNote(task workflow.Task) string {
+	crashes := o.state.GetCrashes(task.ID)
+	if len(crashes) == 0 {
+		return ""
+	}
+
+	var note strings.Builder
+	note.WriteString("## PREVIOUS ATTEMPT CRASHED\n")
+	fmt.Fprintf(&note, "This is restart %d of at most %d. The agent process of the previous attempt exited without completing or failing the task:\n",
+		len(crashes), o.state.Workflow.RestartLimit(task))
+	fmt.Fprintf(&note, "%s\n\n", crashes[len(crashes)-1])
+	note.WriteString("It may have left files half-changed. Check the state of the files the task touches before carrying on, and complete or fail the task when done.\n\n")
+	return note.String()
+}
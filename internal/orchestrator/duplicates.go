@@ -0,0 +1,72 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// repeatable are the operations that change nothing, which run again when
+// delivered twice rather than being remembered
+var repeatable = map[workflow.MessageType]bool{
+	workflow.MessageTypeReadFile:   true,
+	workflow.MessageTypeGlob:       true,
+	workflow.MessageTypeGrep:       true,
+	workflow.MessageTypeCodeSearch: true,
+	workflow.MessageTypeLocks:      true,
+	workflow.MessageTypeDeps:       true,
+	workflow.MessageTypeDepOutput:  true,
+}
+
+// claim guards against running a message twice, whether the file watcher
+// delivered it twice or the agent sent it again. A message handled before
+// returns its original response; one still running is waited for. Otherwise
+// the caller runs it and calls release once its response is recorded.
+func (h *MessageHandler) claim(ctx context.Context, agentID string, msg *workflow.Message) (*workflow.Response, func()) {
+	if msg.ID == "" || repeatable[msg.Type] {
+		return nil, func() {}
+	}
+
+	key := agentID + "/" + msg.ID
+	h.inflightMu.Lock()
+	running, isRunning := h.inflight[key]
+	if !isRunning {
+		if response, ok := h.orchestrator.state.ProcessedResponse(agentID, msg.ID); ok {
+			h.inflightMu.Unlock()
+			return h.duplicate(msg, response), func() {}
+		}
+		done := make(chan struct{})
+		h.inflight[key] = done
+		h.inflightMu.Unlock()
+		return nil, func() {
+			h.inflightMu.Lock()
+			delete(h.inflight, key)
+			h.inflightMu.Unlock()
+			close(done)
+		}
+	}
+	h.inflightMu.Unlock()
+
+	select {
+	case <-running:
+	case <-ctx.Done():
+		response := workflow.Response{MessageID: msg.ID, Timestamp: time.Now()}
+		response.SetError(fmt.Errorf("cancelled waiting for the first delivery of message %s", msg.ID))
+		return &response, func() {}
+	}
+	if response, ok := h.orchestrator.state.ProcessedResponse(agentID, msg.ID); ok {
+		return h.duplicate(msg, response), func() {}
+	}
+	// The first delivery was rejected before it ran, so this one may run
+	return h.claim(ctx, agentID, msg)
+}
+
+// duplicate answers a message delivered again with its original response
+func (h *MessageHandler) duplicate(msg *workflow.Message, response workflow.Response) *workflow.Response {
+	h.orchestrator.state.RecordDuplicate()
+	fmt.Printf("[%s] Message %s was delivered again, answering with its original response\n",
+		time.Now().Format("15:04:05"), msg.ID)
+	return &response
+}
@@ -0,0 +1,122 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/notify"
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// maxEmailOutput keeps long task outputs from bloating notifications
+const maxEmailOutput = 10000
+
+// startEmail emails notifications about the workflow's configured events,
+// one per event or batched into digests. The returned function stops it
+// once pending notifications, including a partial digest, are sent.
+func (o *Orchestrator) startEmail(ctx context.Context) func() {
+	cfg := o.state.Workflow.Email
+	if cfg == nil {
+		return func() {}
+	}
+
+	mailer := notify.NewMailer(cfg.SMTP, cfg.From, cfg.To)
+	events, cancel := o.state.Subscribe(hookEventBuffer)
+	finished := make(chan struct{})
+
+	send := func(subject, body string) {
+		if err := mailer.Send(subject, body); err != nil {
+			fmt.Printf("Failed to send email notification: %v\n", err)
+		}
+	}
+
+	go func() {
+		defer close(finished)
+
+		var digest <-chan time.Time
+		if cfg.DigestMinutes > 0 {
+			ticker := time.NewTicker(time.Duration(cfg.DigestMinutes) * time.Minute)
+			defer ticker.Stop()
+			digest = ticker.C
+		}
+
+		var pending []string
+		flush := func() {
+			if len(pending) == 0 {
+				return
+			}
+			subject := fmt.Sprintf("[swarm %s] %d updates", o.state.SessionID, len(pending))
+			send(subject, strings.Join(pending, "\n\n----\n\n"))
+			pending = nil
+		}
+
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					flush()
+					return
+				}
+				if !cfg.Events().Matches(event.Type) {
+					continue
+				}
+
+				subject, body := o.describeEvent(event)
+				if digest != nil {
+					pending = append(pending, subject+"\n\n"+body)
+				} else {
+					send(fmt.Sprintf("[swarm %s] %s", o.state.SessionID, subject), body)
+				}
+
+			case <-digest:
+				flush()
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-finished
+	}
+}
+
+// describeEvent returns the subject and body of a notification
+func (o *Orchestrator) describeEvent(event workflow.FileEvent) (string, string) {
+	agent := o.state.GetAgent(event.AgentID)
+	at := event.Time.Format("2006-01-02 15:04:05")
+
+	switch {
+	case event.Type == workflow.EventTaskCompleted && agent != nil:
+		output := agent.Output
+		if len(output) > maxEmailOutput {
+			output = output[:runeBoundary(output, maxEmailOutput)] + "\n\n[truncated]"
+		}
+		return fmt.Sprintf("Task %s completed", event.AgentID),
+			fmt.Sprintf("Task %s completed at %s.\n\nOutput:\n%s", event.AgentID, at, output)
+
+	case event.Type == workflow.EventTaskFailed && agent != nil && agent.Status == workflow.TaskStatusQuarantined:
+		return fmt.Sprintf("Task %s quarantined", event.AgentID),
+			fmt.Sprintf("Task %s failed too often too quickly and was quarantined at %s. It runs again only when rerun: swarm task rerun %s\n\nError: %s", event.AgentID, at, event.AgentID, agent.Error)
+
+	case event.Type == workflow.EventTaskFailed && agent != nil:
+		return fmt.Sprintf("Task %s failed", event.AgentID),
+			fmt.Sprintf("Task %s failed at %s.\n\nError: %s", event.AgentID, at, agent.Error)
+
+	case event.Type == workflow.EventQuestionAsked && agent != nil && len(agent.Questions) > 0:
+		questions := agent.AllQuestions()
+		question := questions[len(questions)-1]
+		return fmt.Sprintf("Question from %s", event.AgentID),
+			fmt.Sprintf("Task %s asked at %s:\n\n%s", event.AgentID, at, question.Text)
+	}
+
+	body := fmt.Sprintf("%s for task %s at %s.", event.Type, event.AgentID, at)
+	if event.FilePath != "" {
+		body += "\n\nPath: " + event.FilePath
+	}
+	if event.Operator != "" {
+		body += "\n\nBy: " + event.Operator
+	}
+	return fmt.Sprintf("%s: %s", event.Type, event.AgentID), body
+}
@@ -0,0 +1,88 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/aristath/claude-swarm/internal/version"
+)
+
+// DefaultAPIURL is the address agents use to reach the API server
+const DefaultAPIURL = "http://localhost:8080"
+
+// DefaultGRPCAddr is the address agents use to reach the API server's
+// gRPC service
+const DefaultGRPCAddr = "localhost:8081"
+
+// generateAgentEnv writes env.sh into the agent directory. Agents source it
+// to get their session, directories, API address and token, and swarm-agent
+// on PATH, instead of copying export lines from the instructions.
+func (o *Orchestrator) generateAgentEnv(taskID, agentDir string) error {
+	var env strings.Builder
+
+	fmt.Fprintf(&env, "# Claude Swarm agent environment for task %s\n", taskID)
+	fmt.Fprintf(&env, "# Usage: source %s\n", shellQuote(filepath.Join(agentDir, "env.sh")))
+	fmt.Fprintf(&env, "export SWARM_SESSION_ID=%s\n", shellQuote(o.state.SessionID))
+	fmt.Fprintf(&env, "export SWARM_TASK_ID=%s\n", shellQuote(taskID))
+	fmt.Fprintf(&env, "export SWARM_DIR=%s\n", shellQuote(o.swarmDir))
+	fmt.Fprintf(&env, "export SWARM_AGENT_DIR=%s\n", shellQuote(agentDir))
+	fmt.Fprintf(&env, "export SWARM_API_URL=%s\n", shellQuote(o.apiURL))
+	fmt.Fprintf(&env, "export SWARM_GRPC_ADDR=%s\n", shellQuote(DefaultGRPCAddr))
+	fmt.Fprintf(&env, "export SWARM_PROTOCOL_VERSION=%d\n", version.ProtocolVersion)
+	if o.apiToken != "" {
+		fmt.Fprintf(&env, "export SWARM_API_TOKEN=%s\n", shellQuote(o.apiToken))
+	}
+	if binDir := findAgentBinDir(); binDir != "" {
+		fmt.Fprintf(&env, "export PATH=%s:\"$PATH\"\n", shellQuote(binDir))
+	}
+
+	envFile := filepath.Join(agentDir, "env.sh")
+	if err := os.WriteFile(envFile, []byte(env.String()), 0600); err != nil {
+		return fmt.Errorf("failed to write env file: %w", err)
+	}
+
+	return nil
+}
+
+// DefaultAgentBinDir is where 'swarm install-agent' places swarm-agent
+func DefaultAgentBinDir() string {
+	return filepath.Join(os.Getenv("HOME"), ".claude-swarm", "bin")
+}
+
+// findAgentBinDir returns the directory holding the swarm-agent binary:
+// the install-agent directory, next to the running executable, or wherever
+// it is found on PATH
+func findAgentBinDir() string {
+	if dir := DefaultAgentBinDir(); isFile(filepath.Join(dir, "swarm-agent")) {
+		return dir
+	}
+
+	if exe, err := os.Executable(); err == nil {
+		dir := filepath.Dir(exe)
+		if isFile(filepath.Join(dir, "swarm-agent")) {
+			return dir
+		}
+	}
+
+	if path, err := exec.LookPath("swarm-agent"); err == nil {
+		if abs, err := filepath.Abs(path); err == nil {
+			return filepath.Dir(abs)
+		}
+	}
+
+	return ""
+}
+
+// isFile reports whether path exists and is a regular file
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// shellQuote quotes a value for a POSIX shell
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
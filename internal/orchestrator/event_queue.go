@@ -0,0 +1,97 @@
+package orchestrator
+
+import (
+	"sync"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// eventQueue passes file events from the watcher to the event loop without
+// ever blocking the watcher or dropping an event. Events go straight to the
+// channel while it has room; during bursts they wait, in order, in an
+// unbounded backlog that a pump drains as the event loop catches up.
+type eventQueue struct {
+	out     chan workflow.FileEvent
+	done    <-chan bool
+	ready   chan struct{}
+	onDefer func(backlog int)
+	mu      sync.Mutex
+	backlog []workflow.FileEvent
+	started sync.Once
+}
+
+// newEventQueue creates a queue feeding out until done is closed. onDefer
+// is called with the backlog size each time an event has to wait.
+func newEventQueue(out chan workflow.FileEvent, done <-chan bool, onDefer func(backlog int)) *eventQueue {
+	return &eventQueue{
+		out:     out,
+		done:    done,
+		ready:   make(chan struct{}, 1),
+		onDefer: onDefer,
+	}
+}
+
+// start runs the pump that drains the backlog
+func (q *eventQueue) start() {
+	q.started.Do(func() {
+		go q.pump()
+	})
+}
+
+// push queues an event. It never blocks.
+func (q *eventQueue) push(event workflow.FileEvent) {
+	q.mu.Lock()
+	// Events only skip the backlog when none are waiting, to keep them in order
+	if len(q.backlog) == 0 {
+		select {
+		case q.out <- event:
+			q.mu.Unlock()
+			return
+		default:
+		}
+	}
+	q.backlog = append(q.backlog, event)
+	backlog := len(q.backlog)
+	q.mu.Unlock()
+
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+	if q.onDefer != nil {
+		q.onDefer(backlog)
+	}
+}
+
+// pump moves backlogged events to the channel, oldest first
+func (q *eventQueue) pump() {
+	for {
+		q.mu.Lock()
+		if len(q.backlog) == 0 {
+			q.mu.Unlock()
+			select {
+			case <-q.ready:
+				continue
+			case <-q.done:
+				return
+			}
+		}
+		event := q.backlog[0]
+		q.mu.Unlock()
+
+		select {
+		case q.out <- event:
+		case <-q.done:
+			return
+		}
+
+		q.mu.Lock()
+		q.backlog[0] = workflow.FileEvent{}
+		q.backlog = q.backlog[1:]
+		if len(q.backlog) == 0 {
+			// Let a burst's backing array go
+			q.backlog = nil
+		}
+		q.mu.Unlock()
+	}
+}
@@ -0,0 +1,300 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+	"gopkg.in/yaml.v3"
+)
+
+// FakeScript describes the canned behaviour of simulated agents
+type FakeScript struct {
+	// DefaultOutput is used for tasks without a scripted output.
+	// "{task}" is replaced with the task ID.
+	DefaultOutput string `yaml:"default_output"`
+
+	// Tasks maps task IDs to their scripted behaviour
+	Tasks map[string]FakeTaskScript `yaml:"tasks"`
+}
+
+// FakeTaskScript describes how a single simulated agent behaves
+type FakeTaskScript struct {
+	Output    string         `yaml:"output"`
+	Questions []FakeQuestion `yaml:"questions"`
+	// Fail makes the agent fail with this error instead of completing
+	Fail string `yaml:"fail"`
+	// Crash makes the agent exit as if its process crashed, so it is
+	// restarted while the task has restarts left
+	Crash bool `yaml:"crash"`
+	// Failures limits Fail and Crash to the task's first attempts: with
+	// 2, the third attempt completes. Zero fails every attempt.
+	Failures int `yaml:"failures"`
+	// Delay is how long the agent works before completing or failing,
+	// such as 30s. It sends heartbeats meanwhile.
+	Delay string `yaml:"delay"`
+	// Hang makes the agent never finish nor send heartbeats, as a stuck
+	// agent would
+	Hang bool `yaml:"hang"`
+}
+
+// FakeQuestion is a question a simulated agent asks, written in YAML as
+// its text alone or with timings
+type FakeQuestion struct {
+	Text string `yaml:"text"`
+	// Delay is how long the agent thinks before asking
+	Delay string `yaml:"delay"`
+	// Timeout is how long the agent waits for the answer before failing;
+	// unset means 5 minutes
+	Timeout string `yaml:"timeout"`
+}
+
+// defaultFakeAnswerTimeout is how long simulated agents wait for answers
+const defaultFakeAnswerTimeout = 5 * time.Minute
+
+// UnmarshalYAML accepts a question's text as well as a mapping
+func (q *FakeQuestion) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		q.Text = value.Value
+		return nil
+	}
+
+	type plain FakeQuestion
+	return value.Decode((*plain)(q))
+}
+
+// LoadFakeScript reads a fake agent script from a YAML file
+func LoadFakeScript(path string) (*FakeScript, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fake agent script: %w", err)
+	}
+
+	var script FakeScript
+	if err := yaml.Unmarshal(data, &script); err != nil {
+		return nil, fmt.Errorf("failed to parse fake agent script: %w", err)
+	}
+	if err := script.validate(); err != nil {
+		return nil, fmt.Errorf("invalid fake agent script: %w", err)
+	}
+
+	return &script, nil
+}
+
+// validate checks the script's durations, so a typo fails the run up
+// front rather than a simulated agent halfway through
+func (s *FakeScript) validate() error {
+	for taskID, task := range s.Tasks {
+		if _, err := fakeDuration(task.Delay, 0); err != nil {
+			return fmt.Errorf("task %s: delay: %w", taskID, err)
+		}
+		if task.Failures < 0 {
+			return fmt.Errorf("task %s: failures must not be negative", taskID)
+		}
+		for i, question := range task.Questions {
+			if _, err := fakeDuration(question.Delay, 0); err != nil {
+				return fmt.Errorf("task %s: question %d: delay: %w", taskID, i+1, err)
+			}
+			if _, err := fakeDuration(question.Timeout, defaultFakeAnswerTimeout); err != nil {
+				return fmt.Errorf("task %s: question %d: timeout: %w", taskID, i+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+// fakeDuration parses a scripted duration, or returns fallback when unset
+func fakeDuration(value string, fallback time.Duration) (time.Duration, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("%q is not a duration such as 30s", value)
+	}
+	return d, nil
+}
+
+// FakeSpawner spawns simulated agents that speak the regular file protocol:
+// they ask their scripted questions, wait for the answers and then complete
+// with a canned output. No tokens are spent, so workflow structure,
+// interpolation and dependencies can be exercised cheaply.
+type FakeSpawner struct {
+	script *FakeScript
+
+	mu       sync.Mutex
+	attempts map[string]int // Agents spawned so far by task ID
+}
+
+// NewFakeSpawner creates a fake spawner. A nil script uses canned outputs only.
+func NewFakeSpawner(script *FakeScript) *FakeSpawner {
+	if script == nil {
+		script = &FakeScript{}
+	}
+
+	return &FakeSpawner{script: script, attempts: make(map[string]int)}
+}
+
+// Spawn starts a simulated agent for the task
+func (s *FakeSpawner) Spawn(ctx context.Context, task workflow.Task, agentDir, prompt string) error {
+	s.mu.Lock()
+	s.attempts[task.ID]++
+	attempt := s.attempts[task.ID]
+	s.mu.Unlock()
+
+	fmt.Printf("[FAKE_AGENT] %s (%s)\n", task.ID, agentDir)
+
+	go s.simulate(ctx, task, agentDir, attempt)
+
+	return nil
+}
+
+// simulate plays the scripted behaviour of an agent on its attempt at the
+// task, counted from 1
+func (s *FakeSpawner) simulate(ctx context.Context, task workflow.Task, agentDir string, attempt int) {
+	taskScript := s.script.Tasks[task.ID]
+
+	questionsDir := filepath.Join(agentDir, "questions")
+	for i, question := range taskScript.Questions {
+		qNum := i + 1
+		delay, _ := fakeDuration(question.Delay, 0)
+		if !work(ctx, agentDir, delay) {
+			return
+		}
+
+		qFile := filepath.Join(questionsDir, fmt.Sprintf("q-%d.txt", qNum))
+		if err := writeFileAtomic(qFile, []byte(question.Text)); err != nil {
+			fmt.Printf("[FAKE_AGENT] %s: failed to ask question: %v\n", task.ID, err)
+			return
+		}
+
+		aFile := filepath.Join(questionsDir, fmt.Sprintf("a-%d.txt", qNum))
+		timeout, _ := fakeDuration(question.Timeout, defaultFakeAnswerTimeout)
+		if !waitForFile(ctx, aFile, timeout) {
+			if ctx.Err() == nil {
+				fakeFail(task.ID, agentDir, fmt.Sprintf("no answer to question %d within %s", qNum, timeout), false)
+			}
+			return
+		}
+	}
+
+	if taskScript.Hang {
+		<-ctx.Done()
+		return
+	}
+
+	delay, _ := fakeDuration(taskScript.Delay, 0)
+	if !work(ctx, agentDir, delay) {
+		return
+	}
+
+	failing := taskScript.Failures == 0 || attempt <= taskScript.Failures
+	if failing && (taskScript.Fail != "" || taskScript.Crash) {
+		reason := taskScript.Fail
+		if reason == "" {
+			reason = "fake agent crashed"
+		}
+		fakeFail(task.ID, agentDir, reason, taskScript.Crash)
+		return
+	}
+
+	output := taskScript.Output
+	if output == "" {
+		output = s.script.DefaultOutput
+	}
+	if output == "" {
+		output = "Fake output for task {task}"
+	}
+	output = strings.ReplaceAll(output, "{task}", task.ID)
+
+	if err := os.WriteFile(filepath.Join(agentDir, "output.txt"), []byte(output), 0644); err != nil {
+		fmt.Printf("[FAKE_AGENT] %s: failed to write output: %v\n", task.ID, err)
+		return
+	}
+	if err := os.WriteFile(filepath.Join(agentDir, "status.txt"), []byte("completed"), 0644); err != nil {
+		fmt.Printf("[FAKE_AGENT] %s: failed to write status: %v\n", task.ID, err)
+		return
+	}
+	if err := os.WriteFile(filepath.Join(agentDir, "COMPLETE"), []byte(""), 0644); err != nil {
+		fmt.Printf("[FAKE_AGENT] %s: failed to create COMPLETE marker: %v\n", task.ID, err)
+	}
+}
+
+// fakeFail fails a simulated agent's task with a reason, or marks its
+// process crashed as the process spawner does
+func fakeFail(taskID, agentDir, reason string, crash bool) {
+	if err := os.WriteFile(filepath.Join(agentDir, "error.txt"), []byte(reason), 0644); err != nil {
+		fmt.Printf("[FAKE_AGENT] %s: failed to write error: %v\n", taskID, err)
+		return
+	}
+	if crash {
+		if err := os.WriteFile(filepath.Join(agentDir, "status.txt"), []byte(crashedStatus), 0644); err != nil {
+			fmt.Printf("[FAKE_AGENT] %s: failed to write status: %v\n", taskID, err)
+			return
+		}
+	}
+	if err := os.WriteFile(filepath.Join(agentDir, "FAILED"), []byte(""), 0644); err != nil {
+		fmt.Printf("[FAKE_AGENT] %s: failed to create FAILED marker: %v\n", taskID, err)
+	}
+}
+
+// work simulates an agent busy for a while, sending heartbeats like
+// swarm-agent does. It reports false if ctx was cancelled first.
+func work(ctx context.Context, agentDir string, d time.Duration) bool {
+	if d == 0 {
+		return ctx.Err() == nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	ticker := time.NewTicker(workflow.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-timer.C:
+			return true
+		case <-ticker.C:
+			os.WriteFile(filepath.Join(agentDir, workflow.HeartbeatFile), []byte(time.Now().Format(time.RFC3339)), 0644)
+		}
+	}
+}
+
+// writeFileAtomic writes a file via a temp file and rename so watchers never
+// observe partially written content
+func writeFileAtomic(path string, data []byte) error {
+	tmpFile := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, path)
+}
+
+// waitForFile polls until a file exists, the timeout expires or ctx is cancelled
+func waitForFile(ctx context.Context, path string, timeout time.Duration) bool {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-timer.C:
+			return false
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// heartbeatCheckInterval is how often running agents are checked for
+// signs of life
+const heartbeatCheckInterval = 15 * time.Second
+
+// startHeartbeatWatch marks agents stale when they go silent for the
+// workflow's heartbeat_timeout and fails their tasks when they stay
+// silent, so crashed agents don't look like they are running forever.
+// Without a heartbeat_timeout it does nothing. The returned function
+// stops it.
+func (o *Orchestrator) startHeartbeatWatch(ctx context.Context) func() {
+	timeout := o.state.Workflow.HeartbeatTimeoutDuration()
+	if timeout == 0 {
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+
+		ticker := time.NewTicker(heartbeatCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			o.checkHeartbeats(timeout)
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-finished
+	}
+}
+
+// checkHeartbeats marks silent agents stale and fails the tasks of agents
+// silent for HeartbeatDeadFactor timeouts
+func (o *Orchestrator) checkHeartbeats(timeout time.Duration) {
+	for _, agent := range o.state.GetActiveAgents() {
+		if agent.WorkingDir == "" {
+			continue // Sub-workflows are watched by their own orchestrator
+		}
+		silent := time.Since(lastSeen(agent))
+
+		switch {
+		case silent >= timeout*workflow.HeartbeatDeadFactor:
+			reason := fmt.Sprintf("no heartbeat for %s; the agent is presumed dead", silent.Round(time.Second))
+			if err := o.state.FailTask(agent.TaskID, reason); err != nil {
+				fmt.Printf("Failed to fail task %s: %v\n", agent.TaskID, err)
+				continue
+			}
+			fmt.Printf("[%s] Task failed: %s: %s\n", time.Now().Format("15:04:05"), agent.TaskID, reason)
+
+		case silent >= timeout:
+			if o.state.MarkStale(agent.TaskID) {
+				fmt.Printf("[%s] Agent stale: %s (no heartbeat for %s)\n",
+					time.Now().Format("15:04:05"), agent.TaskID, silent.Round(time.Second))
+			}
+		}
+	}
+}
+
+// lastSeen returns when an agent last showed signs of life: its start,
+// its last API heartbeat, or the latest change to its heartbeat file, log,
+// messages or questions. Agents waiting on the operator, for an answer or
+// a quota approval, count as alive.
+func lastSeen(agent *workflow.AgentState) time.Time {
+	if agent.QuotaPaused {
+		return time.Now()
+	}
+	for _, q := range agent.AllQuestions() {
+		if q.AnsweredAt.IsZero() {
+			return time.Now()
+		}
+	}
+
+	seen := agent.StartedAt
+	if agent.LastHeartbeat.After(seen) {
+		seen = agent.LastHeartbeat
+	}
+	for _, name := range []string{workflow.HeartbeatFile, agentLogFile, "messages", "questions"} {
+		info, err := os.Stat(filepath.Join(agent.WorkingDir, name))
+		if err == nil && info.ModTime().After(seen) {
+			seen = info.ModTime()
+		}
+	}
+	return seen
+}
@@ -0,0 +1,79 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// hookEventBuffer is how many events may queue for hooks before new ones
+// are dropped
+const hookEventBuffer = 256
+
+// startHooks runs the workflow's hooks on state events. The returned
+// function stops them and waits for running commands to finish.
+func (o *Orchestrator) startHooks(ctx context.Context) func() {
+	hooks := o.state.Workflow.Hooks
+	if len(hooks) == 0 {
+		return func() {}
+	}
+
+	events, cancel := o.state.Subscribe(hookEventBuffer)
+	finished := make(chan struct{})
+	var running sync.WaitGroup
+
+	go func() {
+		defer close(finished)
+		for event := range events {
+			for _, hook := range hooks {
+				if !hook.Matches(event.Type) {
+					continue
+				}
+				running.Add(1)
+				go func(hook workflow.Hook, event workflow.FileEvent) {
+					defer running.Done()
+					o.runHook(ctx, hook, event)
+				}(hook, event)
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-finished
+		running.Wait()
+	}
+}
+
+// runHook runs a hook's command for an event. Failures are logged; a
+// broken hook never affects the workflow.
+func (o *Orchestrator) runHook(ctx context.Context, hook workflow.Hook, event workflow.FileEvent) {
+	timeout := hook.TimeoutSeconds
+	if timeout == 0 {
+		timeout = workflow.DefaultHookTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	command := o.state.InterpolateSecrets(hook.Command(event, o.state.SessionID))
+	cmd := exec.CommandContext(ctx, "bash", "-c", command)
+	cmd.Env = append(os.Environ(),
+		"SWARM_EVENT="+string(event.Type),
+		"SWARM_TASK="+event.AgentID,
+		"SWARM_PATH="+event.FilePath,
+		"SWARM_SESSION="+o.state.SessionID,
+		"SWARM_DIR="+o.swarmDir,
+		"SWARM_OPERATOR="+event.Operator,
+	)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		fmt.Printf("Hook %q for %s failed: %v: %s\n", hook.Run, event.Type, err, o.state.Redact(strings.TrimSpace(string(output))))
+	}
+}
@@ -0,0 +1,92 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aristath/claude-swarm/internal/issues"
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// startIssueSync mirrors tasks into the workflow's issue tracker as they
+// start and finish. The returned function stops it once pending updates
+// are sent.
+func (o *Orchestrator) startIssueSync(ctx context.Context) func() {
+	cfg := o.state.Workflow.Issues
+	if cfg == nil {
+		return func() {}
+	}
+
+	tracker, err := issues.New(cfg)
+	if err != nil {
+		fmt.Printf("Issue sync disabled: %v\n", err)
+		return func() {}
+	}
+
+	events, cancel := o.state.Subscribe(hookEventBuffer)
+	finished := make(chan struct{})
+
+	// Events are handled in order, so an issue exists before it moves
+	go func() {
+		defer close(finished)
+		for event := range events {
+			if err := o.syncIssue(ctx, tracker, cfg, event); err != nil {
+				fmt.Printf("Failed to update issue for task %s: %v\n", event.AgentID, err)
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-finished
+	}
+}
+
+// syncIssue updates the issue of a task after an event
+func (o *Orchestrator) syncIssue(ctx context.Context, tracker issues.Tracker, cfg *workflow.Issues, event workflow.FileEvent) error {
+	var status workflow.TaskStatus
+	switch event.Type {
+	case workflow.EventTaskStarted:
+		status = workflow.TaskStatusRunning
+	case workflow.EventTaskCompleted:
+		status = workflow.TaskStatusCompleted
+	case workflow.EventTaskFailed:
+		status = workflow.TaskStatusFailed
+	default:
+		return nil
+	}
+
+	task := o.state.GetTask(event.AgentID)
+	agent := o.state.GetAgent(event.AgentID)
+	if task == nil || agent == nil || task.IsAggregate() {
+		return nil
+	}
+
+	key := agent.Issue
+	if key == "" {
+		key = task.Issue
+	}
+	if key == "" {
+		issueTask := *task
+		issueTask.Prompt = o.state.Workflow.InterpolateParams(task.Prompt)
+		issueTask.Description = o.state.Workflow.InterpolateParams(task.Description)
+		created, err := tracker.Create(ctx, issueTask)
+		if err != nil {
+			return err
+		}
+		key = created
+		fmt.Printf("Created issue %s for task %s\n", key, task.ID)
+	}
+	o.state.SetIssue(task.ID, key)
+
+	if status != workflow.TaskStatusRunning {
+		if err := tracker.Comment(ctx, key, issues.Report(agent)); err != nil {
+			return err
+		}
+	}
+
+	if name := cfg.Status(status); name != "" {
+		return tracker.Transition(ctx, key, name)
+	}
+	return nil
+}
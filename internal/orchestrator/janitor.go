@@ -0,0 +1,124 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// janitorInterval is how often answered messages are checked against
+// their TTL
+const janitorInterval = time.Minute
+
+// messageArchiveDir is where an agent's expired messages are moved when
+// they are archived
+const messageArchiveDir = "archive"
+
+// startMessageJanitor removes answered file-bus messages and their
+// responses once they are older than the workflow's message TTL, once at
+// startup and then periodically. The returned function stops it.
+func (o *Orchestrator) startMessageJanitor(ctx context.Context) func() {
+	cfg := o.state.Workflow.Retention
+	if cfg == nil || cfg.MessageTTL() == 0 {
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+
+		ticker := time.NewTicker(janitorInterval)
+		defer ticker.Stop()
+
+		for {
+			cleaned, err := cleanMessages(o.swarmDir, cfg.MessageTTL(), cfg.ArchiveMessages, time.Now())
+			if err != nil {
+				fmt.Printf("Failed to clean up messages: %v\n", err)
+			}
+			if cleaned > 0 {
+				o.state.RecordMessagesCleaned(cleaned, cfg.ArchiveMessages)
+				action := "Deleted"
+				if cfg.ArchiveMessages {
+					action = "Archived"
+				}
+				fmt.Printf("[%s] %s %d answered messages older than %s\n",
+					time.Now().Format("15:04:05"), action, cleaned, cfg.MessageTTL())
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-finished
+	}
+}
+
+// cleanMessages deletes, or archives, the messages under swarmDir answered
+// more than ttl ago, with their responses and any unread chunks, and
+// returns how many it cleaned. Messages still waiting for an answer are
+// never touched.
+func cleanMessages(swarmDir string, ttl time.Duration, archive bool, now time.Time) (int, error) {
+	agentDirs, err := filepath.Glob(filepath.Join(swarmDir, "agents", "agent-*"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list agent directories: %w", err)
+	}
+
+	cleaned := 0
+	for _, agentDir := range agentDirs {
+		messages, _ := filepath.Glob(filepath.Join(agentDir, "messages", "msg-*.json"))
+		responseDir := filepath.Join(agentDir, "responses")
+		for _, message := range messages {
+			id := strings.TrimSuffix(filepath.Base(message), ".json")
+			result := filepath.Join(responseDir, id+"-result.json")
+			info, err := os.Stat(result)
+			if err != nil || now.Sub(info.ModTime()) < ttl {
+				continue
+			}
+
+			// Chunks are read and removed by the agent, unless it went away
+			chunks, _ := filepath.Glob(filepath.Join(responseDir, id+"-chunk-*.json"))
+			files := append([]string{message, result}, chunks...)
+			if err := removeMessageFiles(agentDir, files, archive); err != nil {
+				return cleaned, err
+			}
+			cleaned++
+		}
+	}
+	return cleaned, nil
+}
+
+// removeMessageFiles deletes a message's files, or moves them to the
+// agent's archive directory. The message goes first: were its response
+// gone and it left, it would look unanswered and be handled again.
+func removeMessageFiles(agentDir string, files []string, archive bool) error {
+	archiveDir := filepath.Join(agentDir, messageArchiveDir)
+	if archive {
+		if err := os.MkdirAll(archiveDir, 0755); err != nil {
+			return fmt.Errorf("failed to create archive directory: %w", err)
+		}
+	}
+
+	for _, file := range files {
+		var err error
+		if archive {
+			err = os.Rename(file, filepath.Join(archiveDir, filepath.Base(file)))
+		} else {
+			err = os.Remove(file)
+		}
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to clean up %s: %w", file, err)
+		}
+	}
+	return nil
+}
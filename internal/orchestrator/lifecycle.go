@@ -0,0 +1,122 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// LifecycleCallback is a Go lifecycle hook, registered with
+// WithLifecycleHook. An error rejects at pre_spawn and post_complete, and
+// leaves the question to the orchestrator at on_question.
+type LifecycleCallback func(ctx context.Context, payload workflow.HookPayload) (workflow.HookResult, error)
+
+// lifecycleCallback is a LifecycleCallback and the point it runs at
+type lifecycleCallback struct {
+	point workflow.LifecyclePoint
+	fn    LifecycleCallback
+}
+
+// RunLifecycleHooks runs the workflow's lifecycle hooks for a point, then
+// the registered callbacks, in order. The first rejection, or at
+// on_question the first answer, is returned without running the rest.
+func (o *Orchestrator) RunLifecycleHooks(ctx context.Context, payload workflow.HookPayload) workflow.HookResult {
+	payload.Session = o.state.SessionID
+
+	for _, hook := range o.state.Workflow.Lifecycle {
+		if !hook.Runs(payload.Point) {
+			continue
+		}
+		result, err := o.runLifecycleHook(ctx, hook, payload)
+		if done, result := o.settleLifecycle(payload, hook.Run, result, err); done {
+			return result
+		}
+	}
+
+	for _, callback := range o.lifecycleCallbacks {
+		if callback.point != payload.Point {
+			continue
+		}
+		result, err := callback.fn(ctx, payload)
+		if done, result := o.settleLifecycle(payload, "callback", result, err); done {
+			return result
+		}
+	}
+
+	return workflow.HookResult{}
+}
+
+// settleLifecycle reports whether a hook's result decides its point. A
+// failed hook rejects, except at on_question where it is only logged.
+func (o *Orchestrator) settleLifecycle(payload workflow.HookPayload, name string, result workflow.HookResult, err error) (bool, workflow.HookResult) {
+	if payload.Point == workflow.LifecycleOnQuestion {
+		if err != nil {
+			fmt.Printf("[%s] Lifecycle hook %q at %s for %s failed: %v\n", time.Now().Format("15:04:05"), name, payload.Point, payload.Task, err)
+			return false, workflow.HookResult{}
+		}
+		return result.Answer != "", workflow.HookResult{Answer: result.Answer}
+	}
+
+	if err != nil {
+		result.Reject = err.Error()
+	}
+	if result.Reject == "" {
+		return false, workflow.HookResult{}
+	}
+	return true, workflow.HookResult{Reject: o.state.Redact(result.Reject)}
+}
+
+// runLifecycleHook runs a lifecycle hook's command with the payload on
+// stdin. A non-zero exit is an error with the command's stderr; otherwise
+// its stdout, if any, is the HookResult.
+func (o *Orchestrator) runLifecycleHook(ctx context.Context, hook workflow.LifecycleHook, payload workflow.HookPayload) (workflow.HookResult, error) {
+	var result workflow.HookResult
+
+	timeout := hook.TimeoutSeconds
+	if timeout == 0 {
+		timeout = workflow.DefaultHookTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	input, err := json.Marshal(payload)
+	if err != nil {
+		return result, fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "bash", "-c", o.state.InterpolateSecrets(hook.Run))
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(),
+		"SWARM_HOOK="+string(payload.Point),
+		"SWARM_TASK="+payload.Task,
+		"SWARM_SESSION="+payload.Session,
+		"SWARM_DIR="+o.swarmDir,
+	)
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return result, fmt.Errorf("timed out after %ds", timeout)
+		}
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return result, fmt.Errorf("%s", message)
+		}
+		return result, err
+	}
+
+	if output := bytes.TrimSpace(stdout.Bytes()); len(output) > 0 {
+		if err := json.Unmarshal(output, &result); err != nil {
+			return result, fmt.Errorf("invalid result: %w", err)
+		}
+	}
+	return result, nil
+}
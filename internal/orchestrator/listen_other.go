@@ -0,0 +1,21 @@
+//go:build !unix
+
+package orchestrator
+
+import (
+	"net"
+	"os"
+)
+
+// listenPrivate listens on a Unix socket only its owner may connect to
+func listenPrivate(path string) (net.Listener, error) {
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
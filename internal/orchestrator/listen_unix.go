@@ -0,0 +1,17 @@
+//go:build unix
+
+package orchestrator
+
+import (
+	"net"
+	"syscall"
+)
+
+// listenPrivate listens on a Unix socket only its owner may connect to.
+// The socket is created under a 0077 umask, so there is no moment when
+// other users could connect before its permissions are restricted.
+func listenPrivate(path string) (net.Listener, error) {
+	old := syscall.Umask(0077)
+	defer syscall.Umask(old)
+	return net.Listen("unix", path)
+}
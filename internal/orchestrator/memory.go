@@ -0,0 +1,144 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/memory"
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// pendingAnswer starts the placeholder answers left for the operator
+const pendingAnswer = "[ORCHESTRATOR NEEDS TO FORMULATE ANSWER]"
+
+// memoryLine is how much of an output, error or answer an entry keeps,
+// in bytes
+const memoryLine = 200
+
+// loadMemory reads the project memory for agent contexts. A resumed
+// session keeps the memory its agents started from.
+func (o *Orchestrator) loadMemory() {
+	snapshot := filepath.Join(o.swarmDir, memory.File)
+	if data, err := os.ReadFile(snapshot); err == nil {
+		o.recalled = string(data)
+		return
+	}
+	if o.memory == nil {
+		return
+	}
+
+	recalled, err := o.memory.Recent(0)
+	if err != nil {
+		fmt.Printf("Failed to read project memory: %v\n", err)
+		return
+	}
+	if recalled == "" {
+		return
+	}
+	if err := os.WriteFile(snapshot, []byte(recalled), 0644); err != nil {
+		fmt.Printf("Failed to write project memory: %v\n", err)
+	}
+	o.recalled = recalled
+}
+
+// memorySection formats the project memory for agent contexts
+func (o *Orchestrator) memorySection() string {
+	if o.recalled == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(`## Project Memory
+Decisions and outcomes of earlier swarm runs in this repository, oldest
+first. Stay consistent with them unless your task says otherwise.
+
+%s
+
+`, o.recalled)
+}
+
+// remember appends the outcome of a finished run, with the err Run
+// returns, to the project memory. Sub-workflows are part of their
+// parent's run.
+func (o *Orchestrator) remember(err error) {
+	if o.memory == nil || len(o.ancestors) > 0 {
+		return
+	}
+
+	if err := o.memory.Append(o.memoryEntry(err)); err != nil {
+		fmt.Printf("Failed to update project memory: %v\n", err)
+		return
+	}
+	fmt.Printf("[%s] Project memory updated: %s\n", time.Now().Format("15:04:05"), o.memory.Path())
+}
+
+// memoryEntry describes a finished run: its tasks' outcomes and the
+// answers agents were given
+func (o *Orchestrator) memoryEntry(err error) string {
+	status := "completed"
+	switch {
+	case errors.Is(err, ErrCancelled):
+		status = "cancelled"
+	case err != nil:
+		status = "failed"
+	}
+
+	wf := o.state.Workflow
+	var entry strings.Builder
+	fmt.Fprintf(&entry, "## %s · %s · %s\n\n", time.Now().Format("2006-01-02 15:04"), wf.Name, status)
+	fmt.Fprintf(&entry, "Session %s.", o.state.SessionID)
+	if goal := firstLine(wf.Description); goal != "" {
+		fmt.Fprintf(&entry, " Goal: %s", goal)
+	}
+	entry.WriteString("\n\nOutcomes:\n")
+
+	var decisions []string
+	for _, task := range o.state.GetTasks() {
+		agent := o.state.GetAgent(task.ID)
+		if agent == nil {
+			fmt.Fprintf(&entry, "- `%s` not run\n", task.ID)
+			continue
+		}
+
+		switch agent.Status {
+		case workflow.TaskStatusCompleted:
+			fmt.Fprintf(&entry, "- `%s` completed: %s\n", task.ID, firstLine(agent.Output))
+		case workflow.TaskStatusFailed, workflow.TaskStatusQuarantined:
+			fmt.Fprintf(&entry, "- `%s` %s: %s\n", task.ID, agent.Status, firstLine(agent.Error))
+		default:
+			fmt.Fprintf(&entry, "- `%s` %s\n", task.ID, agent.Status)
+		}
+
+		for _, question := range agent.AllQuestions() {
+			if question.Answer == "" || strings.HasPrefix(question.Answer, pendingAnswer) {
+				continue
+			}
+			decisions = append(decisions, fmt.Sprintf("- `%s` asked %q: %s\n", task.ID, firstLine(question.Text), firstLine(question.Answer)))
+		}
+	}
+
+	if len(decisions) > 0 {
+		entry.WriteString("\nDecisions:\n")
+		entry.WriteString(strings.Join(decisions, ""))
+	}
+	return entry.String()
+}
+
+// firstLine returns the first non-empty line of a text, shortened to
+// memoryLine bytes
+func firstLine(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if len(line) > memoryLine {
+			line = strings.ToValidUTF8(line[:memoryLine], "") + "…"
+		}
+		return line
+	}
+	return ""
+}
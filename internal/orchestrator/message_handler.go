@@ -1,31 +1,46 @@
 package orchestrator
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/aristath/claude-swarm/internal/coalesce"
+	"github.com/aristath/claude-swarm/internal/proposals"
+	"github.com/aristath/claude-swarm/internal/state"
+	"github.com/aristath/claude-swarm/internal/version"
 	"github.com/aristath/claude-swarm/internal/workflow"
 )
 
 // MessageHandler handles messages from agents
 type MessageHandler struct {
 	orchestrator *Orchestrator
+	// inflight are the messages running, by agent and message ID, closed
+	// when done so duplicates can wait for them
+	inflight   map[string]chan struct{}
+	inflightMu sync.Mutex
 }
 
 // NewMessageHandler creates a new message handler
 func NewMessageHandler(orch *Orchestrator) *MessageHandler {
 	return &MessageHandler{
 		orchestrator: orch,
+		inflight:     make(map[string]chan struct{}),
 	}
 }
 
 // HandleMessage processes a message from an agent
-func (h *MessageHandler) HandleMessage(messagePath string) error {
+func (h *MessageHandler) HandleMessage(ctx context.Context, messagePath string) error {
+	received := time.Now()
+
 	// Read message
 	data, err := os.ReadFile(messagePath)
 	if err != nil {
@@ -37,13 +52,21 @@ func (h *MessageHandler) HandleMessage(messagePath string) error {
 		return fmt.Errorf("failed to parse message: %w", err)
 	}
 
-	// Execute operation
-	response := h.executeOperation(&msg)
-
-	// Write response
 	agentDir := filepath.Dir(filepath.Dir(messagePath)) // messages/msg-X.json -> agent dir
+	agentID := strings.TrimPrefix(filepath.Base(agentDir), "agent-")
+
+	// Chunks of streamed responses are numbered files next to the result
 	responseDir := filepath.Join(agentDir, "responses")
 	os.MkdirAll(responseDir, 0755)
+	response := h.respond(ctx, agentID, &msg, func(chunk workflow.Response) error {
+		data, err := json.Marshal(chunk)
+		if err != nil {
+			return err
+		}
+		return writeFileAtomic(filepath.Join(responseDir, workflow.ChunkFile(msg.ID, chunk.Sequence)), data)
+	})
+
+	// Write response
 
 	responseFile := filepath.Join(responseDir, fmt.Sprintf("%s-result.json", msg.ID))
 	responseData, err := json.MarshalIndent(response, "", "  ")
@@ -51,62 +74,159 @@ func (h *MessageHandler) HandleMessage(messagePath string) error {
 		return fmt.Errorf("failed to marshal response: %w", err)
 	}
 
-	if err := os.WriteFile(responseFile, responseData, 0644); err != nil {
+	if err := writeFileAtomic(responseFile, responseData); err != nil {
 		return fmt.Errorf("failed to write response: %w", err)
 	}
+	h.handled(&msg, response, received)
+
+	return nil
+}
+
+// respond executes a message from an agent, over any transport, and
+// returns the response to send back. Streamed messages get their data in
+// chunks through send first.
+func (h *MessageHandler) respond(ctx context.Context, agentID string, msg *workflow.Message, send func(workflow.Response) error) workflow.Response {
+	// Execute operation, or reuse the recorded response when replaying.
+	// Messages delivered again get their original response, and messages
+	// from agents speaking an unsupported protocol are rejected without
+	// running anything.
+	var response workflow.Response
+	original, release := h.claim(ctx, agentID, msg)
+	defer release()
+	if original != nil {
+		response = *original
+	} else if err := version.CheckProtocol(msg.ProtocolVersion); err != nil {
+		response = workflow.Response{
+			MessageID: msg.ID,
+			Timestamp: time.Now(),
+		}
+		response.SetError(workflow.WithCode(workflow.ErrorUnsupportedProtocol, err))
+	} else if err := decodeMessage(msg); err != nil {
+		response = workflow.Response{
+			MessageID: msg.ID,
+			Timestamp: time.Now(),
+		}
+		response.SetError(err)
+	} else {
+		var stream *responseStream
+		if msg.Stream {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithCancel(ctx)
+			defer cancel()
+			// The data sent is kept for the recorder, and for duplicates
+			keep := h.orchestrator.recorder != nil || !repeatable[msg.Type]
+			stream = newResponseStream(msg, send, cancel, keep)
+		}
+
+		replayed := false
+		if h.orchestrator.replayer != nil {
+			response, replayed = h.orchestrator.replayer.Response(agentID, msg)
+		}
+		if !replayed {
+			response = h.executeOperation(ctx, agentID, msg, stream)
+		}
+		recorded := response
+		if stream != nil {
+			stream.finish(&response)
+			recorded = stream.recorded(response)
+		}
+		if h.orchestrator.recorder != nil {
+			if err := h.orchestrator.recorder.RecordResponse(agentID, msg, recorded); err != nil {
+				fmt.Printf("Failed to record response: %v\n", err)
+			}
+		}
+		if msg.ID != "" && !repeatable[msg.Type] {
+			h.orchestrator.state.RecordProcessed(agentID, msg.ID, recorded)
+		}
+	}
+	response.ProtocolVersion = version.ProtocolVersion
+
+	// A command exiting non-zero is an answer, not a failed operation
+	if original == nil && response.Status == "error" && response.Code != workflow.ErrorCommandFailed {
+		h.orchestrator.state.RecordOperationError(agentID, response.Code)
+	}
+
+	// Large data is compressed for agents that accept it
+	if err := response.CompressData(msg.AcceptEncoding); err != nil {
+		fmt.Printf("Failed to compress response: %v\n", err)
+	}
+
+	return response
+}
+
+// handled records how long a message took and logs it
+func (h *MessageHandler) handled(msg *workflow.Message, response workflow.Response, received time.Time) {
+	h.orchestrator.state.RecordOperation(time.Since(received))
 
 	fmt.Printf("[%s] Handled message %s: %s (status: %s)\n",
 		time.Now().Format("15:04:05"),
 		msg.ID,
 		msg.Type,
 		response.Status)
-
-	return nil
 }
 
-// executeOperation executes the requested operation
-func (h *MessageHandler) executeOperation(msg *workflow.Message) workflow.Response {
+// executeOperation executes the requested operation. Commands stream
+// their output while they run when the agent asked for a stream.
+func (h *MessageHandler) executeOperation(ctx context.Context, agentID string, msg *workflow.Message, stream *responseStream) workflow.Response {
+	// Each operation of a batch counts against quotas on its own
+	if msg.Type == workflow.MessageTypeBatch {
+		return h.executeBatch(ctx, agentID, msg)
+	}
+
 	response := workflow.Response{
 		MessageID: msg.ID,
 		Timestamp: time.Now(),
 	}
 
+	// Count writes and bash commands against the agent's quotas
+	if err := h.reserveQuota(agentID, msg); err != nil {
+		response.SetError(err)
+		return response
+	}
+
+	// In read-only runs, writes become proposals and bash is restricted
+	if h.orchestrator.state.IsReadOnly() {
+		if response, handled := h.executeReadOnly(agentID, msg); handled {
+			return response
+		}
+	}
+
 	switch msg.Type {
 	case workflow.MessageTypeReadFile:
-		content, err := os.ReadFile(msg.Path)
+		file, shared, err := coalesce.ReadFile(msg.Path)
+		h.recordCoalesced(shared)
 		if err != nil {
-			response.Status = "error"
-			response.Error = err.Error()
+			response.SetError(err)
 		} else {
+			h.orchestrator.state.RecordFileRead(file.CacheHit || shared, len(file.Content))
 			response.Status = "success"
-			response.Data = string(content)
+			response.Data = file.Content
+			response.Checksum = workflow.Checksum(file.Content)
 		}
 
 	case workflow.MessageTypeWriteFile:
-		err := os.WriteFile(msg.Path, []byte(msg.Content), 0644)
+		checksum, err := h.writeFile(agentID, msg.Path, msg.Content, msg.ExpectedChecksum)
 		if err != nil {
-			response.Status = "error"
-			response.Error = err.Error()
+			response.SetError(err)
 		} else {
 			response.Status = "success"
 			response.Data = fmt.Sprintf("Wrote %d bytes to %s", len(msg.Content), msg.Path)
+			response.Checksum = checksum
 		}
 
 	case workflow.MessageTypeEditFile:
-		err := h.applyEdits(msg.Path, msg.Edits)
+		err := h.applyEdits(agentID, msg.Path, msg.Edits, msg.ExpectedChecksum)
 		if err != nil {
-			response.Status = "error"
-			response.Error = err.Error()
+			response.SetError(err)
 		} else {
 			response.Status = "success"
 			response.Data = fmt.Sprintf("Applied %d edits to %s", len(msg.Edits), msg.Path)
 		}
 
 	case workflow.MessageTypeBash:
-		output, err := h.executeBash(msg.Command, msg.WorkingDir)
+		output, err := h.executeBash(ctx, msg.Command, msg.WorkingDir, stream)
 		if err != nil {
-			response.Status = "error"
-			response.Error = err.Error()
+			response.SetError(err)
 			response.Data = output // Include partial output
 		} else {
 			response.Status = "success"
@@ -114,93 +234,429 @@ func (h *MessageHandler) executeOperation(msg *workflow.Message) workflow.Respon
 		}
 
 	case workflow.MessageTypeGlob:
-		matches, err := h.executeGlob(msg.Path)
+		matches, next, err := h.executeGlob(msg)
 		if err != nil {
-			response.Status = "error"
-			response.Error = err.Error()
+			response.SetError(err)
 		} else {
 			response.Status = "success"
 			response.Data = strings.Join(matches, "\n")
+			response.NextCursor = next
 		}
 
 	case workflow.MessageTypeGrep:
-		results, err := h.executeGrep(msg)
+		results, next, err := h.executeGrep(ctx, msg)
+		if err != nil {
+			response.SetError(err)
+		} else {
+			response.Status = "success"
+			response.Data = strings.Join(results, "\n")
+			response.NextCursor = next
+		}
+
+	case workflow.MessageTypeCodeSearch:
+		results, next, err := h.executeCodeSearch(ctx, msg)
 		if err != nil {
-			response.Status = "error"
-			response.Error = err.Error()
+			response.SetError(err)
 		} else {
 			response.Status = "success"
-			response.Data = results
+			response.Data = strings.Join(results, "\n")
+			response.NextCursor = next
+		}
+
+	case workflow.MessageTypeLock:
+		lock, err := h.orchestrator.state.AcquireLock(agentID, msg.Path, time.Duration(msg.TTLSeconds)*time.Second)
+		if err != nil {
+			response.SetError(err)
+		} else {
+			response.Status = "success"
+			response.Data = marshalLocks(lock)
+		}
+
+	case workflow.MessageTypeUnlock:
+		if err := h.orchestrator.state.ReleaseLock(agentID, msg.Path); err != nil {
+			response.SetError(err)
+		} else {
+			response.Status = "success"
+			response.Data = fmt.Sprintf("Unlocked %s", msg.Path)
+		}
+
+	case workflow.MessageTypeLocks:
+		response.Status = "success"
+		response.Data = marshalLocks(h.orchestrator.state.GetLocks()...)
+
+	case workflow.MessageTypeAddTasks:
+		added, err := h.addTasks(agentID, msg.Content)
+		if err != nil {
+			response.SetError(err)
+		} else {
+			response.Status = "success"
+			response.Data = strings.Join(added, "\n")
+		}
+
+	case workflow.MessageTypeUsage:
+		data, err := h.recordUsage(agentID, msg.Usage)
+		if err != nil {
+			response.SetError(err)
+		} else {
+			response.Status = "success"
+			response.Data = data
+		}
+
+	case workflow.MessageTypeCheckpoint:
+		if err := h.orchestrator.state.SaveOutputCheckpoint(agentID, msg.Content); err != nil {
+			response.SetError(err)
+		} else {
+			response.Status = "success"
+			response.Data = fmt.Sprintf("Checkpoint saved (%d bytes)", len(msg.Content))
+		}
+
+	case workflow.MessageTypeDeps:
+		deps, err := h.orchestrator.state.Dependencies(agentID)
+		if err != nil {
+			response.SetError(err)
+		} else {
+			response.Status = "success"
+			response.Data = marshalDependencies(deps)
+		}
+
+	case workflow.MessageTypeDepOutput:
+		output, err := h.orchestrator.state.DependencyOutput(agentID, msg.Task)
+		if err != nil {
+			response.SetError(err)
+		} else {
+			response.Status = "success"
+			response.Data = output
+		}
+
+	case workflow.MessageTypeProgress:
+		if err := h.orchestrator.state.RecordProgress(agentID, msg.Content, msg.Percent); err != nil {
+			response.SetError(err)
+		} else {
+			response.Status = "success"
+			response.Data = workflow.FormatProgress(msg.Content, msg.Percent)
 		}
 
 	default:
-		response.Status = "error"
-		response.Error = fmt.Sprintf("unknown message type: %s", msg.Type)
+		response.SetError(workflow.Errorf(workflow.ErrorInvalidRequest, "unknown message type: %s", msg.Type))
 	}
 
 	return response
 }
 
-// applyEdits applies edit operations to a file
-func (h *MessageHandler) applyEdits(path string, edits []workflow.Edit) error {
-	// Read current content
+// executeCodeSearch looks up the definitions of, or references to, a
+// symbol in the tree at msg.Path, returning one page of results
+func (h *MessageHandler) executeCodeSearch(ctx context.Context, msg *workflow.Message) ([]string, string, error) {
+	query := []string{"code_search", msg.Symbol, msg.Kind, msg.Path, fmt.Sprint(msg.References)}
+	page, err := workflow.NewPagination(msg.MaxResults, msg.Cursor, query...)
+	if err != nil {
+		return nil, "", err
+	}
+
+	results, shared, err := coalesce.SearchSymbols(ctx, msg.Path, msg.Symbol, msg.Kind, msg.References)
+	h.recordCoalesced(shared)
+	if err != nil {
+		return nil, "", err
+	}
+
+	results, next := page.Page(results, query...)
+	return results, next, nil
+}
+
+// recordCoalesced counts an operation that shared another's result
+func (h *MessageHandler) recordCoalesced(shared bool) {
+	if shared {
+		h.orchestrator.state.RecordCoalesced()
+	}
+}
+
+// marshalLocks formats locks as a JSON array for responses
+func marshalLocks(locks ...state.FileLock) string {
+	if locks == nil {
+		locks = []state.FileLock{}
+	}
+	data, err := json.MarshalIndent(locks, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// marshalDependencies encodes upstream tasks for a response
+func marshalDependencies(deps []state.Dependency) string {
+	data, err := json.MarshalIndent(deps, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// addTasks merges a tasks fragment from an agent into the running
+// workflow
+func (h *MessageHandler) addTasks(agentID, content string) ([]string, error) {
+	tasks, err := workflow.ParseFragment([]byte(content))
+	if err != nil {
+		return nil, err
+	}
+
+	added, err := h.orchestrator.state.AddTasks(agentID, tasks)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("Agent %s added tasks: %s\n", agentID, strings.Join(added, ", "))
+	return added, nil
+}
+
+// reserveQuota checks a message against the agent's quotas
+func (h *MessageHandler) reserveQuota(agentID string, msg *workflow.Message) error {
+	swarmState := h.orchestrator.state
+
+	switch msg.Type {
+	case workflow.MessageTypeWriteFile:
+		return swarmState.ReserveOperation(agentID, state.OperationWrite, msg.Path, int64(len(msg.Content)))
+
+	case workflow.MessageTypeEditFile:
+		var bytes int64
+		for _, edit := range msg.Edits {
+			bytes += int64(len(edit.NewString))
+		}
+		return swarmState.ReserveOperation(agentID, state.OperationWrite, msg.Path, bytes)
+
+	case workflow.MessageTypeBash:
+		return swarmState.ReserveOperation(agentID, state.OperationBash, "", 0)
+	}
+
+	return nil
+}
+
+// executeReadOnly handles the operations that behave differently in
+// read-only runs. Reads see the agent's own proposed content, writes and
+// edits are stored as proposals and bash commands with side effects are
+// rejected. It returns false for operations that run normally.
+func (h *MessageHandler) executeReadOnly(agentID string, msg *workflow.Message) (workflow.Response, bool) {
+	response := workflow.Response{
+		MessageID: msg.ID,
+		Timestamp: time.Now(),
+	}
+	store := h.orchestrator.proposals
+
+	var proposed string
+	switch msg.Type {
+	case workflow.MessageTypeReadFile:
+		content, ok := store.Current(agentID, msg.Path)
+		if !ok {
+			return response, false
+		}
+		response.Status = "success"
+		response.Data = content
+		response.Checksum = workflow.Checksum(content)
+		return response, true
+
+	case workflow.MessageTypeWriteFile:
+		base, exists, err := h.proposedContent(agentID, msg.Path)
+		if err == nil {
+			err = workflow.CheckUnchanged(msg.Path, base, exists, msg.ExpectedChecksum)
+		}
+		if err != nil {
+			response.SetError(err)
+			return response, true
+		}
+		proposed = msg.Content
+
+	case workflow.MessageTypeEditFile:
+		base, exists, err := h.proposedContent(agentID, msg.Path)
+		if err == nil && !exists {
+			err = fmt.Errorf("failed to read file: %w", os.ErrNotExist)
+		}
+		if err == nil {
+			err = workflow.CheckUnchanged(msg.Path, base, exists, msg.ExpectedChecksum)
+		}
+		if err != nil {
+			response.SetError(err)
+			return response, true
+		}
+
+		result, err := workflow.ApplyEdits(base, msg.Edits)
+		if err != nil {
+			response.SetError(err)
+			return response, true
+		}
+		proposed = result
+
+	case workflow.MessageTypeBash:
+		if err := proposals.CheckReadOnlyCommand(msg.Command); err != nil {
+			response.SetError(err)
+			return response, true
+		}
+		return response, false
+
+	default:
+		return response, false
+	}
+
+	proposal, err := store.Propose(agentID, msg.Path, proposed)
+	if err != nil {
+		response.SetError(err)
+		return response, true
+	}
+
+	response.Status = "success"
+	response.Data = fmt.Sprintf("Read-only mode: change to %s recorded as proposal %s", msg.Path, proposal.ID)
+	return response, true
+}
+
+// proposedContent returns a file as the agent sees it in a read-only run:
+// its own proposed content, or the file on disk
+func (h *MessageHandler) proposedContent(agentID, path string) (string, bool, error) {
+	if content, ok := h.orchestrator.proposals.Current(agentID, path); ok {
+		return content, true, nil
+	}
+	return readCurrent(path)
+}
+
+// readCurrent reads a file, reporting whether it exists
+func readCurrent(path string) (string, bool, error) {
 	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", false, nil
+	}
 	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
+		return "", false, fmt.Errorf("failed to read file: %w", err)
+	}
+	return string(content), true, nil
+}
+
+// decodeMessage restores a message's compressed content and verifies it
+// against the agent's checksum
+func decodeMessage(msg *workflow.Message) error {
+	if err := msg.DecodeContent(); err != nil {
+		return err
 	}
+	return workflow.VerifyChecksum(msg.Content, msg.Checksum)
+}
 
-	result := string(content)
+// writeFile writes a file unless it changed from the expected checksum,
+// then reads it back to make sure it holds the content. It returns the
+// file's checksum.
+func (h *MessageHandler) writeFile(agentID, path, content, expected string) (string, error) {
+	h.orchestrator.state.FileLock().Lock()
+	defer h.orchestrator.state.FileLock().Unlock()
 
-	// Apply each edit
-	for i, edit := range edits {
-		// Check if old_string exists
-		if !strings.Contains(result, edit.OldString) {
-			return fmt.Errorf("edit %d: old_string not found in file", i+1)
+	current, exists, err := readCurrent(path)
+	if err != nil {
+		return "", err
+	}
+	if expected != "" {
+		if err := workflow.CheckUnchanged(path, current, exists, expected); err != nil {
+			return "", err
 		}
+	}
 
-		// Replace (only first occurrence to match Edit tool behavior)
-		result = strings.Replace(result, edit.OldString, edit.NewString, 1)
+	defer coalesce.Invalidate(path)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return "", err
+	}
+	h.orchestrator.trackChange(agentID, path, current, content)
+	return workflow.VerifyFile(path, workflow.Checksum(content))
+}
+
+// applyEdits applies edit operations to a file, unless it changed from the
+// expected checksum
+func (h *MessageHandler) applyEdits(agentID, path string, edits []workflow.Edit, expected string) error {
+	h.orchestrator.state.FileLock().Lock()
+	defer h.orchestrator.state.FileLock().Unlock()
+
+	// Read current content
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	if err := workflow.CheckUnchanged(path, string(content), true, expected); err != nil {
+		return err
+	}
+
+	result, err := workflow.ApplyEdits(string(content), edits)
+	if err != nil {
+		return err
 	}
 
 	// Write back
+	defer coalesce.Invalidate(path)
 	if err := os.WriteFile(path, []byte(result), 0644); err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
+	h.orchestrator.trackChange(agentID, path, string(content), result)
 
 	return nil
 }
 
-// executeBash executes a bash command
-func (h *MessageHandler) executeBash(command, workingDir string) (string, error) {
-	cmd := exec.Command("bash", "-c", command)
+// executeBash executes a bash command, returning its output or, with a
+// stream, sending it while the command runs
+func (h *MessageHandler) executeBash(ctx context.Context, command, workingDir string, stream *responseStream) (string, error) {
+	swarmState := h.orchestrator.state
+	swarmState.RecordBash()
+	cmd := exec.CommandContext(ctx, "bash", "-c", swarmState.InterpolateSecrets(command))
 
 	if workingDir != "" {
 		cmd.Dir = workingDir
 	}
 
+	if stream != nil {
+		return "", stream.run(cmd, swarmState.Redact)
+	}
 	output, err := cmd.CombinedOutput()
-	return string(output), err
+	return swarmState.Redact(string(output)), err
 }
 
 // executeGlob executes a glob pattern
-func (h *MessageHandler) executeGlob(pattern string) ([]string, error) {
-	matches, err := filepath.Glob(pattern)
+func (h *MessageHandler) executeGlob(msg *workflow.Message) ([]string, string, error) {
+	page, err := workflow.NewPagination(msg.MaxResults, msg.Cursor, "glob", msg.Path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	matches, shared, err := coalesce.Glob(msg.Path)
+	h.recordCoalesced(shared)
 	if err != nil {
-		return nil, fmt.Errorf("glob failed: %w", err)
+		return nil, "", err
 	}
 
-	return matches, nil
+	matches, next := page.Page(matches, "glob", msg.Path)
+	return matches, next, nil
 }
 
-// executeGrep executes a grep search
-func (h *MessageHandler) executeGrep(msg *workflow.Message) (string, error) {
-	// Simple grep implementation
-	// For now, just use bash grep
-	cmd := fmt.Sprintf("grep -r '%s' %s", msg.Content, msg.Path)
-	if msg.Path == "" {
-		cmd = fmt.Sprintf("grep -r '%s' .", msg.Content)
+// executeGrep executes a recursive grep for msg.Content under msg.Path,
+// returning one page of matching lines
+func (h *MessageHandler) executeGrep(ctx context.Context, msg *workflow.Message) ([]string, string, error) {
+	path := msg.Path
+	if path == "" {
+		path = "."
+	}
+
+	page, err := workflow.NewPagination(msg.MaxResults, msg.Cursor, "grep", msg.Content, path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	lines, shared, err := coalesce.Grep(ctx, []string{"-r", "--", msg.Content, path}, page.Limit())
+	h.recordCoalesced(shared)
+	if err != nil {
+		return nil, "", err
 	}
 
-	output, err := exec.Command("bash", "-c", cmd).CombinedOutput()
-	return string(output), err
+	lines, next := page.Page(lines, "grep", msg.Content, path)
+	return lines, next, nil
+}
+
+// recordUsage adds the tokens an agent reported to its usage and returns
+// a summary of it
+func (h *MessageHandler) recordUsage(agentID string, usage *workflow.TokenUsage) (string, error) {
+	if usage == nil {
+		return "", workflow.Errorf(workflow.ErrorInvalidRequest, "usage is required")
+	}
+	total, err := h.orchestrator.state.RecordUsage(agentID, *usage)
+	if err != nil {
+		return "", err
+	}
+	return workflow.FormatUsage(total), nil
 }
@@ -1,12 +1,16 @@
 package orchestrator
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/aristath/claude-swarm/internal/state"
 	"github.com/aristath/claude-swarm/internal/workflow"
 	"github.com/fsnotify/fsnotify"
 )
@@ -18,22 +22,44 @@ type FileMonitor struct {
 	events   chan workflow.FileEvent
 	errors   chan error
 	done     chan bool
+	stopOnce sync.Once
+	state    *state.SwarmState
+	queue    *eventQueue
+
+	// Agent requests sent but not yet answered, so a rescan after the
+	// watcher overflows does not send them twice. Only watch uses it.
+	requested map[string]bool
+
+	// Completion markers sent, by modification time; markers are written
+	// again when a task reruns. Only watch uses it.
+	markers map[string]time.Time
+
+	// How often the directories are scanned for files whose events the
+	// watcher missed, or zero not to poll
+	pollInterval time.Duration
 }
 
-// NewFileMonitor creates a new file monitor
-func NewFileMonitor(swarmDir string) (*FileMonitor, error) {
+// NewFileMonitor creates a new file monitor. Events that arrive faster
+// than the orchestrator handles them wait in a backlog, counted in the
+// swarm's metrics.
+func NewFileMonitor(swarmDir string, swarmState *state.SwarmState) (*FileMonitor, error) {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create watcher: %w", err)
 	}
 
-	return &FileMonitor{
-		swarmDir: swarmDir,
-		watcher:  watcher,
-		events:   make(chan workflow.FileEvent, 100),
-		errors:   make(chan error, 10),
-		done:     make(chan bool),
-	}, nil
+	m := &FileMonitor{
+		swarmDir:  swarmDir,
+		watcher:   watcher,
+		events:    make(chan workflow.FileEvent, 100),
+		errors:    make(chan error, 10),
+		done:      make(chan bool),
+		state:     swarmState,
+		requested: make(map[string]bool),
+		markers:   make(map[string]time.Time),
+	}
+	m.queue = newEventQueue(m.events, m.done, swarmState.RecordEventDeferred)
+	return m, nil
 }
 
 // Start begins monitoring for file changes
@@ -44,16 +70,28 @@ func (m *FileMonitor) Start() error {
 		return fmt.Errorf("failed to watch agents directory: %w", err)
 	}
 
+	// Watch the operator control directory
+	controlDir := filepath.Join(m.swarmDir, "control")
+	if err := os.MkdirAll(controlDir, 0755); err != nil {
+		return fmt.Errorf("failed to create control directory: %w", err)
+	}
+	if err := m.watcher.Add(controlDir); err != nil {
+		return fmt.Errorf("failed to watch control directory: %w", err)
+	}
+
 	// Start the watch loop in a goroutine
+	m.queue.start()
 	go m.watch()
 
 	return nil
 }
 
-// Stop stops the file monitor
+// Stop stops the file monitor. It is safe to call more than once.
 func (m *FileMonitor) Stop() {
-	close(m.done)
-	m.watcher.Close()
+	m.stopOnce.Do(func() {
+		close(m.done)
+		m.watcher.Close()
+	})
 }
 
 // Events returns the channel for file events
@@ -80,6 +118,10 @@ func (m *FileMonitor) watchDirectory(dir string) error {
 		}
 
 		if info.IsDir() {
+			// Child sessions of sub-workflows have their own monitor
+			if info.Name() == subSessionDir {
+				return filepath.SkipDir
+			}
 			if err := m.watcher.Add(path); err != nil {
 				return fmt.Errorf("failed to watch %s: %w", path, err)
 			}
@@ -91,11 +133,24 @@ func (m *FileMonitor) watchDirectory(dir string) error {
 
 // watch is the main event loop
 func (m *FileMonitor) watch() {
+	// A nil channel never fires, so without polling only events arrive
+	var poll <-chan time.Time
+	if m.pollInterval > 0 {
+		ticker := time.NewTicker(m.pollInterval)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+
 	for {
 		select {
 		case <-m.done:
 			return
 
+		case <-poll:
+			if recovered := m.reconcile(); recovered > 0 {
+				fmt.Printf("[%s] Polling found %d files the file watcher missed\n", time.Now().Format("15:04:05"), recovered)
+			}
+
 		case event, ok := <-m.watcher.Events:
 			if !ok {
 				return
@@ -110,17 +165,40 @@ func (m *FileMonitor) watch() {
 			if !ok {
 				return
 			}
-			m.errors <- err
+			if errors.Is(err, fsnotify.ErrEventOverflow) {
+				// The kernel dropped events, so look for what they announced
+				m.rescan()
+			}
+			m.reportError(err)
 		}
 	}
 }
 
+// reportError passes an error on without blocking the watch loop, logging
+// it instead when the channel is full
+func (m *FileMonitor) reportError(err error) {
+	select {
+	case m.errors <- err:
+	default:
+		fmt.Printf("[%s] Monitor error: %v\n", time.Now().Format("15:04:05"), err)
+	}
+}
+
 // handleCreate handles a file creation event
 func (m *FileMonitor) handleCreate(path string) {
 	// Check if it's a directory - if so, watch it
 	info, err := os.Stat(path)
 	if err == nil && info.IsDir() {
-		m.watcher.Add(path)
+		if filepath.Base(path) != subSessionDir {
+			m.watcher.Add(path)
+		}
+		return
+	}
+
+	// Operator control requests are not tied to an agent
+	filename := filepath.Base(path)
+	if filepath.Base(filepath.Dir(path)) == "control" && strings.HasPrefix(filename, "ctl-") && strings.HasSuffix(filename, ".json") {
+		m.emit(workflow.EventControlRequest, "", path)
 		return
 	}
 
@@ -130,29 +208,60 @@ func (m *FileMonitor) handleCreate(path string) {
 		return
 	}
 
+	// Answers settle the requests they answer
+	if request := requestFor(path); request != "" {
+		delete(m.requested, request)
+	}
+
 	// Determine event type based on file pattern
 	eventType := m.detectEventType(path)
 	if eventType == "" {
 		return
 	}
 
-	// Send event
-	m.events <- workflow.FileEvent{
-		Type:     workflow.EventType(eventType),
+	m.emit(workflow.EventType(eventType), agentID, path)
+}
+
+// emit queues an agent file event, remembering requests until answered
+// and completion markers, so neither is sent twice
+func (m *FileMonitor) emit(eventType workflow.EventType, agentID, path string) {
+	switch eventType {
+	case workflow.EventFileOperationRequest, workflow.EventQuestionAsked, workflow.EventFollowUpAsked, workflow.EventControlRequest:
+		if m.requested[path] {
+			return
+		}
+		m.requested[path] = true
+
+	case workflow.EventTaskCompleted, workflow.EventTaskFailed:
+		if info, err := os.Stat(path); err == nil {
+			if m.markers[path].Equal(info.ModTime()) {
+				return
+			}
+			m.markers[path] = info.ModTime()
+		}
+	}
+
+	m.queue.push(workflow.FileEvent{
+		Type:     eventType,
 		AgentID:  agentID,
 		FilePath: path,
-	}
+	})
 }
 
-// extractAgentID extracts the agent ID from a file path
+// extractAgentID extracts the agent ID from a file path. Files of a
+// sub-workflow's child session, under the agent's session directory,
+// belong to the child's own monitor.
 func (m *FileMonitor) extractAgentID(path string) string {
-	// Path format: .../agents/agent-<task-id>/...
-	re := regexp.MustCompile(`/agents/agent-([^/]+)/`)
-	matches := re.FindStringSubmatch(path)
-	if len(matches) > 1 {
-		return matches[1]
+	// Path format: <swarm-dir>/agents/agent-<task-id>/...
+	rel, err := filepath.Rel(m.swarmDir, path)
+	if err != nil {
+		return ""
 	}
-	return ""
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	if len(parts) < 3 || parts[0] != "agents" || !strings.HasPrefix(parts[1], "agent-") || parts[2] == subSessionDir {
+		return ""
+	}
+	return strings.TrimPrefix(parts[1], "agent-")
 }
 
 // detectEventType determines the event type from the file path
@@ -183,6 +292,9 @@ func (m *FileMonitor) detectEventType(path string) string {
 	case filename == "COMPLETE":
 		return string(workflow.EventTaskCompleted)
 
+	case filename == "FAILED":
+		return string(workflow.EventTaskFailed)
+
 	case filename == "status.txt":
 		return string(workflow.EventAgentStatusUpdate)
 
@@ -195,3 +307,131 @@ func (m *FileMonitor) detectEventType(path string) string {
 
 	return ""
 }
+
+// requestFor returns the request a response or answer file answers, or ""
+func requestFor(path string) string {
+	dir, filename := filepath.Split(path)
+	switch {
+	case filepath.Base(dir) == "responses" && strings.HasSuffix(filename, "-result.json"):
+		// responses/msg-X-result.json answers messages/msg-X.json
+		return filepath.Join(filepath.Dir(filepath.Clean(dir)), "messages", strings.TrimSuffix(filename, "-result.json")+".json")
+	case strings.HasPrefix(filename, "a-") && strings.HasSuffix(filename, ".txt"):
+		return filepath.Join(dir, "q-"+strings.TrimPrefix(filename, "a-"))
+	}
+	return ""
+}
+
+// answered reports whether a request has been answered, or handled and
+// removed
+func answered(eventType workflow.EventType, path string) bool {
+	if _, err := os.Stat(path); err != nil {
+		return true
+	}
+
+	var answer string
+	switch eventType {
+	case workflow.EventFileOperationRequest:
+		id := strings.TrimSuffix(filepath.Base(path), ".json")
+		answer = filepath.Join(filepath.Dir(filepath.Dir(path)), "responses", id+"-result.json")
+	case workflow.EventQuestionAsked, workflow.EventFollowUpAsked:
+		answer = filepath.Join(filepath.Dir(path), "a-"+strings.TrimPrefix(filepath.Base(path), "q-"))
+	default:
+		return false
+	}
+	_, err := os.Stat(answer)
+	return err == nil
+}
+
+// rescan recovers from the watcher dropping events: it watches directories
+// created meanwhile and sends what their events announced
+func (m *FileMonitor) rescan() {
+	agentsDir := filepath.Join(m.swarmDir, "agents")
+	if err := m.watchDirectory(agentsDir); err != nil {
+		m.reportError(fmt.Errorf("failed to rescan agents directory: %w", err))
+	}
+
+	recovered := m.reconcile()
+	fmt.Printf("[%s] File watcher overflowed, recovered %d requests\n", time.Now().Format("15:04:05"), recovered)
+}
+
+// reconcile scans the agent and control directories for files whose events
+// were never sent: requests still waiting for an answer, and completion
+// markers of tasks still running. It sends them, messages from all agents
+// in the order of their IDs, and returns how many.
+func (m *FileMonitor) reconcile() int {
+	agentsDir := filepath.Join(m.swarmDir, "agents")
+	waiting := make(map[string]bool)
+	recovered := 0
+	check := func(eventType workflow.EventType, agentID, path string) {
+		if answered(eventType, path) {
+			return
+		}
+		waiting[path] = true
+		if !m.requested[path] {
+			m.emit(eventType, agentID, path)
+			recovered++
+		}
+	}
+
+	type message struct{ agentID, path string }
+	var messages []message
+
+	filepath.Walk(agentsDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if info.Name() == subSessionDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		agentID := m.extractAgentID(path)
+		if agentID == "" {
+			return nil
+		}
+		switch eventType := workflow.EventType(m.detectEventType(path)); eventType {
+		case workflow.EventFileOperationRequest:
+			messages = append(messages, message{agentID, path})
+
+		case workflow.EventQuestionAsked, workflow.EventFollowUpAsked:
+			check(eventType, agentID, path)
+
+		case workflow.EventTaskCompleted, workflow.EventTaskFailed:
+			if filepath.Dir(path) != filepath.Join(agentsDir, "agent-"+agentID) {
+				return nil
+			}
+			if agent := m.state.GetAgent(agentID); agent == nil || agent.Status != workflow.TaskStatusRunning {
+				return nil
+			}
+			if !m.markers[path].Equal(info.ModTime()) {
+				m.emit(eventType, agentID, path)
+				recovered++
+			}
+		}
+		return nil
+	})
+
+	// Message IDs start with a ULID, so they sort by when they were sent
+	sort.SliceStable(messages, func(i, j int) bool {
+		return filepath.Base(messages[i].path) < filepath.Base(messages[j].path)
+	})
+	for _, msg := range messages {
+		check(workflow.EventFileOperationRequest, msg.agentID, msg.path)
+	}
+
+	controls, _ := filepath.Glob(filepath.Join(m.swarmDir, "control", "ctl-*.json"))
+	for _, path := range controls {
+		check(workflow.EventControlRequest, "", path)
+	}
+
+	// Forget requests answered while their answers went unseen
+	for path := range m.requested {
+		if !waiting[path] {
+			delete(m.requested, path)
+		}
+	}
+
+	m.state.RecordEventsRecovered(recovered)
+	return recovered
+}
@@ -0,0 +1,98 @@
+package orchestrator
+
+import (
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/agenttype"
+	"github.com/aristath/claude-swarm/internal/memory"
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// Option configures an Orchestrator
+type Option func(*Orchestrator)
+
+// WithSpawner sets the spawner used to launch agents
+func WithSpawner(spawner Spawner) Option {
+	return func(o *Orchestrator) {
+		o.spawner = spawner
+	}
+}
+
+// WithRecorder records answers and operation responses during the run
+func WithRecorder(recorder *Recorder) Option {
+	return func(o *Orchestrator) {
+		o.recorder = recorder
+	}
+}
+
+// WithReplayer substitutes recorded answers and operation responses for
+// live ones. Decisions missing from the recording are made live.
+func WithReplayer(replayer *Replayer) Option {
+	return func(o *Orchestrator) {
+		o.replayer = replayer
+	}
+}
+
+// WithTickInterval sets how often the orchestrator checks for work when
+// no event arrives. Zero keeps DefaultTickInterval.
+func WithTickInterval(interval time.Duration) Option {
+	return func(o *Orchestrator) {
+		if interval > 0 {
+			o.tickInterval = interval
+		}
+	}
+}
+
+// WithPollInterval sets how often the session's directories are scanned
+// for files whose events the file watcher missed, as it can on network
+// and container file systems. Zero turns polling off.
+func WithPollInterval(interval time.Duration) Option {
+	return func(o *Orchestrator) {
+		o.pollInterval = max(interval, 0)
+	}
+}
+
+// WithAPI sets the API server address and bearer token handed to agents
+// through their env.sh
+func WithAPI(url, token string) Option {
+	return func(o *Orchestrator) {
+		if url != "" {
+			o.apiURL = url
+		}
+		o.apiToken = token
+	}
+}
+
+// WithSummaryCommand sets the shell command summarizing long dependency
+// outputs, such as DefaultSummaryCommand. It reads the output on stdin;
+// {prompt} is replaced by the shell-quoted instructions. Without one,
+// long outputs are excerpted.
+func WithSummaryCommand(command string) Option {
+	return func(o *Orchestrator) {
+		o.summaryCommand = command
+	}
+}
+
+// WithLifecycleHook registers a Go callback run at a lifecycle point,
+// after the workflow's lifecycle hooks
+func WithLifecycleHook(point workflow.LifecyclePoint, fn LifecycleCallback) Option {
+	return func(o *Orchestrator) {
+		o.lifecycleCallbacks = append(o.lifecycleCallbacks, lifecycleCallback{point: point, fn: fn})
+	}
+}
+
+// WithProjectMemory gives agents the recent entries of a project's memory
+// and appends the outcome of the run to it when the run finishes
+func WithProjectMemory(projectMemory *memory.Memory) Option {
+	return func(o *Orchestrator) {
+		o.memory = projectMemory
+	}
+}
+
+// WithAgentTypes gives agents the system prompt, tool permissions and model
+// their task's agent type defines
+func WithAgentTypes(library *agenttype.Library) Option {
+	return func(o *Orchestrator) {
+		o.agentTypes = library
+	}
+}
@@ -1,43 +1,127 @@
 package orchestrator
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/aristath/claude-swarm/internal/agenttype"
+	"github.com/aristath/claude-swarm/internal/memory"
+	"github.com/aristath/claude-swarm/internal/proposals"
 	"github.com/aristath/claude-swarm/internal/state"
+	"github.com/aristath/claude-swarm/internal/version"
 	"github.com/aristath/claude-swarm/internal/workflow"
 )
 
 // Orchestrator coordinates the swarm execution
 type Orchestrator struct {
-	swarmDir       string
-	state          *state.SwarmState
-	monitor        *FileMonitor
-	persistence    *state.Persistence
-	parser         *workflow.Parser
-	messageHandler *MessageHandler
-	done           chan bool
+	swarmDir           string
+	state              *state.SwarmState
+	monitor            *FileMonitor
+	persistence        *state.Persistence
+	parser             *workflow.Parser
+	messageHandler     *MessageHandler
+	spawner            Spawner
+	recorder           *Recorder
+	replayer           *Replayer
+	proposals          *proposals.Store
+	apiURL             string
+	apiToken           string
+	repoMap            string
+	memory             *memory.Memory // Project memory runs are remembered in; nil for none
+	recalled           string         // The project memory agents are given
+	summaryCommand     string         // Summarizes long dependency outputs; "" excerpts them
+	summariesMu        sync.Mutex
+	summarizing        map[string]bool // Tasks whose outputs are being summarized
+	agentTypes         *agenttype.Library
+	lifecycleCallbacks []lifecycleCallback
+	tickInterval       time.Duration
+	pollInterval       time.Duration
+	ancestors          []string // Sub-workflow files this session runs under
+	done               chan bool
+	stopOnce           sync.Once
+	handlers           sync.WaitGroup // File operations in flight
+	childrenMu         sync.Mutex
+	children           map[string]*Orchestrator // Running sub-workflows by task ID
+	wake               chan struct{}            // Schedules tasks whose backoff is over
+	wakeMu             sync.Mutex
+	wakeTimer          *time.Timer
+	wakeDeadline       time.Time
+}
+
+// ErrTasksFailed is returned by Run when failed tasks leave the rest of the
+// workflow unable to run
+var ErrTasksFailed = errors.New("tasks failed")
+
+// DefaultTickInterval is how often the orchestrator checks for work when no
+// event arrives. Scheduling is driven by task events; the tick is a safety
+// net for events that were missed.
+const DefaultTickInterval = 30 * time.Second
+
+// DefaultPollInterval is how often the session's directories are scanned
+// for files the file watcher missed
+const DefaultPollInterval = 10 * time.Second
+
+// schedulingEvents are the state events after which tasks may become ready
+// or the run may be over
+var schedulingEvents = map[workflow.EventType]bool{
+	workflow.EventTaskCompleted:  true,
+	workflow.EventTaskFailed:     true,
+	workflow.EventTasksAdded:     true,
+	workflow.EventQuotaApproved:  true,
+	workflow.EventTaskRepeated:   true,
+	workflow.EventTaskRerun:      true,
+	workflow.EventTaskSkipped:    true,
+	workflow.EventAgentCrashed:   true,
+	workflow.EventSwarmResumed:   true,
+	workflow.EventSwarmCancelled: true,
+
+	// A failed verification may leave the task to run again
+	workflow.EventVerificationFailed: true,
+
+	// Dependents of conflicting changes wait for the resolution
+	workflow.EventConflictResolved: true,
 }
 
 // NewOrchestrator creates a new orchestrator
-func NewOrchestrator(swarmDir string, swarmState *state.SwarmState) (*Orchestrator, error) {
-	monitor, err := NewFileMonitor(swarmDir)
+func NewOrchestrator(swarmDir string, swarmState *state.SwarmState, opts ...Option) (*Orchestrator, error) {
+	// Resolve secrets for commands and redaction; they are never persisted
+	secrets, err := swarmState.Workflow.LoadSecrets()
+	if err != nil {
+		return nil, err
+	}
+	swarmState.SetSecrets(secrets)
+
+	monitor, err := NewFileMonitor(swarmDir, swarmState)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create file monitor: %w", err)
 	}
 
 	orch := &Orchestrator{
-		swarmDir:    swarmDir,
-		state:       swarmState,
-		monitor:     monitor,
-		persistence: state.NewPersistence(swarmDir),
-		parser:      workflow.NewParser(),
-		done:        make(chan bool),
+		swarmDir:     swarmDir,
+		state:        swarmState,
+		monitor:      monitor,
+		persistence:  state.NewPersistence(swarmDir),
+		parser:       workflow.NewParser(),
+		spawner:      &PromptSpawner{},
+		proposals:    proposals.NewStore(swarmDir),
+		apiURL:       DefaultAPIURL,
+		tickInterval: DefaultTickInterval,
+		pollInterval: DefaultPollInterval,
+		done:         make(chan bool),
+		wake:         make(chan struct{}, 1),
+	}
+
+	for _, opt := range opts {
+		opt(orch)
 	}
+	monitor.pollInterval = orch.pollInterval
 
 	// Initialize message handler (needs reference to orchestrator)
 	orch.messageHandler = NewMessageHandler(orch)
@@ -45,20 +129,79 @@ func NewOrchestrator(swarmDir string, swarmState *state.SwarmState) (*Orchestrat
 	return orch, nil
 }
 
-// Run starts the orchestrator
-func (o *Orchestrator) Run() error {
+// Run starts the orchestrator. It returns when the workflow completes, Stop
+// is called, or ctx is cancelled; cancellation is propagated to in-flight
+// operations and spawned agents.
+func (o *Orchestrator) Run(ctx context.Context) error {
+	// Publish the protocol version for swarm-agent to check
+	if err := version.Write(o.swarmDir); err != nil {
+		return err
+	}
+
+	// Map the repository once, for every agent's context
+	o.generateRepoMap(ctx)
+
+	// Start from what earlier runs in the repository remembered
+	o.loadMemory()
+
+	// Let in-flight file operations write their responses before returning
+	defer o.handlers.Wait()
+
+	// Run hooks on events until the orchestrator stops
+	stopHooks := o.startHooks(ctx)
+	defer stopHooks()
+
+	// Mirror tasks into the issue tracker, if one is configured
+	stopIssueSync := o.startIssueSync(ctx)
+	defer stopIssueSync()
+
+	// Email notifications, if configured
+	stopEmail := o.startEmail(ctx)
+	defer stopEmail()
+
+	// Prune agent artifacts, if a retention policy is configured
+	stopRetention := o.startRetention(ctx)
+	defer stopRetention()
+
+	// Clean up answered messages, if they have a TTL
+	stopJanitor := o.startMessageJanitor(ctx)
+	defer stopJanitor()
+
+	// Report tasks running far beyond their expected durations
+	stopOverdueWatch := o.startOverdueWatch(ctx)
+	defer stopOverdueWatch()
+
+	// Show agents' transcripts in the TUI
+	stopAgentLogTail := o.startAgentLogTail(ctx)
+	defer stopAgentLogTail()
+
+	// Fail the tasks of agents that stop showing signs of life
+	stopHeartbeatWatch := o.startHeartbeatWatch(ctx)
+	defer stopHeartbeatWatch()
+
+	// Completions and failures, over the file bus or the API, schedule
+	// the next tasks right away
+	stateEvents, unsubscribe := o.state.Subscribe(256)
+	defer unsubscribe()
+
+	// Agents send messages over the socket, the file bus is the fallback
+	stopSocket := o.startSocket(ctx)
+	defer stopSocket()
+
 	// Start file monitor
 	if err := o.monitor.Start(); err != nil {
 		return fmt.Errorf("failed to start file monitor: %w", err)
 	}
 
-	// Spawn initial tasks
-	if err := o.spawnReadyAgents(); err != nil {
-		return fmt.Errorf("failed to spawn initial agents: %w", err)
+	// Spawn initial tasks, unless resumed paused
+	if !o.state.IsPaused() {
+		if err := o.spawnReadyAgents(ctx); err != nil {
+			return fmt.Errorf("failed to spawn initial agents: %w", err)
+		}
 	}
 
 	// Main event loop
-	ticker := time.NewTicker(5 * time.Second)
+	ticker := time.NewTicker(o.tickInterval)
 	defer ticker.Stop()
 
 	for {
@@ -66,80 +209,213 @@ func (o *Orchestrator) Run() error {
 		case <-o.done:
 			return nil
 
+		case <-ctx.Done():
+			o.monitor.Stop()
+			o.writeChangelog()
+			if err := o.persistence.Save(o.state); err != nil {
+				fmt.Printf("Failed to save state: %v\n", err)
+			}
+			return ctx.Err()
+
 		case event := <-o.monitor.Events():
-			if err := o.handleEvent(event); err != nil {
+			if err := o.handleEvent(ctx, event); err != nil {
 				fmt.Printf("Error handling event: %v\n", err)
 			}
 
 		case err := <-o.monitor.Errors():
 			fmt.Printf("Monitor error: %v\n", err)
 
-		case <-ticker.C:
-			// Periodic tasks
-			o.spawnReadyAgents()
+		case event := <-stateEvents:
+			if event.Type == workflow.EventBudgetExceeded {
+				o.enforceBudget()
+			}
+			if event.Type == workflow.EventTaskCompleted || event.Type == workflow.EventTaskFailed {
+				o.writeTaskDiff(event.AgentID)
+			}
+			if !schedulingEvents[event.Type] {
+				continue
+			}
+			if done, err := o.schedule(ctx); done {
+				return err
+			}
 
-			// Save state
-			if err := o.persistence.Save(o.state); err != nil {
-				fmt.Printf("Failed to save state: %v\n", err)
+		case <-o.wake:
+			if done, err := o.schedule(ctx); done {
+				return err
 			}
 
-			// Check if workflow is complete
-			if o.state.IsComplete() {
-				o.state.MarkComplete()
-				o.done <- true
-				return nil
+		case <-ticker.C:
+			if done, err := o.schedule(ctx); done {
+				return err
 			}
 		}
 	}
 }
 
-// Stop stops the orchestrator
+// schedule spawns the tasks that are ready, saves the state and reports
+// whether the run is over, with the error Run returns
+func (o *Orchestrator) schedule(ctx context.Context) (done bool, err error) {
+	// Finished runs leave a changelog, and are remembered for the next
+	// ones in the repository
+	defer func() {
+		if done {
+			o.writeChangelog()
+			o.remember(err)
+		}
+	}()
+
+	if failed := o.state.HaltingFailures(); len(failed) > 0 {
+		o.halt(failed)
+	}
+
+	if !o.state.IsPaused() && !o.state.IsCancelled() && !o.state.IsHalted() {
+		o.spawnReadyAgents(ctx)
+	}
+
+	// Save state
+	if err := o.persistence.Save(o.state); err != nil {
+		fmt.Printf("Failed to save state: %v\n", err)
+	}
+
+	// Check if workflow is complete
+	if o.state.IsComplete() {
+		if failed := o.state.GetFailedTasks(); len(failed) > 0 {
+			fmt.Printf("[%s] Ignored failed tasks: %s\n", time.Now().Format("15:04:05"), strings.Join(failed, ", "))
+		}
+		o.state.MarkComplete()
+		if err := o.persistence.Save(o.state); err != nil {
+			fmt.Printf("Failed to save state: %v\n", err)
+		}
+		return true, nil
+	}
+
+	if o.state.IsCancelled() {
+		return true, ErrCancelled
+	}
+
+	if o.state.IsHalted() {
+		return true, fmt.Errorf("%w: %s (run halted)", ErrTasksFailed, strings.Join(o.state.GetFailedTasks(), ", "))
+	}
+
+	// Stop once failed tasks leave nothing else to run
+	if o.state.IsStalled() {
+		return true, fmt.Errorf("%w: %s", ErrTasksFailed, strings.Join(o.state.GetFailedTasks(), ", "))
+	}
+	return false, nil
+}
+
+// Stop stops the orchestrator. It is safe to call more than once.
 func (o *Orchestrator) Stop() {
-	o.monitor.Stop()
-	close(o.done)
+	o.stopOnce.Do(func() {
+		o.monitor.Stop()
+		close(o.done)
+	})
 }
 
 // handleEvent processes a file event
-func (o *Orchestrator) handleEvent(event workflow.FileEvent) error {
+func (o *Orchestrator) handleEvent(ctx context.Context, event workflow.FileEvent) error {
+	// Anything an agent writes shows it is alive
+	if event.AgentID != "" {
+		o.state.Heartbeat(event.AgentID)
+	}
+
 	switch event.Type {
 	case workflow.EventQuestionAsked:
-		return o.handleQuestionAsked(event)
+		return o.handleQuestionAsked(ctx, event)
 
 	case workflow.EventTaskCompleted:
-		return o.handleTaskCompleted(event)
+		return o.handleTaskCompleted(ctx, event)
+
+	case workflow.EventTaskFailed:
+		return o.handleTaskFailed(event)
 
 	case workflow.EventFollowUpAnswered:
 		return o.handleFollowUpAnswered(event)
 
 	case workflow.EventFileOperationRequest:
-		return o.messageHandler.HandleMessage(event.FilePath)
+		// Operations run concurrently, so one agent's slow command does not
+		// hold up the others and identical reads can be coalesced
+		o.handlers.Add(1)
+		go func() {
+			defer o.handlers.Done()
+			if err := o.messageHandler.HandleMessage(ctx, event.FilePath); err != nil {
+				fmt.Printf("Error handling message: %v\n", err)
+			}
+		}()
+		return nil
 
 	case workflow.EventAgentStatusUpdate:
 		// Just log it, state updates happen elsewhere
 		return nil
 
+	case workflow.EventControlRequest:
+		return o.handleControlRequest(ctx, event)
+
 	default:
 		return nil
 	}
 }
 
 // handleQuestionAsked handles a question from an agent
-func (o *Orchestrator) handleQuestionAsked(event workflow.FileEvent) error {
+func (o *Orchestrator) handleQuestionAsked(ctx context.Context, event workflow.FileEvent) error {
 	// Read the question
 	question, err := os.ReadFile(event.FilePath)
 	if err != nil {
 		return fmt.Errorf("failed to read question: %w", err)
 	}
+	question = []byte(o.state.Redact(string(question)))
 
-	// Extract question number from filename (e.g., q-1.txt -> 1)
+	// Extract question number from filename (e.g., q-1.txt -> 1), and the
+	// thread a reply continues (e.g., q-3-re-1.txt -> 1)
 	filename := filepath.Base(event.FilePath)
 	qNum := o.extractQuestionNumber(filename)
+	thread := o.extractThread(filename)
 
-	// Add to state
-	o.state.AddQuestion(event.AgentID, string(question))
+	// Add to state, as a reply in its thread or a new question
+	if thread > 0 {
+		if _, err := o.state.ReplyToQuestion(event.AgentID, thread, string(question)); err != nil {
+			fmt.Printf("Failed to thread question %d: %v\n", qNum, err)
+			o.state.AddQuestion(event.AgentID, string(question))
+		}
+	} else {
+		o.state.AddQuestion(event.AgentID, string(question))
+	}
 
-	// Formulate answer
-	answer := o.formulateAnswer(event.AgentID, string(question))
+	// Formulate answer, or reuse the recorded one when replaying, or a
+	// canned one when the question matches, or one from an on_question
+	// lifecycle hook
+	answer, replayed := "", false
+	if o.replayer != nil {
+		answer, replayed = o.replayer.Answer(event.AgentID, string(question))
+	}
+	canned := false
+	if !replayed {
+		answer, canned = o.state.CannedAnswer(event.AgentID, string(question))
+	}
+	if !replayed && !canned {
+		answer = o.RunLifecycleHooks(ctx, workflow.HookPayload{
+			Point:      workflow.LifecycleOnQuestion,
+			Task:       event.AgentID,
+			AgentDir:   filepath.Dir(filepath.Dir(event.FilePath)),
+			Question:   string(question),
+			QuestionID: qNum,
+		}).Answer
+	}
+	if !replayed && !canned && answer == "" {
+		answer = o.formulateAnswer(event.AgentID, string(question), qNum)
+
+		// Drafted answers wait for an operator when the workflow reviews
+		// them
+		if o.state.Workflow.ReviewAnswers {
+			fmt.Printf("[%s] Question from agent %s: %s\n", time.Now().Format("15:04:05"), event.AgentID, string(question))
+			return o.queueDraft(event.AgentID, event.FilePath, qNum, answer)
+		}
+	}
+	if o.recorder != nil {
+		if err := o.recorder.RecordAnswer(event.AgentID, string(question), answer); err != nil {
+			fmt.Printf("Failed to record answer: %v\n", err)
+		}
+	}
 
 	// Write answer file
 	answerFile := strings.Replace(event.FilePath, "q-", "a-", 1)
@@ -148,16 +424,20 @@ func (o *Orchestrator) handleQuestionAsked(event workflow.FileEvent) error {
 	}
 
 	// Update state
-	o.state.AnswerQuestion(event.AgentID, qNum, answer)
+	o.state.AnswerQuestion(event.AgentID, qNum, answer, "")
 
 	fmt.Printf("[%s] Question from agent %s: %s\n", time.Now().Format("15:04:05"), event.AgentID, string(question))
-	fmt.Printf("[%s] Answer: %s\n", time.Now().Format("15:04:05"), answer)
+	if canned {
+		fmt.Printf("[%s] Canned answer: %s\n", time.Now().Format("15:04:05"), answer)
+	} else {
+		fmt.Printf("[%s] Answer: %s\n", time.Now().Format("15:04:05"), answer)
+	}
 
 	return nil
 }
 
 // handleTaskCompleted handles task completion
-func (o *Orchestrator) handleTaskCompleted(event workflow.FileEvent) error {
+func (o *Orchestrator) handleTaskCompleted(ctx context.Context, event workflow.FileEvent) error {
 	// Read output file
 	outputFile := filepath.Join(filepath.Dir(event.FilePath), "output.txt")
 	output, err := os.ReadFile(outputFile)
@@ -165,15 +445,115 @@ func (o *Orchestrator) handleTaskCompleted(event workflow.FileEvent) error {
 		return fmt.Errorf("failed to read output: %w", err)
 	}
 
+	// Output breaking the task's format or schema fails the task rather
+	// than reaching downstream prompts
+	if task := o.state.GetTask(event.AgentID); task != nil {
+		normalized, err := task.CheckOutput(string(output))
+		if err != nil {
+			if err := o.state.FailTask(event.AgentID, err.Error()); err != nil {
+				return fmt.Errorf("failed to fail task: %w", err)
+			}
+			fmt.Printf("[%s] Task failed: %s: %v\n", time.Now().Format("15:04:05"), event.AgentID, err)
+			return nil
+		}
+		output = []byte(normalized)
+
+		// Verification commands can take minutes, so they run concurrently
+		// and the task completes once its command passes
+		if task.Verify != nil {
+			o.handlers.Add(1)
+			go func() {
+				defer o.handlers.Done()
+				if o.VerifyCompletion(ctx, event.AgentID, true) != nil {
+					return
+				}
+				if err := o.acceptCompletion(ctx, event, output); err != nil {
+					fmt.Printf("Error handling event: %v\n", err)
+				}
+			}()
+			return nil
+		}
+	}
+
+	return o.acceptCompletion(ctx, event, output)
+}
+
+// acceptCompletion completes a task with its checked output, unless a
+// post_complete lifecycle hook rejects it
+func (o *Orchestrator) acceptCompletion(ctx context.Context, event workflow.FileEvent, output []byte) error {
+	// post_complete lifecycle hooks may reject the output
+	result := o.RunLifecycleHooks(ctx, workflow.HookPayload{
+		Point:    workflow.LifecyclePostComplete,
+		Task:     event.AgentID,
+		AgentDir: filepath.Dir(event.FilePath),
+		Output:   o.state.Redact(string(output)),
+		Criteria: o.completionCriteria(event.AgentID),
+	})
+	if result.Reject != "" {
+		reason := "post_complete hook rejected the output: " + result.Reject
+		if err := o.state.FailTask(event.AgentID, reason); err != nil {
+			return fmt.Errorf("failed to fail task: %w", err)
+		}
+		fmt.Printf("[%s] Task failed: %s: %s\n", time.Now().Format("15:04:05"), event.AgentID, reason)
+		return nil
+	}
+
+	// The files changed, test outcome and notes the agent reported
+	// come with the output
+	completion, err := workflow.ReadCompletion(filepath.Dir(event.FilePath))
+	if err != nil {
+		fmt.Printf("[%s] Ignoring completion metadata of %s: %v\n", time.Now().Format("15:04:05"), event.AgentID, err)
+	}
+	if err := o.state.SetCompletion(event.AgentID, completion); err != nil {
+		return fmt.Errorf("failed to record completion metadata: %w", err)
+	}
+
 	// Mark task as completed
 	if err := o.state.CompleteTask(event.AgentID, string(output)); err != nil {
 		return fmt.Errorf("failed to complete task: %w", err)
 	}
 
-	fmt.Printf("[%s] Task completed: %s\n", time.Now().Format("15:04:05"), event.AgentID)
+	switch agent := o.state.GetAgent(event.AgentID); {
+	case agent == nil:
+		fmt.Printf("[%s] Task repeating: %s (output does not meet repeat_until yet)\n", time.Now().Format("15:04:05"), event.AgentID)
+	case agent.Status == workflow.TaskStatusFailed:
+		fmt.Printf("[%s] Task failed: %s: %s\n", time.Now().Format("15:04:05"), event.AgentID, agent.Error)
+	default:
+		fmt.Printf("[%s] Task completed: %s\n", time.Now().Format("15:04:05"), event.AgentID)
+	}
 
-	// Spawn dependent tasks
-	return o.spawnReadyAgents()
+	// Dependent tasks are spawned when the completion event is scheduled
+	return nil
+}
+
+// handleTaskFailed handles an agent giving up on its task
+func (o *Orchestrator) handleTaskFailed(event workflow.FileEvent) error {
+	// A task already finished over the API stays as it is, whatever its
+	// agent process leaves behind on exit
+	if agent := o.state.GetAgent(event.AgentID); agent != nil && agent.Status != workflow.TaskStatusRunning {
+		return nil
+	}
+
+	errorFile := filepath.Join(filepath.Dir(event.FilePath), "error.txt")
+	message, err := os.ReadFile(errorFile)
+	if err != nil {
+		message = []byte("agent reported failure")
+	}
+
+	reason := o.state.Redact(strings.TrimSpace(string(message)))
+
+	// A crashed agent process is restarted while its task has restarts left
+	if crashed(filepath.Dir(event.FilePath)) {
+		o.restartCrashed(event.AgentID, reason)
+		return nil
+	}
+
+	if err := o.state.FailTask(event.AgentID, reason); err != nil {
+		return fmt.Errorf("failed to fail task: %w", err)
+	}
+
+	o.logFailure(event.AgentID)
+	return nil
 }
 
 // handleFollowUpAnswered handles a follow-up answer from an agent
@@ -184,13 +564,14 @@ func (o *Orchestrator) handleFollowUpAnswered(event workflow.FileEvent) error {
 		return fmt.Errorf("failed to read follow-up answer: %w", err)
 	}
 
-	fmt.Printf("[%s] Follow-up answer from %s: %s\n", time.Now().Format("15:04:05"), event.AgentID, string(answer))
+	fmt.Printf("[%s] Follow-up answer from %s: %s\n", time.Now().Format("15:04:05"), event.AgentID, o.state.Redact(string(answer)))
 
 	return nil
 }
 
-// formulateAnswer generates an answer based on the plan and context
-func (o *Orchestrator) formulateAnswer(agentID, question string) string {
+// formulateAnswer generates an answer based on the plan and context. For a
+// reply, the earlier exchanges of its thread are part of the context.
+func (o *Orchestrator) formulateAnswer(agentID, question string, qNum int) string {
 	// Get the task
 	task := o.state.GetTask(agentID)
 	if task == nil {
@@ -209,11 +590,22 @@ func (o *Orchestrator) formulateAnswer(agentID, question string) string {
 	// - Previous Q&A history (agent.Questions)
 	// - Overall workflow context
 
+	history := ""
+	if thread := o.state.GetThread(agentID, qNum); thread != nil && len(thread.Replies) > 0 {
+		history = "\nThread so far:\n"
+		for _, earlier := range append([]workflow.Question{*thread}, thread.Replies...) {
+			if earlier.ID == qNum {
+				break
+			}
+			history += fmt.Sprintf("Q%d: %s\nA%d: %s\n", earlier.ID, earlier.Text, earlier.ID, earlier.Answer)
+		}
+	}
+
 	// For now, return a placeholder that Claude A will see and can respond to
-	return fmt.Sprintf(`[ORCHESTRATOR NEEDS TO FORMULATE ANSWER]
+	return fmt.Sprintf(pendingAnswer+`
 
 Question from agent '%s': %s
-
+%s
 Context:
 - Task: %s
 - Task Description: %s
@@ -223,27 +615,102 @@ Context:
 Please formulate an answer based on the plan and context.`,
 		agentID,
 		question,
+		history,
 		task.ID,
-		task.Description,
+		o.state.Workflow.InterpolateParams(task.Description),
 		len(agent.Questions))
 }
 
-// spawnReadyAgents spawns agents for tasks that are ready
-func (o *Orchestrator) spawnReadyAgents() error {
+// spawnReadyAgents spawns agents for tasks that are ready, as far as the
+// workflow's and each group's max_parallel allow. Tasks left waiting are
+// spawned when running agents finish.
+func (o *Orchestrator) spawnReadyAgents(ctx context.Context) error {
+	// Dependents see the artifacts of the tasks they wait for
+	o.collectArtifacts()
+
 	readyTasks := o.state.GetReadyTasks()
+	wf := o.state.Workflow
+
+	// Count the running agents, in total and by group
+	active := o.state.GetActiveAgents()
+	running := len(active)
+	runningByGroup := make(map[string]int)
+	for _, agent := range active {
+		if task := o.state.GetTask(agent.TaskID); task != nil && task.Group != "" {
+			runningByGroup[task.Group]++
+		}
+	}
 
 	for _, task := range readyTasks {
-		if err := o.spawnAgent(task); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		// Aggregate tasks, and matrix joins without a prompt, need no agent
+		if task.IsAggregate() {
+			if err := o.aggregate(task); err != nil {
+				fmt.Printf("Failed to join outputs for task %s: %v\n", task.ID, err)
+				continue
+			}
+			return o.spawnReadyAgents(ctx)
+		}
+
+		if wf.MaxParallel > 0 && running >= wf.MaxParallel {
+			break
+		}
+		if limit := wf.GroupLimit(task.Group); limit > 0 && runningByGroup[task.Group] >= limit {
+			continue
+		}
+
+		// Tasks that keep failing wait longer and longer to run again
+		if retryAt := o.state.RetryAt(task.ID); time.Now().Before(retryAt) {
+			o.wakeUpAt(retryAt)
+			continue
+		}
+
+		// Tasks wait for the summaries of long dependency outputs
+		if !o.awaitSummaries(ctx, task) {
+			continue
+		}
+
+		// Workflow tasks run a child session instead of an agent
+		spawn := o.spawnAgent
+		if task.IsSubWorkflow() {
+			spawn = o.startSubWorkflow
+		}
+		if err := spawn(ctx, task); err != nil {
 			fmt.Printf("Failed to spawn agent for task %s: %v\n", task.ID, err)
 			continue
 		}
+		running++
+		if task.Group != "" {
+			runningByGroup[task.Group]++
+		}
 	}
 
 	return nil
 }
 
+// aggregate completes an aggregate or matrix join task with its
+// dependencies' outputs
+func (o *Orchestrator) aggregate(task workflow.Task) error {
+	output, err := workflow.AggregateOutputs(task.Format, task.DependsOn, o.state.GetOutputs())
+	if err != nil {
+		return err
+	}
+	if err := o.state.AddAgent(task.ID, ""); err != nil {
+		return err
+	}
+	if err := o.state.CompleteTask(task.ID, output); err != nil {
+		return err
+	}
+
+	fmt.Printf("[%s] Task completed: %s (joined %d outputs)\n", time.Now().Format("15:04:05"), task.ID, len(task.DependsOn))
+	return nil
+}
+
 // spawnAgent spawns an agent for a task
-func (o *Orchestrator) spawnAgent(task workflow.Task) error {
+func (o *Orchestrator) spawnAgent(ctx context.Context, task workflow.Task) error {
 	// Create agent directory
 	agentDir := filepath.Join(o.swarmDir, "agents", fmt.Sprintf("agent-%s", task.ID))
 	if err := os.MkdirAll(agentDir, 0755); err != nil {
@@ -257,36 +724,81 @@ func (o *Orchestrator) spawnAgent(task workflow.Task) error {
 		}
 	}
 
+	// Clear the markers of an earlier run, such as the previous iteration
+	// of a repeated task, so the new run's are detected as created
+	for _, name := range []string{"COMPLETE", "FAILED", "output.txt", workflow.CompletionFile, "error.txt", "status.txt"} {
+		if err := os.Remove(filepath.Join(agentDir, name)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to clear %s: %w", name, err)
+		}
+	}
+
 	// Watch the agent directory
 	if err := o.monitor.WatchAgentDir(agentDir); err != nil {
 		return fmt.Errorf("failed to watch agent directory: %w", err)
 	}
 
-	// Generate context file
-	context := o.generateAgentContext(task)
+	// Generate context file, after why the previous attempt crashed or
+	// failed verification and what it saved
+	context := o.crashNote(task) + o.verificationNote(task) + o.checkpointNote(task) + o.generateAgentContext(task)
 	contextFile := filepath.Join(agentDir, "context.txt")
 	if err := os.WriteFile(contextFile, []byte(context), 0644); err != nil {
 		return fmt.Errorf("failed to write context file: %w", err)
 	}
 
+	// Publish the output schema for swarm-agent complete to check
+	if task.OutputSchema != nil {
+		schema, err := json.MarshalIndent(task.OutputSchema, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode output schema: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(agentDir, workflow.OutputSchemaFile), schema, 0644); err != nil {
+			return fmt.Errorf("failed to write output schema: %w", err)
+		}
+	}
+	if task.OutputFormat != "" {
+		if err := os.WriteFile(filepath.Join(agentDir, workflow.OutputFormatFile), []byte(task.OutputFormat), 0644); err != nil {
+			return fmt.Errorf("failed to write output format: %w", err)
+		}
+	}
+	if err := o.writeAcceptanceCriteria(task, agentDir); err != nil {
+		return err
+	}
+
 	// Generate Claude settings file for pre-approved permissions
-	if err := o.generateAgentSettings(agentDir); err != nil {
+	if err := o.generateAgentSettings(task, agentDir); err != nil {
 		return fmt.Errorf("failed to generate agent settings: %w", err)
 	}
 
+	// Generate env.sh for agents to source
+	if err := o.generateAgentEnv(task.ID, agentDir); err != nil {
+		return err
+	}
+
 	// Add agent to state
 	if err := o.state.AddAgent(task.ID, agentDir); err != nil {
 		return fmt.Errorf("failed to add agent to state: %w", err)
 	}
 
+	// pre_spawn lifecycle hooks may veto the agent
+	result := o.RunLifecycleHooks(ctx, workflow.HookPayload{
+		Point:    workflow.LifecyclePreSpawn,
+		Task:     task.ID,
+		AgentDir: agentDir,
+		Prompt:   o.state.Redact(o.interpolateTaskPrompt(task)),
+	})
+	if result.Reject != "" {
+		reason := "pre_spawn hook rejected the task: " + result.Reject
+		o.state.FailTask(task.ID, reason)
+		return fmt.Errorf("%s", reason)
+	}
+
 	// Generate spawn prompt
 	prompt := o.generateSpawnPrompt(task, agentDir)
 
-	fmt.Printf("\n[SPAWN_AGENT] %s\n", task.ID)
-	fmt.Printf("Type: %s\n", task.AgentType)
-	fmt.Printf("Directory: %s\n", agentDir)
-	fmt.Printf("\nPrompt:\n%s\n", prompt)
-	fmt.Printf("\n[ORCHESTRATOR] Please use the Task tool to spawn this agent with the above prompt.\n\n")
+	if err := o.spawner.Spawn(ctx, task, agentDir, prompt); err != nil {
+		o.state.FailTask(task.ID, fmt.Sprintf("failed to spawn agent: %v", err))
+		return fmt.Errorf("failed to spawn agent: %w", err)
+	}
 
 	return nil
 }
@@ -295,16 +807,117 @@ func (o *Orchestrator) spawnAgent(task workflow.Task) error {
 func (o *Orchestrator) generateAgentContext(task workflow.Task) string {
 	// Get outputs from dependencies
 	outputs := o.state.GetOutputs()
+	collected := o.state.GetArtifacts()
 	previousOutputs := ""
 
 	for _, depID := range task.DependsOn {
+		agent := o.state.GetAgent(depID)
+		if agent != nil && agent.Status == workflow.TaskStatusSkipped {
+			previousOutputs += fmt.Sprintf("## Skipped dependency: %s\nAn operator skipped this task; it produced no output.\n\n", depID)
+			continue
+		}
 		if output, exists := outputs[depID]; exists {
-			previousOutputs += fmt.Sprintf("## Output from task: %s\n%s\n\n", depID, output)
+			previousOutputs += o.dependencyOutput(task, depID, output)
+			previousOutputs += dependencyCompletion(depID, agent)
+			previousOutputs += o.dependencyCriteria(depID)
+		}
+		// Under the ignore failure policy, dependents run after a failure
+		if agent != nil && agent.Status.IsFailure() {
+			previousOutputs += fmt.Sprintf("## Failed dependency: %s\nThis task failed and produced no output.\nError: %s\n\n", depID, agent.Error)
+		}
+		if paths := collected[depID]; len(paths) > 0 {
+			previousOutputs += fmt.Sprintf("## Artifacts from task: %s\n%s\n\n", depID, strings.Join(paths, "\n"))
+		}
+	}
+
+	// Failure handlers learn why the tasks they handle failed
+	for _, failedID := range task.FailureOf {
+		if agent := o.state.GetAgent(failedID); agent != nil && agent.Status.IsFailure() {
+			previousOutputs += fmt.Sprintf("## Failed task: %s\nError: %s\n\n", failedID, agent.Error)
+		}
+	}
+
+	// Repeated tasks pick up where their previous iteration left off
+	if task.RepeatUntil != nil {
+		iterations := o.state.GetIterations(task.ID)
+		previousOutputs += fmt.Sprintf("## Iteration %d of at most %d\nThis task repeats until its completion output %s.\n\n",
+			len(iterations)+1, task.RepeatUntil.Limit(), task.RepeatUntil)
+		if len(iterations) > 0 {
+			previousOutputs += fmt.Sprintf("## Output of iteration %d\n%s\n\n", len(iterations), iterations[len(iterations)-1])
 		}
 	}
 
 	// Interpolate prompt with dependency outputs
-	interpolatedPrompt := o.parser.InterpolatePrompt(task.Prompt, outputs)
+	interpolatedPrompt := o.interpolateTaskPrompt(task)
+
+	readOnlyNote := ""
+	if o.state.IsReadOnly() {
+		readOnlyNote = `
+## READ-ONLY RUN
+This workflow runs in read-only mode. Writes and edits are not applied;
+they are recorded as proposed changes for the operator to review, and
+reading a file you changed returns your proposed content. Bash commands
+with side effects (redirections, sed -i, git commit, go build, ...) are
+rejected. Analyze, then propose your changes.
+`
+	}
+
+	outputNote := ""
+	if task.OutputFormat != "" && task.OutputSchema == nil {
+		outputNote = fmt.Sprintf(`
+## OUTPUT FORMAT
+%s
+Other tasks consume it, so completions in another format are rejected:
+fix your output and complete again.
+`, workflow.DescribeOutputFormat(task.OutputFormat))
+	}
+	if task.OutputSchema != nil {
+		schema, _ := json.MarshalIndent(task.OutputSchema, "", "  ")
+		outputNote = fmt.Sprintf(`
+## OUTPUT FORMAT
+Your completion output must be a JSON document matching this JSON schema.
+Other tasks consume it, so completions that do not match are rejected:
+fix your output and complete again.
+
+%s
+`, schema)
+	}
+
+	secretsNote := ""
+	if names := o.state.Workflow.SecretNames(); len(names) > 0 {
+		secretsNote = fmt.Sprintf(`
+## SECRETS
+The secrets %s are available to swarm-agent bash commands as
+{secrets.NAME}. The orchestrator substitutes their values when the command
+runs and redacts them from its output; you never see them. Do not try to
+print or store them.
+`, strings.Join(names, ", "))
+	}
+
+	heartbeatNote := ""
+	if timeout := o.state.Workflow.HeartbeatTimeoutDuration(); timeout > 0 {
+		heartbeatNote = fmt.Sprintf(`
+## HEARTBEATS
+Agents that show no sign of life for %s are marked stale, and after
+twice that presumed dead and failed. Every swarm-agent command counts.
+During long work without any, keep "swarm-agent heartbeat --every 30s &"
+running, or POST {"agent_id"} to $SWARM_API_URL/api/heartbeat now and then.
+`, timeout)
+	}
+
+	budgetNote := ""
+	if o.state.Workflow.Budget != nil {
+		budgetNote = `
+## BUDGET
+This run has a token budget. Report what you spend as you go, with
+"swarm-agent report-usage --input N --output N" (or --claude-json with
+the output of claude --output-format json), or POST {"agent_id",
+"input_tokens","output_tokens","cost_usd"} to $SWARM_API_URL/api/usage.
+`
+	}
+
+	agentDir := filepath.Join(o.swarmDir, "agents", fmt.Sprintf("agent-%s", task.ID))
+	envFile := filepath.Join(agentDir, "env.sh")
 
 	return fmt.Sprintf(`# SWARM AGENT - Task: %s
 
@@ -317,16 +930,22 @@ You are part of a Claude Swarm orchestration system.
 
 ## Your Task
 %s
-
+%s
 ## Original Plan
 %s
 
 ## Context from Previous Tasks
 %s
-
+%s
 ## IMPORTANT: Swarm Protocol
 
-**HTTP API Endpoint**: http://localhost:8080
+**HTTP API Endpoint**: %s
+
+**Environment**: %s sets SWARM_API_URL, SWARM_API_TOKEN,
+SWARM_AGENT_DIR and puts swarm-agent on PATH. Shell state may not persist
+between commands, so prefix commands that need it with:
+
+   source %s && <command>
 
 You have TWO ways to communicate with the orchestrator:
 
@@ -352,54 +971,113 @@ You have TWO ways to communicate with the orchestrator:
    Use curl to make HTTP requests for writes:
 
    # Write a file
-   curl -X POST http://localhost:8080/api/file/write \
-     -H "Content-Type: application/json" \
-     -d '{"path":"/path/to/file","content":"file content here"}'
+   curl -X POST $SWARM_API_URL/api/file/write \
+     -H "Content-Type: application/json" -H "Authorization: Bearer $SWARM_API_TOKEN" \
+     -d '{"agent_id":"%s","path":"/path/to/file","content":"file content here"}'
 
    # Edit a file (replace text)
-   curl -X POST http://localhost:8080/api/file/edit \
-     -H "Content-Type: application/json" \
-     -d '{"path":"/path/to/file","old_string":"old","new_string":"new"}'
+   curl -X POST $SWARM_API_URL/api/file/edit \
+     -H "Content-Type: application/json" -H "Authorization: Bearer $SWARM_API_TOKEN" \
+     -d '{"agent_id":"%s","path":"/path/to/file","old_string":"old","new_string":"new"}'
 
    # Execute bash command server-side
-   curl -X POST http://localhost:8080/api/bash \
-     -H "Content-Type: application/json" \
-     -d '{"command":"ls -la","working_dir":"/some/dir"}'
+   curl -X POST $SWARM_API_URL/api/bash \
+     -H "Content-Type: application/json" -H "Authorization: Bearer $SWARM_API_TOKEN" \
+     -d '{"agent_id":"%s","command":"ls -la","working_dir":"/some/dir"}'
 
 3. **Ask Questions**:
-   curl -X POST http://localhost:8080/api/question \
-     -H "Content-Type: application/json" \
+   curl -X POST $SWARM_API_URL/api/question \
+     -H "Content-Type: application/json" -H "Authorization: Bearer $SWARM_API_TOKEN" \
      -d '{"agent_id":"%s","question":"Your question here"}'
+   To follow up on an answer, add "thread": <question number>, or use
+   swarm-agent ask --thread <number> "..."
 
 4. **Complete Task**:
-   curl -X POST http://localhost:8080/api/complete \
-     -H "Content-Type: application/json" \
+   curl -X POST $SWARM_API_URL/api/complete \
+     -H "Content-Type: application/json" -H "Authorization: Bearer $SWARM_API_TOKEN" \
      -d '{"agent_id":"%s","output":"Your results here"}'
 
+5. **Give Up** (only if the task cannot be completed):
+   curl -X POST $SWARM_API_URL/api/fail \
+     -H "Content-Type: application/json" -H "Authorization: Bearer $SWARM_API_TOKEN" \
+     -d '{"agent_id":"%s","error":"Why the task cannot be completed"}'
+
 ## Instructions
 1. Use direct bash commands (cat, grep, ls, etc.) for reading - pre-approved!
-2. Use HTTP API (curl) for all write operations - no permission prompts!
+2. Use HTTP API (curl, after sourcing env.sh) for all write operations - no permission prompts!
 3. Ask questions via API if you need guidance
 4. Report completion via API when done
 5. Be thorough and follow the plan's intent
-
+6. Writes and bash commands count against your quotas; if an operation is
+   rejected with "quota exceeded", wait for the operator to approve more
+7. Other agents may change the files you work on. Add "expected_checksum"
+   (the sha256sum of the file as you read it) to writes and edits; if the
+   file changed, the request fails with code "conflict" and returns the
+   current content and checksum, so you can redo your change on top of it
+8. To coordinate on files shared with other agents, take an advisory lock
+   first with "swarm-agent lock <path>" (or POST {"agent_id","path"} to
+   $SWARM_API_URL/api/lock) and release it with "swarm-agent unlock <path>".
+   Locks expire after 5 minutes unless renewed and are released when you
+   complete; "swarm-agent locks" lists who holds what
+9. To find where a function or type is defined or used, prefer
+   "swarm-agent symbols <Name>" (add --refs for references) over grep
+10. If your task is too big for one agent, break it up: write the subtasks
+   as a YAML "tasks:" list, in the workflow's format, and add them with
+   "swarm-agent add-tasks tasks.yaml". They may depend on any task,
+   including yours; tasks waiting for yours also wait for them
+11. To write multi-line content with quotes, pipe it in verbatim with
+   "swarm-agent file-write --stdin <path> <<'EOF'", or stage it in a
+   local file and send it with "swarm-agent file-write --from-file <tmp> <path>"
+12. To parse swarm-agent's output, add --json: each command then prints one
+   JSON object with "status", "data", "error" and "code"
+13. On long tasks, report progress as you go with
+   "swarm-agent progress --percent 30 'migrated 3/10 modules'" (or POST
+   {"agent_id","text","percent"} to $SWARM_API_URL/api/progress); the
+   operator sees it on your card
+14. Save partial results as you reach milestones with
+   "swarm-agent checkpoint --output '...'" (or POST {"agent_id","output"}
+   to $SWARM_API_URL/api/checkpoint). If you crash or the task is retried,
+   the next agent starts from your last checkpoint
+15. Your swarm-agent operations are logged to agent.log in your agent
+   directory, which the operator watches; add notes on your decisions with
+   "swarm-agent log 'keeping the old column names'"
+16. When you complete, report what you did alongside your output with
+   "swarm-agent complete --output '...' --files-changed a.go,b.go
+   --tests-passed --notes '...'" (or "files_changed", "tests_passed" and
+   "notes" in /api/complete); tasks depending on yours see it
+17. Dependency outputs above are as they were when you started, and long
+   ones are summarized. "swarm-agent deps list" lists the tasks upstream
+   of yours and "swarm-agent deps get <task>" prints one's output in full,
+   as it is now
+%s
 Begin your task now.
 `,
 		task.ID,
 		o.state.SessionID,
-		filepath.Join(o.swarmDir, "agents", fmt.Sprintf("agent-%s", task.ID)),
+		agentDir,
 		o.swarmDir,
 		interpolatedPrompt,
+		o.roleSection(task)+o.briefSection(task),
 		o.state.Plan,
 		previousOutputs,
+		o.repoMapSection()+o.memorySection(),
+		o.apiURL,
+		envFile,
+		envFile,
+		task.ID, // For write API
+		task.ID, // For edit API
+		task.ID, // For bash API
 		task.ID, // For question API
 		task.ID, // For complete API
+		task.ID, // For fail API
+		readOnlyNote+outputNote+secretsNote+heartbeatNote+budgetNote,
 	)
 }
 
 // generateSpawnPrompt generates the prompt for spawning an agent via Task tool
 func (o *Orchestrator) generateSpawnPrompt(task workflow.Task, agentDir string) string {
 	contextFile := filepath.Join(agentDir, "context.txt")
+	envFile := filepath.Join(agentDir, "env.sh")
 
 	return fmt.Sprintf(`You are Agent '%s' in a Claude Swarm orchestration system.
 
@@ -410,34 +1088,35 @@ Your working directory: %s
 
 **IMPORTANT - NO PERMISSION PROMPTS:**
 - You have pre-approved permissions for READ operations (cat, grep, ls, etc.)
-- For WRITE operations, use HTTP API: http://localhost:8080
+- For WRITE operations, use HTTP API: %s
 - See context.txt for full API documentation and examples
 
-Environment variables:
-export SWARM_SESSION_ID=%s
-export SWARM_AGENT_DIR=%s
-export SWARM_API_URL=http://localhost:8080
+Environment (session, directories, API URL and token, swarm-agent on PATH):
+source %s
 
 Quick reference:
 # Read files directly (pre-approved)
 cat %s
 
 # Write via API (no permission prompts)
-curl -X POST $SWARM_API_URL/api/file/write -H "Content-Type: application/json" -d '{"path":"...","content":"..."}'
+source %s && curl -X POST $SWARM_API_URL/api/file/write -H "Content-Type: application/json" -H "Authorization: Bearer $SWARM_API_TOKEN" -d '{"agent_id":"%s","path":"...","content":"..."}'
 
 Begin your task now by reading the context file and following the instructions.
 `,
 		task.ID,
 		contextFile,
 		agentDir,
-		o.state.SessionID,
-		agentDir,
+		o.apiURL,
+		envFile,
 		contextFile,
+		envFile,
+		task.ID,
 	)
 }
 
-// generateAgentSettings generates .claude/settings.local.json for agent permissions
-func (o *Orchestrator) generateAgentSettings(agentDir string) error {
+// generateAgentSettings generates .claude/settings.local.json for agent permissions,
+// with the tools and model of the task's agent type
+func (o *Orchestrator) generateAgentSettings(task workflow.Task, agentDir string) error {
 	claudeDir := filepath.Join(agentDir, ".claude")
 	if err := os.MkdirAll(claudeDir, 0755); err != nil {
 		return fmt.Errorf("failed to create .claude directory: %w", err)
@@ -496,6 +1175,10 @@ func (o *Orchestrator) generateAgentSettings(agentDir string) error {
 			"Bash(pwd:*)",
 			"Bash(cd:*)",
 
+			// Agent environment and helper CLI
+			fmt.Sprintf("Bash(source %s/env.sh:*)", absAgentDir),
+			"Bash(swarm-agent:*)",
+
 			// Read access to agent directory
 			fmt.Sprintf("Read(%s/**/*)", absAgentDir),
 
@@ -503,6 +1186,12 @@ func (o *Orchestrator) generateAgentSettings(agentDir string) error {
 			fmt.Sprintf("Read(%s/**/*)", absSwarmDir),
 		},
 	}
+	if agentType, ok := o.agentTypes.Get(task.AgentType); ok {
+		settings["dangerouslySkipPermissions"] = append(settings["dangerouslySkipPermissions"].([]string), agentType.Tools...)
+		if agentType.Model != "" {
+			settings["model"] = agentType.Model
+		}
+	}
 
 	data, err := json.MarshalIndent(settings, "", "  ")
 	if err != nil {
@@ -529,3 +1218,15 @@ func (o *Orchestrator) extractQuestionNumber(filename string) int {
 	}
 	return 1
 }
+
+// extractThread returns the thread a reply continues, from q-N-re-T.txt,
+// or 0 for a new question
+func (o *Orchestrator) extractThread(filename string) int {
+	parts := strings.Split(strings.TrimSuffix(filename, ".txt"), "-")
+	if len(parts) == 4 && parts[2] == "re" {
+		var thread int
+		fmt.Sscanf(parts[3], "%d", &thread)
+		return thread
+	}
+	return 0
+}
@@ -0,0 +1,26 @@
+package orchestrator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// checkpointNote hands an agent the partial output the previous attempt at
+// its task saved, so it carries on from there instead of starting from
+// scratch
+func (o *Orchestrator) checkpointNote(task workflow.Task) string {
+	checkpoint, exists := o.state.GetOutputCheckpoint(task.ID)
+	if !exists {
+		return ""
+	}
+
+	var note strings.Builder
+	note.WriteString("## CHECKPOINT FROM THE PREVIOUS ATTEMPT\n")
+	fmt.Fprintf(&note, "The previous agent on this task saved this partial output at %s, before it stopped without completing:\n\n",
+		checkpoint.SavedAt.Format("15:04:05"))
+	fmt.Fprintf(&note, "%s\n\n", strings.TrimSpace(checkpoint.Output))
+	note.WriteString("Carry on from it rather than starting from scratch: check that the work it describes is in place, then do what is left. Include what it covers in your own output when you complete.\n\n")
+	return note.String()
+}
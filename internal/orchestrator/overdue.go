@@ -0,0 +1,56 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// overdueInterval is how often running tasks are checked against their
+// expected durations
+const overdueInterval = 15 * time.Second
+
+// startOverdueWatch reports tasks running far beyond their expected
+// duration, once per run, so stalls show up without hard timeouts. The
+// returned function stops it.
+func (o *Orchestrator) startOverdueWatch(ctx context.Context) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+
+		ticker := time.NewTicker(overdueInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			o.checkOverdue()
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-finished
+	}
+}
+
+// checkOverdue marks the running tasks that are overdue
+func (o *Orchestrator) checkOverdue() {
+	for _, agent := range o.state.GetActiveAgents() {
+		task := o.state.GetTask(agent.TaskID)
+		if task == nil {
+			continue
+		}
+		elapsed := time.Since(agent.StartedAt)
+		if !task.Overdue(elapsed) || !o.state.MarkOverdue(task.ID) {
+			continue
+		}
+		fmt.Printf("[%s] Task overdue: %s (running %s, expected %s)\n",
+			time.Now().Format("15:04:05"), task.ID, elapsed.Round(time.Second), task.Expected())
+	}
+}
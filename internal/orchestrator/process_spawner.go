@@ -0,0 +1,180 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// DefaultAgentCommand runs an agent non-interactively with the claude CLI,
+// in its agent directory so it picks up the generated settings
+const DefaultAgentCommand = "claude -p {prompt}"
+
+// Files a spawned agent process leaves in its agent directory
+const (
+	agentLogFile = workflow.AgentLogFile // The process's stdout and stderr
+	agentPIDFile = "agent.pid"
+)
+
+// agentStopGrace is how long an agent process has to exit after being
+// interrupted before it is killed
+const agentStopGrace = 10 * time.Second
+
+// ProcessSpawner launches each agent as a child process from a command
+// template, run with bash in the agent's directory. The process's output
+// goes to agent.log; an agent that exits without completing or failing
+// its task has crashed, and is restarted under the task's max_restarts.
+type ProcessSpawner struct {
+	command string
+
+	mu        sync.Mutex
+	processes map[string]int           // PIDs of running agents by task ID
+	exits     map[string]chan struct{} // Closed once an agent's exit is handled
+}
+
+// NewProcessSpawner creates a spawner running a command template, where
+// {prompt}, {task}, {agent_type}, {dir} and {settings} are replaced by
+// shell-quoted values. An empty template uses DefaultAgentCommand.
+func NewProcessSpawner(command string) *ProcessSpawner {
+	if command == "" {
+		command = DefaultAgentCommand
+	}
+	return &ProcessSpawner{command: command, processes: make(map[string]int), exits: make(map[string]chan struct{})}
+}
+
+// Command returns the shell command that spawns an agent
+func (s *ProcessSpawner) Command(task workflow.Task, agentDir, prompt string) string {
+	replacer := strings.NewReplacer(
+		"{prompt}", shellQuote(prompt),
+		"{task}", shellQuote(task.ID),
+		"{agent_type}", shellQuote(task.AgentType),
+		"{dir}", shellQuote(agentDir),
+		"{settings}", shellQuote(filepath.Join(agentDir, ".claude", "settings.local.json")),
+	)
+	return replacer.Replace(s.command)
+}
+
+// Spawn starts the agent process for a task
+func (s *ProcessSpawner) Spawn(ctx context.Context, task workflow.Task, agentDir, prompt string) error {
+	logFile, err := os.OpenFile(filepath.Join(agentDir, agentLogFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open agent log: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, "bash", "-c", s.Command(task, agentDir, prompt))
+	cmd.Dir = agentDir
+	cmd.Env = append(os.Environ(),
+		"SWARM_TASK="+task.ID,
+		"SWARM_AGENT_DIR="+agentDir,
+	)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	// Give agents a chance to exit cleanly when the orchestrator stops
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(os.Interrupt)
+	}
+	cmd.WaitDelay = agentStopGrace
+
+	if err := cmd.Start(); err != nil {
+		logFile.Close()
+		return fmt.Errorf("failed to start agent process: %w", err)
+	}
+
+	pid := cmd.Process.Pid
+	if err := os.WriteFile(filepath.Join(agentDir, agentPIDFile), []byte(strconv.Itoa(pid)), 0644); err != nil {
+		fmt.Printf("Failed to write PID of agent %s: %v\n", task.ID, err)
+	}
+	exit := make(chan struct{})
+	s.mu.Lock()
+	s.processes[task.ID] = pid
+	s.exits[task.ID] = exit
+	s.mu.Unlock()
+	fmt.Printf("[%s] Agent process started: %s (pid %d)\n", time.Now().Format("15:04:05"), task.ID, pid)
+
+	go func() {
+		defer close(exit)
+		defer logFile.Close()
+		err := cmd.Wait()
+
+		// A task running again, such as after failing verification, has
+		// a new agent whose exit counts instead
+		s.mu.Lock()
+		replaced := s.processes[task.ID] != pid
+		if !replaced {
+			delete(s.processes, task.ID)
+			delete(s.exits, task.ID)
+		}
+		s.mu.Unlock()
+
+		if ctx.Err() != nil || replaced {
+			return
+		}
+		s.exited(task, agentDir, err)
+	}()
+
+	return nil
+}
+
+// exited fails a task whose agent process exited without completing or
+// failing it, marking it crashed
+func (s *ProcessSpawner) exited(task workflow.Task, agentDir string, err error) {
+	for _, marker := range []string{"COMPLETE", "FAILED"} {
+		if isFile(filepath.Join(agentDir, marker)) {
+			return
+		}
+	}
+
+	reason := "agent process exited without completing the task"
+	if err != nil {
+		reason = fmt.Sprintf("agent process exited without completing the task: %v", err)
+	}
+	reason += fmt.Sprintf("; see %s", filepath.Join(agentDir, agentLogFile))
+
+	if err := os.WriteFile(filepath.Join(agentDir, "error.txt"), []byte(reason), 0644); err != nil {
+		fmt.Printf("Failed to write error of agent %s: %v\n", task.ID, err)
+		return
+	}
+	// The orchestrator restarts crashed agents rather than failing their tasks
+	if err := os.WriteFile(filepath.Join(agentDir, "status.txt"), []byte(crashedStatus), 0644); err != nil {
+		fmt.Printf("Failed to write status of agent %s: %v\n", task.ID, err)
+	}
+	if err := os.WriteFile(filepath.Join(agentDir, "FAILED"), []byte(""), 0644); err != nil {
+		fmt.Printf("Failed to create FAILED marker of agent %s: %v\n", task.ID, err)
+	}
+}
+
+// WaitExited waits, for at most timeout, until the exit of a task's agent
+// process has been handled
+func (s *ProcessSpawner) WaitExited(taskID string, timeout time.Duration) {
+	s.mu.Lock()
+	exit := s.exits[taskID]
+	s.mu.Unlock()
+	if exit == nil {
+		return
+	}
+
+	select {
+	case <-exit:
+	case <-time.After(timeout):
+	}
+}
+
+// PIDs returns the process IDs of the running agents by task ID
+func (s *ProcessSpawner) PIDs() map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pids := make(map[string]int, len(s.processes))
+	for taskID, pid := range s.processes {
+		pids[taskID] = pid
+	}
+	return pids
+}
@@ -0,0 +1,192 @@
+package orchestrator
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// RecordKind identifies what a recording entry captured
+type RecordKind string
+
+const (
+	RecordKindAnswer    RecordKind = "answer"
+	RecordKindOperation RecordKind = "operation"
+)
+
+// Recording is a single recorded orchestrator decision
+type Recording struct {
+	Kind     RecordKind         `json:"kind"`
+	AgentID  string             `json:"agent_id"`
+	Key      string             `json:"key"`
+	Answer   string             `json:"answer,omitempty"`
+	Response *workflow.Response `json:"response,omitempty"`
+	Time     time.Time          `json:"time"`
+}
+
+// Recorder appends orchestrator decisions to a JSON lines file
+type Recorder struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewRecorder creates a recorder writing to path
+func NewRecorder(path string) (*Recorder, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recording file: %w", err)
+	}
+
+	return &Recorder{file: file}, nil
+}
+
+// RecordAnswer records the answer given to an agent question
+func (r *Recorder) RecordAnswer(agentID, question, answer string) error {
+	return r.write(Recording{
+		Kind:    RecordKindAnswer,
+		AgentID: agentID,
+		Key:     question,
+		Answer:  answer,
+		Time:    time.Now(),
+	})
+}
+
+// RecordResponse records the response to an agent message
+func (r *Recorder) RecordResponse(agentID string, msg *workflow.Message, response workflow.Response) error {
+	return r.write(Recording{
+		Kind:     RecordKindOperation,
+		AgentID:  agentID,
+		Key:      operationKey(msg),
+		Response: &response,
+		Time:     time.Now(),
+	})
+}
+
+// Close closes the recording file
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.file.Close()
+}
+
+// write appends a recording entry
+func (r *Recorder) write(rec Recording) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal recording: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write recording: %w", err)
+	}
+
+	return nil
+}
+
+// Replayer substitutes recorded decisions for live ones
+type Replayer struct {
+	mu      sync.Mutex
+	entries map[string][]Recording
+}
+
+// LoadReplayer loads a recording file for replay
+func LoadReplayer(path string) (*Replayer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recording file: %w", err)
+	}
+	defer file.Close()
+
+	r := &Replayer{entries: make(map[string][]Recording)}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var rec Recording
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("failed to parse recording line %d: %w", line, err)
+		}
+
+		key := replayKey(rec.Kind, rec.AgentID, rec.Key)
+		r.entries[key] = append(r.entries[key], rec)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read recording file: %w", err)
+	}
+
+	return r, nil
+}
+
+// Answer returns the next recorded answer for an agent question
+func (r *Replayer) Answer(agentID, question string) (string, bool) {
+	rec, ok := r.next(replayKey(RecordKindAnswer, agentID, question))
+	if !ok {
+		return "", false
+	}
+	return rec.Answer, true
+}
+
+// Response returns the next recorded response for an agent message
+func (r *Replayer) Response(agentID string, msg *workflow.Message) (workflow.Response, bool) {
+	rec, ok := r.next(replayKey(RecordKindOperation, agentID, operationKey(msg)))
+	if !ok || rec.Response == nil {
+		return workflow.Response{}, false
+	}
+
+	response := *rec.Response
+	response.MessageID = msg.ID
+	response.Timestamp = time.Now()
+	return response, true
+}
+
+// next pops the next recording for a key, in recorded order
+func (r *Replayer) next(key string) (Recording, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	queue := r.entries[key]
+	if len(queue) == 0 {
+		return Recording{}, false
+	}
+
+	r.entries[key] = queue[1:]
+	return queue[0], true
+}
+
+// replayKey builds the lookup key for a recording
+func replayKey(kind RecordKind, agentID, key string) string {
+	return fmt.Sprintf("%s|%s|%s", kind, agentID, key)
+}
+
+// operationKey identifies an operation independently of its message ID,
+// so a replayed run matches the same request made at a different time
+func operationKey(msg *workflow.Message) string {
+	hash := sha256.New()
+	hash.Write([]byte(msg.Content))
+	for _, edit := range msg.Edits {
+		hash.Write([]byte(edit.OldString))
+		hash.Write([]byte{0})
+		hash.Write([]byte(edit.NewString))
+		hash.Write([]byte{0})
+	}
+
+	return fmt.Sprintf("%s:%s:%s:%s:%x", msg.Type, msg.Path, msg.WorkingDir, msg.Command, hash.Sum(nil)[:8])
+}
@@ -0,0 +1,74 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/aristath/claude-swarm/internal/symbols"
+)
+
+// RepoMapFile is the name of the repository map in the session directory
+const RepoMapFile = "repomap.md"
+
+// generateRepoMap maps the repository once per session, so that agents
+// start from an overview instead of each exploring the tree. Failures only
+// leave the map out of agent contexts.
+func (o *Orchestrator) generateRepoMap(ctx context.Context) {
+	config := o.state.Workflow.RepoMap
+	if config != nil && config.Disabled {
+		return
+	}
+
+	root, maxBytes := "", 0
+	if config != nil {
+		root, maxBytes = config.Root, config.MaxBytes
+	}
+	if root == "" {
+		cwd, err := os.Getwd()
+		if err != nil || !symbols.IsProject(cwd) {
+			// Not a project root; don't index whatever directory this is
+			return
+		}
+		root = cwd
+	}
+
+	// Reuse the map of a resumed session rather than rebuilding it
+	mapFile := filepath.Join(o.swarmDir, RepoMapFile)
+	if data, err := os.ReadFile(mapFile); err == nil {
+		o.repoMap = string(data)
+		return
+	}
+
+	ix, err := symbols.Open(root)
+	if err != nil {
+		fmt.Printf("Failed to map repository: %v\n", err)
+		return
+	}
+
+	repoMap, err := ix.Map(ctx, maxBytes)
+	if err != nil {
+		fmt.Printf("Failed to map repository: %v\n", err)
+		return
+	}
+
+	if err := os.WriteFile(mapFile, []byte(repoMap), 0644); err != nil {
+		fmt.Printf("Failed to write repository map: %v\n", err)
+	}
+	o.repoMap = repoMap
+}
+
+// repoMapSection formats the repository map for agent contexts
+func (o *Orchestrator) repoMapSection() string {
+	if o.repoMap == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(`## Repository Map
+An overview of the repository, generated for this session. Use it to go
+straight to the relevant files instead of exploring the tree.
+
+%s
+`, o.repoMap)
+}
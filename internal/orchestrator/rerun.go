@@ -0,0 +1,47 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/state"
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// Rerun runs a failed task again on behalf of an operator, with a fresh
+// agent directory
+func (o *Orchestrator) Rerun(operator, taskID string) error {
+	if err := RerunTask(o.swarmDir, o.state, taskID, operator); err != nil {
+		return err
+	}
+	fmt.Printf("[%s] Task %s will run again (by %s)\n", time.Now().Format("15:04:05"), taskID, operatorName(operator))
+	o.audit(operator, workflow.ControlRerun, taskID, "")
+	return nil
+}
+
+// Skip skips a pending or failed task on behalf of an operator; its
+// dependents run with an empty output in its place
+func (o *Orchestrator) Skip(operator, taskID string) error {
+	if err := o.state.SkipTask(taskID, operator); err != nil {
+		return err
+	}
+	fmt.Printf("[%s] Task skipped: %s (by %s)\n", time.Now().Format("15:04:05"), taskID, operatorName(operator))
+	o.audit(operator, workflow.ControlSkip, taskID, "")
+	return nil
+}
+
+// RerunTask resets a failed task in a session's state and wipes its agent
+// directory, so the task runs again from scratch. Stopped sessions rerun
+// it when resumed.
+func RerunTask(swarmDir string, swarmState *state.SwarmState, taskID, operator string) error {
+	if err := swarmState.RerunTask(taskID, operator); err != nil {
+		return err
+	}
+	agentDir := filepath.Join(swarmDir, "agents", fmt.Sprintf("agent-%s", taskID))
+	if err := os.RemoveAll(agentDir); err != nil {
+		return fmt.Errorf("failed to wipe agent directory: %w", err)
+	}
+	return nil
+}
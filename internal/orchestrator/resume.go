@@ -0,0 +1,115 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/aristath/claude-swarm/internal/state"
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// Reconciled says how a saved session state was brought up to date with
+// its agent directories
+type Reconciled struct {
+	Completed []string // Tasks whose agents completed after the last save
+	Failed    []string // Tasks whose agents failed after the last save
+	Restarted []string // Tasks whose agents were lost, to run again
+}
+
+// LoadSession loads an interrupted session's saved state and reconciles it
+// with its agent directories. Agents that completed or failed after the
+// state was last saved are recorded as such; agents that were still
+// running are forgotten, so their tasks run again when the session
+// resumes, as are the tasks of a cancelled run. A paused session resumes
+// unpaused.
+func LoadSession(swarmDir string) (*state.SwarmState, *Reconciled, error) {
+	swarmState, err := state.NewPersistence(swarmDir).Load()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load session state: %w", err)
+	}
+	if swarmState.Workflow == nil {
+		return nil, nil, fmt.Errorf("session state has no workflow")
+	}
+
+	// Outputs recorded below are redacted like live ones
+	secrets, err := swarmState.Workflow.LoadSecrets()
+	if err != nil {
+		return nil, nil, err
+	}
+	swarmState.SetSecrets(secrets)
+
+	// Cancelled tasks run again from scratch, whatever markers their
+	// interrupted agents left
+	reconciled := &Reconciled{Restarted: swarmState.Reopen()}
+	for _, task := range swarmState.GetTasks() {
+		if slices.Contains(reconciled.Restarted, task.ID) {
+			continue
+		}
+		agent := swarmState.GetAgent(task.ID)
+		if agent != nil && agent.Status != workflow.TaskStatusRunning {
+			continue
+		}
+		agentDir := filepath.Join(swarmDir, "agents", fmt.Sprintf("agent-%s", task.ID))
+
+		switch {
+		case isFile(filepath.Join(agentDir, "COMPLETE")):
+			if err := reconcileCompleted(swarmState, task, agentDir); err != nil {
+				return nil, nil, err
+			}
+			if agent := swarmState.GetAgent(task.ID); agent != nil && agent.Status.IsFailure() {
+				reconciled.Failed = append(reconciled.Failed, task.ID)
+			} else {
+				reconciled.Completed = append(reconciled.Completed, task.ID)
+			}
+
+		case isFile(filepath.Join(agentDir, "FAILED")):
+			if agent == nil {
+				if err := swarmState.AddAgent(task.ID, agentDir); err != nil {
+					return nil, nil, err
+				}
+			}
+			reason := "agent reported failure"
+			if message, err := os.ReadFile(filepath.Join(agentDir, "error.txt")); err == nil {
+				reason = strings.TrimSpace(string(message))
+			}
+			if err := swarmState.FailTask(task.ID, reason); err != nil {
+				return nil, nil, err
+			}
+			reconciled.Failed = append(reconciled.Failed, task.ID)
+
+		case agent != nil:
+			swarmState.ResetTask(task.ID)
+			reconciled.Restarted = append(reconciled.Restarted, task.ID)
+		}
+	}
+
+	return swarmState, reconciled, nil
+}
+
+// reconcileCompleted records a task its agent completed after the state
+// was last saved, checking its output as a live completion would
+func reconcileCompleted(swarmState *state.SwarmState, task workflow.Task, agentDir string) error {
+	if swarmState.GetAgent(task.ID) == nil {
+		if err := swarmState.AddAgent(task.ID, agentDir); err != nil {
+			return err
+		}
+	}
+
+	output, err := os.ReadFile(filepath.Join(agentDir, "output.txt"))
+	if err != nil {
+		return swarmState.FailTask(task.ID, fmt.Sprintf("failed to read output: %v", err))
+	}
+	normalized, err := task.CheckOutput(string(output))
+	if err != nil {
+		return swarmState.FailTask(task.ID, err.Error())
+	}
+	if completion, err := workflow.ReadCompletion(agentDir); err == nil {
+		if err := swarmState.SetCompletion(task.ID, completion); err != nil {
+			return err
+		}
+	}
+	return swarmState.CompleteTask(task.ID, normalized)
+}
@@ -0,0 +1,67 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/retention"
+)
+
+// retentionInterval is how often artifacts are checked against the
+// retention policy
+const retentionInterval = time.Minute
+
+// startRetention prunes agent artifacts according to the workflow's
+// retention policy, once at startup and then periodically. The returned
+// function stops it.
+func (o *Orchestrator) startRetention(ctx context.Context) func() {
+	cfg := o.state.Workflow.Retention
+	if cfg == nil || (cfg.MaxSizeMB == 0 && cfg.MaxAgeHours == 0) {
+		return func() {}
+	}
+
+	policy := retention.Policy{MaxBytes: cfg.MaxBytes(), MaxAge: cfg.MaxAge()}
+	ctx, cancel := context.WithCancel(ctx)
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+
+		ticker := time.NewTicker(retentionInterval)
+		defer ticker.Stop()
+
+		warned := false
+		for {
+			result, err := retention.Prune(o.swarmDir, policy, time.Now())
+			if err != nil {
+				fmt.Printf("Failed to prune artifacts: %v\n", err)
+			}
+			if result.Files > 0 {
+				fmt.Printf("[%s] Pruned %d artifact files (%s), %s left\n",
+					time.Now().Format("15:04:05"), result.Files,
+					retention.FormatBytes(result.Bytes), retention.FormatBytes(result.Remaining))
+			}
+
+			// Running agents' files are never pruned, so the cap can be
+			// exceeded; warn once each time that happens
+			overCap := policy.MaxBytes > 0 && result.Remaining > policy.MaxBytes
+			if overCap && !warned {
+				fmt.Printf("Warning: agent artifacts use %s, over the %s retention cap, and running agents' files cannot be pruned\n",
+					retention.FormatBytes(result.Remaining), retention.FormatBytes(policy.MaxBytes))
+			}
+			warned = overCap
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-finished
+	}
+}
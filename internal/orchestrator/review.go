@@ -0,0 +1,131 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// Draft is an answer the orchestrator drafted, awaiting an operator's
+// review. Its draft file next to the question tells the agent to keep
+// waiting.
+type Draft struct {
+	TaskID     string `json:"task"`
+	QuestionID int    `json:"question"`
+	Question   string `json:"text"`
+	Answer     string `json:"draft"`
+	// Path is the draft file, d-N.txt for the question q-N.txt
+	Path string `json:"path"`
+}
+
+// draftFile returns the draft file of a question file
+func draftFile(questionFile string) string {
+	return filepath.Join(filepath.Dir(questionFile), "d-"+strings.TrimPrefix(filepath.Base(questionFile), "q-"))
+}
+
+// queueDraft holds the answer to a question for review instead of giving
+// it to the agent
+func (o *Orchestrator) queueDraft(taskID, questionFile string, qNum int, answer string) error {
+	if err := writeFileAtomic(draftFile(questionFile), []byte(answer)); err != nil {
+		return fmt.Errorf("failed to write draft answer: %w", err)
+	}
+	if err := o.state.DraftAnswer(taskID, qNum, answer); err != nil {
+		return fmt.Errorf("failed to draft answer: %w", err)
+	}
+	fmt.Printf("[%s] Answer to question %d from agent %s awaits review: swarm answer %s %d\n", time.Now().Format("15:04:05"), qNum, taskID, taskID, qNum)
+	return nil
+}
+
+// Answer gives an agent the answer an operator approved or wrote for a
+// drafted question. An empty answer approves the draft as it is.
+func (o *Orchestrator) Answer(operator, taskID string, qID int, answer string) error {
+	draft, err := FindDraft(o.swarmDir, taskID, qID)
+	if err != nil {
+		return err
+	}
+
+	detail := fmt.Sprintf("question %d edited", qID)
+	if answer == "" {
+		answer, detail = draft.Answer, fmt.Sprintf("question %d approved", qID)
+	}
+	answer = o.state.Redact(answer)
+
+	answerFile := filepath.Join(filepath.Dir(draft.Path), "a-"+strings.TrimPrefix(filepath.Base(draft.Path), "d-"))
+	if err := os.WriteFile(answerFile, []byte(answer), 0644); err != nil {
+		return fmt.Errorf("failed to write answer: %w", err)
+	}
+	os.Remove(draft.Path)
+
+	if o.recorder != nil {
+		if err := o.recorder.RecordAnswer(taskID, draft.Question, answer); err != nil {
+			fmt.Printf("Failed to record answer: %v\n", err)
+		}
+	}
+	if err := o.state.AnswerQuestion(taskID, qID, answer, operator); err != nil {
+		return fmt.Errorf("failed to answer question: %w", err)
+	}
+
+	fmt.Printf("[%s] Answer to question %d from agent %s given by %s: %s\n", time.Now().Format("15:04:05"), qID, taskID, operatorName(operator), answer)
+	o.audit(operator, workflow.ControlAnswer, taskID, detail)
+	return nil
+}
+
+// FindDraft returns the drafted answer to a task's question in the
+// session in swarmDir
+func FindDraft(swarmDir, taskID string, qID int) (*Draft, error) {
+	drafts, err := PendingDrafts(swarmDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, draft := range drafts {
+		if draft.TaskID == taskID && draft.QuestionID == qID {
+			return &draft, nil
+		}
+	}
+	return nil, workflow.Errorf(workflow.ErrorNotFound, "no drafted answer awaits review for question %d of task %s", qID, taskID)
+}
+
+// PendingDrafts returns the drafted answers awaiting review in the session
+// in swarmDir, by task and question
+func PendingDrafts(swarmDir string) ([]Draft, error) {
+	paths, err := filepath.Glob(filepath.Join(swarmDir, "agents", "agent-*", "questions", "d-*.txt"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list drafts: %w", err)
+	}
+
+	var drafts []Draft
+	for _, path := range paths {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), "d-"), ".txt")
+		qID, err := strconv.Atoi(strings.SplitN(name, "-", 2)[0])
+		if err != nil {
+			continue
+		}
+		answer, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		question, _ := os.ReadFile(filepath.Join(filepath.Dir(path), "q-"+name+".txt"))
+
+		drafts = append(drafts, Draft{
+			TaskID:     strings.TrimPrefix(filepath.Base(filepath.Dir(filepath.Dir(path))), "agent-"),
+			QuestionID: qID,
+			Question:   strings.TrimSpace(string(question)),
+			Answer:     string(answer),
+			Path:       path,
+		})
+	}
+
+	sort.Slice(drafts, func(i, j int) bool {
+		if drafts[i].TaskID != drafts[j].TaskID {
+			return drafts[i].TaskID < drafts[j].TaskID
+		}
+		return drafts[i].QuestionID < drafts[j].QuestionID
+	})
+	return drafts, nil
+}
@@ -0,0 +1,93 @@
+package orchestrator
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// socketTimeout bounds how long a socket connection may take to send its
+// message; operations themselves run under their own timeouts
+const socketTimeout = 10 * time.Second
+
+// startSocket takes agent messages on the session's Unix socket, so that
+// operations round-trip without polling, and returns a function that stops
+// listening. When the socket cannot be created, such as when the session
+// path is too long for one, agents keep using the file bus.
+func (o *Orchestrator) startSocket(ctx context.Context) func() {
+	path := filepath.Join(o.swarmDir, workflow.SocketFile)
+
+	// A crashed run leaves its socket behind
+	os.Remove(path)
+
+	listener, err := listenPrivate(path)
+	if err != nil {
+		fmt.Printf("[%s] Message socket unavailable, agents use the file bus: %v\n", time.Now().Format("15:04:05"), err)
+		return func() {}
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				if !errors.Is(err, net.ErrClosed) {
+					fmt.Printf("Message socket error: %v\n", err)
+				}
+				return
+			}
+			o.handlers.Add(1)
+			go func() {
+				defer o.handlers.Done()
+				o.messageHandler.serveConn(ctx, conn)
+			}()
+		}
+	}()
+
+	return func() {
+		listener.Close()
+	}
+}
+
+// serveConn answers the message an agent sent over the socket, chunk by
+// chunk for streamed messages. Messages must name a running agent.
+func (h *MessageHandler) serveConn(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+	received := time.Now()
+
+	conn.SetReadDeadline(received.Add(socketTimeout))
+	var req workflow.SocketRequest
+	if err := json.NewDecoder(bufio.NewReader(conn)).Decode(&req); err != nil {
+		fmt.Printf("Error handling message: failed to parse socket message: %v\n", err)
+		return
+	}
+	conn.SetReadDeadline(time.Time{})
+
+	encoder := json.NewEncoder(conn)
+	if agent := h.orchestrator.state.GetAgent(req.AgentID); agent == nil || agent.Status != workflow.TaskStatusRunning {
+		response := workflow.Response{
+			MessageID: req.Message.ID,
+			Timestamp: time.Now(),
+		}
+		response.SetError(workflow.WithField("agent_id", workflow.Errorf(workflow.ErrorPermissionDenied, "no running agent for task %q", req.AgentID)))
+		if err := encoder.Encode(response); err != nil {
+			fmt.Printf("Error handling message: failed to send response: %v\n", err)
+		}
+		return
+	}
+	response := h.respond(ctx, req.AgentID, &req.Message, func(chunk workflow.Response) error {
+		return encoder.Encode(chunk)
+	})
+	if err := encoder.Encode(response); err != nil {
+		fmt.Printf("Error handling message: failed to send response: %v\n", err)
+		return
+	}
+	h.handled(&req.Message, response, received)
+}
@@ -0,0 +1,30 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// Spawner launches the agent that works on a task once its directory,
+// context file and settings have been prepared by the orchestrator.
+// Agents should be stopped when ctx is cancelled.
+type Spawner interface {
+	Spawn(ctx context.Context, task workflow.Task, agentDir, prompt string) error
+}
+
+// PromptSpawner prints the spawn prompt so Claude A can launch the agent
+// with the Task tool. This is the default spawner.
+type PromptSpawner struct{}
+
+// Spawn prints the spawn instructions for a task
+func (s *PromptSpawner) Spawn(ctx context.Context, task workflow.Task, agentDir, prompt string) error {
+	fmt.Printf("\n[SPAWN_AGENT] %s\n", task.ID)
+	fmt.Printf("Type: %s\n", task.AgentType)
+	fmt.Printf("Directory: %s\n", agentDir)
+	fmt.Printf("\nPrompt:\n%s\n", prompt)
+	fmt.Printf("\n[ORCHESTRATOR] Please use the Task tool to spawn this agent with the above prompt.\n\n")
+
+	return nil
+}
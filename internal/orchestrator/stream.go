@@ -0,0 +1,197 @@
+package orchestrator
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// streamFlushInterval is how often a running command's output is sent
+const streamFlushInterval = 250 * time.Millisecond
+
+// responseStream sends a streamed response to an agent in chunks
+type responseStream struct {
+	mu        sync.Mutex
+	messageID string
+	send      func(workflow.Response) error
+	// cancel stops the operation when the agent stops listening
+	cancel   context.CancelFunc
+	sequence int
+	pending  strings.Builder
+	lastSent time.Time
+	err      error
+	// whole keeps all the data sent, for the recorder and duplicates
+	whole *strings.Builder
+}
+
+// newResponseStream streams the response to a message through send.
+// With keep, the data sent is kept for recording and answering duplicates.
+func newResponseStream(msg *workflow.Message, send func(workflow.Response) error, cancel context.CancelFunc, keep bool) *responseStream {
+	s := &responseStream{
+		messageID: msg.ID,
+		send:      send,
+		cancel:    cancel,
+		lastSent:  time.Now(),
+	}
+	if keep {
+		s.whole = &strings.Builder{}
+	}
+	return s
+}
+
+// write queues data, sending full chunks right away. Chunks end on a
+// character boundary, so multi-byte characters are never split.
+func (s *responseStream) write(data string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pending.WriteString(data)
+	for s.pending.Len() >= workflow.StreamChunkSize {
+		s.sendPending(workflow.StreamChunkSize)
+	}
+}
+
+// flush sends the queued data, or an empty chunk when nothing was sent
+// for StreamKeepAlive
+func (s *responseStream) flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if runeBoundary(s.pending.String(), s.pending.Len()) == 0 && time.Since(s.lastSent) < workflow.StreamKeepAlive {
+		return
+	}
+	s.sendPending(s.pending.Len())
+}
+
+// sendPending sends up to n bytes of the queued data, keeping back a
+// character cut short at the end until the rest of it is queued
+func (s *responseStream) sendPending(n int) {
+	pending := s.pending.String()
+	cut := runeBoundary(pending, n)
+	if cut == 0 && n >= utf8.UTFMax {
+		cut = n
+	}
+	s.pending.Reset()
+	s.pending.WriteString(pending[cut:])
+	s.sendChunk(pending[:cut])
+}
+
+// runeBoundary moves a cut at n back to the start of the character it
+// splits. Invalid bytes are not held back.
+func runeBoundary(text string, n int) int {
+	n = min(n, len(text))
+	start := n - 1
+	for start > 0 && n-start < utf8.UTFMax && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	if start >= 0 && utf8.RuneStart(text[start]) && !utf8.FullRuneInString(text[start:n]) {
+		return start
+	}
+	return n
+}
+
+// sendChunk sends one chunk; after the agent stopped listening, the
+// operation is cancelled and nothing more is sent
+func (s *responseStream) sendChunk(data string) {
+	if s.err != nil {
+		return
+	}
+	s.sequence++
+	s.lastSent = time.Now()
+	if s.whole != nil {
+		s.whole.WriteString(data)
+	}
+
+	s.err = s.send(workflow.Response{
+		MessageID: s.messageID,
+		Data:      data,
+		More:      true,
+		Sequence:  s.sequence,
+		Timestamp: time.Now(),
+	})
+	if s.err != nil && s.cancel != nil {
+		s.cancel()
+	}
+}
+
+// finish sends the queued data and the final response's data as chunks,
+// leaving the final response to say how many came before it
+func (s *responseStream) finish(response *workflow.Response) {
+	s.write(response.Data)
+	s.mu.Lock()
+	if s.pending.Len() > 0 {
+		s.sendChunk(s.pending.String())
+		s.pending.Reset()
+	}
+	s.mu.Unlock()
+
+	response.Data = ""
+	response.Sequence = s.sequence
+}
+
+// recorded returns the final response with all the data streamed before it,
+// as it is recorded for replay
+func (s *responseStream) recorded(response workflow.Response) workflow.Response {
+	if s.whole != nil {
+		response.Data = s.whole.String() + response.Data
+	}
+	response.Sequence = 0
+	return response
+}
+
+// run starts a command and streams its combined output while it runs.
+// Output is redacted a line at a time, so that secrets are not split
+// between chunks.
+func (s *responseStream) run(cmd *exec.Cmd, redact func(string) string) error {
+	output, writer := io.Pipe()
+	cmd.Stdout, cmd.Stderr = writer, writer
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+		writer.Close()
+	}()
+
+	// Queued output goes out every streamFlushInterval, and the ticker
+	// stops before the final response is sent
+	ticker := time.NewTicker(streamFlushInterval)
+	stopTicker, tickerDone := make(chan struct{}), make(chan struct{})
+	defer func() {
+		close(stopTicker)
+		<-tickerDone
+	}()
+	go func() {
+		defer close(tickerDone)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopTicker:
+				return
+			case <-ticker.C:
+				s.flush()
+			}
+		}
+	}()
+
+	reader := bufio.NewReaderSize(output, 64*1024)
+	for {
+		line, err := reader.ReadSlice('\n')
+		if len(line) > 0 {
+			s.write(redact(string(line)))
+		}
+		if err != nil && !errors.Is(err, bufio.ErrBufferFull) {
+			break
+		}
+	}
+	return <-done
+}
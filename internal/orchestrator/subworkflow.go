@@ -0,0 +1,155 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/state"
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// maxSubWorkflowDepth bounds how deeply workflows may run one another
+const maxSubWorkflowDepth = 5
+
+// subSessionDir is the directory in a workflow task's agent directory that
+// holds its child session
+const subSessionDir = "session"
+
+// withAncestors records the sub-workflow files a child session runs under,
+// to catch workflows that run themselves
+func withAncestors(ancestors []string) Option {
+	return func(o *Orchestrator) {
+		o.ancestors = ancestors
+	}
+}
+
+// startSubWorkflow runs a workflow task's workflow as a child session in
+// the task's agent directory. The task completes with the joined outputs
+// of the child's final tasks, or fails when any child task fails.
+func (o *Orchestrator) startSubWorkflow(ctx context.Context, task workflow.Task) error {
+	if err := o.state.AddAgent(task.ID, ""); err != nil {
+		return err
+	}
+	fmt.Printf("[%s] Sub-workflow started: %s (%s)\n", time.Now().Format("15:04:05"), task.ID, task.Workflow)
+
+	o.handlers.Add(1)
+	go func() {
+		defer o.handlers.Done()
+
+		output, err := o.runSubWorkflow(ctx, task)
+		if o.state.IsCancelled() || o.state.IsHalted() {
+			return
+		}
+		if err != nil {
+			if err := o.state.FailTask(task.ID, err.Error()); err != nil {
+				fmt.Printf("Failed to fail task %s: %v\n", task.ID, err)
+			}
+			fmt.Printf("[%s] Task failed: %s: %v\n", time.Now().Format("15:04:05"), task.ID, err)
+			return
+		}
+
+		if err := o.state.CompleteTask(task.ID, output); err != nil {
+			fmt.Printf("Failed to complete task %s: %v\n", task.ID, err)
+			return
+		}
+		fmt.Printf("[%s] Task completed: %s (sub-workflow)\n", time.Now().Format("15:04:05"), task.ID)
+	}()
+	return nil
+}
+
+// runSubWorkflow runs a child session to the end and returns its output
+func (o *Orchestrator) runSubWorkflow(ctx context.Context, task workflow.Task) (string, error) {
+	if slices.Contains(o.ancestors, task.Workflow) {
+		return "", fmt.Errorf("sub-workflow %s runs itself", task.Workflow)
+	}
+	if len(o.ancestors) >= maxSubWorkflowDepth {
+		return "", fmt.Errorf("sub-workflows nested more than %d deep", maxSubWorkflowDepth)
+	}
+
+	wf, err := o.parser.ParseFile(task.Workflow)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse sub-workflow: %w", err)
+	}
+
+	// The task's with values, which may use the parent's parameters, set
+	// the child's parameters
+	params := make(map[string]string, len(task.With))
+	for name, value := range task.With {
+		params[name] = o.state.Workflow.InterpolateParams(value)
+	}
+	if err := wf.SetParams(params); err != nil {
+		return "", err
+	}
+
+	// Children follow the parent's read-only mode and canned answers
+	if o.state.IsReadOnly() {
+		wf.ReadOnly = true
+	}
+	wf.Answers = append(wf.Answers, o.state.Workflow.Answers...)
+
+	// Start from a clean session, so markers from an earlier iteration
+	// are not mistaken for this one's
+	childDir := filepath.Join(o.swarmDir, "agents", fmt.Sprintf("agent-%s", task.ID), subSessionDir)
+	if err := os.RemoveAll(childDir); err != nil {
+		return "", fmt.Errorf("failed to clear sub-workflow session: %w", err)
+	}
+	for _, subdir := range []string{"agents", "logs"} {
+		if err := os.MkdirAll(filepath.Join(childDir, subdir), 0755); err != nil {
+			return "", fmt.Errorf("failed to create %s directory: %w", subdir, err)
+		}
+	}
+
+	childState := state.NewSwarmState(o.state.SessionID+"/"+task.ID, o.state.Plan, wf)
+	childState.Paused = o.state.IsPaused()
+	opts := []Option{
+		WithSpawner(o.spawner),
+		WithTickInterval(o.tickInterval),
+		WithPollInterval(o.pollInterval),
+		WithAPI(o.apiURL, o.apiToken),
+		WithAgentTypes(o.agentTypes),
+		withAncestors(append(slices.Clone(o.ancestors), task.Workflow)),
+	}
+	// Go lifecycle hooks run for the child's agents too
+	for _, callback := range o.lifecycleCallbacks {
+		opts = append(opts, WithLifecycleHook(callback.point, callback.fn))
+	}
+	child, err := NewOrchestrator(childDir, childState, opts...)
+	if err != nil {
+		return "", err
+	}
+	defer child.Stop()
+	o.addSubWorkflow(task.ID, child)
+	defer o.removeSubWorkflow(task.ID)
+
+	if err := child.Run(ctx); err != nil {
+		return "", fmt.Errorf("sub-workflow %s failed: %w", filepath.Base(task.Workflow), err)
+	}
+	if failed := childState.GetFailedTasks(); len(failed) > 0 {
+		return "", fmt.Errorf("sub-workflow %s failed: %w: %v", filepath.Base(task.Workflow), ErrTasksFailed, failed)
+	}
+
+	return workflow.JoinOutputs(finalTasks(childState.GetTasks(), childState.GetOutputs()), childState.GetOutputs()), nil
+}
+
+// finalTasks returns the completed tasks no other task depends on, whose
+// outputs make up the workflow's output
+func finalTasks(tasks []workflow.Task, outputs map[string]string) []string {
+	dependedOn := make(map[string]bool)
+	for _, task := range tasks {
+		for _, dep := range task.DependsOn {
+			dependedOn[dep] = true
+		}
+	}
+
+	var final []string
+	for _, task := range tasks {
+		if _, completed := outputs[task.ID]; completed && !dependedOn[task.ID] {
+			final = append(final, task.ID)
+		}
+	}
+	return final
+}
@@ -0,0 +1,215 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// DefaultSummaryCommand summarizes long dependency outputs with the claude
+// CLI, which reads the output on stdin. Without --summary-command long
+// outputs are excerpted.
+const DefaultSummaryCommand = "claude -p {prompt}"
+
+// SummariesDir is the session directory caching summaries of long outputs
+// next to the outputs they summarize
+const SummariesDir = "summaries"
+
+// summaryTimeout bounds how long the summary command may run
+const summaryTimeout = 2 * time.Minute
+
+// Bounds of an extractive summary, used when no summary command is set or
+// it fails
+const (
+	summaryHead = 1500
+	summaryTail = 500
+)
+
+// summaryPrompt instructs the summary command
+const summaryPrompt = `Summarize the output of task %s, given on stdin, for the agents of the tasks that depend on it. Keep decisions, file paths, names, interfaces, commands and open issues; drop narration. Answer with the summary only, in at most 300 words.`
+
+// dependencyOutput renders a dependency's output for a task's context:
+// whole, or summarized with a pointer to the full output when it is long
+// and the task takes summaries. It never runs the summary command, whose
+// summaries awaitSummaries makes before the task is spawned.
+func (o *Orchestrator) dependencyOutput(task workflow.Task, depID, output string) string {
+	if !task.SummarizesDependencies() || len(output) <= o.state.Workflow.SummaryThresholdBytes() {
+		return fmt.Sprintf("## Output from task: %s\n%s\n\n", depID, output)
+	}
+
+	summary, fullPath, err := o.summarize(context.Background(), depID, output, "")
+	if err != nil {
+		fmt.Printf("Failed to summarize output of %s: %v\n", depID, err)
+		return fmt.Sprintf("## Output from task: %s\n%s\n\n", depID, output)
+	}
+	return fmt.Sprintf("## Summary of output from task: %s\nThe full output (%d bytes) is in %s; read it for details.\n\n%s\n\n", depID, len(output), fullPath, summary)
+}
+
+// awaitSummaries starts summarizing the long dependency outputs a task's
+// context needs and reports whether their summaries are ready. Summaries
+// are made in the background, so the task waits for them without holding
+// up the scheduler.
+func (o *Orchestrator) awaitSummaries(ctx context.Context, task workflow.Task) bool {
+	if o.summaryCommand == "" || !task.SummarizesDependencies() {
+		return true
+	}
+
+	ready := true
+	outputs := o.state.GetOutputs()
+	for _, depID := range task.DependsOn {
+		output, exists := outputs[depID]
+		if !exists || len(output) <= o.state.Workflow.SummaryThresholdBytes() {
+			continue
+		}
+		if o.startSummary(ctx, depID, output) {
+			ready = false
+		}
+	}
+	return ready
+}
+
+// startSummary summarizes a task's output with the summary command in the
+// background, unless it is cached, and reports whether the summary is
+// still being made. The scheduler runs again once it is done.
+func (o *Orchestrator) startSummary(ctx context.Context, taskID, output string) bool {
+	_, summaryPath := o.summaryPaths(taskID, output)
+	if _, err := os.Stat(summaryPath); err == nil {
+		return false
+	}
+
+	o.summariesMu.Lock()
+	defer o.summariesMu.Unlock()
+	if o.summarizing[taskID] {
+		return true
+	}
+	if o.summarizing == nil {
+		o.summarizing = make(map[string]bool)
+	}
+	o.summarizing[taskID] = true
+
+	go func() {
+		if _, _, err := o.summarize(ctx, taskID, output, o.summaryCommand); err != nil {
+			fmt.Printf("Failed to summarize output of %s: %v\n", taskID, err)
+		}
+
+		o.summariesMu.Lock()
+		delete(o.summarizing, taskID)
+		o.summariesMu.Unlock()
+		o.wakeUpAt(time.Now())
+	}()
+	return true
+}
+
+// summaryPaths returns the paths of the full output and of the summary of
+// a task's output, named by the output's hash
+func (o *Orchestrator) summaryPaths(taskID, output string) (string, string) {
+	sum := sha256.Sum256([]byte(output))
+	base := filepath.Join(o.swarmDir, SummariesDir, fmt.Sprintf("%s-%s", taskID, hex.EncodeToString(sum[:6])))
+	return base + ".txt", base + ".md"
+}
+
+// summarize returns a summary of a task's output and the path of the file
+// holding the full output. Summaries are cached by the output's hash, so
+// every dependent gets the same one and it is made once. Without a
+// command, or if it fails, the summary is an excerpt.
+func (o *Orchestrator) summarize(ctx context.Context, taskID, output, command string) (string, string, error) {
+	fullPath, summaryPath := o.summaryPaths(taskID, output)
+
+	if cached, err := os.ReadFile(summaryPath); err == nil {
+		return string(cached), fullPath, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(summaryPath), 0755); err != nil {
+		return "", "", fmt.Errorf("failed to create summaries directory: %w", err)
+	}
+	if err := os.WriteFile(fullPath, []byte(output), 0644); err != nil {
+		return "", "", fmt.Errorf("failed to write output: %w", err)
+	}
+
+	summary := ""
+	if command != "" {
+		generated, err := runSummaryCommand(ctx, command, o.swarmDir, taskID, output)
+		if err != nil {
+			fmt.Printf("[%s] Summary command failed for %s, using an excerpt: %v\n", time.Now().Format("15:04:05"), taskID, err)
+		}
+		summary = generated
+	}
+	if summary == "" {
+		summary = excerpt(output)
+	}
+
+	if err := os.WriteFile(summaryPath, []byte(summary), 0644); err != nil {
+		return "", "", fmt.Errorf("failed to write summary: %w", err)
+	}
+	fmt.Printf("[%s] Summarized output of %s (%d bytes): %s\n", time.Now().Format("15:04:05"), taskID, len(output), summaryPath)
+	return summary, fullPath, nil
+}
+
+// runSummaryCommand summarizes an output with a summary command, which
+// reads the output on stdin and prints the summary. It stops with ctx.
+func runSummaryCommand(ctx context.Context, command, dir, taskID, output string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, summaryTimeout)
+	defer cancel()
+
+	command = strings.ReplaceAll(command, "{prompt}", shellQuote(fmt.Sprintf(summaryPrompt, taskID)))
+	cmd := exec.CommandContext(ctx, "bash", "-c", command)
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(output)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	summary, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("%w: %s", err, message)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(summary)), nil
+}
+
+// excerpt summarizes an output without a model: its Markdown headings, for
+// the outline, then its beginning and end
+func excerpt(output string) string {
+	if len(output) <= summaryHead+summaryTail {
+		return output
+	}
+
+	var headings []string
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "#") {
+			headings = append(headings, line)
+		}
+	}
+
+	var b strings.Builder
+	if len(headings) > 0 {
+		fmt.Fprintf(&b, "Outline:\n%s\n\n", strings.Join(headings, "\n"))
+	}
+	fmt.Fprintf(&b, "Beginning:\n%s\n\n[...]\n\nEnd:\n%s", cutAtLine(output[:summaryHead], false), cutAtLine(output[len(output)-summaryTail:], true))
+	return b.String()
+}
+
+// cutAtLine drops the partial line at the end of text, or at its start
+// with fromStart, unless text is a single line
+func cutAtLine(text string, fromStart bool) string {
+	if fromStart {
+		if i := strings.Index(text, "\n"); i >= 0 {
+			return text[i+1:]
+		}
+		return text
+	}
+	if i := strings.LastIndex(text, "\n"); i >= 0 {
+		return text[:i]
+	}
+	return text
+}
@@ -0,0 +1,98 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// verifyHeartbeatInterval is how often a task being verified counts as
+// alive, so that long checks do not make its agent look stale
+const verifyHeartbeatInterval = 10 * time.Second
+
+// ExitWaiter is implemented by spawners that can wait for the agents they
+// started to exit
+type ExitWaiter interface {
+	WaitExited(taskID string, timeout time.Duration)
+}
+
+// VerifyCompletion runs the verification command of a task whose agent
+// is completing it. It returns nil when the command passes or the task
+// has none. Otherwise the failure is recorded and returned as a
+// verification_failed error: while the task has attempts left its agent
+// fixes the work and completes again or, with respawn, a new agent does;
+// after that the task fails.
+func (o *Orchestrator) VerifyCompletion(ctx context.Context, taskID string, respawn bool) error {
+	task := o.state.GetTask(taskID)
+	if task == nil || task.Verify == nil {
+		return nil
+	}
+
+	command := o.state.Workflow.InterpolateParams(task.Verify.Run)
+	ctx, cancel := context.WithTimeout(ctx, task.Verify.Timeout())
+	defer cancel()
+
+	// The agent counts as alive while its work is checked
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		ticker := time.NewTicker(verifyHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				o.state.Heartbeat(taskID)
+			}
+		}
+	}()
+
+	cmd := exec.CommandContext(ctx, "bash", "-c", o.state.InterpolateSecrets(command))
+	cmd.Dir = task.Verify.WorkingDir
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		fmt.Printf("[%s] Verification passed: %s\n", time.Now().Format("15:04:05"), taskID)
+		return nil
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("timed out after %s", task.Verify.Timeout())
+	}
+
+	// A new agent starts only once the old one's process is gone, so that
+	// its exit is not taken for the new agent crashing
+	if waiter, ok := o.spawner.(ExitWaiter); ok && respawn {
+		waiter.WaitExited(taskID, agentStopGrace)
+	}
+
+	report := workflow.VerifyReport(command, err, o.state.Redact(string(output)), o.state.Workflow.AcceptanceCriteria(*task))
+	retry, failed := o.state.FailVerification(taskID, report, respawn)
+	if !retry {
+		o.logFailure(taskID)
+		return workflow.Errorf(workflow.ErrorVerificationFailed, "%s", report)
+	}
+
+	fmt.Printf("[%s] Verification failed: %s: %v (attempt %d of %d)\n", time.Now().Format("15:04:05"), taskID, err, failed, task.Verify.Attempts())
+	return workflow.Errorf(workflow.ErrorVerificationFailed, "%s\n\nThe task is not complete (attempt %d of %d): fix the failure and complete again.", strings.TrimSpace(report), failed, task.Verify.Attempts())
+}
+
+// verificationNote tells an agent that the previous attempt at its task
+// failed verification, and why
+func (o *Orchestrator) verificationNote(task workflow.Task) string {
+	reports := o.state.GetVerifications(task.ID)
+	if len(reports) == 0 || task.Verify == nil {
+		return ""
+	}
+
+	var note strings.Builder
+	note.WriteString("## PREVIOUS ATTEMPT FAILED VERIFICATION\n")
+	fmt.Fprintf(&note, "This is attempt %d of at most %d. The previous agent completed the task, but its verification failed:\n\n",
+		len(reports)+1, task.Verify.Attempts())
+	fmt.Fprintf(&note, "%s\n\n", strings.TrimSpace(reports[len(reports)-1]))
+	note.WriteString("Its changes are still in place. Find and fix the cause, run the check yourself, and complete the task when it passes.\n\n")
+	return note.String()
+}
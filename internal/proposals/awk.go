@@ -0,0 +1,104 @@
+package proposals
+
+import (
+	"strings"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// checkAwkProgram rejects awk programs that run commands (system, pipes,
+// @ directives and indirect calls) or redirect print output to files.
+// Comparisons such as $1 > 5 are allowed outside print statements and
+// inside parentheses, where awk does not treat > as a redirection.
+func checkAwkProgram(program string) error {
+	inPrint := false
+	depth := 0
+	var prev byte = '\n'
+	prevWord := ""
+
+	for i := 0; i < len(program); i++ {
+		c := program[i]
+		switch {
+		case c == ' ' || c == '\t':
+			continue
+		case c == '\\' && i+1 < len(program) && program[i+1] == '\n':
+			i++
+			continue
+		case c == '#':
+			for i < len(program) && program[i] != '\n' {
+				i++
+			}
+			i--
+			continue
+		case c == '"':
+			for i++; i < len(program) && program[i] != '"'; i++ {
+				if program[i] == '\\' {
+					i++
+				}
+			}
+		case c == '/' && awkRegexAllowed(prev, prevWord):
+			for i++; i < len(program) && program[i] != '/'; i++ {
+				if program[i] == '\\' {
+					i++
+				}
+			}
+		case isAwkWordStart(c):
+			start := i
+			for i+1 < len(program) && (isAwkWordStart(program[i+1]) || isDigit(program[i+1])) {
+				i++
+			}
+			word := program[start : i+1]
+			if word == "system" {
+				return workflow.Errorf(workflow.ErrorSandboxViolation, "read-only mode: awk may not run commands")
+			}
+			if word == "print" || word == "printf" {
+				inPrint, depth = true, 0
+			}
+			prev, prevWord = c, word
+			continue
+		case c == '|':
+			if i+1 < len(program) && program[i+1] == '|' {
+				i++
+				break
+			}
+			return workflow.Errorf(workflow.ErrorSandboxViolation, "read-only mode: awk may not run commands")
+		case c == '@':
+			return workflow.Errorf(workflow.ErrorSandboxViolation, "read-only mode: awk @ directives and indirect calls are not allowed")
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			depth--
+		case c == '>':
+			if inPrint && depth <= 0 {
+				return workflow.Errorf(workflow.ErrorSandboxViolation, "read-only mode: awk may not write files")
+			}
+		case c == ';' || c == '{' || c == '}':
+			inPrint = false
+		case c == '\n':
+			// A statement continues on the next lines after a comma
+			// or inside parentheses
+			if prev == ',' || depth > 0 {
+				continue
+			}
+			inPrint = false
+		}
+		prev, prevWord = c, ""
+	}
+
+	return nil
+}
+
+// awkRegexAllowed reports whether a slash starts a regex rather than a
+// division. Only positions where awk expects an operand count, so an
+// unclear slash is scanned as code instead of being skipped.
+func awkRegexAllowed(prev byte, prevWord string) bool {
+	if prevWord != "" {
+		return prevWord == "print" || prevWord == "printf" || prevWord == "return"
+	}
+	return strings.IndexByte("\n(,{};!~&|=", prev) >= 0
+}
+
+// isAwkWordStart reports whether c may start an awk identifier
+func isAwkWordStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
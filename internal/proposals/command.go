@@ -0,0 +1,337 @@
+package proposals
+
+import (
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// readOnlyCommands are the programs allowed to run in read-only mode
+var readOnlyCommands = map[string]bool{
+	"cat": true, "head": true, "tail": true, "less": true, "more": true,
+	"ls": true, "tree": true, "find": true, "file": true, "stat": true,
+	"du": true, "df": true, "wc": true, "grep": true, "egrep": true,
+	"fgrep": true, "rg": true, "ag": true, "cut": true, "sort": true,
+	"uniq": true, "diff": true, "cmp": true, "awk": true, "sed": true,
+	"tr": true, "echo": true, "printf": true, "pwd": true, "which": true,
+	"env": true, "date": true, "true": true, "false": true, "test": true,
+	"basename": true, "dirname": true, "realpath": true, "readlink": true,
+	"md5sum": true, "sha256sum": true, "jq": true, "git": true, "go": true,
+}
+
+// readOnlyGitCommands are the git subcommands allowed in read-only mode
+var readOnlyGitCommands = map[string]bool{
+	"status": true, "log": true, "diff": true, "show": true, "blame": true,
+	"branch": true, "rev-parse": true, "ls-files": true, "grep": true,
+	"describe": true, "shortlog": true, "cat-file": true,
+}
+
+// readOnlyGoCommands are the go subcommands allowed in read-only mode
+var readOnlyGoCommands = map[string]bool{
+	"version": true, "env": true, "list": true, "doc": true, "vet": true,
+}
+
+// writeFlags are the options that let an allowed program write files or
+// run other programs
+var writeFlags = map[string]flagRules{
+	"sed":  {deny: "if", values: "l", script: 'e', long: []string{"in-place", "file"}, longValues: []string{"line-length"}, longScript: "expression"},
+	"awk":  {deny: "fEilopdDW", values: "Fv", script: 'e', long: []string{"file", "exec", "include", "load", "pretty-print", "profile", "dump-variables", "debug"}, longValues: []string{"field-separator", "assign"}, longScript: "source"},
+	"sort": {deny: "o", values: "kStT", long: []string{"output", "compress-program"}},
+	"uniq": {values: "fsw"},
+	"tree": {deny: "oR"},
+	"less": {deny: "oO", long: []string{"log-file", "LOG-FILE"}},
+	"file": {deny: "C", long: []string{"compile"}},
+	"date": {deny: "s", values: "dfr", long: []string{"set"}},
+	"rg":   {long: []string{"pre", "hostname-bin"}},
+	"ag":   {long: []string{"pager"}},
+	"git":  {long: []string{"output", "open-files-in-pager"}},
+}
+
+// readOnlyFindActions are the find actions that delete files, write
+// files or run programs
+var readOnlyFindActions = map[string]bool{
+	"-delete": true, "-exec": true, "-execdir": true, "-ok": true, "-okdir": true,
+	"-fprint": true, "-fprint0": true, "-fprintf": true, "-fls": true,
+}
+
+// goWriteFlags are the go flags that run other programs or modify go.mod
+var goWriteFlags = map[string]bool{
+	"toolexec": true, "vettool": true, "exec": true, "modfile": true, "overlay": true, "o": true,
+}
+
+// CheckReadOnlyCommand returns an error if a bash command may have side
+// effects. The command is parsed as a shell program and every command it
+// runs must be on the read-only allowlist without write flags; output
+// redirection, substitutions and environment changes are rejected.
+func CheckReadOnlyCommand(command string) error {
+	file, err := syntax.NewParser(syntax.Variant(syntax.LangBash)).Parse(strings.NewReader(command), "")
+	if err != nil {
+		return workflow.Errorf(workflow.ErrorSandboxViolation, "read-only mode: cannot parse command: %v", err)
+	}
+
+	var violation error
+	syntax.Walk(file, func(node syntax.Node) bool {
+		if violation == nil {
+			violation = checkNode(node)
+		}
+		return violation == nil
+	})
+	return violation
+}
+
+// checkNode rejects shell constructs that can write files or run
+// arbitrary programs
+func checkNode(node syntax.Node) error {
+	switch n := node.(type) {
+	case *syntax.CmdSubst:
+		return workflow.Errorf(workflow.ErrorSandboxViolation, "read-only mode: command substitution is not allowed")
+	case *syntax.ProcSubst:
+		return workflow.Errorf(workflow.ErrorSandboxViolation, "read-only mode: process substitution is not allowed")
+	case *syntax.DeclClause:
+		return workflow.Errorf(workflow.ErrorSandboxViolation, "read-only mode: %s is not allowed", n.Variant.Value)
+	case *syntax.ParamExp:
+		if n.Exp != nil || n.Repl != nil {
+			return workflow.Errorf(workflow.ErrorSandboxViolation, "read-only mode: parameter expansion operators are not allowed")
+		}
+	case *syntax.Word:
+		if syntax.SplitBraces(n) {
+			return workflow.Errorf(workflow.ErrorSandboxViolation, "read-only mode: brace expansion is not allowed")
+		}
+	case *syntax.Assign:
+		if n.Name != nil && !safeVariable(n.Name.Value) {
+			return workflow.Errorf(workflow.ErrorSandboxViolation, "read-only mode: setting %s is not allowed", n.Name.Value)
+		}
+	case *syntax.Redirect:
+		return checkRedirect(n)
+	case *syntax.CallExpr:
+		return checkCall(n)
+	}
+	return nil
+}
+
+// safeVariable reports whether a variable only affects formatting and may
+// be set before a command, like LC_ALL=C
+func safeVariable(name string) bool {
+	switch name {
+	case "LANG", "LANGUAGE", "TZ", "NO_COLOR", "COLUMNS":
+		return true
+	}
+	return strings.HasPrefix(name, "LC_")
+}
+
+// checkRedirect allows input redirection, duplicating descriptors (2>&1)
+// and discarding output (>/dev/null)
+func checkRedirect(redirect *syntax.Redirect) error {
+	target := wordText(redirect.Word)
+	switch redirect.Op {
+	case syntax.RdrIn, syntax.DplIn, syntax.Hdoc, syntax.DashHdoc, syntax.WordHdoc:
+		return nil
+	case syntax.DplOut:
+		if target == "-" || strings.Trim(target, "0123456789") == "" {
+			return nil
+		}
+	case syntax.RdrOut, syntax.AppOut, syntax.ClbOut, syntax.RdrAll, syntax.AppAll:
+		if target == "/dev/null" {
+			return nil
+		}
+	}
+	return workflow.Errorf(workflow.ErrorSandboxViolation, "read-only mode: output redirection is not allowed")
+}
+
+// checkCall checks a single command against the allowlist and the write
+// flags of its program
+func checkCall(call *syntax.CallExpr) error {
+	if len(call.Args) == 0 {
+		return nil
+	}
+
+	program, ok := literal(call.Args[0])
+	if !ok || strings.Contains(program, "/") {
+		return workflow.Errorf(workflow.ErrorSandboxViolation, "read-only mode: %s is not an allowed command", wordText(call.Args[0]))
+	}
+	if !readOnlyCommands[program] {
+		return workflow.Errorf(workflow.ErrorSandboxViolation, "read-only mode: %s is not an allowed command", program)
+	}
+
+	args := make([]string, 0, len(call.Args)-1)
+	for _, word := range call.Args[1:] {
+		args = append(args, wordText(word))
+	}
+
+	switch program {
+	case "git":
+		return checkGit(args)
+	case "go":
+		return checkGo(args)
+	case "find":
+		for _, arg := range args {
+			if readOnlyFindActions[arg] {
+				return workflow.Errorf(workflow.ErrorSandboxViolation, "read-only mode: find %s is not allowed", arg)
+			}
+		}
+	case "env":
+		for _, arg := range args {
+			if arg != "-0" && arg != "--null" {
+				return workflow.Errorf(workflow.ErrorSandboxViolation, "read-only mode: env may only print the environment")
+			}
+		}
+	case "sed":
+		scripts, operands, err := writeFlags[program].parse(program, args)
+		if err != nil {
+			return err
+		}
+		if len(scripts) == 0 && len(operands) > 0 {
+			scripts = operands[:1]
+		}
+		for _, script := range scripts {
+			if err := checkSedScript(script); err != nil {
+				return err
+			}
+		}
+	case "awk":
+		scripts, operands, err := writeFlags[program].parse(program, args)
+		if err != nil {
+			return err
+		}
+		// Without -e every operand may be the program, so all are checked
+		for _, script := range append(scripts, operands...) {
+			if err := checkAwkProgram(script); err != nil {
+				return err
+			}
+		}
+	case "uniq":
+		_, operands, err := writeFlags[program].parse(program, args)
+		if err != nil {
+			return err
+		}
+		if len(operands) > 1 {
+			return workflow.Errorf(workflow.ErrorSandboxViolation, "read-only mode: uniq may not write an output file")
+		}
+	default:
+		if rules, ok := writeFlags[program]; ok {
+			_, _, err := rules.parse(program, args)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkGit allows read-only git subcommands without config overrides or
+// flags that write files
+func checkGit(args []string) error {
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			break
+		}
+		if arg == "-c" || strings.HasPrefix(arg, "--config-env") || strings.HasPrefix(arg, "--exec-path=") {
+			return workflow.Errorf(workflow.ErrorSandboxViolation, "read-only mode: git %s is not allowed", arg)
+		}
+	}
+
+	sub := firstNonFlag(args)
+	if !readOnlyGitCommands[sub] {
+		return workflow.Errorf(workflow.ErrorSandboxViolation, "read-only mode: git %s is not allowed", sub)
+	}
+	// git branch only lists branches without further arguments
+	if sub == "branch" {
+		for _, arg := range args {
+			if arg != "branch" && arg != "-a" && arg != "-r" && arg != "-v" && arg != "-vv" && arg != "--list" {
+				return workflow.Errorf(workflow.ErrorSandboxViolation, "read-only mode: git branch %s is not allowed", arg)
+			}
+		}
+	}
+
+	rules := writeFlags["git"]
+	if sub == "grep" {
+		// git grep -O opens the matches in a pager or editor
+		rules.deny, rules.values = "O", "efABCm"
+	}
+	_, _, err := rules.parse("git "+sub, args)
+	return err
+}
+
+// checkGo allows read-only go subcommands without flags that run other
+// programs, write files or modify go.mod
+func checkGo(args []string) error {
+	sub := firstNonFlag(args)
+	if !readOnlyGoCommands[sub] {
+		return workflow.Errorf(workflow.ErrorSandboxViolation, "read-only mode: go %s is not allowed", sub)
+	}
+
+	for i, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !hasValue && i+1 < len(args) {
+			value = args[i+1]
+		}
+		if goWriteFlags[name] || (sub == "env" && (name == "w" || name == "u")) || (name == "mod" && value == "mod") {
+			return workflow.Errorf(workflow.ErrorSandboxViolation, "read-only mode: go %s %s is not allowed", sub, arg)
+		}
+	}
+	return nil
+}
+
+// literal returns the value of a word made only of literal text and quotes
+func literal(word *syntax.Word) (string, bool) {
+	var text strings.Builder
+	for _, part := range word.Parts {
+		switch p := part.(type) {
+		case *syntax.Lit:
+			text.WriteString(p.Value)
+		case *syntax.SglQuoted:
+			text.WriteString(p.Value)
+		case *syntax.DblQuoted:
+			for _, inner := range p.Parts {
+				lit, ok := inner.(*syntax.Lit)
+				if !ok {
+					return "", false
+				}
+				text.WriteString(lit.Value)
+			}
+		default:
+			return "", false
+		}
+	}
+	return text.String(), true
+}
+
+// wordText returns the value of a word with quotes removed. Expansions
+// are kept as a bare $ since their values are not known before running.
+func wordText(word *syntax.Word) string {
+	if word == nil {
+		return ""
+	}
+	var text strings.Builder
+	var add func(parts []syntax.WordPart)
+	add = func(parts []syntax.WordPart) {
+		for _, part := range parts {
+			switch p := part.(type) {
+			case *syntax.Lit:
+				text.WriteString(p.Value)
+			case *syntax.SglQuoted:
+				text.WriteString(p.Value)
+			case *syntax.DblQuoted:
+				add(p.Parts)
+			default:
+				text.WriteString("$")
+			}
+		}
+	}
+	add(word.Parts)
+	return text.String()
+}
+
+// firstNonFlag returns the first argument not starting with a dash
+func firstNonFlag(args []string) string {
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			return arg
+		}
+	}
+	return ""
+}
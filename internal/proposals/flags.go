@@ -0,0 +1,84 @@
+package proposals
+
+import (
+	"strings"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// flagRules describes the options of a program that may write files or run
+// other programs, following getopt conventions
+type flagRules struct {
+	deny       string   // short flags that write files or run programs
+	values     string   // short flags that take a value
+	script     byte     // short flag whose value is a script, like sed -e
+	long       []string // long flags that write files or run programs
+	longValues []string // long flags that take a value
+	longScript string   // long form of the script flag
+}
+
+// parse walks the arguments of a program, rejecting denied flags, and
+// returns the script values and the operands. Long flags match by prefix
+// since getopt accepts abbreviations like --out for --output.
+func (r flagRules) parse(program string, args []string) (scripts, operands []string, err error) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return scripts, append(operands, args[i+1:]...), nil
+		case strings.HasPrefix(arg, "--"):
+			name, value, hasValue := strings.Cut(arg[2:], "=")
+			switch {
+			case abbreviates(r.long, name):
+				return nil, nil, workflow.Errorf(workflow.ErrorSandboxViolation, "read-only mode: %s %s is not allowed", program, arg)
+			case r.longScript != "" && strings.HasPrefix(r.longScript, name):
+				if !hasValue && i+1 < len(args) {
+					i++
+					value = args[i]
+				}
+				scripts = append(scripts, value)
+			case abbreviates(r.longValues, name) && !hasValue:
+				i++
+			}
+		case strings.HasPrefix(arg, "-") && arg != "-":
+			cluster := arg[1:]
+			for j := 0; j < len(cluster); j++ {
+				flag := cluster[j]
+				if strings.IndexByte(r.deny, flag) >= 0 {
+					return nil, nil, workflow.Errorf(workflow.ErrorSandboxViolation, "read-only mode: %s -%c is not allowed", program, flag)
+				}
+				if flag != r.script && strings.IndexByte(r.values, flag) < 0 {
+					continue
+				}
+
+				// The value is the rest of the cluster or the next argument
+				value := cluster[j+1:]
+				if value == "" && i+1 < len(args) {
+					i++
+					value = args[i]
+				}
+				if flag == r.script {
+					scripts = append(scripts, value)
+				}
+				break
+			}
+		default:
+			operands = append(operands, arg)
+		}
+	}
+	return scripts, operands, nil
+}
+
+// abbreviates reports whether name is one of the flags or an abbreviation
+// of one
+func abbreviates(flags []string, name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, flag := range flags {
+		if strings.HasPrefix(flag, name) {
+			return true
+		}
+	}
+	return false
+}
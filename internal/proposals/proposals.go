@@ -0,0 +1,346 @@
+// Package proposals collects the file changes agents propose in read-only
+// runs. Instead of touching the repository, writes and edits are stored as
+// proposals under the session's proposals/ directory, one per task and
+// file, for an operator to review.
+package proposals
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/diff"
+)
+
+// Status represents the review status of a proposal
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusApplied  Status = "applied"
+	StatusRejected Status = "rejected"
+)
+
+// Proposal is a proposed change to a single file
+type Proposal struct {
+	ID     string `json:"id"`
+	TaskID string `json:"task_id"`
+	Path   string `json:"path"`
+	// Original is the file content when the change was first proposed;
+	// Existed is false if the file did not exist
+	Original  string    `json:"original"`
+	Existed   bool      `json:"existed"`
+	Proposed  string    `json:"proposed"`
+	Diff      string    `json:"diff"`
+	Status    Status    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Store persists proposals as JSON files in a directory
+type Store struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewStore returns the proposal store of a swarm directory
+func NewStore(swarmDir string) *Store {
+	return &Store{dir: filepath.Join(swarmDir, "proposals")}
+}
+
+// Dir returns the directory holding the proposals
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Propose records new content for a file. Repeated changes by the same task
+// to the same file update its pending proposal, so the diff always shows
+// the cumulative change against the original content. Paths are made
+// absolute, so applying a proposal doesn't depend on the working directory.
+func (s *Store) Propose(taskID, path, content string) (*Proposal, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve path: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	proposal, err := s.pending(taskID, path)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if proposal == nil {
+		original, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read original file: %w", err)
+		}
+
+		proposal = &Proposal{
+			ID:        proposalID(taskID, path),
+			TaskID:    taskID,
+			Path:      path,
+			Original:  string(original),
+			Existed:   err == nil,
+			Status:    StatusPending,
+			CreatedAt: now,
+		}
+	}
+
+	proposal.Proposed = content
+	proposal.Diff = diff.UnifiedFile(path, proposal.Original, content)
+	proposal.UpdatedAt = now
+
+	if err := s.save(proposal); err != nil {
+		return nil, err
+	}
+
+	return proposal, nil
+}
+
+// Current returns the content a task has proposed for a file, if any
+func (s *Store) Current(taskID, path string) (string, bool) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	proposal, err := s.pending(taskID, path)
+	if err != nil || proposal == nil {
+		return "", false
+	}
+
+	return proposal.Proposed, true
+}
+
+// Get loads a proposal by ID
+func (s *Store) Get(id string) (*Proposal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.load(id)
+}
+
+// List returns all proposals, oldest first
+func (s *Store) List() ([]*Proposal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read proposals directory: %w", err)
+	}
+
+	proposals := []*Proposal{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+
+		proposal, err := s.load(strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			return nil, err
+		}
+		proposals = append(proposals, proposal)
+	}
+
+	sort.Slice(proposals, func(i, j int) bool {
+		return proposals[i].CreatedAt.Before(proposals[j].CreatedAt)
+	})
+
+	return proposals, nil
+}
+
+// Pending returns the proposals awaiting review, oldest first
+func (s *Store) Pending() ([]*Proposal, error) {
+	all, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	pending := []*Proposal{}
+	for _, proposal := range all {
+		if proposal.Status == StatusPending {
+			pending = append(pending, proposal)
+		}
+	}
+
+	return pending, nil
+}
+
+// ByTask groups proposals into one patch set per task
+func ByTask(proposals []*Proposal) map[string][]*Proposal {
+	sets := make(map[string][]*Proposal)
+	for _, proposal := range proposals {
+		sets[proposal.TaskID] = append(sets[proposal.TaskID], proposal)
+	}
+	return sets
+}
+
+// Apply writes a pending proposal to disk. It fails without touching the
+// file if the file changed since the proposal was made.
+func (s *Store) Apply(id string) (*Proposal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	proposal, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	if proposal.Status != StatusPending {
+		return nil, fmt.Errorf("proposal %s is already %s", id, proposal.Status)
+	}
+
+	current, err := os.ReadFile(proposal.Path)
+	exists := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", proposal.Path, err)
+	}
+	if exists != proposal.Existed || string(current) != proposal.Original {
+		return nil, fmt.Errorf("proposal %s conflicts: %s changed since the proposal was made", id, proposal.Path)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(proposal.Path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(proposal.Path, []byte(proposal.Proposed), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", proposal.Path, err)
+	}
+
+	proposal.Status = StatusApplied
+	proposal.UpdatedAt = time.Now()
+	if err := s.save(proposal); err != nil {
+		return nil, err
+	}
+
+	return proposal, nil
+}
+
+// Reject marks a pending proposal as rejected
+func (s *Store) Reject(id string) (*Proposal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	proposal, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	if proposal.Status != StatusPending {
+		return nil, fmt.Errorf("proposal %s is already %s", id, proposal.Status)
+	}
+
+	proposal.Status = StatusRejected
+	proposal.UpdatedAt = time.Now()
+	if err := s.save(proposal); err != nil {
+		return nil, err
+	}
+
+	return proposal, nil
+}
+
+// LineStats returns the number of added and removed lines in the diff
+func (p *Proposal) LineStats() (added, removed int) {
+	inHunk := false
+	for _, line := range strings.Split(p.Diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case !inHunk:
+			// File headers
+		case strings.HasPrefix(line, "+"):
+			added++
+		case strings.HasPrefix(line, "-"):
+			removed++
+		}
+	}
+	return added, removed
+}
+
+// pending loads the pending proposal of a task for a file (must be called with lock held)
+func (s *Store) pending(taskID, path string) (*Proposal, error) {
+	proposal, err := s.load(proposalID(taskID, path))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if proposal.Status != StatusPending {
+		return nil, nil
+	}
+
+	return proposal, nil
+}
+
+// load reads a proposal file (must be called with lock held)
+func (s *Store) load(id string) (*Proposal, error) {
+	data, err := os.ReadFile(filepath.Join(s.dir, id+".json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("proposal %s not found: %w", id, os.ErrNotExist)
+		}
+		return nil, fmt.Errorf("failed to read proposal: %w", err)
+	}
+
+	var proposal Proposal
+	if err := json.Unmarshal(data, &proposal); err != nil {
+		return nil, fmt.Errorf("failed to parse proposal %s: %w", id, err)
+	}
+
+	return &proposal, nil
+}
+
+// save writes a proposal file atomically (must be called with lock held)
+func (s *Store) save(proposal *Proposal) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create proposals directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(proposal, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal proposal: %w", err)
+	}
+
+	path := filepath.Join(s.dir, proposal.ID+".json")
+	tmpFile := filepath.Join(s.dir, "."+proposal.ID+".json.tmp")
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write proposal: %w", err)
+	}
+	if err := os.Rename(tmpFile, path); err != nil {
+		return fmt.Errorf("failed to write proposal: %w", err)
+	}
+
+	return nil
+}
+
+// proposalID derives a stable, file-name safe ID from a task and path
+func proposalID(taskID, path string) string {
+	cleaned := filepath.Clean(path)
+	sum := sha256.Sum256([]byte(cleaned))
+	safe := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, filepath.Base(cleaned))
+
+	return fmt.Sprintf("%s--%s-%s", taskID, safe, hex.EncodeToString(sum[:4]))
+}
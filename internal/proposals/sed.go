@@ -0,0 +1,151 @@
+package proposals
+
+import (
+	"strings"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// checkSedScript rejects sed scripts that write files (w, W and the s///w
+// flag) or run commands (e and the s///e flag). Commands that cannot be
+// recognized are rejected too, so an unusual script fails closed.
+func checkSedScript(script string) error {
+	invalid := workflow.Errorf(workflow.ErrorSandboxViolation, "read-only mode: cannot verify sed script %q", script)
+
+	for i := 0; i < len(script); {
+		c := script[i]
+		switch c {
+		case ' ', '\t', '\n', ';', '}':
+			i++
+			continue
+		case '#':
+			i = sedLineEnd(script, i)
+			continue
+		}
+
+		var ok bool
+		if i, ok = sedAddress(script, i); !ok {
+			return invalid
+		}
+		if i < len(script) && script[i] == ',' {
+			if i, ok = sedAddress(script, skipBlanks(script, i+1)); !ok {
+				return invalid
+			}
+		}
+		for i = skipBlanks(script, i); i < len(script) && script[i] == '!'; i = skipBlanks(script, i+1) {
+		}
+		if i >= len(script) {
+			break
+		}
+
+		cmd := script[i]
+		i++
+		switch cmd {
+		case 'w', 'W', 'e':
+			return workflow.Errorf(workflow.ErrorSandboxViolation, "read-only mode: sed %c may not write files or run commands", cmd)
+		case 's':
+			var flags string
+			if i, ok = sedDelimited(script, i, 2); !ok {
+				return invalid
+			}
+			for ; i < len(script) && !strings.ContainsRune(";\n}#", rune(script[i])); i++ {
+				flags += string(script[i])
+			}
+			if strings.ContainsAny(flags, "we") {
+				return workflow.Errorf(workflow.ErrorSandboxViolation, "read-only mode: sed s///w and s///e are not allowed")
+			}
+		case 'y':
+			if i, ok = sedDelimited(script, i, 2); !ok {
+				return invalid
+			}
+		case 'a', 'i', 'c', 'r', 'R':
+			// Text and file names run to the end of the line
+			i = sedLineEnd(script, i)
+		case 'b', 't', 'T', ':', 'v':
+			for i < len(script) && script[i] != ';' && script[i] != '\n' {
+				i++
+			}
+		case 'q', 'Q', 'l', 'L':
+			for i = skipBlanks(script, i); i < len(script) && isDigit(script[i]); i++ {
+			}
+		case '{', '=', 'd', 'D', 'g', 'G', 'h', 'H', 'n', 'N', 'p', 'P', 'x', 'z', 'F':
+		default:
+			return invalid
+		}
+	}
+
+	return nil
+}
+
+// sedAddress skips an optional line number, $, /regex/ or +N address
+func sedAddress(script string, i int) (int, bool) {
+	if i >= len(script) {
+		return i, true
+	}
+
+	switch c := script[i]; {
+	case isDigit(c), c == '+', c == '~':
+		for i++; i < len(script) && (isDigit(script[i]) || script[i] == '~'); i++ {
+		}
+	case c == '$':
+		i++
+	case c == '/', c == '\\':
+		if c == '\\' {
+			i++
+		}
+		var ok bool
+		if i, ok = sedDelimited(script, i, 1); !ok {
+			return i, false
+		}
+		for i < len(script) && (script[i] == 'I' || script[i] == 'M') {
+			i++
+		}
+	}
+	return i, true
+}
+
+// sedDelimited skips parts delimited by the character at i, like the
+// regex and replacement of s/a/b/
+func sedDelimited(script string, i, parts int) (int, bool) {
+	if i >= len(script) || script[i] == '\n' || script[i] == '\\' {
+		return i, false
+	}
+
+	delim := script[i]
+	for i++; parts > 0; i++ {
+		if i >= len(script) {
+			return i, false
+		}
+		switch script[i] {
+		case '\\':
+			i++
+		case delim:
+			parts--
+		}
+	}
+	return i, true
+}
+
+// sedLineEnd returns the end of the line at i, following backslash
+// continuations
+func sedLineEnd(script string, i int) int {
+	for ; i < len(script) && script[i] != '\n'; i++ {
+		if script[i] == '\\' {
+			i++
+		}
+	}
+	return i
+}
+
+// skipBlanks skips spaces and tabs
+func skipBlanks(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+		i++
+	}
+	return i
+}
+
+// isDigit reports whether c is an ASCII digit
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
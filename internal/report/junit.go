@@ -0,0 +1,99 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Time     float64      `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name      string      `xml:"name,attr"`
+	Tests     int         `xml:"tests,attr"`
+	Failures  int         `xml:"failures,attr"`
+	Skipped   int         `xml:"skipped,attr"`
+	Time      float64     `xml:"time,attr"`
+	Timestamp string      `xml:"timestamp,attr"`
+	Cases     []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      float64       `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the result as a JUnit XML file, one test case per
+// task. Failed tasks, and tasks cut off by the run stopping, are failures;
+// tasks that never ran are skipped.
+func (r *Result) WriteJUnit(path string) error {
+	suite := junitSuite{
+		Name:      r.Workflow,
+		Tests:     len(r.Tasks),
+		Time:      r.Duration,
+		Timestamp: r.StartedAt.Format("2006-01-02T15:04:05"),
+	}
+
+	for _, task := range r.Tasks {
+		tc := junitCase{
+			Name:      task.ID,
+			Classname: r.Workflow,
+			Time:      task.Duration,
+			SystemOut: task.Output,
+		}
+
+		switch task.Status {
+		case workflow.TaskStatusFailed, workflow.TaskStatusQuarantined, workflow.TaskStatusRunning:
+			tc.Failure = &junitMessage{Message: firstLine(task.Error), Text: task.Error}
+			suite.Failures++
+		case TaskStatusSkipped, workflow.TaskStatusPending:
+			tc.Skipped = &junitMessage{Message: "not run"}
+			suite.Skipped++
+		case workflow.TaskStatusCancelled:
+			tc.Skipped = &junitMessage{Message: "cancelled"}
+			suite.Skipped++
+		}
+
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	suites := junitSuites{
+		Name:     r.Workflow,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Skipped:  suite.Skipped,
+		Time:     suite.Time,
+		Suites:   []junitSuite{suite},
+	}
+
+	data, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode JUnit results: %w", err)
+	}
+	data = append([]byte(xml.Header), data...)
+	data = append(data, '\n')
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write JUnit results: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package report
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// maxSummaryOutput keeps one task's output from dominating the summary
+const maxSummaryOutput = 4000
+
+// statusIcons label task statuses in summaries
+var statusIcons = map[workflow.TaskStatus]string{
+	workflow.TaskStatusCompleted:   "✅ completed",
+	workflow.TaskStatusFailed:      "❌ failed",
+	workflow.TaskStatusQuarantined: "☣️ quarantined",
+	workflow.TaskStatusRunning:     "⏱️ unfinished",
+	workflow.TaskStatusPending:     "⏭️ skipped",
+	workflow.TaskStatusCancelled:   "🚫 cancelled",
+	TaskStatusSkipped:              "⏭️ skipped",
+}
+
+// interimIcons label the statuses of tasks not done yet in interim reports
+var interimIcons = map[workflow.TaskStatus]string{
+	workflow.TaskStatusRunning: "⏱️ running",
+	workflow.TaskStatusPending: "⏳ pending",
+}
+
+// Markdown formats the result as a Markdown summary
+func (r *Result) Markdown() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "## Swarm: %s — %s\n\n", r.Workflow, r.Status)
+	fmt.Fprintf(&b, "%d of %d tasks completed, %d failed, in %s.\n\n",
+		r.Count(workflow.TaskStatusCompleted), len(r.Tasks),
+		r.Count(workflow.TaskStatusFailed)+r.Count(workflow.TaskStatusQuarantined), formatSeconds(r.Duration))
+	if r.Usage != nil {
+		fmt.Fprintf(&b, "Agents reported %s.\n\n", workflow.FormatUsage(*r.Usage))
+	}
+
+	b.WriteString("| Task | Status | Duration |\n")
+	b.WriteString("|------|--------|----------|\n")
+	for _, task := range r.Tasks {
+		duration := "-"
+		if task.Duration > 0 {
+			duration = formatSeconds(task.Duration)
+		}
+		label := statusIcons[task.Status]
+		if interim, ok := interimIcons[task.Status]; ok && r.Status == StatusRunning {
+			label = interim
+		}
+		if summary := task.Completion.Summary(); summary != "" {
+			label += " (" + summary + ")"
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s |\n", task.ID, label, duration)
+	}
+
+	for _, task := range r.Tasks {
+		switch {
+		case task.Error != "":
+			fmt.Fprintf(&b, "\n<details><summary>%s: error</summary>\n\n```\n%s\n```\n</details>\n", task.ID, task.Error)
+		case task.Output != "":
+			output := task.Output
+			if len(output) > maxSummaryOutput {
+				output = output[:maxSummaryOutput] + "\n[truncated]"
+			}
+			if completion := task.Completion.Describe(); completion != "" {
+				output = strings.ReplaceAll(strings.TrimSuffix(completion, "\n"), "\n", "  \n") + "\n\n" + output
+			}
+			fmt.Fprintf(&b, "\n<details><summary>%s: output</summary>\n\n%s\n</details>\n", task.ID, output)
+		}
+	}
+
+	return b.String()
+}
+
+// WriteMarkdown appends the Markdown summary to a file, which is how
+// GitHub Actions collects job summaries
+func (r *Result) WriteMarkdown(path string) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open summary: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(r.Markdown()); err != nil {
+		return fmt.Errorf("failed to write summary: %w", err)
+	}
+	return nil
+}
+
+// formatSeconds formats a duration in seconds, rounded to the second
+func formatSeconds(seconds float64) string {
+	return (time.Duration(seconds * float64(time.Second))).Round(time.Second).String()
+}
+
+// firstLine returns the first line of a message
+func firstLine(message string) string {
+	line, _, _ := strings.Cut(message, "\n")
+	return line
+}
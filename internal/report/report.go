@@ -0,0 +1,108 @@
+// Package report summarizes the outcome of a workflow run for machines and
+// people: as a JSON result, a JUnit XML file for CI test reporting, and a
+// Markdown summary such as a GitHub Actions job summary.
+package report
+
+import (
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/state"
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// Run outcomes
+const (
+	StatusPassed      = "passed"
+	StatusFailed      = "failed"
+	StatusTimeout     = "timeout"
+	StatusInterrupted = "interrupted"
+	StatusCancelled   = "cancelled"
+	StatusRunning     = "running" // Still going, for interim reports
+)
+
+// TaskStatusSkipped marks tasks that never ran, because the run stopped,
+// a dependency failed or an operator skipped them
+const TaskStatusSkipped = workflow.TaskStatusSkipped
+
+// Task is the outcome of one task
+type Task struct {
+	ID          string              `json:"id"`
+	Description string              `json:"description,omitempty"`
+	Status      workflow.TaskStatus `json:"status"`
+	Duration    float64             `json:"duration_seconds"`
+	Output      string              `json:"output,omitempty"`
+	Error       string              `json:"error,omitempty"`
+	// Completion is the files changed, test outcome and notes the agent
+	// reported
+	Completion *workflow.Completion `json:"completion,omitempty"`
+}
+
+// Result is the outcome of a workflow run
+type Result struct {
+	Workflow  string    `json:"workflow"`
+	Session   string    `json:"session"`
+	Status    string    `json:"status"`
+	StartedAt time.Time `json:"started_at"`
+	Duration  float64   `json:"duration_seconds"`
+	Tasks     []Task    `json:"tasks"`
+	// Usage is the tokens and cost agents reported, if they did
+	Usage *workflow.TokenUsage `json:"usage,omitempty"`
+}
+
+// FromState builds the result of a run from its final state
+func FromState(s *state.SwarmState, status string) *Result {
+	now := time.Now()
+	result := &Result{
+		Workflow:  s.Workflow.Name,
+		Session:   s.SessionID,
+		Status:    status,
+		StartedAt: s.StartedAt,
+		Duration:  now.Sub(s.StartedAt).Seconds(),
+	}
+	if usage := s.TokenUsage(); usage.Tokens() > 0 || usage.CostUSD > 0 {
+		result.Usage = &usage
+	}
+
+	for _, task := range s.GetTasks() {
+		entry := Task{
+			ID:          task.ID,
+			Description: task.Description,
+			Status:      TaskStatusSkipped,
+		}
+		if status == StatusRunning {
+			entry.Status = workflow.TaskStatusPending
+		}
+
+		if agent := s.GetAgent(task.ID); agent != nil {
+			entry.Status = agent.Status
+			entry.Output = agent.Output
+			entry.Error = agent.Error
+			entry.Completion = agent.Completion
+
+			finished := agent.FinishedAt
+			if finished.IsZero() {
+				finished = now
+			}
+			entry.Duration = finished.Sub(agent.StartedAt).Seconds()
+
+			if agent.Status == workflow.TaskStatusRunning && status != StatusPassed && status != StatusRunning {
+				entry.Error = "did not finish before the run stopped"
+			}
+		}
+
+		result.Tasks = append(result.Tasks, entry)
+	}
+
+	return result
+}
+
+// Count returns how many tasks ended with a status
+func (r *Result) Count(status workflow.TaskStatus) int {
+	count := 0
+	for _, task := range r.Tasks {
+		if task.Status == status {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,123 @@
+// Package retention prunes the artifacts agents leave in a session
+// directory: their contexts, message and question files, and anything
+// else they write into their agent directory. Only finished agents are
+// pruned, and the files that record how they finished are kept.
+package retention
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// kept are the files recording how an agent finished, never pruned
+var kept = map[string]bool{
+	"COMPLETE":   true,
+	"FAILED":     true,
+	"status.txt": true,
+	"output.txt": true,
+	"error.txt":  true,
+}
+
+// Policy limits a session's artifacts. Zero means unlimited.
+type Policy struct {
+	MaxBytes int64
+	MaxAge   time.Duration
+}
+
+// Result describes what a prune removed and what is left
+type Result struct {
+	Files int
+	Bytes int64
+	// Remaining is the size of the artifacts left, including those of
+	// running agents, which are never pruned
+	Remaining int64
+}
+
+type artifact struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// Prune removes artifacts of finished agents under swarmDir that are older
+// than the policy's maximum age, then the oldest ones until the session is
+// within its size cap
+func Prune(swarmDir string, policy Policy, now time.Time) (Result, error) {
+	var result Result
+
+	agentDirs, err := filepath.Glob(filepath.Join(swarmDir, "agents", "agent-*"))
+	if err != nil {
+		return result, fmt.Errorf("failed to list agent directories: %w", err)
+	}
+
+	var prunable []artifact
+	for _, agentDir := range agentDirs {
+		finished := isFinished(agentDir)
+		err := filepath.WalkDir(agentDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+
+			result.Remaining += info.Size()
+			if finished && !(filepath.Dir(path) == agentDir && kept[d.Name()]) {
+				prunable = append(prunable, artifact{path: path, size: info.Size(), modTime: info.ModTime()})
+			}
+			return nil
+		})
+		if err != nil {
+			return result, fmt.Errorf("failed to scan %s: %w", agentDir, err)
+		}
+	}
+
+	sort.Slice(prunable, func(i, j int) bool {
+		return prunable[i].modTime.Before(prunable[j].modTime)
+	})
+
+	for _, a := range prunable {
+		expired := policy.MaxAge > 0 && now.Sub(a.modTime) > policy.MaxAge
+		oversize := policy.MaxBytes > 0 && result.Remaining > policy.MaxBytes
+		if !expired && !oversize {
+			continue
+		}
+		if err := os.Remove(a.path); err != nil && !os.IsNotExist(err) {
+			return result, fmt.Errorf("failed to prune %s: %w", a.path, err)
+		}
+		result.Files++
+		result.Bytes += a.size
+		result.Remaining -= a.size
+	}
+
+	return result, nil
+}
+
+// isFinished reports whether an agent completed or failed
+func isFinished(agentDir string) bool {
+	for _, marker := range []string{"COMPLETE", "FAILED"} {
+		if _, err := os.Stat(filepath.Join(agentDir, marker)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatBytes formats a size for messages, as in "1.5 MB"
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
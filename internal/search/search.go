@@ -0,0 +1,215 @@
+// Package search finds text across everything a session's agents left
+// behind: outputs, errors, questions and answers, and their transcripts.
+package search
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/aristath/claude-swarm/internal/state"
+)
+
+// maxText is how much of a matching line is kept, in bytes
+const maxText = 200
+
+// Match is a line matching the query
+type Match struct {
+	Task string `json:"task"`
+	// Source is where the line is from: output, error, question 2,
+	// answer 2, agent.log, messages/msg-3.json...
+	Source string `json:"source"`
+	Line   int    `json:"line"`
+	Text   string `json:"text"`
+}
+
+// Options narrow a search
+type Options struct {
+	// Regexp treats the query as a regular expression; otherwise it is a
+	// case-insensitive substring
+	Regexp bool
+	// Task limits the search to one task
+	Task string
+	// Limit caps the number of matches; 0 means no limit
+	Limit int
+}
+
+// Session searches the tasks of a session, in workflow order. Agent
+// directories under swarmDir are searched for transcripts; an empty
+// swarmDir searches the state only.
+func Session(swarmDir string, s *state.SwarmState, query string, opts Options) ([]Match, error) {
+	match, err := matcher(query, opts.Regexp)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []Match
+	for _, task := range s.GetTasks() {
+		if opts.Task != "" && task.ID != opts.Task {
+			continue
+		}
+
+		for _, doc := range documents(swarmDir, s, task.ID) {
+			if doc.text == "" {
+				continue
+			}
+			for i, line := range strings.Split(doc.text, "\n") {
+				if !match(line) {
+					continue
+				}
+				matches = append(matches, Match{Task: task.ID, Source: doc.source, Line: i + 1, Text: trim(line)})
+				if opts.Limit > 0 && len(matches) >= opts.Limit {
+					return matches, nil
+				}
+			}
+		}
+	}
+	return matches, nil
+}
+
+// matcher returns a function reporting whether a line matches the query
+func matcher(query string, useRegexp bool) (func(string) bool, error) {
+	if query == "" {
+		return nil, fmt.Errorf("query is required")
+	}
+	if useRegexp {
+		re, err := regexp.Compile(query)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression: %w", err)
+		}
+		return re.MatchString, nil
+	}
+	query = strings.ToLower(query)
+	return func(line string) bool {
+		return strings.Contains(strings.ToLower(line), query)
+	}, nil
+}
+
+// document is a searchable text from a task
+type document struct {
+	source string
+	text   string
+}
+
+// documents returns everything searchable for a task
+func documents(swarmDir string, s *state.SwarmState, taskID string) []document {
+	var docs []document
+
+	for i, output := range s.GetIterations(taskID) {
+		docs = append(docs, document{fmt.Sprintf("output (iteration %d)", i+1), output})
+	}
+
+	if agent := s.GetAgent(taskID); agent != nil {
+		docs = append(docs,
+			document{"output", agent.Output},
+			document{"error", agent.Error},
+		)
+		for _, q := range agent.AllQuestions() {
+			docs = append(docs,
+				document{fmt.Sprintf("question %d", q.ID), q.Text},
+				document{fmt.Sprintf("answer %d", q.ID), q.Answer},
+			)
+		}
+		for _, f := range agent.FollowUps {
+			docs = append(docs,
+				document{fmt.Sprintf("follow-up %d", f.ID), f.Text},
+				document{fmt.Sprintf("follow-up answer %d", f.ID), f.Answer},
+			)
+		}
+	}
+
+	if swarmDir != "" {
+		docs = append(docs, transcripts(filepath.Join(swarmDir, "agents", "agent-"+taskID))...)
+	}
+	return docs
+}
+
+// transcripts returns the agent's log and the messages it exchanged with
+// the orchestrator, oldest first
+func transcripts(agentDir string) []document {
+	var docs []document
+
+	if text, ok := readFile(filepath.Join(agentDir, "agent.log")); ok {
+		docs = append(docs, document{"agent.log", text})
+	}
+
+	for _, dir := range []string{"messages", "responses"} {
+		entries, err := os.ReadDir(filepath.Join(agentDir, dir))
+		if err != nil {
+			continue
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return lessNatural(entries[i].Name(), entries[j].Name())
+		})
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+				continue
+			}
+			if text, ok := readFile(filepath.Join(agentDir, dir, entry.Name())); ok {
+				docs = append(docs, document{dir + "/" + entry.Name(), text})
+			}
+		}
+	}
+	return docs
+}
+
+// readFile reads a text file
+func readFile(path string) (string, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// lessNatural orders msg-2.json before msg-10.json
+func lessNatural(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
+
+// trim shortens a matching line to maxText bytes
+func trim(line string) string {
+	line = strings.TrimSpace(line)
+	if len(line) <= maxText {
+		return line
+	}
+	return strings.ToValidUTF8(line[:maxText], "") + "…"
+}
+
+// Format lists matches grouped by task, e.g.
+//
+//	build
+//	  output:3: renamed parseConfig to loadConfig
+func Format(matches []Match) string {
+	var b strings.Builder
+	task := ""
+	for _, m := range matches {
+		if m.Task != task {
+			if task != "" {
+				b.WriteString("\n")
+			}
+			task = m.Task
+			b.WriteString(task)
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "  %s:%d: %s\n", m.Source, m.Line, m.Text)
+	}
+	return b.String()
+}
+
+// Tasks returns the tasks with matches, in order
+func Tasks(matches []Match) []string {
+	var tasks []string
+	for _, m := range matches {
+		if len(tasks) == 0 || tasks[len(tasks)-1] != m.Task {
+			tasks = append(tasks, m.Task)
+		}
+	}
+	return tasks
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// compress accepts gzip request bodies (Content-Encoding: gzip) and gzips
+// responses of at least workflow.CompressionThreshold bytes for clients
+// that send Accept-Encoding: gzip
+func (s *Server) compress(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.EqualFold(r.Header.Get("Content-Encoding"), "gzip") {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				s.jsonError(w, fmt.Sprintf("Invalid gzip body: %v", err), http.StatusBadRequest)
+				return
+			}
+			defer zr.Close()
+			r.Body = zr
+			r.Header.Del("Content-Encoding")
+			r.ContentLength = -1
+		}
+
+		if !acceptsGzip(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		buf := &bufferedResponse{header: w.Header(), status: http.StatusOK}
+		next.ServeHTTP(buf, r)
+
+		w.Header().Add("Vary", "Accept-Encoding")
+		body := buf.body.Bytes()
+		if len(body) >= workflow.CompressionThreshold {
+			var compressed bytes.Buffer
+			zw := gzip.NewWriter(&compressed)
+			if _, err := zw.Write(body); err == nil && zw.Close() == nil {
+				w.Header().Set("Content-Encoding", "gzip")
+				body = compressed.Bytes()
+			}
+		}
+
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(buf.status)
+		w.Write(body)
+	})
+}
+
+// acceptsGzip reports whether a request's Accept-Encoding allows gzip
+func acceptsGzip(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+			continue
+		}
+		return strings.ReplaceAll(strings.TrimSpace(params), " ", "") != "q=0"
+	}
+	return false
+}
+
+// bufferedResponse holds a response until it is known whether to compress it
+type bufferedResponse struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if b.wroteHeader {
+		return
+	}
+	b.status = status
+	b.wroteHeader = true
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	b.wroteHeader = true
+	return b.body.Write(p)
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+)
+
+// statusPaths are the read-only endpoints dashboards use. With embedding
+// they are served without the API token.
+var statusPaths = map[string]bool{
+	"/status":     true,
+	"/api/status": true,
+	"/api/events": true,
+	"/health":     true,
+}
+
+// SetCORSOrigins lets web pages from these origins, such as
+// https://portal.example.com, call the API; "*" allows any origin. Call it
+// before Start.
+func (s *Server) SetCORSOrigins(origins []string) {
+	s.corsOrigins = origins
+}
+
+// EnableEmbedding serves GET requests to the status endpoints without the
+// API token, and lets pages from the CORS origins, or any page without
+// them, frame the status page. Everything else still needs the token.
+func (s *Server) EnableEmbedding() {
+	s.embed = true
+}
+
+// allowsOrigin reports whether pages from origin may call the API
+func (s *Server) allowsOrigin(origin string) bool {
+	for _, allowed := range s.corsOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// frameAncestors returns who may frame the status page, as a CSP
+// frame-ancestors source list
+func (s *Server) frameAncestors() string {
+	if !s.embed {
+		return "'none'"
+	}
+	if len(s.corsOrigins) == 0 {
+		return "*"
+	}
+	return strings.Join(s.corsOrigins, " ")
+}
+
+// cors answers preflight requests and marks responses readable by pages
+// from the allowed origins
+func (s *Server) cors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" || !s.allowsOrigin(origin) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		header := w.Header()
+		header.Set("Access-Control-Allow-Origin", origin)
+		header.Add("Vary", "Origin")
+		header.Set("Access-Control-Expose-Headers", ProtocolHeader)
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			header.Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+			header.Set("Access-Control-Allow-Headers", "Authorization, Content-Type, Content-Encoding, "+ProtocolHeader)
+			header.Set("Access-Control-Max-Age", "600")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/state"
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// defaultEventLimit and maxEventLimit bound the events of a page
+const (
+	defaultEventLimit = 100
+	maxEventLimit     = 1000
+)
+
+// handleEvents serves the session's event history, oldest first, a page
+// at a time: GET /api/events?type=question_asked&agent=task3&after=<time>&limit=100.
+// Every page's next_cursor continues after its last event, so polling with
+// it picks up only the events logged since.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	params := r.URL.Query()
+	query := state.EventQuery{
+		AgentID: params.Get("agent"),
+		Limit:   defaultEventLimit,
+	}
+
+	// type may be repeated or comma-separated
+	var types []string
+	for _, value := range params["type"] {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				types = append(types, name)
+				query.Types = append(query.Types, workflow.EventType(name))
+			}
+		}
+	}
+
+	if value := params.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			s.jsonFailure(w, "", workflow.WithField("limit", workflow.Errorf(workflow.ErrorInvalidRequest, "invalid limit %q", value)))
+			return
+		}
+		query.Limit = min(limit, maxEventLimit)
+	}
+
+	if value := params.Get("after"); value != "" {
+		after, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			s.jsonFailure(w, "", workflow.WithField("after", workflow.Errorf(workflow.ErrorInvalidRequest, "invalid after %q, expected an RFC 3339 time", value)))
+			return
+		}
+		query.After = after
+	}
+
+	// Cursors hold the number of the last event returned, for the same
+	// filters only
+	key := []string{"events", query.AgentID, strings.Join(types, ","), params.Get("after")}
+	page, err := workflow.NewPagination(query.Limit, params.Get("cursor"), key...)
+	if err != nil {
+		s.jsonFailure(w, "", workflow.WithField("cursor", err))
+		return
+	}
+	query.AfterSeq = int64(page.Offset)
+
+	events, more := s.state.QueryEvents(query)
+	last := query.AfterSeq
+	if len(events) > 0 {
+		last = events[len(events)-1].Seq
+	}
+
+	data, err := json.Marshal(events)
+	if err != nil {
+		s.jsonFailure(w, "", fmt.Errorf("failed to marshal events: %w", err))
+		return
+	}
+	s.jsonResponse(w, APIResponse{
+		Success:    true,
+		Data:       string(data),
+		NextCursor: workflow.NewCursor(int(last), key...),
+		More:       more,
+	})
+}
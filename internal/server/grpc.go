@@ -0,0 +1,388 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os/exec"
+	"strconv"
+
+	"github.com/aristath/claude-swarm/internal/agentpb"
+	"github.com/aristath/claude-swarm/internal/proposals"
+	"github.com/aristath/claude-swarm/internal/state"
+	"github.com/aristath/claude-swarm/internal/version"
+	"github.com/aristath/claude-swarm/internal/workflow"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// EnableGRPC also serves the agent protocol of proto/agent/v1/agent.proto
+// over gRPC, on a port of its own
+func (s *Server) EnableGRPC(port int) {
+	s.grpcAddr = fmt.Sprintf(":%d", port)
+	s.grpcServer = grpc.NewServer(
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			grpc.SetHeader(ctx, protocolMetadata())
+			if err := s.authorizeCall(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			ss.SetHeader(protocolMetadata())
+			if err := s.authorizeCall(ss.Context()); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	)
+	agentpb.RegisterAgentServer(s.grpcServer, &agentService{server: s})
+}
+
+// serveGRPC starts the gRPC server, if enabled, reporting when it fails
+func (s *Server) serveGRPC(errCh chan<- error) error {
+	if s.grpcServer == nil {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", s.grpcAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for gRPC: %w", err)
+	}
+	go func() {
+		fmt.Printf("Starting gRPC server on %s\n", s.grpcAddr)
+		if err := s.grpcServer.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			errCh <- err
+		}
+	}()
+	return nil
+}
+
+// stopGRPC stops the gRPC server, ending its calls and killing the
+// commands they run
+func (s *Server) stopGRPC() {
+	if s.grpcServer != nil {
+		s.grpcServer.Stop()
+	}
+}
+
+// protocolMetadata stamps gRPC responses with the server's protocol version
+func protocolMetadata() metadata.MD {
+	return metadata.Pairs(ProtocolHeader, strconv.Itoa(version.ProtocolVersion))
+}
+
+// authorizeCall applies the HTTP API's token and protocol version checks
+// to a gRPC call
+func (s *Server) authorizeCall(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	if s.token != "" {
+		var auth string
+		if values := md.Get("authorization"); len(values) > 0 {
+			auth = values[0]
+		}
+		if subtle.ConstantTimeCompare([]byte(auth), []byte("Bearer "+s.token)) != 1 {
+			return status.Error(codes.Unauthenticated, "Unauthorized")
+		}
+	}
+
+	if values := md.Get(ProtocolHeader); len(values) > 0 {
+		peer, err := strconv.Atoi(values[0])
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "Invalid %s metadata: %q", ProtocolHeader, values[0])
+		}
+		if err := version.CheckProtocol(peer); err != nil {
+			return status.Error(codes.FailedPrecondition, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// agentService serves the gRPC agent protocol. Calls go through the HTTP
+// API's handlers, so both transports check, reserve and record operations
+// alike.
+type agentService struct {
+	agentpb.UnimplementedAgentServer
+	server *Server
+}
+
+func (a *agentService) Send(ctx context.Context, msg *agentpb.Message) (*agentpb.Response, error) {
+	endpoint, body := grpcRequest(msg)
+	if endpoint == "" {
+		resp := failedResponse(workflow.WithField("type", workflow.Errorf(workflow.ErrorInvalidRequest, "unknown message type %q", msg.Type)))
+		resp.MessageId = msg.Id
+		return resp, nil
+	}
+
+	resp := a.server.dispatch(ctx, endpoint, body)
+	resp.MessageId = msg.Id
+	return resp, nil
+}
+
+func (a *agentService) Ask(ctx context.Context, question *agentpb.Question) (*agentpb.Response, error) {
+	return a.server.dispatch(ctx, "/api/question", QuestionRequest{
+		AgentID:  question.AgentId,
+		Question: question.Question,
+		Thread:   int(question.Thread),
+	}), nil
+}
+
+func (a *agentService) Complete(ctx context.Context, completion *agentpb.Completion) (*agentpb.Response, error) {
+	if completion.Failed {
+		return a.server.dispatch(ctx, "/api/fail", FailRequest{
+			AgentID: completion.AgentId,
+			Error:   completion.Error,
+		}), nil
+	}
+	return a.server.dispatch(ctx, "/api/complete", CompleteRequest{
+		AgentID: completion.AgentId,
+		Output:  completion.Output,
+	}), nil
+}
+
+// Bash runs a command like the /api/bash endpoint, but streams its output
+// while it runs and feeds it the agent's input
+func (a *agentService) Bash(stream agentpb.Agent_BashServer) error {
+	s := a.server
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	agentID := first.AgentId
+
+	if err := s.state.ReserveOperation(agentID, state.OperationBash, "", 0); err != nil {
+		return s.bashFailed(stream, agentID, err)
+	}
+	if s.state.IsReadOnly() {
+		if err := proposals.CheckReadOnlyCommand(first.Command); err != nil {
+			return s.bashFailed(stream, agentID, err)
+		}
+	}
+
+	s.state.RecordBash()
+	cmd := exec.CommandContext(stream.Context(), "bash", "-c", s.state.InterpolateSecrets(first.Command))
+	if first.WorkingDir != "" {
+		cmd.Dir = first.WorkingDir
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return s.bashFailed(stream, agentID, err)
+	}
+	output, writer := io.Pipe()
+	cmd.Stdout, cmd.Stderr = writer, writer
+	if err := cmd.Start(); err != nil {
+		return s.bashFailed(stream, agentID, err)
+	}
+
+	go feedStdin(stream, stdin, first.Stdin)
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+		writer.Close()
+	}()
+
+	// Output is redacted a line at a time, so that secrets are not split
+	// between messages
+	reader := bufio.NewReaderSize(output, 64*1024)
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		if len(chunk) > 0 {
+			if sendErr := stream.Send(&agentpb.BashOutput{Output: []byte(s.state.Redact(string(chunk)))}); sendErr != nil {
+				output.Close()
+				return sendErr
+			}
+		}
+		if err != nil && !errors.Is(err, bufio.ErrBufferFull) {
+			break
+		}
+	}
+
+	result := &agentpb.BashOutput{Done: true}
+	if err := <-done; err != nil {
+		// A command exiting non-zero is an answer, not a failed operation
+		code := workflow.CodeOf(err)
+		if code != workflow.ErrorCommandFailed {
+			s.state.RecordOperationError(agentID, code)
+		}
+		result.Code = string(code)
+		result.Error = err.Error()
+
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			result.ExitCode = int32(exitErr.ExitCode())
+		}
+	}
+	return stream.Send(result)
+}
+
+// bashFailed ends a bash stream for a command that could not run
+func (s *Server) bashFailed(stream agentpb.Agent_BashServer, agentID string, err error) error {
+	code := workflow.CodeOf(err)
+	if agentID != "" {
+		s.state.RecordOperationError(agentID, code)
+	}
+	return stream.Send(&agentpb.BashOutput{
+		Done:     true,
+		ExitCode: -1,
+		Code:     string(code),
+		Error:    err.Error(),
+	})
+}
+
+// feedStdin writes the stream's input to the command's standard input,
+// which it closes once the agent closes its side of the stream
+func feedStdin(stream agentpb.Agent_BashServer, stdin io.WriteCloser, data []byte) {
+	defer stdin.Close()
+	for {
+		if _, err := stdin.Write(data); err != nil {
+			return
+		}
+		in, err := stream.Recv()
+		if err != nil {
+			return
+		}
+		data = in.Stdin
+	}
+}
+
+// dispatch serves a gRPC call with the HTTP API's handler for endpoint
+func (s *Server) dispatch(ctx context.Context, endpoint string, body interface{}) *agentpb.Response {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return failedResponse(fmt.Errorf("failed to marshal request: %w", err))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return failedResponse(fmt.Errorf("failed to create request: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	rec := &bufferedResponse{header: make(http.Header)}
+	s.measure(s.mux).ServeHTTP(rec, req)
+
+	// Problems repeat their failure in the shape of APIResponse
+	var reply struct {
+		APIResponse
+		Field string `json:"field"`
+	}
+	if err := json.Unmarshal(rec.body.Bytes(), &reply); err != nil {
+		return failedResponse(fmt.Errorf("failed to read response: %w", err))
+	}
+
+	return &agentpb.Response{
+		Success:    reply.Success,
+		Data:       reply.Data,
+		Checksum:   reply.Checksum,
+		NextCursor: reply.NextCursor,
+		Code:       string(reply.Code),
+		Error:      reply.Error,
+		Field:      reply.Field,
+	}
+}
+
+// failedResponse reports an error that kept a call from reaching its handler
+func failedResponse(err error) *agentpb.Response {
+	return &agentpb.Response{
+		Code:  string(workflow.CodeOf(err)),
+		Error: err.Error(),
+		Field: workflow.FieldOf(err),
+	}
+}
+
+// grpcRequest returns the endpoint and request body of a message, or no
+// endpoint for unknown types
+func grpcRequest(msg *agentpb.Message) (string, interface{}) {
+	switch workflow.MessageType(msg.Type) {
+	case workflow.MessageTypeReadFile:
+		return "/api/file/read", FileReadRequest{
+			AgentID: msg.AgentId,
+			Path:    msg.Path,
+		}
+	case workflow.MessageTypeWriteFile:
+		return "/api/file/write", FileWriteRequest{
+			AgentID:          msg.AgentId,
+			Path:             msg.Path,
+			Content:          msg.Content,
+			Checksum:         msg.Checksum,
+			ExpectedChecksum: msg.ExpectedChecksum,
+		}
+	case workflow.MessageTypeEditFile:
+		edits := make([]workflow.Edit, 0, len(msg.Edits))
+		for _, edit := range msg.Edits {
+			edits = append(edits, workflow.Edit{OldString: edit.OldString, NewString: edit.NewString})
+		}
+		return "/api/file/edit", FileEditRequest{
+			AgentID:          msg.AgentId,
+			Path:             msg.Path,
+			Edits:            edits,
+			ExpectedChecksum: msg.ExpectedChecksum,
+		}
+	case workflow.MessageTypeGlob:
+		return "/api/glob", GlobRequest{
+			Pattern:    msg.Path,
+			MaxResults: int(msg.MaxResults),
+			Cursor:     msg.Cursor,
+		}
+	case workflow.MessageTypeGrep:
+		return "/api/grep", GrepRequest{
+			Pattern:    msg.Content,
+			Path:       msg.Path,
+			Recursive:  true,
+			MaxResults: int(msg.MaxResults),
+			Cursor:     msg.Cursor,
+		}
+	case workflow.MessageTypeCodeSearch:
+		return "/api/symbols", SymbolsRequest{
+			Name:       msg.Symbol,
+			Path:       msg.Path,
+			Kind:       msg.Kind,
+			References: msg.References,
+			MaxResults: int(msg.MaxResults),
+			Cursor:     msg.Cursor,
+		}
+	case workflow.MessageTypeLock:
+		return "/api/lock", LockRequest{
+			AgentID:    msg.AgentId,
+			Path:       msg.Path,
+			TTLSeconds: int(msg.TtlSeconds),
+		}
+	case workflow.MessageTypeUnlock:
+		return "/api/unlock", LockRequest{
+			AgentID: msg.AgentId,
+			Path:    msg.Path,
+		}
+	case workflow.MessageTypeLocks:
+		return "/api/locks", struct{}{}
+	case workflow.MessageTypeAddTasks:
+		return "/api/tasks/add", AddTasksRequest{
+			AgentID: msg.AgentId,
+			Tasks:   msg.Content,
+		}
+	case workflow.MessageTypeUsage:
+		usage := UsageRequest{AgentID: msg.AgentId}
+		if msg.Usage != nil {
+			usage.TokenUsage = workflow.TokenUsage{
+				InputTokens:  msg.Usage.InputTokens,
+				OutputTokens: msg.Usage.OutputTokens,
+				CostUSD:      msg.Usage.CostUsd,
+			}
+		}
+		return "/api/usage", usage
+	case workflow.MessageTypeCheckpoint:
+		return "/api/checkpoint", CheckpointRequest{
+			AgentID: msg.AgentId,
+			Output:  msg.Content,
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,16 @@
+package server
+
+import (
+	"net/http/pprof"
+)
+
+// EnableProfiling serves net/http/pprof under /debug/pprof/, behind the
+// same token as the API. Call it before Start. CPU profiles and traces
+// must be shorter than the server's 30s write timeout.
+func (s *Server) EnableProfiling() {
+	s.mux.HandleFunc("/debug/pprof/", pprof.Index)
+	s.mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	s.mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	s.mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	s.mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+}
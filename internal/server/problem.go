@@ -0,0 +1,85 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// ProblemContentType is the content type of error responses
+const ProblemContentType = "application/problem+json"
+
+// problemTypePrefix starts the type URI of problems, which ends with
+// their error code
+const problemTypePrefix = "urn:claude-swarm:problem:"
+
+// Problem is the RFC 7807 body of every failed request. Code is the
+// machine-readable error code and Field the request field at fault, when
+// there is one. Success and Error repeat the failure in the shape of
+// APIResponse, for clients that predate problems.
+type Problem struct {
+	Type     string             `json:"type"`
+	Title    string             `json:"title"`
+	Status   int                `json:"status"`
+	Detail   string             `json:"detail"`
+	Instance string             `json:"instance,omitempty"`
+	Code     workflow.ErrorCode `json:"code"`
+	Field    string             `json:"field,omitempty"`
+	Success  bool               `json:"success"`
+	Error    string             `json:"error"`
+	// Data and Checksum carry a stale file's current content
+	Data     string `json:"data,omitempty"`
+	Checksum string `json:"checksum,omitempty"`
+}
+
+// newProblem describes a failure with an error code
+func newProblem(code workflow.ErrorCode, status int, detail string) Problem {
+	return Problem{
+		Type:   problemTypePrefix + string(code),
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+		Error:  detail,
+	}
+}
+
+// writeProblem responds with a problem
+func (s *Server) writeProblem(w http.ResponseWriter, problem Problem) {
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+// decodeRequest decodes a JSON request body. A value of the wrong type
+// names its field.
+func decodeRequest(r *http.Request, v interface{}) error {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err == nil {
+		return nil
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return workflow.WithField(typeErr.Field, workflow.Errorf(workflow.ErrorInvalidRequest, "invalid request: %s must be a %s", typeErr.Field, typeErr.Type))
+	}
+	return workflow.Errorf(workflow.ErrorInvalidRequest, "invalid request: %v", err)
+}
+
+// requireField fails a request missing a required field
+func requireField(field, value string) error {
+	if value != "" {
+		return nil
+	}
+	return workflow.WithField(field, workflow.Errorf(workflow.ErrorInvalidRequest, "%s is required", field))
+}
+
+// handleNotFound answers requests to unknown endpoints
+func (s *Server) handleNotFound(w http.ResponseWriter, r *http.Request) {
+	problem := newProblem(workflow.ErrorNotFound, http.StatusNotFound, fmt.Sprintf("no endpoint %s", r.URL.Path))
+	problem.Instance = r.URL.Path
+	s.writeProblem(w, problem)
+}
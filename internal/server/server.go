@@ -1,31 +1,54 @@
 package server
 
 import (
+	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
+	"net"
 	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/aristath/claude-swarm/internal/coalesce"
+	"github.com/aristath/claude-swarm/internal/proposals"
 	"github.com/aristath/claude-swarm/internal/state"
+	"github.com/aristath/claude-swarm/internal/version"
 	"github.com/aristath/claude-swarm/internal/workflow"
+	"google.golang.org/grpc"
 )
 
 // Server is the HTTP API server for agent communication
 type Server struct {
-	state      *state.SwarmState
-	swarmDir   string
+	state     *state.SwarmState
+	swarmDir  string
+	proposals *proposals.Store
+	token     string
+	lifecycle LifecycleHooks
+	verifier  Verifier
+	// corsOrigins are the origins whose pages may call the API
+	corsOrigins []string
+	// embed opens the status endpoints to dashboards without the token
+	embed      bool
+	mux        *http.ServeMux
 	httpServer *http.Server
+	// grpcServer serves the agent protocol over gRPC, when enabled
+	grpcServer *grpc.Server
+	grpcAddr   string
 }
 
 // NewServer creates a new API server
 func NewServer(swarmState *state.SwarmState, swarmDir string, port int) *Server {
 	s := &Server{
-		state:    swarmState,
-		swarmDir: swarmDir,
+		state:     swarmState,
+		swarmDir:  swarmDir,
+		proposals: proposals.NewStore(swarmDir),
 	}
 
 	mux := http.NewServeMux()
@@ -37,60 +60,216 @@ func NewServer(swarmState *state.SwarmState, swarmDir string, port int) *Server
 	mux.HandleFunc("/api/bash", s.handleBash)
 	mux.HandleFunc("/api/glob", s.handleGlob)
 	mux.HandleFunc("/api/grep", s.handleGrep)
+	mux.HandleFunc("/api/symbols", s.handleSymbols)
+
+	// Advisory file locks
+	mux.HandleFunc("/api/lock", s.handleLock)
+	mux.HandleFunc("/api/unlock", s.handleUnlock)
+	mux.HandleFunc("/api/locks", s.handleLocks)
 
 	// Agent communication endpoints
 	mux.HandleFunc("/api/question", s.handleQuestion)
 	mux.HandleFunc("/api/complete", s.handleComplete)
+	mux.HandleFunc("/api/fail", s.handleFail)
+	mux.HandleFunc("/api/heartbeat", s.handleHeartbeat)
+	mux.HandleFunc("/api/tasks/add", s.handleAddTasks)
+	mux.HandleFunc("/api/usage", s.handleUsage)
+	mux.HandleFunc("/api/progress", s.handleProgress)
+	mux.HandleFunc("/api/checkpoint", s.handleCheckpoint)
+	mux.HandleFunc("/api/deps", s.handleDeps)
+	mux.HandleFunc("/api/deps/output", s.handleDepOutput)
+
+	// Session status and history, for external tools and dashboards
+	mux.HandleFunc("/api/events", s.handleEvents)
+	mux.HandleFunc("/api/status", s.handleStatus)
+	mux.HandleFunc("/status", s.handleStatusPage)
 
 	// Health check
 	mux.HandleFunc("/health", s.handleHealth)
 
+	// Everything else
+	mux.HandleFunc("/", s.handleNotFound)
+	s.mux = mux
+
 	s.httpServer = &http.Server{
-		Addr:         fmt.Sprintf(":%d", port),
-		Handler:      mux,
-		ReadTimeout:  30 * time.Second,
-		WriteTimeout: 30 * time.Second,
+		Addr:        fmt.Sprintf(":%d", port),
+		Handler:     s.cors(s.authorize(s.negotiateProtocol(s.compress(s.measure(mux))))),
+		ReadTimeout: 30 * time.Second,
+		// Long enough for swarm-agent's slowest request, a symbol search
+		// indexing a large tree
+		WriteTimeout: 2 * time.Minute,
 	}
 
 	return s
 }
 
-// Start starts the HTTP server
-func (s *Server) Start() error {
-	fmt.Printf("Starting API server on %s\n", s.httpServer.Addr)
-	return s.httpServer.ListenAndServe()
+// Start starts the HTTP server, and the gRPC server if enabled, and blocks
+// until ctx is cancelled or a server fails. Request contexts derive from ctx, so cancelling it also
+// aborts in-flight operations such as bash commands.
+func (s *Server) Start(ctx context.Context) error {
+	s.httpServer.BaseContext = func(net.Listener) context.Context {
+		return ctx
+	}
+
+	errCh := make(chan error, 2)
+	if err := s.serveGRPC(errCh); err != nil {
+		return err
+	}
+	defer s.stopGRPC()
+
+	go func() {
+		fmt.Printf("Starting API server on %s\n", s.httpServer.Addr)
+		errCh <- s.httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		s.httpServer.Close()
+		return err
+
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return s.httpServer.Shutdown(shutdownCtx)
+	}
+}
+
+// SetToken requires agents to send "Authorization: Bearer <token>" with
+// every request. An empty token disables authentication.
+func (s *Server) SetToken(token string) {
+	s.token = token
 }
 
-// Stop stops the HTTP server
+// LifecycleHooks runs lifecycle hooks for completions and questions that
+// arrive over the API, such as the orchestrator's
+type LifecycleHooks interface {
+	RunLifecycleHooks(ctx context.Context, payload workflow.HookPayload) workflow.HookResult
+}
+
+// SetLifecycleHooks runs lifecycle hooks on completions and questions
+func (s *Server) SetLifecycleHooks(hooks LifecycleHooks) {
+	s.lifecycle = hooks
+}
+
+// Verifier runs the verification commands of tasks completed over the
+// API, such as the orchestrator
+type Verifier interface {
+	VerifyCompletion(ctx context.Context, taskID string, respawn bool) error
+}
+
+// SetVerifier verifies completions before the tasks complete
+func (s *Server) SetVerifier(verifier Verifier) {
+	s.verifier = verifier
+}
+
+// authorize rejects requests without the bearer token, if one is set.
+// The health check stays open, and so do the status endpoints with
+// embedding.
+func (s *Server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		open := r.URL.Path == "/health" || (s.embed && statusPaths[r.URL.Path] && r.Method == http.MethodGet)
+		if s.token != "" && !open {
+			auth := r.Header.Get("Authorization")
+			if subtle.ConstantTimeCompare([]byte(auth), []byte("Bearer "+s.token)) != 1 {
+				s.jsonError(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ProtocolHeader carries the protocol version of HTTP requests and responses
+const ProtocolHeader = version.Header
+
+// negotiateProtocol rejects requests declaring a protocol version the
+// server cannot serve and stamps every response with the server's version.
+// Requests without the header predate versioning and are accepted.
+func (s *Server) negotiateProtocol(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(ProtocolHeader, strconv.Itoa(version.ProtocolVersion))
+
+		if header := r.Header.Get(ProtocolHeader); header != "" {
+			peer, err := strconv.Atoi(header)
+			if err != nil {
+				s.jsonError(w, fmt.Sprintf("Invalid %s header: %q", ProtocolHeader, header), http.StatusBadRequest)
+				return
+			}
+			if err := version.CheckProtocol(peer); err != nil {
+				s.jsonError(w, err.Error(), http.StatusUpgradeRequired)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// measure records how long agent API requests take, for the session stats;
+// external tools reading the event history are left out
+func (s *Server) measure(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		next.ServeHTTP(w, r)
+		if strings.HasPrefix(r.URL.Path, "/api/") && r.URL.Path != "/api/events" {
+			s.state.RecordOperation(time.Since(start))
+		}
+	})
+}
+
+// Stop stops the HTTP and gRPC servers
 func (s *Server) Stop() error {
+	s.stopGRPC()
 	return s.httpServer.Close()
 }
 
 // Request/Response types
 
 type FileReadRequest struct {
-	Path string `json:"path"`
+	AgentID string `json:"agent_id,omitempty"`
+	Path    string `json:"path"`
 }
 
 type FileWriteRequest struct {
-	Path    string `json:"path"`
-	Content string `json:"content"`
+	AgentID  string `json:"agent_id,omitempty"`
+	Path     string `json:"path"`
+	Content  string `json:"content"`
+	Checksum string `json:"checksum,omitempty"` // SHA-256 of Content, verified on receipt
+	// ExpectedChecksum is the SHA-256 the file must still have for the write
+	// to apply, so that agents do not clobber each other's changes
+	ExpectedChecksum string `json:"expected_checksum,omitempty"`
 }
 
 type FileEditRequest struct {
+	AgentID   string          `json:"agent_id,omitempty"`
 	Path      string          `json:"path"`
 	Edits     []workflow.Edit `json:"edits"`
 	OldString string          `json:"old_string,omitempty"` // Single edit support
 	NewString string          `json:"new_string,omitempty"`
+	// ExpectedChecksum is the SHA-256 the file must still have for the edits
+	// to apply
+	ExpectedChecksum string `json:"expected_checksum,omitempty"`
+}
+
+type LockRequest struct {
+	AgentID    string `json:"agent_id"`
+	Path       string `json:"path"`
+	TTLSeconds int    `json:"ttl_seconds,omitempty"` // Default 5 minutes
 }
 
 type BashRequest struct {
+	AgentID    string `json:"agent_id,omitempty"`
 	Command    string `json:"command"`
 	WorkingDir string `json:"working_dir,omitempty"`
 }
 
 type GlobRequest struct {
-	Pattern string `json:"pattern"`
+	Pattern    string `json:"pattern"`
+	MaxResults int    `json:"max_results,omitempty"` // Page size, default 1000
+	Cursor     string `json:"cursor,omitempty"`      // From the previous page's next_cursor
 }
 
 type GrepRequest struct {
@@ -98,22 +277,82 @@ type GrepRequest struct {
 	Path       string `json:"path,omitempty"`
 	Recursive  bool   `json:"recursive,omitempty"`
 	IgnoreCase bool   `json:"ignore_case,omitempty"`
+	MaxResults int    `json:"max_results,omitempty"` // Page size, default 1000
+	Cursor     string `json:"cursor,omitempty"`      // From the previous page's next_cursor
+}
+
+type SymbolsRequest struct {
+	Name       string `json:"name"`                 // Symbol name, optionally qualified as "Type.Method"
+	Path       string `json:"path,omitempty"`       // Tree to search, default the server's working directory
+	Kind       string `json:"kind,omitempty"`       // Only definitions of this kind (func, method, type, ...)
+	References bool   `json:"references,omitempty"` // Return references instead of definitions
+	MaxResults int    `json:"max_results,omitempty"`
+	Cursor     string `json:"cursor,omitempty"`
+}
+
+type AddTasksRequest struct {
+	AgentID string `json:"agent_id"`
+	Tasks   string `json:"tasks"` // YAML with a tasks list, like a workflow's
 }
 
 type QuestionRequest struct {
 	AgentID  string `json:"agent_id"`
 	Question string `json:"question"`
+	Thread   int    `json:"thread,omitempty"` // Question number whose thread this follows up
 }
 
 type CompleteRequest struct {
 	AgentID string `json:"agent_id"`
 	Output  string `json:"output"`
+	// Optional metadata, as swarm-agent complete's flags give it
+	FilesChanged []string `json:"files_changed,omitempty"`
+	TestsPassed  *bool    `json:"tests_passed,omitempty"`
+	Notes        string   `json:"notes,omitempty"`
+}
+
+type FailRequest struct {
+	AgentID string `json:"agent_id"`
+	Error   string `json:"error"`
+}
+
+type HeartbeatRequest struct {
+	AgentID string `json:"agent_id"`
+}
+
+type UsageRequest struct {
+	AgentID string `json:"agent_id"`
+	workflow.TokenUsage
+}
+
+type CheckpointRequest struct {
+	AgentID string `json:"agent_id"`
+	Output  string `json:"output"`
+}
+
+// DepsRequest lists an agent's upstream tasks, or with Task gets the
+// output of one of them
+type DepsRequest struct {
+	AgentID string `json:"agent_id"`
+	Task    string `json:"task,omitempty"`
+}
+
+type ProgressRequest struct {
+	AgentID string `json:"agent_id"`
+	Text    string `json:"text"`
+	Percent *int   `json:"percent,omitempty"`
 }
 
 type APIResponse struct {
-	Success bool   `json:"success"`
-	Data    string `json:"data,omitempty"`
-	Error   string `json:"error,omitempty"`
+	Success  bool               `json:"success"`
+	Data     string             `json:"data,omitempty"`
+	Code     workflow.ErrorCode `json:"code,omitempty"`
+	Error    string             `json:"error,omitempty"`
+	Checksum string             `json:"checksum,omitempty"` // SHA-256 of the file read or written
+	// NextCursor is set when a glob or grep has more results, and on every
+	// page of events
+	NextCursor string `json:"next_cursor,omitempty"`
+	// More is set when a page of events is full and more events follow
+	More bool `json:"more,omitempty"`
 }
 
 // Handlers
@@ -125,18 +364,28 @@ func (s *Server) handleFileRead(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req FileReadRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.jsonError(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+	if err := decodeRequest(r, &req); err != nil {
+		s.jsonFailure(w, "", err)
 		return
 	}
 
-	content, err := os.ReadFile(req.Path)
+	// In read-only runs agents see their own proposed changes
+	if s.state.IsReadOnly() && req.AgentID != "" {
+		if proposed, ok := s.proposals.Current(req.AgentID, req.Path); ok {
+			s.jsonFile(w, proposed, workflow.Checksum(proposed))
+			return
+		}
+	}
+
+	file, shared, err := coalesce.ReadFile(req.Path)
+	s.recordCoalesced(shared)
 	if err != nil {
-		s.jsonError(w, fmt.Sprintf("Failed to read file: %v", err), http.StatusInternalServerError)
+		s.jsonFailure(w, req.AgentID, fmt.Errorf("failed to read file: %w", err))
 		return
 	}
+	s.state.RecordFileRead(file.CacheHit || shared, len(file.Content))
 
-	s.jsonSuccess(w, string(content))
+	s.jsonFile(w, file.Content, workflow.Checksum(file.Content))
 }
 
 func (s *Server) handleFileWrite(w http.ResponseWriter, r *http.Request) {
@@ -146,24 +395,59 @@ func (s *Server) handleFileWrite(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req FileWriteRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.jsonError(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+	if err := decodeRequest(r, &req); err != nil {
+		s.jsonFailure(w, "", err)
+		return
+	}
+
+	if err := workflow.VerifyChecksum(req.Content, req.Checksum); err != nil {
+		s.jsonFailure(w, req.AgentID, err)
+		return
+	}
+
+	s.state.FileLock().Lock()
+	defer s.state.FileLock().Unlock()
+
+	// Stale writes are rejected before they count against the quota
+	current, exists, err := s.currentContent(req.AgentID, req.Path)
+	if err == nil && req.ExpectedChecksum != "" {
+		err = workflow.CheckUnchanged(req.Path, current, exists, req.ExpectedChecksum)
+	}
+	if err == nil {
+		err = s.state.ReserveOperation(req.AgentID, state.OperationWrite, req.Path, int64(len(req.Content)))
+	}
+	if err != nil {
+		s.jsonFailure(w, req.AgentID, err)
+		return
+	}
+
+	if s.state.IsReadOnly() {
+		s.propose(w, req.AgentID, req.Path, req.Content)
 		return
 	}
 
 	// Ensure directory exists
 	dir := filepath.Dir(req.Path)
 	if err := os.MkdirAll(dir, 0755); err != nil {
-		s.jsonError(w, fmt.Sprintf("Failed to create directory: %v", err), http.StatusInternalServerError)
+		s.jsonFailure(w, req.AgentID, fmt.Errorf("failed to create directory: %w", err))
 		return
 	}
 
+	defer coalesce.Invalidate(req.Path)
 	if err := os.WriteFile(req.Path, []byte(req.Content), 0644); err != nil {
-		s.jsonError(w, fmt.Sprintf("Failed to write file: %v", err), http.StatusInternalServerError)
+		s.jsonFailure(w, req.AgentID, fmt.Errorf("failed to write file: %w", err))
 		return
 	}
+	s.state.RecordChange(req.AgentID, req.Path, current, req.Content)
 
-	s.jsonSuccess(w, fmt.Sprintf("Wrote %d bytes to %s", len(req.Content), req.Path))
+	// Read the file back to catch truncated or racing writes
+	checksum, err := workflow.VerifyFile(req.Path, workflow.Checksum(req.Content))
+	if err != nil {
+		s.jsonFailure(w, req.AgentID, err)
+		return
+	}
+
+	s.jsonFile(w, fmt.Sprintf("Wrote %d bytes to %s", len(req.Content), req.Path), checksum)
 }
 
 func (s *Server) handleFileEdit(w http.ResponseWriter, r *http.Request) {
@@ -173,8 +457,8 @@ func (s *Server) handleFileEdit(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req FileEditRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.jsonError(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+	if err := decodeRequest(r, &req); err != nil {
+		s.jsonFailure(w, "", err)
 		return
 	}
 
@@ -190,37 +474,81 @@ func (s *Server) handleFileEdit(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if len(edits) == 0 {
-		s.jsonError(w, "No edits provided", http.StatusBadRequest)
+		s.jsonFailure(w, req.AgentID, workflow.WithField("edits", workflow.Errorf(workflow.ErrorInvalidRequest, "no edits provided")))
 		return
 	}
 
-	// Read file
-	content, err := os.ReadFile(req.Path)
+	var editBytes int64
+	for _, edit := range edits {
+		editBytes += int64(len(edit.NewString))
+	}
+	s.state.FileLock().Lock()
+	defer s.state.FileLock().Unlock()
+
+	// Read file, or the agent's proposed content in read-only runs
+	base, exists, err := s.currentContent(req.AgentID, req.Path)
+	if err == nil && !exists {
+		err = fmt.Errorf("failed to read file: %w", os.ErrNotExist)
+	}
+	if err == nil {
+		err = workflow.CheckUnchanged(req.Path, base, exists, req.ExpectedChecksum)
+	}
+	if err == nil {
+		err = s.state.ReserveOperation(req.AgentID, state.OperationWrite, req.Path, editBytes)
+	}
 	if err != nil {
-		s.jsonError(w, fmt.Sprintf("Failed to read file: %v", err), http.StatusInternalServerError)
+		s.jsonFailure(w, req.AgentID, err)
 		return
 	}
 
-	result := string(content)
+	result, err := workflow.ApplyEdits(base, edits)
+	if err != nil {
+		s.jsonFailure(w, req.AgentID, err)
+		return
+	}
 
-	// Apply each edit
-	for i, edit := range edits {
-		if !strings.Contains(result, edit.OldString) {
-			s.jsonError(w, fmt.Sprintf("Edit %d: old_string not found in file", i+1), http.StatusBadRequest)
-			return
-		}
-		result = strings.Replace(result, edit.OldString, edit.NewString, 1)
+	if s.state.IsReadOnly() {
+		s.propose(w, req.AgentID, req.Path, result)
+		return
 	}
 
 	// Write back
+	defer coalesce.Invalidate(req.Path)
 	if err := os.WriteFile(req.Path, []byte(result), 0644); err != nil {
-		s.jsonError(w, fmt.Sprintf("Failed to write file: %v", err), http.StatusInternalServerError)
+		s.jsonFailure(w, req.AgentID, fmt.Errorf("failed to write file: %w", err))
 		return
 	}
+	s.state.RecordChange(req.AgentID, req.Path, base, result)
 
 	s.jsonSuccess(w, fmt.Sprintf("Applied %d edit(s) to %s", len(edits), req.Path))
 }
 
+// recordCoalesced counts an operation that shared another's result
+func (s *Server) recordCoalesced(shared bool) {
+	if shared {
+		s.state.RecordCoalesced()
+	}
+}
+
+// currentContent returns a file as the agent sees it: in read-only runs its
+// own proposed content, otherwise the file on disk
+func (s *Server) currentContent(agentID, path string) (string, bool, error) {
+	if s.state.IsReadOnly() {
+		if proposed, ok := s.proposals.Current(agentID, path); ok {
+			return proposed, true, nil
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read file: %w", err)
+	}
+	return string(content), true, nil
+}
+
 func (s *Server) handleBash(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		s.jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -228,22 +556,42 @@ func (s *Server) handleBash(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req BashRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.jsonError(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+	if err := decodeRequest(r, &req); err != nil {
+		s.jsonFailure(w, "", err)
+		return
+	}
+
+	if err := s.state.ReserveOperation(req.AgentID, state.OperationBash, "", 0); err != nil {
+		s.jsonFailure(w, req.AgentID, err)
 		return
 	}
 
-	cmd := exec.Command("bash", "-c", req.Command)
+	if s.state.IsReadOnly() {
+		if err := proposals.CheckReadOnlyCommand(req.Command); err != nil {
+			s.jsonFailure(w, req.AgentID, err)
+			return
+		}
+	}
+
+	s.state.RecordBash()
+	cmd := exec.CommandContext(r.Context(), "bash", "-c", s.state.InterpolateSecrets(req.Command))
 	if req.WorkingDir != "" {
 		cmd.Dir = req.WorkingDir
 	}
 
 	output, err := cmd.CombinedOutput()
+	output = []byte(s.state.Redact(string(output)))
 	if err != nil {
 		// Include output even on error
+		// A command exiting non-zero is an answer, not a failed operation
+		code := workflow.CodeOf(err)
+		if code != workflow.ErrorCommandFailed {
+			s.state.RecordOperationError(req.AgentID, code)
+		}
 		s.jsonResponse(w, APIResponse{
 			Success: false,
 			Data:    string(output),
+			Code:    code,
 			Error:   err.Error(),
 		})
 		return
@@ -259,18 +607,26 @@ func (s *Server) handleGlob(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req GlobRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.jsonError(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+	if err := decodeRequest(r, &req); err != nil {
+		s.jsonFailure(w, "", err)
 		return
 	}
 
-	matches, err := filepath.Glob(req.Pattern)
+	page, err := workflow.NewPagination(req.MaxResults, req.Cursor, "glob", req.Pattern)
 	if err != nil {
-		s.jsonError(w, fmt.Sprintf("Glob failed: %v", err), http.StatusInternalServerError)
+		s.jsonFailure(w, "", err)
 		return
 	}
 
-	s.jsonSuccess(w, strings.Join(matches, "\n"))
+	matches, shared, err := coalesce.Glob(req.Pattern)
+	s.recordCoalesced(shared)
+	if err != nil {
+		s.jsonFailure(w, "", err)
+		return
+	}
+
+	matches, next := page.Page(matches, "glob", req.Pattern)
+	s.jsonPage(w, matches, next)
 }
 
 func (s *Server) handleGrep(w http.ResponseWriter, r *http.Request) {
@@ -280,8 +636,8 @@ func (s *Server) handleGrep(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req GrepRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.jsonError(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+	if err := decodeRequest(r, &req); err != nil {
+		s.jsonFailure(w, "", err)
 		return
 	}
 
@@ -293,24 +649,125 @@ func (s *Server) handleGrep(w http.ResponseWriter, r *http.Request) {
 	if req.IgnoreCase {
 		args = append(args, "-i")
 	}
-	args = append(args, req.Pattern)
+	args = append(args, "--", req.Pattern)
 	if req.Path != "" {
 		args = append(args, req.Path)
 	} else {
 		args = append(args, ".")
 	}
 
-	cmd := exec.Command("grep", args...)
-	output, err := cmd.CombinedOutput()
+	query := append([]string{"grep"}, args...)
+	page, err := workflow.NewPagination(req.MaxResults, req.Cursor, query...)
 	if err != nil {
-		// grep returns error if no matches, but that's not really an error
-		if len(output) == 0 {
-			s.jsonSuccess(w, "")
-			return
-		}
+		s.jsonFailure(w, "", err)
+		return
 	}
 
-	s.jsonSuccess(w, string(output))
+	lines, shared, err := coalesce.Grep(r.Context(), args, page.Limit())
+	s.recordCoalesced(shared)
+	if err != nil {
+		s.jsonFailure(w, "", err)
+		return
+	}
+
+	lines, next := page.Page(lines, query...)
+	s.jsonPage(w, lines, next)
+}
+
+func (s *Server) handleLock(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req LockRequest
+	if err := decodeRequest(r, &req); err != nil {
+		s.jsonFailure(w, "", err)
+		return
+	}
+	if err := requireField("agent_id", req.AgentID); err != nil {
+		s.jsonFailure(w, "", err)
+		return
+	}
+
+	lock, err := s.state.AcquireLock(req.AgentID, req.Path, time.Duration(req.TTLSeconds)*time.Second)
+	if err != nil {
+		s.jsonFailure(w, req.AgentID, err)
+		return
+	}
+
+	s.jsonLocks(w, lock)
+}
+
+func (s *Server) handleUnlock(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req LockRequest
+	if err := decodeRequest(r, &req); err != nil {
+		s.jsonFailure(w, "", err)
+		return
+	}
+	if err := requireField("agent_id", req.AgentID); err != nil {
+		s.jsonFailure(w, "", err)
+		return
+	}
+
+	if err := s.state.ReleaseLock(req.AgentID, req.Path); err != nil {
+		s.jsonFailure(w, req.AgentID, err)
+		return
+	}
+
+	s.jsonSuccess(w, fmt.Sprintf("Unlocked %s", req.Path))
+}
+
+func (s *Server) handleLocks(w http.ResponseWriter, r *http.Request) {
+	s.jsonLocks(w, s.state.GetLocks()...)
+}
+
+// jsonLocks responds with locks as a JSON array
+func (s *Server) jsonLocks(w http.ResponseWriter, locks ...state.FileLock) {
+	if locks == nil {
+		locks = []state.FileLock{}
+	}
+	data, err := json.MarshalIndent(locks, "", "  ")
+	if err != nil {
+		s.jsonFailure(w, "", fmt.Errorf("failed to marshal locks: %w", err))
+		return
+	}
+	s.jsonSuccess(w, string(data))
+}
+
+func (s *Server) handleSymbols(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req SymbolsRequest
+	if err := decodeRequest(r, &req); err != nil {
+		s.jsonFailure(w, "", err)
+		return
+	}
+
+	query := []string{"code_search", req.Name, req.Kind, req.Path, strconv.FormatBool(req.References)}
+	page, err := workflow.NewPagination(req.MaxResults, req.Cursor, query...)
+	if err != nil {
+		s.jsonFailure(w, "", err)
+		return
+	}
+
+	results, shared, err := coalesce.SearchSymbols(r.Context(), req.Path, req.Name, req.Kind, req.References)
+	s.recordCoalesced(shared)
+	if err != nil {
+		s.jsonFailure(w, "", err)
+		return
+	}
+
+	results, next := page.Page(results, query...)
+	s.jsonPage(w, results, next)
 }
 
 func (s *Server) handleQuestion(w http.ResponseWriter, r *http.Request) {
@@ -320,18 +777,50 @@ func (s *Server) handleQuestion(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req QuestionRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.jsonError(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+	if err := decodeRequest(r, &req); err != nil {
+		s.jsonFailure(w, "", err)
 		return
 	}
 
-	// Add question to state
-	qNum, err := s.state.AddQuestion(req.AgentID, req.Question)
+	// Add question to state, as a reply in its thread or a new question
+	var qNum int
+	var err error
+	if req.Thread > 0 {
+		qNum, err = s.state.ReplyToQuestion(req.AgentID, req.Thread, req.Question)
+		if workflow.CodeOf(err) == workflow.ErrorNotFound {
+			s.jsonFailure(w, req.AgentID, err)
+			return
+		}
+	} else {
+		qNum, err = s.state.AddQuestion(req.AgentID, req.Question)
+	}
 	if err != nil {
-		s.jsonError(w, fmt.Sprintf("Failed to add question: %v", err), http.StatusInternalServerError)
+		s.jsonFailure(w, req.AgentID, fmt.Errorf("failed to add question: %w", err))
+		return
+	}
+
+	// Answer instantly when a canned answer matches
+	if answer, ok := s.state.CannedAnswer(req.AgentID, req.Question); ok {
+		s.state.AnswerQuestion(req.AgentID, qNum, answer, "")
+		s.jsonSuccess(w, answer)
 		return
 	}
 
+	// or an on_question lifecycle hook answers
+	if s.lifecycle != nil {
+		result := s.lifecycle.RunLifecycleHooks(r.Context(), workflow.HookPayload{
+			Point:      workflow.LifecycleOnQuestion,
+			Task:       req.AgentID,
+			Question:   req.Question,
+			QuestionID: qNum,
+		})
+		if result.Answer != "" {
+			s.state.AnswerQuestion(req.AgentID, qNum, result.Answer, "")
+			s.jsonSuccess(w, result.Answer)
+			return
+		}
+	}
+
 	// For now, return a placeholder answer
 	// In a real implementation, this would trigger orchestrator to formulate answer
 	answer := fmt.Sprintf("Question %d received from agent %s. Orchestrator will process and answer.", qNum, req.AgentID)
@@ -346,18 +835,261 @@ func (s *Server) handleComplete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req CompleteRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.jsonError(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+	if err := decodeRequest(r, &req); err != nil {
+		s.jsonFailure(w, "", err)
+		return
+	}
+
+	// Reject output that breaks the task's format or schema, so the agent
+	// can fix it and complete again
+	if task := s.state.GetTask(req.AgentID); task != nil {
+		output, err := task.CheckOutput(req.Output)
+		if err != nil {
+			s.jsonFailure(w, req.AgentID, err)
+			return
+		}
+		req.Output = output
+	}
+
+	// A failed verification goes back to the agent to fix, until the task
+	// runs out of attempts and fails
+	if s.verifier != nil {
+		if err := s.verifier.VerifyCompletion(r.Context(), req.AgentID, false); err != nil {
+			if agent := s.state.GetAgent(req.AgentID); agent != nil && agent.Status.IsFailure() {
+				s.jsonSuccess(w, fmt.Sprintf("Task %s failed: %s", req.AgentID, agent.Error))
+				return
+			}
+			s.jsonFailure(w, req.AgentID, err)
+			return
+		}
+	}
+
+	// post_complete lifecycle hooks may reject the output, for the agent
+	// to fix it and complete again
+	if s.lifecycle != nil {
+		result := s.lifecycle.RunLifecycleHooks(r.Context(), workflow.HookPayload{
+			Point:    workflow.LifecyclePostComplete,
+			Task:     req.AgentID,
+			Output:   s.state.Redact(req.Output),
+			Criteria: s.acceptanceCriteria(req.AgentID),
+		})
+		if result.Reject != "" {
+			s.jsonFailure(w, req.AgentID, workflow.Errorf(workflow.ErrorInvalidRequest, "post_complete hook rejected the output: %s", result.Reject))
+			return
+		}
+	}
+
+	completion := &workflow.Completion{FilesChanged: req.FilesChanged, TestsPassed: req.TestsPassed, Notes: req.Notes}
+	if err := s.state.SetCompletion(req.AgentID, completion); err != nil {
+		s.jsonFailure(w, req.AgentID, err)
 		return
 	}
 
 	// Mark task as complete
 	if err := s.state.CompleteTask(req.AgentID, req.Output); err != nil {
-		s.jsonError(w, fmt.Sprintf("Failed to complete task: %v", err), http.StatusInternalServerError)
+		s.jsonFailure(w, req.AgentID, fmt.Errorf("failed to complete task: %w", err))
+		return
+	}
+
+	// A repeated task whose output does not meet its condition runs again
+	switch agent := s.state.GetAgent(req.AgentID); {
+	case agent == nil || agent.Status == workflow.TaskStatusRunning:
+		s.jsonSuccess(w, fmt.Sprintf("Task %s will run again: its output does not meet repeat_until yet", req.AgentID))
+	case agent.Status == workflow.TaskStatusFailed:
+		s.jsonSuccess(w, fmt.Sprintf("Task %s failed: %s", req.AgentID, agent.Error))
+	default:
+		message := fmt.Sprintf("Task %s marked as complete", req.AgentID)
+		if criteria := s.acceptanceCriteria(req.AgentID); len(criteria) > 0 {
+			message += "\n\nAcceptance criteria for this task:\n" + workflow.Checklist(criteria)
+		}
+		s.jsonSuccess(w, message)
+	}
+}
+
+// acceptanceCriteria returns a task's acceptance criteria, echoed back to
+// its agent and passed to post_complete hooks when it completes
+func (s *Server) acceptanceCriteria(taskID string) []string {
+	if task := s.state.GetTask(taskID); task != nil {
+		return s.state.Workflow.AcceptanceCriteria(*task)
+	}
+	return nil
+}
+
+func (s *Server) handleFail(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req FailRequest
+	if err := decodeRequest(r, &req); err != nil {
+		s.jsonFailure(w, "", err)
+		return
+	}
+	if err := requireField("error", req.Error); err != nil {
+		s.jsonFailure(w, req.AgentID, err)
+		return
+	}
+
+	if err := s.state.FailTask(req.AgentID, req.Error); err != nil {
+		s.jsonFailure(w, req.AgentID, fmt.Errorf("failed to fail task: %w", err))
+		return
+	}
+
+	s.jsonSuccess(w, fmt.Sprintf("Task %s marked as failed", req.AgentID))
+}
+
+func (s *Server) handleHeartbeat(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req HeartbeatRequest
+	if err := decodeRequest(r, &req); err != nil {
+		s.jsonFailure(w, "", err)
+		return
+	}
+	if s.state.GetAgent(req.AgentID) == nil {
+		s.jsonFailure(w, "", workflow.WithField("agent_id", workflow.Errorf(workflow.ErrorNotFound, "agent not found: %s", req.AgentID)))
+		return
+	}
+
+	s.state.Heartbeat(req.AgentID)
+	s.jsonSuccess(w, "Heartbeat recorded")
+}
+
+func (s *Server) handleAddTasks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req AddTasksRequest
+	if err := decodeRequest(r, &req); err != nil {
+		s.jsonFailure(w, "", err)
+		return
+	}
+
+	tasks, err := workflow.ParseFragment([]byte(req.Tasks))
+	if err != nil {
+		s.jsonFailure(w, req.AgentID, err)
+		return
+	}
+
+	added, err := s.state.AddTasks(req.AgentID, tasks)
+	if err != nil {
+		s.jsonFailure(w, req.AgentID, err)
+		return
+	}
+
+	s.jsonSuccess(w, strings.Join(added, "\n"))
+}
+
+func (s *Server) handleUsage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req UsageRequest
+	if err := decodeRequest(r, &req); err != nil {
+		s.jsonFailure(w, "", err)
+		return
+	}
+
+	total, err := s.state.RecordUsage(req.AgentID, req.TokenUsage)
+	if err != nil {
+		s.jsonFailure(w, req.AgentID, err)
+		return
+	}
+
+	s.jsonSuccess(w, workflow.FormatUsage(total))
+}
+
+func (s *Server) handleProgress(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req ProgressRequest
+	if err := decodeRequest(r, &req); err != nil {
+		s.jsonFailure(w, "", err)
+		return
+	}
+
+	if err := s.state.RecordProgress(req.AgentID, req.Text, req.Percent); err != nil {
+		s.jsonFailure(w, req.AgentID, err)
+		return
+	}
+
+	s.jsonSuccess(w, workflow.FormatProgress(req.Text, req.Percent))
+}
+
+func (s *Server) handleCheckpoint(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req CheckpointRequest
+	if err := decodeRequest(r, &req); err != nil {
+		s.jsonFailure(w, "", err)
+		return
+	}
+
+	if err := s.state.SaveOutputCheckpoint(req.AgentID, req.Output); err != nil {
+		s.jsonFailure(w, req.AgentID, err)
+		return
+	}
+
+	s.jsonSuccess(w, fmt.Sprintf("Checkpoint saved (%d bytes)", len(req.Output)))
+}
+
+func (s *Server) handleDeps(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req DepsRequest
+	if err := decodeRequest(r, &req); err != nil {
+		s.jsonFailure(w, "", err)
+		return
+	}
+
+	deps, err := s.state.Dependencies(req.AgentID)
+	if err != nil {
+		s.jsonFailure(w, req.AgentID, err)
+		return
+	}
+	data, err := json.MarshalIndent(deps, "", "  ")
+	if err != nil {
+		s.jsonFailure(w, req.AgentID, fmt.Errorf("failed to marshal dependencies: %w", err))
+		return
+	}
+	s.jsonSuccess(w, string(data))
+}
+
+func (s *Server) handleDepOutput(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req DepsRequest
+	if err := decodeRequest(r, &req); err != nil {
+		s.jsonFailure(w, "", err)
 		return
 	}
 
-	s.jsonSuccess(w, fmt.Sprintf("Task %s marked as complete", req.AgentID))
+	output, err := s.state.DependencyOutput(req.AgentID, req.Task)
+	if err != nil {
+		s.jsonFailure(w, req.AgentID, err)
+		return
+	}
+	s.jsonSuccess(w, output)
 }
 
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
@@ -366,6 +1098,22 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 
 // Helper methods
 
+// propose records a write as a proposal in read-only runs
+func (s *Server) propose(w http.ResponseWriter, agentID, path, content string) {
+	if agentID == "" {
+		s.jsonFailure(w, "", workflow.WithField("agent_id", workflow.Errorf(workflow.ErrorInvalidRequest, "agent_id is required in read-only mode")))
+		return
+	}
+
+	proposal, err := s.proposals.Propose(agentID, path, content)
+	if err != nil {
+		s.jsonFailure(w, agentID, fmt.Errorf("failed to record proposal: %w", err))
+		return
+	}
+
+	s.jsonSuccess(w, fmt.Sprintf("Read-only mode: change to %s recorded as proposal %s", path, proposal.ID))
+}
+
 func (s *Server) jsonSuccess(w http.ResponseWriter, data string) {
 	s.jsonResponse(w, APIResponse{
 		Success: true,
@@ -373,14 +1121,100 @@ func (s *Server) jsonSuccess(w http.ResponseWriter, data string) {
 	})
 }
 
-func (s *Server) jsonError(w http.ResponseWriter, error string, statusCode int) {
-	w.WriteHeader(statusCode)
+// jsonPage responds with a page of search results, one per line
+func (s *Server) jsonPage(w http.ResponseWriter, results []string, next string) {
+	s.jsonResponse(w, APIResponse{
+		Success:    true,
+		Data:       strings.Join(results, "\n"),
+		NextCursor: next,
+	})
+}
+
+// jsonFile responds with data and the checksum of the file it concerns
+func (s *Server) jsonFile(w http.ResponseWriter, data, checksum string) {
 	s.jsonResponse(w, APIResponse{
-		Success: false,
-		Error:   error,
+		Success:  true,
+		Data:     data,
+		Checksum: checksum,
 	})
 }
 
+func (s *Server) jsonError(w http.ResponseWriter, error string, statusCode int) {
+	s.writeProblem(w, newProblem(codeForStatus(statusCode), statusCode, error))
+}
+
+// jsonFailure reports a failed operation as a problem with the error's
+// code, the matching HTTP status and the field at fault, and counts it
+// against the agent
+func (s *Server) jsonFailure(w http.ResponseWriter, agentID string, err error) {
+	code := workflow.CodeOf(err)
+	if agentID != "" {
+		s.state.RecordOperationError(agentID, code)
+	}
+
+	problem := newProblem(code, statusForCode(code), err.Error())
+	problem.Field = workflow.FieldOf(err)
+
+	// A stale file's current content lets the agent retry without a re-read
+	var stale *workflow.StaleError
+	if errors.As(err, &stale) {
+		problem.Data = stale.Content
+		problem.Checksum = stale.Current
+	}
+
+	s.writeProblem(w, problem)
+}
+
+// statusForCode maps an error code to an HTTP status
+func statusForCode(code workflow.ErrorCode) int {
+	switch code {
+	case workflow.ErrorNotFound:
+		return http.StatusNotFound
+	case workflow.ErrorPermissionDenied, workflow.ErrorSandboxViolation:
+		return http.StatusForbidden
+	case workflow.ErrorTimeout:
+		return http.StatusGatewayTimeout
+	case workflow.ErrorQuotaExceeded:
+		return http.StatusTooManyRequests
+	case workflow.ErrorConflict:
+		return http.StatusConflict
+	case workflow.ErrorChecksumMismatch, workflow.ErrorVerificationFailed:
+		return http.StatusUnprocessableEntity
+	case workflow.ErrorInvalidRequest:
+		return http.StatusBadRequest
+	case workflow.ErrorUnauthorized:
+		return http.StatusUnauthorized
+	case workflow.ErrorUnsupportedProtocol:
+		return http.StatusUpgradeRequired
+	}
+	return http.StatusInternalServerError
+}
+
+// codeForStatus maps an HTTP status to an error code
+func codeForStatus(status int) workflow.ErrorCode {
+	switch status {
+	case http.StatusNotFound:
+		return workflow.ErrorNotFound
+	case http.StatusForbidden:
+		return workflow.ErrorPermissionDenied
+	case http.StatusGatewayTimeout:
+		return workflow.ErrorTimeout
+	case http.StatusTooManyRequests:
+		return workflow.ErrorQuotaExceeded
+	case http.StatusConflict:
+		return workflow.ErrorConflict
+	case http.StatusUnprocessableEntity:
+		return workflow.ErrorChecksumMismatch
+	case http.StatusBadRequest, http.StatusMethodNotAllowed:
+		return workflow.ErrorInvalidRequest
+	case http.StatusUnauthorized:
+		return workflow.ErrorUnauthorized
+	case http.StatusUpgradeRequired:
+		return workflow.ErrorUnsupportedProtocol
+	}
+	return workflow.ErrorInternal
+}
+
 func (s *Server) jsonResponse(w http.ResponseWriter, resp APIResponse) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
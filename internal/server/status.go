@@ -0,0 +1,86 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+
+	"github.com/aristath/claude-swarm/internal/state"
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// statusPage renders the session status as a small page that refreshes
+// itself, for embedding in dashboards and portals
+var statusPage = template.Must(template.New("status").Funcs(template.FuncMap{
+	"usage": workflow.FormatUsage,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="{{.Refresh}}">
+<title>{{.Workflow}} - Claude Swarm</title>
+<style>
+body { font-family: sans-serif; margin: 1em; color: #222; }
+table { border-collapse: collapse; }
+th, td { text-align: left; padding: 0.2em 1em 0.2em 0; }
+.completed { color: #080; } .failed { color: #c00; } .running { color: #b80; }
+.pending, .cancelled, .skipped { color: #888; }
+</style>
+</head>
+<body>
+<h1>{{.Workflow}}</h1>
+<p>Session {{.Session}}: {{.State}}, {{printf "%.0f" .Progress}}% done{{if .Usage.Tokens}}, {{usage .Usage}}{{end}}</p>
+<table>
+<tr><th>Task</th><th>Status</th><th>Open questions</th></tr>
+{{range .Tasks}}<tr class="{{.Status}}"><td>{{.ID}}</td><td>{{.Status}}{{if .Error}}: {{.Error}}{{end}}{{if .Progress}} ({{.Progress}}){{end}}</td><td>{{if .Questions}}{{.Questions}}{{end}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// handleStatus serves a summary of the session and its tasks:
+// GET /api/status
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	data, err := json.Marshal(s.state.Status())
+	if err != nil {
+		s.jsonFailure(w, "", fmt.Errorf("failed to marshal status: %w", err))
+		return
+	}
+	s.jsonSuccess(w, string(data))
+}
+
+// handleStatusPage serves the session status as HTML: GET /status, with
+// ?refresh=<seconds> to change how often it reloads (5 by default)
+func (s *Server) handleStatusPage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	refresh := 5
+	if value := r.URL.Query().Get("refresh"); value != "" {
+		var err error
+		if refresh, err = strconv.Atoi(value); err != nil || refresh <= 0 {
+			s.jsonFailure(w, "", workflow.WithField("refresh", workflow.Errorf(workflow.ErrorInvalidRequest, "invalid refresh %q", value)))
+			return
+		}
+	}
+
+	page := struct {
+		Refresh int
+		state.Status
+	}{refresh, s.state.Status()}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", "frame-ancestors "+s.frameAncestors())
+	if err := statusPage.Execute(w, page); err != nil {
+		fmt.Printf("Failed to render status page: %v\n", err)
+	}
+}
@@ -0,0 +1,50 @@
+package state
+
+import (
+	"time"
+)
+
+// agentLogLimit is how many transcript lines are kept for the TUI, across
+// agents
+const agentLogLimit = 500
+
+// AgentLogEntry is a line of an agent's transcript
+type AgentLogEntry struct {
+	TaskID string
+	Text   string
+	At     time.Time // When the orchestrator read it
+}
+
+// AppendAgentLog keeps lines read from an agent's transcript, dropping the
+// oldest beyond agentLogLimit. They are not saved with the state; the
+// transcripts stay in the agent directories.
+func (s *SwarmState) AppendAgentLog(taskID string, lines []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for _, line := range lines {
+		s.agentLog = append(s.agentLog, AgentLogEntry{TaskID: taskID, Text: s.secrets.Redact(line), At: now})
+	}
+	if excess := len(s.agentLog) - agentLogLimit; excess > 0 {
+		s.agentLog = append([]AgentLogEntry(nil), s.agentLog[excess:]...)
+	}
+}
+
+// AgentLog returns up to n of the latest transcript lines of a task, or of
+// all tasks for "", oldest first
+func (s *SwarmState) AgentLog(taskID string, n int) []AgentLogEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var entries []AgentLogEntry
+	for i := len(s.agentLog) - 1; i >= 0 && len(entries) < n; i-- {
+		if taskID == "" || s.agentLog[i].TaskID == taskID {
+			entries = append(entries, s.agentLog[i])
+		}
+	}
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries
+}
@@ -0,0 +1,47 @@
+package state
+
+import "github.com/aristath/claude-swarm/internal/workflow"
+
+// PendingArtifacts returns the completed tasks declaring artifacts that
+// have not been collected yet
+func (s *SwarmState) PendingArtifacts() []workflow.Task {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var pending []workflow.Task
+	for _, task := range s.Workflow.Tasks {
+		if len(task.Artifacts) == 0 || !s.isTaskCompleted(task.ID) {
+			continue
+		}
+		if _, collected := s.Artifacts[task.ID]; !collected {
+			pending = append(pending, task)
+		}
+	}
+	return pending
+}
+
+// RecordArtifacts records the artifacts collected from a task
+func (s *SwarmState) RecordArtifacts(taskID string, paths []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.Artifacts == nil {
+		s.Artifacts = make(map[string][]string)
+	}
+	if paths == nil {
+		paths = []string{}
+	}
+	s.Artifacts[taskID] = paths
+}
+
+// GetArtifacts returns the collected artifact paths by task
+func (s *SwarmState) GetArtifacts() map[string][]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	artifacts := make(map[string][]string, len(s.Artifacts))
+	for taskID, paths := range s.Artifacts {
+		artifacts[taskID] = append([]string(nil), paths...)
+	}
+	return artifacts
+}
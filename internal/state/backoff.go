@@ -0,0 +1,40 @@
+package state
+
+import (
+	"time"
+)
+
+// recordFailure notes that a task failed, forgetting its failures that
+// are no longer recent, and returns how many recent failures it has (must
+// be called with lock held)
+func (s *SwarmState) recordFailure(taskID string) int {
+	now := time.Now()
+	s.Failures[taskID] = append(s.recentFailures(taskID, now), now)
+	return len(s.Failures[taskID])
+}
+
+// recentFailures returns a task's failures within the workflow's backoff
+// window (must be called with lock held)
+func (s *SwarmState) recentFailures(taskID string, now time.Time) []time.Time {
+	window := s.Workflow.Backoff.WindowDuration()
+	var recent []time.Time
+	for _, at := range s.Failures[taskID] {
+		if now.Sub(at) < window {
+			recent = append(recent, at)
+		}
+	}
+	return recent
+}
+
+// RetryAt returns when a task that failed recently may run again, or the
+// zero time when it need not wait
+func (s *SwarmState) RetryAt(taskID string) time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	recent := s.recentFailures(taskID, time.Now())
+	if len(recent) == 0 {
+		return time.Time{}
+	}
+	return recent[len(recent)-1].Add(s.Workflow.Backoff.Delay(len(recent)))
+}
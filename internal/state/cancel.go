@@ -0,0 +1,100 @@
+package state
+
+import (
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// SetPaused pauses or resumes spawning on behalf of an operator, reporting
+// whether it changed
+func (s *SwarmState) SetPaused(paused bool, operator string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.Paused == paused || s.CancelledAt != nil || s.HaltedAt != nil {
+		return false
+	}
+	s.Paused = paused
+	if paused {
+		s.addOperatorEvent(workflow.EventSwarmPaused, "", "", operator)
+	} else {
+		s.addOperatorEvent(workflow.EventSwarmResumed, "", "", operator)
+	}
+	return true
+}
+
+// IsPaused reports whether spawning is paused
+func (s *SwarmState) IsPaused() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.Paused
+}
+
+// Cancel marks every task that has not completed or failed as cancelled,
+// running ones included, on behalf of an operator, and returns their IDs
+// in workflow order
+func (s *SwarmState) Cancel(operator string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.CancelledAt != nil {
+		return nil
+	}
+	now := time.Now()
+	s.CancelledAt = &now
+
+	cancelled := s.cancelUnfinished(now, operator)
+	s.addOperatorEvent(workflow.EventSwarmCancelled, "", "", operator)
+	return cancelled
+}
+
+// cancelUnfinished marks the tasks that have not completed or failed as
+// cancelled and returns their IDs in workflow order (must be called with
+// lock held)
+func (s *SwarmState) cancelUnfinished(now time.Time, operator string) []string {
+	var cancelled []string
+	for _, task := range s.Workflow.Tasks {
+		agent, exists := s.Agents[task.ID]
+		if !exists {
+			agent = &workflow.AgentState{TaskID: task.ID, StartedAt: now}
+			s.Agents[task.ID] = agent
+		} else if agent.Status != workflow.TaskStatusRunning {
+			continue
+		}
+		agent.Status = workflow.TaskStatusCancelled
+		agent.FinishedAt = now
+		s.releaseLocks(task.ID)
+		cancelled = append(cancelled, task.ID)
+		s.addOperatorEvent(workflow.EventTaskCancelled, task.ID, "", operator)
+	}
+	return cancelled
+}
+
+// IsCancelled reports whether the run was cancelled
+func (s *SwarmState) IsCancelled() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.CancelledAt != nil
+}
+
+// Reopen lifts a cancellation, a halt and a pause, and forgets the cancelled
+// tasks so they run again, returning their IDs
+func (s *SwarmState) Reopen() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var reopened []string
+	for _, task := range s.Workflow.Tasks {
+		if agent, exists := s.Agents[task.ID]; exists && agent.Status == workflow.TaskStatusCancelled {
+			delete(s.Agents, task.ID)
+			reopened = append(reopened, task.ID)
+		}
+	}
+	s.CancelledAt = nil
+	s.HaltedAt = nil
+	s.Paused = false
+	return reopened
+}
@@ -0,0 +1,14 @@
+package state
+
+import (
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// RecordCheckpoint records that an operator checkpointed the run into a
+// directory
+func (s *SwarmState) RecordCheckpoint(dir, operator string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.addOperatorEvent(workflow.EventCheckpoint, "", dir, operator)
+}
@@ -0,0 +1,27 @@
+package state
+
+import (
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// SetCompletion records the metadata an agent reported as it completed its
+// task. It is set before the task completes, so dependents see it as soon
+// as they are scheduled.
+func (s *SwarmState) SetCompletion(taskID string, completion *workflow.Completion) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, exists := s.Agents[taskID]
+	if !exists {
+		return workflow.Errorf(workflow.ErrorNotFound, "agent for task %s not found", taskID)
+	}
+	if completion.Empty() {
+		agent.Completion = nil
+		return nil
+	}
+
+	redacted := *completion
+	redacted.Notes = s.secrets.Redact(completion.Notes)
+	agent.Completion = &redacted
+	return nil
+}
@@ -0,0 +1,159 @@
+package state
+
+import (
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/diff"
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// maxTrackedFile caps the size of files whose changes are compared for
+// conflicts
+const maxTrackedFile = 1 << 20
+
+// Conflict resolutions
+const (
+	ResolutionKeepFirst  = "keep_first"
+	ResolutionKeepSecond = "keep_second"
+	ResolutionMerged     = "merged"
+)
+
+// Conflict is two tasks changing the same lines of a file: the second
+// task's change overwrote or rewrote lines the first had just changed.
+// Until an operator resolves it, tasks depending on either wait.
+type Conflict struct {
+	ID     int
+	Path   string
+	First  string // Task whose change was overwritten
+	Second string // Task that changed the same lines again
+	// Contents of the file before the first change, after it and after
+	// the second
+	Base       string
+	FirstText  string
+	SecondText string
+	DetectedAt time.Time
+	Resolution string     `json:",omitempty"`
+	ResolvedBy string     `json:",omitempty"`
+	ResolvedAt *time.Time `json:",omitempty"`
+}
+
+// IsOpen reports whether the conflict awaits resolution
+func (c *Conflict) IsOpen() bool {
+	return c.Resolution == ""
+}
+
+// fileChange is the latest change to a file through the orchestrator
+type fileChange struct {
+	taskID string
+	before string
+	after  string
+}
+
+// RecordChange tracks a task's change to a file through the orchestrator,
+// and returns the conflict it causes when it changed the same lines as
+// another task's change just before it. Changes by no task, such as
+// resolutions, are tracked without conflicting.
+func (s *SwarmState) RecordChange(taskID, path, before, after string) *Conflict {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.changes == nil {
+		s.changes = make(map[string]fileChange)
+	}
+	s.recordTaskChange(taskID, path, before, after)
+
+	last, tracked := s.changes[path]
+	if len(before) > maxTrackedFile || len(after) > maxTrackedFile {
+		delete(s.changes, path)
+		return nil
+	}
+	s.changes[path] = fileChange{taskID: taskID, before: before, after: after}
+
+	if !tracked || taskID == "" || last.taskID == "" || last.taskID == taskID || last.after != before {
+		return nil
+	}
+	if !diff.Overlaps(last.before, before, after) {
+		return nil
+	}
+
+	conflict := &Conflict{
+		ID:         len(s.Conflicts) + 1,
+		Path:       path,
+		First:      last.taskID,
+		Second:     taskID,
+		Base:       last.before,
+		FirstText:  last.after,
+		SecondText: after,
+		DetectedAt: time.Now(),
+	}
+	s.Conflicts = append(s.Conflicts, conflict)
+	s.addEvent(workflow.EventFileConflict, taskID, path)
+	return conflict
+}
+
+// GetConflict returns a copy of a conflict by ID
+func (s *SwarmState) GetConflict(id int) (Conflict, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, conflict := range s.Conflicts {
+		if conflict.ID == id {
+			return *conflict, true
+		}
+	}
+	return Conflict{}, false
+}
+
+// OpenConflicts returns copies of the conflicts awaiting resolution,
+// oldest first
+func (s *SwarmState) OpenConflicts() []Conflict {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var open []Conflict
+	for _, conflict := range s.Conflicts {
+		if conflict.IsOpen() {
+			open = append(open, *conflict)
+		}
+	}
+	return open
+}
+
+// ResolveConflict records how an operator resolved a conflict, so tasks
+// depending on its tasks can run
+func (s *SwarmState) ResolveConflict(id int, resolution, operator string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, conflict := range s.Conflicts {
+		if conflict.ID != id {
+			continue
+		}
+		if !conflict.IsOpen() {
+			return workflow.Errorf(workflow.ErrorConflict, "conflict %d was already resolved (%s)", id, conflict.Resolution)
+		}
+		now := time.Now()
+		conflict.Resolution = resolution
+		conflict.ResolvedBy = operator
+		conflict.ResolvedAt = &now
+		s.addOperatorEvent(workflow.EventConflictResolved, "", conflict.Path, operator)
+		return nil
+	}
+	return workflow.Errorf(workflow.ErrorNotFound, "conflict %d not found", id)
+}
+
+// heldByConflict reports whether a task depends on a task with an open
+// conflict (must be called with lock held)
+func (s *SwarmState) heldByConflict(task workflow.Task) bool {
+	for _, conflict := range s.Conflicts {
+		if !conflict.IsOpen() {
+			continue
+		}
+		for _, depID := range task.DependsOn {
+			if depID == conflict.First || depID == conflict.Second {
+				return true
+			}
+		}
+	}
+	return false
+}
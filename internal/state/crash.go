@@ -0,0 +1,54 @@
+package state
+
+import (
+	"fmt"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// RestartCrashed records that a running task's agent process crashed,
+// exiting without completing or failing the task. While the task has
+// restarts left under its max_restarts and is not quarantined, its agent
+// is forgotten so the task runs again once its backoff is over; otherwise
+// the task fails. It reports whether the task restarts and how many times
+// its agents crashed.
+func (s *SwarmState) RestartCrashed(taskID, reason string) (bool, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, exists := s.Agents[taskID]
+	if !exists || agent.Status != workflow.TaskStatusRunning {
+		return false, 0
+	}
+
+	s.Crashes[taskID] = append(s.Crashes[taskID], s.secrets.Redact(reason))
+	crashes := len(s.Crashes[taskID])
+	s.addEvent(workflow.EventAgentCrashed, taskID, "")
+
+	var task *workflow.Task
+	for i := range s.Workflow.Tasks {
+		if s.Workflow.Tasks[i].ID == taskID {
+			task = &s.Workflow.Tasks[i]
+		}
+	}
+	failures := s.recordFailure(taskID)
+	if task == nil || crashes > s.Workflow.RestartLimit(*task) || s.Workflow.Backoff.Quarantines(failures) {
+		if crashes > 1 {
+			reason = fmt.Sprintf("%s (crashed %d times)", reason, crashes)
+		}
+		s.failTask(agent, reason, failures)
+		return false, crashes
+	}
+
+	delete(s.Agents, taskID)
+	s.releaseLocks(taskID)
+	return true, crashes
+}
+
+// GetCrashes returns why the earlier agent processes of a task crashed
+func (s *SwarmState) GetCrashes(taskID string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]string(nil), s.Crashes[taskID]...)
+}
@@ -0,0 +1,106 @@
+package state
+
+import (
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// Dependency is a task upstream of another, which it depends on directly
+// or through other tasks, as swarm-agent deps lists it
+type Dependency struct {
+	TaskID      string               `json:"task_id"`
+	Status      workflow.TaskStatus  `json:"status"`
+	Direct      bool                 `json:"direct"` // In the task's depends_on
+	OutputBytes int                  `json:"output_bytes"`
+	FinishedAt  time.Time            `json:"finished_at,omitempty"`
+	Completion  *workflow.Completion `json:"completion,omitempty"`
+}
+
+// Dependencies returns the tasks upstream of a task, in workflow order.
+// Tasks that have not started are pending.
+func (s *SwarmState) Dependencies(taskID string) ([]Dependency, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	upstream, direct, err := s.upstream(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	deps := []Dependency{}
+	for _, task := range s.Workflow.Tasks {
+		if !upstream[task.ID] {
+			continue
+		}
+		dep := Dependency{TaskID: task.ID, Status: workflow.TaskStatusPending, Direct: direct[task.ID]}
+		if agent, exists := s.Agents[task.ID]; exists {
+			dep.Status = agent.Status
+			dep.FinishedAt = agent.FinishedAt
+			dep.Completion = agent.Completion
+		}
+		if output, exists := s.outputsCache[task.ID]; exists {
+			dep.OutputBytes = len(output)
+		}
+		deps = append(deps, dep)
+	}
+	return deps, nil
+}
+
+// DependencyOutput returns the current output of a task upstream of
+// another. Only upstream tasks can be read, once they have completed.
+func (s *SwarmState) DependencyOutput(taskID, depID string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if depID == "" {
+		return "", workflow.WithField("task", workflow.Errorf(workflow.ErrorInvalidRequest, "dependency task is required"))
+	}
+	upstream, _, err := s.upstream(taskID)
+	if err != nil {
+		return "", err
+	}
+	if !upstream[depID] {
+		return "", workflow.Errorf(workflow.ErrorNotFound, "task %s does not depend on %s", taskID, depID)
+	}
+	output, exists := s.outputsCache[depID]
+	if !exists {
+		status := workflow.TaskStatusPending
+		if agent, ok := s.Agents[depID]; ok {
+			status = agent.Status
+		}
+		return "", workflow.Errorf(workflow.ErrorConflict, "task %s has no output: it is %s", depID, status)
+	}
+	return output, nil
+}
+
+// upstream returns the tasks a task depends on, directly or through other
+// tasks, and the ones it depends on directly (must be called with lock
+// held)
+func (s *SwarmState) upstream(taskID string) (map[string]bool, map[string]bool, error) {
+	dependsOn := make(map[string][]string, len(s.Workflow.Tasks))
+	for _, task := range s.Workflow.Tasks {
+		dependsOn[task.ID] = task.DependsOn
+	}
+	if _, exists := dependsOn[taskID]; !exists {
+		return nil, nil, workflow.Errorf(workflow.ErrorNotFound, "task %s not found", taskID)
+	}
+
+	direct := make(map[string]bool)
+	for _, depID := range dependsOn[taskID] {
+		direct[depID] = true
+	}
+
+	upstream := make(map[string]bool)
+	queue := append([]string(nil), dependsOn[taskID]...)
+	for len(queue) > 0 {
+		depID := queue[0]
+		queue = queue[1:]
+		if upstream[depID] || depID == taskID {
+			continue
+		}
+		upstream[depID] = true
+		queue = append(queue, dependsOn[depID]...)
+	}
+	return upstream, direct, nil
+}
@@ -0,0 +1,70 @@
+package state
+
+import (
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// EventQuery selects events from the event log. Zero fields match every
+// event.
+type EventQuery struct {
+	Types   []workflow.EventType
+	AgentID string
+	// After and AfterSeq select the events that happened after a time and
+	// after an event of the log
+	After    time.Time
+	AfterSeq int64
+	// Limit bounds the events returned
+	Limit int
+}
+
+// matches reports whether an event is selected by the query
+func (q EventQuery) matches(event workflow.FileEvent) bool {
+	if event.Seq <= q.AfterSeq {
+		return false
+	}
+	if !q.After.IsZero() && !event.Time.After(q.After) {
+		return false
+	}
+	if q.AgentID != "" && event.AgentID != q.AgentID {
+		return false
+	}
+	if len(q.Types) == 0 {
+		return true
+	}
+	for _, eventType := range q.Types {
+		if event.Type == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// QueryEvents returns the events a query selects, oldest first, and
+// whether more follow the limit
+func (s *SwarmState) QueryEvents(q EventQuery) ([]workflow.FileEvent, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	events := []workflow.FileEvent{}
+	for _, event := range s.Events {
+		if !q.matches(event) {
+			continue
+		}
+		if q.Limit > 0 && len(events) == q.Limit {
+			return events, true
+		}
+		events = append(events, event)
+	}
+	return events, false
+}
+
+// nextSeq returns the number of the next event of the log (must be called
+// with lock held)
+func (s *SwarmState) nextSeq() int64 {
+	if len(s.Events) == 0 {
+		return 1
+	}
+	return s.Events[len(s.Events)-1].Seq + 1
+}
@@ -0,0 +1,109 @@
+package state
+
+import (
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// HaltingFailures returns the failed tasks that halt the run under the halt
+// failure policy: those no failure handler takes care of. It returns
+// nothing under other policies or once the run halted.
+func (s *SwarmState) HaltingFailures() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.Workflow.OnTaskFailure() != workflow.FailurePolicyHalt || s.HaltedAt != nil || s.CancelledAt != nil {
+		return nil
+	}
+
+	handled := make(map[string]bool)
+	for _, task := range s.Workflow.Tasks {
+		for _, failedID := range task.FailureOf {
+			handled[failedID] = true
+		}
+	}
+
+	var failed []string
+	for _, task := range s.Workflow.Tasks {
+		if agent, exists := s.Agents[task.ID]; exists && agent.Status.IsFailure() && !handled[task.ID] {
+			failed = append(failed, task.ID)
+		}
+	}
+	return failed
+}
+
+// Halt stops the run after a failure: like Cancel, the tasks that have not
+// finished are marked cancelled, but the run ends as failed. It returns
+// the cancelled tasks in workflow order.
+func (s *SwarmState) Halt() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.HaltedAt != nil || s.CancelledAt != nil {
+		return nil
+	}
+	now := time.Now()
+	s.HaltedAt = &now
+	return s.cancelUnfinished(now, "")
+}
+
+// IsHalted reports whether a failure halted the run
+func (s *SwarmState) IsHalted() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.HaltedAt != nil
+}
+
+// BlockedBy returns the failed task a pending task waits for, directly or
+// through its dependencies, or "" if it is not blocked. Under the ignore
+// failure policy no task is blocked.
+func (s *SwarmState) BlockedBy(taskID string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.Workflow.OnTaskFailure() == workflow.FailurePolicyIgnore {
+		return ""
+	}
+	return s.blockedBy(taskID, make(map[string]bool))
+}
+
+// blockedBy finds a failed task among a task's dependencies (must be
+// called with lock held)
+func (s *SwarmState) blockedBy(taskID string, seen map[string]bool) string {
+	if seen[taskID] {
+		return ""
+	}
+	seen[taskID] = true
+
+	for _, task := range s.Workflow.Tasks {
+		if task.ID != taskID {
+			continue
+		}
+		for _, depID := range task.DependsOn {
+			if agent, exists := s.Agents[depID]; exists && agent.Status.IsFailure() {
+				return depID
+			}
+			if _, exists := s.Agents[depID]; !exists {
+				if failed := s.blockedBy(depID, seen); failed != "" {
+					return failed
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// isTaskDone reports whether a task is done: it completed or was skipped,
+// or failed under the ignore failure policy (must be called with lock
+// held)
+func (s *SwarmState) isTaskDone(taskID string) bool {
+	if s.isTaskCompleted(taskID) {
+		return true
+	}
+	if agent, exists := s.Agents[taskID]; exists && agent.Status == workflow.TaskStatusSkipped {
+		return true
+	}
+	return s.Workflow.OnTaskFailure() == workflow.FailurePolicyIgnore && s.anyTaskFailed([]string{taskID})
+}
@@ -0,0 +1,36 @@
+package state
+
+import (
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// Heartbeat records a sign of life from a running agent, which is no
+// longer stale
+func (s *SwarmState) Heartbeat(taskID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, exists := s.Agents[taskID]
+	if !exists || agent.Status != workflow.TaskStatusRunning {
+		return
+	}
+	agent.LastHeartbeat = time.Now()
+	agent.Stale = false
+}
+
+// MarkStale marks a running agent stale, reporting whether it was not
+// already
+func (s *SwarmState) MarkStale(taskID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, exists := s.Agents[taskID]
+	if !exists || agent.Status != workflow.TaskStatusRunning || agent.Stale {
+		return false
+	}
+	agent.Stale = true
+	s.addEvent(workflow.EventAgentStale, taskID, "")
+	return true
+}
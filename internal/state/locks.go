@@ -0,0 +1,123 @@
+package state
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// DefaultLockTTL is how long a lock lasts unless the agent asks otherwise.
+// Agents that crash or forget to unlock lose their locks when they expire.
+const DefaultLockTTL = 5 * time.Minute
+
+// FileLock is an advisory lock held by an agent on a path. Locks are not
+// enforced on writes; agents that share files check them to coordinate.
+type FileLock struct {
+	Path       string    `json:"path"`
+	Owner      string    `json:"owner"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// LockError is returned when a path is locked by another agent
+type LockError struct {
+	Lock FileLock
+}
+
+func (e *LockError) Error() string {
+	return fmt.Sprintf("%s is locked by %s until %s", e.Lock.Path, e.Lock.Owner, e.Lock.ExpiresAt.Format(time.RFC3339))
+}
+
+// ErrorCode classifies lock errors for responses
+func (e *LockError) ErrorCode() workflow.ErrorCode {
+	return workflow.ErrorConflict
+}
+
+// AcquireLock locks a path for an agent. Locking a path the agent already
+// holds extends the lock. A zero ttl uses DefaultLockTTL.
+func (s *SwarmState) AcquireLock(taskID, path string, ttl time.Duration) (FileLock, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if taskID == "" {
+		return FileLock{}, workflow.WithField("agent_id", workflow.Errorf(workflow.ErrorInvalidRequest, "lock owner is required"))
+	}
+	if path == "" {
+		return FileLock{}, workflow.WithField("path", workflow.Errorf(workflow.ErrorInvalidRequest, "lock path is required"))
+	}
+	if ttl <= 0 {
+		ttl = DefaultLockTTL
+	}
+	path = filepath.Clean(path)
+	now := time.Now()
+
+	if lock, ok := s.Locks[path]; ok && lock.Owner != taskID && now.Before(lock.ExpiresAt) {
+		return FileLock{}, &LockError{Lock: *lock}
+	}
+
+	lock, ok := s.Locks[path]
+	if !ok || lock.Owner != taskID || !now.Before(lock.ExpiresAt) {
+		lock = &FileLock{Path: path, Owner: taskID, AcquiredAt: now}
+		s.Locks[path] = lock
+		s.addEvent(workflow.EventLockAcquired, taskID, path)
+	}
+	lock.ExpiresAt = now.Add(ttl)
+
+	return *lock, nil
+}
+
+// ReleaseLock releases an agent's lock on a path
+func (s *SwarmState) ReleaseLock(taskID, path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path = filepath.Clean(path)
+	lock, ok := s.Locks[path]
+	if !ok || !time.Now().Before(lock.ExpiresAt) {
+		delete(s.Locks, path)
+		return workflow.Errorf(workflow.ErrorNotFound, "%s is not locked", path)
+	}
+	if lock.Owner != taskID {
+		return &LockError{Lock: *lock}
+	}
+
+	delete(s.Locks, path)
+	s.addEvent(workflow.EventLockReleased, taskID, path)
+
+	return nil
+}
+
+// GetLocks returns the unexpired locks, sorted by path
+func (s *SwarmState) GetLocks() []FileLock {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	locks := make([]FileLock, 0, len(s.Locks))
+	for path, lock := range s.Locks {
+		if !now.Before(lock.ExpiresAt) {
+			delete(s.Locks, path)
+			continue
+		}
+		locks = append(locks, *lock)
+	}
+
+	sort.Slice(locks, func(i, j int) bool {
+		return locks[i].Path < locks[j].Path
+	})
+
+	return locks
+}
+
+// releaseLocks drops every lock an agent holds. Callers must hold s.mu.
+func (s *SwarmState) releaseLocks(taskID string) {
+	for path, lock := range s.Locks {
+		if lock.Owner == taskID {
+			delete(s.Locks, path)
+			s.addEvent(workflow.EventLockReleased, taskID, path)
+		}
+	}
+}
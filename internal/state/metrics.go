@@ -0,0 +1,203 @@
+package state
+
+import (
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// statsWindow is how far back rates are computed
+const statsWindow = 5 * time.Minute
+
+// Metrics counts orchestrator-level activity over a session
+type Metrics struct {
+	FileReads        int           // Files read through the orchestrator
+	CacheHits        int           // Reads served from the file cache
+	CacheBytesSaved  int64         // Bytes the cache saved reading from disk
+	Coalesced        int           // Operations that shared an identical operation already running
+	Operations       int           // Operations handled over the message bus and HTTP API
+	OperationLatency time.Duration // Total time from receiving operations to responding
+	BashRuns         int           // Bash commands run
+	EventsDeferred   int           // File events that waited because the event loop was busy
+	EventBacklogPeak int           // Most file events waiting at once
+	EventsRecovered  int           // Files found by a rescan or poll after the file watcher missed their events
+	MessagesDeleted  int           // Answered file-bus messages deleted once past their TTL
+	MessagesArchived int           // Answered file-bus messages archived once past their TTL
+	Duplicates       int           // Messages delivered again, answered without running again
+}
+
+// activity keeps the times of recent operations, within statsWindow
+type activity struct {
+	operations []time.Time
+	bash       []time.Time
+}
+
+// Stats summarizes a session's throughput and waits, for tuning
+// concurrency and spotting bottlenecks
+type Stats struct {
+	Metrics
+	// Rates over the last few minutes, or the session so far if shorter
+	OperationsPerMinute float64
+	BashPerMinute       float64
+	AverageLatency      time.Duration
+	// Questions answered, and how long agents waited for the answers
+	QuestionsAnswered   int
+	AverageQuestionWait time.Duration
+	LongestQuestionWait time.Duration
+	// Questions still waiting for an answer, and the longest wait so far
+	QuestionsWaiting   int
+	LongestPendingWait time.Duration
+}
+
+// CacheHitRate returns the share of file reads served from the cache, 0-100
+func (m Metrics) CacheHitRate() float64 {
+	if m.FileReads == 0 {
+		return 0
+	}
+	return float64(m.CacheHits) / float64(m.FileReads) * 100
+}
+
+// RecordFileRead counts a file read, and whether the cache served it
+func (s *SwarmState) RecordFileRead(cacheHit bool, bytes int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Metrics.FileReads++
+	if cacheHit {
+		s.Metrics.CacheHits++
+		s.Metrics.CacheBytesSaved += int64(bytes)
+	}
+}
+
+// GetMetrics returns a snapshot of the session metrics
+func (s *SwarmState) GetMetrics() Metrics {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.Metrics
+}
+
+// RecordOperation counts an operation handled for an agent, and how long
+// it took to respond
+func (s *SwarmState) RecordOperation(latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.Metrics.Operations++
+	s.Metrics.OperationLatency += latency
+	s.recent.operations = append(trimWindow(s.recent.operations, now), now)
+}
+
+// RecordBash counts a bash command run for an agent
+func (s *SwarmState) RecordBash() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.Metrics.BashRuns++
+	s.recent.bash = append(trimWindow(s.recent.bash, now), now)
+}
+
+// GetStats computes the session's throughput, latency and question waits
+func (s *SwarmState) GetStats() Stats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	stats := Stats{Metrics: s.Metrics}
+
+	if s.Metrics.Operations > 0 {
+		stats.AverageLatency = s.Metrics.OperationLatency / time.Duration(s.Metrics.Operations)
+	}
+
+	window := statsWindow
+	if elapsed := now.Sub(s.StartedAt); elapsed < window {
+		window = elapsed
+	}
+	if minutes := window.Minutes(); minutes > 0 {
+		stats.OperationsPerMinute = float64(countSince(s.recent.operations, now.Add(-window))) / minutes
+		stats.BashPerMinute = float64(countSince(s.recent.bash, now.Add(-window))) / minutes
+	}
+
+	var totalWait time.Duration
+	for _, agent := range s.Agents {
+		for _, question := range agent.AllQuestions() {
+			if !question.AnsweredAt.IsZero() {
+				wait := question.AnsweredAt.Sub(question.AskedAt)
+				stats.QuestionsAnswered++
+				totalWait += wait
+				stats.LongestQuestionWait = max(stats.LongestQuestionWait, wait)
+			} else if agent.Status == workflow.TaskStatusRunning {
+				stats.QuestionsWaiting++
+				stats.LongestPendingWait = max(stats.LongestPendingWait, now.Sub(question.AskedAt))
+			}
+		}
+	}
+	if stats.QuestionsAnswered > 0 {
+		stats.AverageQuestionWait = totalWait / time.Duration(stats.QuestionsAnswered)
+	}
+
+	return stats
+}
+
+// trimWindow drops times older than statsWindow
+func trimWindow(times []time.Time, now time.Time) []time.Time {
+	cutoff := now.Add(-statsWindow)
+	i := 0
+	for i < len(times) && times[i].Before(cutoff) {
+		i++
+	}
+	return times[i:]
+}
+
+// countSince counts the times at or after since
+func countSince(times []time.Time, since time.Time) int {
+	count := 0
+	for _, t := range times {
+		if !t.Before(since) {
+			count++
+		}
+	}
+	return count
+}
+
+// RecordCoalesced counts an operation that shared another's result
+func (s *SwarmState) RecordCoalesced() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Metrics.Coalesced++
+}
+
+// RecordEventDeferred counts a file event that had to wait for the event
+// loop, and how many were waiting
+func (s *SwarmState) RecordEventDeferred(backlog int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Metrics.EventsDeferred++
+	s.Metrics.EventBacklogPeak = max(s.Metrics.EventBacklogPeak, backlog)
+}
+
+// RecordEventsRecovered counts files a rescan or poll found after the file
+// watcher missed their events
+func (s *SwarmState) RecordEventsRecovered(count int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Metrics.EventsRecovered += count
+}
+
+// RecordMessagesCleaned counts answered messages the janitor deleted or
+// archived
+func (s *SwarmState) RecordMessagesCleaned(count int, archived bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if archived {
+		s.Metrics.MessagesArchived += count
+	} else {
+		s.Metrics.MessagesDeleted += count
+	}
+}
@@ -0,0 +1,45 @@
+package state
+
+import (
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// SaveOutputCheckpoint keeps partial output from a running agent, replacing
+// the one it saved before, for the next attempt at its task should it not
+// complete. Completing the task discards it.
+func (s *SwarmState) SaveOutputCheckpoint(taskID, output string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, exists := s.Agents[taskID]
+	if !exists {
+		return workflow.Errorf(workflow.ErrorNotFound, "agent for task %s not found", taskID)
+	}
+	if agent.Status != workflow.TaskStatusRunning {
+		return workflow.Errorf(workflow.ErrorConflict, "task %s is not running", taskID)
+	}
+	if output == "" {
+		return workflow.Errorf(workflow.ErrorInvalidRequest, "checkpoint output is required")
+	}
+
+	if s.OutputCheckpoints == nil {
+		s.OutputCheckpoints = make(map[string]workflow.OutputCheckpoint)
+	}
+	s.OutputCheckpoints[taskID] = workflow.OutputCheckpoint{
+		Output:  s.secrets.Redact(output),
+		SavedAt: time.Now(),
+	}
+	return nil
+}
+
+// GetOutputCheckpoint returns the partial output an earlier agent of a
+// task saved, if there is one
+func (s *SwarmState) GetOutputCheckpoint(taskID string) (workflow.OutputCheckpoint, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	checkpoint, exists := s.OutputCheckpoints[taskID]
+	return checkpoint, exists
+}
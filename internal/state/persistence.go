@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/aristath/claude-swarm/internal/workflow"
 )
@@ -63,8 +64,44 @@ func (p *Persistence) Load() (*SwarmState, error) {
 	if state.Agents == nil {
 		state.Agents = make(map[string]*workflow.AgentState)
 	}
-	if state.outputsCache == nil {
-		state.outputsCache = make(map[string]string)
+	if state.Locks == nil {
+		state.Locks = make(map[string]*FileLock)
+	}
+	if state.Iterations == nil {
+		state.Iterations = make(map[string][]string)
+	}
+	if state.Crashes == nil {
+		state.Crashes = make(map[string][]string)
+	}
+	if state.Verifications == nil {
+		state.Verifications = make(map[string][]string)
+	}
+	if state.Failures == nil {
+		state.Failures = make(map[string][]time.Time)
+	}
+	if state.Artifacts == nil {
+		state.Artifacts = make(map[string][]string)
+	}
+	if state.Processed == nil {
+		state.Processed = make(map[string][]ProcessedMessage)
+	}
+	if state.OutputCheckpoints == nil {
+		state.OutputCheckpoints = make(map[string]workflow.OutputCheckpoint)
+	}
+
+	// Events saved before they were numbered are numbered in order
+	for i := range state.Events {
+		if state.Events[i].Seq == 0 {
+			state.Events[i].Seq = int64(i + 1)
+		}
+	}
+
+	// Rebuild the outputs of completed and skipped tasks for interpolation
+	state.outputsCache = make(map[string]string)
+	for taskID, agent := range state.Agents {
+		if agent.Status == workflow.TaskStatusCompleted || agent.Status == workflow.TaskStatusSkipped {
+			state.outputsCache[taskID] = agent.Output
+		}
 	}
 
 	return &state, nil
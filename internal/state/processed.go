@@ -0,0 +1,79 @@
+package state
+
+import (
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// maxProcessedMessages is how many of each agent's latest messages are
+// remembered against duplicate delivery
+const maxProcessedMessages = 100
+
+// processedDataLimit caps the data kept of a remembered response; longer
+// data keeps its end
+const processedDataLimit = 4 << 10
+
+// ProcessedMessage is a handled message and the response it got
+type ProcessedMessage struct {
+	ID       string
+	Response workflow.Response
+}
+
+// RecordProcessed remembers an agent's handled message and its response,
+// so a duplicate of it gets the response instead of running again. The
+// data of the response, and of each result of a batch, is truncated and
+// redacted before it is kept.
+func (s *SwarmState) RecordProcessed(agentID, messageID string, response workflow.Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	response = s.processedResponse(response)
+	if len(response.Results) > 0 {
+		results := make([]workflow.Response, len(response.Results))
+		for i, result := range response.Results {
+			results[i] = s.processedResponse(result)
+		}
+		response.Results = results
+	}
+
+	if s.Processed == nil {
+		s.Processed = make(map[string][]ProcessedMessage)
+	}
+	processed := append(s.Processed[agentID], ProcessedMessage{ID: messageID, Response: response})
+	if len(processed) > maxProcessedMessages {
+		processed = processed[len(processed)-maxProcessedMessages:]
+	}
+	s.Processed[agentID] = processed
+}
+
+// processedResponse truncates and redacts a response's data for keeping
+// (must be called with lock held)
+func (s *SwarmState) processedResponse(response workflow.Response) workflow.Response {
+	if len(response.Data) > processedDataLimit {
+		response.Data = "...(truncated)\n" + response.Data[len(response.Data)-processedDataLimit:]
+		response.Checksum = ""
+	}
+	response.Data = s.secrets.Redact(response.Data)
+	return response
+}
+
+// ProcessedResponse returns the response an agent's message got, if it was
+// handled before
+func (s *SwarmState) ProcessedResponse(agentID, messageID string) (workflow.Response, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, processed := range s.Processed[agentID] {
+		if processed.ID == messageID {
+			return processed.Response, true
+		}
+	}
+	return workflow.Response{}, false
+}
+
+// RecordDuplicate counts a message delivered again
+func (s *SwarmState) RecordDuplicate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Metrics.Duplicates++
+}
@@ -0,0 +1,34 @@
+package state
+
+import (
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// RecordProgress stores the status a running agent reported and how far
+// along it is, if it said, and emits EventTaskProgress
+func (s *SwarmState) RecordProgress(taskID, text string, percent *int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, exists := s.Agents[taskID]
+	if !exists {
+		return workflow.Errorf(workflow.ErrorNotFound, "agent for task %s not found", taskID)
+	}
+	if agent.Status != workflow.TaskStatusRunning {
+		return workflow.Errorf(workflow.ErrorConflict, "task %s is not running", taskID)
+	}
+	if text == "" && percent == nil {
+		return workflow.Errorf(workflow.ErrorInvalidRequest, "progress needs a status or a percentage")
+	}
+	if percent != nil && (*percent < 0 || *percent > 100) {
+		return workflow.Errorf(workflow.ErrorInvalidRequest, "percent must be between 0 and 100, got %d", *percent)
+	}
+
+	agent.Progress = text
+	agent.ProgressPercent = percent
+	agent.ProgressAt = time.Now()
+	s.addEvent(workflow.EventTaskProgress, taskID, "")
+	return nil
+}
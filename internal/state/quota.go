@@ -0,0 +1,141 @@
+package state
+
+import (
+	"fmt"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// OperationKind classifies operations counted against quotas
+type OperationKind string
+
+const (
+	OperationWrite OperationKind = "write"
+	OperationBash  OperationKind = "bash"
+)
+
+// QuotaError is returned when an agent's operations are paused because a
+// quota was exceeded
+type QuotaError struct {
+	TaskID string
+	Reason string
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("quota exceeded for task %s: %s; operations paused pending operator approval", e.TaskID, e.Reason)
+}
+
+// ErrorCode classifies quota errors for responses
+func (e *QuotaError) ErrorCode() workflow.ErrorCode {
+	return workflow.ErrorQuotaExceeded
+}
+
+// ReserveOperation checks an operation against the agent's quotas and
+// records its usage. Operations must come from a running agent, so a
+// missing or made-up agent ID cannot skip the quotas. If the agent is
+// paused, or the operation would exceed a quota, the agent is paused and a
+// *QuotaError is returned.
+func (s *SwarmState) ReserveOperation(taskID string, kind OperationKind, path string, bytes int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, exists := s.Agents[taskID]
+	if !exists || agent.Status != workflow.TaskStatusRunning {
+		return workflow.WithField("agent_id", workflow.Errorf(workflow.ErrorPermissionDenied, "no running agent for task %q", taskID))
+	}
+
+	if agent.QuotaPaused {
+		return &QuotaError{TaskID: taskID, Reason: agent.QuotaPausedReason}
+	}
+
+	quotas := s.quotasFor(taskID)
+	usage := &agent.Usage
+
+	var reason string
+	switch kind {
+	case OperationWrite:
+		newFile := !containsString(usage.FilesWritten, path)
+		if quotas.MaxFilesWritten > 0 && newFile && len(usage.FilesWritten)+1 > quotas.MaxFilesWritten {
+			reason = fmt.Sprintf("max_files_written (%d) reached", quotas.MaxFilesWritten)
+		} else if quotas.MaxBytesWritten > 0 && usage.BytesWritten+bytes > quotas.MaxBytesWritten {
+			reason = fmt.Sprintf("max_bytes_written (%d) reached", quotas.MaxBytesWritten)
+		} else {
+			if newFile {
+				usage.FilesWritten = append(usage.FilesWritten, path)
+			}
+			usage.BytesWritten += bytes
+		}
+
+	case OperationBash:
+		if quotas.MaxBashInvocations > 0 && usage.BashInvocations+1 > quotas.MaxBashInvocations {
+			reason = fmt.Sprintf("max_bash_invocations (%d) reached", quotas.MaxBashInvocations)
+		} else {
+			usage.BashInvocations++
+		}
+	}
+
+	if reason == "" {
+		return nil
+	}
+
+	agent.QuotaPaused = true
+	agent.QuotaPausedReason = reason
+	s.addEvent(workflow.EventQuotaExceeded, taskID, path)
+
+	return &QuotaError{TaskID: taskID, Reason: reason}
+}
+
+// ApproveQuota lets a paused agent continue, granting it a fresh quota.
+// The operator who approved is recorded with the event.
+func (s *SwarmState) ApproveQuota(taskID, operator string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, exists := s.Agents[taskID]
+	if !exists {
+		return fmt.Errorf("agent for task %s not found", taskID)
+	}
+
+	agent.QuotaPaused = false
+	agent.QuotaPausedReason = ""
+	agent.Usage = workflow.QuotaUsage{}
+
+	s.addOperatorEvent(workflow.EventQuotaApproved, taskID, "", operator)
+
+	return nil
+}
+
+// GetQuotaPausedAgents returns the agents waiting for quota approval
+func (s *SwarmState) GetQuotaPausedAgents() []*workflow.AgentState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	paused := []*workflow.AgentState{}
+	for _, agent := range s.Agents {
+		if agent.QuotaPaused {
+			paused = append(paused, agent)
+		}
+	}
+
+	return paused
+}
+
+// quotasFor returns the effective quotas of a task (must be called with lock held)
+func (s *SwarmState) quotasFor(taskID string) workflow.Quotas {
+	for _, task := range s.Workflow.Tasks {
+		if task.ID == taskID {
+			return s.Workflow.Quotas.Merge(task.Quotas)
+		}
+	}
+	return s.Workflow.Quotas.Merge(nil)
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package state
+
+import (
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// RerunTask forgets a failed or quarantined task's agent on behalf of an
+// operator, so the task runs again from scratch, or from the checkpoint
+// its agents last saved
+func (s *SwarmState) RerunTask(taskID, operator string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, exists := s.Agents[taskID]
+	if !exists || !agent.Status.IsFailure() {
+		if !s.hasTask(taskID) {
+			return workflow.Errorf(workflow.ErrorNotFound, "task %s not found", taskID)
+		}
+		return workflow.Errorf(workflow.ErrorConflict, "task %s has not failed", taskID)
+	}
+
+	delete(s.Agents, taskID)
+	delete(s.Iterations, taskID)
+	delete(s.Crashes, taskID)
+	delete(s.Verifications, taskID)
+	delete(s.Failures, taskID)
+	delete(s.Processed, taskID)
+	s.releaseLocks(taskID)
+
+	s.addOperatorEvent(workflow.EventTaskRerun, taskID, "", operator)
+	return nil
+}
+
+// SkipTask marks a pending, failed or quarantined task skipped on behalf of an
+// operator, so its dependents run with an empty output in its place
+func (s *SwarmState) SkipTask(taskID, operator string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.hasTask(taskID) {
+		return workflow.Errorf(workflow.ErrorNotFound, "task %s not found", taskID)
+	}
+	if agent, exists := s.Agents[taskID]; exists && !agent.Status.IsFailure() {
+		return workflow.Errorf(workflow.ErrorConflict, "task %s is %s", taskID, agent.Status)
+	}
+
+	now := time.Now()
+	s.Agents[taskID] = &workflow.AgentState{
+		TaskID:     taskID,
+		Status:     workflow.TaskStatusSkipped,
+		StartedAt:  now,
+		FinishedAt: now,
+	}
+	s.outputsCache[taskID] = ""
+	s.releaseLocks(taskID)
+
+	s.addOperatorEvent(workflow.EventTaskSkipped, taskID, "", operator)
+	return nil
+}
+
+// hasTask reports whether the workflow has a task (must be called with
+// lock held)
+func (s *SwarmState) hasTask(taskID string) bool {
+	for _, task := range s.Workflow.Tasks {
+		if task.ID == taskID {
+			return true
+		}
+	}
+	return false
+}
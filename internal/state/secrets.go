@@ -0,0 +1,28 @@
+package state
+
+import "github.com/aristath/claude-swarm/internal/workflow"
+
+// SetSecrets sets the resolved secret values to interpolate into commands
+// and redact from everything the state records
+func (s *SwarmState) SetSecrets(values workflow.SecretValues) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.secrets = values
+}
+
+// InterpolateSecrets replaces {secrets.name} references with secret values
+func (s *SwarmState) InterpolateSecrets(text string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.secrets.Interpolate(text)
+}
+
+// Redact replaces secret values in text
+func (s *SwarmState) Redact(text string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.secrets.Redact(text)
+}
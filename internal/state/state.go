@@ -2,6 +2,7 @@ package state
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -10,29 +11,69 @@ import (
 
 // SwarmState represents the complete state of a swarm orchestration session
 type SwarmState struct {
-	SessionID       string
-	Plan            string
-	Workflow        *workflow.Workflow
-	Agents          map[string]*workflow.AgentState
-	CompletedTasks  []string
-	Events          []workflow.FileEvent
-	StartedAt       time.Time
-	CompletedAt     *time.Time
-	mu              sync.RWMutex
-	outputsCache    map[string]string // Cache of task outputs
+	SessionID      string
+	Plan           string
+	Workflow       *workflow.Workflow
+	Agents         map[string]*workflow.AgentState
+	CompletedTasks []string
+	Events         []workflow.FileEvent
+	Locks          map[string]*FileLock   // Advisory file locks by path
+	Iterations     map[string][]string    // Outputs of the earlier runs of repeated tasks
+	Crashes        map[string][]string    // Why the earlier agent processes of tasks crashed
+	Verifications  map[string][]string    // Reports of the failed verifications of tasks
+	Failures       map[string][]time.Time // When tasks recently failed, for backoff
+	Artifacts      map[string][]string    // Collected artifact paths by task
+	Metrics        Metrics
+	StartedAt      time.Time
+	CompletedAt    *time.Time
+	Paused         bool       // No agents are spawned while paused
+	CancelledAt    *time.Time // Set when the run was cancelled
+	HaltedAt       *time.Time // Set when a failure halted the run
+	// Tokens is the usage agents reported for the whole run, including
+	// agents since rerun
+	Tokens workflow.TokenUsage
+	// Processed holds each agent's latest handled messages, so duplicate
+	// deliveries are answered without running again
+	Processed map[string][]ProcessedMessage `json:",omitempty"`
+	// Conflicts are tasks that changed the same lines of a file
+	Conflicts []*Conflict `json:",omitempty"`
+	// OutputCheckpoints are the partial outputs agents of unfinished
+	// tasks saved, for their task's next attempt
+	OutputCheckpoints map[string]workflow.OutputCheckpoint `json:",omitempty"`
+	// BudgetExceededAt is set when the reported usage first went over
+	// the workflow's budget
+	BudgetExceededAt *time.Time `json:",omitempty"`
+	mu               sync.RWMutex
+	outputsCache     map[string]string // Cache of task outputs
+	subscribers      []chan workflow.FileEvent
+	recent           activity // Recent operations, for rates
+	secrets          workflow.SecretValues
+	changes          map[string]fileChange // Latest change to each file, for conflicts
+	agentLog         []AgentLogEntry       // Latest transcript lines of agents, for the TUI
+	taskChanges      taskChanges           // Net changes of each task, for the changelog
+	// fileMu makes checking a file and writing it atomic between agents,
+	// whether they write through the message handler or the API server
+	fileMu sync.Mutex
 }
 
 // NewSwarmState creates a new swarm state
 func NewSwarmState(sessionID string, plan string, wf *workflow.Workflow) *SwarmState {
 	return &SwarmState{
-		SessionID:    sessionID,
-		Plan:         plan,
-		Workflow:     wf,
-		Agents:       make(map[string]*workflow.AgentState),
+		SessionID:      sessionID,
+		Plan:           plan,
+		Workflow:       wf,
+		Agents:         make(map[string]*workflow.AgentState),
 		CompletedTasks: []string{},
-		Events:       []workflow.FileEvent{},
-		StartedAt:    time.Now(),
-		outputsCache: make(map[string]string),
+		Events:         []workflow.FileEvent{},
+		Locks:          make(map[string]*FileLock),
+		Iterations:     make(map[string][]string),
+		Crashes:        make(map[string][]string),
+		Verifications:  make(map[string][]string),
+		Failures:       make(map[string][]time.Time),
+		Artifacts:      make(map[string][]string),
+		Processed:      make(map[string][]ProcessedMessage),
+		StartedAt:      time.Now(),
+		outputsCache:   make(map[string]string),
 	}
 }
 
@@ -52,6 +93,8 @@ func (s *SwarmState) AddAgent(taskID, workingDir string) error {
 		Questions:  []workflow.Question{},
 		FollowUps:  []workflow.FollowUp{},
 		WorkingDir: workingDir,
+		Iteration:  len(s.Iterations[taskID]) + 1,
+		Restarts:   len(s.Crashes[taskID]),
 	}
 
 	s.addEvent(workflow.EventTaskStarted, taskID, "")
@@ -59,7 +102,9 @@ func (s *SwarmState) AddAgent(taskID, workingDir string) error {
 	return nil
 }
 
-// CompleteTask marks a task as completed
+// CompleteTask marks a task as completed. A task repeating until its
+// output meets a condition is queued to run again instead, or fails once
+// it runs out of iterations.
 func (s *SwarmState) CompleteTask(taskID, output string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -68,18 +113,96 @@ func (s *SwarmState) CompleteTask(taskID, output string) error {
 	if !exists {
 		return fmt.Errorf("agent for task %s not found", taskID)
 	}
+	if agent.Status == workflow.TaskStatusCancelled {
+		return fmt.Errorf("task %s was cancelled", taskID)
+	}
+	output = s.secrets.Redact(output)
+	delete(s.OutputCheckpoints, taskID)
+
+	if repeat := s.repeatCondition(taskID); repeat != nil && !repeat.Met(output) {
+		if agent.Iteration < repeat.Limit() {
+			s.repeatTask(taskID, output)
+			return nil
+		}
+		agent.Status = workflow.TaskStatusFailed
+		agent.Output = output
+		agent.Error = fmt.Sprintf("output still does not meet repeat_until (%s) after %d iterations", repeat, agent.Iteration)
+		agent.FinishedAt = time.Now()
+		s.releaseLocks(taskID)
+		s.addEvent(workflow.EventTaskFailed, taskID, "")
+		return nil
+	}
 
 	agent.Status = workflow.TaskStatusCompleted
 	agent.Output = output
+	agent.FinishedAt = time.Now()
 
 	s.CompletedTasks = append(s.CompletedTasks, taskID)
 	s.outputsCache[taskID] = output
+	s.releaseLocks(taskID)
 
 	s.addEvent(workflow.EventTaskCompleted, taskID, "")
 
 	return nil
 }
 
+// repeatCondition returns a task's repeat_until condition (must be called
+// with lock held)
+func (s *SwarmState) repeatCondition(taskID string) *workflow.RepeatUntil {
+	for _, task := range s.Workflow.Tasks {
+		if task.ID == taskID {
+			return task.RepeatUntil
+		}
+	}
+	return nil
+}
+
+// repeatTask keeps an iteration's output and removes its agent, so the
+// task is ready to run again (must be called with lock held)
+func (s *SwarmState) repeatTask(taskID, output string) {
+	if s.Iterations == nil {
+		s.Iterations = make(map[string][]string)
+	}
+	s.Iterations[taskID] = append(s.Iterations[taskID], output)
+	delete(s.Agents, taskID)
+	s.releaseLocks(taskID)
+
+	s.addEvent(workflow.EventTaskRepeated, taskID, "")
+}
+
+// MarkOverdue marks a running task overdue, reporting whether it was not
+// already
+func (s *SwarmState) MarkOverdue(taskID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, exists := s.Agents[taskID]
+	if !exists || agent.Status != workflow.TaskStatusRunning || agent.Overdue {
+		return false
+	}
+	agent.Overdue = true
+	s.addEvent(workflow.EventTaskOverdue, taskID, "")
+	return true
+}
+
+// ResetTask forgets a task's agent, so the task is ready to run again,
+// such as when its agent was lost with an interrupted orchestrator
+func (s *SwarmState) ResetTask(taskID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.Agents, taskID)
+	s.releaseLocks(taskID)
+}
+
+// GetIterations returns the outputs of the earlier runs of a repeated task
+func (s *SwarmState) GetIterations(taskID string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]string(nil), s.Iterations[taskID]...)
+}
+
 // FailTask marks a task as failed
 func (s *SwarmState) FailTask(taskID, errorMsg string) error {
 	s.mu.Lock()
@@ -89,13 +212,28 @@ func (s *SwarmState) FailTask(taskID, errorMsg string) error {
 	if !exists {
 		return fmt.Errorf("agent for task %s not found", taskID)
 	}
+	if agent.Status == workflow.TaskStatusCancelled {
+		return fmt.Errorf("task %s was cancelled", taskID)
+	}
+
+	s.failTask(agent, errorMsg, s.recordFailure(taskID))
+	return nil
+}
 
+// failTask marks an agent's task failed, or quarantined after too many
+// quick failures (must be called with lock held)
+func (s *SwarmState) failTask(agent *workflow.AgentState, errorMsg string, failures int) {
 	agent.Status = workflow.TaskStatusFailed
-	agent.Error = errorMsg
+	agent.Error = s.secrets.Redact(errorMsg)
+	agent.FinishedAt = time.Now()
+	s.releaseLocks(agent.TaskID)
 
-	s.addEvent(workflow.EventTaskFailed, taskID, "")
+	s.addEvent(workflow.EventTaskFailed, agent.TaskID, "")
 
-	return nil
+	if s.Workflow.Backoff.Quarantines(failures) {
+		agent.Status = workflow.TaskStatusQuarantined
+		s.addEvent(workflow.EventTaskQuarantined, agent.TaskID, "")
+	}
 }
 
 // AddQuestion adds a question from an agent
@@ -108,10 +246,10 @@ func (s *SwarmState) AddQuestion(taskID, questionText string) (int, error) {
 		return 0, fmt.Errorf("agent for task %s not found", taskID)
 	}
 
-	qID := len(agent.Questions) + 1
+	qID := len(agent.AllQuestions()) + 1
 	question := workflow.Question{
 		ID:      qID,
-		Text:    questionText,
+		Text:    s.secrets.Redact(questionText),
 		AskedAt: time.Now(),
 	}
 
@@ -122,8 +260,115 @@ func (s *SwarmState) AddQuestion(taskID, questionText string) (int, error) {
 	return qID, nil
 }
 
-// AnswerQuestion adds an answer to a question
-func (s *SwarmState) AnswerQuestion(taskID string, qID int, answer string) error {
+// ReplyToQuestion adds a follow-up to the thread of an earlier question,
+// given by the ID of the question or any reply in its thread. It returns
+// the reply's question ID.
+func (s *SwarmState) ReplyToQuestion(taskID string, thread int, questionText string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, exists := s.Agents[taskID]
+	if !exists {
+		return 0, fmt.Errorf("agent for task %s not found", taskID)
+	}
+
+	root := threadOf(agent, thread)
+	if root == nil {
+		return 0, workflow.Errorf(workflow.ErrorNotFound, "question %d not found for task %s", thread, taskID)
+	}
+
+	qID := len(agent.AllQuestions()) + 1
+	root.Replies = append(root.Replies, workflow.Question{
+		ID:      qID,
+		Text:    s.secrets.Redact(questionText),
+		AskedAt: time.Now(),
+	})
+
+	s.addEvent(workflow.EventQuestionAsked, taskID, "")
+
+	return qID, nil
+}
+
+// GetThread returns the question starting the thread that a question
+// belongs to, with its replies
+func (s *SwarmState) GetThread(taskID string, qID int) *workflow.Question {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	agent, exists := s.Agents[taskID]
+	if !exists {
+		return nil
+	}
+	root := threadOf(agent, qID)
+	if root == nil {
+		return nil
+	}
+	thread := *root
+	thread.Replies = append([]workflow.Question(nil), root.Replies...)
+	return &thread
+}
+
+// CannedAnswer returns the workflow's canned answer to a task's question,
+// if one matches
+func (s *SwarmState) CannedAnswer(taskID, question string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.Workflow == nil {
+		return "", false
+	}
+	answer, ok := s.Workflow.Answers.Find(taskID, question)
+	if !ok {
+		return "", false
+	}
+	return s.Workflow.InterpolateParams(answer), true
+}
+
+// threadOf returns the question starting the thread a question or reply
+// belongs to (must be called with lock held)
+func threadOf(agent *workflow.AgentState, qID int) *workflow.Question {
+	for i := range agent.Questions {
+		root := &agent.Questions[i]
+		if root.ID == qID {
+			return root
+		}
+		for _, reply := range root.Replies {
+			if reply.ID == qID {
+				return root
+			}
+		}
+	}
+	return nil
+}
+
+// AnswerQuestion adds an answer to a question or reply. The operator is
+// who answered, or "" when the orchestrator did.
+func (s *SwarmState) AnswerQuestion(taskID string, qID int, answer, operator string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, exists := s.Agents[taskID]
+	if !exists {
+		return fmt.Errorf("agent for task %s not found", taskID)
+	}
+
+	question := findQuestion(agent, qID)
+	if question == nil {
+		return fmt.Errorf("question %d not found for task %s", qID, taskID)
+	}
+
+	question.Answer = s.secrets.Redact(answer)
+	question.AnsweredAt = time.Now()
+	question.AnsweredBy = operator
+
+	s.addOperatorEvent(workflow.EventQuestionAnswered, taskID, "", operator)
+
+	return nil
+}
+
+// DraftAnswer queues the orchestrator's answer to a question for an
+// operator to review before the agent gets it
+func (s *SwarmState) DraftAnswer(taskID string, qID int, draft string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -132,18 +377,62 @@ func (s *SwarmState) AnswerQuestion(taskID string, qID int, answer string) error
 		return fmt.Errorf("agent for task %s not found", taskID)
 	}
 
-	if qID < 1 || qID > len(agent.Questions) {
+	question := findQuestion(agent, qID)
+	if question == nil {
 		return fmt.Errorf("question %d not found for task %s", qID, taskID)
 	}
 
-	agent.Questions[qID-1].Answer = answer
-	agent.Questions[qID-1].AnsweredAt = time.Now()
+	question.Draft = s.secrets.Redact(draft)
 
-	s.addEvent(workflow.EventQuestionAnswered, taskID, "")
+	s.addEvent(workflow.EventAnswerDrafted, taskID, "")
 
 	return nil
 }
 
+// PendingAnswer is a question whose drafted answer awaits review
+type PendingAnswer struct {
+	TaskID   string
+	Question workflow.Question
+}
+
+// PendingAnswers returns the questions with drafted answers awaiting
+// review, oldest first
+func (s *SwarmState) PendingAnswers() []PendingAnswer {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var pending []PendingAnswer
+	for taskID, agent := range s.Agents {
+		for _, question := range agent.AllQuestions() {
+			if question.Draft != "" && question.AnsweredAt.IsZero() {
+				pending = append(pending, PendingAnswer{TaskID: taskID, Question: question})
+			}
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].Question.AskedAt.Before(pending[j].Question.AskedAt)
+	})
+	return pending
+}
+
+// findQuestion returns a question or reply by ID (must be called with lock
+// held)
+func findQuestion(agent *workflow.AgentState, qID int) *workflow.Question {
+	root := threadOf(agent, qID)
+	if root == nil {
+		return nil
+	}
+	if root.ID == qID {
+		return root
+	}
+	for i := range root.Replies {
+		if root.Replies[i].ID == qID {
+			return &root.Replies[i]
+		}
+	}
+	return nil
+}
+
 // GetReadyTasks returns tasks that are ready to be spawned
 func (s *SwarmState) GetReadyTasks() []workflow.Task {
 	s.mu.RLock()
@@ -158,10 +447,20 @@ func (s *SwarmState) GetReadyTasks() []workflow.Task {
 			continue
 		}
 
-		// Check if all dependencies are completed
+		// Failure handlers wait for a task they handle to fail
+		if len(task.FailureOf) > 0 && !s.anyTaskFailed(task.FailureOf) {
+			continue
+		}
+
+		// Dependents of conflicting changes wait for their resolution
+		if s.heldByConflict(task) {
+			continue
+		}
+
+		// Check if all dependencies are done
 		allDepsCompleted := true
 		for _, depID := range task.DependsOn {
-			if !s.isTaskCompleted(depID) {
+			if !s.isTaskDone(depID) {
 				allDepsCompleted = false
 				break
 			}
@@ -172,6 +471,11 @@ func (s *SwarmState) GetReadyTasks() []workflow.Task {
 		}
 	}
 
+	// Higher priorities first; the stable sort keeps declaration order
+	sort.SliceStable(ready, func(i, j int) bool {
+		return ready[i].Priority > ready[j].Priority
+	})
+
 	return ready
 }
 
@@ -185,6 +489,39 @@ func (s *SwarmState) isTaskCompleted(taskID string) bool {
 	return false
 }
 
+// anyTaskFailed reports whether one of the tasks failed (must be called
+// with lock held)
+func (s *SwarmState) anyTaskFailed(taskIDs []string) bool {
+	for _, taskID := range taskIDs {
+		if agent, exists := s.Agents[taskID]; exists && agent.Status.IsFailure() {
+			return true
+		}
+	}
+	return false
+}
+
+// isRequired reports whether a task must complete for the workflow to
+// complete: failure handlers only must once triggered (must be called with
+// lock held)
+func (s *SwarmState) isRequired(task workflow.Task) bool {
+	return len(task.FailureOf) == 0 || s.anyTaskFailed(task.FailureOf)
+}
+
+// requiredCount returns the number of required tasks and how many of them
+// are done (must be called with lock held)
+func (s *SwarmState) requiredCount() (required, completed int) {
+	for _, task := range s.Workflow.Tasks {
+		if !s.isRequired(task) {
+			continue
+		}
+		required++
+		if s.isTaskDone(task.ID) {
+			completed++
+		}
+	}
+	return required, completed
+}
+
 // GetTask returns a task by ID
 func (s *SwarmState) GetTask(taskID string) *workflow.Task {
 	s.mu.RLock()
@@ -198,6 +535,21 @@ func (s *SwarmState) GetTask(taskID string) *workflow.Task {
 	return nil
 }
 
+// FileLock returns the lock held while checking a file and writing it,
+// shared by every path agents write files through
+func (s *SwarmState) FileLock() *sync.Mutex {
+	return &s.fileMu
+}
+
+// GetTasks returns a copy of the workflow's tasks, which running agents
+// may add to
+func (s *SwarmState) GetTasks() []workflow.Task {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]workflow.Task(nil), s.Workflow.Tasks...)
+}
+
 // GetAgent returns an agent state by task ID
 func (s *SwarmState) GetAgent(taskID string) *workflow.AgentState {
 	s.mu.RLock()
@@ -234,12 +586,76 @@ func (s *SwarmState) GetOutputs() map[string]string {
 	return outputs
 }
 
-// IsComplete checks if all tasks are completed
+// IsComplete checks if all tasks are completed, leaving out failure
+// handlers that were never triggered. Under the ignore failure policy,
+// failed tasks count as done.
 func (s *SwarmState) IsComplete() bool {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	return len(s.CompletedTasks) == len(s.Workflow.Tasks)
+	required, completed := s.requiredCount()
+	return completed == required
+}
+
+// IsStalled reports whether the workflow can make no further progress: it
+// is not complete, yet no agent is running, no task is ready and no
+// conflict awaits resolution, because tasks failed or depend on tasks that
+// failed
+func (s *SwarmState) IsStalled() bool {
+	if s.IsComplete() || len(s.GetActiveAgents()) > 0 || len(s.OpenConflicts()) > 0 {
+		return false
+	}
+	return len(s.GetReadyTasks()) == 0
+}
+
+// GetFailedTasks returns the IDs of failed tasks in workflow order
+func (s *SwarmState) GetFailedTasks() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var failed []string
+	for _, task := range s.Workflow.Tasks {
+		if agent, exists := s.Agents[task.ID]; exists && agent.Status.IsFailure() {
+			failed = append(failed, task.ID)
+		}
+	}
+	return failed
+}
+
+// RecordOperationError counts a failed operation of an agent by error code
+func (s *SwarmState) RecordOperationError(taskID string, code workflow.ErrorCode) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, exists := s.Agents[taskID]
+	if !exists {
+		return
+	}
+
+	if agent.OperationErrors == nil {
+		agent.OperationErrors = make(map[workflow.ErrorCode]int)
+	}
+	agent.OperationErrors[code]++
+
+	s.addEvent(workflow.EventOperationFailed, taskID, "")
+}
+
+// SetIssue records the tracker issue mirroring a task
+func (s *SwarmState) SetIssue(taskID, key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if agent, exists := s.Agents[taskID]; exists {
+		agent.Issue = key
+	}
+}
+
+// IsReadOnly reports whether agent writes are turned into proposals
+func (s *SwarmState) IsReadOnly() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.Workflow.ReadOnly
 }
 
 // MarkComplete marks the entire workflow as complete
@@ -256,23 +672,69 @@ func (s *SwarmState) GetProgress() float64 {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	if len(s.Workflow.Tasks) == 0 {
+	required, completed := s.requiredCount()
+	if required == 0 {
 		return 100.0
 	}
 
-	return float64(len(s.CompletedTasks)) / float64(len(s.Workflow.Tasks)) * 100.0
+	return float64(completed) / float64(required) * 100.0
 }
 
 // addEvent adds an event to the event log (must be called with lock held)
 func (s *SwarmState) addEvent(eventType workflow.EventType, agentID string, filePath string) {
+	s.addOperatorEvent(eventType, agentID, filePath, "")
+}
+
+// addOperatorEvent adds an event an operator caused (must be called with
+// lock held)
+func (s *SwarmState) addOperatorEvent(eventType workflow.EventType, agentID, filePath, operator string) {
 	event := workflow.FileEvent{
+		Seq:      s.nextSeq(),
 		Type:     eventType,
 		AgentID:  agentID,
 		FilePath: filePath,
 		Time:     time.Now(),
+		Operator: operator,
 	}
 
 	s.Events = append(s.Events, event)
+
+	// Fan out to subscribers without blocking state updates
+	for _, sub := range s.subscribers {
+		select {
+		case sub <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel receiving every event added from now on and a
+// function to cancel the subscription. Events are dropped for subscribers
+// whose buffer is full.
+func (s *SwarmState) Subscribe(buffer int) (<-chan workflow.FileEvent, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch := make(chan workflow.FileEvent, buffer)
+	s.subscribers = append(s.subscribers, ch)
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			for i, sub := range s.subscribers {
+				if sub == ch {
+					s.subscribers = append(s.subscribers[:i], s.subscribers[i+1:]...)
+					break
+				}
+			}
+			close(ch)
+		})
+	}
+
+	return ch, cancel
 }
 
 // GetRecentEvents returns the N most recent events
@@ -0,0 +1,90 @@
+package state
+
+import (
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// Status summarizes a session for dashboards
+type Status struct {
+	Session   string              `json:"session"`
+	Workflow  string              `json:"workflow"`
+	State     string              `json:"state"` // running, paused, completed, cancelled or halted
+	Progress  float64             `json:"progress"`
+	StartedAt time.Time           `json:"started_at"`
+	Tasks     []TaskSummary       `json:"tasks"`
+	Usage     workflow.TokenUsage `json:"usage"`
+}
+
+// TaskSummary is a task's status in a Status
+type TaskSummary struct {
+	ID         string              `json:"id"`
+	Status     workflow.TaskStatus `json:"status"`
+	StartedAt  *time.Time          `json:"started_at,omitempty"`
+	FinishedAt *time.Time          `json:"finished_at,omitempty"`
+	Error      string              `json:"error,omitempty"`
+	// Questions counts the questions the agent asked that are unanswered
+	Questions int `json:"open_questions,omitempty"`
+	// Progress is what a running agent last reported with swarm-agent
+	// progress, such as "30% migrated 3/10 modules"
+	Progress string `json:"progress,omitempty"`
+}
+
+// Status returns a summary of the session, its tasks in workflow order
+func (s *SwarmState) Status() Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	status := Status{
+		Session:   s.SessionID,
+		Workflow:  s.Workflow.Name,
+		State:     "running",
+		Progress:  100,
+		StartedAt: s.StartedAt,
+		Tasks:     []TaskSummary{},
+		Usage:     s.Tokens,
+	}
+
+	required, completed := s.requiredCount()
+	if required > 0 {
+		status.Progress = float64(completed) / float64(required) * 100
+	}
+	switch {
+	case s.CancelledAt != nil:
+		status.State = "cancelled"
+	case s.HaltedAt != nil:
+		status.State = "halted"
+	case s.CompletedAt != nil || completed == required:
+		status.State = "completed"
+	case s.Paused:
+		status.State = "paused"
+	}
+
+	for _, task := range s.Workflow.Tasks {
+		summary := TaskSummary{ID: task.ID, Status: workflow.TaskStatusPending}
+		if agent, exists := s.Agents[task.ID]; exists {
+			summary.Status = agent.Status
+			summary.Error = agent.Error
+			if !agent.StartedAt.IsZero() {
+				started := agent.StartedAt
+				summary.StartedAt = &started
+			}
+			if !agent.FinishedAt.IsZero() {
+				finished := agent.FinishedAt
+				summary.FinishedAt = &finished
+			}
+			for _, question := range agent.AllQuestions() {
+				if question.AnsweredAt.IsZero() {
+					summary.Questions++
+				}
+			}
+			if agent.Status == workflow.TaskStatusRunning {
+				summary.Progress = workflow.FormatProgress(agent.Progress, agent.ProgressPercent)
+			}
+		}
+		status.Tasks = append(status.Tasks, summary)
+	}
+
+	return status
+}
@@ -0,0 +1,78 @@
+package state
+
+import (
+	"sort"
+
+	"github.com/aristath/claude-swarm/internal/diff"
+)
+
+// taskChange is a task's net change to a file: its content before the
+// task's first change and after its last
+type taskChange struct {
+	before string
+	after  string
+	large  bool // Too large to diff
+}
+
+// taskChanges are the files each task changed through the orchestrator
+type taskChanges map[string]map[string]*taskChange
+
+// recordTaskChange adds a change to its task's net changes (must be called
+// with lock held)
+func (s *SwarmState) recordTaskChange(taskID, path, before, after string) {
+	if taskID == "" {
+		return
+	}
+	if s.taskChanges == nil {
+		s.taskChanges = make(taskChanges)
+	}
+	files := s.taskChanges[taskID]
+	if files == nil {
+		files = make(map[string]*taskChange)
+		s.taskChanges[taskID] = files
+	}
+
+	change, changed := files[path]
+	if !changed {
+		change = &taskChange{before: before}
+		files[path] = change
+	}
+	change.after = after
+	if change.large || len(before) > maxTrackedFile || len(after) > maxTrackedFile {
+		change.large = true
+		change.before, change.after = "", ""
+	}
+}
+
+// TaskDiff returns the unified diff of the changes a task made to files
+// through the orchestrator since this process started, each file from
+// before the task's first change to after its last, and the files it
+// changed. Files too large to diff are listed without a diff; files it
+// changed back are left out.
+func (s *SwarmState) TaskDiff(taskID string) (string, []string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	files := s.taskChanges[taskID]
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var unified string
+	var changed []string
+	for _, path := range paths {
+		change := files[path]
+		if change.large {
+			unified += "# " + path + ": too large to diff\n"
+			changed = append(changed, path)
+			continue
+		}
+		if fileDiff := diff.UnifiedFile(path, change.before, change.after); fileDiff != "" {
+			unified += fileDiff
+			changed = append(changed, path)
+		}
+	}
+	return unified, changed
+}
@@ -0,0 +1,78 @@
+package state
+
+import (
+	"fmt"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// AddTasks merges tasks added by a running agent into the workflow. The
+// tasks may depend on any task of the workflow, and are validated with it
+// as a whole. Tasks waiting for the adding agent's task also wait for the
+// added tasks, so a planner's dependents see the work it broke out.
+// Agents may not add tasks that run commands or files of their choosing:
+// verify commands, child workflows and includes.
+func (s *SwarmState) AddTasks(taskID string, tasks []workflow.Task) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, exists := s.Agents[taskID]
+	if !exists || agent.Status != workflow.TaskStatusRunning {
+		return nil, workflow.Errorf(workflow.ErrorInvalidRequest, "task %s is not running", taskID)
+	}
+	if len(tasks) == 0 {
+		return nil, workflow.Errorf(workflow.ErrorInvalidRequest, "no tasks to add")
+	}
+
+	added := make([]string, len(tasks))
+	for i, task := range tasks {
+		if err := checkAgentTask(task); err != nil {
+			return nil, err
+		}
+		added[i] = task.ID
+	}
+
+	merged := *s.Workflow
+	merged.Tasks = make([]workflow.Task, 0, len(s.Workflow.Tasks)+len(tasks))
+	for _, task := range s.Workflow.Tasks {
+		if _, spawned := s.Agents[task.ID]; !spawned && dependsOn(task, taskID) {
+			task.DependsOn = append(append([]string(nil), task.DependsOn...), added...)
+		}
+		merged.Tasks = append(merged.Tasks, task)
+	}
+	merged.Tasks = append(merged.Tasks, tasks...)
+	merged.ExpandSnippets()
+
+	if err := workflow.NewParser().Validate(&merged); err != nil {
+		return nil, workflow.WithCode(workflow.ErrorInvalidRequest, fmt.Errorf("invalid tasks: %w", err))
+	}
+
+	s.Workflow.Tasks = merged.Tasks
+	s.addEvent(workflow.EventTasksAdded, taskID, "")
+	return added, nil
+}
+
+// checkAgentTask rejects the task fields agents may not set
+func checkAgentTask(task workflow.Task) error {
+	var field string
+	switch {
+	case task.Verify != nil:
+		field = "verify"
+	case task.IsSubWorkflow() || task.Workflow != "":
+		field = "type: workflow"
+	case task.Include != "":
+		field = "include"
+	default:
+		return nil
+	}
+	return workflow.WithField("tasks", workflow.Errorf(workflow.ErrorPermissionDenied, "task %s: agents cannot add tasks with %s", task.ID, field))
+}
+
+func dependsOn(task workflow.Task, taskID string) bool {
+	for _, dep := range task.DependsOn {
+		if dep == taskID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package state
+
+import (
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// RecordUsage adds the tokens an agent reported to its usage and the
+// run's, pricing them by the workflow's budget when they come without a
+// cost. The first report that takes the run over budget emits
+// EventBudgetExceeded. It returns the agent's usage so far.
+func (s *SwarmState) RecordUsage(taskID string, usage workflow.TokenUsage) (workflow.TokenUsage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, exists := s.Agents[taskID]
+	if !exists {
+		return workflow.TokenUsage{}, workflow.Errorf(workflow.ErrorNotFound, "agent for task %s not found", taskID)
+	}
+	if usage.InputTokens < 0 || usage.OutputTokens < 0 || usage.CostUSD < 0 {
+		return workflow.TokenUsage{}, workflow.Errorf(workflow.ErrorInvalidRequest, "token usage must not be negative")
+	}
+
+	usage = s.Workflow.Budget.Price(usage)
+	agent.Tokens = agent.Tokens.Add(usage)
+	s.Tokens = s.Tokens.Add(usage)
+
+	if s.BudgetExceededAt == nil && s.Workflow.Budget.Exceeded(s.Tokens) != "" {
+		now := time.Now()
+		s.BudgetExceededAt = &now
+		s.addEvent(workflow.EventBudgetExceeded, taskID, "")
+	}
+
+	return agent.Tokens, nil
+}
+
+// TokenUsage returns the tokens and cost reported for the whole run
+func (s *SwarmState) TokenUsage() workflow.TokenUsage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Tokens
+}
+
+// BudgetExceeded returns why the run went over its budget, or "" if it has
+// not
+func (s *SwarmState) BudgetExceeded() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.BudgetExceededAt == nil {
+		return ""
+	}
+	return s.Workflow.Budget.Exceeded(s.Tokens)
+}
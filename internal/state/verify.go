@@ -0,0 +1,54 @@
+package state
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// FailVerification records that a running task's output failed its
+// verification. While the task has attempts left, it stays running for
+// its agent to fix the failure and complete again or, with respawn, its
+// agent is forgotten so the task runs again with the failure in its
+// context; otherwise the task fails. It reports whether the task gets
+// another attempt and how many times it failed verification.
+func (s *SwarmState) FailVerification(taskID, report string, respawn bool) (bool, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, exists := s.Agents[taskID]
+	if !exists || agent.Status != workflow.TaskStatusRunning {
+		return false, 0
+	}
+
+	s.Verifications[taskID] = append(s.Verifications[taskID], s.secrets.Redact(report))
+	failed := len(s.Verifications[taskID])
+	s.addEvent(workflow.EventVerificationFailed, taskID, "")
+
+	limit := workflow.DefaultVerifyAttempts
+	for _, task := range s.Workflow.Tasks {
+		if task.ID == taskID && task.Verify != nil {
+			limit = task.Verify.Attempts()
+		}
+	}
+	if failed >= limit {
+		reason, _, _ := strings.Cut(report, "\n")
+		s.failTask(agent, fmt.Sprintf("%s (failed verification %d times)", reason, failed), s.recordFailure(taskID))
+		return false, failed
+	}
+
+	if respawn {
+		delete(s.Agents, taskID)
+		s.releaseLocks(taskID)
+	}
+	return true, failed
+}
+
+// GetVerifications returns the reports of a task's failed verifications
+func (s *SwarmState) GetVerifications(taskID string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]string(nil), s.Verifications[taskID]...)
+}
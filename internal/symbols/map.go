@@ -0,0 +1,218 @@
+package symbols
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// DefaultMapSize is the default size limit of a repository map in bytes
+const DefaultMapSize = 8000
+
+// maxSymbolsPerFile keeps one large file from crowding out the others
+const maxSymbolsPerFile = 12
+
+// keyFiles are the files that tell an agent what a repository is and how
+// it is built, wherever they appear in the top two levels
+var keyFiles = map[string]bool{
+	"readme.md": true, "readme": true, "readme.rst": true, "claude.md": true,
+	"contributing.md": true, "go.mod": true, "package.json": true,
+	"cargo.toml": true, "pyproject.toml": true, "setup.py": true,
+	"requirements.txt": true, "gemfile": true, "pom.xml": true,
+	"build.gradle": true, "composer.json": true, "makefile": true,
+	"dockerfile": true, "docker-compose.yml": true, "main.go": true,
+	"main.py": true, "index.js": true, "index.ts": true, "main.rs": true,
+	"lib.rs": true,
+}
+
+// projectMarkers identify a directory as the root of a project
+var projectMarkers = []string{".git", "go.mod", "package.json", "Cargo.toml", "pyproject.toml", "setup.py", "pom.xml", "Makefile"}
+
+// IsProject reports whether dir looks like the root of a project, so that
+// a map is not built for a home directory by accident
+func IsProject(dir string) bool {
+	for _, marker := range projectMarkers {
+		if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Map returns a compact overview of the repository for agent contexts: its
+// top-level layout, key files and the exported symbols of each source
+// file, cut off at maxBytes
+func (ix *Index) Map(ctx context.Context, maxBytes int) (string, error) {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMapSize
+	}
+	if err := ix.Refresh(ctx); err != nil {
+		return "", err
+	}
+
+	ix.mu.Lock()
+	files := make(map[string][]Symbol, len(ix.files))
+	for path, entry := range ix.files {
+		rel, err := filepath.Rel(ix.root, path)
+		if err != nil {
+			continue
+		}
+		files[filepath.ToSlash(rel)] = entry.symbols
+	}
+	ix.mu.Unlock()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Root: %s\n\n", ix.root)
+
+	b.WriteString("Layout:\n")
+	for _, line := range ix.layout(files) {
+		fmt.Fprintf(&b, "  %s\n", line)
+	}
+
+	if key := ix.keyFiles(); len(key) > 0 {
+		fmt.Fprintf(&b, "\nKey files: %s\n", strings.Join(key, ", "))
+	}
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		if !isTestFile(path) {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	b.WriteString("\nExported symbols:\n")
+	for i, path := range paths {
+		names := exportedNames(files[path])
+		if len(names) == 0 {
+			continue
+		}
+		if len(names) > maxSymbolsPerFile {
+			names = append(names[:maxSymbolsPerFile], fmt.Sprintf("+%d more", len(names)-maxSymbolsPerFile))
+		}
+
+		line := fmt.Sprintf("  %s: %s\n", path, strings.Join(names, ", "))
+		if b.Len()+len(line) > maxBytes {
+			fmt.Fprintf(&b, "  ... %d more files (use swarm-agent symbols to look them up)\n", len(paths)-i)
+			break
+		}
+		b.WriteString(line)
+	}
+
+	return b.String(), nil
+}
+
+// layout lists the top-level entries, with the number of source files in
+// each directory
+func (ix *Index) layout(files map[string][]Symbol) []string {
+	counts := make(map[string]int)
+	for path := range files {
+		if top, _, nested := strings.Cut(path, "/"); nested {
+			counts[top]++
+		}
+	}
+
+	entries, err := os.ReadDir(ix.root)
+	if err != nil {
+		return nil
+	}
+
+	var lines []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") || skipDirs[name] {
+			continue
+		}
+		if entry.IsDir() {
+			if counts[name] > 0 {
+				lines = append(lines, fmt.Sprintf("%s/ (%d source files)", name, counts[name]))
+			} else {
+				lines = append(lines, name+"/")
+			}
+		} else {
+			lines = append(lines, name)
+		}
+	}
+	return lines
+}
+
+// keyFiles finds the key files in the top two levels
+func (ix *Index) keyFiles() []string {
+	var found []string
+
+	entries, err := os.ReadDir(ix.root)
+	if err != nil {
+		return nil
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() {
+			if keyFiles[strings.ToLower(name)] {
+				found = append(found, name)
+			}
+			continue
+		}
+		if strings.HasPrefix(name, ".") || skipDirs[name] {
+			continue
+		}
+
+		children, err := os.ReadDir(filepath.Join(ix.root, name))
+		if err != nil {
+			continue
+		}
+		for _, child := range children {
+			if !child.IsDir() && keyFiles[strings.ToLower(child.Name())] {
+				found = append(found, name+"/"+child.Name())
+			}
+		}
+	}
+
+	sort.Strings(found)
+	return found
+}
+
+// exportedNames returns the names of a file's symbols that other code can
+// use: capitalized names in Go, names without a leading underscore
+// elsewhere. Fields and local variables are left out.
+func exportedNames(symbols []Symbol) []string {
+	var names []string
+	for _, symbol := range symbols {
+		if symbol.Kind == "field" || symbol.Kind == "member" || symbol.Kind == "local" {
+			continue
+		}
+		if strings.HasSuffix(symbol.Path, ".go") {
+			if !isExportedGo(symbol.Name) || (symbol.Scope != "" && !isExportedGo(symbol.Scope)) {
+				continue
+			}
+		} else if strings.HasPrefix(symbol.Name, "_") {
+			continue
+		}
+
+		name := symbol.Name
+		if symbol.Scope != "" {
+			name = symbol.Scope + "." + name
+		}
+		names = append(names, fmt.Sprintf("%s %s", symbol.Kind, name))
+	}
+	return names
+}
+
+func isExportedGo(name string) bool {
+	for _, r := range name {
+		return unicode.IsUpper(r)
+	}
+	return false
+}
+
+// isTestFile reports whether a path is a test, which maps leave out
+func isTestFile(path string) bool {
+	base := filepath.Base(path)
+	return strings.HasSuffix(base, "_test.go") ||
+		strings.HasPrefix(base, "test_") ||
+		strings.Contains(base, ".test.") ||
+		strings.Contains(base, ".spec.")
+}
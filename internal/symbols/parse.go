@@ -0,0 +1,279 @@
+package symbols
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// languages maps file extensions to the language of their parser
+var languages = map[string]string{
+	".go":   "go",
+	".py":   "python",
+	".js":   "javascript",
+	".jsx":  "javascript",
+	".mjs":  "javascript",
+	".ts":   "javascript",
+	".tsx":  "javascript",
+	".rb":   "ruby",
+	".java": "java",
+	".kt":   "java",
+	".cs":   "java",
+	".rs":   "rust",
+	".c":    "c",
+	".h":    "c",
+	".cc":   "c",
+	".cpp":  "c",
+	".hpp":  "c",
+	".php":  "php",
+}
+
+// languageOf returns the language of a source file, or "" if it is not
+// indexed
+func languageOf(path string) string {
+	return languages[strings.ToLower(filepath.Ext(path))]
+}
+
+// pattern recognizes a definition on a single line. The "name" group
+// captures the symbol name.
+type pattern struct {
+	kind string
+	re   *regexp.Regexp
+}
+
+// patterns are the line-based parsers for languages without a built-in
+// parser. They miss unusual layouts, which ctags handles when installed.
+var patterns = map[string][]pattern{
+	"python": {
+		{"class", regexp.MustCompile(`^\s*class\s+(?P<name>\w+)`)},
+		{"func", regexp.MustCompile(`^\s*(?:async\s+)?def\s+(?P<name>\w+)`)},
+		{"var", regexp.MustCompile(`^(?P<name>[A-Z][A-Z0-9_]*)\s*=`)},
+	},
+	"javascript": {
+		{"class", regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:abstract\s+)?class\s+(?P<name>\w+)`)},
+		{"func", regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:async\s+)?function\*?\s+(?P<name>\w+)`)},
+		{"type", regexp.MustCompile(`^\s*(?:export\s+)?(?:declare\s+)?(?:interface|type|enum)\s+(?P<name>\w+)`)},
+		{"var", regexp.MustCompile(`^\s*(?:export\s+)?(?:const|let|var)\s+(?P<name>\w+)\s*=`)},
+	},
+	"ruby": {
+		{"class", regexp.MustCompile(`^\s*(?:class|module)\s+(?:\w+::)*(?P<name>\w+)`)},
+		{"func", regexp.MustCompile(`^\s*def\s+(?:self\.)?(?P<name>\w+[?!=]?)`)},
+	},
+	"java": {
+		{"class", regexp.MustCompile(`^\s*(?:(?:public|private|protected|internal|static|final|abstract|sealed|data|open|partial)\s+)*(?:class|interface|enum|record|object|struct)\s+(?P<name>\w+)`)},
+		{"func", regexp.MustCompile(`^\s*(?:(?:public|private|protected|internal|static|final|abstract|override|async|virtual|synchronized|suspend)\s+)*fun\s+(?:<[^>]*>\s*)?(?:\w+\.)?(?P<name>\w+)\s*\(`)},
+		{"method", regexp.MustCompile(`^\s*(?:(?:public|private|protected|internal|static|final|abstract|override|async|virtual|synchronized)\s+)+[\w<>\[\],.?\s]+?\s+(?P<name>\w+)\s*\([^;]*$`)},
+	},
+	"rust": {
+		{"func", regexp.MustCompile(`^\s*(?:pub(?:\([\w:]+\))?\s+)?(?:const\s+)?(?:async\s+)?(?:unsafe\s+)?(?:extern\s+"\w+"\s+)?fn\s+(?P<name>\w+)`)},
+		{"type", regexp.MustCompile(`^\s*(?:pub(?:\([\w:]+\))?\s+)?(?:struct|enum|trait|type|union)\s+(?P<name>\w+)`)},
+		{"module", regexp.MustCompile(`^\s*(?:pub(?:\([\w:]+\))?\s+)?mod\s+(?P<name>\w+)`)},
+		{"const", regexp.MustCompile(`^\s*(?:pub(?:\([\w:]+\))?\s+)?(?:const|static)\s+(?:mut\s+)?(?P<name>\w+)\s*:`)},
+		{"macro", regexp.MustCompile(`^\s*macro_rules!\s+(?P<name>\w+)`)},
+	},
+	"c": {
+		{"type", regexp.MustCompile(`^\s*(?:typedef\s+)?(?:struct|enum|union|class)\s+(?P<name>\w+)\s*(?::[^{;]*)?\{?\s*$`)},
+		{"macro", regexp.MustCompile(`^\s*#\s*define\s+(?P<name>\w+)`)},
+		{"func", regexp.MustCompile(`^(?:[\w:*&<>]+\s+)+[*&]*(?P<name>\w+)\s*\([^;]*\)\s*(?:const\s*)?\{?\s*$`)},
+	},
+	"php": {
+		{"class", regexp.MustCompile(`^\s*(?:(?:abstract|final|readonly)\s+)*(?:class|interface|trait|enum)\s+(?P<name>\w+)`)},
+		{"func", regexp.MustCompile(`^\s*(?:(?:public|private|protected|static|abstract|final)\s+)*function\s+&?(?P<name>\w+)`)},
+	},
+}
+
+// keywords are never function names, though C-like patterns may think so
+var keywords = map[string]bool{
+	"if": true, "for": true, "while": true, "switch": true, "return": true,
+	"catch": true, "else": true, "do": true, "sizeof": true, "new": true,
+}
+
+// parseFile extracts the definitions of one source file
+func parseFile(path string) ([]Symbol, error) {
+	language := languageOf(path)
+	if language == "go" {
+		return parseGo(path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var symbols []Symbol
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), maxFileSize)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		for _, p := range patterns[language] {
+			match := p.re.FindStringSubmatch(text)
+			if match == nil {
+				continue
+			}
+			name := match[p.re.SubexpIndex("name")]
+			if keywords[name] {
+				continue
+			}
+			symbols = append(symbols, Symbol{
+				Name:      name,
+				Kind:      p.kind,
+				Path:      path,
+				Line:      line,
+				Signature: strings.TrimSpace(text),
+			})
+			break
+		}
+	}
+
+	return symbols, nil
+}
+
+// parseGo extracts the declarations of a Go file with the Go parser
+func parseGo(path string) ([]Symbol, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+	if err != nil && file == nil {
+		return nil, err
+	}
+
+	lines := sourceLines(path)
+	symbol := func(name, kind, scope string, pos token.Pos) Symbol {
+		line := fset.Position(pos).Line
+		s := Symbol{Name: name, Kind: kind, Scope: scope, Path: path, Line: line}
+		if line > 0 && line <= len(lines) {
+			s.Signature = strings.TrimSpace(lines[line-1])
+		}
+		return s
+	}
+
+	var symbols []Symbol
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				symbols = append(symbols, symbol(d.Name.Name, "method", receiverType(d.Recv.List[0].Type), d.Name.Pos()))
+			} else {
+				symbols = append(symbols, symbol(d.Name.Name, "func", "", d.Name.Pos()))
+			}
+
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch sp := spec.(type) {
+				case *ast.TypeSpec:
+					symbols = append(symbols, symbol(sp.Name.Name, "type", "", sp.Name.Pos()))
+					symbols = append(symbols, goMembers(sp, symbol)...)
+				case *ast.ValueSpec:
+					kind := "var"
+					if d.Tok == token.CONST {
+						kind = "const"
+					}
+					for _, name := range sp.Names {
+						if name.Name != "_" {
+							symbols = append(symbols, symbol(name.Name, kind, "", name.Pos()))
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return symbols, nil
+}
+
+// goMembers returns the fields of a struct and the methods of an interface
+func goMembers(spec *ast.TypeSpec, symbol func(name, kind, scope string, pos token.Pos) Symbol) []Symbol {
+	var members []Symbol
+	switch t := spec.Type.(type) {
+	case *ast.StructType:
+		for _, field := range t.Fields.List {
+			for _, name := range field.Names {
+				members = append(members, symbol(name.Name, "field", spec.Name.Name, name.Pos()))
+			}
+		}
+	case *ast.InterfaceType:
+		for _, method := range t.Methods.List {
+			for _, name := range method.Names {
+				members = append(members, symbol(name.Name, "method", spec.Name.Name, name.Pos()))
+			}
+		}
+	}
+	return members
+}
+
+// receiverType returns the type name of a method receiver
+func receiverType(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverType(t.X)
+	case *ast.IndexExpr:
+		return receiverType(t.X)
+	case *ast.IndexListExpr:
+		return receiverType(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+// sourceLines returns the lines of a file, or nil if it cannot be read
+func sourceLines(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	return strings.Split(string(data), "\n")
+}
+
+// ctagsTag is one line of universal-ctags JSON output
+type ctagsTag struct {
+	Type    string `json:"_type"`
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Line    int    `json:"line"`
+	Kind    string `json:"kind"`
+	Scope   string `json:"scope"`
+	Pattern string `json:"pattern"`
+}
+
+// runCtags indexes files with universal-ctags, grouping symbols by path
+func runCtags(ctx context.Context, ctags string, paths []string) (map[string][]Symbol, error) {
+	args := []string{"--output-format=json", "--fields=+nKs", "-f", "-", "-L", "-"}
+	cmd := exec.CommandContext(ctx, ctags, args...)
+	cmd.Stdin = strings.NewReader(strings.Join(paths, "\n"))
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("ctags failed: %w", err)
+	}
+
+	found := make(map[string][]Symbol)
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 64*1024), maxFileSize)
+	for scanner.Scan() {
+		var tag ctagsTag
+		if err := json.Unmarshal(scanner.Bytes(), &tag); err != nil || tag.Type != "tag" {
+			continue
+		}
+		signature := strings.TrimSuffix(strings.TrimPrefix(tag.Pattern, "/^"), "$/")
+		found[tag.Path] = append(found[tag.Path], Symbol{
+			Name:      tag.Name,
+			Kind:      tag.Kind,
+			Scope:     tag.Scope,
+			Path:      tag.Path,
+			Line:      tag.Line,
+			Signature: strings.TrimSpace(signature),
+		})
+	}
+
+	return found, nil
+}
@@ -0,0 +1,366 @@
+// Package symbols maintains per-repository symbol indexes so agents can
+// find definitions and references more precisely than with raw grep.
+// Files are indexed with universal-ctags when it is installed, and with
+// built-in parsers otherwise. Indexes are refreshed incrementally: only
+// files whose size or modification time changed are parsed again.
+package symbols
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// Symbol is a definition found in a source file
+type Symbol struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`            // func, method, type, class, const, var, ...
+	Scope     string `json:"scope,omitempty"` // Enclosing type for methods and fields
+	Path      string `json:"path"`
+	Line      int    `json:"line"`
+	Signature string `json:"signature,omitempty"` // The defining line, trimmed
+}
+
+// String formats a symbol as "path:line: kind scope.name"
+func (s Symbol) String() string {
+	name := s.Name
+	if s.Scope != "" {
+		name = s.Scope + "." + name
+	}
+	return fmt.Sprintf("%s:%d: %s %s", s.Path, s.Line, s.Kind, name)
+}
+
+// Reference is a line that mentions a symbol without defining it
+type Reference struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// String formats a reference as "path:line: text"
+func (r Reference) String() string {
+	return fmt.Sprintf("%s:%d: %s", r.Path, r.Line, r.Text)
+}
+
+// maxFileSize skips generated and vendored blobs that would bloat the index
+const maxFileSize = 1 << 20
+
+// skipDirs are never indexed
+var skipDirs = map[string]bool{
+	".git":         true,
+	".hg":          true,
+	".svn":         true,
+	".swarm":       true,
+	"node_modules": true,
+	"vendor":       true,
+	"dist":         true,
+	"build":        true,
+	"target":       true,
+	"__pycache__":  true,
+}
+
+type fileEntry struct {
+	modTime time.Time
+	size    int64
+	symbols []Symbol
+}
+
+// Index is the symbol index of one directory tree
+type Index struct {
+	root  string
+	ctags string // Path of universal-ctags, empty to use the built-in parsers
+
+	mu    sync.Mutex
+	files map[string]*fileEntry
+}
+
+var (
+	indexesMu sync.Mutex
+	indexes   = make(map[string]*Index)
+)
+
+// Open returns the shared index of a directory tree, creating it on first
+// use. Every caller in the process shares it, so it is built only once.
+func Open(root string) (*Index, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", root, err)
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", root, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", root)
+	}
+
+	indexesMu.Lock()
+	defer indexesMu.Unlock()
+
+	if ix, ok := indexes[abs]; ok {
+		return ix, nil
+	}
+
+	ix := &Index{
+		root:  abs,
+		ctags: findCtags(),
+		files: make(map[string]*fileEntry),
+	}
+	indexes[abs] = ix
+	return ix, nil
+}
+
+// Root returns the directory the index covers
+func (ix *Index) Root() string {
+	return ix.root
+}
+
+// Refresh brings the index up to date with the files on disk
+func (ix *Index) Refresh(ctx context.Context) error {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	seen := make(map[string]bool)
+	var changed []string
+
+	err := filepath.WalkDir(ix.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable directories are skipped, not fatal
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if d.IsDir() {
+			if path != ix.root && (skipDirs[d.Name()] || strings.HasPrefix(d.Name(), ".")) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || languageOf(path) == "" {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil || info.Size() > maxFileSize {
+			return nil
+		}
+
+		seen[path] = true
+		entry, ok := ix.files[path]
+		if !ok || !entry.modTime.Equal(info.ModTime()) || entry.size != info.Size() {
+			ix.files[path] = &fileEntry{modTime: info.ModTime(), size: info.Size()}
+			changed = append(changed, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to scan %s: %w", ix.root, err)
+	}
+
+	for path := range ix.files {
+		if !seen[path] {
+			delete(ix.files, path)
+		}
+	}
+
+	return ix.parse(ctx, changed)
+}
+
+// parse indexes changed files, with ctags if available
+func (ix *Index) parse(ctx context.Context, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	if ix.ctags != "" {
+		found, err := runCtags(ctx, ix.ctags, paths)
+		if err == nil {
+			for _, path := range paths {
+				ix.files[path].symbols = found[path]
+			}
+			return nil
+		}
+		// Fall back to the built-in parsers if ctags misbehaves
+		fmt.Printf("ctags failed, using built-in parsers: %v\n", err)
+		ix.ctags = ""
+	}
+
+	for _, path := range paths {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		symbols, err := parseFile(path)
+		if err != nil {
+			continue
+		}
+		ix.files[path].symbols = symbols
+	}
+
+	return nil
+}
+
+// Definitions returns the definitions of a symbol, sorted by path and
+// line. The name may be qualified with its scope, as in "Server.Start". A
+// non-empty kind restricts the results to that kind.
+func (ix *Index) Definitions(ctx context.Context, name, kind string) ([]Symbol, error) {
+	if err := ix.Refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	scope, base := "", name
+	if i := strings.LastIndex(name, "."); i > 0 {
+		scope, base = name[:i], name[i+1:]
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	var results []Symbol
+	for _, entry := range ix.files {
+		for _, symbol := range entry.symbols {
+			if symbol.Name != base || (scope != "" && symbol.Scope != scope) {
+				continue
+			}
+			if kind != "" && symbol.Kind != kind {
+				continue
+			}
+			results = append(results, symbol)
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Path != results[j].Path {
+			return results[i].Path < results[j].Path
+		}
+		return results[i].Line < results[j].Line
+	})
+
+	return results, nil
+}
+
+// References returns the lines of indexed files that mention a symbol as a
+// whole word, excluding its definitions, sorted by path and line
+func (ix *Index) References(ctx context.Context, name string) ([]Reference, error) {
+	if i := strings.LastIndex(name, "."); i > 0 {
+		name = name[i+1:]
+	}
+
+	definitions, err := ix.Definitions(ctx, name, "")
+	if err != nil {
+		return nil, err
+	}
+	defined := make(map[string]bool, len(definitions))
+	for _, symbol := range definitions {
+		defined[fmt.Sprintf("%s:%d", symbol.Path, symbol.Line)] = true
+	}
+
+	ix.mu.Lock()
+	paths := make([]string, 0, len(ix.files))
+	for path := range ix.files {
+		paths = append(paths, path)
+	}
+	ix.mu.Unlock()
+	sort.Strings(paths)
+
+	word := regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\b`)
+
+	var results []Reference
+	for _, path := range paths {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 64*1024), maxFileSize)
+		for line := 1; scanner.Scan(); line++ {
+			text := scanner.Text()
+			if !strings.Contains(text, name) || !word.MatchString(text) {
+				continue
+			}
+			if defined[fmt.Sprintf("%s:%d", path, line)] {
+				continue
+			}
+			results = append(results, Reference{Path: path, Line: line, Text: strings.TrimSpace(text)})
+		}
+		file.Close()
+	}
+
+	return results, nil
+}
+
+// findCtags returns the path of universal-ctags, which supports the JSON
+// output the index reads, or "" if it is not installed
+func findCtags() string {
+	for _, name := range []string{"universal-ctags", "ctags"} {
+		path, err := exec.LookPath(name)
+		if err != nil {
+			continue
+		}
+		output, err := exec.Command(path, "--version").Output()
+		if err == nil && strings.Contains(string(output), "Universal Ctags") {
+			return path
+		}
+	}
+	return ""
+}
+
+// Search looks up a symbol under root and formats the results one per
+// line: its definitions, or with refs set the lines referencing it
+func Search(ctx context.Context, root, name, kind string, refs bool) ([]string, error) {
+	if name == "" {
+		return nil, workflow.Errorf(workflow.ErrorInvalidRequest, "symbol name is required")
+	}
+	if root == "" {
+		root = "."
+	}
+
+	ix, err := Open(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	if refs {
+		references, err := ix.References(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, reference := range references {
+			lines = append(lines, reference.String())
+		}
+		return lines, nil
+	}
+
+	definitions, err := ix.Definitions(ctx, name, kind)
+	if err != nil {
+		return nil, err
+	}
+	for _, symbol := range definitions {
+		line := symbol.String()
+		if symbol.Signature != "" {
+			line += "  " + symbol.Signature
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
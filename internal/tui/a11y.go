@@ -0,0 +1,108 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/aristath/claude-swarm/internal/state"
+	"github.com/aristath/claude-swarm/internal/workflow"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// In accessibility mode the TUI renders for screen readers: plain text
+// instead of box-drawing borders, progress bars and symbols, statuses in
+// words rather than only in color, and each new event announced as a line
+// of its own above the view.
+
+// symbol returns a glyph, or in accessibility mode the text standing in
+// for it
+func (m *OrchestrationModel) symbol(glyph, text string) string {
+	if m.a11y {
+		return text
+	}
+	return glyph
+}
+
+// marker prefixes the selected entry of a list
+func (m *OrchestrationModel) marker(selected bool) string {
+	switch {
+	case selected:
+		return m.symbol("▸ ", "[selected] ")
+	case m.a11y:
+		return ""
+	}
+	return "  "
+}
+
+// bordered gives a style a rounded border, except in accessibility mode
+func bordered(style lipgloss.Style, a11y bool) lipgloss.Style {
+	if a11y {
+		return style
+	}
+	return style.Border(lipgloss.RoundedBorder())
+}
+
+// describeEvent describes an event in words, such as "14:23:45 task
+// completed: analyze"
+func describeEvent(event workflow.FileEvent) string {
+	text := event.Time.Format("15:04:05") + " " + strings.ReplaceAll(string(event.Type), "_", " ")
+	if event.AgentID != "" {
+		text += ": " + event.AgentID
+	}
+	if event.Operator != "" {
+		text += " by " + event.Operator
+	}
+	return text
+}
+
+// announce prints the events since the last announcement as lines above
+// the view, where screen readers read them as they appear
+func (m *OrchestrationModel) announce() tea.Cmd {
+	if !m.a11y {
+		return nil
+	}
+	events, _ := m.state.QueryEvents(state.EventQuery{AfterSeq: m.announced})
+	if len(events) == 0 {
+		return nil
+	}
+
+	cmds := make([]tea.Cmd, 0, len(events))
+	for _, event := range events {
+		cmds = append(cmds, tea.Println("Event "+describeEvent(event)))
+	}
+	m.announced = events[len(events)-1].Seq
+	return tea.Sequence(cmds...)
+}
+
+// renderPlain renders the orchestrator's view and the agents one above the
+// other, under headings that say which one has focus
+func (m *OrchestrationModel) renderPlain() string {
+	heading := func(title string, pane PaneType) string {
+		if m.focusedPane == pane {
+			title += " (focused)"
+		}
+		return lipgloss.NewStyle().Bold(true).Render(title)
+	}
+
+	// Long lines wrap rather than run off the screen
+	return lipgloss.NewStyle().Width(m.width).Render(lipgloss.JoinVertical(lipgloss.Left,
+		m.renderHeader(),
+		"",
+		heading("Orchestrator", OrchestratorPane),
+		m.renderOrchestratorView(m.width),
+		"",
+		heading("Agents", AgentSidebarPane),
+		m.renderAgentSidebar(m.width),
+		m.renderFooter(),
+	))
+}
+
+// plainLayout sizes the views for renderPlain: the orchestrator's gets
+// two thirds of the rows the header, headings and footer leave
+func (m *OrchestrationModel) plainLayout() {
+	rows := max(m.height-10, 2)
+	m.mainViewport.Width = m.width
+	m.mainViewport.Height = rows * 2 / 3
+	m.sidebarViewport.Width = m.width
+	m.sidebarViewport.Height = rows - m.mainViewport.Height
+}
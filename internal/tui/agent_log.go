@@ -0,0 +1,50 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// agentLogLines is how many transcript lines the agent log view shows
+const agentLogLines = 200
+
+// agentLogTask returns the task whose transcript the agent log shows: the
+// agent selected in the sidebar, or "" for all agents
+func (m *OrchestrationModel) agentLogTask() string {
+	if m.focusedPane == AgentSidebarPane && m.state.GetAgent(m.selectedAgent) != nil {
+		return m.selectedAgent
+	}
+	return ""
+}
+
+// renderAgentLog renders the latest count lines of the agents' transcripts,
+// the operations they ran and the notes they logged with swarm-agent log.
+// Lines of all agents are prefixed with their task.
+func (m *OrchestrationModel) renderAgentLog(count int) string {
+	taskID := m.agentLogTask()
+	entries := m.state.AgentLog(taskID, count)
+	if len(entries) == 0 {
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Italic(true).Render("Nothing logged yet") + "\n"
+	}
+
+	var log strings.Builder
+	for _, entry := range entries {
+		if taskID == "" {
+			log.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("cyan")).Render(entry.TaskID + ": "))
+		}
+		log.WriteString(entry.Text)
+		log.WriteString("\n")
+	}
+	return log.String()
+}
+
+// renderAgentLogView renders the agent log view L opens
+func (m *OrchestrationModel) renderAgentLogView() string {
+	title := "Agent Log: all agents"
+	if taskID := m.agentLogTask(); taskID != "" {
+		title = fmt.Sprintf("Agent Log: %s", taskID)
+	}
+	return lipgloss.NewStyle().Bold(true).Render(title) + "\n\n" + m.renderAgentLog(agentLogLines)
+}
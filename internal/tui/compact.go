@@ -0,0 +1,160 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// The smallest terminal the split layout renders in: the sidebar needs
+// about 30 columns for agent cards, and the panes' borders and padding,
+// the header and the footer take about 12 rows
+const (
+	minSplitWidth  = 100
+	minSplitHeight = 20
+)
+
+// compact reports whether the terminal is too small for the split layout,
+// so a single pane is shown instead
+func (m *OrchestrationModel) compact() bool {
+	return m.width < minSplitWidth || m.height < minSplitHeight
+}
+
+// viewOpen reports whether a view opened with its key replaces the
+// orchestrator's overview
+func (m *OrchestrationModel) viewOpen() bool {
+	return m.showProposals || m.showStats || m.showSearch || m.showReview || m.showConflicts || m.showLog
+}
+
+// renderCompact renders the single pane shown in small terminals: a status
+// line, the questions awaiting answers and as many recent events as fit.
+// A view opened with its key takes the whole pane instead.
+func (m *OrchestrationModel) renderCompact() string {
+	fit := lipgloss.NewStyle().MaxWidth(m.width)
+	bodyHeight := m.height - 2
+
+	var body string
+	if m.viewOpen() {
+		body = m.renderOrchestratorView(m.width)
+	} else {
+		var content strings.Builder
+		questions := m.pendingQuestions()
+		if len(questions) > 0 {
+			shown := min(len(questions), max(bodyHeight/3, 1))
+			content.WriteString(lipgloss.NewStyle().Bold(true).Render(fmt.Sprintf("Pending questions (%d)", len(questions))))
+			content.WriteString("\n")
+			for _, question := range questions[:shown] {
+				content.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("yellow")).
+					Render(fmt.Sprintf("%s%s: %s", m.symbol("💬 ", ""), question.taskID, strings.Join(strings.Fields(question.Text), " "))))
+				content.WriteString("\n")
+			}
+			bodyHeight -= shown + 1
+		}
+
+		if bodyHeight > 1 {
+			content.WriteString(lipgloss.NewStyle().Bold(true).Render("Recent events"))
+			content.WriteString("\n")
+			content.WriteString(m.renderEventLog(bodyHeight - 1))
+		}
+		body = strings.TrimSuffix(content.String(), "\n")
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		fit.Render(m.renderCompactStatus()),
+		fit.MaxHeight(m.height-2).Height(m.height-2).Render(body),
+		fit.Render(m.renderCompactFooter()),
+	)
+}
+
+// renderCompactStatus renders the run's progress and task counts, with the
+// alerts the header would show, on one line
+func (m *OrchestrationModel) renderCompactStatus() string {
+	counts := make(map[workflow.TaskStatus]int)
+	for _, task := range m.state.GetTasks() {
+		switch agent := m.state.GetAgent(task.ID); {
+		case agent == nil:
+			counts[workflow.TaskStatusPending]++
+		case agent.Status.IsFailure():
+			counts[workflow.TaskStatusFailed]++
+		default:
+			counts[agent.Status]++
+		}
+	}
+
+	status := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).
+		Render(fmt.Sprintf("%s %.0f%%", m.state.Workflow.Name, m.state.GetProgress()))
+	status += fmt.Sprintf(" | %d running, %d done, %d failed, %d pending",
+		counts[workflow.TaskStatusRunning], counts[workflow.TaskStatusCompleted],
+		counts[workflow.TaskStatusFailed], counts[workflow.TaskStatusPending])
+
+	switch {
+	case m.state.IsCancelled():
+		status += " | " + lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("red")).Render("CANCELLED")
+	case m.state.IsHalted():
+		status += " | " + lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("red")).Render("HALTED")
+	case m.state.IsPaused():
+		status += " | " + lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("yellow")).Render(m.symbol("⏸ ", "")+"PAUSED")
+	}
+	if pending := len(m.state.PendingAnswers()); pending > 0 {
+		status += " | " + lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("yellow")).Render(fmt.Sprintf("%d to review", pending))
+	}
+	if open := len(m.state.OpenConflicts()); open > 0 {
+		status += " | " + lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("208")).Render(fmt.Sprintf("%d conflicts", open))
+	}
+	return status
+}
+
+// renderCompactFooter renders the footer's help on one line
+func (m *OrchestrationModel) renderCompactFooter() string {
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	switch {
+	case m.searching:
+		return helpStyle.Render(m.searchInput.View() + " [Enter] Search [Esc] Cancel")
+	case m.editing:
+		return helpStyle.Render("[Ctrl+S] Send answer [Esc] Discard")
+	case m.merging:
+		return helpStyle.Render("[Ctrl+S] Resolve [Esc] Discard")
+	case m.confirmCancel:
+		return helpStyle.Foreground(lipgloss.Color("red")).Render("Cancel the run? [Y] Cancel [I] And interrupt agents")
+	}
+
+	hold := "[H] Pause"
+	if m.state.IsPaused() {
+		hold = "[H] Resume"
+	}
+	help := "[S] Stats [L] Log [/] Search " + hold + " [X] Cancel"
+	if len(m.state.PendingAnswers()) > 0 || m.showReview {
+		help += " [V] Review"
+	}
+	if len(m.state.OpenConflicts()) > 0 || m.showConflicts {
+		help += " [C] Conflicts"
+	}
+	return helpStyle.Render(help + " [Q] Quit")
+}
+
+// pendingQuestion is a question still waiting for its answer
+type pendingQuestion struct {
+	taskID string
+	workflow.Question
+}
+
+// pendingQuestions returns the questions running agents are waiting on,
+// oldest first
+func (m *OrchestrationModel) pendingQuestions() []pendingQuestion {
+	var pending []pendingQuestion
+	for _, agent := range m.state.GetActiveAgents() {
+		for _, question := range agent.AllQuestions() {
+			if question.Answer == "" {
+				pending = append(pending, pendingQuestion{taskID: agent.TaskID, Question: question})
+			}
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].AskedAt.Before(pending[j].AskedAt)
+	})
+	return pending
+}
@@ -0,0 +1,183 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aristath/claude-swarm/internal/diff"
+	"github.com/aristath/claude-swarm/internal/orchestrator"
+	"github.com/aristath/claude-swarm/internal/state"
+	"github.com/aristath/claude-swarm/internal/workflow"
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// newMergeInput creates the input conflicts are merged in by hand
+func newMergeInput() textarea.Model {
+	input := textarea.New()
+	input.Placeholder = "Merged file..."
+	input.SetHeight(16)
+	input.CharLimit = 0
+	return input
+}
+
+// selectedConflictEntry returns the open conflict selected in the
+// conflicts view
+func (m *OrchestrationModel) selectedConflictEntry() (state.Conflict, bool) {
+	open := m.state.OpenConflicts()
+	if len(open) == 0 {
+		return state.Conflict{}, false
+	}
+	if m.selectedConflict >= len(open) {
+		m.selectedConflict = len(open) - 1
+	}
+	return open[m.selectedConflict], true
+}
+
+// updateConflicts handles keys in the conflicts view: Up and Down select a
+// conflict, 1 and 2 keep the first or second task's version and M merges
+// it by hand. It reports whether the key was handled.
+func (m *OrchestrationModel) updateConflicts(msg tea.KeyMsg) (bool, tea.Cmd) {
+	switch msg.String() {
+	case "up":
+		if m.selectedConflict > 0 {
+			m.selectedConflict--
+		}
+		return true, nil
+
+	case "down":
+		if m.selectedConflict < len(m.state.OpenConflicts())-1 {
+			m.selectedConflict++
+		}
+		return true, nil
+
+	case "1", "2":
+		if conflict, ok := m.selectedConflictEntry(); ok {
+			resolution := state.ResolutionKeepFirst
+			if msg.String() == "2" {
+				resolution = state.ResolutionKeepSecond
+			}
+			m.sendResolution(conflict, resolution, "")
+		}
+		return true, nil
+
+	case "m", "M":
+		conflict, ok := m.selectedConflictEntry()
+		if !ok {
+			return true, nil
+		}
+		merged, _ := diff.Merge3(conflict.Base, conflict.FirstText, conflict.SecondText, conflict.First, conflict.Second)
+		m.merging = true
+		m.mergeInput.SetWidth(m.mainViewport.Width)
+		m.mergeInput.SetValue(merged)
+		return true, m.mergeInput.Focus()
+	}
+	return false, nil
+}
+
+// updateMerging handles keys while a conflict is merged by hand: Ctrl+S
+// sends the merge, Esc discards it and everything else goes to the input
+func (m *OrchestrationModel) updateMerging(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.merging = false
+		m.mergeInput.Blur()
+		return nil
+
+	case "ctrl+s":
+		merged := m.mergeInput.Value()
+		if strings.Contains(merged, "<<<<<<< ") {
+			// Keep editing until every conflict is merged
+			return nil
+		}
+		m.merging = false
+		m.mergeInput.Blur()
+		if conflict, ok := m.selectedConflictEntry(); ok {
+			m.sendResolution(conflict, state.ResolutionMerged, merged)
+		}
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.mergeInput, cmd = m.mergeInput.Update(msg)
+	return cmd
+}
+
+// sendResolution asks the orchestrator to resolve a conflict
+func (m *OrchestrationModel) sendResolution(conflict state.Conflict, resolution, content string) {
+	orchestrator.SendControlRequest(m.swarmDir, workflow.ControlRequest{
+		Action:     workflow.ControlResolve,
+		Conflict:   conflict.ID,
+		Resolution: resolution,
+		Content:    content,
+	})
+}
+
+// renderConflicts renders the open conflicts, the selected one as a
+// three-way view: each task's change to the base and their merge
+func (m *OrchestrationModel) renderConflicts() string {
+	var content strings.Builder
+
+	open := m.state.OpenConflicts()
+	content.WriteString(lipgloss.NewStyle().Bold(true).Render(fmt.Sprintf("File conflicts (%d)", len(open))))
+	content.WriteString("\n\n")
+
+	if len(open) == 0 {
+		content.WriteString(lipgloss.NewStyle().
+			Foreground(lipgloss.Color("240")).
+			Italic(true).
+			Render("No open conflicts"))
+		return content.String()
+	}
+
+	selected, _ := m.selectedConflictEntry()
+	if m.merging {
+		content.WriteString(fmt.Sprintf("Merging conflict %d on %s\n\n", selected.ID, selected.Path))
+		content.WriteString(m.mergeInput.View())
+		return content.String()
+	}
+
+	conflictStyle := lipgloss.NewStyle().Bold(true)
+	for i, conflict := range open {
+		marker := m.marker(i == m.selectedConflict)
+		if i == m.selectedConflict {
+			conflictStyle = conflictStyle.Foreground(lipgloss.Color("205"))
+		} else {
+			conflictStyle = conflictStyle.UnsetForeground()
+		}
+		content.WriteString(conflictStyle.Render(fmt.Sprintf("%s#%d %s: %s and %s", marker, conflict.ID, conflict.Path, conflict.First, conflict.Second)))
+		content.WriteString("\n")
+	}
+	content.WriteString("\n")
+
+	headingStyle := lipgloss.NewStyle().Bold(true)
+	content.WriteString(headingStyle.Render(fmt.Sprintf("[1] %s's version", selected.First)))
+	content.WriteString("\n")
+	content.WriteString(renderDiff(diff.Unified("base", selected.First, selected.Base, selected.FirstText)))
+	content.WriteString("\n")
+	content.WriteString(headingStyle.Render(fmt.Sprintf("[2] %s's version", selected.Second)))
+	content.WriteString("\n")
+	content.WriteString(renderDiff(diff.Unified("base", selected.Second, selected.Base, selected.SecondText)))
+	content.WriteString("\n")
+
+	merged, conflicted := diff.Merge3(selected.Base, selected.FirstText, selected.SecondText, selected.First, selected.Second)
+	status := lipgloss.NewStyle().Foreground(lipgloss.Color("green")).Render("merges cleanly")
+	if conflicted {
+		status = lipgloss.NewStyle().Foreground(lipgloss.Color("208")).Render("needs a manual merge")
+	}
+	content.WriteString(headingStyle.Render("[M] Merge") + " (" + status + ")")
+	content.WriteString("\n")
+	for _, line := range strings.Split(strings.TrimSuffix(merged, "\n"), "\n") {
+		style := lipgloss.NewStyle()
+		switch {
+		case strings.HasPrefix(line, "<<<<<<< "), strings.HasPrefix(line, "||||||| "),
+			line == "=======", strings.HasPrefix(line, ">>>>>>> "):
+			style = style.Foreground(lipgloss.Color("208"))
+		}
+		content.WriteString(style.Render(line))
+		content.WriteString("\n")
+	}
+
+	return content.String()
+}
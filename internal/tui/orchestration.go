@@ -2,11 +2,17 @@ package tui
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/aristath/claude-swarm/internal/orchestrator"
+	"github.com/aristath/claude-swarm/internal/proposals"
+	"github.com/aristath/claude-swarm/internal/search"
 	"github.com/aristath/claude-swarm/internal/state"
 	"github.com/aristath/claude-swarm/internal/workflow"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -14,15 +20,45 @@ import (
 
 // OrchestrationModel handles the orchestration phase with split-screen layout
 type OrchestrationModel struct {
-	sessionID        string
-	swarmDir         string
-	state            *state.SwarmState
-	mainViewport     viewport.Model
-	sidebarViewport  viewport.Model
-	width            int
-	height           int
-	focusedPane      PaneType
-	lastUpdate       time.Time
+	sessionID       string
+	swarmDir        string
+	state           *state.SwarmState
+	mainViewport    viewport.Model
+	sidebarViewport viewport.Model
+	width           int
+	height          int
+	focusedPane     PaneType
+	lastUpdate      time.Time
+	proposals       *proposals.Store
+	showProposals   bool
+	showStats       bool
+	selectedSection int             // The task list section G selects
+	collapsed       map[string]bool // Collapsed task list sections by label
+	searching       bool            // The search query is being typed
+	searchInput     textinput.Model
+	showSearch      bool
+	searchQuery     string
+	searchMatches   []search.Match
+	searchErr       error
+	confirmCancel   bool // X was pressed; waiting for confirmation
+	showReview      bool
+	selectedDraft   int  // The drafted answer Enter approves
+	editing         bool // The selected draft is being edited
+	answerInput     textarea.Model
+	// The conflicts view, and the selected conflict being merged by hand
+	showConflicts    bool
+	selectedConflict int
+	merging          bool
+	mergeInput       textarea.Model
+	// The agent sidebar's order, its pinned agents and the selected one
+	agentOrder    agentOrder
+	pinned        map[string]bool
+	selectedAgent string
+	// The agent log view, showing the agents' transcripts
+	showLog bool
+	// Accessibility mode, and the last event announced in it
+	a11y      bool
+	announced int64
 }
 
 // PaneType represents which pane is focused
@@ -46,6 +82,10 @@ func NewOrchestrationModel(sessionID, swarmDir string, swarmState *state.SwarmSt
 		sidebarViewport: sideVP,
 		focusedPane:     OrchestratorPane,
 		lastUpdate:      time.Now(),
+		proposals:       proposals.NewStore(swarmDir),
+		searchInput:     newSearchInput(),
+		answerInput:     newAnswerInput(),
+		mergeInput:      newMergeInput(),
 	}
 }
 
@@ -61,10 +101,75 @@ func (m OrchestrationModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.searching {
+			return m, m.updateSearch(msg)
+		}
+		if m.editing {
+			return m, m.updateEditing(msg)
+		}
+		if m.merging {
+			return m, m.updateMerging(msg)
+		}
+		if m.showReview {
+			if handled, cmd := m.updateReview(msg); handled {
+				return m, cmd
+			}
+		}
+		if m.showConflicts {
+			if handled, cmd := m.updateConflicts(msg); handled {
+				return m, cmd
+			}
+		}
+		if m.confirmCancel {
+			m.confirmCancel = false
+			switch msg.String() {
+			case "y", "Y", "i", "I":
+				orchestrator.SendControlRequest(m.swarmDir, workflow.ControlRequest{
+					Action: workflow.ControlCancel,
+					Signal: msg.String() == "i" || msg.String() == "I",
+				})
+			}
+			return m, nil
+		}
+		if m.focusedPane == AgentSidebarPane && m.updateSidebar(msg) {
+			return m, nil
+		}
+
 		switch msg.String() {
 		case "ctrl+c", "q", "Q":
 			return m, tea.Quit
 
+		case "/":
+			// Search outputs, answers and transcripts
+			return m, m.startSearch()
+
+		case "esc":
+			// Close the search results
+			m.showSearch = false
+			return m, nil
+
+		case "v", "V":
+			// Toggle between the overview and the answers awaiting review
+			m.showReview = !m.showReview
+			m.showProposals = false
+			m.showStats = false
+			m.showSearch = false
+			m.showConflicts = false
+			m.showLog = false
+			m.mainViewport.GotoTop()
+			return m, nil
+
+		case "c", "C":
+			// Toggle between the overview and the file conflicts
+			m.showConflicts = !m.showConflicts
+			m.showProposals = false
+			m.showStats = false
+			m.showSearch = false
+			m.showReview = false
+			m.showLog = false
+			m.mainViewport.GotoTop()
+			return m, nil
+
 		case "tab":
 			// Switch focused pane
 			if m.focusedPane == OrchestratorPane {
@@ -78,6 +183,75 @@ func (m OrchestrationModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Refresh view
 			m.updateViewports()
 			return m, nil
+
+		case "a", "A":
+			// Approve all agents paused by their quotas
+			for _, agent := range m.state.GetQuotaPausedAgents() {
+				orchestrator.SendControlRequest(m.swarmDir, workflow.ControlRequest{
+					Action: workflow.ControlApproveQuota,
+					TaskID: agent.TaskID,
+				})
+			}
+			return m, nil
+
+		case "p", "P":
+			// Toggle between the overview and the proposed changes
+			m.showProposals = !m.showProposals
+			m.showStats = false
+			m.showSearch = false
+			m.showReview = false
+			m.showConflicts = false
+			m.showLog = false
+			m.mainViewport.GotoTop()
+			return m, nil
+
+		case "h", "H":
+			// Hold spawning, or resume it
+			action := workflow.ControlPause
+			if m.state.IsPaused() {
+				action = workflow.ControlResume
+			}
+			orchestrator.SendControlRequest(m.swarmDir, workflow.ControlRequest{Action: action})
+			return m, nil
+
+		case "x", "X":
+			// Cancel the run, once confirmed
+			if !m.state.IsCancelled() {
+				m.confirmCancel = true
+			}
+			return m, nil
+
+		case "g", "G":
+			// Select the next section of the task list
+			m.selectNextSection()
+			return m, nil
+
+		case " ":
+			// Collapse or expand the selected section
+			m.toggleSection()
+			return m, nil
+
+		case "s", "S":
+			// Toggle between the overview and the session stats
+			m.showStats = !m.showStats
+			m.showProposals = false
+			m.showSearch = false
+			m.showReview = false
+			m.showConflicts = false
+			m.showLog = false
+			m.mainViewport.GotoTop()
+			return m, nil
+
+		case "l", "L":
+			// Toggle between the overview and the agents' transcripts
+			m.showLog = !m.showLog
+			m.showProposals = false
+			m.showStats = false
+			m.showSearch = false
+			m.showReview = false
+			m.showConflicts = false
+			m.mainViewport.GotoTop()
+			return m, nil
 		}
 
 	case tea.WindowSizeMsg:
@@ -94,6 +268,15 @@ func (m OrchestrationModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.sidebarViewport.Width = sideWidth - 4
 		m.sidebarViewport.Height = msg.Height - 8
 
+		// Small terminals get a single pane, between a status line and
+		// a footer
+		if m.compact() {
+			m.mainViewport.Width = msg.Width
+			m.mainViewport.Height = msg.Height - 2
+		} else if m.a11y {
+			m.plainLayout()
+		}
+
 		m.updateViewports()
 		return m, nil
 
@@ -101,12 +284,12 @@ func (m OrchestrationModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Periodic update
 		m.lastUpdate = time.Time(msg)
 		m.updateViewports()
-		return m, m.tick()
+		return m, tea.Batch(m.tick(), m.announce())
 
 	case OrchestratorEventMsg:
 		// Handle orchestrator events
 		m.updateViewports()
-		return m, nil
+		return m, m.announce()
 	}
 
 	// Update viewports based on focused pane
@@ -125,6 +308,12 @@ func (m OrchestrationModel) View() string {
 	if m.width == 0 {
 		return "Initializing..."
 	}
+	if m.compact() {
+		return m.renderCompact()
+	}
+	if m.a11y {
+		return m.renderPlain()
+	}
 
 	// Calculate split widths (70/30)
 	mainWidth := int(float64(m.width) * 0.70)
@@ -155,14 +344,14 @@ func (m OrchestrationModel) View() string {
 
 	mainStyle := lipgloss.NewStyle().
 		Width(mainWidth).
-		Height(m.height - 4).
+		Height(m.height-4).
 		Border(mainBorder).
 		BorderForeground(mainColor).
 		Padding(1, 2)
 
 	sideStyle := lipgloss.NewStyle().
 		Width(sideWidth).
-		Height(m.height - 4).
+		Height(m.height-4).
 		Border(sideBorder).
 		BorderForeground(sideColor).
 		Padding(1, 2)
@@ -197,15 +386,95 @@ func (m *OrchestrationModel) renderHeader() string {
 		progress,
 		m.lastUpdate.Format("15:04:05"))
 
+	if metrics := m.state.GetMetrics(); metrics.FileReads > 0 {
+		info += fmt.Sprintf(" | Cache: %d/%d reads (%.0f%%)",
+			metrics.CacheHits, metrics.FileReads, metrics.CacheHitRate())
+	}
+	if metrics := m.state.GetMetrics(); metrics.Coalesced > 0 {
+		info += fmt.Sprintf(" | Coalesced: %d", metrics.Coalesced)
+	}
+	info += m.renderUsage()
+
+	switch {
+	case m.state.IsCancelled():
+		info += " | " + lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("red")).Render("CANCELLED")
+	case m.state.IsHalted():
+		info += " | " + lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("red")).Render("HALTED")
+	case m.state.IsPaused():
+		info += " | " + lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("yellow")).Render(m.symbol("⏸ ", "")+"PAUSED")
+	}
+	if pending := len(m.state.PendingAnswers()); pending > 0 {
+		info += " | " + lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("yellow")).Render(fmt.Sprintf("%d answers to review", pending))
+	}
+	if open := len(m.state.OpenConflicts()); open > 0 {
+		info += " | " + lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("208")).Render(fmt.Sprintf("%d file conflicts", open))
+	}
+
 	return lipgloss.JoinVertical(lipgloss.Left,
 		headerStyle.Render(title),
 		infoStyle.Render(info),
 	)
 }
 
+// renderUsage renders the tokens and cost agents reported, against the
+// budget if the workflow has one
+func (m *OrchestrationModel) renderUsage() string {
+	usage := m.state.TokenUsage()
+	budget := m.state.Workflow.Budget
+	if budget == nil && usage.Tokens() == 0 {
+		return ""
+	}
+
+	text := " | Tokens: " + workflow.FormatTokens(usage.Tokens())
+	if budget != nil && budget.MaxTokens > 0 {
+		text += " of " + workflow.FormatTokens(budget.MaxTokens)
+	}
+	text += fmt.Sprintf(" ($%.2f", usage.CostUSD)
+	if budget != nil && budget.MaxCostUSD > 0 {
+		text += fmt.Sprintf(" of $%.2f", budget.MaxCostUSD)
+	}
+	text += ")"
+
+	if m.state.BudgetExceeded() != "" {
+		text += " " + lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("red")).Render("OVER BUDGET")
+	}
+	return text
+}
+
 func (m *OrchestrationModel) renderOrchestratorView(width int) string {
 	var content strings.Builder
 
+	if m.showProposals {
+		content.WriteString(m.renderProposalDiffs())
+		m.mainViewport.SetContent(content.String())
+		return m.mainViewport.View()
+	}
+	if m.showStats {
+		content.WriteString(m.renderStats())
+		m.mainViewport.SetContent(content.String())
+		return m.mainViewport.View()
+	}
+	if m.showSearch {
+		content.WriteString(m.renderSearch())
+		m.mainViewport.SetContent(content.String())
+		return m.mainViewport.View()
+	}
+	if m.showReview {
+		content.WriteString(m.renderReview())
+		m.mainViewport.SetContent(content.String())
+		return m.mainViewport.View()
+	}
+	if m.showConflicts {
+		content.WriteString(m.renderConflicts())
+		m.mainViewport.SetContent(content.String())
+		return m.mainViewport.View()
+	}
+	if m.showLog {
+		content.WriteString(m.renderAgentLogView())
+		m.mainViewport.SetContent(content.String())
+		return m.mainViewport.View()
+	}
+
 	// Progress bar
 	progress := m.state.GetProgress()
 	progressBar := m.renderProgressBar(progress, width-8)
@@ -223,6 +492,20 @@ func (m *OrchestrationModel) renderOrchestratorView(width int) string {
 	content.WriteString("\n")
 	content.WriteString(m.renderEventLog(8))
 
+	// The agents' transcripts
+	content.WriteString("\n")
+	content.WriteString(lipgloss.NewStyle().Bold(true).Render("Agent Log:"))
+	content.WriteString("\n")
+	content.WriteString(m.renderAgentLog(6))
+
+	// Proposed changes
+	if m.state.IsReadOnly() {
+		content.WriteString("\n")
+		content.WriteString(lipgloss.NewStyle().Bold(true).Render("Proposed Changes:"))
+		content.WriteString("\n")
+		content.WriteString(m.renderProposalSummary())
+	}
+
 	m.mainViewport.SetContent(content.String())
 	return m.mainViewport.View()
 }
@@ -230,12 +513,16 @@ func (m *OrchestrationModel) renderOrchestratorView(width int) string {
 func (m *OrchestrationModel) renderAgentSidebar(width int) string {
 	var content strings.Builder
 
-	// Active agents section
-	activeAgents := m.state.GetActiveAgents()
+	// Active agents section, pinned agents first
+	activeAgents := m.sidebarAgents()
 	content.WriteString(lipgloss.NewStyle().
 		Bold(true).
 		Foreground(lipgloss.Color("205")).
 		Render(fmt.Sprintf("Active Agents (%d)", len(activeAgents))))
+	content.WriteString("\n")
+	content.WriteString(lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240")).
+		Render("By " + m.agentOrder.String()))
 	content.WriteString("\n\n")
 
 	if len(activeAgents) == 0 {
@@ -246,6 +533,9 @@ func (m *OrchestrationModel) renderAgentSidebar(width int) string {
 		content.WriteString("\n\n")
 	} else {
 		for _, agent := range activeAgents {
+			if m.focusedPane == AgentSidebarPane {
+				content.WriteString(m.marker(agent.TaskID == m.selectedAgent))
+			}
 			content.WriteString(m.renderAgentCard(agent))
 			content.WriteString("\n")
 		}
@@ -264,6 +554,10 @@ func (m *OrchestrationModel) renderAgentSidebar(width int) string {
 }
 
 func (m *OrchestrationModel) renderProgressBar(progress float64, width int) string {
+	if m.a11y {
+		return fmt.Sprintf("Progress: %.0f%%", progress)
+	}
+
 	filled := int((progress / 100.0) * float64(width))
 	empty := width - filled
 
@@ -275,51 +569,102 @@ func (m *OrchestrationModel) renderProgressBar(progress float64, width int) stri
 }
 
 func (m *OrchestrationModel) renderTaskList() string {
-	var tasks strings.Builder
-
-	for _, task := range m.state.Workflow.Tasks {
-		agent := m.state.GetAgent(task.ID)
+	// Workflows with labels are shown as collapsible sections
+	if sections := taskSections(m.state.GetTasks()); sections != nil {
+		return m.renderSections(sections)
+	}
 
-		var status string
-		var icon string
-		var color lipgloss.Color
+	var tasks strings.Builder
+	for _, task := range m.state.GetTasks() {
+		tasks.WriteString(m.renderTaskLine(task))
+		tasks.WriteString("\n")
+	}
+	return tasks.String()
+}
 
-		if agent == nil {
-			status = "pending"
-			icon = "⋯"
-			color = lipgloss.Color("240")
-		} else {
-			switch agent.Status {
-			case workflow.TaskStatusRunning:
-				status = "running"
-				icon = "⧗"
-				color = lipgloss.Color("yellow")
-				elapsed := time.Since(agent.StartedAt).Round(time.Second)
-				status = fmt.Sprintf("running (%s)", elapsed)
-			case workflow.TaskStatusCompleted:
-				status = "completed"
-				icon = "✓"
-				color = lipgloss.Color("green")
-			case workflow.TaskStatusFailed:
-				status = "failed"
-				icon = "✗"
+// renderTaskLine renders a task with its status
+func (m *OrchestrationModel) renderTaskLine(task workflow.Task) string {
+	agent := m.state.GetAgent(task.ID)
+
+	var status string
+	var icon string
+	var color lipgloss.Color
+
+	if agent == nil {
+		status = "pending"
+		icon = "⋯"
+		color = lipgloss.Color("240")
+		if failed := m.state.BlockedBy(task.ID); failed != "" {
+			status = fmt.Sprintf("blocked (%s failed)", failed)
+			icon = "⊗"
+		}
+	} else {
+		switch agent.Status {
+		case workflow.TaskStatusRunning:
+			status = "running"
+			icon = "⧗"
+			color = lipgloss.Color("yellow")
+			elapsed := time.Since(agent.StartedAt).Round(time.Second)
+			status = fmt.Sprintf("running (%s)", elapsed)
+			if task.Overdue(elapsed) {
+				status = fmt.Sprintf("overdue (%s, expected %s)", elapsed, task.Expected())
+				color = lipgloss.Color("208")
+			}
+			if agent.Stale {
+				icon = "☠"
+				status = fmt.Sprintf("stale (%s, no heartbeat)", elapsed)
 				color = lipgloss.Color("red")
-			default:
-				status = "unknown"
-				icon = "?"
-				color = lipgloss.Color("240")
 			}
+		case workflow.TaskStatusCompleted:
+			status = "completed"
+			icon = "✓"
+			color = lipgloss.Color("green")
+		case workflow.TaskStatusFailed:
+			status = "failed"
+			icon = "✗"
+			color = lipgloss.Color("red")
+		case workflow.TaskStatusQuarantined:
+			status = "quarantined, rerun to release"
+			icon = "☣"
+			color = lipgloss.Color("red")
+		case workflow.TaskStatusCancelled:
+			status = "cancelled"
+			icon = "⊘"
+			color = lipgloss.Color("240")
+		case workflow.TaskStatusSkipped:
+			status = "skipped"
+			icon = "⏭"
+			color = lipgloss.Color("240")
+		default:
+			status = "unknown"
+			icon = "?"
+			color = lipgloss.Color("240")
+		}
+		if agent.Restarts > 0 {
+			status += fmt.Sprintf(", restart %d", agent.Restarts)
+		}
+		if tokens := agent.Tokens.Tokens(); tokens > 0 {
+			status += ", " + workflow.FormatTokens(tokens) + " tokens"
+		}
+		if summary := agent.Completion.Summary(); summary != "" {
+			status += ", " + summary
 		}
-
-		line := lipgloss.NewStyle().
-			Foreground(color).
-			Render(fmt.Sprintf("  %s %-15s [%s]", icon, task.ID, status))
-
-		tasks.WriteString(line)
-		tasks.WriteString("\n")
 	}
 
-	return tasks.String()
+	line := fmt.Sprintf("  %s %-15s [%s]", icon, task.ID, status)
+	if m.a11y {
+		line = fmt.Sprintf("  %s: %s", task.ID, status)
+	}
+	line = lipgloss.NewStyle().
+		Foreground(color).
+		Render(line)
+
+	// The notes the agent completed with go below its task
+	if agent != nil && agent.Completion != nil && agent.Completion.Notes != "" {
+		notes := truncate(strings.Join(strings.Fields(agent.Completion.Notes), " "), 80)
+		line += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("      "+m.symbol("✎ ", "Notes: ")+notes)
+	}
+	return line
 }
 
 func (m *OrchestrationModel) renderEventLog(count int) string {
@@ -345,14 +690,87 @@ func (m *OrchestrationModel) renderEventLog(count int) string {
 		case workflow.EventQuestionAnswered:
 			icon = "💡"
 			color = lipgloss.Color("green")
+		case workflow.EventAnswerDrafted:
+			icon = "✎"
+			color = lipgloss.Color("yellow")
+		case workflow.EventQuotaExceeded:
+			icon = "⏸"
+			color = lipgloss.Color("red")
+		case workflow.EventBudgetExceeded:
+			icon = "$"
+			color = lipgloss.Color("red")
+		case workflow.EventOperationFailed:
+			icon = "⚠"
+			color = lipgloss.Color("yellow")
+		case workflow.EventLockAcquired:
+			icon = "🔒"
+			color = lipgloss.Color("magenta")
+		case workflow.EventLockReleased:
+			icon = "🔓"
+			color = lipgloss.Color("240")
+		case workflow.EventTaskRepeated:
+			icon = "↻"
+			color = lipgloss.Color("cyan")
+		case workflow.EventTaskRerun:
+			icon = "↻"
+			color = lipgloss.Color("yellow")
+		case workflow.EventTaskSkipped:
+			icon = "⏭"
+			color = lipgloss.Color("240")
+		case workflow.EventTaskOverdue:
+			icon = "⏰"
+			color = lipgloss.Color("208")
+		case workflow.EventAgentStale:
+			icon = "☠"
+			color = lipgloss.Color("red")
+		case workflow.EventAgentCrashed:
+			icon = "↯"
+			color = lipgloss.Color("red")
+		case workflow.EventVerificationFailed:
+			icon = "✗"
+			color = lipgloss.Color("208")
+		case workflow.EventTaskQuarantined:
+			icon = "☣"
+			color = lipgloss.Color("red")
+		case workflow.EventTaskCancelled, workflow.EventSwarmCancelled:
+			icon = "⊘"
+			color = lipgloss.Color("red")
+		case workflow.EventSwarmPaused:
+			icon = "⏸"
+			color = lipgloss.Color("yellow")
+		case workflow.EventSwarmResumed:
+			icon = "▶"
+			color = lipgloss.Color("green")
+		case workflow.EventCheckpoint:
+			icon = "⚑"
+			color = lipgloss.Color("cyan")
+		case workflow.EventFileConflict:
+			icon = "⚔"
+			color = lipgloss.Color("208")
+		case workflow.EventConflictResolved:
+			icon = "⚖"
+			color = lipgloss.Color("green")
+		case workflow.EventTaskProgress:
+			icon = "◔"
+			color = lipgloss.Color("cyan")
 		default:
 			icon = "•"
 			color = lipgloss.Color("240")
 		}
 
+		text := fmt.Sprintf("%s [%s] %s: %s", icon, timestamp, event.AgentID, event.Type)
+		if event.AgentID == "" {
+			text = fmt.Sprintf("%s [%s] %s", icon, timestamp, event.Type)
+		}
+		if event.Operator != "" {
+			text += " by " + event.Operator
+		}
+		if m.a11y {
+			text = describeEvent(event)
+		}
 		line := lipgloss.NewStyle().
 			Foreground(color).
-			Render(fmt.Sprintf("%s [%s] %s: %s", icon, timestamp, event.AgentID, event.Type))
+			Render(text)
 
 		log.WriteString(line)
 		log.WriteString("\n")
@@ -361,6 +779,175 @@ func (m *OrchestrationModel) renderEventLog(count int) string {
 	return log.String()
 }
 
+func (m *OrchestrationModel) renderProposalSummary() string {
+	pending, err := m.proposals.Pending()
+	if err != nil {
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("red")).Render(err.Error())
+	}
+	if len(pending) == 0 {
+		return lipgloss.NewStyle().
+			Foreground(lipgloss.Color("240")).
+			Italic(true).
+			Render("No proposed changes yet")
+	}
+
+	sets := proposals.ByTask(pending)
+
+	var summary strings.Builder
+	for _, task := range m.state.GetTasks() {
+		set := sets[task.ID]
+		if len(set) == 0 {
+			continue
+		}
+
+		added, removed := 0, 0
+		for _, proposal := range set {
+			a, r := proposal.LineStats()
+			added += a
+			removed += r
+		}
+
+		summary.WriteString(fmt.Sprintf("  %-15s %d files ", task.ID, len(set)))
+		summary.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("green")).Render(fmt.Sprintf("+%d", added)))
+		summary.WriteString(" ")
+		summary.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("red")).Render(fmt.Sprintf("-%d", removed)))
+		summary.WriteString("\n")
+	}
+
+	summary.WriteString(lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240")).
+		Render(fmt.Sprintf("  Press [P] to review; apply with: swarm proposals apply %s <task-id>", m.sessionID)))
+	summary.WriteString("\n")
+
+	return summary.String()
+}
+
+func (m *OrchestrationModel) renderProposalDiffs() string {
+	var content strings.Builder
+
+	content.WriteString(lipgloss.NewStyle().Bold(true).Render("Proposed Changes"))
+	content.WriteString("\n\n")
+
+	pending, err := m.proposals.Pending()
+	if err != nil {
+		content.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("red")).Render(err.Error()))
+		return content.String()
+	}
+	if len(pending) == 0 {
+		content.WriteString(lipgloss.NewStyle().
+			Foreground(lipgloss.Color("240")).
+			Italic(true).
+			Render("No proposed changes"))
+		return content.String()
+	}
+
+	for _, proposal := range pending {
+		content.WriteString(lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("205")).
+			Render(fmt.Sprintf("%s (%s)", proposal.Path, proposal.TaskID)))
+		content.WriteString("\n")
+		content.WriteString(renderDiff(proposal.Diff))
+		content.WriteString("\n")
+	}
+
+	return content.String()
+}
+
+// renderDiff colours a unified diff
+func renderDiff(text string) string {
+	var content strings.Builder
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		style := lipgloss.NewStyle()
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			style = style.Foreground(lipgloss.Color("cyan"))
+		case strings.HasPrefix(line, "+"):
+			style = style.Foreground(lipgloss.Color("green"))
+		case strings.HasPrefix(line, "-"):
+			style = style.Foreground(lipgloss.Color("red"))
+		}
+		content.WriteString(style.Render(line))
+		content.WriteString("\n")
+	}
+	return content.String()
+}
+
+func (m *OrchestrationModel) renderStats() string {
+	var content strings.Builder
+
+	stats := m.state.GetStats()
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	row := func(label, value string) {
+		content.WriteString(labelStyle.Render(fmt.Sprintf("  %-22s", label)))
+		content.WriteString(value)
+		content.WriteString("\n")
+	}
+
+	content.WriteString(lipgloss.NewStyle().Bold(true).Render("Session Stats"))
+	content.WriteString("\n\n")
+
+	content.WriteString(lipgloss.NewStyle().Bold(true).Render("Operations:"))
+	content.WriteString("\n")
+	row("Throughput", fmt.Sprintf("%.1f/min", stats.OperationsPerMinute))
+	row("Total", fmt.Sprintf("%d", stats.Operations))
+	row("Average latency", formatWait(stats.AverageLatency))
+	row("Coalesced", fmt.Sprintf("%d", stats.Coalesced))
+	if stats.FileReads > 0 {
+		row("Cache hits", fmt.Sprintf("%d/%d reads (%.0f%%)", stats.CacheHits, stats.FileReads, stats.CacheHitRate()))
+	}
+	if stats.EventsDeferred > 0 {
+		row("Events deferred", fmt.Sprintf("%d (peak backlog %d)", stats.EventsDeferred, stats.EventBacklogPeak))
+	}
+	if stats.EventsRecovered > 0 {
+		row("Events recovered", fmt.Sprintf("%d", stats.EventsRecovered))
+	}
+	if stats.Duplicates > 0 {
+		row("Duplicates answered", fmt.Sprintf("%d", stats.Duplicates))
+	}
+	if cleaned := stats.MessagesDeleted + stats.MessagesArchived; cleaned > 0 {
+		row("Messages cleaned up", fmt.Sprintf("%d (%d archived)", cleaned, stats.MessagesArchived))
+	}
+	content.WriteString("\n")
+
+	content.WriteString(lipgloss.NewStyle().Bold(true).Render("Bash:"))
+	content.WriteString("\n")
+	row("Executions", fmt.Sprintf("%.1f/min", stats.BashPerMinute))
+	row("Total", fmt.Sprintf("%d", stats.BashRuns))
+	content.WriteString("\n")
+
+	content.WriteString(lipgloss.NewStyle().Bold(true).Render("Questions:"))
+	content.WriteString("\n")
+	row("Answered", fmt.Sprintf("%d", stats.QuestionsAnswered))
+	row("Average wait", formatWait(stats.AverageQuestionWait))
+	row("Longest wait", formatWait(stats.LongestQuestionWait))
+	waiting := fmt.Sprintf("%d", stats.QuestionsWaiting)
+	if stats.QuestionsWaiting > 0 {
+		waiting = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("yellow")).
+			Render(fmt.Sprintf("%d (longest %s)", stats.QuestionsWaiting, formatWait(stats.LongestPendingWait)))
+	}
+	row("Waiting", waiting)
+
+	content.WriteString("\n")
+	content.WriteString(labelStyle.Render("  Rates cover the last 5 minutes"))
+	content.WriteString("\n")
+
+	return content.String()
+}
+
+// formatWait formats a duration with a precision that suits its size
+func formatWait(d time.Duration) string {
+	switch {
+	case d == 0:
+		return "-"
+	case d < time.Second:
+		return d.Round(time.Millisecond).String()
+	default:
+		return d.Round(100 * time.Millisecond).String()
+	}
+}
+
 func (m *OrchestrationModel) renderAgentCard(agent *workflow.AgentState) string {
 	elapsed := time.Since(agent.StartedAt).Round(time.Second)
 
@@ -374,7 +961,7 @@ func (m *OrchestrationModel) renderAgentCard(agent *workflow.AgentState) string
 	case workflow.TaskStatusCompleted:
 		statusIcon = "✓"
 		statusColor = lipgloss.Color("green")
-	case workflow.TaskStatusFailed:
+	case workflow.TaskStatusFailed, workflow.TaskStatusQuarantined:
 		statusIcon = "✗"
 		statusColor = lipgloss.Color("red")
 	default:
@@ -382,17 +969,69 @@ func (m *OrchestrationModel) renderAgentCard(agent *workflow.AgentState) string
 		statusColor = lipgloss.Color("240")
 	}
 
-	card := fmt.Sprintf("%s %s\n  Started: %s ago\n  Questions: %d",
-		statusIcon,
-		agent.TaskID,
+	title := statusIcon + " " + agent.TaskID
+	if m.a11y {
+		title = fmt.Sprintf("%s (%s)", agent.TaskID, agent.Status)
+	}
+	if m.pinned[agent.TaskID] {
+		title += m.symbol(" 📌", ", pinned")
+	}
+	card := fmt.Sprintf("%s\n  Started: %s ago\n  Questions: %d",
+		title,
 		elapsed,
 		len(agent.Questions))
 
+	if agent.Progress != "" || agent.ProgressPercent != nil {
+		card += "\n" + m.renderAgentProgress(agent)
+	}
+
+	if len(agent.OperationErrors) > 0 {
+		card += fmt.Sprintf("\n  Errors: %s", formatErrorCounts(agent.OperationErrors))
+	}
+
+	if task := m.state.GetTask(agent.TaskID); task != nil && agent.Status == workflow.TaskStatusRunning && task.Overdue(elapsed) {
+		statusColor = lipgloss.Color("208")
+		card += fmt.Sprintf("\n  %sOverdue: expected %s", m.symbol("⏰ ", ""), task.Expected())
+	}
+
+	if agent.QuotaPaused {
+		statusColor = lipgloss.Color("red")
+		card += fmt.Sprintf("\n  %sPaused: %s", m.symbol("⏸ ", ""), agent.QuotaPausedReason)
+	}
+
+	if agent.Stale && agent.Status == workflow.TaskStatusRunning {
+		statusColor = lipgloss.Color("red")
+		card += "\n  " + m.symbol("☠ ", "") + "Stale: no heartbeat"
+		if !agent.LastHeartbeat.IsZero() {
+			card += fmt.Sprintf(" since %s", agent.LastHeartbeat.Format("15:04:05"))
+		}
+	}
+
 	return lipgloss.NewStyle().
 		Foreground(statusColor).
 		Render(card)
 }
 
+// formatErrorCounts lists error counts by code, most frequent first
+func formatErrorCounts(counts map[workflow.ErrorCode]int) string {
+	codes := make([]workflow.ErrorCode, 0, len(counts))
+	for code := range counts {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		if counts[codes[i]] != counts[codes[j]] {
+			return counts[codes[i]] > counts[codes[j]]
+		}
+		return codes[i] < codes[j]
+	})
+
+	parts := make([]string, len(codes))
+	for i, code := range codes {
+		parts[i] = fmt.Sprintf("%s×%d", code, counts[code])
+	}
+	return strings.Join(parts, ", ")
+}
+
 func (m *OrchestrationModel) renderRecentQuestions(count int) string {
 	var questions strings.Builder
 
@@ -403,21 +1042,17 @@ func (m *OrchestrationModel) renderRecentQuestions(count int) string {
 				break
 			}
 
-			qText := q.Text
-			if len(qText) > 50 {
-				qText = qText[:50] + "..."
-			}
+			qa := fmt.Sprintf("%s %s orchestrator\nQ: %s\nA: %s\n",
+				agent.TaskID,
+				m.symbol("→", "asked the"),
+				truncate(q.Text, 50),
+				truncate(q.Answer, 50))
 
-			aText := q.Answer
-			if len(aText) > 50 {
-				aText = aText[:50] + "..."
+			// Follow-ups are shown with the question they continue
+			for _, reply := range q.Replies {
+				qa += fmt.Sprintf("  %sQ: %s\n    A: %s\n", m.symbol("↳ ", "Follow-up "), truncate(reply.Text, 46), truncate(reply.Answer, 46))
 			}
 
-			qa := fmt.Sprintf("%s → orchestrator\nQ: %s\nA: %s\n",
-				agent.TaskID,
-				qText,
-				aText)
-
 			questions.WriteString(lipgloss.NewStyle().
 				Foreground(lipgloss.Color("240")).
 				Render(qa))
@@ -437,12 +1072,62 @@ func (m *OrchestrationModel) renderRecentQuestions(count int) string {
 	return questions.String()
 }
 
+// truncate shortens text to n bytes, marking the cut with "..."
+func truncate(text string, n int) string {
+	if len(text) > n {
+		return text[:n] + "..."
+	}
+	return text
+}
+
 func (m *OrchestrationModel) renderFooter() string {
 	helpStyle := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("240")).
 		Padding(1, 2)
 
-	return helpStyle.Render("[Tab] Switch pane | [R] Refresh | [Q] Quit")
+	if m.searching {
+		return helpStyle.Render(m.searchInput.View() + "  [Enter] Search | [Esc] Cancel")
+	}
+	if m.editing {
+		return helpStyle.Render("[Ctrl+S] Send answer | [Esc] Discard edit")
+	}
+	if m.merging {
+		return helpStyle.Render("[Ctrl+S] Resolve with the merge, once no conflict markers remain | [Esc] Discard merge")
+	}
+	if m.confirmCancel {
+		return helpStyle.Foreground(lipgloss.Color("red")).
+			Render("Cancel the run? Remaining tasks are marked cancelled. [Y] Cancel | [I] Cancel and interrupt agents | any other key to keep running")
+	}
+
+	hold := "[H] Pause"
+	if m.state.IsPaused() {
+		hold = "[H] Resume"
+	}
+	help := "[Tab] Switch pane | [R] Refresh | [A] Approve paused agents | [S] Stats | [L] Agent log | [/] Search | " + hold + " | [X] Cancel"
+	if m.showSearch {
+		help += " | [Esc] Close search"
+	}
+	if m.showReview {
+		help += " | [" + m.symbol("↑/↓", "Up/Down") + "] Select | [Enter] Approve draft | [E] Edit"
+	} else if len(m.state.PendingAnswers()) > 0 {
+		help += " | [V] Review answers"
+	}
+	if m.showConflicts {
+		help += " | [" + m.symbol("↑/↓", "Up/Down") + "] Select | [1] Keep first | [2] Keep second | [M] Merge"
+	} else if len(m.state.OpenConflicts()) > 0 {
+		help += " | [C] Conflicts"
+	}
+	if m.state.IsReadOnly() {
+		help += " | [P] Proposals"
+	}
+	if taskSections(m.state.GetTasks()) != nil {
+		help += " | [G] Next section | [Space] Fold"
+	}
+	if m.focusedPane == AgentSidebarPane {
+		help += " | [N] Next agent | [T] Pin | [O] Sort: " + m.agentOrder.String()
+	}
+
+	return helpStyle.Render(help + " | [Q] Quit")
 }
 
 func (m *OrchestrationModel) updateViewports() {
@@ -7,6 +7,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/aristath/claude-swarm/internal/estimate"
+	"github.com/aristath/claude-swarm/internal/memory"
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
@@ -36,17 +38,40 @@ type PlanningModel struct {
 	swarmDir    string
 	mode        PlanningMode
 	messages    []Message
-	plan        strings.Builder
+	plans       []planDraft // Alternative plans; there is always one
+	current     int         // The plan being discussed or selected
 	viewport    viewport.Model
 	textarea    textarea.Model
 	width       int
 	height      int
 	ready       bool
 	workflowGen *WorkflowGenerator
+	summarizer  *PlanSummarizer
+	options     PlanningOptions
+	deadline    time.Time
+	recalled    string // The project memory the discussion starts from
+	a11y        bool   // Render for screen readers
 }
 
-// NewPlanningModel creates a new planning model
-func NewPlanningModel(sessionID, swarmDir string) PlanningModel {
+// PlanningOptions configures the planning phase
+type PlanningOptions struct {
+	// SummarizeEvery periodically condenses the discussion into plan.md
+	// instead of saving the messages verbatim; zero disables it
+	SummarizeEvery time.Duration
+	// Timebox ends the discussion after this long and moves on to
+	// reviewing the plan; zero means no limit
+	Timebox time.Duration
+}
+
+// summarizeTickMsg triggers a periodic plan summary
+type summarizeTickMsg struct{}
+
+// timeboxMsg ends a time-boxed discussion
+type timeboxMsg struct{}
+
+// NewPlanningModel creates a new planning model. The recent entries of a
+// project memory are shown when the discussion starts.
+func NewPlanningModel(sessionID, swarmDir string, opts PlanningOptions, projectMemory *memory.Memory) PlanningModel {
 	ta := textarea.New()
 	ta.Placeholder = "Type your message here..."
 	ta.Focus()
@@ -57,6 +82,16 @@ func NewPlanningModel(sessionID, swarmDir string) PlanningModel {
 	vp := viewport.New(80, 20)
 	vp.SetContent("")
 
+	var deadline time.Time
+	if opts.Timebox > 0 {
+		deadline = time.Now().Add(opts.Timebox)
+	}
+
+	recalled := ""
+	if projectMemory != nil {
+		recalled, _ = projectMemory.Recent(0)
+	}
+
 	return PlanningModel{
 		sessionID:   sessionID,
 		swarmDir:    swarmDir,
@@ -64,7 +99,12 @@ func NewPlanningModel(sessionID, swarmDir string) PlanningModel {
 		messages:    []Message{},
 		textarea:    ta,
 		viewport:    vp,
+		plans:       []planDraft{{name: planName(0)}},
 		workflowGen: NewWorkflowGenerator(),
+		summarizer:  NewPlanSummarizer(),
+		options:     opts,
+		deadline:    deadline,
+		recalled:    recalled,
 	}
 }
 
@@ -76,9 +116,32 @@ func (m PlanningModel) Init() tea.Cmd {
 		Time:    time.Now(),
 	}
 	m.messages = append(m.messages, welcome)
+	if m.recalled != "" {
+		m.messages = append(m.messages, Message{
+			Author:  "System",
+			Content: "Earlier runs in this repository remembered:\n\n" + m.recalled,
+			Time:    time.Now(),
+		})
+	}
 	m.updateViewport()
 
-	return textarea.Blink
+	cmds := []tea.Cmd{textarea.Blink, m.scheduleSummary()}
+	if m.options.Timebox > 0 {
+		cmds = append(cmds, tea.Tick(m.options.Timebox, func(time.Time) tea.Msg {
+			return timeboxMsg{}
+		}))
+	}
+	return tea.Batch(cmds...)
+}
+
+// scheduleSummary schedules the next periodic summary, if enabled
+func (m PlanningModel) scheduleSummary() tea.Cmd {
+	if m.options.SummarizeEvery <= 0 {
+		return nil
+	}
+	return tea.Tick(m.options.SummarizeEvery, func(time.Time) tea.Msg {
+		return summarizeTickMsg{}
+	})
 }
 
 func (m PlanningModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -118,14 +181,44 @@ func (m PlanningModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 
 		case "s", "S":
-			// Start orchestration
+			// Start orchestration with the selected plan
 			if m.mode == ModeReady {
+				if err := m.selectPlan(); err != nil {
+					return m, func() tea.Msg {
+						return ErrorMsg{Err: err}
+					}
+				}
 				return m, func() tea.Msg {
 					return StartOrchestrationMsg{}
 				}
 			}
 			return m, nil
 
+		case "ctrl+n":
+			// Start an alternative plan
+			if m.mode == ModeDiscussion {
+				m.newPlan()
+			}
+			return m, nil
+
+		case "ctrl+t":
+			// Switch between alternative plans
+			if m.mode == ModeDiscussion {
+				m.switchPlan()
+			}
+			return m, nil
+
+		case "left", "right":
+			// Select among the generated workflows
+			if m.mode == ModeReady && len(m.plans) > 1 {
+				step := 1
+				if msg.String() == "left" {
+					step = len(m.plans) - 1
+				}
+				m.current = (m.current + step) % len(m.plans)
+				return m, nil
+			}
+
 		case "q", "Q":
 			return m, tea.Quit
 
@@ -138,8 +231,7 @@ func (m PlanningModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.textarea.Reset()
 
 					// Add to plan
-					m.plan.WriteString(userMsg)
-					m.plan.WriteString("\n\n")
+					m.plan().notes = append(m.plan().notes, userMsg)
 
 					// Prompt for Claude's response
 					m.addSystemMessage("[CLAUDE A] Please respond to the user's message, helping them plan their workflow.")
@@ -164,9 +256,42 @@ func (m PlanningModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.updateViewport()
 		return m, nil
 
+	case summarizeTickMsg:
+		if m.mode != ModeDiscussion {
+			return m, nil
+		}
+		if plan := m.plan(); len(plan.notes) > plan.summarized {
+			if err := m.savePlan(); err != nil {
+				m.addSystemMessage(fmt.Sprintf("Failed to summarize plan: %v", err))
+			} else {
+				m.addSystemMessage(fmt.Sprintf("Plan summarized from %d messages into %s.", len(plan.notes), filepath.Base(m.planFile(plan.name))))
+			}
+		}
+		return m, m.scheduleSummary()
+
+	case timeboxMsg:
+		if m.mode == ModeDiscussion {
+			m.savePlan()
+			m.mode = ModeReviewPlan
+			m.addSystemMessage(fmt.Sprintf("Planning time box of %s is up. Plan saved! Press [G] to generate workflow, [E] to continue editing, [Q] to quit.", m.options.Timebox))
+		}
+		return m, nil
+
 	case WorkflowGeneratedMsg:
 		m.mode = ModeReady
-		m.addSystemMessage(fmt.Sprintf("Workflow generated successfully!\n\nWorkflow: %s\nTasks: %d\n\nPress [S] to start orchestration, [Q] to quit.", msg.Path, msg.TaskCount))
+		if len(msg.Alternatives) > 1 {
+			for i, generated := range msg.Alternatives {
+				m.plans[i].workflow = generated.YAML
+				m.plans[i].estimate = generated.Estimate
+			}
+			m.addSystemMessage(fmt.Sprintf("Generated %d alternative workflows, compared below.\n\nPress [←/→] to select one, [S] to start orchestration with it, [Q] to quit.", len(msg.Alternatives)))
+			return m, nil
+		}
+		estimateLine := ""
+		if len(msg.Alternatives) == 1 && msg.Alternatives[0].Estimate != "" {
+			estimateLine = fmt.Sprintf("Estimate: %s\n", msg.Alternatives[0].Estimate)
+		}
+		m.addSystemMessage(fmt.Sprintf("Workflow generated successfully!\n\nWorkflow: %s\nTasks: %d\n%s\nPress [S] to start orchestration, [Q] to quit.", msg.Path, msg.TaskCount, estimateLine))
 		return m, nil
 	}
 
@@ -196,21 +321,33 @@ func (m PlanningModel) View() string {
 	s.WriteString("\n")
 
 	// Session info
+	sessionInfo := fmt.Sprintf("Session: %s | Directory: %s", m.sessionID, m.swarmDir)
+	if !m.deadline.IsZero() && m.mode == ModeDiscussion {
+		sessionInfo += fmt.Sprintf(" | Time box ends %s", m.deadline.Format("15:04"))
+	}
+	if len(m.plans) > 1 {
+		sessionInfo += fmt.Sprintf(" | Plan %s of %d", m.plan().name, len(m.plans))
+	}
 	info := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("240")).
-		Render(fmt.Sprintf("Session: %s | Directory: %s", m.sessionID, m.swarmDir))
+		Render(sessionInfo)
 	s.WriteString(info)
 	s.WriteString("\n\n")
 
 	// Conversation viewport
-	viewportStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
+	viewportStyle := bordered(lipgloss.NewStyle(), m.a11y).
 		BorderForeground(lipgloss.Color("63")).
 		Padding(1, 2)
 
 	s.WriteString(viewportStyle.Render(m.viewport.View()))
 	s.WriteString("\n\n")
 
+	// Generated workflows side by side, when there are alternatives
+	if m.mode == ModeReady && len(m.plans) > 1 {
+		s.WriteString(m.renderComparison())
+		s.WriteString("\n")
+	}
+
 	// Input area (only in discussion mode)
 	if m.mode == ModeDiscussion {
 		inputLabel := lipgloss.NewStyle().
@@ -219,8 +356,7 @@ func (m PlanningModel) View() string {
 		s.WriteString(inputLabel)
 		s.WriteString("\n")
 
-		textareaStyle := lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
+		textareaStyle := bordered(lipgloss.NewStyle(), m.a11y).
 			BorderForeground(lipgloss.Color("205"))
 
 		s.WriteString(textareaStyle.Render(m.textarea.View()))
@@ -292,38 +428,65 @@ func (m *PlanningModel) updateViewport() {
 	m.viewport.GotoBottom()
 }
 
+// savePlan saves every plan, each to its own file
 func (m *PlanningModel) savePlan() error {
-	planFile := filepath.Join(m.swarmDir, "plan.md")
+	for i := range m.plans {
+		plan := &m.plans[i]
+		planContent := fmt.Sprintf("# Plan\n\n%s\n", m.planText(*plan))
 
-	planContent := fmt.Sprintf("# Plan\n\n%s\n", m.plan.String())
+		if err := os.WriteFile(m.planFile(plan.name), []byte(planContent), 0644); err != nil {
+			return fmt.Errorf("failed to save plan: %w", err)
+		}
 
-	if err := os.WriteFile(planFile, []byte(planContent), 0644); err != nil {
-		return fmt.Errorf("failed to save plan: %w", err)
+		plan.summarized = len(plan.notes)
 	}
 
 	return nil
 }
 
+// planText returns a plan: a summary of its discussion when summarizing,
+// otherwise the user's messages verbatim
+func (m PlanningModel) planText(plan planDraft) string {
+	if m.options.SummarizeEvery > 0 {
+		return m.summarizer.Summarize(plan.notes)
+	}
+	return strings.Join(plan.notes, "\n\n")
+}
+
+// generateWorkflow generates a workflow from every plan
 func (m *PlanningModel) generateWorkflow() tea.Cmd {
+	plans := append([]planDraft(nil), m.plans...)
 	return func() tea.Msg {
-		// Generate workflow from plan
-		workflow, err := m.workflowGen.GenerateFromPlan(m.plan.String())
-		if err != nil {
-			return ErrorMsg{Err: err}
-		}
+		// Past sessions sit next to this one
+		history, _ := estimate.LoadHistory(filepath.Dir(m.swarmDir))
+
+		var generated []GeneratedWorkflow
+		for _, plan := range plans {
+			// Generate workflow from plan
+			workflow, err := m.workflowGen.GenerateFromPlan(m.planText(plan))
+			if err != nil {
+				return ErrorMsg{Err: err}
+			}
 
-		// Save workflow
-		workflowFile := filepath.Join(m.swarmDir, "workflow.yaml")
-		if err := os.WriteFile(workflowFile, []byte(workflow), 0644); err != nil {
-			return ErrorMsg{Err: err}
-		}
+			// Save workflow
+			workflowFile := m.workflowFile(plan.name)
+			if err := os.WriteFile(workflowFile, []byte(workflow), 0644); err != nil {
+				return ErrorMsg{Err: err}
+			}
 
-		// Count tasks (simple count)
-		taskCount := strings.Count(workflow, "- id:")
+			generated = append(generated, GeneratedWorkflow{
+				Plan:      plan.name,
+				Path:      workflowFile,
+				YAML:      workflow,
+				TaskCount: strings.Count(workflow, "- id:"),
+				Estimate:  estimateWorkflow(workflow, history),
+			})
+		}
 
 		return WorkflowGeneratedMsg{
-			Path:      workflowFile,
-			TaskCount: taskCount,
+			Path:         generated[0].Path,
+			TaskCount:    generated[0].TaskCount,
+			Alternatives: generated,
 		}
 	}
 }
@@ -346,10 +509,13 @@ func (m PlanningModel) getModeString() string {
 func (m PlanningModel) getHelpText() string {
 	switch m.mode {
 	case ModeDiscussion:
-		return "Ctrl+D: Finish planning | Ctrl+C: Quit"
+		return "Ctrl+D: Finish planning | Ctrl+N: Alternative plan | Ctrl+T: Switch plan | Ctrl+C: Quit"
 	case ModeReviewPlan:
 		return "[G] Generate workflow | [E] Continue editing | [Q] Quit"
 	case ModeReady:
+		if len(m.plans) > 1 {
+			return "[←/→] Select plan | [S] Start orchestration | [Q] Quit"
+		}
 		return "[S] Start orchestration | [Q] Quit"
 	default:
 		return "[Q] Quit"
@@ -361,6 +527,8 @@ type StartOrchestrationMsg struct{}
 type WorkflowGeneratedMsg struct {
 	Path      string
 	TaskCount int
+	// Alternatives are the workflows of every plan, when there are several
+	Alternatives []GeneratedWorkflow
 }
 type ErrorMsg struct {
 	Err error
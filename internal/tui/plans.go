@@ -0,0 +1,203 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aristath/claude-swarm/internal/estimate"
+	"github.com/aristath/claude-swarm/internal/workflow"
+	"github.com/charmbracelet/lipgloss"
+	"gopkg.in/yaml.v3"
+)
+
+// planDraft is one of the alternative plans of a planning session
+type planDraft struct {
+	name       string
+	notes      []string // The user's messages, which the plan is made of
+	summarized int      // How many notes the saved plan covers
+	workflow   string   // Generated workflow YAML
+	estimate   string   // Cost and time estimate of the workflow
+}
+
+// GeneratedWorkflow is the workflow generated from one plan
+type GeneratedWorkflow struct {
+	Plan      string
+	Path      string
+	YAML      string
+	TaskCount int
+	Estimate  string
+}
+
+// planName names the i-th plan: a, b, c, ...
+func planName(i int) string {
+	if i < 26 {
+		return string(rune('a' + i))
+	}
+	return fmt.Sprintf("%d", i+1)
+}
+
+// plan returns the plan being discussed or selected
+func (m *PlanningModel) plan() *planDraft {
+	return &m.plans[m.current]
+}
+
+// newPlan starts an alternative plan, which the discussion continues in
+func (m *PlanningModel) newPlan() {
+	m.plans = append(m.plans, planDraft{name: planName(len(m.plans))})
+	m.current = len(m.plans) - 1
+	m.addSystemMessage(fmt.Sprintf("Started alternative plan %s. Ctrl+T switches between plans.", m.plan().name))
+}
+
+// switchPlan moves to the next plan
+func (m *PlanningModel) switchPlan() {
+	if len(m.plans) < 2 {
+		return
+	}
+	m.current = (m.current + 1) % len(m.plans)
+	m.addSystemMessage(fmt.Sprintf("Now on plan %s (%d messages).", m.plan().name, len(m.plan().notes)))
+}
+
+// planFile returns where a plan is saved. A single plan is plan.md;
+// alternatives are plan-a.md, plan-b.md, ...
+func (m PlanningModel) planFile(name string) string {
+	if len(m.plans) < 2 {
+		return filepath.Join(m.swarmDir, "plan.md")
+	}
+	return filepath.Join(m.swarmDir, fmt.Sprintf("plan-%s.md", name))
+}
+
+// workflowFile returns where a plan's workflow is saved, named like its
+// plan file
+func (m PlanningModel) workflowFile(name string) string {
+	if len(m.plans) < 2 {
+		return filepath.Join(m.swarmDir, "workflow.yaml")
+	}
+	return filepath.Join(m.swarmDir, fmt.Sprintf("workflow-%s.yaml", name))
+}
+
+// selectPlan makes the selected alternative the session's plan.md and
+// workflow.yaml, which orchestration runs
+func (m PlanningModel) selectPlan() error {
+	if len(m.plans) < 2 {
+		return nil
+	}
+	plan := m.plans[m.current]
+
+	content := fmt.Sprintf("# Plan\n\n%s\n", m.planText(plan))
+	if err := os.WriteFile(filepath.Join(m.swarmDir, "plan.md"), []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to save plan: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(m.swarmDir, "workflow.yaml"), []byte(plan.workflow), 0644); err != nil {
+		return fmt.Errorf("failed to save workflow: %w", err)
+	}
+	return nil
+}
+
+// renderComparison renders the generated workflows side by side, with
+// the selected one highlighted
+func (m PlanningModel) renderComparison() string {
+	width := 30
+	if m.width > 0 {
+		width = max(20, (m.width-4)/len(m.plans)-2)
+	}
+
+	columns := make([]string, 0, len(m.plans))
+	for i, plan := range m.plans {
+		style := bordered(lipgloss.NewStyle(), m.a11y).
+			BorderForeground(lipgloss.Color("240")).
+			Padding(0, 1).
+			Width(width)
+		summary := summarizeWorkflow(plan)
+		if i == m.current {
+			style = style.BorderForeground(lipgloss.Color("205"))
+			if m.a11y {
+				summary = "[selected] " + summary
+			}
+		}
+		columns = append(columns, style.Render(summary))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, columns...)
+}
+
+// summarizeWorkflow lists a plan's generated tasks and their dependencies
+func summarizeWorkflow(plan planDraft) string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "Plan %s\n", plan.name)
+
+	var wf workflow.Workflow
+	if err := yaml.Unmarshal([]byte(plan.workflow), &wf); err != nil {
+		fmt.Fprintf(&s, "\nInvalid workflow: %v\n", err)
+		return s.String()
+	}
+
+	fmt.Fprintf(&s, "%d tasks, %d parallel at most\n", len(wf.Tasks), maxWidth(wf.Tasks))
+	if plan.estimate != "" {
+		fmt.Fprintf(&s, "%s\n", plan.estimate)
+	}
+	if err := workflow.NewParser().Validate(&wf); err != nil {
+		fmt.Fprintf(&s, "⚠ %v\n", err)
+	}
+	s.WriteString("\n")
+	for _, task := range wf.Tasks {
+		fmt.Fprintf(&s, "• %s", task.ID)
+		if len(task.DependsOn) > 0 {
+			fmt.Fprintf(&s, " ← %s", strings.Join(task.DependsOn, ", "))
+		}
+		s.WriteString("\n")
+		if task.Description != "" {
+			fmt.Fprintf(&s, "  %s\n", truncate(task.Description, 60))
+		}
+	}
+	return s.String()
+}
+
+// estimateWorkflow returns a one-line cost and time estimate of generated
+// workflow YAML, or "" when it can't be read
+func estimateWorkflow(data string, history estimate.History) string {
+	wf, err := workflow.NewParser().Parse([]byte(data))
+	if err != nil {
+		// Estimate workflows that don't validate yet as they are
+		wf = &workflow.Workflow{}
+		if err := yaml.Unmarshal([]byte(data), wf); err != nil {
+			return ""
+		}
+	}
+	return estimate.NewEstimator(history).Estimate(wf).Summary()
+}
+
+// maxWidth returns the most tasks that can run at once, by dependency depth
+func maxWidth(tasks []workflow.Task) int {
+	depth := make(map[string]int, len(tasks))
+	var depthOf func(id string, seen map[string]bool) int
+	depthOf = func(id string, seen map[string]bool) int {
+		if d, ok := depth[id]; ok {
+			return d
+		}
+		if seen[id] {
+			return 0
+		}
+		seen[id] = true
+		d := 0
+		for _, task := range tasks {
+			if task.ID != id {
+				continue
+			}
+			for _, dep := range task.DependsOn {
+				d = max(d, depthOf(dep, seen)+1)
+			}
+		}
+		depth[id] = d
+		return d
+	}
+
+	counts := map[int]int{}
+	widest := 0
+	for _, task := range tasks {
+		d := depthOf(task.ID, map[string]bool{})
+		counts[d]++
+		widest = max(widest, counts[d])
+	}
+	return widest
+}
@@ -0,0 +1,32 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// agentProgressBarWidth fits an agent card's progress bar in the sidebar
+const agentProgressBarWidth = 10
+
+// renderAgentProgress renders the progress an agent reported with
+// swarm-agent progress for its card: a bar for its percentage and its
+// status, with how long ago it was reported
+func (m *OrchestrationModel) renderAgentProgress(agent *workflow.AgentState) string {
+	var lines []string
+	if percent := agent.ProgressPercent; percent != nil {
+		if m.a11y {
+			lines = append(lines, fmt.Sprintf("Progress: %d%%", *percent))
+		} else {
+			filled := *percent * agentProgressBarWidth / 100
+			lines = append(lines, fmt.Sprintf("Progress: [%s%s] %d%%",
+				strings.Repeat("█", filled), strings.Repeat("░", agentProgressBarWidth-filled), *percent))
+		}
+	}
+	if agent.Progress != "" {
+		lines = append(lines, fmt.Sprintf("%s (%s ago)", truncate(agent.Progress, 40), time.Since(agent.ProgressAt).Round(time.Second)))
+	}
+	return "  " + strings.Join(lines, "\n  ")
+}
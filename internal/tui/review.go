@@ -0,0 +1,150 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aristath/claude-swarm/internal/orchestrator"
+	"github.com/aristath/claude-swarm/internal/state"
+	"github.com/aristath/claude-swarm/internal/workflow"
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// newAnswerInput creates the input drafted answers are edited in
+func newAnswerInput() textarea.Model {
+	input := textarea.New()
+	input.Placeholder = "Answer for the agent..."
+	input.ShowLineNumbers = false
+	input.SetHeight(8)
+	input.CharLimit = 0
+	return input
+}
+
+// selectedAnswer returns the drafted answer selected in the review view
+func (m *OrchestrationModel) selectedAnswer() (state.PendingAnswer, bool) {
+	pending := m.state.PendingAnswers()
+	if len(pending) == 0 {
+		return state.PendingAnswer{}, false
+	}
+	if m.selectedDraft >= len(pending) {
+		m.selectedDraft = len(pending) - 1
+	}
+	return pending[m.selectedDraft], true
+}
+
+// updateReview handles keys in the review view: Up and Down select a
+// draft, Enter approves it and E edits it. It reports whether the key was
+// handled.
+func (m *OrchestrationModel) updateReview(msg tea.KeyMsg) (bool, tea.Cmd) {
+	switch msg.String() {
+	case "up":
+		if m.selectedDraft > 0 {
+			m.selectedDraft--
+		}
+		return true, nil
+
+	case "down":
+		if m.selectedDraft < len(m.state.PendingAnswers())-1 {
+			m.selectedDraft++
+		}
+		return true, nil
+
+	case "enter":
+		if pending, ok := m.selectedAnswer(); ok {
+			m.sendAnswer(pending, "")
+		}
+		return true, nil
+
+	case "e", "E":
+		pending, ok := m.selectedAnswer()
+		if !ok {
+			return true, nil
+		}
+		m.editing = true
+		m.answerInput.SetWidth(m.mainViewport.Width)
+		m.answerInput.SetValue(pending.Question.Draft)
+		return true, m.answerInput.Focus()
+	}
+	return false, nil
+}
+
+// updateEditing handles keys while an answer is edited: Ctrl+S sends it,
+// Esc discards the edit and everything else goes to the input
+func (m *OrchestrationModel) updateEditing(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.editing = false
+		m.answerInput.Blur()
+		return nil
+
+	case "ctrl+s":
+		m.editing = false
+		m.answerInput.Blur()
+		answer := strings.TrimSpace(m.answerInput.Value())
+		if pending, ok := m.selectedAnswer(); ok && answer != "" {
+			if answer == strings.TrimSpace(pending.Question.Draft) {
+				answer = ""
+			}
+			m.sendAnswer(pending, answer)
+		}
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.answerInput, cmd = m.answerInput.Update(msg)
+	return cmd
+}
+
+// sendAnswer asks the orchestrator to answer a drafted question; an empty
+// answer approves the draft
+func (m *OrchestrationModel) sendAnswer(pending state.PendingAnswer, answer string) {
+	orchestrator.SendControlRequest(m.swarmDir, workflow.ControlRequest{
+		Action:   workflow.ControlAnswer,
+		TaskID:   pending.TaskID,
+		Question: pending.Question.ID,
+		Answer:   answer,
+	})
+}
+
+// renderReview renders the drafted answers awaiting review, or the one
+// being edited
+func (m *OrchestrationModel) renderReview() string {
+	var content strings.Builder
+
+	pending := m.state.PendingAnswers()
+	content.WriteString(lipgloss.NewStyle().Bold(true).Render(fmt.Sprintf("Answers awaiting review (%d)", len(pending))))
+	content.WriteString("\n\n")
+
+	if len(pending) == 0 {
+		content.WriteString(lipgloss.NewStyle().
+			Foreground(lipgloss.Color("240")).
+			Italic(true).
+			Render("No drafted answers"))
+		return content.String()
+	}
+
+	selected, _ := m.selectedAnswer()
+	if m.editing {
+		content.WriteString(fmt.Sprintf("%s asked (Q%d): %s\n\n", selected.TaskID, selected.Question.ID, selected.Question.Text))
+		content.WriteString(m.answerInput.View())
+		return content.String()
+	}
+
+	questionStyle := lipgloss.NewStyle().Bold(true)
+	draftStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240")).PaddingLeft(4)
+	for i, answer := range pending {
+		marker := m.marker(i == m.selectedDraft)
+		if i == m.selectedDraft {
+			questionStyle = questionStyle.Foreground(lipgloss.Color("205"))
+		} else {
+			questionStyle = questionStyle.UnsetForeground()
+		}
+		content.WriteString(questionStyle.Render(fmt.Sprintf("%s%s Q%d: %s", marker, answer.TaskID, answer.Question.ID, answer.Question.Text)))
+		content.WriteString("\n")
+		content.WriteString(draftStyle.Render(answer.Question.Draft))
+		content.WriteString("\n\n")
+	}
+	return content.String()
+}
@@ -0,0 +1,109 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aristath/claude-swarm/internal/search"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// searchLimit caps the matches listed in the TUI
+const searchLimit = 200
+
+// newSearchInput creates the input the search query is typed in
+func newSearchInput() textinput.Model {
+	input := textinput.New()
+	input.Prompt = "/"
+	input.Placeholder = "search outputs, answers and transcripts"
+	input.CharLimit = 200
+	return input
+}
+
+// startSearch focuses the search input
+func (m *OrchestrationModel) startSearch() tea.Cmd {
+	m.searching = true
+	m.searchInput.SetValue("")
+	return m.searchInput.Focus()
+}
+
+// updateSearch handles keys while the query is typed: Enter searches, Esc
+// cancels and everything else goes to the input
+func (m *OrchestrationModel) updateSearch(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.searching = false
+		m.searchInput.Blur()
+		return nil
+
+	case "enter":
+		m.searching = false
+		m.searchInput.Blur()
+		m.runSearch(m.searchInput.Value())
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.searchInput, cmd = m.searchInput.Update(msg)
+	return cmd
+}
+
+// runSearch searches the session and shows the matches in the main pane
+func (m *OrchestrationModel) runSearch(query string) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return
+	}
+	m.searchQuery = query
+	m.searchMatches, m.searchErr = search.Session(m.swarmDir, m.state, query, search.Options{Limit: searchLimit})
+	m.showSearch = true
+	m.showProposals = false
+	m.showStats = false
+	m.showLog = false
+	m.mainViewport.GotoTop()
+}
+
+// renderSearch renders the matches of the last search, grouped by task
+func (m *OrchestrationModel) renderSearch() string {
+	var content strings.Builder
+
+	content.WriteString(lipgloss.NewStyle().Bold(true).Render(fmt.Sprintf("Search: %s", m.searchQuery)))
+	content.WriteString("\n\n")
+
+	switch {
+	case m.searchErr != nil:
+		content.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("red")).Render(m.searchErr.Error()))
+		return content.String()
+	case len(m.searchMatches) == 0:
+		content.WriteString("No matches")
+		return content.String()
+	}
+
+	taskStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("cyan"))
+	sourceStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	task := ""
+	for _, match := range m.searchMatches {
+		if match.Task != task {
+			if task != "" {
+				content.WriteString("\n")
+			}
+			task = match.Task
+			content.WriteString(taskStyle.Render(task))
+			content.WriteString("\n")
+		}
+		content.WriteString("  ")
+		content.WriteString(sourceStyle.Render(fmt.Sprintf("%s:%d:", match.Source, match.Line)))
+		content.WriteString(" ")
+		content.WriteString(match.Text)
+		content.WriteString("\n")
+	}
+
+	summary := fmt.Sprintf("\n%d matches in %d tasks", len(m.searchMatches), len(search.Tasks(m.searchMatches)))
+	if len(m.searchMatches) >= searchLimit {
+		summary += fmt.Sprintf(" (first %d shown)", searchLimit)
+	}
+	content.WriteString(sourceStyle.Render(summary))
+	return content.String()
+}
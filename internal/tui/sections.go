@@ -0,0 +1,141 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// unlabeledSection holds the tasks without labels when others have them
+const unlabeledSection = "Unlabeled"
+
+// sectionBarWidth is the width of a section's progress bar
+const sectionBarWidth = 12
+
+// taskSection is the tasks sharing a first label, shown as a collapsible
+// section of the task list
+type taskSection struct {
+	label string
+	tasks []workflow.Task
+}
+
+// taskSections groups tasks by their first label, in order of first
+// appearance, with unlabeled tasks last. It returns nil when no task has
+// a label, and the task list stays flat.
+func taskSections(tasks []workflow.Task) []taskSection {
+	var sections []taskSection
+	index := map[string]int{}
+	var unlabeled []workflow.Task
+
+	for _, task := range tasks {
+		if len(task.Labels) == 0 {
+			unlabeled = append(unlabeled, task)
+			continue
+		}
+		label := task.Labels[0]
+		i, ok := index[label]
+		if !ok {
+			i = len(sections)
+			index[label] = i
+			sections = append(sections, taskSection{label: label})
+		}
+		sections[i].tasks = append(sections[i].tasks, task)
+	}
+
+	if len(sections) == 0 {
+		return nil
+	}
+	if len(unlabeled) > 0 {
+		sections = append(sections, taskSection{label: unlabeledSection, tasks: unlabeled})
+	}
+	return sections
+}
+
+// selectNextSection moves the selection to the next section, wrapping
+func (m *OrchestrationModel) selectNextSection() {
+	sections := taskSections(m.state.GetTasks())
+	if len(sections) == 0 {
+		return
+	}
+	m.selectedSection = (m.selectedSection + 1) % len(sections)
+}
+
+// toggleSection collapses or expands the selected section
+func (m *OrchestrationModel) toggleSection() {
+	sections := taskSections(m.state.GetTasks())
+	if len(sections) == 0 {
+		return
+	}
+	label := sections[m.selectedSection%len(sections)].label
+	if m.collapsed == nil {
+		m.collapsed = map[string]bool{}
+	}
+	m.collapsed[label] = !m.collapsed[label]
+}
+
+// renderSections renders the task list as sections with a progress bar
+// each; collapsed sections show their header only
+func (m *OrchestrationModel) renderSections(sections []taskSection) string {
+	var list strings.Builder
+
+	for i, section := range sections {
+		completed, failed := 0, 0
+		for _, task := range section.tasks {
+			if agent := m.state.GetAgent(task.ID); agent != nil {
+				switch agent.Status {
+				case workflow.TaskStatusCompleted:
+					completed++
+				case workflow.TaskStatusFailed, workflow.TaskStatusQuarantined:
+					failed++
+				}
+			}
+		}
+
+		fold := "▾"
+		if m.collapsed[section.label] {
+			fold = "▸"
+		}
+		filled := completed * sectionBarWidth / len(section.tasks)
+		bar := strings.Repeat("█", filled) + strings.Repeat("░", sectionBarWidth-filled)
+		header := fmt.Sprintf("%s %s [%s] %d/%d", fold, section.label, bar, completed, len(section.tasks))
+		if failed > 0 {
+			header += fmt.Sprintf(", %d failed", failed)
+		}
+		if m.a11y {
+			fold = "expanded"
+			if m.collapsed[section.label] {
+				fold = "collapsed"
+			}
+			header = fmt.Sprintf("%s%s, %s: %d of %d done", m.marker(i == m.selectedSection%len(sections)), section.label, fold, completed, len(section.tasks))
+			if failed > 0 {
+				header += fmt.Sprintf(", %d failed", failed)
+			}
+		}
+
+		style := lipgloss.NewStyle().Bold(true)
+		switch {
+		case failed > 0:
+			style = style.Foreground(lipgloss.Color("red"))
+		case completed == len(section.tasks):
+			style = style.Foreground(lipgloss.Color("green"))
+		}
+		if i == m.selectedSection%len(sections) {
+			style = style.Underline(true)
+		}
+		list.WriteString(style.Render(header))
+		list.WriteString("\n")
+
+		if m.collapsed[section.label] {
+			continue
+		}
+		for _, task := range section.tasks {
+			list.WriteString("  ")
+			list.WriteString(m.renderTaskLine(task))
+			list.WriteString("\n")
+		}
+	}
+
+	return list.String()
+}
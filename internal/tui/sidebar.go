@@ -0,0 +1,125 @@
+package tui
+
+import (
+	"sort"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/workflow"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// agentOrder is how the sidebar orders active agents, below the pinned
+// ones
+type agentOrder int
+
+const (
+	orderByStart     agentOrder = iota // Longest running first
+	orderByStatus                      // Agents needing attention first
+	orderByQuestions                   // Most questions first
+)
+
+func (o agentOrder) String() string {
+	switch o {
+	case orderByStatus:
+		return "status"
+	case orderByQuestions:
+		return "questions"
+	}
+	return "start time"
+}
+
+// updateSidebar handles keys while the agent sidebar has focus: O cycles
+// the sort order, N selects the next agent and T pins or unpins it. It
+// reports whether the key was handled.
+func (m *OrchestrationModel) updateSidebar(msg tea.KeyMsg) bool {
+	switch msg.String() {
+	case "o", "O":
+		m.agentOrder = (m.agentOrder + 1) % (orderByQuestions + 1)
+		return true
+
+	case "n", "N":
+		m.selectNextAgent()
+		return true
+
+	case "t", "T":
+		if m.state.GetAgent(m.selectedAgent) == nil {
+			m.selectNextAgent()
+		}
+		if m.selectedAgent != "" {
+			if m.pinned == nil {
+				m.pinned = make(map[string]bool)
+			}
+			m.pinned[m.selectedAgent] = !m.pinned[m.selectedAgent]
+		}
+		return true
+	}
+	return false
+}
+
+// selectNextAgent moves the selection to the next agent in the sidebar,
+// wrapping
+func (m *OrchestrationModel) selectNextAgent() {
+	agents := m.sidebarAgents()
+	if len(agents) == 0 {
+		m.selectedAgent = ""
+		return
+	}
+	next := 0
+	for i, agent := range agents {
+		if agent.TaskID == m.selectedAgent {
+			next = (i + 1) % len(agents)
+		}
+	}
+	m.selectedAgent = agents[next].TaskID
+}
+
+// sidebarAgents returns the active agents in the sidebar's order: pinned
+// agents first, each group sorted by the chosen order, then by task
+func (m *OrchestrationModel) sidebarAgents() []*workflow.AgentState {
+	agents := m.state.GetActiveAgents()
+	now := time.Now()
+
+	sort.Slice(agents, func(i, j int) bool {
+		a, b := agents[i], agents[j]
+		if m.pinned[a.TaskID] != m.pinned[b.TaskID] {
+			return m.pinned[a.TaskID]
+		}
+		switch m.agentOrder {
+		case orderByStatus:
+			if ra, rb := m.attention(a, now), m.attention(b, now); ra != rb {
+				return ra < rb
+			}
+		case orderByQuestions:
+			if len(a.Questions) != len(b.Questions) {
+				return len(a.Questions) > len(b.Questions)
+			}
+		default:
+			if !a.StartedAt.Equal(b.StartedAt) {
+				return a.StartedAt.Before(b.StartedAt)
+			}
+		}
+		return a.TaskID < b.TaskID
+	})
+	return agents
+}
+
+// attention ranks a running agent by how urgently it needs an operator:
+// stale, paused by its quotas, waiting for an answer, overdue, and then
+// the rest
+func (m *OrchestrationModel) attention(agent *workflow.AgentState, now time.Time) int {
+	switch {
+	case agent.Stale:
+		return 0
+	case agent.QuotaPaused:
+		return 1
+	}
+	for _, question := range agent.AllQuestions() {
+		if question.Answer == "" {
+			return 2
+		}
+	}
+	if task := m.state.GetTask(agent.TaskID); task != nil && task.Overdue(now.Sub(agent.StartedAt)) {
+		return 3
+	}
+	return 4
+}
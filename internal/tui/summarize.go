@@ -0,0 +1,150 @@
+package tui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// PlanSummarizer condenses a planning discussion into a structured plan,
+// so plan.md stays coherent however long the discussion runs
+type PlanSummarizer struct{}
+
+// NewPlanSummarizer creates a new plan summarizer
+func NewPlanSummarizer() *PlanSummarizer {
+	return &PlanSummarizer{}
+}
+
+// Plan sections, in the order they are written. None starts with "task",
+// which the workflow generator would read as a task header.
+const (
+	sectionGoals       = "Goals and Context"
+	sectionDecisions   = "Decisions"
+	sectionConstraints = "Constraints"
+	sectionQuestions   = "Open Questions"
+	sectionWork        = "Work Breakdown"
+)
+
+var (
+	// summaryTaskRe matches the task headers the workflow generator reads,
+	// capturing the task number or name
+	summaryTaskRe = regexp.MustCompile(`(?i)^[\s\-\#]*(?:task\s*(\d+)|(\d+)\.|task\s*:?\s*(.+))`)
+	decisionRe    = regexp.MustCompile(`(?i)^(?:let's|lets|we'll|we will|we should go with|go with|use|decided|decision:|agreed)\b`)
+	constraintRe  = regexp.MustCompile(`(?i)\b(?:must|must not|never|don't|do not|avoid|only|required?|without)\b`)
+)
+
+// summaryTask is a task from the discussion; restating a task replaces it
+type summaryTask struct {
+	key   string
+	lines []string
+}
+
+// Summarize condenses the user's planning messages into plan sections.
+// Tasks keep their headers and details verbatim so workflow generation
+// finds them; a task restated later replaces the earlier version. Other
+// lines are sorted into goals, decisions, constraints and open questions,
+// without repeats.
+func (s *PlanSummarizer) Summarize(messages []string) string {
+	sections := map[string][]string{}
+	seen := map[string]bool{}
+	var tasks []*summaryTask
+	taskIndex := map[string]*summaryTask{}
+
+	add := func(section, line string) {
+		key := strings.ToLower(line)
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		sections[section] = append(sections[section], line)
+	}
+
+	for _, message := range messages {
+		var current *summaryTask
+		for _, line := range strings.Split(message, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				current = nil
+				continue
+			}
+
+			if key := s.taskKey(line); key != "" {
+				task, exists := taskIndex[key]
+				if !exists {
+					task = &summaryTask{key: key}
+					taskIndex[key] = task
+					tasks = append(tasks, task)
+				}
+				task.lines = []string{line}
+				current = task
+				continue
+			}
+
+			// Details directly under a task header belong to the task
+			if current != nil {
+				current.lines = append(current.lines, line)
+				continue
+			}
+
+			add(s.classify(line), strings.TrimLeft(line, "-* "))
+		}
+	}
+
+	var plan strings.Builder
+	for _, section := range []string{sectionGoals, sectionDecisions, sectionConstraints, sectionQuestions} {
+		writeSection(&plan, section, sections[section])
+	}
+
+	// Tasks come last, since the workflow generator reads everything after
+	// a task header as part of its prompt
+	if len(tasks) > 0 {
+		fmt.Fprintf(&plan, "## %s\n\n", sectionWork)
+		for _, task := range tasks {
+			plan.WriteString(strings.Join(task.lines, "\n"))
+			plan.WriteString("\n\n")
+		}
+	}
+
+	return strings.TrimSpace(plan.String())
+}
+
+// taskKey identifies the task a line starts, or returns "" for other lines
+func (s *PlanSummarizer) taskKey(line string) string {
+	matches := summaryTaskRe.FindStringSubmatch(line)
+	if matches == nil {
+		return ""
+	}
+	for _, key := range matches[1:] {
+		if key != "" {
+			return strings.ToLower(strings.TrimSpace(key))
+		}
+	}
+	return ""
+}
+
+// classify picks the section for a line outside any task
+func (s *PlanSummarizer) classify(line string) string {
+	text := strings.TrimLeft(line, "-* ")
+	switch {
+	case strings.HasSuffix(text, "?"):
+		return sectionQuestions
+	case decisionRe.MatchString(text):
+		return sectionDecisions
+	case constraintRe.MatchString(text):
+		return sectionConstraints
+	default:
+		return sectionGoals
+	}
+}
+
+// writeSection writes a section as a bulleted list, if it has lines
+func writeSection(plan *strings.Builder, title string, lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+	fmt.Fprintf(plan, "## %s\n\n", title)
+	for _, line := range lines {
+		fmt.Fprintf(plan, "- %s\n", line)
+	}
+	plan.WriteString("\n")
+}
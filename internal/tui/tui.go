@@ -1,10 +1,15 @@
 package tui
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
 
+	"github.com/aristath/claude-swarm/internal/agenttype"
+	"github.com/aristath/claude-swarm/internal/memory"
 	"github.com/aristath/claude-swarm/internal/orchestrator"
 	"github.com/aristath/claude-swarm/internal/server"
 	"github.com/aristath/claude-swarm/internal/state"
@@ -29,17 +34,54 @@ type MainModel struct {
 	orchestration   OrchestrationModel
 	orchestratorSvc *orchestrator.Orchestrator
 	apiServer       *server.Server
+	cancel          context.CancelFunc
 	ready           bool
+	options         Options
+}
+
+// Options configures the TUI application
+type Options struct {
+	// Profiling serves net/http/pprof on the API server
+	Profiling bool
+	// CORSOrigins are the origins whose pages may call the API server
+	CORSOrigins []string
+	// Embed serves the API server's status endpoints without the token,
+	// for dashboards and portals
+	Embed bool
+	// Planning configures the planning phase
+	Planning PlanningOptions
+	// AgentCommand spawns each agent as a process from this command
+	// template; empty prints spawn prompts instead
+	AgentCommand string
+	// SummaryCommand summarizes long dependency outputs; empty excerpts
+	// them instead
+	SummaryCommand string
+	// Memory is the project memory planning and agents start from and the
+	// run is remembered in; nil for none
+	Memory *memory.Memory
+	// AgentTypes defines the prompt, tools and model of agent types; nil
+	// for none
+	AgentTypes *agenttype.Library
+	// ReviewAnswers queues the answers the orchestrator drafts for review
+	// in the orchestration view
+	ReviewAnswers bool
+	// A11y renders for screen readers: plain text instead of borders,
+	// progress bars and symbols, and new events announced line by line
+	A11y bool
 }
 
 // NewMainModel creates a new main TUI model
-func NewMainModel(sessionID, swarmDir string) MainModel {
+func NewMainModel(sessionID, swarmDir string, opts Options) MainModel {
+	planning := NewPlanningModel(sessionID, swarmDir, opts.Planning, opts.Memory)
+	planning.a11y = opts.A11y
+
 	return MainModel{
 		mode:          ModePlanning,
 		sessionID:     sessionID,
 		swarmDir:      swarmDir,
-		planningModel: NewPlanningModel(sessionID, swarmDir),
+		planningModel: planning,
 		ready:         false,
+		options:       opts,
 	}
 }
 
@@ -51,11 +93,8 @@ func (m MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		if msg.String() == "ctrl+c" {
-			if m.orchestratorSvc != nil {
-				m.orchestratorSvc.Stop()
-			}
-			if m.apiServer != nil {
-				m.apiServer.Stop()
+			if m.cancel != nil {
+				m.cancel()
 			}
 			return m, tea.Quit
 		}
@@ -68,6 +107,7 @@ func (m MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Orchestrator is ready, show orchestration UI
 		m.mode = ModeOrchestration
 		m.orchestration = NewOrchestrationModel(m.sessionID, m.swarmDir, msg.State)
+		m.orchestration.a11y = m.options.A11y
 		return m, m.orchestration.Init()
 
 	case OrchestratorEventMsg:
@@ -116,6 +156,14 @@ func (m MainModel) startOrchestration() (tea.Model, tea.Cmd) {
 			return ErrorMsg{Err: fmt.Errorf("failed to load workflow: %w", err)}
 		}
 	}
+	if m.options.ReviewAnswers {
+		wf.ReviewAnswers = true
+	}
+	if err := wf.AddAnswers(workflow.UserAnswersFile()); err != nil {
+		return m, func() tea.Msg {
+			return ErrorMsg{Err: err}
+		}
+	}
 
 	// Load plan
 	planPath := filepath.Join(m.swarmDir, "plan.md")
@@ -129,8 +177,30 @@ func (m MainModel) startOrchestration() (tea.Model, tea.Cmd) {
 	// Create state
 	swarmState := state.NewSwarmState(m.sessionID, string(planData), wf)
 
+	// Agents authenticate to the API server with a per-session token
+	// handed to them through env.sh
+	token, err := generateToken()
+	if err != nil {
+		return m, func() tea.Msg {
+			return ErrorMsg{Err: fmt.Errorf("failed to generate API token: %w", err)}
+		}
+	}
+
 	// Create orchestrator
-	orch, err := orchestrator.NewOrchestrator(m.swarmDir, swarmState)
+	opts := []orchestrator.Option{
+		orchestrator.WithAPI(orchestrator.DefaultAPIURL, token),
+		orchestrator.WithSummaryCommand(m.options.SummaryCommand),
+	}
+	if m.options.Memory != nil {
+		opts = append(opts, orchestrator.WithProjectMemory(m.options.Memory))
+	}
+	if m.options.AgentTypes != nil {
+		opts = append(opts, orchestrator.WithAgentTypes(m.options.AgentTypes))
+	}
+	if m.options.AgentCommand != "" {
+		opts = append(opts, orchestrator.WithSpawner(orchestrator.NewProcessSpawner(m.options.AgentCommand)))
+	}
+	orch, err := orchestrator.NewOrchestrator(m.swarmDir, swarmState, opts...)
 	if err != nil {
 		return m, func() tea.Msg {
 			return ErrorMsg{Err: fmt.Errorf("failed to create orchestrator: %w", err)}
@@ -139,20 +209,36 @@ func (m MainModel) startOrchestration() (tea.Model, tea.Cmd) {
 
 	m.orchestratorSvc = orch
 
-	// Create API server on port 8080
+	// A single cancel stops the orchestrator, the API server and any
+	// in-flight operations
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+
+	// Create API server on port 8080, with its gRPC service on 8081
 	apiServer := server.NewServer(swarmState, m.swarmDir, 8080)
+	apiServer.EnableGRPC(8081)
+	apiServer.SetToken(token)
+	apiServer.SetLifecycleHooks(orch)
+	apiServer.SetVerifier(orch)
+	if m.options.Profiling {
+		apiServer.EnableProfiling()
+	}
+	apiServer.SetCORSOrigins(m.options.CORSOrigins)
+	if m.options.Embed {
+		apiServer.EnableEmbedding()
+	}
 	m.apiServer = apiServer
 
 	// Start API server in background
 	go func() {
-		if err := apiServer.Start(); err != nil {
+		if err := apiServer.Start(ctx); err != nil {
 			fmt.Printf("API server error: %v\n", err)
 		}
 	}()
 
 	// Start orchestrator in background
 	go func() {
-		if err := orch.Run(); err != nil {
+		if err := orch.Run(ctx); err != nil && err != context.Canceled {
 			fmt.Printf("Orchestrator error: %v\n", err)
 		}
 	}()
@@ -163,20 +249,31 @@ func (m MainModel) startOrchestration() (tea.Model, tea.Cmd) {
 	}
 }
 
+// generateToken returns a random hex token
+func generateToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
 // Custom messages
 type OrchestratorReadyMsg struct {
 	State *state.SwarmState
 }
 
 // Run starts the TUI application
-func Run(sessionID, swarmDir string) error {
-	model := NewMainModel(sessionID, swarmDir)
-
-	p := tea.NewProgram(
-		model,
-		tea.WithAltScreen(),
-		tea.WithMouseCellMotion(),
-	)
+func Run(sessionID, swarmDir string, opts Options) error {
+	model := NewMainModel(sessionID, swarmDir, opts)
+
+	// Screen readers follow the terminal's scrollback, where events are
+	// announced, so accessibility mode keeps to the main screen
+	programOpts := []tea.ProgramOption{tea.WithAltScreen(), tea.WithMouseCellMotion()}
+	if opts.A11y {
+		programOpts = nil
+	}
+	p := tea.NewProgram(model, programOpts...)
 
 	_, err := p.Run()
 	return err
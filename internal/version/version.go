@@ -0,0 +1,102 @@
+// Package version identifies the build and the agent protocol it speaks.
+// The orchestrator publishes its version in the session directory so that
+// swarm-agent binaries from another build fail loudly instead of
+// misbehaving.
+package version
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Version is the build version, set at build time with
+// -ldflags "-X github.com/aristath/claude-swarm/internal/version.Version=v1.2.3"
+var Version = "dev"
+
+// ProtocolVersion is the version of the file and HTTP protocol between the
+// orchestrator and agents. Bump it on incompatible changes.
+const ProtocolVersion = 1
+
+// MinProtocolVersion is the oldest protocol still accepted from peers.
+// Messages without a version predate versioning and are treated as
+// protocol 1.
+const MinProtocolVersion = 1
+
+// Header carries the protocol version of HTTP requests and responses
+const Header = "X-Swarm-Protocol-Version"
+
+// FileName is the name of the version file in the session directory
+const FileName = "version.json"
+
+// Info describes a build
+type Info struct {
+	Version         string `json:"version"`
+	ProtocolVersion int    `json:"protocol_version"`
+}
+
+// Current returns the version of the running build
+func Current() Info {
+	return Info{
+		Version:         Version,
+		ProtocolVersion: ProtocolVersion,
+	}
+}
+
+// String formats the version for humans
+func (i Info) String() string {
+	return fmt.Sprintf("%s (protocol %d)", i.Version, i.ProtocolVersion)
+}
+
+// CheckCompatible returns an error if a peer speaks a protocol this build
+// does not accept
+func (i Info) CheckCompatible(peer Info) error {
+	if CheckProtocol(peer.ProtocolVersion) != nil {
+		return fmt.Errorf("protocol mismatch: this binary is %s but the orchestrator is %s; run 'swarm install-agent' to install a matching swarm-agent", i, peer)
+	}
+	return nil
+}
+
+// CheckProtocol returns an error if a peer's protocol version cannot be
+// served. Zero means the peer predates versioning and speaks protocol 1.
+func CheckProtocol(peer int) error {
+	if peer == 0 {
+		peer = 1
+	}
+	if peer < MinProtocolVersion || peer > ProtocolVersion {
+		return fmt.Errorf("unsupported protocol version %d: this side speaks protocol %d (accepts %d-%d); run 'swarm install-agent' to install a matching swarm-agent",
+			peer, ProtocolVersion, MinProtocolVersion, ProtocolVersion)
+	}
+	return nil
+}
+
+// Write publishes the current version in a session directory
+func Write(swarmDir string) error {
+	data, err := json.MarshalIndent(Current(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal version: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(swarmDir, FileName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write version file: %w", err)
+	}
+
+	return nil
+}
+
+// Read loads the version published in a session directory
+func Read(swarmDir string) (Info, error) {
+	var info Info
+
+	data, err := os.ReadFile(filepath.Join(swarmDir, FileName))
+	if err != nil {
+		return info, fmt.Errorf("failed to read version file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &info); err != nil {
+		return info, fmt.Errorf("failed to parse version file: %w", err)
+	}
+
+	return info, nil
+}
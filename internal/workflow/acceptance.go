@@ -0,0 +1,58 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AcceptanceCriteriaFile is the file in an agent's directory listing its
+// task's acceptance criteria, which swarm-agent complete echoes back
+const AcceptanceCriteriaFile = "acceptance_criteria.md"
+
+// validateAcceptanceCriteria checks that no criterion is blank
+func (t *Task) validateAcceptanceCriteria() error {
+	for i, criterion := range t.AcceptanceCriteria {
+		if strings.TrimSpace(criterion) == "" {
+			return fmt.Errorf("acceptance criterion %d is empty", i+1)
+		}
+	}
+	return nil
+}
+
+// Checklist renders acceptance criteria as a Markdown checklist, one
+// criterion per item
+func Checklist(criteria []string) string {
+	var b strings.Builder
+	for _, criterion := range criteria {
+		// Continuation lines stay inside their item
+		lines := strings.Split(strings.TrimSpace(criterion), "\n")
+		fmt.Fprintf(&b, "- [ ] %s\n", strings.Join(lines, "\n      "))
+	}
+	return b.String()
+}
+
+// textField is a task field that may reference parameters and snippets
+type textField struct{ name, text string }
+
+// textFields returns the task's prompt, description, acceptance criteria
+// and verification command
+func (t *Task) textFields() []textField {
+	fields := []textField{{"prompt", t.Prompt}, {"description", t.Description}}
+	for _, criterion := range t.AcceptanceCriteria {
+		fields = append(fields, textField{"acceptance_criteria", criterion})
+	}
+	if t.Verify != nil {
+		fields = append(fields, textField{"verify", t.Verify.Run})
+	}
+	return fields
+}
+
+// AcceptanceCriteria returns the task's acceptance criteria with the
+// workflow's parameters filled in
+func (w *Workflow) AcceptanceCriteria(task Task) []string {
+	var criteria []string
+	for _, criterion := range task.AcceptanceCriteria {
+		criteria = append(criteria, w.InterpolateParams(criterion))
+	}
+	return criteria
+}
@@ -0,0 +1,17 @@
+package workflow
+
+import (
+	"strings"
+	"time"
+)
+
+// AgentLogFile is the agent's transcript in its agent directory: the
+// output of a spawned agent process, with the notes and operations
+// swarm-agent appends to it
+const AgentLogFile = "agent.log"
+
+// AgentLogLine formats a line swarm-agent appends to the transcript, such
+// as "[14:23:45] write_file main.go: success"
+func AgentLogLine(at time.Time, text string) string {
+	return "[" + at.Format("15:04:05") + "] " + strings.Join(strings.Fields(text), " ") + "\n"
+}
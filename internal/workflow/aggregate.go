@@ -0,0 +1,103 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// TaskTypeAggregate marks a task the orchestrator completes itself by
+// combining its dependencies' outputs, without spawning an agent
+const TaskTypeAggregate = "aggregate"
+
+// Ways an aggregate task combines outputs
+const (
+	AggregateConcat  = "concat"  // Each output under a heading with its task ID
+	AggregateBullets = "bullets" // One bullet per task with the gist of its output
+	AggregateJSON    = "json"    // A JSON array of {task, output} objects
+)
+
+// bulletLength bounds the gist of an output in a bullet summary
+const bulletLength = 200
+
+// IsAggregate reports whether the orchestrator completes the task itself
+// by combining its dependencies' outputs: an aggregate task, or the join
+// of a matrix or include without a prompt
+func (t Task) IsAggregate() bool {
+	return t.Aggregate || t.Type == TaskTypeAggregate
+}
+
+// AggregateOutputs combines the outputs of tasks in a format; an empty
+// format concatenates them
+func AggregateOutputs(format string, taskIDs []string, outputs map[string]string) (string, error) {
+	switch format {
+	case "", AggregateConcat:
+		return JoinOutputs(taskIDs, outputs), nil
+
+	case AggregateBullets:
+		lines := make([]string, len(taskIDs))
+		for i, id := range taskIDs {
+			lines[i] = fmt.Sprintf("- **%s**: %s", id, gist(outputs[id]))
+		}
+		return strings.Join(lines, "\n"), nil
+
+	case AggregateJSON:
+		type entry struct {
+			Task   string `json:"task"`
+			Output any    `json:"output"`
+		}
+		entries := make([]entry, len(taskIDs))
+		for i, id := range taskIDs {
+			// Outputs that are JSON are embedded as is, others as strings
+			var output any = strings.TrimSpace(outputs[id])
+			if raw := []byte(output.(string)); json.Valid(raw) {
+				output = json.RawMessage(raw)
+			}
+			entries[i] = entry{Task: id, Output: output}
+		}
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to encode outputs: %w", err)
+		}
+		return string(data), nil
+
+	default:
+		return "", fmt.Errorf("unknown aggregate format %q", format)
+	}
+}
+
+// gist returns the first sentence of an output's first line of prose,
+// skipping headings and code fences
+func gist(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "```") {
+			continue
+		}
+		line = strings.TrimLeft(line, "-*> ")
+		if end := strings.Index(line, ". "); end >= 0 {
+			line = line[:end+1]
+		}
+		if runes := []rune(line); len(runes) > bulletLength {
+			line = string(runes[:bulletLength]) + "…"
+		}
+		return line
+	}
+	return "(no output)"
+}
+
+// validateAggregate checks an aggregate task's fields
+func (t Task) validateAggregate() error {
+	if t.Prompt != "" {
+		return fmt.Errorf("type %s combines outputs, not a prompt", TaskTypeAggregate)
+	}
+	if len(t.DependsOn) == 0 {
+		return fmt.Errorf("type %s needs depends_on, the tasks whose outputs it combines", TaskTypeAggregate)
+	}
+	switch t.Format {
+	case "", AggregateConcat, AggregateBullets, AggregateJSON:
+		return nil
+	default:
+		return fmt.Errorf("unknown format %q (want %s, %s or %s)", t.Format, AggregateConcat, AggregateBullets, AggregateJSON)
+	}
+}
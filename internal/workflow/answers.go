@@ -0,0 +1,104 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CannedAnswer is an answer the orchestrator gives instantly to questions
+// matching a pattern, such as which package manager to use, so that only
+// novel questions need a formulated answer
+type CannedAnswer struct {
+	// Match is a regular expression, matched case-insensitively against
+	// the question
+	Match  string `yaml:"match"`
+	Answer string `yaml:"answer"`
+	// Tasks limits the answer to questions from these tasks
+	Tasks []string `yaml:"tasks,omitempty"`
+}
+
+// CannedAnswers are checked in order; the first match answers
+type CannedAnswers []CannedAnswer
+
+// Find returns the canned answer to a task's question, if one matches
+func (a CannedAnswers) Find(taskID, question string) (string, bool) {
+	for _, canned := range a {
+		if len(canned.Tasks) > 0 && !contains(canned.Tasks, taskID) {
+			continue
+		}
+		re, err := regexp.Compile("(?i)" + canned.Match)
+		if err != nil {
+			continue
+		}
+		if re.MatchString(question) {
+			return canned.Answer, true
+		}
+	}
+	return "", false
+}
+
+// validate checks that every answer has a valid pattern and text
+func (a CannedAnswers) validate() error {
+	for i, canned := range a {
+		if canned.Match == "" || canned.Answer == "" {
+			return fmt.Errorf("answer %d: match and answer are required", i+1)
+		}
+		if _, err := regexp.Compile("(?i)" + canned.Match); err != nil {
+			return fmt.Errorf("answer %d: invalid match: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// UserAnswersFile is where canned answers shared by all workflows live
+func UserAnswersFile() string {
+	return filepath.Join(os.Getenv("HOME"), ".claude-swarm", "answers.yaml")
+}
+
+// LoadAnswers reads a file of canned answers, an "answers:" list like a
+// workflow's
+func LoadAnswers(path string) (CannedAnswers, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read answers: %w", err)
+	}
+
+	var file struct {
+		Answers CannedAnswers `yaml:"answers"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse answers %s: %w", path, err)
+	}
+	if err := file.Answers.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return file.Answers, nil
+}
+
+// AddAnswers appends the canned answers of a file to the workflow's, which
+// take precedence. A missing file is not an error.
+func (w *Workflow) AddAnswers(path string) error {
+	answers, err := LoadAnswers(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	w.Answers = append(w.Answers, answers...)
+	return nil
+}
+
+func contains(list []string, item string) bool {
+	for _, entry := range list {
+		if entry == item {
+			return true
+		}
+	}
+	return false
+}
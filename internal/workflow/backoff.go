@@ -0,0 +1,94 @@
+package workflow
+
+import (
+	"fmt"
+	"time"
+)
+
+// Backoff defaults, for workflows that set no backoff or leave parts out
+const (
+	DefaultBackoffInitial  = 10 * time.Second
+	DefaultBackoffMax      = 5 * time.Minute
+	DefaultBackoffWindow   = 10 * time.Minute
+	DefaultQuarantineAfter = 5
+)
+
+// Backoff keeps tasks that fail over and over from burning the budget.
+// Failures of a task within the window of each other are quick: each one
+// doubles how long a restarted agent waits before it spawns, and too many
+// quarantine the task until an operator reruns it.
+type Backoff struct {
+	// Initial is the wait after the first quick failure, such as 10s
+	Initial string `yaml:"initial,omitempty"`
+	// Max caps the wait, such as 5m
+	Max string `yaml:"max,omitempty"`
+	// Window is how close failures must be to count as quick, such as 10m
+	Window string `yaml:"window,omitempty"`
+	// QuarantineAfter is how many quick failures quarantine a task
+	QuarantineAfter int `yaml:"quarantine_after,omitempty"`
+}
+
+// Delay returns how long a task waits before running again after a
+// number of quick failures
+func (b *Backoff) Delay(failures int) time.Duration {
+	if failures <= 0 {
+		return 0
+	}
+	initial, max := DefaultBackoffInitial, DefaultBackoffMax
+	if b != nil {
+		initial = parseDurationOr(b.Initial, initial)
+		max = parseDurationOr(b.Max, max)
+	}
+
+	delay := initial
+	for i := 1; i < failures && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		return max
+	}
+	return delay
+}
+
+// WindowDuration returns how close failures must be to count as quick
+func (b *Backoff) WindowDuration() time.Duration {
+	if b == nil {
+		return DefaultBackoffWindow
+	}
+	return parseDurationOr(b.Window, DefaultBackoffWindow)
+}
+
+// Quarantines reports whether a number of quick failures quarantine a task
+func (b *Backoff) Quarantines(failures int) bool {
+	limit := DefaultQuarantineAfter
+	if b != nil && b.QuarantineAfter > 0 {
+		limit = b.QuarantineAfter
+	}
+	return failures >= limit
+}
+
+// validate checks the durations and the quarantine threshold
+func (b *Backoff) validate() error {
+	for _, field := range [][2]string{{"initial", b.Initial}, {"max", b.Max}, {"window", b.Window}} {
+		if field[1] == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(field[1]); err != nil || d <= 0 {
+			return fmt.Errorf("%s %q is not a positive duration such as 30s or 5m", field[0], field[1])
+		}
+	}
+	if b.QuarantineAfter < 0 {
+		return fmt.Errorf("quarantine_after must not be negative")
+	}
+	return nil
+}
+
+// parseDurationOr parses a duration, or returns a default when it is unset
+// or invalid
+func parseDurationOr(value string, def time.Duration) time.Duration {
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return def
+	}
+	return d
+}
@@ -0,0 +1,106 @@
+package workflow
+
+import (
+	"fmt"
+)
+
+// DefaultUSDPerMillionTokens is the blended input and output price used to
+// turn tokens into cost, in estimates and for usage reported without a cost
+const DefaultUSDPerMillionTokens = 6.0
+
+// Budget caps the tokens and money a run may spend, as reported by its
+// agents. Zero means unlimited.
+type Budget struct {
+	MaxTokens  int64   `yaml:"max_tokens,omitempty"`
+	MaxCostUSD float64 `yaml:"max_cost_usd,omitempty"`
+	// USDPerMillionTokens prices usage reported without a cost
+	USDPerMillionTokens float64 `yaml:"usd_per_million_tokens,omitempty"`
+	// OnExceeded is what happens once the budget is spent: pause the run
+	// (the default) or cancel it
+	OnExceeded BudgetAction `yaml:"on_exceeded,omitempty"`
+}
+
+// BudgetAction is what the orchestrator does when a budget is exceeded
+type BudgetAction string
+
+const (
+	BudgetPause  BudgetAction = "pause"
+	BudgetCancel BudgetAction = "cancel"
+)
+
+// TokenUsage is the tokens an agent reported spending and their cost
+type TokenUsage struct {
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+}
+
+// Tokens returns the input and output tokens together
+func (u TokenUsage) Tokens() int64 {
+	return u.InputTokens + u.OutputTokens
+}
+
+// Add returns the sum of two usages
+func (u TokenUsage) Add(other TokenUsage) TokenUsage {
+	return TokenUsage{
+		InputTokens:  u.InputTokens + other.InputTokens,
+		OutputTokens: u.OutputTokens + other.OutputTokens,
+		CostUSD:      u.CostUSD + other.CostUSD,
+	}
+}
+
+// Price fills in the cost of usage reported without one
+func (b *Budget) Price(usage TokenUsage) TokenUsage {
+	if usage.CostUSD > 0 {
+		return usage
+	}
+	rate := DefaultUSDPerMillionTokens
+	if b != nil && b.USDPerMillionTokens > 0 {
+		rate = b.USDPerMillionTokens
+	}
+	usage.CostUSD = float64(usage.Tokens()) / 1e6 * rate
+	return usage
+}
+
+// Exceeded returns why total usage is over the budget, or "" if it is not
+func (b *Budget) Exceeded(total TokenUsage) string {
+	if b == nil {
+		return ""
+	}
+	if b.MaxTokens > 0 && total.Tokens() > b.MaxTokens {
+		return fmt.Sprintf("max_tokens (%d) exceeded: %d tokens used", b.MaxTokens, total.Tokens())
+	}
+	if b.MaxCostUSD > 0 && total.CostUSD > b.MaxCostUSD {
+		return fmt.Sprintf("max_cost_usd ($%.2f) exceeded: $%.2f spent", b.MaxCostUSD, total.CostUSD)
+	}
+	return ""
+}
+
+// validate checks the limits and the action
+func (b *Budget) validate() error {
+	if b.MaxTokens < 0 || b.MaxCostUSD < 0 || b.USDPerMillionTokens < 0 {
+		return fmt.Errorf("max_tokens, max_cost_usd and usd_per_million_tokens must not be negative")
+	}
+	switch b.OnExceeded {
+	case "", BudgetPause, BudgetCancel:
+		return nil
+	}
+	return fmt.Errorf("unknown on_exceeded %q (want pause or cancel)", b.OnExceeded)
+}
+
+// FormatUsage formats usage for people, such as "12k tokens, $0.07"
+func FormatUsage(usage TokenUsage) string {
+	return fmt.Sprintf("%s tokens, $%.2f", FormatTokens(usage.Tokens()), usage.CostUSD)
+}
+
+// FormatTokens formats a token count as 850, 12k or 1.2M
+func FormatTokens(n int64) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1e6)
+	case n >= 1000:
+		return fmt.Sprintf("%dk", (n+500)/1000)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
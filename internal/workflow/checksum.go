@@ -0,0 +1,80 @@
+package workflow
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+)
+
+// Checksum returns the hex-encoded SHA-256 of content
+func Checksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyChecksum checks content against a checksum. An empty checksum is
+// not checked, for peers that do not send one.
+func VerifyChecksum(content, checksum string) error {
+	if checksum == "" {
+		return nil
+	}
+	if got := Checksum(content); got != checksum {
+		return Errorf(ErrorChecksumMismatch, "checksum mismatch: expected %s, got %s (%d bytes); content was truncated or modified in transit", checksum, got, len(content))
+	}
+	return nil
+}
+
+// VerifyFile reads a file back and checks it against a checksum, catching
+// writes that were truncated or raced by another writer. It returns the
+// file's checksum.
+func VerifyFile(path, checksum string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to verify %s: %w", path, err)
+	}
+
+	got := Checksum(string(content))
+	if got != checksum {
+		return got, Errorf(ErrorChecksumMismatch, "checksum mismatch: %s does not hold the written content (expected %s, got %s); it was truncated or modified concurrently", path, checksum, got)
+	}
+	return got, nil
+}
+
+// StaleError reports that a file changed since the agent read it
+type StaleError struct {
+	Path     string
+	Expected string
+	Current  string // Checksum of Content, empty if the file is gone
+	Content  string
+}
+
+func (e *StaleError) Error() string {
+	if e.Current == "" {
+		return fmt.Sprintf("%s no longer exists (expected checksum %s)", e.Path, e.Expected)
+	}
+	return fmt.Sprintf("%s changed since it was read (expected checksum %s, now %s); re-read it and retry", e.Path, e.Expected, e.Current)
+}
+
+// ErrorCode classifies stale files as conflicts
+func (e *StaleError) ErrorCode() ErrorCode {
+	return ErrorConflict
+}
+
+// CheckUnchanged returns a *StaleError if a file's content no longer has
+// the checksum the agent expects. An empty expected checksum is not checked.
+func CheckUnchanged(path, content string, exists bool, expected string) error {
+	if expected == "" {
+		return nil
+	}
+
+	current := ""
+	if exists {
+		current = Checksum(content)
+	}
+	if current == expected {
+		return nil
+	}
+
+	return &StaleError{Path: path, Expected: expected, Current: current, Content: content}
+}
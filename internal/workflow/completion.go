@@ -0,0 +1,92 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CompletionFile is where swarm-agent complete writes the metadata it was
+// given, next to output.txt
+const CompletionFile = "completion.json"
+
+// Completion is what an agent reported about its work when it completed,
+// besides its output
+type Completion struct {
+	FilesChanged []string `json:"files_changed,omitempty"`
+	// TestsPassed is whether the agent's tests passed, if it said
+	TestsPassed *bool  `json:"tests_passed,omitempty"`
+	Notes       string `json:"notes,omitempty"`
+}
+
+// Empty reports whether the agent reported nothing
+func (c *Completion) Empty() bool {
+	return c == nil || (len(c.FilesChanged) == 0 && c.TestsPassed == nil && c.Notes == "")
+}
+
+// Summary summarizes the files changed and tests on one line, such as "3
+// files changed, tests passed"
+func (c *Completion) Summary() string {
+	if c == nil {
+		return ""
+	}
+	var parts []string
+	switch len(c.FilesChanged) {
+	case 0:
+	case 1:
+		parts = append(parts, "1 file changed")
+	default:
+		parts = append(parts, fmt.Sprintf("%d files changed", len(c.FilesChanged)))
+	}
+	if c.TestsPassed != nil {
+		parts = append(parts, "tests "+c.testsOutcome())
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Describe lists everything reported, a line each, for prompts and reports
+func (c *Completion) Describe() string {
+	if c.Empty() {
+		return ""
+	}
+	var b strings.Builder
+	if len(c.FilesChanged) > 0 {
+		fmt.Fprintf(&b, "Files changed: %s\n", strings.Join(c.FilesChanged, ", "))
+	}
+	if c.TestsPassed != nil {
+		fmt.Fprintf(&b, "Tests: %s\n", c.testsOutcome())
+	}
+	if c.Notes != "" {
+		fmt.Fprintf(&b, "Notes: %s\n", c.Notes)
+	}
+	return b.String()
+}
+
+func (c *Completion) testsOutcome() string {
+	if *c.TestsPassed {
+		return "passed"
+	}
+	return "failed"
+}
+
+// ReadCompletion reads the completion metadata in an agent directory; it
+// returns nil if the agent reported none
+func ReadCompletion(agentDir string) (*Completion, error) {
+	data, err := os.ReadFile(filepath.Join(agentDir, CompletionFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read completion metadata: %w", err)
+	}
+	var completion Completion
+	if err := json.Unmarshal(data, &completion); err != nil {
+		return nil, fmt.Errorf("failed to parse completion metadata: %w", err)
+	}
+	if completion.Empty() {
+		return nil, nil
+	}
+	return &completion, nil
+}
@@ -0,0 +1,45 @@
+package workflow
+
+import (
+	"fmt"
+	"net"
+)
+
+// DefaultEmailEvents are the events emailed unless configured otherwise
+var DefaultEmailEvents = EventTypes{EventTaskCompleted, EventTaskFailed, EventQuestionAsked}
+
+// Email configures email notifications over SMTP
+type Email struct {
+	// SMTP is the server address as host:port. Port 465 uses implicit TLS;
+	// other ports upgrade with STARTTLS when the server offers it.
+	SMTP string   `yaml:"smtp"`
+	From string   `yaml:"from"`
+	To   []string `yaml:"to"`
+	// On lists the events to send; see DefaultEmailEvents
+	On EventTypes `yaml:"on,omitempty"`
+	// DigestMinutes batches notifications into one email every so many
+	// minutes instead of one email per event
+	DigestMinutes int `yaml:"digest_minutes,omitempty"`
+}
+
+// Events returns the events to send
+func (e *Email) Events() EventTypes {
+	if len(e.On) == 0 {
+		return DefaultEmailEvents
+	}
+	return e.On
+}
+
+// validate checks the server address and recipients
+func (e *Email) validate() error {
+	if _, _, err := net.SplitHostPort(e.SMTP); err != nil {
+		return fmt.Errorf("smtp must be host:port: %w", err)
+	}
+	if e.From == "" || len(e.To) == 0 {
+		return fmt.Errorf("from and to are required")
+	}
+	if e.DigestMinutes < 0 {
+		return fmt.Errorf("digest_minutes must not be negative")
+	}
+	return e.On.validate()
+}
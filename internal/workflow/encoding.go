@@ -0,0 +1,64 @@
+package workflow
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+)
+
+// EncodingGzip marks a payload as base64-encoded gzip
+const EncodingGzip = "gzip"
+
+// CompressionThreshold is the payload size from which compression pays off
+const CompressionThreshold = 64 * 1024
+
+// CompressPayload gzips data if it is at least CompressionThreshold bytes
+// and returns the payload with its encoding; smaller data is returned as is
+// with an empty encoding
+func CompressPayload(data string) (string, string, error) {
+	if len(data) < CompressionThreshold {
+		return data, "", nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := io.WriteString(zw, data); err != nil {
+		return "", "", fmt.Errorf("failed to compress payload: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return "", "", fmt.Errorf("failed to compress payload: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), EncodingGzip, nil
+}
+
+// DecompressPayload reverses CompressPayload
+func DecompressPayload(payload, encoding string) (string, error) {
+	switch encoding {
+	case "":
+		return payload, nil
+	case EncodingGzip:
+	default:
+		return "", Errorf(ErrorInvalidRequest, "unsupported payload encoding %q", encoding)
+	}
+
+	compressed, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return "", Errorf(ErrorInvalidRequest, "failed to decode payload: %v", err)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return "", Errorf(ErrorInvalidRequest, "failed to decompress payload: %v", err)
+	}
+	defer zr.Close()
+
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return "", Errorf(ErrorInvalidRequest, "failed to decompress payload: %v", err)
+	}
+
+	return string(data), nil
+}
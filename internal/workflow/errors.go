@@ -0,0 +1,119 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+)
+
+// ErrorCode is a machine-readable category of a failed operation
+type ErrorCode string
+
+const (
+	ErrorNotFound            ErrorCode = "not_found"
+	ErrorPermissionDenied    ErrorCode = "permission_denied"
+	ErrorTimeout             ErrorCode = "timeout"
+	ErrorSandboxViolation    ErrorCode = "sandbox_violation"
+	ErrorQuotaExceeded       ErrorCode = "quota_exceeded"
+	ErrorConflict            ErrorCode = "conflict"
+	ErrorChecksumMismatch    ErrorCode = "checksum_mismatch"
+	ErrorInvalidRequest      ErrorCode = "invalid_request"
+	ErrorUnauthorized        ErrorCode = "unauthorized"
+	ErrorUnsupportedProtocol ErrorCode = "unsupported_protocol"
+	ErrorCommandFailed       ErrorCode = "command_failed"
+	ErrorVerificationFailed  ErrorCode = "verification_failed"
+	ErrorInternal            ErrorCode = "internal"
+)
+
+// CodedError attaches an error code to an error
+type CodedError struct {
+	Code ErrorCode
+	Err  error
+}
+
+func (e *CodedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CodedError) Unwrap() error {
+	return e.Err
+}
+
+// WithCode attaches an error code to err
+func WithCode(code ErrorCode, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &CodedError{Code: code, Err: err}
+}
+
+// Errorf formats an error with an error code
+func Errorf(code ErrorCode, format string, args ...interface{}) error {
+	return &CodedError{Code: code, Err: fmt.Errorf(format, args...)}
+}
+
+// CodeOf classifies an error. Errors carrying a code, or implementing
+// ErrorCode() ErrorCode, keep it; common system errors are mapped; anything
+// else is internal.
+func CodeOf(err error) ErrorCode {
+	var coded *CodedError
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+
+	var typed interface{ ErrorCode() ErrorCode }
+	if errors.As(err, &typed) {
+		return typed.ErrorCode()
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return ErrorNotFound
+	case errors.Is(err, fs.ErrPermission):
+		return ErrorPermissionDenied
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorTimeout
+	case errors.Is(err, filepath.ErrBadPattern):
+		return ErrorInvalidRequest
+	case errors.As(err, &exitErr):
+		return ErrorCommandFailed
+	}
+
+	return ErrorInternal
+}
+
+// FieldError names the request field an error is about
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// WithField names the request field err is about
+func WithField(field string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &FieldError{Field: field, Err: err}
+}
+
+// FieldOf returns the request field an error is about, or "" if it is not
+// about one
+func FieldOf(err error) string {
+	var fieldErr *FieldError
+	if errors.As(err, &fieldErr) {
+		return fieldErr.Field
+	}
+	return ""
+}
@@ -0,0 +1,113 @@
+package workflow
+
+import (
+	"fmt"
+	"slices"
+)
+
+// OnFailure configures what runs when a task fails: another task of the
+// workflow, or an inline prompt
+type OnFailure struct {
+	Task      string `yaml:"task,omitempty"`
+	AgentType string `yaml:"agent_type,omitempty"`
+	Prompt    string `yaml:"prompt,omitempty"`
+}
+
+// ExpandFailureHandlers links tasks to the handlers that run when they
+// fail. A named handler task only runs if one of the tasks naming it
+// fails; an inline prompt becomes a handler task "<id>-on-failure".
+// Expanding a workflow again leaves the handlers it already has.
+func (w *Workflow) ExpandFailureHandlers() error {
+	index := make(map[string]int, len(w.Tasks))
+	for i, task := range w.Tasks {
+		index[task.ID] = i
+	}
+
+	var tasks []Task
+	var handlers []Task
+	for _, task := range w.Tasks {
+		tasks = append(tasks, task)
+		if task.OnFailure == nil {
+			continue
+		}
+
+		handler := task.OnFailure
+		switch {
+		case handler.Task != "" && handler.Prompt != "":
+			return fmt.Errorf("task %s: on_failure takes a task or a prompt, not both", task.ID)
+		case handler.Task == "" && handler.Prompt == "":
+			return fmt.Errorf("task %s: on_failure needs a task or a prompt", task.ID)
+		case handler.Task != "":
+			if _, ok := index[handler.Task]; !ok {
+				return fmt.Errorf("task %s: on_failure task %s not found", task.ID, handler.Task)
+			}
+			if handler.Task == task.ID {
+				return fmt.Errorf("task %s: on_failure must name another task", task.ID)
+			}
+		default:
+			if i, ok := index[task.ID+"-on-failure"]; ok {
+				if !slices.Contains(w.Tasks[i].FailureOf, task.ID) {
+					return fmt.Errorf("task %s: duplicate task ID: %s-on-failure", task.ID, task.ID)
+				}
+				continue
+			}
+			agentType := handler.AgentType
+			if agentType == "" {
+				agentType = task.AgentType
+			}
+			handlers = append(handlers, Task{
+				ID:          task.ID + "-on-failure",
+				AgentType:   agentType,
+				Description: fmt.Sprintf("Handle the failure of %s", task.ID),
+				Prompt:      handler.Prompt,
+				Group:       task.Group,
+				Priority:    task.Priority,
+				Quotas:      task.Quotas,
+				FailureOf:   []string{task.ID},
+			})
+		}
+	}
+	tasks = append(tasks, handlers...)
+
+	// Link named handlers to every task naming them, now that inline
+	// handlers are tasks too
+	byID := make(map[string]*Task, len(tasks))
+	for i := range tasks {
+		byID[tasks[i].ID] = &tasks[i]
+	}
+	for _, task := range tasks {
+		if task.OnFailure != nil && task.OnFailure.Task != "" {
+			handler := byID[task.OnFailure.Task]
+			if !slices.Contains(handler.FailureOf, task.ID) {
+				handler.FailureOf = append(handler.FailureOf, task.ID)
+			}
+		}
+	}
+
+	w.Tasks = tasks
+	return nil
+}
+
+// validateFailureHandlers checks that handlers can run when triggered and
+// that only other handlers wait for them
+func (w *Workflow) validateFailureHandlers(v *validator) {
+	handlers := make(map[string]bool)
+	for _, task := range w.Tasks {
+		if len(task.FailureOf) > 0 {
+			handlers[task.ID] = true
+		}
+	}
+
+	for _, task := range w.Tasks {
+		for _, dep := range task.DependsOn {
+			if handlers[dep] && !handlers[task.ID] {
+				v.add(taskPath(task.ID, "depends_on", dep), "task %s: depends on %s, which only runs when a task fails", task.ID, dep)
+			}
+			for _, failed := range task.FailureOf {
+				if dep == failed {
+					v.add(taskPath(task.ID, "depends_on", dep), "task %s: handles the failure of %s, so it cannot depend on it", task.ID, dep)
+				}
+			}
+		}
+	}
+}
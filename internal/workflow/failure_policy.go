@@ -0,0 +1,40 @@
+package workflow
+
+import "fmt"
+
+// FailurePolicy decides what happens to the rest of a workflow when a task
+// fails
+type FailurePolicy string
+
+const (
+	// FailurePolicyHalt stops everything: running agents are cancelled and
+	// no further task starts
+	FailurePolicyHalt FailurePolicy = "halt"
+	// FailurePolicyContinueIndependent blocks the failed task's dependents
+	// while the tasks that don't depend on it run to the end. The default.
+	FailurePolicyContinueIndependent FailurePolicy = "continue_independent"
+	// FailurePolicyIgnore treats failed tasks as done: their dependents run
+	// and the workflow completes
+	FailurePolicyIgnore FailurePolicy = "ignore"
+)
+
+// FailurePolicies are the valid failure policies
+var FailurePolicies = []FailurePolicy{FailurePolicyHalt, FailurePolicyContinueIndependent, FailurePolicyIgnore}
+
+// ParseFailurePolicy parses a failure policy name
+func ParseFailurePolicy(name string) (FailurePolicy, error) {
+	for _, policy := range FailurePolicies {
+		if FailurePolicy(name) == policy {
+			return policy, nil
+		}
+	}
+	return "", fmt.Errorf("unknown failure policy %q (want halt, continue_independent or ignore)", name)
+}
+
+// OnTaskFailure returns the workflow's failure policy
+func (w *Workflow) OnTaskFailure() FailurePolicy {
+	if w.FailurePolicy == "" {
+		return FailurePolicyContinueIndependent
+	}
+	return w.FailurePolicy
+}
@@ -0,0 +1,37 @@
+package workflow
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Fragment is a set of tasks an agent adds to a running workflow, in the
+// same format as a workflow's tasks
+type Fragment struct {
+	Tasks []Task `yaml:"tasks"`
+}
+
+// ParseFragment parses a tasks.yaml fragment, expanding its matrices and
+// failure handlers. The tasks are validated against the running workflow
+// when they are added.
+func ParseFragment(data []byte) ([]Task, error) {
+	var fragment Fragment
+	if err := yaml.Unmarshal(data, &fragment); err != nil {
+		return nil, Errorf(ErrorInvalidRequest, "failed to parse tasks YAML: %v", err)
+	}
+	if len(fragment.Tasks) == 0 {
+		return nil, Errorf(ErrorInvalidRequest, "tasks fragment has no tasks")
+	}
+
+	w := Workflow{Tasks: fragment.Tasks}
+	for _, expand := range []func() error{
+		w.ExpandMatrices,
+		w.ExpandFailureHandlers,
+	} {
+		if err := expand(); err != nil {
+			return nil, WithCode(ErrorInvalidRequest, fmt.Errorf("invalid tasks: %w", err))
+		}
+	}
+	return w.Tasks, nil
+}
@@ -0,0 +1,45 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Edge is a task's dependency on another task
+type Edge struct {
+	From string // The dependency
+	To   string // The task depending on it
+	// Placeholders are the dependency's {id.output} and {id.artifacts}
+	// placeholders the dependent task's prompt uses
+	Placeholders []string
+	// Joined is set when the dependent task is an aggregate, which uses
+	// its dependencies' outputs without placeholders
+	Joined bool
+}
+
+// Unreferenced reports whether the dependent task never references the
+// dependency's output, so the edge only orders the two tasks
+func (e Edge) Unreferenced() bool {
+	return !e.Joined && len(e.Placeholders) == 0
+}
+
+// Edges returns the workflow's dependencies, in task order, with the
+// placeholders through which each dependent task uses its dependency's
+// output
+func (w *Workflow) Edges() []Edge {
+	var edges []Edge
+	for _, task := range w.Tasks {
+		prompt := w.InterpolateParams(task.Prompt)
+		for _, depID := range task.DependsOn {
+			edge := Edge{From: depID, To: task.ID, Joined: task.IsAggregate()}
+			for _, suffix := range []string{"output", "artifacts"} {
+				placeholder := fmt.Sprintf("{%s.%s}", depID, suffix)
+				if strings.Contains(prompt, placeholder) {
+					edge.Placeholders = append(edge.Placeholders, placeholder)
+				}
+			}
+			edges = append(edges, edge)
+		}
+	}
+	return edges
+}
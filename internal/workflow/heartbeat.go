@@ -0,0 +1,44 @@
+package workflow
+
+import (
+	"fmt"
+	"time"
+)
+
+// HeartbeatFile is the file in an agent directory that swarm-agent
+// rewrites to show the agent is alive
+const HeartbeatFile = "heartbeat"
+
+// HeartbeatInterval is how often swarm-agent writes heartbeats while it
+// runs, such as while waiting for a long operation
+const HeartbeatInterval = 30 * time.Second
+
+// HeartbeatDeadFactor is how many heartbeat timeouts an agent stays
+// silent before it is presumed dead and its task fails
+const HeartbeatDeadFactor = 2
+
+// HeartbeatTimeoutDuration returns how long an agent may stay silent
+// before it is stale, or zero when heartbeats are not checked
+func (w *Workflow) HeartbeatTimeoutDuration() time.Duration {
+	d, err := time.ParseDuration(w.HeartbeatTimeout)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// validateHeartbeatTimeout checks that heartbeat_timeout leaves room for
+// a few heartbeats
+func (w *Workflow) validateHeartbeatTimeout() error {
+	if w.HeartbeatTimeout == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(w.HeartbeatTimeout)
+	if err != nil || d <= 0 {
+		return fmt.Errorf("%q is not a positive duration such as 10m", w.HeartbeatTimeout)
+	}
+	if d < 2*HeartbeatInterval {
+		return fmt.Errorf("%s is shorter than two heartbeat intervals (%s)", w.HeartbeatTimeout, 2*HeartbeatInterval)
+	}
+	return nil
+}
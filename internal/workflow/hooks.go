@@ -0,0 +1,139 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultHookTimeout is how long a hook command may run, in seconds
+const DefaultHookTimeout = 30
+
+// Hook runs a shell command when matching events happen, for local
+// automation such as desktop notifications
+type Hook struct {
+	On  EventTypes `yaml:"on"`
+	Run string     `yaml:"run"`
+	// TimeoutSeconds bounds how long the command may run
+	TimeoutSeconds int `yaml:"timeout_seconds,omitempty"`
+}
+
+// EventTypes is a list of event types, written in YAML as a single name or
+// a list. "*" matches every event.
+type EventTypes []EventType
+
+// UnmarshalYAML accepts a single event type as well as a list
+func (e *EventTypes) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*e = EventTypes{EventType(value.Value)}
+		return nil
+	}
+
+	var list []EventType
+	if err := value.Decode(&list); err != nil {
+		return err
+	}
+	*e = list
+	return nil
+}
+
+// HookEvents are the events hooks can run on
+var HookEvents = []EventType{
+	EventTaskStarted,
+	EventTaskCompleted,
+	EventTaskFailed,
+	EventTaskQuarantined,
+	EventQuestionAsked,
+	EventQuestionAnswered,
+	EventAnswerDrafted,
+	EventQuotaExceeded,
+	EventQuotaApproved,
+	EventBudgetExceeded,
+	EventOperationFailed,
+	EventLockAcquired,
+	EventLockReleased,
+	EventTasksAdded,
+	EventTaskRepeated,
+	EventTaskOverdue,
+	EventTaskCancelled,
+	EventTaskRerun,
+	EventTaskSkipped,
+	EventAgentStale,
+	EventAgentCrashed,
+	EventVerificationFailed,
+	EventSwarmPaused,
+	EventSwarmResumed,
+	EventSwarmCancelled,
+	EventCheckpoint,
+	EventFileConflict,
+	EventConflictResolved,
+	EventTaskProgress,
+}
+
+// Matches reports whether an event type is in the list
+func (e EventTypes) Matches(eventType EventType) bool {
+	for _, on := range e {
+		if on == "*" || on == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// validate checks that every event in the list is one of HookEvents
+func (e EventTypes) validate() error {
+	for _, on := range e {
+		if on == "*" {
+			continue
+		}
+		known := false
+		for _, eventType := range HookEvents {
+			if on == eventType {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown event %q", on)
+		}
+	}
+
+	return nil
+}
+
+// Matches reports whether the hook runs on an event type
+func (h Hook) Matches(eventType EventType) bool {
+	return h.On.Matches(eventType)
+}
+
+// Command returns the hook's command for an event, with {event}, {task},
+// {path} and {session} replaced by the shell-quoted values
+func (h Hook) Command(event FileEvent, sessionID string) string {
+	replacer := strings.NewReplacer(
+		"{event}", shellQuote(string(event.Type)),
+		"{task}", shellQuote(event.AgentID),
+		"{path}", shellQuote(event.FilePath),
+		"{session}", shellQuote(sessionID),
+	)
+	return replacer.Replace(h.Run)
+}
+
+// validate checks that a hook has a command and runs on known events
+func (h Hook) validate() error {
+	if h.Run == "" {
+		return fmt.Errorf("run is required")
+	}
+	if len(h.On) == 0 {
+		return fmt.Errorf("on is required")
+	}
+	if h.TimeoutSeconds < 0 {
+		return fmt.Errorf("timeout_seconds must not be negative")
+	}
+	return h.On.validate()
+}
+
+// shellQuote quotes a value for use as a single shell word
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
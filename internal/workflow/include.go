@@ -0,0 +1,247 @@
+package workflow
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Template is a reusable set of tasks, included into workflows by tasks
+// with an include path
+type Template struct {
+	// Args declares the template's arguments with their defaults. An
+	// argument without a default must be given by every include.
+	Args  map[string]*string `yaml:"args,omitempty"`
+	Tasks []Task             `yaml:"tasks"`
+}
+
+// maxIncludeDepth stops templates that include each other endlessly
+const maxIncludeDepth = 8
+
+// argPattern matches {args.name} references
+var argPattern = regexp.MustCompile(`\{args\.([A-Za-z0-9_-]+)\}`)
+
+// LoadTemplate reads a task template file
+func LoadTemplate(path string) (*Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %w", err)
+	}
+
+	var template Template
+	if err := yaml.Unmarshal(data, &template); err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", path, err)
+	}
+	if len(template.Tasks) == 0 {
+		return nil, fmt.Errorf("template %s has no tasks", path)
+	}
+
+	return &template, nil
+}
+
+// ExpandIncludes replaces every task with an include by the template's
+// tasks, instantiated with the task's arguments. Template task IDs are
+// prefixed with the including task's ID, and tasks without dependencies
+// inside the template inherit the including task's. The including task's
+// ID becomes a task the orchestrator completes itself with the outputs of
+// the template's last tasks, so tasks depending on it wait for the whole
+// template. Relative include paths are resolved against dir.
+func (w *Workflow) ExpandIncludes(dir string) error {
+	tasks, err := expandIncludes(w.Tasks, dir, 0)
+	if err != nil {
+		return err
+	}
+	w.Tasks = tasks
+	return nil
+}
+
+func expandIncludes(tasks []Task, dir string, depth int) ([]Task, error) {
+	var expanded []Task
+	for _, task := range tasks {
+		if task.Include == "" {
+			task.resolveSubWorkflow(dir)
+			expanded = append(expanded, task)
+			continue
+		}
+		if depth >= maxIncludeDepth {
+			return nil, fmt.Errorf("task %s: includes nested more than %d deep", task.ID, maxIncludeDepth)
+		}
+
+		path := task.Include
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		template, err := LoadTemplate(path)
+		if err != nil {
+			return nil, fmt.Errorf("task %s: %w", task.ID, err)
+		}
+		args, err := template.bind(task.With)
+		if err != nil {
+			return nil, fmt.Errorf("task %s: %s: %w", task.ID, task.Include, err)
+		}
+
+		instances, err := template.instantiate(task, args)
+		if err != nil {
+			return nil, fmt.Errorf("task %s: %s: %w", task.ID, task.Include, err)
+		}
+		instances, err = expandIncludes(instances, filepath.Dir(path), depth+1)
+		if err != nil {
+			return nil, err
+		}
+		expanded = append(expanded, instances...)
+	}
+	return expanded, nil
+}
+
+// bind resolves the template's arguments from an include's values and the
+// defaults
+func (t *Template) bind(with map[string]string) (map[string]string, error) {
+	args := make(map[string]string, len(t.Args))
+	for name, value := range with {
+		if _, ok := t.Args[name]; !ok {
+			return nil, fmt.Errorf("unknown argument %q (declared: %s)", name, strings.Join(t.argNames(), ", "))
+		}
+		args[name] = value
+	}
+	for name, value := range t.Args {
+		if _, ok := args[name]; ok {
+			continue
+		}
+		if value == nil {
+			return nil, fmt.Errorf("missing argument %q", name)
+		}
+		args[name] = *value
+	}
+	return args, nil
+}
+
+// instantiate returns the template's tasks for one include, followed by
+// the task joining their outputs under the including task's ID
+func (t *Template) instantiate(include Task, args map[string]string) ([]Task, error) {
+	prefix := include.ID + "-"
+	internal := make(map[string]bool, len(t.Tasks))
+	for _, task := range t.Tasks {
+		internal[task.ID] = true
+	}
+
+	// Template references to its own tasks' outputs and artifacts follow
+	// the renaming
+	var pairs []string
+	for id := range internal {
+		pairs = append(pairs, fmt.Sprintf("{%s.output}", id), fmt.Sprintf("{%s%s.output}", prefix, id))
+		pairs = append(pairs, fmt.Sprintf("{%s.artifacts}", id), fmt.Sprintf("{%s%s.artifacts}", prefix, id))
+	}
+	renamer := strings.NewReplacer(pairs...)
+
+	dependedOn := make(map[string]bool)
+	var tasks []Task
+	for _, task := range t.Tasks {
+		if task.ID == "" {
+			return nil, fmt.Errorf("task ID is required")
+		}
+
+		instance := task
+		instance.ID = prefix + task.ID
+		instance.DependsOn = nil
+		for _, dep := range task.DependsOn {
+			if !internal[dep] {
+				return nil, fmt.Errorf("task %s: dependency %s is not in the template", task.ID, dep)
+			}
+			dependedOn[dep] = true
+			instance.DependsOn = append(instance.DependsOn, prefix+dep)
+		}
+		if len(task.DependsOn) == 0 {
+			instance.DependsOn = append([]string(nil), include.DependsOn...)
+		}
+
+		instance.AcceptanceCriteria = append([]string(nil), task.AcceptanceCriteria...)
+		fields := []*string{&instance.Prompt, &instance.Description, &instance.Include}
+		for i := range instance.AcceptanceCriteria {
+			fields = append(fields, &instance.AcceptanceCriteria[i])
+		}
+		if task.Verify != nil {
+			verify := *task.Verify
+			instance.Verify = &verify
+			fields = append(fields, &verify.Run)
+		}
+		for _, field := range fields {
+			text, err := interpolateArgs(*field, args)
+			if err != nil {
+				return nil, fmt.Errorf("task %s: %w", task.ID, err)
+			}
+			*field = renamer.Replace(text)
+		}
+		if task.With != nil {
+			// Nested includes can pass arguments on
+			instance.With = make(map[string]string, len(task.With))
+			for name, value := range task.With {
+				text, err := interpolateArgs(value, args)
+				if err != nil {
+					return nil, fmt.Errorf("task %s: %w", task.ID, err)
+				}
+				instance.With[name] = text
+			}
+		}
+		if instance.Group == "" {
+			instance.Group = include.Group
+		}
+		if instance.Priority == 0 {
+			instance.Priority = include.Priority
+		}
+		if instance.Quotas == nil {
+			instance.Quotas = include.Quotas
+		}
+		if len(instance.Labels) == 0 {
+			instance.Labels = include.Labels
+		}
+		tasks = append(tasks, instance)
+	}
+
+	var last []string
+	for _, task := range t.Tasks {
+		if !dependedOn[task.ID] {
+			last = append(last, prefix+task.ID)
+		}
+	}
+
+	tasks = append(tasks, Task{
+		ID:          include.ID,
+		AgentType:   include.AgentType,
+		Description: fmt.Sprintf("Join the results of %s", include.Include),
+		Prompt:      fmt.Sprintf("Join the outputs of %s", strings.Join(last, ", ")),
+		DependsOn:   last,
+		Group:       include.Group,
+		Labels:      include.Labels,
+		Priority:    include.Priority,
+		Issue:       include.Issue,
+		Aggregate:   true,
+	})
+	return tasks, nil
+}
+
+// interpolateArgs replaces {args.name} references with argument values
+func interpolateArgs(text string, args map[string]string) (string, error) {
+	for _, match := range argPattern.FindAllStringSubmatch(text, -1) {
+		if _, ok := args[match[1]]; !ok {
+			return "", fmt.Errorf("unknown argument %q", match[1])
+		}
+	}
+	return argPattern.ReplaceAllStringFunc(text, func(ref string) string {
+		return args[argPattern.FindStringSubmatch(ref)[1]]
+	}), nil
+}
+
+// argNames returns the declared argument names, sorted
+func (t *Template) argNames() []string {
+	names := make([]string, 0, len(t.Args))
+	for name := range t.Args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,67 @@
+package workflow
+
+import "fmt"
+
+// Issue tracker providers
+const (
+	IssueProviderJira   = "jira"
+	IssueProviderLinear = "linear"
+)
+
+// Issues configures mirroring tasks into an issue tracker
+type Issues struct {
+	Provider string `yaml:"provider"`
+	// URL is the Jira base URL, or overrides the Linear API endpoint
+	URL string `yaml:"url,omitempty"`
+	// Project is the Jira project key issues are created in
+	Project string `yaml:"project,omitempty"`
+	// IssueType is the Jira issue type, "Task" by default
+	IssueType string `yaml:"issue_type,omitempty"`
+	// Team is the key of the Linear team issues are created in
+	Team string `yaml:"team,omitempty"`
+	// Statuses names the tracker status each task status moves issues to.
+	// An empty name leaves the issue where it is.
+	Statuses map[TaskStatus]string `yaml:"statuses,omitempty"`
+}
+
+// defaultIssueStatuses are the tracker statuses used unless configured.
+// Failed tasks keep their issue in progress, with the error as a comment.
+var defaultIssueStatuses = map[TaskStatus]string{
+	TaskStatusRunning:   "In Progress",
+	TaskStatusCompleted: "Done",
+}
+
+// Status returns the tracker status for a task status, or "" to leave
+// issues where they are
+func (i *Issues) Status(status TaskStatus) string {
+	if name, ok := i.Statuses[status]; ok {
+		return name
+	}
+	return defaultIssueStatuses[status]
+}
+
+// validate checks that the provider is known and has what it needs
+func (i *Issues) validate() error {
+	switch i.Provider {
+	case IssueProviderJira:
+		if i.URL == "" || i.Project == "" {
+			return fmt.Errorf("jira requires url and project")
+		}
+	case IssueProviderLinear:
+		if i.Team == "" {
+			return fmt.Errorf("linear requires team")
+		}
+	default:
+		return fmt.Errorf("unknown provider %q (want jira or linear)", i.Provider)
+	}
+
+	for status := range i.Statuses {
+		switch status {
+		case TaskStatusRunning, TaskStatusCompleted, TaskStatusFailed:
+		default:
+			return fmt.Errorf("unknown task status %q in statuses", status)
+		}
+	}
+
+	return nil
+}
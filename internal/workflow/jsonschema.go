@@ -0,0 +1,9 @@
+package workflow
+
+import _ "embed"
+
+// WorkflowSchema is the JSON Schema of workflow files, for editors to
+// complete and check workflows as they are written
+//
+//go:embed workflow.schema.json
+var WorkflowSchema []byte
@@ -0,0 +1,118 @@
+package workflow
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LifecyclePoint is a point in a task's life where lifecycle hooks run
+type LifecyclePoint string
+
+const (
+	// LifecyclePreSpawn runs before an agent is spawned for a task; a
+	// rejection fails the task instead
+	LifecyclePreSpawn LifecyclePoint = "pre_spawn"
+	// LifecyclePostComplete runs when an agent completes its task, after
+	// its output was checked; a rejection fails the task
+	LifecyclePostComplete LifecyclePoint = "post_complete"
+	// LifecycleOnQuestion runs when an agent asks a question that no
+	// canned answer matches; an answer is given to the agent
+	LifecycleOnQuestion LifecyclePoint = "on_question"
+)
+
+// HookPoints are the points lifecycle hooks can run at
+var HookPoints = []LifecyclePoint{LifecyclePreSpawn, LifecyclePostComplete, LifecycleOnQuestion}
+
+// LifecyclePoints is a list of lifecycle points, written in YAML as a
+// single name or a list
+type LifecyclePoints []LifecyclePoint
+
+// UnmarshalYAML accepts a single lifecycle point as well as a list
+func (p *LifecyclePoints) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*p = LifecyclePoints{LifecyclePoint(value.Value)}
+		return nil
+	}
+
+	var list []LifecyclePoint
+	if err := value.Decode(&list); err != nil {
+		return err
+	}
+	*p = list
+	return nil
+}
+
+// LifecycleHook runs a command at lifecycle points, with a HookPayload as
+// JSON on stdin. Unlike event hooks, the orchestrator waits for it and
+// acts on its HookResult, printed as JSON on stdout.
+type LifecycleHook struct {
+	At  LifecyclePoints `yaml:"at"`
+	Run string          `yaml:"run"`
+	// TimeoutSeconds bounds how long the command may run
+	TimeoutSeconds int `yaml:"timeout_seconds,omitempty"`
+}
+
+// HookPayload describes a lifecycle point to a hook
+type HookPayload struct {
+	Point   LifecyclePoint `json:"point"`
+	Session string         `json:"session"`
+	Task    string         `json:"task"`
+	// AgentDir is the agent's directory, where it is known
+	AgentDir string `json:"agent_dir,omitempty"`
+	// Prompt is the task's interpolated prompt, at pre_spawn
+	Prompt string `json:"prompt,omitempty"`
+	// Output is the task's checked output, at post_complete
+	Output string `json:"output,omitempty"`
+	// Criteria are the task's acceptance criteria, at post_complete
+	Criteria []string `json:"acceptance_criteria,omitempty"`
+	// Question and QuestionID are the question asked, at on_question
+	Question   string `json:"question,omitempty"`
+	QuestionID int    `json:"question_id,omitempty"`
+}
+
+// HookResult is what a lifecycle hook decided
+type HookResult struct {
+	// Reject, at pre_spawn or post_complete, fails the task with the
+	// reason given
+	Reject string `json:"reject,omitempty"`
+	// Answer, at on_question, answers the question
+	Answer string `json:"answer,omitempty"`
+}
+
+// Runs reports whether the hook runs at a lifecycle point
+func (h LifecycleHook) Runs(point LifecyclePoint) bool {
+	for _, at := range h.At {
+		if at == point {
+			return true
+		}
+	}
+	return false
+}
+
+// validate checks that a lifecycle hook has a command and runs at known
+// points
+func (h LifecycleHook) validate() error {
+	if h.Run == "" {
+		return fmt.Errorf("run is required")
+	}
+	if len(h.At) == 0 {
+		return fmt.Errorf("at is required")
+	}
+	if h.TimeoutSeconds < 0 {
+		return fmt.Errorf("timeout_seconds must not be negative")
+	}
+	for _, at := range h.At {
+		known := false
+		for _, point := range HookPoints {
+			if at == point {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown lifecycle point %q", at)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package workflow
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MatrixJoin configures the task that joins a matrix's instances
+type MatrixJoin struct {
+	AgentType string `yaml:"agent_type,omitempty"`
+	Prompt    string `yaml:"prompt,omitempty"`
+}
+
+// nonIDChars are replaced when deriving instance IDs from matrix items
+var nonIDChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// ExpandMatrices replaces every task with a matrix by one task per item,
+// with {{item}} and {{index}} interpolated into its prompt and
+// description, and a join task that depends on all of them. The join task
+// takes the original task's ID, so tasks depending on the matrix wait for
+// every instance and {id.output} is the joined output. Without a join
+// prompt the orchestrator joins the outputs itself, without an agent.
+func (w *Workflow) ExpandMatrices() error {
+	var tasks []Task
+	for _, task := range w.Tasks {
+		if task.Matrix == nil {
+			tasks = append(tasks, task)
+			continue
+		}
+		if len(task.Matrix) == 0 {
+			return fmt.Errorf("task %s: matrix is empty", task.ID)
+		}
+
+		instanceIDs := make([]string, 0, len(task.Matrix))
+		seen := make(map[string]bool)
+		for i, item := range task.Matrix {
+			id := instanceID(task.ID, item, i)
+			if seen[id] {
+				id = fmt.Sprintf("%s-%d", task.ID, i+1)
+			}
+			seen[id] = true
+			instanceIDs = append(instanceIDs, id)
+
+			instance := task
+			instance.ID = id
+			instance.Matrix = nil
+			instance.Join = nil
+			instance.Issue = ""
+			instance.Prompt = interpolateItem(task.Prompt, item, i)
+			instance.Description = interpolateItem(task.Description, item, i)
+			instance.AcceptanceCriteria = nil
+			for _, criterion := range task.AcceptanceCriteria {
+				instance.AcceptanceCriteria = append(instance.AcceptanceCriteria, interpolateItem(criterion, item, i))
+			}
+			if task.Verify != nil {
+				verify := *task.Verify
+				verify.Run = interpolateItem(verify.Run, item, i)
+				instance.Verify = &verify
+			}
+			instance.DependsOn = append([]string(nil), task.DependsOn...)
+			tasks = append(tasks, instance)
+		}
+
+		join := Task{
+			ID:          task.ID,
+			AgentType:   task.AgentType,
+			Description: fmt.Sprintf("Join the results of %s", task.ID),
+			DependsOn:   instanceIDs,
+			Group:       task.Group,
+			Labels:      task.Labels,
+			Priority:    task.Priority,
+			Quotas:      task.Quotas,
+			Issue:       task.Issue,
+		}
+		if task.Join != nil && task.Join.Prompt != "" {
+			join.Prompt = task.Join.Prompt
+			if task.Join.AgentType != "" {
+				join.AgentType = task.Join.AgentType
+			}
+		} else {
+			join.Prompt = fmt.Sprintf("Join the outputs of %s", strings.Join(instanceIDs, ", "))
+			join.Aggregate = true
+		}
+		tasks = append(tasks, join)
+	}
+
+	w.Tasks = tasks
+	return nil
+}
+
+// instanceID derives the ID of a matrix instance from its item, falling
+// back to its position for items that make poor IDs
+func instanceID(taskID, item string, index int) string {
+	slug := strings.Trim(nonIDChars.ReplaceAllString(item, "-"), "-.")
+	if slug == "" || len(slug) > 40 {
+		return fmt.Sprintf("%s-%d", taskID, index+1)
+	}
+	return taskID + "-" + slug
+}
+
+// interpolateItem replaces {{item}} and {{index}} (counting from 1)
+func interpolateItem(text, item string, index int) string {
+	return strings.NewReplacer(
+		"{{item}}", item,
+		"{{index}}", strconv.Itoa(index+1),
+	).Replace(text)
+}
+
+// JoinOutputs joins the outputs of a matrix's instances into the output of
+// its join task
+func JoinOutputs(taskIDs []string, outputs map[string]string) string {
+	var b strings.Builder
+	for i, id := range taskIDs {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		fmt.Fprintf(&b, "## %s\n%s", id, strings.TrimSpace(outputs[id]))
+	}
+	return b.String()
+}
@@ -1,44 +1,189 @@
 package workflow
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
 
 // Message represents a message from an agent to the orchestrator
 type Message struct {
-	ID          string      `json:"id"`
-	Type        MessageType `json:"type"`
-	Path        string      `json:"path,omitempty"`
-	Content     string      `json:"content,omitempty"`
-	Command     string      `json:"command,omitempty"`
-	WorkingDir  string      `json:"working_dir,omitempty"`
-	Edits       []Edit      `json:"edits,omitempty"`
-	Timestamp   time.Time   `json:"timestamp"`
+	ID               string      `json:"id"`
+	ProtocolVersion  int         `json:"protocol_version,omitempty"`
+	Type             MessageType `json:"type"`
+	Path             string      `json:"path,omitempty"`
+	Content          string      `json:"content,omitempty"`
+	Encoding         string      `json:"encoding,omitempty"`          // Encoding of Content, empty for plain text
+	AcceptEncoding   string      `json:"accept_encoding,omitempty"`   // Asks for large response data to be compressed
+	Checksum         string      `json:"checksum,omitempty"`          // SHA-256 of the plain Content
+	ExpectedChecksum string      `json:"expected_checksum,omitempty"` // SHA-256 the file must still have for a write or edit to apply
+	Command          string      `json:"command,omitempty"`
+	WorkingDir       string      `json:"working_dir,omitempty"`
+	Edits            []Edit      `json:"edits,omitempty"`
+	TTLSeconds       int         `json:"ttl_seconds,omitempty"` // Lock duration, default 5 minutes
+	MaxResults       int         `json:"max_results,omitempty"` // Glob and grep page size, default 1000
+	Cursor           string      `json:"cursor,omitempty"`      // Next page of a glob or grep, from NextCursor
+	Symbol           string      `json:"symbol,omitempty"`      // Name to look up with code_search, e.g. "Server.Start"
+	Kind             string      `json:"kind,omitempty"`        // Restricts code_search to a kind of symbol
+	References       bool        `json:"references,omitempty"`  // Makes code_search return references instead of definitions
+	Usage            *TokenUsage `json:"usage,omitempty"`       // Tokens spent, for report_usage
+	Percent          *int        `json:"percent,omitempty"`     // How far along the agent is, for progress
+	Task             string      `json:"task,omitempty"`        // Upstream task whose output dep_output returns
+	Stream           bool        `json:"stream,omitempty"`      // Asks for the response in chunks as it is produced
+	Operations       []Message   `json:"operations,omitempty"`  // Operations of a batch, run in order
+	KeepGoing        bool        `json:"keep_going,omitempty"`  // Runs a batch's remaining operations after one fails
+	Timestamp        time.Time   `json:"timestamp"`
+}
+
+// CompressContent gzips large content
+func (m *Message) CompressContent() error {
+	content, encoding, err := CompressPayload(m.Content)
+	if err != nil {
+		return err
+	}
+	m.Content, m.Encoding = content, encoding
+	return nil
+}
+
+// DecodeContent restores compressed content to plain text
+func (m *Message) DecodeContent() error {
+	content, err := DecompressPayload(m.Content, m.Encoding)
+	if err != nil {
+		return err
+	}
+	m.Content, m.Encoding = content, ""
+	return nil
 }
 
 // MessageType represents the type of operation requested
 type MessageType string
 
 const (
-	MessageTypeReadFile  MessageType = "read_file"
-	MessageTypeWriteFile MessageType = "write_file"
-	MessageTypeEditFile  MessageType = "edit_file"
-	MessageTypeBash      MessageType = "bash"
-	MessageTypeGlob      MessageType = "glob"
-	MessageTypeGrep      MessageType = "grep"
+	MessageTypeReadFile   MessageType = "read_file"
+	MessageTypeWriteFile  MessageType = "write_file"
+	MessageTypeEditFile   MessageType = "edit_file"
+	MessageTypeBash       MessageType = "bash"
+	MessageTypeGlob       MessageType = "glob"
+	MessageTypeGrep       MessageType = "grep"
+	MessageTypeCodeSearch MessageType = "code_search"
+	MessageTypeLock       MessageType = "lock"
+	MessageTypeUnlock     MessageType = "unlock"
+	MessageTypeLocks      MessageType = "locks"
+	MessageTypeAddTasks   MessageType = "add_tasks"
+	MessageTypeUsage      MessageType = "report_usage"
+	MessageTypeProgress   MessageType = "progress"
+	MessageTypeCheckpoint MessageType = "checkpoint"
+	MessageTypeDeps       MessageType = "deps"
+	MessageTypeDepOutput  MessageType = "dep_output"
+	MessageTypeBatch      MessageType = "batch"
 )
 
+// MaxBatchOperations is how many operations a batch may hold
+const MaxBatchOperations = 100
+
+// SocketFile is the Unix socket in the session directory where the
+// orchestrator takes messages from agents. Agents fall back to the
+// messages/ directory when it is missing.
+const SocketFile = "swarm.sock"
+
+// Streamed responses come in chunks of at most StreamChunkSize bytes of
+// data. While a command runs silently, an empty chunk every
+// StreamKeepAlive tells the agent the orchestrator is still there.
+const (
+	StreamChunkSize = 256 * 1024
+	StreamKeepAlive = 15 * time.Second
+)
+
+// ChunkFile is the name of a chunk of a streamed response on the file
+// bus, next to the final msg-<id>-result.json
+func ChunkFile(messageID string, sequence int) string {
+	return fmt.Sprintf("%s-chunk-%d.json", messageID, sequence)
+}
+
+// SocketRequest is a message sent over the socket: one JSON line per
+// connection, answered with one Response line, or a line per chunk for
+// streamed messages
+type SocketRequest struct {
+	AgentID string  `json:"agent_id"`
+	Message Message `json:"message"`
+}
+
 // Edit represents a file edit operation
 type Edit struct {
 	OldString string `json:"old_string"`
 	NewString string `json:"new_string"`
 }
 
+// ApplyEdits applies edits to content in order. Each edit replaces the
+// first occurrence of its old string, matching the Edit tool.
+func ApplyEdits(content string, edits []Edit) (string, error) {
+	result := content
+	for i, edit := range edits {
+		if !strings.Contains(result, edit.OldString) {
+			return "", Errorf(ErrorConflict, "edit %d: old_string not found in file", i+1)
+		}
+		result = strings.Replace(result, edit.OldString, edit.NewString, 1)
+	}
+
+	return result, nil
+}
+
 // Response represents the orchestrator's response to a message
 type Response struct {
-	MessageID string    `json:"message_id"`
-	Status    string    `json:"status"`
-	Data      string    `json:"data,omitempty"`
-	Error     string    `json:"error,omitempty"`
-	Timestamp time.Time `json:"timestamp"`
+	MessageID       string    `json:"message_id"`
+	ProtocolVersion int       `json:"protocol_version,omitempty"`
+	Status          string    `json:"status"`
+	Data            string    `json:"data,omitempty"`
+	Encoding        string    `json:"encoding,omitempty"`    // Encoding of Data, empty for plain text
+	Checksum        string    `json:"checksum,omitempty"`    // SHA-256 of the file read or written
+	NextCursor      string    `json:"next_cursor,omitempty"` // Set when a glob or grep has more results
+	More            bool      `json:"more,omitempty"`        // Marks a chunk of a streamed response
+	Sequence        int       `json:"sequence,omitempty"`    // Number of a chunk; on the final response, how many came before it
+	Code            ErrorCode `json:"code,omitempty"`
+	Error           string    `json:"error,omitempty"`
+	Timestamp       time.Time `json:"timestamp"`
+
+	// Results are the responses to a batch's operations, in the order
+	// they ran
+	Results []Response `json:"results,omitempty"`
+}
+
+// SetError marks the response as failed with err's code and message. For
+// a stale file the data carries its current content.
+func (r *Response) SetError(err error) {
+	r.Status = "error"
+	r.Code = CodeOf(err)
+	r.Error = err.Error()
+
+	var stale *StaleError
+	if errors.As(err, &stale) {
+		r.Data = stale.Content
+		r.Checksum = stale.Current
+	}
+}
+
+// CompressData gzips large data if the agent accepts it
+func (r *Response) CompressData(acceptEncoding string) error {
+	if acceptEncoding != EncodingGzip {
+		return nil
+	}
+	data, encoding, err := CompressPayload(r.Data)
+	if err != nil {
+		return err
+	}
+	r.Data, r.Encoding = data, encoding
+	return nil
+}
+
+// DecodeData restores compressed data to plain text
+func (r *Response) DecodeData() error {
+	data, err := DecompressPayload(r.Data, r.Encoding)
+	if err != nil {
+		return err
+	}
+	r.Data, r.Encoding = data, ""
+	return nil
 }
 
 // GlobRequest represents a glob pattern search request
@@ -54,3 +199,42 @@ type GrepRequest struct {
 	Glob       string `json:"glob,omitempty"`
 	OutputMode string `json:"output_mode,omitempty"` // content, files_with_matches, count
 }
+
+// ControlRequest is an operator command sent to a running orchestrator by
+// dropping a JSON file into the session's control/ directory
+type ControlRequest struct {
+	ID     string        `json:"id"`
+	Action ControlAction `json:"action"`
+	TaskID string        `json:"task_id,omitempty"`
+	// Signal interrupts the running agents when cancelling
+	Signal bool `json:"signal,omitempty"`
+	// Question and Answer answer a task's question; an empty answer
+	// approves the orchestrator's draft
+	Question int    `json:"question,omitempty"`
+	Answer   string `json:"answer,omitempty"`
+	// Name labels a checkpoint
+	Name string `json:"name,omitempty"`
+	// Conflict and Resolution resolve a file conflict, with the merged
+	// file's Content for a manual merge
+	Conflict   int    `json:"conflict,omitempty"`
+	Resolution string `json:"resolution,omitempty"`
+	Content    string `json:"content,omitempty"`
+	// Operator is who sent the command
+	Operator  string    `json:"operator,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ControlAction represents the operator command to execute
+type ControlAction string
+
+const (
+	ControlApproveQuota ControlAction = "approve_quota"
+	ControlPause        ControlAction = "pause"
+	ControlResume       ControlAction = "resume"
+	ControlCancel       ControlAction = "cancel"
+	ControlAnswer       ControlAction = "answer"
+	ControlRerun        ControlAction = "rerun"
+	ControlSkip         ControlAction = "skip"
+	ControlCheckpoint   ControlAction = "checkpoint"
+	ControlResolve      ControlAction = "resolve_conflict"
+)
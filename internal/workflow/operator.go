@@ -0,0 +1,38 @@
+package workflow
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UserConfigFile is where the operator's settings shared by all sessions
+// live
+func UserConfigFile() string {
+	return filepath.Join(os.Getenv("HOME"), ".claude-swarm", "config.yaml")
+}
+
+// CurrentOperator returns who is operating the swarm, for attributing
+// answers, approvals and cancellations: $SWARM_OPERATOR, the "operator"
+// set in UserConfigFile, or the OS user
+func CurrentOperator() string {
+	if name := os.Getenv("SWARM_OPERATOR"); name != "" {
+		return name
+	}
+
+	if data, err := os.ReadFile(UserConfigFile()); err == nil {
+		var config struct {
+			Operator string `yaml:"operator"`
+		}
+		if yaml.Unmarshal(data, &config) == nil && config.Operator != "" {
+			return config.Operator
+		}
+	}
+
+	if current, err := user.Current(); err == nil {
+		return current.Username
+	}
+	return os.Getenv("USER")
+}
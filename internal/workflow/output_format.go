@@ -0,0 +1,215 @@
+package workflow
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Output formats a task's completion output may be held to
+const (
+	OutputFormatMarkdown = "markdown" // Markdown with balanced code fences
+	OutputFormatJSON     = "json"     // A JSON document
+	OutputFormatDiff     = "diff"     // A unified diff, as git apply takes
+)
+
+// OutputFormatFile is the file in an agent's directory holding its task's
+// output format, so swarm-agent can check outputs before completing
+const OutputFormatFile = "output_format"
+
+// fenceInfos are the code fence languages stripped from outputs wrapped
+// whole in a fence, by format
+var fenceInfos = map[string][]string{
+	OutputFormatMarkdown: {"markdown", "md"},
+	OutputFormatJSON:     {"", "json"},
+	OutputFormatDiff:     {"", "diff", "patch"},
+}
+
+// hunkHeader matches a unified diff hunk header such as @@ -1,4 +1,5 @@
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// CheckOutput normalizes a task's completion output to its output_format
+// and checks it against the format and its output_schema, returning the
+// output dependents see. Outputs wrapped whole in a code fence are
+// unwrapped and line endings become \n. A task with an output_schema but
+// no output_format is held to json.
+func (t Task) CheckOutput(output string) (string, error) {
+	format := t.OutputFormat
+	if format == "" && t.OutputSchema != nil {
+		format = OutputFormatJSON
+	}
+	return CheckOutput(format, t.OutputSchema, output)
+}
+
+// CheckOutput normalizes an output to a format and checks it against the
+// format and a JSON schema, either of which may be empty
+func CheckOutput(format string, schema map[string]any, output string) (string, error) {
+	if format == "" {
+		return output, nil
+	}
+
+	output = unfence(strings.ReplaceAll(output, "\r\n", "\n"), fenceInfos[format])
+	switch format {
+	case OutputFormatMarkdown:
+		output = strings.TrimSpace(output)
+		if output == "" {
+			return "", Errorf(ErrorInvalidRequest, "output is empty; want markdown")
+		}
+		if countFences(output)%2 != 0 {
+			return "", Errorf(ErrorInvalidRequest, "output is not valid markdown: a code fence is not closed")
+		}
+
+	case OutputFormatJSON:
+		output = strings.TrimSpace(output)
+		if schema == nil {
+			schema = map[string]any{}
+		}
+		if err := ValidateOutput(schema, output); err != nil {
+			return "", err
+		}
+
+	case OutputFormatDiff:
+		// Spaces are significant: a trailing blank context line is " "
+		output = strings.Trim(output, "\n") + "\n"
+		if err := checkDiff(output); err != nil {
+			return "", Errorf(ErrorInvalidRequest, "output is not a unified diff: %v", err)
+		}
+	}
+	return output, nil
+}
+
+// DescribeOutputFormat tells agents what their completion output must be
+func DescribeOutputFormat(format string) string {
+	switch format {
+	case OutputFormatMarkdown:
+		return "Your completion output must be Markdown. Close every code fence."
+	case OutputFormatJSON:
+		return "Your completion output must be a single JSON document, with nothing before or after it."
+	case OutputFormatDiff:
+		return "Your completion output must be a unified diff that git apply accepts: --- a/<file>\nand +++ b/<file> headers, then hunks whose @@ -start,count +start,count @@\nheaders match the lines that follow."
+	}
+	return ""
+}
+
+// validateOutputFormat checks that output_format is known and fits the
+// task
+func (t Task) validateOutputFormat() error {
+	switch t.OutputFormat {
+	case "":
+		return nil
+	case OutputFormatMarkdown, OutputFormatDiff:
+		if t.OutputSchema != nil {
+			return fmt.Errorf("output_schema requires output_format %s", OutputFormatJSON)
+		}
+	case OutputFormatJSON:
+	default:
+		return fmt.Errorf("unknown output_format %q (want %s, %s or %s)", t.OutputFormat, OutputFormatMarkdown, OutputFormatJSON, OutputFormatDiff)
+	}
+	if t.Type != "" {
+		return fmt.Errorf("output_format applies to agent tasks, not type %s", t.Type)
+	}
+	return nil
+}
+
+// unfence unwraps output wrapped whole in a code fence of one of the
+// languages, as agents often do
+func unfence(output string, infos []string) string {
+	trimmed := strings.TrimSpace(output)
+	first, rest, ok := strings.Cut(trimmed, "\n")
+	if !ok || !strings.HasPrefix(first, "```") || !strings.HasSuffix(rest, "```") {
+		return output
+	}
+
+	info := strings.TrimSpace(strings.TrimPrefix(first, "```"))
+	known := false
+	for _, candidate := range infos {
+		if info == candidate {
+			known = true
+		}
+	}
+	body := strings.TrimSuffix(rest, "```")
+	if !known || countFences(body) > 0 {
+		return output
+	}
+	return body
+}
+
+// countFences counts the lines opening or closing a code fence
+func countFences(text string) int {
+	count := 0
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			count++
+		}
+	}
+	return count
+}
+
+// checkDiff checks that a diff has file headers and hunks whose line
+// counts match their headers
+func checkDiff(diff string) error {
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+	files, hunks := 0, 0
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "):
+			files++
+			i++
+
+		case strings.HasPrefix(line, "@@"):
+			match := hunkHeader.FindStringSubmatch(line)
+			if match == nil {
+				return fmt.Errorf("line %d: malformed hunk header %q", i+1, line)
+			}
+			if files == 0 {
+				return fmt.Errorf("line %d: hunk before any --- and +++ file header", i+1)
+			}
+			hunks++
+
+			start := i + 1
+			oldLines, newLines := hunkLength(match[2]), hunkLength(match[4])
+			for oldLines > 0 || newLines > 0 {
+				i++
+				if i >= len(lines) {
+					return fmt.Errorf("line %d: hunk ends early: %d old and %d new lines missing", start, oldLines, newLines)
+				}
+				switch body := lines[i]; {
+				case body == "" || body[0] == ' ':
+					oldLines--
+					newLines--
+				case body[0] == '-':
+					oldLines--
+				case body[0] == '+':
+					newLines--
+				case body[0] == '\\':
+				default:
+					return fmt.Errorf("line %d: %q inside a hunk; hunk lines start with a space, - or +", i+1, body)
+				}
+				if oldLines < 0 || newLines < 0 {
+					return fmt.Errorf("line %d: hunk has more lines than its header counts", start)
+				}
+			}
+			// "\ No newline at end of file" may follow the last line
+			if i+1 < len(lines) && strings.HasPrefix(lines[i+1], `\`) {
+				i++
+			}
+		}
+	}
+
+	if files == 0 || hunks == 0 {
+		return fmt.Errorf("no --- and +++ file headers with @@ hunks found")
+	}
+	return nil
+}
+
+// hunkLength parses the line count of a hunk header, which defaults to 1
+func hunkLength(count string) int {
+	if count == "" {
+		return 1
+	}
+	n, _ := strconv.Atoi(count)
+	return n
+}
@@ -0,0 +1,40 @@
+package workflow
+
+import (
+	"fmt"
+	"time"
+)
+
+// OverdueFactor is how many times its expected duration a task runs
+// before it is overdue
+const OverdueFactor = 2
+
+// Expected returns how long the task is expected to run, or zero when it
+// sets no expected_duration
+func (t Task) Expected() time.Duration {
+	d, err := time.ParseDuration(t.ExpectedDuration)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// Overdue reports whether a task that has run for elapsed is far beyond
+// its expected duration
+func (t Task) Overdue(elapsed time.Duration) bool {
+	expected := t.Expected()
+	return expected > 0 && elapsed > expected*OverdueFactor
+}
+
+// validateExpectedDuration checks that expected_duration is a positive
+// duration such as 10m or 1h30m
+func (t Task) validateExpectedDuration() error {
+	if t.ExpectedDuration == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(t.ExpectedDuration)
+	if err != nil || d <= 0 {
+		return fmt.Errorf("expected_duration %q is not a positive duration such as 10m or 1h30m", t.ExpectedDuration)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package workflow
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxResults bounds glob and grep results when a request sets no
+// max_results
+const DefaultMaxResults = 1000
+
+// Pagination selects a page of search results: at most Max results,
+// starting after the position encoded in Cursor
+type Pagination struct {
+	Max    int
+	Offset int
+}
+
+// NewPagination reads max_results and a cursor for a search. The cursor
+// must come from a previous page of the same search.
+func NewPagination(maxResults int, cursor string, query ...string) (Pagination, error) {
+	if maxResults <= 0 {
+		maxResults = DefaultMaxResults
+	}
+
+	p := Pagination{Max: maxResults}
+	if cursor == "" {
+		return p, nil
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return p, Errorf(ErrorInvalidRequest, "invalid cursor %q", cursor)
+	}
+	offset, key, ok := strings.Cut(string(decoded), ":")
+	if !ok || key != queryKey(query) {
+		return p, Errorf(ErrorInvalidRequest, "cursor %q does not belong to this search", cursor)
+	}
+	p.Offset, err = strconv.Atoi(offset)
+	if err != nil || p.Offset < 0 {
+		return p, Errorf(ErrorInvalidRequest, "invalid cursor %q", cursor)
+	}
+
+	return p, nil
+}
+
+// Page returns the page of results, and the cursor for the next page or ""
+// if this is the last one
+func (p Pagination) Page(results []string, query ...string) ([]string, string) {
+	if p.Offset >= len(results) {
+		return nil, ""
+	}
+
+	end := p.Offset + p.Max
+	if end >= len(results) {
+		return results[p.Offset:], ""
+	}
+	return results[p.Offset:end], p.cursor(end, query)
+}
+
+// Limit is the number of results to produce to fill this page and know
+// whether another one follows
+func (p Pagination) Limit() int {
+	return p.Offset + p.Max + 1
+}
+
+func (p Pagination) cursor(offset int, query []string) string {
+	return NewCursor(offset, query...)
+}
+
+// NewCursor returns the cursor of the results after a position, for
+// results whose positions never change, such as the numbers of logged
+// events. NewPagination decodes it as the offset.
+func NewCursor(position int, query ...string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(position) + ":" + queryKey(query)))
+}
+
+// queryKey fingerprints a search so that cursors are not reused across
+// searches
+func queryKey(query []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(query, "\x00")))
+	return hex.EncodeToString(sum[:4])
+}
@@ -0,0 +1,72 @@
+package workflow
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// paramPattern matches {params.name} references
+var paramPattern = regexp.MustCompile(`\{params\.([A-Za-z0-9_-]+)\}`)
+
+// ParseParams parses key=value pairs, as given to swarm run --param
+func ParseParams(pairs []string) (map[string]string, error) {
+	params := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid parameter %q: want key=value", pair)
+		}
+		params[key] = value
+	}
+	return params, nil
+}
+
+// SetParams overrides the defaults of declared parameters
+func (w *Workflow) SetParams(overrides map[string]string) error {
+	for key, value := range overrides {
+		if _, ok := w.Params[key]; !ok {
+			return fmt.Errorf("unknown parameter %q (declared: %s)", key, strings.Join(w.paramNames(), ", "))
+		}
+		w.Params[key] = value
+	}
+	return nil
+}
+
+// InterpolateParams replaces {params.name} references with parameter values
+func (w *Workflow) InterpolateParams(text string) string {
+	if len(w.Params) == 0 {
+		return text
+	}
+	return paramPattern.ReplaceAllStringFunc(text, func(ref string) string {
+		name := paramPattern.FindStringSubmatch(ref)[1]
+		if value, ok := w.Params[name]; ok {
+			return value
+		}
+		return ref
+	})
+}
+
+// validateParams checks that tasks only reference declared parameters
+func (w *Workflow) validateParams(v *validator) {
+	for _, task := range w.Tasks {
+		for _, field := range task.textFields() {
+			for _, match := range paramPattern.FindAllStringSubmatch(field.text, -1) {
+				if _, ok := w.Params[match[1]]; !ok {
+					v.add(taskPath(task.ID, field.name), "task %s: unknown parameter %q", task.ID, match[1])
+				}
+			}
+		}
+	}
+}
+
+// paramNames returns the declared parameter names, sorted
+func (w *Workflow) paramNames() []string {
+	names := make([]string, 0, len(w.Params))
+	for name := range w.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
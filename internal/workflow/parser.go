@@ -1,8 +1,11 @@
 package workflow
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -23,64 +26,225 @@ func (p *Parser) ParseFile(path string) (*Workflow, error) {
 		return nil, fmt.Errorf("failed to read workflow file: %w", err)
 	}
 
-	return p.Parse(data)
+	return p.parse(data, filepath.Dir(path))
 }
 
-// Parse parses workflow YAML data
+// Parse parses workflow YAML data. Includes are resolved against the
+// current directory.
 func (p *Parser) Parse(data []byte) (*Workflow, error) {
+	return p.parse(data, ".")
+}
+
+// parse parses workflow YAML data, resolving includes against dir
+func (p *Parser) parse(data []byte, dir string) (*Workflow, error) {
 	var workflow Workflow
 
 	if err := yaml.Unmarshal(data, &workflow); err != nil {
 		return nil, fmt.Errorf("failed to parse workflow YAML: %w", err)
 	}
 
+	if err := workflow.ExpandIncludes(dir); err != nil {
+		return nil, fmt.Errorf("workflow validation failed: %w", err)
+	}
+
+	if err := workflow.ExpandMatrices(); err != nil {
+		return nil, fmt.Errorf("workflow validation failed: %w", err)
+	}
+
+	if err := workflow.ExpandFailureHandlers(); err != nil {
+		return nil, fmt.Errorf("workflow validation failed: %w", err)
+	}
+
+	workflow.ExpandSnippets()
+
 	if err := p.Validate(&workflow); err != nil {
+		var problems ValidationErrors
+		if errors.As(err, &problems) {
+			var doc yaml.Node
+			if yaml.Unmarshal(data, &doc) == nil {
+				problems.locate(&doc)
+			}
+		}
 		return nil, fmt.Errorf("workflow validation failed: %w", err)
 	}
 
 	return &workflow, nil
 }
 
-// Validate validates a workflow definition
+// Validate validates a workflow definition. It reports every problem it
+// finds, as ValidationErrors.
 func (p *Parser) Validate(workflow *Workflow) error {
+	v := &validator{}
+
 	if workflow.Name == "" {
-		return fmt.Errorf("workflow name is required")
+		v.add([]string{"name"}, "workflow name is required")
 	}
 
 	if len(workflow.Tasks) == 0 {
-		return fmt.Errorf("workflow must have at least one task")
+		v.add([]string{"tasks"}, "workflow must have at least one task")
+	}
+
+	if workflow.FailurePolicy != "" {
+		if _, err := ParseFailurePolicy(string(workflow.FailurePolicy)); err != nil {
+			v.add([]string{"failure_policy"}, "failure_policy: %v", err)
+		}
+	}
+
+	if workflow.SummaryThreshold < 0 {
+		v.add([]string{"summary_threshold"}, "summary_threshold must not be negative")
+	}
+
+	if workflow.MaxParallel < 0 {
+		v.add([]string{"max_parallel"}, "max_parallel must not be negative")
+	}
+	for name, group := range workflow.Groups {
+		if group.MaxParallel < 0 {
+			v.add([]string{"groups", name, "max_parallel"}, "group %s: max_parallel must not be negative", name)
+		}
+	}
+
+	if workflow.Issues != nil {
+		if err := workflow.Issues.validate(); err != nil {
+			v.add([]string{"issues"}, "issues: %v", err)
+		}
+	}
+
+	if workflow.Email != nil {
+		if err := workflow.Email.validate(); err != nil {
+			v.add([]string{"email"}, "email: %v", err)
+		}
+	}
+
+	if err := workflow.Answers.validate(); err != nil {
+		v.add([]string{"answers"}, "answers: %v", err)
+	}
+
+	if workflow.Retention != nil {
+		if err := workflow.Retention.validate(); err != nil {
+			v.add([]string{"retention"}, "retention: %v", err)
+		}
+	}
+
+	if workflow.Budget != nil {
+		if err := workflow.Budget.validate(); err != nil {
+			v.add([]string{"budget"}, "budget: %v", err)
+		}
+	}
+
+	if err := workflow.validateHeartbeatTimeout(); err != nil {
+		v.add([]string{"heartbeat_timeout"}, "heartbeat_timeout: %v", err)
+	}
+
+	if err := validateMaxRestarts(workflow.MaxRestarts); err != nil {
+		v.add([]string{"max_restarts"}, "%v", err)
+	}
+
+	if workflow.Backoff != nil {
+		if err := workflow.Backoff.validate(); err != nil {
+			v.add([]string{"backoff"}, "backoff: %v", err)
+		}
+	}
+
+	for i, hook := range workflow.Hooks {
+		if err := hook.validate(); err != nil {
+			v.add([]string{"hooks", strconv.Itoa(i)}, "hook %d: %v", i+1, err)
+		}
+	}
+
+	for i, hook := range workflow.Lifecycle {
+		if err := hook.validate(); err != nil {
+			v.add([]string{"lifecycle", strconv.Itoa(i)}, "lifecycle hook %d: %v", i+1, err)
+		}
 	}
 
 	// Validate task IDs are unique
 	taskIDs := make(map[string]bool)
+	occurrences := make(map[string]int)
 	for _, task := range workflow.Tasks {
+		occurrences[task.ID]++
 		if task.ID == "" {
-			return fmt.Errorf("task ID is required")
+			v.add(taskPath(fmt.Sprintf("#%d", occurrences[""])), "task ID is required")
+			continue
 		}
 
 		if taskIDs[task.ID] {
-			return fmt.Errorf("duplicate task ID: %s", task.ID)
+			v.add(taskPath(fmt.Sprintf("%s#%d", task.ID, occurrences[task.ID]), "id"), "duplicate task ID: %s", task.ID)
 		}
 		taskIDs[task.ID] = true
 
-		if task.Prompt == "" {
-			return fmt.Errorf("task %s: prompt is required", task.ID)
+		if err := task.validateType(); err != nil {
+			v.add(taskPath(task.ID), "task %s: %v", task.ID, err)
+		}
+
+		if err := task.validateContext(); err != nil {
+			v.add(taskPath(task.ID, "context"), "task %s: %v", task.ID, err)
+		}
+
+		if err := task.validateOutputFormat(); err != nil {
+			v.add(taskPath(task.ID, "output_format"), "task %s: %v", task.ID, err)
+		}
+
+		if task.OutputSchema != nil {
+			if err := checkSchema(task.OutputSchema); err != nil {
+				v.add(taskPath(task.ID, "output_schema"), "task %s: output_schema: %v", task.ID, err)
+			}
+		}
+
+		if err := task.validateAcceptanceCriteria(); err != nil {
+			v.add(taskPath(task.ID, "acceptance_criteria"), "task %s: %v", task.ID, err)
+		}
+
+		if task.Verify != nil {
+			if err := task.Verify.validate(); err != nil {
+				v.add(taskPath(task.ID, "verify"), "task %s: verify: %v", task.ID, err)
+			}
+		}
+
+		for _, pattern := range task.Artifacts {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				v.add(taskPath(task.ID, "artifacts", pattern), "task %s: invalid artifact pattern %q: %v", task.ID, pattern, err)
+			}
+		}
+
+		if task.RepeatUntil != nil {
+			if err := task.RepeatUntil.validate(); err != nil {
+				v.add(taskPath(task.ID, "repeat_until"), "task %s: repeat_until: %v", task.ID, err)
+			}
+		}
+
+		if err := task.validateExpectedDuration(); err != nil {
+			v.add(taskPath(task.ID, "expected_duration"), "task %s: %v", task.ID, err)
+		}
+
+		if err := validateMaxRestarts(task.MaxRestarts); err != nil {
+			v.add(taskPath(task.ID, "max_restarts"), "task %s: %v", task.ID, err)
+		}
+
+		if task.Group != "" {
+			if _, ok := workflow.Groups[task.Group]; !ok {
+				v.add(taskPath(task.ID, "group"), "task %s: group %s not defined", task.ID, task.Group)
+			}
 		}
 
 		// Validate dependencies exist
 		for _, depID := range task.DependsOn {
 			if !taskIDs[depID] && !p.taskExistsInList(depID, workflow.Tasks) {
-				return fmt.Errorf("task %s: dependency %s not found", task.ID, depID)
+				v.add(taskPath(task.ID, "depends_on", depID), "task %s: dependency %s not found", task.ID, depID)
 			}
 		}
 	}
 
+	workflow.validateSnippets(v)
+	workflow.validateSecrets(v)
+	workflow.validateParams(v)
+	workflow.validateFailureHandlers(v)
+
 	// Check for circular dependencies
 	if err := p.checkCircularDependencies(workflow); err != nil {
-		return err
+		v.add([]string{"tasks"}, "%v", err)
 	}
 
-	return nil
+	return v.err()
 }
 
 // taskExistsInList checks if a task ID exists in the task list
@@ -143,3 +307,16 @@ func (p *Parser) InterpolatePrompt(prompt string, outputs map[string]string) str
 
 	return result
 }
+
+// InterpolateArtifacts replaces {task-id.artifacts} placeholders with the
+// paths of a task's collected artifacts, one per line
+func (p *Parser) InterpolateArtifacts(prompt string, artifacts map[string][]string) string {
+	result := prompt
+
+	for taskID, paths := range artifacts {
+		placeholder := fmt.Sprintf("{%s.artifacts}", taskID)
+		result = strings.ReplaceAll(result, placeholder, strings.Join(paths, "\n"))
+	}
+
+	return result
+}
@@ -0,0 +1,63 @@
+package workflow
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxIterations caps repeated tasks that do not set max_iterations
+const DefaultMaxIterations = 5
+
+// RepeatUntil repeats a task until its output meets a condition, such as
+// "run the tests and fix failures" until the tests pass. The task fails if
+// the condition is still unmet after the maximum number of iterations.
+type RepeatUntil struct {
+	// OutputContains is met when the output contains the text
+	OutputContains string `yaml:"output_contains,omitempty"`
+	// OutputMatches is met when the output matches the regular expression
+	OutputMatches string `yaml:"output_matches,omitempty"`
+	MaxIterations int    `yaml:"max_iterations,omitempty"`
+}
+
+// Met reports whether a task output meets the condition
+func (r *RepeatUntil) Met(output string) bool {
+	if r.OutputMatches != "" {
+		re, err := regexp.Compile(r.OutputMatches)
+		return err == nil && re.MatchString(output)
+	}
+	return strings.Contains(output, r.OutputContains)
+}
+
+// Limit returns the maximum number of iterations
+func (r *RepeatUntil) Limit() int {
+	if r.MaxIterations > 0 {
+		return r.MaxIterations
+	}
+	return DefaultMaxIterations
+}
+
+// String describes the condition for agents
+func (r *RepeatUntil) String() string {
+	if r.OutputMatches != "" {
+		return fmt.Sprintf("matches the regular expression %s", strconv.Quote(r.OutputMatches))
+	}
+	return fmt.Sprintf("contains %s", strconv.Quote(r.OutputContains))
+}
+
+// validate checks that exactly one condition is set and compiles
+func (r *RepeatUntil) validate() error {
+	if (r.OutputContains == "") == (r.OutputMatches == "") {
+		return fmt.Errorf("set one of output_contains and output_matches")
+	}
+	if r.OutputMatches != "" {
+		if _, err := regexp.Compile(r.OutputMatches); err != nil {
+			return fmt.Errorf("invalid output_matches: %w", err)
+		}
+	}
+	if r.MaxIterations < 0 {
+		return fmt.Errorf("max_iterations must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package workflow
+
+import "fmt"
+
+// DefaultMaxRestarts is how many times a crashed agent process restarts
+// when neither its task nor the workflow sets max_restarts
+const DefaultMaxRestarts = 2
+
+// RestartLimit returns how many times a task's agent process restarts
+// after exiting without completing or failing the task: the task's
+// max_restarts, else the workflow's, else DefaultMaxRestarts
+func (w *Workflow) RestartLimit(task Task) int {
+	switch {
+	case task.MaxRestarts != nil:
+		return *task.MaxRestarts
+	case w.MaxRestarts != nil:
+		return *w.MaxRestarts
+	}
+	return DefaultMaxRestarts
+}
+
+// validateMaxRestarts checks that a max_restarts is not negative
+func validateMaxRestarts(limit *int) error {
+	if limit != nil && *limit < 0 {
+		return fmt.Errorf("max_restarts must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package workflow
+
+import (
+	"fmt"
+	"time"
+)
+
+// Retention limits how much agent artifacts a session keeps. Files of
+// finished agents are pruned, oldest first, once they are older than the
+// maximum age or the session exceeds its size cap.
+type Retention struct {
+	MaxSizeMB   int `yaml:"max_size_mb,omitempty"`
+	MaxAgeHours int `yaml:"max_age_hours,omitempty"`
+
+	// Answered messages on the file bus and their responses are deleted
+	// once older than the TTL, or moved to the agent's archive directory,
+	// running agents' included
+	MessageTTLMinutes int  `yaml:"message_ttl_minutes,omitempty"`
+	ArchiveMessages   bool `yaml:"archive_messages,omitempty"`
+}
+
+// MaxBytes returns the size cap in bytes, or 0 if there is none
+func (r *Retention) MaxBytes() int64 {
+	return int64(r.MaxSizeMB) << 20
+}
+
+// MaxAge returns the maximum artifact age, or 0 if there is none
+func (r *Retention) MaxAge() time.Duration {
+	return time.Duration(r.MaxAgeHours) * time.Hour
+}
+
+// MessageTTL returns how long answered messages are kept, or 0 if they
+// are kept for good
+func (r *Retention) MessageTTL() time.Duration {
+	return time.Duration(r.MessageTTLMinutes) * time.Minute
+}
+
+// validate checks that the limits are not negative
+func (r *Retention) validate() error {
+	if r.MaxSizeMB < 0 || r.MaxAgeHours < 0 {
+		return fmt.Errorf("max_size_mb and max_age_hours must not be negative")
+	}
+	if r.MessageTTLMinutes < 0 {
+		return fmt.Errorf("message_ttl_minutes must not be negative")
+	}
+	return nil
+}
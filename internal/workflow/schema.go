@@ -0,0 +1,297 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// OutputSchemaFile is the file in an agent's directory holding its task's
+// output schema, so swarm-agent can check outputs before completing
+const OutputSchemaFile = "output_schema.json"
+
+// schemaTypes are the JSON schema types ValidateOutput understands
+var schemaTypes = map[string]bool{
+	"object": true, "array": true, "string": true, "number": true,
+	"integer": true, "boolean": true, "null": true,
+}
+
+// schemaKeywords are the keywords ValidateOutput checks, and annotations
+// it may ignore
+var schemaKeywords = map[string]bool{
+	"type": true, "properties": true, "required": true, "additionalProperties": true,
+	"items": true, "enum": true, "const": true, "minimum": true, "maximum": true,
+	"minLength": true, "maxLength": true, "pattern": true, "minItems": true, "maxItems": true,
+	"$schema": true, "$id": true, "$comment": true, "title": true, "description": true,
+	"default": true, "examples": true,
+}
+
+// ValidateOutput checks that a task output is JSON matching a JSON schema.
+// It supports the commonly used keywords: type, properties, required,
+// additionalProperties, items, enum, const, minimum, maximum, minLength,
+// maxLength, pattern, minItems and maxItems.
+func ValidateOutput(schema map[string]any, output string) error {
+	var value any
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &value); err != nil {
+		return Errorf(ErrorInvalidRequest, "output is not valid JSON: %v", err)
+	}
+	if err := validateValue(schema, value, "$"); err != nil {
+		return WithCode(ErrorInvalidRequest, fmt.Errorf("output does not match the output schema: %w", err))
+	}
+	return nil
+}
+
+// checkSchema rejects schemas using keywords or types ValidateOutput does
+// not know, such as oneOf or $ref, so they surface when the workflow is
+// parsed instead of being ignored
+func checkSchema(schema map[string]any) error {
+	keywords := make([]string, 0, len(schema))
+	for keyword := range schema {
+		keywords = append(keywords, keyword)
+	}
+	sort.Strings(keywords)
+	for _, keyword := range keywords {
+		if !schemaKeywords[keyword] {
+			return fmt.Errorf("unsupported keyword %q", keyword)
+		}
+	}
+	if additional, ok := schema["additionalProperties"]; ok {
+		if _, isBool := additional.(bool); !isBool {
+			return fmt.Errorf("additionalProperties must be true or false")
+		}
+	}
+
+	for _, t := range schemaTypeList(schema) {
+		if !schemaTypes[t] {
+			return fmt.Errorf("unknown type %q", t)
+		}
+	}
+	if properties, ok := schema["properties"].(map[string]any); ok {
+		for name, property := range properties {
+			sub, ok := property.(map[string]any)
+			if !ok {
+				return fmt.Errorf("property %s: schema must be an object", name)
+			}
+			if err := checkSchema(sub); err != nil {
+				return fmt.Errorf("property %s: %w", name, err)
+			}
+		}
+	}
+	if items, ok := schema["items"]; ok {
+		sub, isObject := items.(map[string]any)
+		if !isObject {
+			return fmt.Errorf("items: schema must be an object")
+		}
+		if err := checkSchema(sub); err != nil {
+			return fmt.Errorf("items: %w", err)
+		}
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+	return nil
+}
+
+func validateValue(schema map[string]any, value any, path string) error {
+	if types := schemaTypeList(schema); len(types) > 0 {
+		matched := false
+		for _, t := range types {
+			if hasType(value, t) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Errorf("%s: expected %s, got %s", path, strings.Join(types, " or "), typeOf(value))
+		}
+	}
+
+	if expected, ok := schema["const"]; ok && !jsonEqual(expected, value) {
+		return fmt.Errorf("%s: must be %s", path, jsonString(expected))
+	}
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, option := range enum {
+			if jsonEqual(option, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			options := make([]string, len(enum))
+			for i, option := range enum {
+				options[i] = jsonString(option)
+			}
+			return fmt.Errorf("%s: must be one of %s", path, strings.Join(options, ", "))
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		return validateObject(schema, v, path)
+
+	case []any:
+		if min, ok := schemaNumber(schema, "minItems"); ok && float64(len(v)) < min {
+			return fmt.Errorf("%s: must have at least %v items", path, min)
+		}
+		if max, ok := schemaNumber(schema, "maxItems"); ok && float64(len(v)) > max {
+			return fmt.Errorf("%s: must have at most %v items", path, max)
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				if err := validateValue(items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+
+	case string:
+		length := float64(len([]rune(v)))
+		if min, ok := schemaNumber(schema, "minLength"); ok && length < min {
+			return fmt.Errorf("%s: must be at least %v characters", path, min)
+		}
+		if max, ok := schemaNumber(schema, "maxLength"); ok && length > max {
+			return fmt.Errorf("%s: must be at most %v characters", path, max)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			re, err := regexp.Compile(pattern)
+			if err == nil && !re.MatchString(v) {
+				return fmt.Errorf("%s: must match %s", path, pattern)
+			}
+		}
+
+	case float64:
+		if min, ok := schemaNumber(schema, "minimum"); ok && v < min {
+			return fmt.Errorf("%s: must be at least %v", path, min)
+		}
+		if max, ok := schemaNumber(schema, "maximum"); ok && v > max {
+			return fmt.Errorf("%s: must be at most %v", path, max)
+		}
+	}
+
+	return nil
+}
+
+func validateObject(schema map[string]any, object map[string]any, path string) error {
+	if required, ok := schema["required"].([]any); ok {
+		for _, name := range required {
+			if key, ok := name.(string); ok {
+				if _, present := object[key]; !present {
+					return fmt.Errorf("%s: missing required property %q", path, key)
+				}
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]any)
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		property, declared := properties[key].(map[string]any)
+		if !declared {
+			if allowed, ok := schema["additionalProperties"].(bool); ok && !allowed {
+				return fmt.Errorf("%s: unexpected property %q", path, key)
+			}
+			continue
+		}
+		if err := validateValue(property, object[key], path+"."+key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// schemaTypeList returns a schema's type, which may be a name or a list
+func schemaTypeList(schema map[string]any) []string {
+	switch t := schema["type"].(type) {
+	case string:
+		return []string{t}
+	case []any:
+		var types []string
+		for _, item := range t {
+			if name, ok := item.(string); ok {
+				types = append(types, name)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// schemaNumber returns a numeric keyword, which YAML may decode as an int
+func schemaNumber(schema map[string]any, keyword string) (float64, bool) {
+	switch n := schema[keyword].(type) {
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func hasType(value any, t string) bool {
+	switch t {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	}
+	return false
+}
+
+func typeOf(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// jsonEqual compares a schema value, decoded from YAML, with an output
+// value decoded from JSON
+func jsonEqual(a, b any) bool {
+	return jsonString(a) == jsonString(b)
+}
+
+func jsonString(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
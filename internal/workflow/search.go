@@ -0,0 +1,71 @@
+package workflow
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+)
+
+// Grep runs grep with args and returns up to limit lines of its output,
+// stopping grep early once enough lines were read. No matches is not an
+// error.
+func Grep(ctx context.Context, args []string, limit int) ([]string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "grep", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("grep failed: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("grep failed: %w", err)
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if len(lines) >= limit {
+			break
+		}
+	}
+	truncated := len(lines) >= limit
+
+	// A line too long to scan stops reading, so grep is stopped too
+	// rather than left blocked on a full pipe
+	if scanErr := scanner.Err(); scanErr != nil {
+		cancel()
+		cmd.Wait()
+		if errors.Is(scanErr, bufio.ErrTooLong) {
+			return nil, WithCode(ErrorInvalidRequest, errors.New("grep failed: a matching line is longer than 1 MiB"))
+		}
+		return nil, fmt.Errorf("failed to read grep output: %w", scanErr)
+	}
+	if truncated {
+		cancel()
+	}
+
+	err = cmd.Wait()
+	if truncated || err == nil {
+		return lines, nil
+	}
+
+	// Exit status 1 means no matches; 2 means trouble, but grep still
+	// reports the matches it found alongside unreadable files
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && (exitErr.ExitCode() == 1 || len(lines) > 0) {
+		return lines, nil
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, WithCode(ErrorTimeout, fmt.Errorf("grep timed out: %w", ctx.Err()))
+	}
+	return nil, WithCode(ErrorInvalidRequest, fmt.Errorf("grep failed: %v: %s", err, bytes.TrimSpace(stderr.Bytes())))
+}
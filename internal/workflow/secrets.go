@@ -0,0 +1,120 @@
+package workflow
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Secret is a value kept out of the workflow file, read from an environment
+// variable or a file when the run starts
+type Secret struct {
+	Env  string `yaml:"env,omitempty"`
+	File string `yaml:"file,omitempty"`
+}
+
+// secretPattern matches {secrets.name} references
+var secretPattern = regexp.MustCompile(`\{secrets\.([A-Za-z0-9_-]+)\}`)
+
+// SecretValues are the resolved values of a workflow's secrets by name.
+// They are never persisted.
+type SecretValues map[string]string
+
+// LoadSecrets resolves the workflow's secrets
+func (w *Workflow) LoadSecrets() (SecretValues, error) {
+	values := make(SecretValues, len(w.Secrets))
+	for name, secret := range w.Secrets {
+		if secret.Env != "" {
+			value, ok := os.LookupEnv(secret.Env)
+			if !ok {
+				return nil, fmt.Errorf("secret %s: environment variable %s is not set", name, secret.Env)
+			}
+			values[name] = value
+			continue
+		}
+
+		data, err := os.ReadFile(secret.File)
+		if err != nil {
+			return nil, fmt.Errorf("secret %s: failed to read %s: %w", name, secret.File, err)
+		}
+		values[name] = strings.TrimRight(string(data), "\r\n")
+	}
+	return values, nil
+}
+
+// SecretNames returns the declared secret names, sorted
+func (w *Workflow) SecretNames() []string {
+	names := make([]string, 0, len(w.Secrets))
+	for name := range w.Secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Interpolate replaces {secrets.name} references with secret values
+func (v SecretValues) Interpolate(text string) string {
+	if len(v) == 0 {
+		return text
+	}
+	return secretPattern.ReplaceAllStringFunc(text, func(ref string) string {
+		name := secretPattern.FindStringSubmatch(ref)[1]
+		if value, ok := v[name]; ok {
+			return value
+		}
+		return ref
+	})
+}
+
+// Redact replaces secret values in text with [redacted:name], longest
+// values first so one secret containing another is redacted whole
+func (v SecretValues) Redact(text string) string {
+	if len(v) == 0 {
+		return text
+	}
+
+	names := make([]string, 0, len(v))
+	for name, value := range v {
+		if value != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return len(v[names[i]]) > len(v[names[j]])
+	})
+
+	for _, name := range names {
+		text = strings.ReplaceAll(text, v[name], "[redacted:"+name+"]")
+	}
+	return text
+}
+
+// validateSecrets checks that every secret has one source and that tasks
+// and hooks only reference declared secrets
+func (w *Workflow) validateSecrets(v *validator) {
+	for _, name := range w.SecretNames() {
+		if secret := w.Secrets[name]; (secret.Env == "") == (secret.File == "") {
+			v.add([]string{"secrets", name}, "secret %s: exactly one of env or file is required", name)
+		}
+	}
+
+	check := func(path []string, owner, text string) {
+		for _, match := range secretPattern.FindAllStringSubmatch(text, -1) {
+			if _, ok := w.Secrets[match[1]]; !ok {
+				v.add(path, "%s: unknown secret %q", owner, match[1])
+			}
+		}
+	}
+	for _, task := range w.Tasks {
+		check(taskPath(task.ID, "prompt"), "task "+task.ID, task.Prompt)
+	}
+	for i, hook := range w.Hooks {
+		check([]string{"hooks", strconv.Itoa(i), "run"}, "hook "+hook.Run, hook.Run)
+	}
+	for i, hook := range w.Lifecycle {
+		check([]string{"lifecycle", strconv.Itoa(i), "run"}, "lifecycle hook "+hook.Run, hook.Run)
+	}
+}
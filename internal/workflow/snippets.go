@@ -0,0 +1,71 @@
+package workflow
+
+import (
+	"regexp"
+	"sort"
+)
+
+// snippetPattern matches {snippet.name} references
+var snippetPattern = regexp.MustCompile(`\{snippet\.([A-Za-z0-9_-]+)\}`)
+
+// ExpandSnippets replaces {snippet.name} references in task prompts and
+// descriptions with the snippets' text, so shared instructions are
+// defined once. Snippets may reference parameters, which are interpolated
+// afterwards like the rest of the prompt. Unknown references are left in
+// place for validation to report.
+func (w *Workflow) ExpandSnippets() {
+	if len(w.Snippets) == 0 {
+		return
+	}
+	for i := range w.Tasks {
+		w.Tasks[i].Prompt = w.interpolateSnippets(w.Tasks[i].Prompt)
+		w.Tasks[i].Description = w.interpolateSnippets(w.Tasks[i].Description)
+		for j, criterion := range w.Tasks[i].AcceptanceCriteria {
+			w.Tasks[i].AcceptanceCriteria[j] = w.interpolateSnippets(criterion)
+		}
+		if verify := w.Tasks[i].Verify; verify != nil {
+			verify.Run = w.interpolateSnippets(verify.Run)
+		}
+	}
+}
+
+// interpolateSnippets replaces the snippet references in text
+func (w *Workflow) interpolateSnippets(text string) string {
+	return snippetPattern.ReplaceAllStringFunc(text, func(ref string) string {
+		name := snippetPattern.FindStringSubmatch(ref)[1]
+		if snippet, ok := w.Snippets[name]; ok {
+			return snippet
+		}
+		return ref
+	})
+}
+
+// validateSnippets checks that snippets don't reference each other and
+// that tasks only reference declared snippets
+func (w *Workflow) validateSnippets(v *validator) {
+	for _, name := range w.snippetNames() {
+		if snippetPattern.MatchString(w.Snippets[name]) {
+			v.add([]string{"snippets", name}, "snippet %s: snippets cannot reference other snippets", name)
+		}
+	}
+
+	for _, task := range w.Tasks {
+		for _, field := range task.textFields() {
+			for _, match := range snippetPattern.FindAllStringSubmatch(field.text, -1) {
+				if _, ok := w.Snippets[match[1]]; !ok {
+					v.add(taskPath(task.ID, field.name), "task %s: unknown snippet %q", task.ID, match[1])
+				}
+			}
+		}
+	}
+}
+
+// snippetNames returns the declared snippet names, sorted
+func (w *Workflow) snippetNames() []string {
+	names := make([]string, 0, len(w.Snippets))
+	for name := range w.Snippets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,59 @@
+package workflow
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// TaskTypeWorkflow marks a task that runs another workflow as a child
+// session instead of spawning an agent
+const TaskTypeWorkflow = "workflow"
+
+// IsSubWorkflow reports whether the task runs a child workflow
+func (t Task) IsSubWorkflow() bool {
+	return t.Type == TaskTypeWorkflow
+}
+
+// resolveSubWorkflow makes a sub-workflow path absolute, relative to the
+// directory of the file declaring the task
+func (t *Task) resolveSubWorkflow(dir string) {
+	if t.Workflow == "" || filepath.IsAbs(t.Workflow) {
+		return
+	}
+	path := filepath.Join(dir, t.Workflow)
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	t.Workflow = path
+}
+
+// validateType checks that a task's type and its fields go together
+func (t Task) validateType() error {
+	switch t.Type {
+	case "":
+		if t.Workflow != "" {
+			return fmt.Errorf("workflow requires type: %s", TaskTypeWorkflow)
+		}
+		if t.Format != "" {
+			return fmt.Errorf("format requires type: %s", TaskTypeAggregate)
+		}
+		if t.Prompt == "" {
+			return fmt.Errorf("prompt is required")
+		}
+	case TaskTypeAggregate:
+		if t.Workflow != "" {
+			return fmt.Errorf("type %s combines outputs, not a workflow", TaskTypeAggregate)
+		}
+		return t.validateAggregate()
+	case TaskTypeWorkflow:
+		if t.Workflow == "" {
+			return fmt.Errorf("workflow is required for type %s", TaskTypeWorkflow)
+		}
+		if t.Prompt != "" {
+			return fmt.Errorf("type %s runs a workflow, not a prompt", TaskTypeWorkflow)
+		}
+	default:
+		return fmt.Errorf("unknown type %q (want %s or %s, or none for an agent)", t.Type, TaskTypeWorkflow, TaskTypeAggregate)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package workflow
+
+import "fmt"
+
+// How a task's context includes the outputs of its dependencies
+const (
+	ContextSummary = "summary" // Long outputs are summarized; the default
+	ContextFull    = "full"    // Outputs are included whole
+)
+
+// DefaultSummaryThreshold is how many bytes a dependency output may have
+// before dependents get a summary of it instead
+const DefaultSummaryThreshold = 16000
+
+// SummarizesDependencies reports whether the task's context summarizes
+// long dependency outputs
+func (t Task) SummarizesDependencies() bool {
+	return t.Context != ContextFull
+}
+
+// SummaryThresholdBytes returns how long a dependency output may be before
+// it is summarized
+func (w *Workflow) SummaryThresholdBytes() int {
+	if w.SummaryThreshold == 0 {
+		return DefaultSummaryThreshold
+	}
+	return w.SummaryThreshold
+}
+
+// validateContext checks the task's context setting
+func (t Task) validateContext() error {
+	switch t.Context {
+	case "", ContextSummary, ContextFull:
+		return nil
+	}
+	return fmt.Errorf("unknown context %q (want %s or %s)", t.Context, ContextSummary, ContextFull)
+}
@@ -1,12 +1,96 @@
 package workflow
 
-import "time"
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
 
 // Workflow represents a complete workflow definition
 type Workflow struct {
-	Name        string `yaml:"name"`
-	Description string `yaml:"description"`
-	Tasks       []Task `yaml:"tasks"`
+	Name        string  `yaml:"name"`
+	Description string  `yaml:"description"`
+	Quotas      *Quotas `yaml:"quotas,omitempty"`
+	// ReadOnly turns writes and edits into proposals collected for review
+	// and rejects bash commands with side effects
+	ReadOnly bool     `yaml:"read_only,omitempty"`
+	RepoMap  *RepoMap `yaml:"repo_map,omitempty"`
+	// Params are named values with defaults, referenced in prompts as
+	// {params.name} and overridden with swarm run --param name=value
+	Params map[string]string `yaml:"params,omitempty"`
+	// Snippets are shared instructions, such as coding standards, injected
+	// into prompts and descriptions as {snippet.name}
+	Snippets map[string]string `yaml:"snippets,omitempty"`
+	// Secrets are values read from the environment or files. Agents pass
+	// {secrets.name} references to bash commands, which get the values;
+	// everything the orchestrator records has them redacted.
+	Secrets map[string]Secret `yaml:"secrets,omitempty"`
+	// FailurePolicy decides what happens to the other tasks when a task
+	// fails; empty means continue_independent
+	FailurePolicy FailurePolicy `yaml:"failure_policy,omitempty"`
+	// SummaryThreshold is how many bytes a dependency output may have
+	// before dependents get a summary of it; zero means
+	// DefaultSummaryThreshold
+	SummaryThreshold int `yaml:"summary_threshold,omitempty"`
+	// MaxParallel caps how many agents run at once; zero means no limit
+	MaxParallel int `yaml:"max_parallel,omitempty"`
+	// Groups configures named groups of tasks, which tasks join by name
+	Groups map[string]TaskGroup `yaml:"groups,omitempty"`
+	// Hooks run shell commands on events
+	Hooks []Hook `yaml:"hooks,omitempty"`
+	// Lifecycle hooks run commands the orchestrator waits for before
+	// spawning agents, on completions and on questions
+	Lifecycle []LifecycleHook `yaml:"lifecycle,omitempty"`
+	// Issues mirrors tasks into an issue tracker
+	Issues *Issues `yaml:"issues,omitempty"`
+	// Email sends notifications over SMTP
+	Email *Email `yaml:"email,omitempty"`
+	// Retention prunes agent artifacts by age and total size
+	Retention *Retention `yaml:"retention,omitempty"`
+	// Budget caps the tokens and cost agents report for the whole run
+	Budget *Budget `yaml:"budget,omitempty"`
+	// HeartbeatTimeout is how long a running agent may go without a sign
+	// of life, such as 10m, before it is marked stale; twice that and its
+	// task fails. Empty means agents are never presumed dead.
+	HeartbeatTimeout string `yaml:"heartbeat_timeout,omitempty"`
+	// MaxRestarts is how many times an agent process that crashes, exiting
+	// without completing or failing its task, is restarted before the task
+	// fails; unset means DefaultMaxRestarts and 0 never restarts
+	MaxRestarts *int `yaml:"max_restarts,omitempty"`
+	// Backoff delays restarting tasks that keep failing and quarantines
+	// them when they fail too often too quickly
+	Backoff *Backoff `yaml:"backoff,omitempty"`
+	// Answers are canned answers to questions matching a pattern
+	Answers CannedAnswers `yaml:"answers,omitempty"`
+	// ReviewAnswers queues the answers the orchestrator drafts for an
+	// operator to approve or edit before agents get them
+	ReviewAnswers bool   `yaml:"review_answers,omitempty"`
+	Tasks         []Task `yaml:"tasks"`
+}
+
+// TaskGroup configures a group of tasks
+type TaskGroup struct {
+	// MaxParallel caps how many of the group's agents run at once; zero
+	// means no limit beyond the workflow's
+	MaxParallel int `yaml:"max_parallel,omitempty"`
+}
+
+// GroupLimit returns the max_parallel of a task group, or 0 if it has none
+func (w *Workflow) GroupLimit(group string) int {
+	if group == "" {
+		return 0
+	}
+	return w.Groups[group].MaxParallel
+}
+
+// RepoMap configures the repository map injected into every agent's
+// context. By default it maps the current directory if it looks like a
+// project.
+type RepoMap struct {
+	Root     string `yaml:"root,omitempty"`
+	MaxBytes int    `yaml:"max_bytes,omitempty"`
+	Disabled bool   `yaml:"disabled,omitempty"`
 }
 
 // Task represents a single task in the workflow
@@ -16,6 +100,97 @@ type Task struct {
 	Description string   `yaml:"description"`
 	Prompt      string   `yaml:"prompt"`
 	DependsOn   []string `yaml:"depends_on"`
+	Quotas      *Quotas  `yaml:"quotas,omitempty"`
+	Group       string   `yaml:"group,omitempty"`
+	// AcceptanceCriteria are the conditions the task's work must meet.
+	// They are listed in the agent's context, echoed back when it
+	// completes, and passed to the tasks reviewing its output.
+	AcceptanceCriteria []string `yaml:"acceptance_criteria,omitempty"`
+	// Verify runs a command when the task completes; the task completes
+	// only if it passes
+	Verify *Verify `yaml:"verify,omitempty"`
+	// Labels tag the task, such as with its phase; the TUI groups tasks
+	// into sections by their first label
+	Labels []string `yaml:"labels,omitempty"`
+	// Priority orders tasks that become ready at the same time: higher
+	// priorities spawn first, ties in declaration order
+	Priority int `yaml:"priority,omitempty"`
+	// Matrix expands the task into one instance per item; see
+	// ExpandMatrices
+	Matrix []string    `yaml:"matrix,omitempty"`
+	Join   *MatrixJoin `yaml:"join,omitempty"`
+	// Include instantiates the tasks of a template file with the With
+	// arguments; see ExpandIncludes
+	Include string            `yaml:"include,omitempty"`
+	With    map[string]string `yaml:"with,omitempty"`
+	// Type "workflow" runs the Workflow file as a child session, with the
+	// With values as its parameters, instead of spawning an agent. Type
+	// "aggregate" combines the outputs of the tasks it depends on in a
+	// Format; see AggregateOutputs.
+	Type     string `yaml:"type,omitempty"`
+	Workflow string `yaml:"workflow,omitempty"`
+	Format   string `yaml:"format,omitempty"`
+	// Aggregate marks a join task the orchestrator completes itself by
+	// joining its dependencies' outputs
+	Aggregate bool `yaml:"-"`
+	// Issue is an existing tracker issue to update instead of creating one
+	Issue string `yaml:"issue,omitempty"`
+	// Context is how the task's context includes its dependencies'
+	// outputs: "summary" (the default) summarizes outputs longer than the
+	// workflow's summary_threshold, "full" includes them whole
+	Context string `yaml:"context,omitempty"`
+	// OutputFormat is the format the task's output must have: markdown,
+	// json or diff; see CheckOutput
+	OutputFormat string `yaml:"output_format,omitempty"`
+	// OutputSchema is a JSON schema the task's output must match
+	OutputSchema map[string]any `yaml:"output_schema,omitempty"`
+	// OnFailure runs a handler when the task fails; see
+	// ExpandFailureHandlers
+	OnFailure *OnFailure `yaml:"on_failure,omitempty"`
+	// Artifacts are globs of the files the task produces, collected into
+	// the session when it completes
+	Artifacts []string `yaml:"artifacts,omitempty"`
+	// RepeatUntil runs the task again until its output meets a condition
+	RepeatUntil *RepeatUntil `yaml:"repeat_until,omitempty"`
+	// ExpectedDuration is how long the task should take, such as 10m; a
+	// task running far longer is reported overdue
+	ExpectedDuration string `yaml:"expected_duration,omitempty"`
+	// MaxRestarts overrides the workflow's max_restarts for the task
+	MaxRestarts *int `yaml:"max_restarts,omitempty"`
+	// FailureOf lists the tasks whose failure this handler runs for. A
+	// handler runs only when one of them fails.
+	FailureOf []string `yaml:"-"`
+}
+
+// Quotas limits what a single agent may do before an operator has to
+// approve further operations. Zero means unlimited.
+type Quotas struct {
+	MaxFilesWritten    int   `yaml:"max_files_written,omitempty"`
+	MaxBytesWritten    int64 `yaml:"max_bytes_written,omitempty"`
+	MaxBashInvocations int   `yaml:"max_bash_invocations,omitempty"`
+}
+
+// Merge returns q with every limit set in override replacing its own
+func (q *Quotas) Merge(override *Quotas) Quotas {
+	var merged Quotas
+	if q != nil {
+		merged = *q
+	}
+	if override == nil {
+		return merged
+	}
+
+	if override.MaxFilesWritten != 0 {
+		merged.MaxFilesWritten = override.MaxFilesWritten
+	}
+	if override.MaxBytesWritten != 0 {
+		merged.MaxBytesWritten = override.MaxBytesWritten
+	}
+	if override.MaxBashInvocations != 0 {
+		merged.MaxBashInvocations = override.MaxBashInvocations
+	}
+
+	return merged
 }
 
 // TaskStatus represents the current status of a task
@@ -26,18 +201,89 @@ const (
 	TaskStatusRunning   TaskStatus = "running"
 	TaskStatusCompleted TaskStatus = "completed"
 	TaskStatusFailed    TaskStatus = "failed"
+	TaskStatusCancelled TaskStatus = "cancelled"
+	// TaskStatusSkipped marks tasks an operator skipped; dependents run
+	// with an empty output in their place
+	TaskStatusSkipped TaskStatus = "skipped"
+	// TaskStatusQuarantined marks failed tasks that failed too often too
+	// quickly; they count as failed and run again only when rerun
+	TaskStatusQuarantined TaskStatus = "quarantined"
 )
 
+// IsFailure reports whether the status is failed or quarantined
+func (s TaskStatus) IsFailure() bool {
+	return s == TaskStatusFailed || s == TaskStatusQuarantined
+}
+
 // AgentState represents the state of an agent working on a task
 type AgentState struct {
 	TaskID     string
 	Status     TaskStatus
 	StartedAt  time.Time
+	FinishedAt time.Time
 	Output     string
 	Error      string
 	Questions  []Question
 	FollowUps  []FollowUp
 	WorkingDir string
+	Usage      QuotaUsage
+	// QuotaPaused is set when an operation would exceed the task's quotas;
+	// further writes and bash commands are rejected until an operator approves
+	QuotaPaused       bool
+	QuotaPausedReason string
+	// OperationErrors counts the agent's failed operations by error code
+	OperationErrors map[ErrorCode]int
+	// Issue is the key of the tracker issue mirroring the task
+	Issue string
+	// Iteration counts the runs of a repeated task, from 1
+	Iteration int
+	// Overdue is set once the task has run far beyond its expected duration
+	Overdue bool
+	// LastHeartbeat is when the agent was last heard from over the API
+	LastHeartbeat time.Time `json:",omitempty"`
+	// Stale is set while the agent has gone without a heartbeat for longer
+	// than the workflow's heartbeat_timeout
+	Stale bool `json:",omitempty"`
+	// Tokens is the usage the agent reported with swarm-agent report-usage
+	Tokens TokenUsage
+	// Restarts counts the agent processes of the task that crashed before
+	// this one
+	Restarts int `json:",omitempty"`
+	// Progress is the status the agent last reported with swarm-agent
+	// progress, such as "migrated 3/10 modules"
+	Progress string `json:",omitempty"`
+	// ProgressPercent is how far along the agent said it is, if it did
+	ProgressPercent *int `json:",omitempty"`
+	// ProgressAt is when the agent last reported progress
+	ProgressAt time.Time `json:",omitempty"`
+	// Completion is the files changed, test outcome and notes the agent
+	// reported with swarm-agent complete
+	Completion *Completion `json:",omitempty"`
+}
+
+// FormatProgress formats reported progress for people, such as "30%
+// migrated 3/10 modules"
+func FormatProgress(text string, percent *int) string {
+	if percent == nil {
+		return text
+	}
+	return strings.TrimSpace(fmt.Sprintf("%d%% %s", *percent, text))
+}
+
+// OutputCheckpoint is partial output an agent saved with swarm-agent
+// checkpoint, handed to the next attempt at its task if it does not
+// complete
+type OutputCheckpoint struct {
+	Output  string
+	SavedAt time.Time
+}
+
+// QuotaUsage tracks the operations an agent has performed since its
+// quotas were last approved
+type QuotaUsage struct {
+	FilesWritten    []string
+	BytesWritten    int64
+	BashInvocations int
 }
 
 // Question represents a question asked by an agent to the orchestrator
@@ -47,6 +293,28 @@ type Question struct {
 	AskedAt    time.Time
 	Answer     string
 	AnsweredAt time.Time
+	// AnsweredBy is the operator who answered, when a person did
+	AnsweredBy string `json:",omitempty"`
+	// Draft is the orchestrator's answer awaiting an operator's review,
+	// when the workflow reviews answers
+	Draft string `json:",omitempty"`
+	// Replies continue the question's thread: follow-ups the agent asked
+	// about the answer, each answered in turn
+	Replies []Question `json:",omitempty"`
+}
+
+// AllQuestions returns an agent's questions and their replies, in the
+// order they were asked
+func (a *AgentState) AllQuestions() []Question {
+	var all []Question
+	for _, question := range a.Questions {
+		all = append(all, question)
+		all = append(all, question.Replies...)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].ID < all[j].ID
+	})
+	return all
 }
 
 // FollowUp represents a follow-up question from orchestrator to agent
@@ -62,21 +330,51 @@ type FollowUp struct {
 type EventType string
 
 const (
-	EventQuestionAsked         EventType = "question_asked"
-	EventQuestionAnswered      EventType = "question_answered"
-	EventFollowUpAsked         EventType = "followup_asked"
-	EventFollowUpAnswered      EventType = "followup_answered"
-	EventTaskStarted           EventType = "task_started"
-	EventTaskCompleted         EventType = "task_completed"
-	EventTaskFailed            EventType = "task_failed"
-	EventAgentStatusUpdate     EventType = "agent_status_update"
-	EventFileOperationRequest  EventType = "file_operation_request"
+	EventQuestionAsked        EventType = "question_asked"
+	EventQuestionAnswered     EventType = "question_answered"
+	EventAnswerDrafted        EventType = "answer_drafted"
+	EventFollowUpAsked        EventType = "followup_asked"
+	EventFollowUpAnswered     EventType = "followup_answered"
+	EventTaskStarted          EventType = "task_started"
+	EventTaskCompleted        EventType = "task_completed"
+	EventTaskFailed           EventType = "task_failed"
+	EventTaskQuarantined      EventType = "task_quarantined"
+	EventAgentStatusUpdate    EventType = "agent_status_update"
+	EventFileOperationRequest EventType = "file_operation_request"
+	EventQuotaExceeded        EventType = "quota_exceeded"
+	EventQuotaApproved        EventType = "quota_approved"
+	EventBudgetExceeded       EventType = "budget_exceeded"
+	EventControlRequest       EventType = "control_request"
+	EventOperationFailed      EventType = "operation_failed"
+	EventLockAcquired         EventType = "lock_acquired"
+	EventLockReleased         EventType = "lock_released"
+	EventTasksAdded           EventType = "tasks_added"
+	EventTaskRepeated         EventType = "task_repeated"
+	EventTaskOverdue          EventType = "task_overdue"
+	EventTaskCancelled        EventType = "task_cancelled"
+	EventTaskRerun            EventType = "task_rerun"
+	EventTaskSkipped          EventType = "task_skipped"
+	EventAgentStale           EventType = "agent_stale"
+	EventAgentCrashed         EventType = "agent_crashed"
+	EventVerificationFailed   EventType = "verification_failed"
+	EventSwarmPaused          EventType = "swarm_paused"
+	EventSwarmResumed         EventType = "swarm_resumed"
+	EventSwarmCancelled       EventType = "swarm_cancelled"
+	EventCheckpoint           EventType = "checkpoint"
+	EventFileConflict         EventType = "file_conflict"
+	EventConflictResolved     EventType = "conflict_resolved"
+	EventTaskProgress         EventType = "task_progress"
 )
 
 // FileEvent represents a file system event detected by the monitor
 type FileEvent struct {
+	// Seq numbers the events of a session's log in order, from 1
+	Seq      int64 `json:",omitempty"`
 	Type     EventType
 	AgentID  string
 	FilePath string
 	Time     time.Time
+	// Operator is who caused the event, for operator actions such as
+	// approvals and cancellations
+	Operator string `json:",omitempty"`
 }
@@ -0,0 +1,71 @@
+package workflow
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"sync"
+	"time"
+)
+
+// crockford is the alphabet ULIDs are written in: Crockford's base32,
+// which sorts in the same order as the values it encodes
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ulids generates monotonic ULIDs: within a process each one sorts after
+// the last, even when the clock stands still or is set back
+var ulids struct {
+	sync.Mutex
+	ms   uint64 // Millisecond timestamp of the last ULID
+	high uint16 // Its 80 random bits
+	low  uint64
+}
+
+// NewULID returns a ULID: 26 characters, a millisecond timestamp followed
+// by 80 random bits, that sort by when they were made. A ULID made in the
+// same millisecond as the last one, or after the clock went back, keeps
+// the last one's timestamp and increments its random bits instead.
+func NewULID() string {
+	ulids.Lock()
+	defer ulids.Unlock()
+
+	ms := uint64(time.Now().UnixMilli())
+	switch {
+	case ms > ulids.ms:
+		var random [10]byte
+		rand.Read(random[:])
+		ulids.ms = ms
+		ulids.high = binary.BigEndian.Uint16(random[:2])
+		ulids.low = binary.BigEndian.Uint64(random[2:])
+	default:
+		ulids.low++
+		if ulids.low == 0 {
+			ulids.high++
+			if ulids.high == 0 {
+				// The random bits ran out; borrow the next millisecond
+				ulids.ms++
+			}
+		}
+	}
+
+	// 48 bits of time and 80 random bits, 5 bits a character from the end
+	hi := ulids.ms<<16 | uint64(ulids.high)
+	lo := ulids.low
+	var id [26]byte
+	for i := len(id) - 1; i >= 0; i-- {
+		id[i] = crockford[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(id[:])
+}
+
+// NewMessageID returns the ID of a message from an agent: "msg-", a ULID
+// and the agent's task, so IDs never collide across agents and sort in the
+// order the messages were sent
+func NewMessageID(agentID string) string {
+	id := "msg-" + NewULID()
+	if agentID != "" {
+		id += "-" + agentID
+	}
+	return id
+}
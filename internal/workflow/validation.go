@@ -0,0 +1,145 @@
+package workflow
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ValidationError is a problem in a workflow, located in its file when the
+// workflow was parsed from YAML
+type ValidationError struct {
+	// Path leads to the problem: field names, and IDs, values or indexes
+	// of list items, e.g. tasks, build, depends_on, lint. Tasks without an
+	// ID have the empty ID; a repeated ID carries its occurrence, build#2.
+	Path    []string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+	}
+	return e.Message
+}
+
+// ValidationErrors are all the problems found in a workflow
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = err.Error()
+	}
+	return fmt.Sprintf("%d problems:\n%s", len(e), strings.Join(lines, "\n"))
+}
+
+// locate sets the line and column of each problem from the YAML document
+// the workflow was parsed from. Problems in tasks generated by includes
+// and matrices point at the task that generated them.
+func (e ValidationErrors) locate(doc *yaml.Node) {
+	for i := range e {
+		if node := lookup(doc, e[i].Path); node != nil {
+			e[i].Line, e[i].Column = node.Line, node.Column
+		}
+	}
+	sort.SliceStable(e, func(i, j int) bool {
+		return e[i].Line < e[j].Line
+	})
+}
+
+// lookup returns the node at a path, or the deepest node on the way to it
+func lookup(node *yaml.Node, path []string) *yaml.Node {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	for _, segment := range path {
+		next := child(node, segment)
+		if next == nil {
+			break
+		}
+		node = next
+	}
+	return node
+}
+
+// child returns a mapping's value for a key, or a sequence's item with an
+// ID, value or index matching segment. An item whose ID prefixes segment
+// is the include or matrix task that generated it.
+func child(node *yaml.Node, segment string) *yaml.Node {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == segment {
+				return node.Content[i+1]
+			}
+		}
+
+	case yaml.SequenceNode:
+		// A repeated ID is told apart by its occurrence, as in build#2
+		occurrence := 1
+		if id, n, ok := strings.Cut(segment, "#"); ok {
+			if count, err := strconv.Atoi(n); err == nil {
+				segment, occurrence = id, count
+			}
+		}
+
+		var generator *yaml.Node
+		for _, item := range node.Content {
+			id := item.Value
+			if item.Kind == yaml.MappingNode {
+				id = ""
+				if idNode := child(item, "id"); idNode != nil {
+					id = idNode.Value
+				}
+			}
+			if id == segment {
+				if occurrence--; occurrence == 0 {
+					return item
+				}
+				continue
+			}
+			if id != "" && strings.HasPrefix(segment, id+"-") {
+				generator = item
+			}
+		}
+		if generator != nil {
+			return generator
+		}
+		if index, err := strconv.Atoi(segment); err == nil && index >= 0 && index < len(node.Content) {
+			return node.Content[index]
+		}
+	}
+	return nil
+}
+
+// validator collects the problems found in a workflow
+type validator struct {
+	errs ValidationErrors
+}
+
+// add records a problem at a path
+func (v *validator) add(path []string, format string, args ...any) {
+	v.errs = append(v.errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// err returns the problems found, or nil
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+// taskPath is the path to a task's field
+func taskPath(taskID string, fields ...string) []string {
+	return append([]string{"tasks", taskID}, fields...)
+}
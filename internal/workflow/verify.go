@@ -0,0 +1,80 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultVerifyTimeout bounds verification commands that do not set
+// timeout_seconds, in seconds
+const DefaultVerifyTimeout = 600
+
+// DefaultVerifyAttempts is how many completions a task gets to pass its
+// verification when it does not set max_attempts
+const DefaultVerifyAttempts = 3
+
+// verifyReportTail is how much of a failed verification command's output
+// its report keeps
+const verifyReportTail = 4000
+
+// Verify checks a task's work when it completes, such as "go test ./..."
+// for "the tests pass". The task completes only if the command exits 0;
+// otherwise the failure goes back to its agent to fix, until the task runs
+// out of attempts and fails.
+type Verify struct {
+	Run string `yaml:"run"`
+	// WorkingDir is where the command runs, by default the orchestrator's
+	// working directory
+	WorkingDir     string `yaml:"working_dir,omitempty"`
+	TimeoutSeconds int    `yaml:"timeout_seconds,omitempty"`
+	MaxAttempts    int    `yaml:"max_attempts,omitempty"`
+}
+
+// Timeout returns how long the command may run
+func (v *Verify) Timeout() time.Duration {
+	if v.TimeoutSeconds > 0 {
+		return time.Duration(v.TimeoutSeconds) * time.Second
+	}
+	return DefaultVerifyTimeout * time.Second
+}
+
+// Attempts returns how many completions the task gets to pass
+func (v *Verify) Attempts() int {
+	if v.MaxAttempts > 0 {
+		return v.MaxAttempts
+	}
+	return DefaultVerifyAttempts
+}
+
+// validate checks that the command is set and the limits are not negative
+func (v *Verify) validate() error {
+	if strings.TrimSpace(v.Run) == "" {
+		return fmt.Errorf("run is required")
+	}
+	if v.TimeoutSeconds < 0 {
+		return fmt.Errorf("timeout_seconds must not be negative")
+	}
+	if v.MaxAttempts < 0 {
+		return fmt.Errorf("max_attempts must not be negative")
+	}
+	return nil
+}
+
+// VerifyReport describes a failed verification to the agent that has to
+// fix it: the command, why it failed, the end of its output and the
+// acceptance criteria it checks
+func VerifyReport(command string, err error, output string, criteria []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Verification command failed: %s\n%v\n", command, err)
+	if output = strings.TrimSpace(output); output != "" {
+		if len(output) > verifyReportTail {
+			output = "...\n" + output[len(output)-verifyReportTail:]
+		}
+		fmt.Fprintf(&b, "\nOutput:\n%s\n", output)
+	}
+	if len(criteria) > 0 {
+		fmt.Fprintf(&b, "\nAcceptance criteria:\n%s", Checklist(criteria))
+	}
+	return b.String()
+}
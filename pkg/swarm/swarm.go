@@ -0,0 +1,345 @@
+// Package swarm is the public API for embedding Claude Swarm orchestration
+// in other Go programs. It wraps the orchestrator, state and workflow
+// packages behind a small, stable surface: parse a workflow, create a
+// Session with Options, subscribe to its events and Run it with a context.
+package swarm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aristath/claude-swarm/internal/memory"
+	"github.com/aristath/claude-swarm/internal/orchestrator"
+	"github.com/aristath/claude-swarm/internal/state"
+	"github.com/aristath/claude-swarm/internal/workflow"
+)
+
+// Workflow definition types
+type (
+	Workflow      = workflow.Workflow
+	Task          = workflow.Task
+	TaskStatus    = workflow.TaskStatus
+	FailurePolicy = workflow.FailurePolicy
+	Budget        = workflow.Budget
+)
+
+// Validation error types
+type (
+	ValidationError  = workflow.ValidationError
+	ValidationErrors = workflow.ValidationErrors
+)
+
+// Runtime state types
+type (
+	State      = state.SwarmState
+	AgentState = workflow.AgentState
+	Question   = workflow.Question
+	TokenUsage = workflow.TokenUsage
+)
+
+// Event types
+type (
+	Event     = workflow.FileEvent
+	EventType = workflow.EventType
+)
+
+// Agent protocol types
+type (
+	Message  = workflow.Message
+	Response = workflow.Response
+)
+
+// Lifecycle hook types
+type (
+	LifecyclePoint    = workflow.LifecyclePoint
+	HookPayload       = workflow.HookPayload
+	HookResult        = workflow.HookResult
+	LifecycleCallback = orchestrator.LifecycleCallback
+)
+
+// Lifecycle points, where lifecycle hooks run
+const (
+	LifecyclePreSpawn     = workflow.LifecyclePreSpawn
+	LifecyclePostComplete = workflow.LifecyclePostComplete
+	LifecycleOnQuestion   = workflow.LifecycleOnQuestion
+)
+
+// Spawner launches the agent working on a task once its directory and
+// context file have been prepared
+type Spawner = orchestrator.Spawner
+
+// NewProcessSpawner creates a spawner running each agent as a process from
+// a command template, such as "claude -p {prompt}"; see
+// DefaultAgentCommand
+func NewProcessSpawner(command string) Spawner {
+	return orchestrator.NewProcessSpawner(command)
+}
+
+// DefaultAgentCommand runs agents with the claude CLI
+const DefaultAgentCommand = orchestrator.DefaultAgentCommand
+
+// Task statuses
+const (
+	TaskStatusPending     = workflow.TaskStatusPending
+	TaskStatusRunning     = workflow.TaskStatusRunning
+	TaskStatusCompleted   = workflow.TaskStatusCompleted
+	TaskStatusFailed      = workflow.TaskStatusFailed
+	TaskStatusQuarantined = workflow.TaskStatusQuarantined
+	TaskStatusCancelled   = workflow.TaskStatusCancelled
+	TaskStatusSkipped     = workflow.TaskStatusSkipped
+)
+
+// Failure policies, deciding what happens to the other tasks when a task
+// fails
+const (
+	FailurePolicyHalt                = workflow.FailurePolicyHalt
+	FailurePolicyContinueIndependent = workflow.FailurePolicyContinueIndependent
+	FailurePolicyIgnore              = workflow.FailurePolicyIgnore
+)
+
+// Event types
+const (
+	EventQuestionAsked        = workflow.EventQuestionAsked
+	EventQuestionAnswered     = workflow.EventQuestionAnswered
+	EventAnswerDrafted        = workflow.EventAnswerDrafted
+	EventFollowUpAsked        = workflow.EventFollowUpAsked
+	EventFollowUpAnswered     = workflow.EventFollowUpAnswered
+	EventTaskStarted          = workflow.EventTaskStarted
+	EventTaskCompleted        = workflow.EventTaskCompleted
+	EventTaskFailed           = workflow.EventTaskFailed
+	EventAgentStatusUpdate    = workflow.EventAgentStatusUpdate
+	EventFileOperationRequest = workflow.EventFileOperationRequest
+	EventTaskCancelled        = workflow.EventTaskCancelled
+	EventTaskRerun            = workflow.EventTaskRerun
+	EventTaskSkipped          = workflow.EventTaskSkipped
+	EventBudgetExceeded       = workflow.EventBudgetExceeded
+	EventSwarmPaused          = workflow.EventSwarmPaused
+	EventSwarmResumed         = workflow.EventSwarmResumed
+	EventSwarmCancelled       = workflow.EventSwarmCancelled
+)
+
+// ErrCancelled is returned by Run when the session was cancelled
+var ErrCancelled = orchestrator.ErrCancelled
+
+// ParseWorkflow parses and validates workflow YAML
+func ParseWorkflow(data []byte) (*Workflow, error) {
+	return workflow.NewParser().Parse(data)
+}
+
+// ParseWorkflowFile reads, parses and validates a workflow YAML file
+func ParseWorkflowFile(path string) (*Workflow, error) {
+	return workflow.NewParser().ParseFile(path)
+}
+
+// Options configures a Session
+type Options struct {
+	// SwarmDir is the session directory holding agents/ and state.json.
+	// Required.
+	SwarmDir string
+
+	// SessionID defaults to the base name of SwarmDir
+	SessionID string
+
+	// Plan is the plan text shared with every agent
+	Plan string
+
+	// Spawner launches agents. Defaults to printing spawn prompts.
+	Spawner Spawner
+
+	// FakeAgents spawns simulated agents that complete immediately.
+	// Ignored when Spawner is set.
+	FakeAgents bool
+
+	// TickInterval is how often the orchestrator checks for work when no
+	// event arrives. Defaults to 30 seconds.
+	TickInterval time.Duration
+
+	// PollInterval is how often the session's directories are scanned for
+	// files the file watcher missed, as it can on NFS and Docker volumes.
+	// Defaults to 10 seconds; negative turns polling off.
+	PollInterval time.Duration
+
+	// LifecycleHooks are Go callbacks run at lifecycle points, after the
+	// workflow's lifecycle hooks. At pre_spawn and post_complete, a
+	// rejection or error fails the task; at on_question, an answer
+	// answers the question.
+	LifecycleHooks map[LifecyclePoint][]LifecycleCallback
+
+	// ProjectDir is a directory of the repository whose project memory,
+	// under ~/.claude-swarm/projects/, agents are given and the run is
+	// remembered in. Empty leaves the project memory alone.
+	ProjectDir string
+
+	// Operator is who pauses, resumes and cancels the session, recorded
+	// in its events and audit log. Defaults to $SWARM_OPERATOR, the
+	// operator in ~/.claude-swarm/config.yaml or the OS user.
+	Operator string
+}
+
+// Session is a single orchestration run
+type Session struct {
+	state    *state.SwarmState
+	orch     *orchestrator.Orchestrator
+	operator string
+	ran      bool
+	mu       sync.Mutex
+}
+
+// NewSession prepares a session for a workflow. Workflows built in Go are
+// validated like parsed ones, failing with ValidationErrors.
+func NewSession(wf *Workflow, opts Options) (*Session, error) {
+	if wf == nil {
+		return nil, fmt.Errorf("workflow is required")
+	}
+	if opts.SwarmDir == "" {
+		return nil, fmt.Errorf("swarm directory is required")
+	}
+	if err := wf.ExpandIncludes("."); err != nil {
+		return nil, err
+	}
+	if err := wf.ExpandMatrices(); err != nil {
+		return nil, err
+	}
+	if err := wf.ExpandFailureHandlers(); err != nil {
+		return nil, err
+	}
+	wf.ExpandSnippets()
+	if err := workflow.NewParser().Validate(wf); err != nil {
+		return nil, err
+	}
+
+	swarmDir, err := filepath.Abs(opts.SwarmDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve swarm directory: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Join(swarmDir, "agents"), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create swarm directory: %w", err)
+	}
+
+	sessionID := opts.SessionID
+	if sessionID == "" {
+		sessionID = filepath.Base(swarmDir)
+	}
+
+	var orchOpts []orchestrator.Option
+	switch {
+	case opts.Spawner != nil:
+		orchOpts = append(orchOpts, orchestrator.WithSpawner(opts.Spawner))
+	case opts.FakeAgents:
+		orchOpts = append(orchOpts, orchestrator.WithSpawner(orchestrator.NewFakeSpawner(nil)))
+	}
+	orchOpts = append(orchOpts, orchestrator.WithTickInterval(opts.TickInterval))
+	if opts.PollInterval != 0 {
+		orchOpts = append(orchOpts, orchestrator.WithPollInterval(opts.PollInterval))
+	}
+	for _, point := range workflow.HookPoints {
+		for _, fn := range opts.LifecycleHooks[point] {
+			orchOpts = append(orchOpts, orchestrator.WithLifecycleHook(point, fn))
+		}
+	}
+
+	if opts.ProjectDir != "" {
+		projectMemory, err := memory.ForDir(opts.ProjectDir)
+		if err != nil {
+			return nil, err
+		}
+		if projectMemory != nil {
+			orchOpts = append(orchOpts, orchestrator.WithProjectMemory(projectMemory))
+		}
+	}
+
+	swarmState := state.NewSwarmState(sessionID, opts.Plan, wf)
+	orch, err := orchestrator.NewOrchestrator(swarmDir, swarmState, orchOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	operator := opts.Operator
+	if operator == "" {
+		operator = workflow.CurrentOperator()
+	}
+
+	return &Session{
+		state:    swarmState,
+		orch:     orch,
+		operator: operator,
+	}, nil
+}
+
+// State returns the live session state
+func (s *Session) State() *State {
+	return s.state
+}
+
+// Subscribe returns a channel receiving session events and a function to
+// cancel the subscription. Events are dropped if the buffer fills up.
+func (s *Session) Subscribe(buffer int) (<-chan Event, func()) {
+	return s.state.Subscribe(buffer)
+}
+
+// Run orchestrates the workflow until it completes or ctx is cancelled.
+// A session can only be run once.
+func (s *Session) Run(ctx context.Context) error {
+	s.mu.Lock()
+	if s.ran {
+		s.mu.Unlock()
+		return fmt.Errorf("session already run")
+	}
+	s.ran = true
+	s.mu.Unlock()
+
+	return s.orch.Run(ctx)
+}
+
+// Pause stops spawning agents; running agents carry on
+func (s *Session) Pause() {
+	s.orch.Pause(s.operator)
+}
+
+// Resume spawns agents again after Pause
+func (s *Session) Resume() {
+	s.orch.Resume(s.operator)
+}
+
+// Rerun runs a failed task again with a fresh agent directory
+func (s *Session) Rerun(taskID string) error {
+	return s.orch.Rerun(s.operator, taskID)
+}
+
+// Skip skips a pending or failed task; its dependents run with an empty
+// output in its place
+func (s *Session) Skip(taskID string) error {
+	return s.orch.Skip(s.operator, taskID)
+}
+
+// Answer gives an agent the answer to a question the orchestrator drafted
+// for review, with the workflow's review_answers. An empty answer approves
+// the draft.
+func (s *Session) Answer(taskID string, questionID int, answer string) error {
+	return s.orch.Answer(s.operator, taskID, questionID, answer)
+}
+
+// Cancel marks the tasks that have not finished cancelled and makes Run
+// return ErrCancelled. With signal, agents spawned as processes are
+// interrupted.
+func (s *Session) Cancel(signal bool) {
+	s.orch.Cancel(s.operator, signal)
+}
+
+// Run is a convenience wrapper creating a session and running it
+func Run(ctx context.Context, wf *Workflow, opts Options) (*State, error) {
+	session, err := NewSession(wf, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := session.Run(ctx); err != nil {
+		return session.State(), err
+	}
+
+	return session.State(), nil
+}
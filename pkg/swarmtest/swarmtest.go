@@ -0,0 +1,316 @@
+// Package swarmtest provides helpers for testing workflows against a real
+// orchestrator running in a temporary directory. Tests play the part of the
+// agents: they inject questions, file-operation messages and completions
+// through the regular file protocol and assert on the resulting state.
+package swarmtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aristath/claude-swarm/pkg/swarm"
+)
+
+// DefaultTimeout is how long helpers wait for the orchestrator to react
+const DefaultTimeout = 15 * time.Second
+
+// Harness runs a session against a temporary swarm directory
+type Harness struct {
+	T        testing.TB
+	Dir      string
+	State    *swarm.State
+	Session  *swarm.Session
+	Timeout  time.Duration
+	spawner  *recordingSpawner
+	runErr   chan error
+	started  bool
+	msgCount int
+}
+
+// New creates a harness for a workflow, with a session configured by opts.
+// The swarm directory defaults to a temporary one. Agents are not launched,
+// whatever the Spawner; the test drives them with the harness helpers.
+func New(t testing.TB, wf *swarm.Workflow, opts swarm.Options) *Harness {
+	t.Helper()
+
+	if opts.SwarmDir == "" {
+		opts.SwarmDir = t.TempDir()
+	}
+	spawner := &recordingSpawner{spawned: make(map[string]bool)}
+	opts.Spawner = spawner
+
+	session, err := swarm.NewSession(wf, opts)
+	if err != nil {
+		t.Fatalf("swarmtest: failed to create session: %v", err)
+	}
+
+	dir, err := filepath.Abs(opts.SwarmDir)
+	if err != nil {
+		t.Fatalf("swarmtest: %v", err)
+	}
+
+	return &Harness{
+		T:       t,
+		Dir:     dir,
+		State:   session.State(),
+		Session: session,
+		Timeout: DefaultTimeout,
+		spawner: spawner,
+		runErr:  make(chan error, 1),
+	}
+}
+
+// NewFromYAML parses a workflow definition and creates a harness for it
+func NewFromYAML(t testing.TB, data string, opts swarm.Options) *Harness {
+	t.Helper()
+
+	wf, err := swarm.ParseWorkflow([]byte(data))
+	if err != nil {
+		t.Fatalf("swarmtest: %v", err)
+	}
+
+	return New(t, wf, opts)
+}
+
+// Start runs the session in the background. It is stopped when the
+// test finishes.
+func (h *Harness) Start() {
+	h.T.Helper()
+
+	if h.started {
+		return
+	}
+	h.started = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		h.runErr <- h.Session.Run(ctx)
+	}()
+
+	h.T.Cleanup(cancel)
+}
+
+// AgentDir returns the directory of the agent working on a task
+func (h *Harness) AgentDir(taskID string) string {
+	return filepath.Join(h.Dir, "agents", fmt.Sprintf("agent-%s", taskID))
+}
+
+// WaitForSpawn waits until the orchestrator has spawned an agent for a task
+func (h *Harness) WaitForSpawn(taskID string) {
+	h.T.Helper()
+
+	h.waitFor(fmt.Sprintf("agent %s to be spawned", taskID), func() bool {
+		return h.spawner.wasSpawned(taskID)
+	})
+}
+
+// Spawned reports whether an agent has been spawned for a task
+func (h *Harness) Spawned(taskID string) bool {
+	return h.spawner.wasSpawned(taskID)
+}
+
+// Ask makes an agent ask a question and returns the orchestrator's answer
+func (h *Harness) Ask(taskID, question string) string {
+	h.T.Helper()
+	h.WaitForSpawn(taskID)
+
+	agent := h.State.GetAgent(taskID)
+	qNum := 1
+	if agent != nil {
+		qNum = len(agent.Questions) + 1
+	}
+
+	questionsDir := filepath.Join(h.AgentDir(taskID), "questions")
+	h.writeAtomic(filepath.Join(questionsDir, fmt.Sprintf("q-%d.txt", qNum)), []byte(question))
+
+	answerFile := filepath.Join(questionsDir, fmt.Sprintf("a-%d.txt", qNum))
+	var answer []byte
+	h.waitFor(fmt.Sprintf("answer to question %d from %s", qNum, taskID), func() bool {
+		data, err := os.ReadFile(answerFile)
+		if err != nil || len(data) == 0 {
+			return false
+		}
+		answer = data
+		return true
+	})
+
+	return string(answer)
+}
+
+// Send makes an agent send a file-operation message and returns the response
+func (h *Harness) Send(taskID string, msg swarm.Message) swarm.Response {
+	h.T.Helper()
+	h.WaitForSpawn(taskID)
+
+	if msg.ID == "" {
+		h.msgCount++
+		msg.ID = fmt.Sprintf("msg-test-%d", h.msgCount)
+	}
+	if msg.Timestamp.IsZero() {
+		msg.Timestamp = time.Now()
+	}
+
+	data, err := json.MarshalIndent(msg, "", "  ")
+	if err != nil {
+		h.T.Fatalf("swarmtest: failed to marshal message: %v", err)
+	}
+
+	agentDir := h.AgentDir(taskID)
+	h.writeAtomic(filepath.Join(agentDir, "messages", msg.ID+".json"), data)
+
+	responseFile := filepath.Join(agentDir, "responses", fmt.Sprintf("%s-result.json", msg.ID))
+	var response swarm.Response
+	h.waitFor(fmt.Sprintf("response to message %s from %s", msg.ID, taskID), func() bool {
+		data, err := os.ReadFile(responseFile)
+		if err != nil {
+			return false
+		}
+		return json.Unmarshal(data, &response) == nil
+	})
+
+	if err := response.DecodeData(); err != nil {
+		h.T.Fatalf("swarmtest: failed to decode response to message %s: %v", msg.ID, err)
+	}
+
+	return response
+}
+
+// Complete makes an agent report completion with the given output
+func (h *Harness) Complete(taskID, output string) {
+	h.T.Helper()
+	h.WaitForSpawn(taskID)
+
+	agentDir := h.AgentDir(taskID)
+	h.writeAtomic(filepath.Join(agentDir, "output.txt"), []byte(output))
+	h.writeAtomic(filepath.Join(agentDir, "status.txt"), []byte("completed"))
+	h.writeAtomic(filepath.Join(agentDir, "COMPLETE"), nil)
+}
+
+// WaitForStatus waits until a task reaches the given status
+func (h *Harness) WaitForStatus(taskID string, status swarm.TaskStatus) {
+	h.T.Helper()
+
+	h.waitFor(fmt.Sprintf("task %s to be %s", taskID, status), func() bool {
+		agent := h.State.GetAgent(taskID)
+		return agent != nil && agent.Status == status
+	})
+}
+
+// WaitForCompletion waits until the orchestrator has finished the workflow
+func (h *Harness) WaitForCompletion() {
+	h.T.Helper()
+
+	select {
+	case err := <-h.runErr:
+		if err != nil {
+			h.T.Fatalf("swarmtest: orchestrator failed: %v", err)
+		}
+	case <-time.After(h.Timeout):
+		h.T.Fatalf("swarmtest: timeout waiting for workflow to complete")
+	}
+}
+
+// AssertStatus fails the test if a task does not have the given status
+func (h *Harness) AssertStatus(taskID string, want swarm.TaskStatus) {
+	h.T.Helper()
+
+	agent := h.State.GetAgent(taskID)
+	if agent == nil {
+		if want != swarm.TaskStatusPending {
+			h.T.Errorf("task %s: status = pending, want %s", taskID, want)
+		}
+		return
+	}
+
+	if agent.Status != want {
+		h.T.Errorf("task %s: status = %s, want %s", taskID, agent.Status, want)
+	}
+}
+
+// AssertOutput fails the test if a task's output differs from want
+func (h *Harness) AssertOutput(taskID, want string) {
+	h.T.Helper()
+
+	got, ok := h.State.GetOutputs()[taskID]
+	if !ok {
+		h.T.Errorf("task %s: no output recorded", taskID)
+		return
+	}
+
+	if got != want {
+		h.T.Errorf("task %s: output = %q, want %q", taskID, got, want)
+	}
+}
+
+// AssertQuestionCount fails the test if a task asked a different number of questions
+func (h *Harness) AssertQuestionCount(taskID string, want int) {
+	h.T.Helper()
+
+	agent := h.State.GetAgent(taskID)
+	got := 0
+	if agent != nil {
+		got = len(agent.Questions)
+	}
+
+	if got != want {
+		h.T.Errorf("task %s: %d questions asked, want %d", taskID, got, want)
+	}
+}
+
+// waitFor polls cond until it holds or the harness timeout expires
+func (h *Harness) waitFor(what string, cond func() bool) {
+	h.T.Helper()
+
+	deadline := time.Now().Add(h.Timeout)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	h.T.Fatalf("swarmtest: timeout waiting for %s", what)
+}
+
+// writeAtomic writes a file via rename so the orchestrator never observes
+// partial content
+func (h *Harness) writeAtomic(path string, data []byte) {
+	h.T.Helper()
+
+	tmpFile := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		h.T.Fatalf("swarmtest: failed to write %s: %v", path, err)
+	}
+	if err := os.Rename(tmpFile, path); err != nil {
+		h.T.Fatalf("swarmtest: failed to write %s: %v", path, err)
+	}
+}
+
+// recordingSpawner records which tasks were spawned without launching anything
+type recordingSpawner struct {
+	mu      sync.Mutex
+	spawned map[string]bool
+}
+
+// Spawn records the task as spawned
+func (s *recordingSpawner) Spawn(ctx context.Context, task swarm.Task, agentDir, prompt string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.spawned[task.ID] = true
+	return nil
+}
+
+// wasSpawned reports whether a task was spawned
+func (s *recordingSpawner) wasSpawned(taskID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.spawned[taskID]
+}